@@ -14,6 +14,7 @@ import (
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/github"
 	"github.com/openshift/sippy/pkg/flags"
 	"github.com/openshift/sippy/pkg/github/commenter"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/sippyserver"
 )
 
@@ -116,7 +117,7 @@ func main() {
 	if err != nil {
 		log.WithError(err).Fatal("cannot parse log-level")
 	}
-	log.SetLevel(level)
+	logging.SetDefaultLevel(level)
 	log.Debug("debug logging enabled")
 
 	// Add some millisecond precision to log timestamps, useful for debugging performance.
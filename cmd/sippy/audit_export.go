@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/auditlog"
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+type AuditExportFlags struct {
+	DBFlags *flags.PostgresFlags
+
+	Start      string
+	End        string
+	SigningKey string
+	Output     string
+	Verify     string
+	PublicKey  string
+}
+
+func NewAuditExportFlags() *AuditExportFlags {
+	return &AuditExportFlags{
+		DBFlags: flags.NewPostgresDatabaseFlags(),
+	}
+}
+
+func (f *AuditExportFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+
+	fs.StringVar(&f.Start, "start", "", "Export events from this date (YYYY-MM-DD, UTC) (required)")
+	fs.StringVar(&f.End, "end", "", "Export events up to and including this date (YYYY-MM-DD, UTC), defaults to today")
+	fs.StringVar(&f.SigningKey, "signing-key", "", "PEM encoded ed25519 private key to sign the export with (required)")
+	fs.StringVarP(&f.Output, "output", "o", "", "File to write the export to, defaults to stdout")
+	fs.StringVar(&f.Verify, "verify", "", "Verify a previously written export instead of exporting")
+	fs.StringVar(&f.PublicKey, "public-key", "", "PEM encoded ed25519 public key to verify the export with")
+}
+
+// dateRange returns the start of the start date, and the end of the end date.
+func (f *AuditExportFlags) dateRange() (time.Time, time.Time, error) {
+	if f.Start == "" {
+		return time.Time{}, time.Time{}, fmt.Errorf("--start is required")
+	}
+	start, err := time.Parse("2006-01-02", f.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.WithMessage(err, "invalid --start")
+	}
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if f.End != "" {
+		end, err = time.Parse("2006-01-02", f.End)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.WithMessage(err, "invalid --end")
+		}
+	}
+	end = end.AddDate(0, 0, 1)
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("--start must not be after --end")
+	}
+	return start, end, nil
+}
+
+func NewAuditExportCommand() *cobra.Command {
+	f := NewAuditExportFlags()
+
+	cmd := &cobra.Command{
+		Use:   "audit-export",
+		Short: "Export the signed audit log of changes people made through the API",
+		Long: `Export the audit log of the changes people made through the API over a date range, i.e. opening incidents,
+pinning component readiness bases or recording manual test results, as evidence of CI exceptions for compliance
+audits.
+
+Each event is hashed together with the hash of the event before it, and the export is signed with an ed25519 key
+created by "openssl genpkey -algorithm ed25519". The export fails if the recorded events don't chain, meaning they
+were altered or removed in the database.
+
+With --verify, a previously written export is checked against the public key, created by "openssl pkey -pubout",
+instead: that it was signed with the key, and that none of its events were altered, added or removed since.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if f.Verify != "" {
+				return verifyAuditExport(f.Verify, f.PublicKey)
+			}
+
+			start, end, err := f.dateRange()
+			if err != nil {
+				return err
+			}
+			if f.SigningKey == "" {
+				return fmt.Errorf("--signing-key is required")
+			}
+			key, err := auditlog.LoadPrivateKey(f.SigningKey)
+			if err != nil {
+				return err
+			}
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return err
+			}
+			export, err := auditlog.NewExport(dbc, start, end)
+			if err != nil {
+				return err
+			}
+			export.Sign(key)
+
+			var out io.Writer = cmd.OutOrStdout()
+			if f.Output != "" {
+				file, err := os.Create(f.Output)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				out = file
+			}
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(export); err != nil {
+				return err
+			}
+			log.Infof("exported %d audit events from %s until %s", len(export.Events), start.Format(time.RFC3339),
+				end.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+func verifyAuditExport(path, publicKey string) error {
+	if publicKey == "" {
+		return fmt.Errorf("--public-key is required with --verify")
+	}
+	key, err := auditlog.LoadPublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	export := &auditlog.Export{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return errors.Wrapf(err, "error parsing export %s", path)
+	}
+	if err := export.Verify(key); err != nil {
+		return errors.Wrapf(err, "export %s failed verification", path)
+	}
+	log.Infof("export %s is intact: %d audit events from %s until %s, signed by the given key", path,
+		len(export.Events), export.Start.Format(time.RFC3339), export.End.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+type BigQuerySchemasFlags struct {
+	BigQueryFlags    *flags.BigQueryFlags
+	GoogleCloudFlags *flags.GoogleCloudFlags
+	Table            string
+	Verify           bool
+}
+
+func NewBigQuerySchemasFlags() *BigQuerySchemasFlags {
+	return &BigQuerySchemasFlags{
+		BigQueryFlags:    flags.NewBigQueryFlags(),
+		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
+	}
+}
+
+func (f *BigQuerySchemasFlags) BindFlags(fs *pflag.FlagSet) {
+	f.BigQueryFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+	fs.StringVar(&f.Table, "table", f.Table, "Only emit or verify the schema of this table")
+	fs.BoolVar(&f.Verify, "verify", f.Verify, "Verify the live tables in the dataset match the expected schemas")
+}
+
+type tableSchemaOutput struct {
+	Table       string          `json:"table"`
+	Description string          `json:"description"`
+	Owned       bool            `json:"owned"`
+	Schema      json.RawMessage `json:"schema"`
+	// PartitionField and ClusterFields are applied when sippy creates the table, see --create-bigquery-tables.
+	PartitionField string   `json:"partition_field,omitempty"`
+	ClusterFields  []string `json:"cluster_fields,omitempty"`
+}
+
+func NewBigQuerySchemasCommand() *cobra.Command {
+	f := NewBigQuerySchemasFlags()
+
+	cmd := &cobra.Command{
+		Use:   "bigquery-schemas",
+		Short: "Emit, or verify, the BigQuery table schemas sippy expects",
+		Long: `Emit the schemas of the BigQuery tables sippy writes or reads as JSON, in the format accepted by
+"bq mk --schema". Tables sippy doesn't own only list the fields it reads. With --verify, the live tables in the
+dataset are compared against the expected schemas instead, and any differences are reported.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemas := bqcachedclient.ExpectedSchemas
+			if f.Table != "" {
+				schema, ok := bqcachedclient.ExpectedSchema(f.Table)
+				if !ok {
+					return fmt.Errorf("no expected schema for table %s", f.Table)
+				}
+				schemas = []bqcachedclient.TableSchema{schema}
+			}
+
+			if f.Verify {
+				tables := make([]string, 0, len(schemas))
+				for _, s := range schemas {
+					tables = append(tables, s.Table)
+				}
+				// verified below, not on connecting, so only the selected tables are checked
+				f.BigQueryFlags.VerifySchemas = false
+				ctx := context.Background()
+				client, err := f.BigQueryFlags.GetBigQueryClient(ctx, nil, f.GoogleCloudFlags.ServiceAccountCredentialFile)
+				if err != nil {
+					return errors.WithMessage(err, "couldn't get BigQuery client")
+				}
+				if err := client.VerifySchemas(ctx, tables); err != nil {
+					return err
+				}
+				log.Infof("%d tables in %s match the expected schemas", len(tables), f.BigQueryFlags.BigQueryDataset)
+				return nil
+			}
+
+			output := make([]tableSchemaOutput, 0, len(schemas))
+			for _, s := range schemas {
+				fields, err := s.Schema.ToJSONFields()
+				if err != nil {
+					return errors.Wrapf(err, "couldn't encode schema of %s", s.Table)
+				}
+				o := tableSchemaOutput{
+					Table:       s.Table,
+					Description: s.Description,
+					Owned:       s.Owned,
+					Schema:      fields,
+				}
+				if s.TimePartitioning != nil {
+					o.PartitionField = s.TimePartitioning.Field
+				}
+				if s.Clustering != nil {
+					o.ClusterFields = s.Clustering.Fields
+				}
+				output = append(output, o)
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(output)
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
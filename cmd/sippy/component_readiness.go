@@ -19,8 +19,10 @@ import (
 	"github.com/openshift/sippy/pkg/apis/cache"
 	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
 	"github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/componentreadiness/notifier"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
 	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/sippyserver"
 	"github.com/openshift/sippy/pkg/sippyserver/metrics"
 )
@@ -97,7 +99,7 @@ func (f *ComponentReadinessFlags) Run() error { //nolint:gocyclo
 	if err != nil {
 		log.WithError(err).Fatal("Cannot parse log-level")
 	}
-	log.SetLevel(level)
+	logging.SetDefaultLevel(level)
 
 	// Add some millisecond precision to log timestamps, useful for debugging performance.
 	formatter := new(log.TextFormatter)
@@ -161,6 +163,7 @@ func (f *ComponentReadinessFlags) runServerMode() error {
 		log.WithError(err).Fatal("unable to load views")
 
 	}
+	f.ComponentReadinessFlags.SetReportMemoryLimits()
 
 	server := sippyserver.NewServer(
 		sippyserver.ModeOpenShift,
@@ -181,6 +184,8 @@ func (f *ComponentReadinessFlags) runServerMode() error {
 	)
 
 	if f.MetricsAddr != "" {
+		viewNotifier := notifier.New(f.ComponentReadinessFlags.SMTPConfig())
+
 		// Do an immediate metrics update
 		err = metrics.RefreshMetricsDB(nil,
 			bigQueryClient,
@@ -190,7 +195,8 @@ func (f *ComponentReadinessFlags) runServerMode() error {
 			time.Time{},
 			cache.RequestOptions{CRTimeRoundingFactor: f.ComponentReadinessFlags.CRTimeRoundingFactor},
 			views.ComponentReadiness,
-			f.MaintainRegressionTables)
+			f.MaintainRegressionTables,
+			viewNotifier)
 		if err != nil {
 			log.WithError(err).Error("error refreshing metrics")
 		}
@@ -212,7 +218,8 @@ func (f *ComponentReadinessFlags) runServerMode() error {
 						time.Time{},
 						cache.RequestOptions{CRTimeRoundingFactor: f.ComponentReadinessFlags.CRTimeRoundingFactor},
 						views.ComponentReadiness,
-						f.MaintainRegressionTables)
+						f.MaintainRegressionTables,
+						viewNotifier)
 					if err != nil {
 						log.WithError(err).Error("error refreshing metrics")
 					}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/client"
+	"github.com/openshift/sippy/pkg/e2echeck"
+	"github.com/openshift/sippy/pkg/sippyserver"
+)
+
+type E2ECheckFlags struct {
+	URL                    string
+	Token                  string
+	Release                string
+	View                   string
+	SkipComponentReadiness bool
+	MaxDataAge             time.Duration
+	Timeout                time.Duration
+	Output                 string
+}
+
+func NewE2ECheckFlags() *E2ECheckFlags {
+	return &E2ECheckFlags{
+		URL:        os.Getenv("SIPPY_URL"),
+		Token:      os.Getenv("SIPPY_TOKEN"),
+		MaxDataAge: sippyserver.DefaultReadinessMaxDataAge,
+		Timeout:    10 * time.Minute,
+		Output:     "text",
+	}
+}
+
+func (f *E2ECheckFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.URL, "url", f.URL, "URL of the sippy deployment to check, i.e. https://sippy.dptools.openshift.org")
+	fs.StringVar(&f.Token, "token", f.Token, "Bearer token for deployments behind an authenticating proxy")
+	fs.StringVar(&f.Release, "release", f.Release, "Release whose reports are checked")
+	fs.StringVar(&f.View, "view", f.View, "Component readiness view to generate, defaults to the first view sampling --release")
+	fs.BoolVar(&f.SkipComponentReadiness, "skip-component-readiness", f.SkipComponentReadiness, "Skip the component readiness check, for deployments without BigQuery")
+	fs.DurationVar(&f.MaxDataAge, "max-data-age", f.MaxDataAge, "Fail if data was last imported longer ago than this, 0 to not check freshness")
+	fs.DurationVar(&f.Timeout, "timeout", f.Timeout, "Time allowed for all checks, including generating the component readiness report")
+	fs.StringVar(&f.Output, "output", f.Output, "Output format, text or json")
+}
+
+func (f *E2ECheckFlags) Validate() error {
+	if f.URL == "" {
+		return fmt.Errorf("--url is required")
+	}
+	if f.Release == "" {
+		return fmt.Errorf("--release is required")
+	}
+	if f.Output != "text" && f.Output != "json" {
+		return fmt.Errorf("--output must be text or json")
+	}
+	return nil
+}
+
+func NewE2ECheckCommand() *cobra.Command {
+	f := NewE2ECheckFlags()
+
+	cmd := &cobra.Command{
+		Use:   "e2e-check",
+		Short: "Smoke test the critical endpoints of a running sippy deployment",
+		Long: `Smoke test a running sippy deployment, for use as a post-deploy gate. The health, install, variants and
+component readiness endpoints are requested for a release, and their responses checked for the expected shape
+and for recently imported data. Exits non-zero if any check fails.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+			c, err := client.New(f.URL, client.Options{Token: f.Token})
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), f.Timeout)
+			defer cancel()
+			results := e2echeck.Run(ctx, c, e2echeck.Options{
+				Release:                f.Release,
+				View:                   f.View,
+				SkipComponentReadiness: f.SkipComponentReadiness,
+				MaxDataAge:             f.MaxDataAge,
+			})
+
+			if f.Output == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(results); err != nil {
+					return err
+				}
+			} else {
+				w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				for _, r := range results {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, r.Status, time.Duration(r.DurationMS)*time.Millisecond, r.Message)
+				}
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+
+			if failed := e2echeck.Failed(results); failed > 0 {
+				return errors.Errorf("%d of %d checks against %s failed", failed, len(results), f.URL)
+			}
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
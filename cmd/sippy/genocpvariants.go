@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/bigquery"
@@ -15,30 +16,156 @@ import (
 	"github.com/spf13/pflag"
 	"google.golang.org/api/option"
 
+	"github.com/openshift/sippy/pkg/api"
 	"github.com/openshift/sippy/pkg/flags"
 )
 
-type LoadVariantsFlags struct {
-	BigQueryFlags     *flags.BigQueryFlags
-	GoogleCloudFlags  *flags.GoogleCloudFlags
-	OutputFile        string
-	Mode              string
-	BigqueryJobsTable string
+// VariantLoaderFlags select and configure the loader calculating the variants jobs are expected to have.
+type VariantLoaderFlags struct {
+	BigQueryFlags       *flags.BigQueryFlags
+	GoogleCloudFlags    *flags.GoogleCloudFlags
+	Mode                string
+	BigqueryJobsTable   string
+	JobExclusionsFile   string
+	ReleaseDefaultsFile string
+	VariantRulesFromDB  bool
+	DBFlags             *flags.PostgresFlags
+
+	VariantOverridesFile     string
+	VariantSchemaFile        string
+	VariantSchemaEnforcement string
 }
 
-func NewLoadVariantsFlags() *LoadVariantsFlags {
-	return &LoadVariantsFlags{
+func NewVariantLoaderFlags() *VariantLoaderFlags {
+	return &VariantLoaderFlags{
 		BigQueryFlags:    flags.NewBigQueryFlags(),
 		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
+		DBFlags:          flags.NewPostgresDatabaseFlags(),
 	}
 }
 
-func (f *LoadVariantsFlags) BindFlags(fs *pflag.FlagSet) {
+func (f *VariantLoaderFlags) BindFlags(fs *pflag.FlagSet) {
 	f.BigQueryFlags.BindFlags(fs)
 	f.GoogleCloudFlags.BindFlags(fs)
-	fs.StringVar(&f.OutputFile, "o", "expected-job-variants.json", "Output json file for job variant data")
-	fs.StringVar(&f.Mode, "mode", "ocp", "Implementation of job variant generator")
+	f.DBFlags.BindFlags(fs)
+	fs.StringVar(&f.Mode, "mode", "ocp", fmt.Sprintf("Implementation of job variant generator, one of: %s", strings.Join(variantregistry.VariantLoaderNames(), ", ")))
 	fs.StringVar(&f.BigqueryJobsTable, "bigquery-jobs-table", "jobs", "Jobs table to load job names from")
+	fs.StringVar(&f.JobExclusionsFile, "job-exclusions-file", "", "File of job name regexes, one per line, to keep out of the variant registry")
+	fs.StringVar(&f.ReleaseDefaultsFile, "release-defaults-file", "", "YAML file of variant defaults by release, overriding the built in matrix")
+	fs.BoolVar(&f.VariantRulesFromDB, "variant-rules-from-db", false, "Use the variant rules revision last committed via the API, overriding the built in matrix")
+	fs.StringVar(&f.VariantOverridesFile, "variant-overrides-file", "", "YAML or JSON file of job name regexes and the variants to force for matching jobs, taking precedence over job names, job specs, cluster data and release defaults")
+	fs.StringVar(&f.VariantSchemaFile, "variant-schema-file", "", "YAML file of the variants jobs may have and their allowed values, overriding the built in schema")
+	fs.StringVar(&f.VariantSchemaEnforcement, "variant-schema-enforcement", string(variantregistry.SchemaEnforcementWarn), "What to do with calculated variant values the schema doesn't allow, one of: off, warn, reject (drop them)")
+}
+
+// bigQueryClient returns a client for the project job names are listed from, which also holds the registry.
+func (f *VariantLoaderFlags) bigQueryClient(ctx context.Context) (*bigquery.Client, error) {
+	bigQueryClient, err := bigquery.NewClient(ctx, f.BigQueryFlags.BigQueryProject,
+		option.WithCredentialsFile(f.GoogleCloudFlags.ServiceAccountCredentialFile))
+	if err != nil {
+		log.WithError(err).Error("CRITICAL error getting BigQuery client which prevents generating job variants")
+		return nil, err
+	}
+	return bigQueryClient, nil
+}
+
+// variantLoader returns the loader selected by --mode.
+func (f *VariantLoaderFlags) variantLoader(ctx context.Context, bigQueryClient *bigquery.Client) (variantregistry.VariantLoader, error) {
+	gcsClient, err := gcs.NewGCSClient(ctx,
+		f.GoogleCloudFlags.ServiceAccountCredentialFile,
+		f.GoogleCloudFlags.OAuthClientCredentialFile,
+	)
+	if err != nil {
+		log.WithError(err).Error("CRITICAL error getting GCS client which prevents generating job variants")
+		return nil, err
+	}
+
+	var exclusions *variantregistry.JobExclusions
+	if f.JobExclusionsFile != "" {
+		exclusions, err = variantregistry.LoadJobExclusions(f.JobExclusionsFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var overrides *variantregistry.VariantOverrides
+	if f.VariantOverridesFile != "" {
+		overrides, err = variantregistry.LoadVariantOverrides(f.VariantOverridesFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	schema, enforcement, err := variantSchema(f.VariantSchemaFile, f.VariantSchemaEnforcement)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaults *variantregistry.ReleaseDefaults
+	switch {
+	case f.ReleaseDefaultsFile != "" && f.VariantRulesFromDB:
+		return nil, fmt.Errorf("only one of --release-defaults-file and --variant-rules-from-db may be set")
+	case f.ReleaseDefaultsFile != "":
+		defaults, err = variantregistry.LoadReleaseDefaults(f.ReleaseDefaultsFile)
+		if err != nil {
+			return nil, err
+		}
+	case f.VariantRulesFromDB:
+		dbc, err := f.DBFlags.GetDBClient()
+		if err != nil {
+			return nil, err
+		}
+		defaults, err = api.ActiveReleaseDefaults(dbc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return variantregistry.NewVariantLoader(f.Mode, variantregistry.VariantLoaderOptions{
+		BigQueryClient:    bigQueryClient,
+		BigQueryProject:   f.BigQueryFlags.BigQueryProject,
+		BigQueryDataSet:   f.BigQueryFlags.BigQueryDataset,
+		BigQueryTable:     f.BigqueryJobsTable,
+		GCSClient:         gcsClient,
+		GCSBucket:         f.GoogleCloudFlags.StorageBucket,
+		Exclusions:        exclusions,
+		Defaults:          defaults,
+		Overrides:         overrides,
+		Schema:            schema,
+		SchemaEnforcement: enforcement,
+	})
+}
+
+// variantSchema returns the schema in the given file, or the built in one, and how it is to be enforced.
+func variantSchema(file, enforcement string) (*variantregistry.VariantSchema, variantregistry.SchemaEnforcement, error) {
+	e, err := variantregistry.ParseSchemaEnforcement(enforcement)
+	if err != nil {
+		return nil, "", err
+	}
+	if file == "" {
+		return variantregistry.DefaultVariantSchema(), e, nil
+	}
+	schema, err := variantregistry.LoadVariantSchema(file)
+	if err != nil {
+		return nil, "", err
+	}
+	return schema, e, nil
+}
+
+type LoadVariantsFlags struct {
+	*VariantLoaderFlags
+	OutputFile string
+}
+
+func NewLoadVariantsFlags() *LoadVariantsFlags {
+	return &LoadVariantsFlags{
+		VariantLoaderFlags: NewVariantLoaderFlags(),
+	}
+}
+
+func (f *LoadVariantsFlags) BindFlags(fs *pflag.FlagSet) {
+	f.VariantLoaderFlags.BindFlags(fs)
+	fs.StringVar(&f.OutputFile, "o", "expected-job-variants.json", "Output json file for job variant data")
 }
 
 func NewLoadJobVariantsCommand() *cobra.Command {
@@ -47,46 +174,27 @@ func NewLoadJobVariantsCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "generate-job-variants",
 		Short: "Categorize all known jobs with their appropriate variants",
-		Long:  "This command is somewhat OCP specific and will load all job names that have run in the last several months. The command will load a recent job runs artifacts to search for cluster-data.json, and then try to determine what variants the job should be categorized with based on a combination of the job name, and the contents of cluster-data.json. The resulting desired job variants json file is then written to disk and can be provided as input to the sync-job-variants command.",
+		Long:  "This command loads the jobs of the product selected by --mode and determines what variants each should be categorized with. The default ocp mode will load all job names that have run in the last several months, load a recent job run's artifacts to search for cluster-data.json, and determine the variants from a combination of the job name and the contents of cluster-data.json. Other products can register their own mode with variantregistry.RegisterVariantLoader. The resulting desired job variants json file is then written to disk and can be provided as input to the sync-job-variants command.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Cancel syncing after 4 hours
 			ctx, cancel := context.WithTimeout(context.Background(), time.Hour*4)
 			defer cancel()
-			bigQueryClient, err := bigquery.NewClient(ctx, f.BigQueryFlags.BigQueryProject,
-				option.WithCredentialsFile(f.GoogleCloudFlags.ServiceAccountCredentialFile))
+			bigQueryClient, err := f.bigQueryClient(ctx)
 			if err != nil {
-				log.WithError(err).Error("CRITICAL error getting BigQuery client which prevents generating job variants")
 				return err
 			}
-			gcsClient, err := gcs.NewGCSClient(context.TODO(),
-				f.GoogleCloudFlags.ServiceAccountCredentialFile,
-				f.GoogleCloudFlags.OAuthClientCredentialFile,
-			)
+			jvs, err := f.variantLoader(ctx, bigQueryClient)
 			if err != nil {
-				log.WithError(err).Error("CRITICAL error getting GCS client which prevents generating job variants")
 				return err
 			}
-
-			var jsonData []byte
-
-			switch f.Mode {
-			case "ocp":
-
-				jvs := variantregistry.NewOCPVariantLoader(bigQueryClient, f.BigQueryFlags.BigQueryProject,
-					f.BigQueryFlags.BigQueryDataset, f.BigqueryJobsTable,
-					gcsClient,
-					f.GoogleCloudFlags.StorageBucket)
-				expectedVariants, err := jvs.LoadExpectedJobVariants(context.TODO())
-				if err != nil {
-					return err
-				}
-				log.WithField("jobs", len(expectedVariants)).Info("calculated expected variants")
-				jsonData, err = json.MarshalIndent(expectedVariants, "", "  ")
-				if err != nil {
-					return err
-				}
-			default:
-				return fmt.Errorf("unknown mode: %s", f.Mode)
+			expectedVariants, err := variantregistry.LoadExpectedJobVariants(ctx, jvs)
+			if err != nil {
+				return err
+			}
+			log.WithField("jobs", len(expectedVariants)).Info("calculated expected variants")
+			jsonData, err := json.MarshalIndent(expectedVariants, "", "  ")
+			if err != nil {
+				return err
 			}
 
 			file, err := os.Create(f.OutputFile)
@@ -100,7 +208,7 @@ func NewLoadJobVariantsCommand() *cobra.Command {
 				return err
 			}
 
-			log.Infof("Expected OCP job variants written to: %s", f.OutputFile)
+			log.Infof("Expected %s job variants written to: %s", f.Mode, f.OutputFile)
 
 			return nil
 		},
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+type IntegrityCheckFlags struct {
+	DBFlags *flags.PostgresFlags
+	Fix     bool
+}
+
+func NewIntegrityCheckFlags() *IntegrityCheckFlags {
+	return &IntegrityCheckFlags{
+		DBFlags: flags.NewPostgresDatabaseFlags(),
+	}
+}
+
+func (f *IntegrityCheckFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	fs.BoolVar(&f.Fix, "fix", f.Fix, "Remove duplicate rows, keeping the most recently inserted row for each natural key")
+}
+
+func NewIntegrityCheckCommand() *cobra.Command {
+	f := NewIntegrityCheckFlags()
+
+	cmd := &cobra.Command{
+		Use:   "integrity-check",
+		Short: "Report, and optionally remove, duplicate rows in ingested tables",
+		Long: `Report rows in ingested tables that share a natural key, i.e. the same test result recorded twice for
+a job run. Loaders upsert on these keys, but rows written before they were enforced may be duplicated, which
+prevents the schema migration from creating the unique indexes.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return err
+			}
+
+			reports, err := dbc.FindDuplicates()
+			if err != nil {
+				return errors.WithMessage(err, "couldn't check for duplicates")
+			}
+			var found bool
+			for _, r := range reports {
+				if r.ExtraRows > 0 {
+					found = true
+					log.WithFields(log.Fields{
+						"table":     r.Table,
+						"keys":      r.Keys,
+						"extraRows": r.ExtraRows,
+					}).Warning("duplicate rows found")
+				}
+			}
+			if !found {
+				log.Info("no duplicate rows found")
+				return nil
+			}
+			if !f.Fix {
+				return errors.New("duplicate rows found, re-run with --fix to remove them")
+			}
+
+			if _, err := dbc.RemoveDuplicates(); err != nil {
+				return errors.WithMessage(err, "couldn't remove duplicates")
+			}
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
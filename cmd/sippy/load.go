@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"time"
 
 	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/storage"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -21,22 +23,33 @@ import (
 
 	v1 "github.com/openshift/sippy/pkg/apis/config/v1"
 	"github.com/openshift/sippy/pkg/dataloader"
+	"github.com/openshift/sippy/pkg/dataloader/anomalyloader"
 	"github.com/openshift/sippy/pkg/dataloader/bugloader"
 	"github.com/openshift/sippy/pkg/dataloader/jiraloader"
+	"github.com/openshift/sippy/pkg/dataloader/jirasyncloader"
 	"github.com/openshift/sippy/pkg/dataloader/loaderwithmetrics"
+	"github.com/openshift/sippy/pkg/dataloader/massfailureloader"
+	"github.com/openshift/sippy/pkg/dataloader/milestoneloader"
+	"github.com/openshift/sippy/pkg/dataloader/payloadflakeloader"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/github"
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
 	"github.com/openshift/sippy/pkg/dataloader/testownershiploader"
+	"github.com/openshift/sippy/pkg/dataloader/watchlistloader"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/flags"
 	"github.com/openshift/sippy/pkg/github/commenter"
+	"github.com/openshift/sippy/pkg/jirasync"
+	"github.com/openshift/sippy/pkg/notification"
 	"github.com/openshift/sippy/pkg/sippyserver"
 )
 
 type LoadFlags struct {
 	LoadOpenShiftCIBigQuery bool
+	LoadIntervals           bool
+	LoadEventPatterns       bool
+	LoadFingerprints        bool
 	Loaders                 []string
 
 	InitDatabase bool
@@ -51,6 +64,40 @@ type LoadFlags struct {
 	GoogleCloudFlags     *flags.GoogleCloudFlags
 	ModeFlags            *flags.ModeFlags
 	JobVariantsInputFile string
+	JobVariantsBatchSize int
+	AnomalyWebhookURL    string
+	OwnerWebhookURL      string
+	IncidentWebhookURL   string
+
+	JiraURL           string
+	JiraSyncProject   string
+	JiraSyncIssueType string
+	JiraSyncLabels    []string
+	JiraSyncSippyURL  string
+	JiraSyncFixWindow time.Duration
+
+	NotificationSMTPAddr  string
+	NotificationEmailFrom string
+
+	BackfillStart       string
+	BackfillEnd         string
+	BackfillJobRegex    string
+	BackfillConcurrency int
+
+	ReportPath string
+
+	ArtifactCacheDir string
+
+	JobVariantsAudit       bool
+	JobVariantsAuditActor  string
+	JobVariantsAuditReason string
+
+	JobVariantsSchemaFile        string
+	JobVariantsSchemaEnforcement string
+
+	JobVariantsConcurrency int
+	JobVariantsRetries     int
+	JobVariantsBackoff     time.Duration
 }
 
 func NewLoadFlags() *LoadFlags {
@@ -74,10 +121,39 @@ func (f *LoadFlags) BindFlags(fs *pflag.FlagSet) {
 
 	fs.BoolVar(&f.InitDatabase, "init-database", false, "Migrate the DB before loading")
 	fs.BoolVar(&f.LoadOpenShiftCIBigQuery, "load-openshift-ci-bigquery", false, "Load ProwJobs from OpenShift CI BigQuery")
+	fs.BoolVar(&f.LoadIntervals, "load-intervals", false, "Load cluster operator conditions and fired alerts from job run interval files")
+	fs.BoolVar(&f.LoadEventPatterns, "load-event-patterns", false, "Load abnormal event patterns (crashloops, OOMKills, image pull backoffs) from job run interval files")
+	fs.BoolVar(&f.LoadFingerprints, "load-fingerprints", false, "Load job run environment fingerprints (cloud region, instance types, node image versions) from cluster data and gathered nodes")
 	fs.StringArrayVar(&f.Loaders, "loader", []string{"prow", "releases", "jira", "github", "bugs", "test-mapping"}, "Which data sources to use for data loading")
 	fs.StringArrayVar(&f.Releases, "release", f.Releases, "Which releases to load (one per arg instance)")
 	fs.StringArrayVar(&f.Architectures, "arch", f.Architectures, "Which architectures to load (one per arg instance)")
 	fs.StringVar(&f.JobVariantsInputFile, "job-variants-input-file", "expected-job-variants.json", "JSON input file for the job-variants loader")
+	fs.IntVar(&f.JobVariantsBatchSize, "job-variants-merge-batch-size", variantregistry.DefaultMergeBatchSize, "Number of variant updates and deletes the job-variants loader merges into the registry with each statement")
+	fs.IntVar(&f.JobVariantsConcurrency, "job-variants-concurrency", variantregistry.DefaultSyncConcurrency, "Number of batches of inserts the job-variants loader streams into the registry at once; merges and job deletes are run one at a time")
+	fs.IntVar(&f.JobVariantsRetries, "job-variants-retries", variantregistry.DefaultSyncRetries, "Number of times the job-variants loader retries a batch that failed to be written to the registry")
+	fs.DurationVar(&f.JobVariantsBackoff, "job-variants-retry-backoff", variantregistry.DefaultSyncBackoff, "Delay before the job-variants loader first retries a failed batch, doubling on each subsequent retry")
+	fs.BoolVar(&f.JobVariantsAudit, "job-variants-audit", false, "Append every change the job-variants loader makes to the registry to the "+bqcachedclient.JobVariantsAuditTable+" table")
+	fs.StringVar(&f.JobVariantsAuditActor, "job-variants-audit-actor", "job-variants-loader", "Who or what is running the job-variants loader, recorded with each audited change")
+	fs.StringVar(&f.JobVariantsAuditReason, "job-variants-audit-reason", "", "Why the job-variants loader is being run, i.e. the variant rules revision or pull request synced, recorded with each audited change")
+	fs.StringVar(&f.JobVariantsSchemaFile, "job-variants-schema-file", "", "YAML file of the variants jobs may have and their allowed values, overriding the built in schema the job-variants loader validates against")
+	fs.StringVar(&f.JobVariantsSchemaEnforcement, "job-variants-schema-enforcement", string(variantregistry.SchemaEnforcementWarn), "What the job-variants loader does with variant values the schema doesn't allow, one of: off, warn, reject (fail the sync)")
+	fs.StringVar(&f.BackfillStart, "backfill-start", "", "Re-import prow job runs completed after this RFC3339 time, replacing existing data (requires --load-openshift-ci-bigquery)")
+	fs.StringVar(&f.BackfillEnd, "backfill-end", "", "Re-import prow job runs started before this RFC3339 time, defaults to now")
+	fs.StringVar(&f.BackfillJobRegex, "backfill-job-regex", "", "Only re-import prow jobs matching this regex")
+	fs.IntVar(&f.BackfillConcurrency, "backfill-concurrency", prowloader.DefaultBackfillConcurrency, "Number of job runs to re-import at once")
+	fs.StringVar(&f.IncidentWebhookURL, "incident-webhook-url", "", "URL to post provisional incidents to for confirmation when using the mass-failures loader")
+	fs.StringVar(&f.AnomalyWebhookURL, "anomaly-webhook-url", "", "URL to post newly detected pass rate anomalies to when using the anomalies loader")
+	fs.StringVar(&f.OwnerWebhookURL, "owner-change-webhook-url", "", "URL to post jobs whose Owner variant changed to when using the job-variants loader")
+	fs.StringVar(&f.JiraURL, "jira-url", "https://issues.redhat.com", "JIRA instance the jira-sync loader opens issues in, authenticated with the token in JIRA_TOKEN")
+	fs.StringVar(&f.JiraSyncProject, "jira-sync-project", "", "JIRA project the jira-sync loader opens issues for incidents and regressions in")
+	fs.StringVar(&f.JiraSyncIssueType, "jira-sync-issue-type", "Bug", "Type of the issues opened by the jira-sync loader")
+	fs.StringArrayVar(&f.JiraSyncLabels, "jira-sync-label", nil, "Label to add to the issues opened by the jira-sync loader (one per arg instance)")
+	fs.StringVar(&f.JiraSyncSippyURL, "jira-sync-sippy-url", "https://sippy.dptools.openshift.org", "Sippy URL linked from the issues opened by the jira-sync loader")
+	fs.DurationVar(&f.JiraSyncFixWindow, "jira-sync-fix-verification-window", jirasync.DefaultVerificationWindow, "How long after a fix ships in an accepted payload its regression has to clear before the jira-sync loader reopens its triage")
+	fs.StringVar(&f.NotificationSMTPAddr, "notification-smtp-addr", "", "host:port of the SMTP server used to e-mail watchlist notifications, credentials are read from SIPPY_SMTP_USERNAME and SIPPY_SMTP_PASSWORD")
+	fs.StringVar(&f.NotificationEmailFrom, "notification-email-from", "sippy@redhat.com", "From address for e-mailed watchlist notifications")
+	fs.StringVar(&f.ArtifactCacheDir, "artifact-cache-dir", "", "Cache job run artifacts read by the prow loader in this directory, so they can be re-parsed without fetching them again")
+	fs.StringVar(&f.ReportPath, "report-path", "", "Write a JSON report of the run's counts, durations and errors to this file or gs://bucket/object URL")
 }
 
 func NewLoadCommand() *cobra.Command {
@@ -187,9 +263,79 @@ func NewLoadCommand() *cobra.Command {
 					loaders = append(loaders, variantsyncer)
 				}
 
+				// Detect pass rate anomalies, and optionally notify a webhook
+				if l == "anomalies" {
+					if dbErr != nil {
+						return dbErr
+					}
+					loaders = append(loaders, anomalyloader.New(ctx, dbc, f.Releases, f.AnomalyWebhookURL))
+				}
+
+				// Notify watchlist subscribers of threshold crossings and regressions
+				if l == "watchlist" {
+					if dbErr != nil {
+						return dbErr
+					}
+					smtpConfig := notification.SMTPConfig{
+						Addr:     f.NotificationSMTPAddr,
+						From:     f.NotificationEmailFrom,
+						Username: os.Getenv("SIPPY_SMTP_USERNAME"),
+						Password: os.Getenv("SIPPY_SMTP_PASSWORD"),
+					}
+					loaders = append(loaders, watchlistloader.New(ctx, dbc, smtpConfig))
+				}
+
+				// Snapshot pass rates at release milestones that have passed
+				if l == "milestones" {
+					if dbErr != nil {
+						return dbErr
+					}
+					loaders = append(loaders, milestoneloader.New(dbc))
+				}
+
+				// Open JIRA issues for incidents and regressions, and sync their status back into triage state
+				if l == "jira-sync" {
+					if dbErr != nil {
+						return dbErr
+					}
+					if f.JiraSyncProject == "" {
+						return fmt.Errorf("--jira-sync-project is required for the jira-sync loader")
+					}
+					client, err := jirasync.NewClient(f.JiraURL, os.Getenv("JIRA_TOKEN"), f.JiraSyncProject, f.JiraSyncIssueType)
+					if err != nil {
+						return errors.Wrap(err, "error creating jira client")
+					}
+					loaders = append(loaders, jirasyncloader.New(ctx, dbc, client, jirasync.Config{
+						Labels:             f.JiraSyncLabels,
+						SippyURL:           f.JiraSyncSippyURL,
+						VerificationWindow: f.JiraSyncFixWindow,
+					}))
+				}
+
+				// Open provisional incidents for failure spikes across many jobs, and optionally notify a webhook
+				if l == "mass-failures" {
+					if dbErr != nil {
+						return dbErr
+					}
+					loaders = append(loaders, massfailureloader.New(ctx, dbc, f.IncidentWebhookURL))
+				}
+
+				// Classify test failures that passed elsewhere in the same payload as flakes
+				if l == "payload-flakes" {
+					if dbErr != nil {
+						return dbErr
+					}
+					loaders = append(loaders, payloadflakeloader.New(dbc))
+				}
+
 				// Job Variants Loader from BigQuery
 				if l == "job-variants" {
-					variantsLoader, err := f.jobVariantsLoader(ctx)
+					// the database is only used to record owner changes, so is optional
+					var variantsDB *db.DB
+					if dbErr == nil {
+						variantsDB = dbc
+					}
+					variantsLoader, err := f.jobVariantsLoader(ctx, variantsDB)
 					if err != nil {
 						return err
 					}
@@ -204,6 +350,14 @@ func NewLoadCommand() *cobra.Command {
 			if len(l.Errors()) > 0 {
 				allErrs = append(allErrs, l.Errors()...)
 			}
+			if f.ReportPath != "" {
+				if err := f.writeReport(ctx, l.Report()); err != nil {
+					log.WithError(err).Error("error writing run report")
+					allErrs = append(allErrs, err)
+				} else {
+					log.WithField("path", f.ReportPath).Info("wrote run report")
+				}
+			}
 
 			elapsed := time.Since(start)
 			log.WithField("elapsed", elapsed).Info("database load complete")
@@ -228,7 +382,23 @@ func NewLoadCommand() *cobra.Command {
 	return cmd
 }
 
-func (f *LoadFlags) jobVariantsLoader(ctx context.Context) (dataloader.DataLoader, error) {
+func (f *LoadFlags) writeReport(ctx context.Context, report dataloader.RunReport) error {
+	var gcsClient *storage.Client
+	if _, _, ok := dataloader.ParseGCSPath(f.ReportPath); ok {
+		var err error
+		gcsClient, err = gcs.NewGCSClient(ctx,
+			f.GoogleCloudFlags.ServiceAccountCredentialFile,
+			f.GoogleCloudFlags.OAuthClientCredentialFile,
+		)
+		if err != nil {
+			return errors.WithMessage(err, "could not get GCS client")
+		}
+		defer gcsClient.Close()
+	}
+	return dataloader.WriteReport(ctx, report, f.ReportPath, gcsClient)
+}
+
+func (f *LoadFlags) jobVariantsLoader(ctx context.Context, dbc *db.DB) (dataloader.DataLoader, error) {
 	bigQueryClient, err := bigquery.NewClient(ctx, f.BigQueryFlags.BigQueryProject,
 		option.WithCredentialsFile(f.GoogleCloudFlags.ServiceAccountCredentialFile))
 	if err != nil {
@@ -258,12 +428,76 @@ func (f *LoadFlags) jobVariantsLoader(ctx context.Context) (dataloader.DataLoade
 	}
 
 	log.Infof("Loaded expected job variant data from: %s", inputFile)
+	schema, enforcement, err := variantSchema(f.JobVariantsSchemaFile, f.JobVariantsSchemaEnforcement)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &bqcachedclient.Client{BQ: bigQueryClient, Dataset: f.BigQueryFlags.BigQueryDataset}
+	tables := []string{bqcachedclient.JobVariantsTable}
+	if f.JobVariantsAudit {
+		tables = append(tables, bqcachedclient.JobVariantsAuditTable)
+	}
+	if f.BigQueryFlags.CreateTables {
+		if _, err := client.CreateMissingTables(ctx, tables); err != nil {
+			return nil, err
+		}
+	}
+	if f.BigQueryFlags.VerifySchemas {
+		if err := client.VerifySchemas(ctx, tables); err != nil {
+			return nil, err
+		}
+	}
 	syncer := variantregistry.NewJobVariantsLoader(bigQueryClient, f.BigQueryFlags.BigQueryProject,
-		f.BigQueryFlags.BigQueryDataset, "job_variants", expectedVariants)
+		f.BigQueryFlags.BigQueryDataset, bqcachedclient.JobVariantsTable, expectedVariants,
+		dbc, f.OwnerWebhookURL)
+	syncer.SetMergeBatchSize(f.JobVariantsBatchSize)
+	syncer.SetConcurrency(f.JobVariantsConcurrency)
+	syncer.SetRetries(f.JobVariantsRetries, f.JobVariantsBackoff)
+	syncer.SetVariantSchema(schema, enforcement)
+	if f.JobVariantsAudit {
+		syncer.EnableAudit(bqcachedclient.JobVariantsAuditTable, f.JobVariantsAuditActor, f.JobVariantsAuditReason)
+	}
 	return syncer, nil
 
 }
 
+// backfillOptions returns the prow loader backfill configuration, or nil if not backfilling.
+func (f *LoadFlags) backfillOptions() (*prowloader.BackfillOptions, error) {
+	if f.BackfillStart == "" {
+		if f.BackfillEnd != "" || f.BackfillJobRegex != "" {
+			return nil, fmt.Errorf("--backfill-start is required to backfill")
+		}
+		return nil, nil
+	}
+	if !f.LoadOpenShiftCIBigQuery {
+		return nil, fmt.Errorf("backfill requires --load-openshift-ci-bigquery")
+	}
+
+	opts := &prowloader.BackfillOptions{
+		End:         time.Now(),
+		Concurrency: f.BackfillConcurrency,
+	}
+	var err error
+	if opts.Start, err = time.Parse(time.RFC3339, f.BackfillStart); err != nil {
+		return nil, errors.WithMessage(err, "invalid --backfill-start")
+	}
+	if f.BackfillEnd != "" {
+		if opts.End, err = time.Parse(time.RFC3339, f.BackfillEnd); err != nil {
+			return nil, errors.WithMessage(err, "invalid --backfill-end")
+		}
+	}
+	if !opts.End.After(opts.Start) {
+		return nil, fmt.Errorf("--backfill-end must be after --backfill-start")
+	}
+	if f.BackfillJobRegex != "" {
+		if opts.JobRegex, err = regexp.Compile(f.BackfillJobRegex); err != nil {
+			return nil, errors.WithMessage(err, "invalid --backfill-job-regex")
+		}
+	}
+	return opts, nil
+}
+
 func (f *LoadFlags) prowLoader(ctx context.Context, dbc *db.DB, sippyConfig *v1.SippyConfig) (dataloader.DataLoader, error) {
 	gcsClient, err := gcs.NewGCSClient(ctx,
 		f.GoogleCloudFlags.ServiceAccountCredentialFile,
@@ -297,7 +531,12 @@ func (f *LoadFlags) prowLoader(ctx context.Context, dbc *db.DB, sippyConfig *v1.
 		return nil, err
 	}
 
-	return prowloader.New(
+	backfill, err := f.backfillOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	pl := prowloader.New(
 		ctx,
 		dbc,
 		gcsClient,
@@ -308,5 +547,15 @@ func (f *LoadFlags) prowLoader(ctx context.Context, dbc *db.DB, sippyConfig *v1.
 		f.ModeFlags.GetSyntheticTestManager(),
 		f.Releases,
 		sippyConfig,
-		ghCommenter), nil
+		ghCommenter,
+		f.LoadIntervals,
+		f.LoadEventPatterns)
+	if backfill != nil {
+		pl.EnableBackfill(*backfill)
+	}
+	if f.LoadFingerprints {
+		pl.EnableFingerprints()
+	}
+	pl.SetArtifactCacheDir(f.ArtifactCacheDir)
+	return pl, nil
 }
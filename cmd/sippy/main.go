@@ -3,6 +3,8 @@ package main
 import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/openshift/sippy/pkg/logging"
 )
 
 var logLevel = "info"
@@ -19,7 +21,7 @@ platform, etc).`,
 		if err != nil {
 			log.WithError(err).Fatal("cannot parse log-level")
 		}
-		log.SetLevel(level)
+		logging.SetDefaultLevel(level)
 		log.Debug("debug logging enabled")
 	},
 }
@@ -39,7 +41,15 @@ func main() {
 		NewSnapshotCommand(),
 		NewRefreshCommand(),
 		NewLoadJobVariantsCommand(),
+		NewVariantsCommand(),
 		NewComponentReadinessCommand(),
+		NewIntegrityCheckCommand(),
+		NewReparseCommand(),
+		NewQueryCommand(),
+		NewBigQuerySchemasCommand(),
+		NewPurgeJobsCommand(),
+		NewAuditExportCommand(),
+		NewE2ECheckCommand(),
 	)
 
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/jobpurge"
+)
+
+type PurgeJobsFlags struct {
+	BigQueryFlags    *flags.BigQueryFlags
+	DBFlags          *flags.PostgresFlags
+	GoogleCloudFlags *flags.GoogleCloudFlags
+
+	Release     string
+	JobRegex    string
+	Variants    []string
+	NoRunsSince string
+	Confirm     string
+}
+
+func NewPurgeJobsFlags() *PurgeJobsFlags {
+	return &PurgeJobsFlags{
+		BigQueryFlags:    flags.NewBigQueryFlags(),
+		DBFlags:          flags.NewPostgresDatabaseFlags(),
+		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
+	}
+}
+
+func (f *PurgeJobsFlags) BindFlags(fs *pflag.FlagSet) {
+	f.BigQueryFlags.BindFlags(fs)
+	f.DBFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+
+	fs.StringVar(&f.Release, "release", "", "Only purge jobs of this release")
+	fs.StringVar(&f.JobRegex, "job-regex", "", "Only purge jobs whose name matches this Postgres regex")
+	fs.StringArrayVar(&f.Variants, "variant", nil, "Only purge jobs with this variant, i.e. Owner:eng, may be repeated")
+	fs.StringVar(&f.NoRunsSince, "no-runs-since", "", "Only purge jobs without runs since this date (YYYY-MM-DD)")
+	fs.StringVar(&f.Confirm, "confirm", "", "Purge the jobs, using the confirmation token printed by a dry run with the same selector")
+}
+
+func (f *PurgeJobsFlags) selector() (jobpurge.Selector, error) {
+	selector := jobpurge.Selector{
+		Release:  f.Release,
+		JobRegex: f.JobRegex,
+		Variants: f.Variants,
+	}
+	if f.NoRunsSince != "" {
+		t, err := time.Parse("2006-01-02", f.NoRunsSince)
+		if err != nil {
+			return selector, errors.WithMessage(err, "invalid --no-runs-since")
+		}
+		selector.NoRunsSince = &t
+	}
+	return selector, selector.Validate()
+}
+
+func NewPurgeJobsCommand() *cobra.Command {
+	f := NewPurgeJobsFlags()
+
+	cmd := &cobra.Command{
+		Use:   "purge-jobs",
+		Short: "Delete all data for the selected jobs from Postgres and the BigQuery variant registry",
+		Long: `Delete all data for jobs matching a selector, i.e. long-removed periodics of an old release: the jobs,
+their runs and everything imported for them in Postgres, and their variants in the BigQuery variant registry when
+a service account is given. The junit and jobs tables in BigQuery are written by other CI tooling and are not
+modified.
+
+Without --confirm, this is a dry run printing what would be deleted and a confirmation token. Re-run with
+--confirm <token> to purge. The token only matches while the selected jobs and row counts are unchanged.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			selector, err := f.selector()
+			if err != nil {
+				return err
+			}
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			var bqc *bqcachedclient.Client
+			if f.GoogleCloudFlags.ServiceAccountCredentialFile != "" {
+				bqc, err = f.BigQueryFlags.GetBigQueryClient(ctx, nil, f.GoogleCloudFlags.ServiceAccountCredentialFile)
+				if err != nil {
+					return errors.WithMessage(err, "couldn't get bigquery client")
+				}
+			} else {
+				log.Warning("no service account given, the BigQuery variant registry will not be purged")
+			}
+
+			var plan *jobpurge.Plan
+			if f.Confirm == "" {
+				plan, err = jobpurge.NewPlan(ctx, dbc, bqc, selector)
+			} else {
+				plan, err = jobpurge.Purge(ctx, dbc, bqc, selector, f.Confirm)
+			}
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(plan); err != nil {
+				return err
+			}
+			if !plan.Purged {
+				fmt.Fprintf(os.Stderr, "dry run, re-run with --confirm %s to purge\n", plan.Token)
+			}
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/client"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/filter"
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+const (
+	queryOutputTable = "table"
+	queryOutputJSON  = "json"
+)
+
+type QueryFlags struct {
+	DBFlags  *flags.PostgresFlags
+	SippyURL string
+	Token    string
+	Release  string
+	Output   string
+	Limit    int
+	PassRate string
+}
+
+func NewQueryFlags() *QueryFlags {
+	return &QueryFlags{
+		DBFlags:  flags.NewPostgresDatabaseFlags(),
+		SippyURL: os.Getenv("SIPPY_URL"),
+		Token:    os.Getenv("SIPPY_TOKEN"),
+		Output:   queryOutputTable,
+		Limit:    25,
+	}
+}
+
+func (f *QueryFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	fs.StringVar(&f.SippyURL, "sippy-url", f.SippyURL,
+		"URL of a running sippy server to query, i.e. https://sippy.dptools.openshift.org. If empty, results are computed from the database")
+	fs.StringVar(&f.Token, "token", f.Token, "Bearer token for sippy servers behind an authenticating proxy")
+	fs.StringVar(&f.Release, "release", f.Release, "Release to query, i.e. 4.16")
+	fs.StringVarP(&f.Output, "output", "o", f.Output, "Output format (table, json)")
+	fs.IntVar(&f.Limit, "limit", f.Limit, "Maximum number of rows to return, 0 for all")
+	fs.StringVar(&f.PassRate, "pass-rate", f.PassRate, "Pass rate mode for tests (strict, lenient)")
+}
+
+func (f *QueryFlags) Validate() error {
+	if f.Release == "" {
+		return fmt.Errorf("--release is required")
+	}
+	if f.Output != queryOutputTable && f.Output != queryOutputJSON {
+		return fmt.Errorf("unknown output format %q, must be %s or %s", f.Output, queryOutputTable, queryOutputJSON)
+	}
+	if _, err := apitype.ParsePassRateMode(f.PassRate); err != nil {
+		return err
+	}
+	return nil
+}
+
+// querySource answers the query subcommands, either from a sippy server or directly from the database. Results
+// are sorted by current pass percentage ascending, so the least healthy rows are first.
+type querySource interface {
+	Tests(ctx context.Context, release, name string, mode apitype.PassRateMode, limit int) ([]apitype.Test, error)
+	Jobs(ctx context.Context, release, name string, limit int) ([]apitype.JobV2, error)
+	Variants(ctx context.Context, release, name string, limit int) ([]apitype.Variant, error)
+}
+
+func (f *QueryFlags) source() (querySource, error) {
+	if f.SippyURL != "" {
+		c, err := client.New(f.SippyURL, client.Options{Token: f.Token})
+		if err != nil {
+			return nil, err
+		}
+		return &serverSource{client: c}, nil
+	}
+
+	dbc, err := f.DBFlags.GetDBClient()
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't connect to the database, set --sippy-url to query a server instead")
+	}
+	reportEnd := time.Now()
+	if pinned := f.DBFlags.GetPinnedTime(); pinned != nil {
+		reportEnd = *pinned
+	}
+	return &dbSource{dbc: dbc, reportEnd: reportEnd}, nil
+}
+
+func NewQueryCommand() *cobra.Command {
+	f := NewQueryFlags()
+
+	cmd := &cobra.Command{
+		Use:   "query",
+		Short: "Query test, job and variant health from a sippy server or the database",
+		Long: `Answer common questions from the terminal, such as the pass rate of a test or the health of a job, by
+calling a running sippy server with --sippy-url (or SIPPY_URL), or computing the reports directly against the
+database. Names are matched by substring, and results are sorted with the lowest current pass rate first.`,
+	}
+
+	cmd.AddCommand(
+		newQuerySubcommand(f, "tests [name]", "Report current and previous pass rates of tests", runTestsQuery),
+		newQuerySubcommand(f, "jobs [name]", "Report current and previous pass rates of jobs", runJobsQuery),
+		newQuerySubcommand(f, "variants [name]", "Report current and previous pass rates of jobs by variant", runVariantsQuery),
+	)
+	f.BindFlags(cmd.PersistentFlags())
+
+	return cmd
+}
+
+func newQuerySubcommand(f *QueryFlags, use, short string,
+	run func(ctx context.Context, f *QueryFlags, src querySource, name string, out io.Writer) error) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := f.Validate(); err != nil {
+				return err
+			}
+			src, err := f.source()
+			if err != nil {
+				return err
+			}
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return run(cmd.Context(), f, src, name, cmd.OutOrStdout())
+		},
+	}
+}
+
+func runTestsQuery(ctx context.Context, f *QueryFlags, src querySource, name string, out io.Writer) error {
+	tests, err := src.Tests(ctx, f.Release, name, apitype.PassRateMode(f.PassRate), f.Limit)
+	if err != nil {
+		return errors.WithMessage(err, "couldn't query tests")
+	}
+	if f.Output == queryOutputJSON {
+		return writeQueryJSON(out, tests)
+	}
+
+	rows := make([][]string, 0, len(tests))
+	for _, t := range tests {
+		rows = append(rows, []string{
+			t.Name,
+			fmt.Sprintf("%.2f%%", t.CurrentPassPercentage),
+			fmt.Sprintf("%d", t.CurrentRuns),
+			fmt.Sprintf("%.2f%%", t.PreviousPassPercentage),
+			fmt.Sprintf("%d", t.PreviousRuns),
+			fmt.Sprintf("%+.2f", t.NetImprovement),
+		})
+	}
+	return writeQueryTable(out, []string{"NAME", "PASS", "RUNS", "PREVIOUS PASS", "PREVIOUS RUNS", "NET"}, rows)
+}
+
+func runJobsQuery(ctx context.Context, f *QueryFlags, src querySource, name string, out io.Writer) error {
+	jobs, err := src.Jobs(ctx, f.Release, name, f.Limit)
+	if err != nil {
+		return errors.WithMessage(err, "couldn't query jobs")
+	}
+	if f.Output == queryOutputJSON {
+		return writeQueryJSON(out, jobs)
+	}
+
+	rows := make([][]string, 0, len(jobs))
+	for _, j := range jobs {
+		variants := make([]string, 0, len(j.Variants))
+		for k, v := range j.Variants {
+			variants = append(variants, k+":"+v)
+		}
+		sort.Strings(variants)
+		rows = append(rows, []string{
+			j.Name,
+			fmt.Sprintf("%.2f%%", j.CurrentPassPercentage),
+			fmt.Sprintf("%d", j.CurrentRuns),
+			fmt.Sprintf("%.2f%%", j.PreviousPassPercentage),
+			fmt.Sprintf("%d", j.PreviousRuns),
+			fmt.Sprintf("%d", j.OpenBugs),
+			strings.Join(variants, ","),
+		})
+	}
+	return writeQueryTable(out, []string{"NAME", "PASS", "RUNS", "PREVIOUS PASS", "PREVIOUS RUNS", "OPEN BUGS", "VARIANTS"}, rows)
+}
+
+func runVariantsQuery(ctx context.Context, f *QueryFlags, src querySource, name string, out io.Writer) error {
+	variants, err := src.Variants(ctx, f.Release, name, f.Limit)
+	if err != nil {
+		return errors.WithMessage(err, "couldn't query variants")
+	}
+	if f.Output == queryOutputJSON {
+		return writeQueryJSON(out, variants)
+	}
+
+	rows := make([][]string, 0, len(variants))
+	for _, v := range variants {
+		rows = append(rows, []string{
+			v.Name,
+			fmt.Sprintf("%.2f%%", v.CurrentPassPercentage),
+			fmt.Sprintf("%d", v.CurrentRuns),
+			fmt.Sprintf("%.2f%%", v.PreviousPassPercentage),
+			fmt.Sprintf("%d", v.PreviousRuns),
+			fmt.Sprintf("%+.2f", v.NetImprovement),
+		})
+	}
+	return writeQueryTable(out, []string{"NAME", "PASS", "RUNS", "PREVIOUS PASS", "PREVIOUS RUNS", "NET"}, rows)
+}
+
+func writeQueryJSON(out io.Writer, v interface{}) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func writeQueryTable(out io.Writer, header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// nameFilter matches rows whose name contains name, or all rows if it is empty.
+func nameFilter(name string) *filter.Filter {
+	if name == "" {
+		return nil
+	}
+	return &filter.Filter{Items: []filter.FilterItem{{Field: "name", Operator: filter.OperatorContains, Value: name}}}
+}
+
+// serverSource answers queries from a running sippy server.
+type serverSource struct {
+	client *client.Client
+}
+
+func (s *serverSource) listOptions(name string, limit int) *client.ListOptions {
+	return &client.ListOptions{
+		Filter:    nameFilter(name),
+		SortField: "current_pass_percentage",
+		Sort:      apitype.SortAscending,
+		Limit:     limit,
+	}
+}
+
+func (s *serverSource) Tests(ctx context.Context, release, name string, mode apitype.PassRateMode, limit int) ([]apitype.Test, error) {
+	return s.client.Tests(ctx, release, mode, s.listOptions(name, limit))
+}
+
+func (s *serverSource) Jobs(ctx context.Context, release, name string, limit int) ([]apitype.JobV2, error) {
+	return s.client.Jobs(ctx, release, s.listOptions(name, limit))
+}
+
+func (s *serverSource) Variants(ctx context.Context, release, name string, limit int) ([]apitype.Variant, error) {
+	return s.client.Variants(ctx, release, s.listOptions(name, limit))
+}
+
+// dbSource computes the same reports as the server directly from the database.
+type dbSource struct {
+	dbc       *db.DB
+	reportEnd time.Time
+}
+
+func (s *dbSource) Tests(_ context.Context, release, name string, mode apitype.PassRateMode, limit int) ([]apitype.Test, error) {
+	tests, _, err := api.BuildTestsResults(s.dbc, release, "", true, false, mode, &filter.FilterOptions{Filter: nameFilter(name)})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tests, func(i, j int) bool {
+		return tests[i].CurrentPassPercentage < tests[j].CurrentPassPercentage
+	})
+	return limitRows(tests, limit), nil
+}
+
+func (s *dbSource) Jobs(_ context.Context, release, name string, limit int) ([]apitype.JobV2, error) {
+	filterOpts := &filter.FilterOptions{Filter: nameFilter(name)}
+	if filterOpts.Filter == nil {
+		filterOpts.Filter = &filter.Filter{}
+	}
+	jobs, err := api.JobReportsFromDB(s.dbc, release, "", filterOpts, time.Time{}, time.Time{}, time.Time{}, s.reportEnd)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].CurrentPassPercentage < jobs[j].CurrentPassPercentage
+	})
+
+	results := make([]apitype.JobV2, 0, len(jobs))
+	for _, job := range limitRows(jobs, limit) {
+		results = append(results, apitype.JobV2{Job: job, Variants: api.TypedVariants(job.Variants)})
+	}
+	return results, nil
+}
+
+func (s *dbSource) Variants(_ context.Context, release, name string, limit int) ([]apitype.Variant, error) {
+	start := s.reportEnd.Add(-14 * 24 * time.Hour)
+	boundary := s.reportEnd.Add(-7 * 24 * time.Hour)
+	variants, err := query.VariantReports(s.dbc, release, start, boundary, s.reportEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]apitype.Variant, 0, len(variants))
+	for _, v := range variants {
+		if strings.Contains(v.Name, name) {
+			results = append(results, v)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].CurrentPassPercentage < results[j].CurrentPassPercentage
+	})
+	return limitRows(results, limit), nil
+}
+
+func limitRows[T any](rows []T, limit int) []T {
+	if limit > 0 && len(rows) > limit {
+		return rows[:limit]
+	}
+	return rows
+}
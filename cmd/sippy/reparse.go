@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/dataloader/prowloader"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+	"github.com/openshift/sippy/pkg/flags"
+)
+
+type ReparseFlags struct {
+	DBFlags          *flags.PostgresFlags
+	GoogleCloudFlags *flags.GoogleCloudFlags
+	ModeFlags        *flags.ModeFlags
+
+	ArtifactCacheDir string
+	Start            string
+	JobRegex         string
+	Limit            int
+	Concurrency      int
+}
+
+func NewReparseFlags() *ReparseFlags {
+	return &ReparseFlags{
+		DBFlags:          flags.NewPostgresDatabaseFlags(),
+		GoogleCloudFlags: flags.NewGoogleCloudFlags(),
+		ModeFlags:        flags.NewModeFlags(),
+	}
+}
+
+func (f *ReparseFlags) BindFlags(fs *pflag.FlagSet) {
+	f.DBFlags.BindFlags(fs)
+	f.GoogleCloudFlags.BindFlags(fs)
+	f.ModeFlags.BindFlags(fs)
+
+	fs.StringVar(&f.ArtifactCacheDir, "artifact-cache-dir", "", "Read job run artifacts from this directory when cached there by a previous load or re-parse, caching any fetched from GCS")
+	fs.StringVar(&f.Start, "start", "", "Only re-parse job runs that started after this RFC3339 time")
+	fs.StringVar(&f.JobRegex, "job-regex", "", "Only re-parse runs of jobs matching this regex")
+	fs.IntVar(&f.Limit, "limit", 0, "Re-parse at most this many job runs, most recent first")
+	fs.IntVar(&f.Concurrency, "concurrency", prowloader.DefaultBackfillConcurrency, "Number of job runs to re-parse at once")
+}
+
+func (f *ReparseFlags) reparseOptions() (prowloader.ReparseOptions, error) {
+	opts := prowloader.ReparseOptions{
+		Limit:       f.Limit,
+		Concurrency: f.Concurrency,
+	}
+	var err error
+	if f.Start != "" {
+		if opts.Start, err = time.Parse(time.RFC3339, f.Start); err != nil {
+			return opts, errors.WithMessage(err, "invalid --start")
+		}
+	}
+	if f.JobRegex != "" {
+		if opts.JobRegex, err = regexp.Compile(f.JobRegex); err != nil {
+			return opts, errors.WithMessage(err, "invalid --job-regex")
+		}
+	}
+	return opts, nil
+}
+
+func NewReparseCommand() *cobra.Command {
+	f := NewReparseFlags()
+
+	cmd := &cobra.Command{
+		Use:   "reparse",
+		Short: "Re-parse the junit artifacts of job runs imported with an older parser version",
+		Long: fmt.Sprintf(`Re-import the test results of job runs imported with a junit parser version older than the current
+version (%d), replacing their existing results. This applies fixes to the conversion of junit artifacts to runs
+that were already imported, without a full reload. Artifacts are fetched from GCS again, or read from
+--artifact-cache-dir if a previous load or re-parse cached them there.
+
+Test results marked as payload flakes are replaced too, re-run the payload-flakes loader afterwards.`, prowloader.JunitParserVersion),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := f.reparseOptions()
+			if err != nil {
+				return err
+			}
+
+			dbc, err := f.DBFlags.GetDBClient()
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			gcsClient, err := gcs.NewGCSClient(ctx,
+				f.GoogleCloudFlags.ServiceAccountCredentialFile,
+				f.GoogleCloudFlags.OAuthClientCredentialFile,
+			)
+			if err != nil {
+				return errors.WithMessage(err, "couldn't get GCS client")
+			}
+
+			pl := prowloader.New(ctx, dbc, gcsClient, nil, f.GoogleCloudFlags.StorageBucket, nil,
+				f.ModeFlags.GetVariantManager(ctx, nil), f.ModeFlags.GetSyntheticTestManager(),
+				nil, nil, nil, false, false)
+			pl.SetArtifactCacheDir(f.ArtifactCacheDir)
+
+			pl.Reparse(opts)
+			if errs := pl.Errors(); len(errs) > 0 {
+				for _, err := range errs {
+					log.WithError(err).Warning("re-parse error")
+				}
+				return fmt.Errorf("%d job runs could not be re-parsed", len(errs))
+			}
+			return nil
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -2,17 +2,23 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io/fs"
 	"net/http"
+	"os"
 	"time"
 
 	"cloud.google.com/go/storage"
 	resources "github.com/openshift/sippy"
 	"github.com/openshift/sippy/pkg/apis/cache"
+	"github.com/openshift/sippy/pkg/artifacturls"
 	"github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/componentreadiness/notifier"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/featureflags"
 	"github.com/openshift/sippy/pkg/flags"
+	"github.com/openshift/sippy/pkg/reportqueue"
 	"github.com/openshift/sippy/pkg/sippyserver"
 	"github.com/openshift/sippy/pkg/sippyserver/metrics"
 	"github.com/openshift/sippy/pkg/util"
@@ -30,11 +36,23 @@ type ServerFlags struct {
 	GoogleCloudFlags        *flags.GoogleCloudFlags
 	ModeFlags               *flags.ModeFlags
 	ProwFlags               *flags.ProwFlags
+	ArtifactURLFlags        *flags.ArtifactURLFlags
 	ComponentReadinessFlags *flags.ComponentReadinessFlags
 
 	ListenAddr               string
 	MetricsAddr              string
+	GRPCAddr                 string
 	MaintainRegressionTables bool
+	ReadinessMaxDataAge      time.Duration
+	FeatureFlagsFile         string
+	AccessLogRetention       time.Duration
+	EnableJobPurgeAPI        bool
+	EnableExternalRunsAPI    bool
+	EnableManualResultsAPI   bool
+	EnableWriteAPI           bool
+	AdminUsers               []string
+	EnqueueReports           bool
+	ReportWorker             bool
 }
 
 func NewServerFlags() *ServerFlags {
@@ -45,6 +63,7 @@ func NewServerFlags() *ServerFlags {
 		GoogleCloudFlags:        flags.NewGoogleCloudFlags(),
 		ModeFlags:               flags.NewModeFlags(),
 		ProwFlags:               flags.NewProwFlags(),
+		ArtifactURLFlags:        flags.NewArtifactURLFlags(),
 		ComponentReadinessFlags: flags.NewComponentReadinessFlags(),
 		ListenAddr:              ":8080",
 		MetricsAddr:             ":2112",
@@ -55,18 +74,34 @@ func (f *ServerFlags) BindFlags(flagSet *pflag.FlagSet) {
 	f.BigQueryFlags.BindFlags(flagSet)
 	f.CacheFlags.BindFlags(flagSet)
 	f.DBFlags.BindFlags(flagSet)
+	f.DBFlags.BindReplicaFlags(flagSet)
 	f.GoogleCloudFlags.BindFlags(flagSet)
 	f.ModeFlags.BindFlags(flagSet)
 	f.ProwFlags.BindFlags(flagSet)
+	f.ArtifactURLFlags.BindFlags(flagSet)
 	f.ComponentReadinessFlags.BindFlags(flagSet)
 
 	flagSet.StringVar(&f.ListenAddr, "listen", f.ListenAddr, "The address to serve analysis reports on (default :8080)")
 	flagSet.StringVar(&f.MetricsAddr, "listen-metrics", f.MetricsAddr, "The address to serve prometheus metrics on (default :2112)")
+	flagSet.StringVar(&f.GRPCAddr, "listen-grpc", f.GRPCAddr, "The address to serve the gRPC API on, disabled if empty")
 	flagSet.BoolVar(&f.MaintainRegressionTables, "maintain-regression-tables", false, "Enable maintenance of open regressions table in bigquery.")
+	flagSet.StringVar(&f.FeatureFlagsFile, "feature-flags", "", "Optional yaml file declaring feature flags that gate new analyses")
+	flagSet.DurationVar(&f.AccessLogRetention, "access-log-retention", 0, "Record API requests in the database for usage analysis, and keep them this long. Disabled if 0")
+	flagSet.BoolVar(&f.EnableJobPurgeAPI, "enable-job-purge-api", false, "Enable the API deleting all data for selected jobs")
+	flagSet.BoolVar(&f.EnableExternalRunsAPI, "enable-external-job-runs-api", false, "Enable the API ingesting job runs from CI systems other than Prow")
+	flagSet.BoolVar(&f.EnableManualResultsAPI, "enable-manual-test-results-api", false, "Enable the API recording test results QE ran by hand")
+	flagSet.BoolVar(&f.EnableWriteAPI, "enable-write-api", false, "Enable creating, updating and deleting incidents, watchlist subscriptions, lanes and dashboards through the API")
+	flagSet.StringSliceVar(&f.AdminUsers, "admin-user", nil, "User, as authenticated by the proxy in front of sippy, allowed to change feature flags and log levels through the API. May be repeated")
+	flagSet.BoolVar(&f.EnqueueReports, "enqueue-reports", false, "Hand component report generation to processes run with --report-worker instead of generating reports in this one")
+	flagSet.BoolVar(&f.ReportWorker, "report-worker", false, "Generate reports queued by processes run with --enqueue-reports instead of serving the API")
+	flagSet.DurationVar(&f.ReadinessMaxDataAge, "readiness-max-data-age", sippyserver.DefaultReadinessMaxDataAge, "Age of the newest imported job run after which /readyz reports data as stale")
 }
 
 func (f *ServerFlags) Validate() error {
 	// TODO: Validate other flags
+	if f.EnqueueReports && f.ReportWorker {
+		return fmt.Errorf("--enqueue-reports and --report-worker are mutually exclusive")
+	}
 	return f.ProwFlags.Validate()
 }
 
@@ -81,6 +116,12 @@ func NewServeCommand() *cobra.Command {
 				return errors.WithMessage(err, "error validating options")
 			}
 
+			artifactURLs, err := f.ArtifactURLFlags.GetBuilder(f.ProwFlags.URL, f.GoogleCloudFlags.StorageBucket)
+			if err != nil {
+				return errors.WithMessage(err, "invalid artifact URL template")
+			}
+			artifacturls.SetDefault(artifactURLs)
+
 			dbc, err := f.DBFlags.GetDBClient()
 			if err != nil {
 				return errors.WithMessage(err, "couldn't get DB client")
@@ -129,6 +170,7 @@ func NewServeCommand() *cobra.Command {
 				log.WithError(err).Fatal("unable to load views")
 
 			}
+			f.ComponentReadinessFlags.SetReportMemoryLimits()
 
 			server := sippyserver.NewServer(
 				f.ModeFlags.GetServerMode(),
@@ -147,8 +189,54 @@ func NewServeCommand() *cobra.Command {
 				f.ComponentReadinessFlags.CRTimeRoundingFactor,
 				views,
 			)
+			server.SetReadinessMaxDataAge(f.ReadinessMaxDataAge)
+
+			var flagDefinitions []featureflags.Flag
+			if f.FeatureFlagsFile != "" {
+				flagDefinitions, err = featureflags.LoadConfig(f.FeatureFlagsFile)
+				if err != nil {
+					log.WithError(err).Fatal("unable to load feature flags")
+				}
+			}
+			featureFlags, err := featureflags.NewManager(flagDefinitions)
+			if err != nil {
+				log.WithError(err).Fatal("invalid feature flag definition found")
+			}
+			server.SetFeatureFlags(featureFlags)
+
+			if f.EnableJobPurgeAPI {
+				server.EnableJobPurge()
+			}
+
+			if f.EnableExternalRunsAPI {
+				server.EnableExternalJobRuns()
+			}
+
+			if f.EnableManualResultsAPI {
+				server.EnableManualTestResults()
+			}
+
+			if f.EnableWriteAPI {
+				server.EnableWriteAPI()
+			}
+
+			if len(f.AdminUsers) > 0 {
+				server.SetAdminUsers(f.AdminUsers)
+			}
+
+			if f.EnqueueReports {
+				server.EnqueueReports()
+			}
+
+			if f.AccessLogRetention > 0 {
+				accessLogStore := sippyserver.NewAccessLogStore(dbc, f.AccessLogRetention)
+				go accessLogStore.Run(context.Background())
+				server.SetAccessLogStore(accessLogStore)
+			}
 
 			if f.MetricsAddr != "" {
+				viewNotifier := notifier.New(f.ComponentReadinessFlags.SMTPConfig())
+
 				// Do an immediate metrics update
 				err = metrics.RefreshMetricsDB(dbc,
 					bigQueryClient,
@@ -158,7 +246,8 @@ func NewServeCommand() *cobra.Command {
 					util.GetReportEnd(pinnedDateTime),
 					cache.RequestOptions{CRTimeRoundingFactor: f.ComponentReadinessFlags.CRTimeRoundingFactor},
 					views.ComponentReadiness,
-					f.MaintainRegressionTables)
+					f.MaintainRegressionTables,
+					viewNotifier)
 				if err != nil {
 					log.WithError(err).Error("error refreshing metrics")
 				}
@@ -180,7 +269,8 @@ func NewServeCommand() *cobra.Command {
 								util.GetReportEnd(pinnedDateTime),
 								cache.RequestOptions{CRTimeRoundingFactor: f.ComponentReadinessFlags.CRTimeRoundingFactor},
 								views.ComponentReadiness,
-								f.MaintainRegressionTables)
+								f.MaintainRegressionTables,
+								viewNotifier)
 							if err != nil {
 								log.WithError(err).Error("error refreshing metrics")
 							}
@@ -201,6 +291,23 @@ func NewServeCommand() *cobra.Command {
 				}()
 			}
 
+			if f.ReportWorker {
+				hostname, err := os.Hostname()
+				if err != nil {
+					return errors.WithMessage(err, "couldn't get hostname to identify the report worker")
+				}
+				reportqueue.NewWorker(dbc, hostname, server.ReportJobHandlers()).Run(context.Background())
+				return nil
+			}
+
+			if f.GRPCAddr != "" {
+				go func() {
+					if err := server.ServeGRPC(f.GRPCAddr); err != nil {
+						log.WithError(err).Fatal("gRPC server exited")
+					}
+				}()
+			}
+
 			server.Serve()
 			return nil
 		},
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/variantregistry"
+)
+
+type VariantsDiffFlags struct {
+	*VariantLoaderFlags
+	JobRegex string
+	JSON     bool
+}
+
+func NewVariantsDiffFlags() *VariantsDiffFlags {
+	return &VariantsDiffFlags{
+		VariantLoaderFlags: NewVariantLoaderFlags(),
+	}
+}
+
+func (f *VariantsDiffFlags) BindFlags(fs *pflag.FlagSet) {
+	f.VariantLoaderFlags.BindFlags(fs)
+	fs.StringVar(&f.JobRegex, "job-regex", "", "Only diff jobs whose name matches this regex (required)")
+	fs.BoolVar(&f.JSON, "json", false, "Print the diff as JSON")
+}
+
+func NewVariantsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "variants",
+		Short: "Inspect the job variant registry",
+	}
+	cmd.AddCommand(newVariantsDiffCommand())
+	cmd.AddCommand(newVariantsSimulateCommand())
+	return cmd
+}
+
+func newVariantsDiffCommand() *cobra.Command {
+	f := NewVariantsDiffFlags()
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Print what syncing the registry would change for the selected jobs, without syncing",
+		Long: `Calculate the expected variants of the jobs matching --job-regex with the loader selected by --mode, as
+generate-job-variants does, and compare them with the variants currently in the BigQuery registry. Each job a sync
+would add, change or remove is printed with the variants' current values prefixed with - and expected values with +.
+Jobs in the registry that match --job-regex but are no longer listed by the loader would be removed. Nothing is
+written.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if f.JobRegex == "" {
+				return fmt.Errorf("--job-regex is required")
+			}
+			match, err := regexp.Compile(f.JobRegex)
+			if err != nil {
+				return errors.WithMessage(err, "invalid --job-regex")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Hour)
+			defer cancel()
+			bigQueryClient, err := f.bigQueryClient(ctx)
+			if err != nil {
+				return err
+			}
+			loader, err := f.variantLoader(ctx, bigQueryClient)
+			if err != nil {
+				return err
+			}
+			expectedVariants, err := variantregistry.LoadExpectedJobVariants(ctx, variantregistry.MatchingJobs(loader, match))
+			if err != nil {
+				return err
+			}
+
+			registry := variantregistry.NewJobVariantsLoader(bigQueryClient, f.BigQueryFlags.BigQueryProject,
+				f.BigQueryFlags.BigQueryDataset, bqcachedclient.JobVariantsTable, nil, nil, "")
+			currentVariants, err := registry.CurrentJobVariants()
+			if err != nil {
+				return err
+			}
+
+			diffs := variantregistry.DiffJobVariants(expectedVariants, currentVariants, match)
+			log.Infof("a sync would change %d of %d jobs matching %s", len(diffs), len(expectedVariants), f.JobRegex)
+			if f.JSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(diffs)
+			}
+			return variantregistry.WriteJobDiffs(cmd.OutOrStdout(), diffs)
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
+
+type VariantsSimulateFlags struct {
+	*VariantLoaderFlags
+	SnapshotsDir string
+	AuditTable   string
+	JSON         bool
+}
+
+func NewVariantsSimulateFlags() *VariantsSimulateFlags {
+	return &VariantsSimulateFlags{
+		VariantLoaderFlags: NewVariantLoaderFlags(),
+	}
+}
+
+func (f *VariantsSimulateFlags) BindFlags(fs *pflag.FlagSet) {
+	f.VariantLoaderFlags.BindFlags(fs)
+	fs.StringVar(&f.SnapshotsDir, "snapshots-dir", "", "Directory of expected job variants JSON files named with the date they were synced, i.e. expected-job-variants-2024-06-01.json (required)")
+	fs.StringVar(&f.AuditTable, "audit-table", bqcachedclient.JobVariantsAuditTable, "Table of audited registry changes to reconstruct the registry's past states from")
+	fs.BoolVar(&f.JSON, "json", false, "Print the simulation as JSON")
+}
+
+func newVariantsSimulateCommand() *cobra.Command {
+	f := NewVariantsSimulateFlags()
+
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Replay past syncs under proposed variant rules, printing the rows each would have changed",
+		Long: `Replay the expected job variants stored in --snapshots-dir, i.e. by running generate-job-variants with
+-o expected-job-variants-$(date +%F).json before each sync, against the registry as it was before each day's sync,
+reconstructed from the registry's audit table. Each snapshot's jobs are reclassified with the rules of the loader
+selected by --mode, including the job name parsing of this build and any --release-defaults-file,
+--variant-overrides-file and --variant-schema-file, without reading job artifacts: variants a job's name doesn't
+determine keep their stored value.
+
+For each day, the rows the sync changed are printed next to the rows it would have changed had the proposed rules
+been in place since the first snapshot, and how many rows the registry would have differed by. The first day
+includes switching the registry to the proposed rules. Nothing is written.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if f.SnapshotsDir == "" {
+				return fmt.Errorf("--snapshots-dir is required")
+			}
+			snapshots, err := variantregistry.FindVariantSnapshots(f.SnapshotsDir)
+			if err != nil {
+				return err
+			}
+			if len(snapshots) == 0 {
+				return fmt.Errorf("no dated snapshots found in %s", f.SnapshotsDir)
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Hour)
+			defer cancel()
+			bigQueryClient, err := f.bigQueryClient(ctx)
+			if err != nil {
+				return err
+			}
+			loader, err := f.variantLoader(ctx, bigQueryClient)
+			if err != nil {
+				return err
+			}
+			reclassifier, ok := loader.(variantregistry.VariantReclassifier)
+			if !ok {
+				return fmt.Errorf("the %s variant loader can't reclassify stored jobs", f.Mode)
+			}
+
+			registry := variantregistry.NewJobVariantsLoader(bigQueryClient, f.BigQueryFlags.BigQueryProject,
+				f.BigQueryFlags.BigQueryDataset, bqcachedclient.JobVariantsTable, nil, nil, "")
+			registry.EnableAudit(f.AuditTable, "", "")
+			history, err := registry.RegistryHistory(ctx, snapshots[0].Date)
+			if err != nil {
+				return err
+			}
+
+			simulation, err := variantregistry.SimulateSyncs(snapshots, history, reclassifier)
+			if err != nil {
+				return err
+			}
+			log.Infof("simulated %d syncs: %d rows changed, %d would have under the proposed rules",
+				len(simulation.Syncs), simulation.ActualRows, simulation.ProposedRows)
+			if f.JSON {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(simulation)
+			}
+			return variantregistry.WriteSimulation(cmd.OutOrStdout(), simulation)
+		},
+	}
+
+	f.BindFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,91 @@
+// Package anomaly contains lightweight detection of sudden drops in daily pass rates.
+package anomaly
+
+import (
+	"math"
+	"time"
+)
+
+// Point is a single daily pass rate observation.
+type Point struct {
+	Date           time.Time
+	Runs           int
+	PassPercentage float64
+}
+
+// Config controls the sensitivity of the detector.
+type Config struct {
+	// Alpha is the EWMA smoothing factor, higher values weight recent days more heavily.
+	Alpha float64
+	// Threshold is the number of standard deviations below the baseline a pass rate must fall to be flagged.
+	Threshold float64
+	// MinRuns is the minimum number of runs on a day for it to be considered, days with fewer runs
+	// are too noisy to be flagged and are not included in the baseline.
+	MinRuns int
+	// Warmup is the number of days required to establish a baseline before anything can be flagged.
+	Warmup int
+	// MinDrop is the minimum drop in percentage points from the baseline to be flagged. This prevents
+	// very stable series with a tiny variance from flagging insignificant changes.
+	MinDrop float64
+}
+
+// DefaultConfig returns the configuration used when none is provided.
+func DefaultConfig() Config {
+	return Config{
+		Alpha:     0.3,
+		Threshold: 3,
+		MinRuns:   5,
+		Warmup:    5,
+		MinDrop:   10,
+	}
+}
+
+// Anomaly is a point flagged by the detector.
+type Anomaly struct {
+	Point
+	Expected   float64
+	Deviations float64
+}
+
+// DetectEWMA walks a series of daily pass rates in date order, maintaining an exponentially weighted moving average
+// and variance, and flags any day that falls more than the configured number of standard deviations below the
+// baseline established by the preceding days. Flagged days are not folded into the baseline, so a sustained drop
+// continues to be reported rather than quickly becoming the new normal.
+func DetectEWMA(series []Point, cfg Config) []Anomaly {
+	var anomalies []Anomaly
+	var mean, variance float64
+	observed := 0
+
+	for _, p := range series {
+		if p.Runs < cfg.MinRuns {
+			continue
+		}
+
+		if observed >= cfg.Warmup {
+			// Floor the deviation at one percentage point so a perfectly stable history doesn't produce
+			// infinite deviations.
+			stddev := math.Max(math.Sqrt(variance), 1)
+			drop := mean - p.PassPercentage
+			if drop >= cfg.MinDrop && drop > cfg.Threshold*stddev {
+				anomalies = append(anomalies, Anomaly{
+					Point:      p,
+					Expected:   mean,
+					Deviations: drop / stddev,
+				})
+				continue
+			}
+		}
+
+		if observed == 0 {
+			mean = p.PassPercentage
+		} else {
+			diff := p.PassPercentage - mean
+			incr := cfg.Alpha * diff
+			mean += incr
+			variance = (1 - cfg.Alpha) * (variance + diff*incr)
+		}
+		observed++
+	}
+
+	return anomalies
+}
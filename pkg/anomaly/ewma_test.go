@@ -0,0 +1,76 @@
+package anomaly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeSeries(runs int, rates ...float64) []Point {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	series := make([]Point, 0, len(rates))
+	for i, r := range rates {
+		series = append(series, Point{
+			Date:           start.Add(time.Duration(i) * 24 * time.Hour),
+			Runs:           runs,
+			PassPercentage: r,
+		})
+	}
+	return series
+}
+
+func TestDetectEWMA(t *testing.T) {
+	tests := []struct {
+		name          string
+		series        []Point
+		expectedDates []int
+	}{
+		{
+			name:   "stable series",
+			series: makeSeries(20, 90, 91, 89, 90, 92, 90, 88, 91),
+		},
+		{
+			name:          "sudden drop",
+			series:        makeSeries(20, 90, 91, 89, 90, 92, 90, 40, 91),
+			expectedDates: []int{6},
+		},
+		{
+			name:          "sustained drop keeps flagging",
+			series:        makeSeries(20, 90, 91, 89, 90, 92, 40, 42, 41),
+			expectedDates: []int{5, 6, 7},
+		},
+		{
+			name:   "drop during warmup is ignored",
+			series: makeSeries(20, 90, 91, 40, 90, 92),
+		},
+		{
+			name:   "too few runs",
+			series: makeSeries(2, 90, 91, 89, 90, 92, 90, 40, 91),
+		},
+		{
+			name:   "small drop on a perfectly stable series",
+			series: makeSeries(20, 100, 100, 100, 100, 100, 100, 95),
+		},
+		{
+			name:   "noisy series",
+			series: makeSeries(20, 90, 60, 95, 55, 92, 58, 50, 91),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := DetectEWMA(tt.series, DefaultConfig())
+			var dates []int
+			for _, a := range anomalies {
+				for i, p := range tt.series {
+					if p.Date.Equal(a.Date) {
+						dates = append(dates, i)
+					}
+				}
+				assert.Greater(t, a.Expected, a.PassPercentage)
+				assert.Greater(t, a.Deviations, DefaultConfig().Threshold)
+			}
+			assert.Equal(t, tt.expectedDates, dates)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/notification"
+)
+
+// WebhookPayload is the body posted to a webhook when anomalies are detected.
+type WebhookPayload struct {
+	Anomalies []apitype.PassRateAnomaly `json:"anomalies"`
+}
+
+// PostWebhook sends the given anomalies to a webhook, as json or as a text summary for Slack and Google Chat.
+func PostWebhook(ctx context.Context, url string, anomalies []apitype.PassRateAnomaly) error {
+	return notification.Send(ctx, notification.Message{
+		Subject: fmt.Sprintf("%d pass rate anomalies detected", len(anomalies)),
+		Text:    anomalyText(anomalies),
+		Data:    WebhookPayload{Anomalies: anomalies},
+	}, notification.Destination{Transport: notification.WebhookURL(url)})
+}
+
+func anomalyText(anomalies []apitype.PassRateAnomaly) string {
+	lines := []string{fmt.Sprintf("%d pass rate anomalies detected:", len(anomalies))}
+	for _, a := range anomalies {
+		lines = append(lines, fmt.Sprintf("• %s %s (%s) on %s: %.1f%% vs %.1f%% expected",
+			a.Kind, a.Name, a.Release, a.Date.Format("2006-01-02"), a.PassPercentage, a.ExpectedPassPercentage))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// DefaultTopParams is how many of the most common parameter sets are returned for each endpoint.
+const DefaultTopParams = 5
+
+// GetEndpointUsage summarizes the access logs of each endpoint between start and end, along with the parameter
+// sets it was most commonly called with.
+func GetEndpointUsage(dbc *db.DB, start, end time.Time, topParams int) ([]apitype.EndpointUsage, error) {
+	usage, err := query.EndpointUsage(dbc, start, end)
+	if err != nil {
+		return nil, err
+	}
+	params, err := query.EndpointParams(dbc, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return addTopParams(usage, params, topParams), nil
+}
+
+// addTopParams adds the first topParams parameter sets of each endpoint, which are expected most common first.
+func addTopParams(usage []apitype.EndpointUsage, params []apitype.EndpointParamsUsage, topParams int) []apitype.EndpointUsage {
+	byEndpoint := map[string][]apitype.EndpointParamsUsage{}
+	for _, p := range params {
+		if len(byEndpoint[p.Endpoint]) < topParams {
+			byEndpoint[p.Endpoint] = append(byEndpoint[p.Endpoint], p)
+		}
+	}
+	for i := range usage {
+		usage[i].TopParams = byEndpoint[usage[i].Endpoint]
+		if usage[i].TopParams == nil {
+			usage[i].TopParams = []apitype.EndpointParamsUsage{}
+		}
+	}
+	return usage
+}
+
+// GetVariantUsage reports how API requests between start and end used each variant key and value, alongside how
+// many jobs in the registry have them, so maintainers can see which variants matter to users. Registered keys no
+// request used are included, as are keys requests used that no job has.
+func GetVariantUsage(dbc *db.DB, start, end time.Time) ([]apitype.VariantUsage, error) {
+	keys, err := query.VariantKeyUsage(dbc, start, end)
+	if err != nil {
+		return nil, err
+	}
+	values, err := query.VariantValueUsage(dbc, start, end)
+	if err != nil {
+		return nil, err
+	}
+	registered, err := query.VariantValueCounts(dbc, "")
+	if err != nil {
+		return nil, err
+	}
+	return buildVariantUsage(keys, values, registered), nil
+}
+
+// buildVariantUsage adds the used values of each key, and the registry's job counts, to the usage of each key.
+func buildVariantUsage(keys []apitype.VariantUsage, values []apitype.VariantValueUsage,
+	registered []apitype.VariantValueCount) []apitype.VariantUsage {
+	usage := map[string]*apitype.VariantUsage{}
+	get := func(key string) *apitype.VariantUsage {
+		if _, ok := usage[key]; !ok {
+			usage[key] = &apitype.VariantUsage{Key: key, Values: []apitype.VariantValueUsage{}}
+		}
+		return usage[key]
+	}
+	for i := range keys {
+		keys[i].Values = []apitype.VariantValueUsage{}
+		usage[keys[i].Key] = &keys[i]
+	}
+	for _, k := range groupVariantValues(registered) {
+		u := get(k.Key)
+		u.Registered = true
+		u.Jobs = k.Jobs
+	}
+
+	valueJobs := map[string]int{}
+	for _, r := range registered {
+		valueJobs[r.Key+":"+r.Value] = r.Jobs
+	}
+	for _, v := range values {
+		v.Jobs = valueJobs[v.Key+":"+v.Value]
+		u := get(v.Key)
+		u.Values = append(u.Values, v)
+	}
+
+	result := make([]apitype.VariantUsage, 0, len(usage))
+	for _, u := range usage {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
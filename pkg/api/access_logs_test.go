@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestAddTopParams(t *testing.T) {
+	usage := []apitype.EndpointUsage{{Endpoint: "/api/jobs"}, {Endpoint: "/api/tests"}}
+	params := []apitype.EndpointParamsUsage{
+		{Endpoint: "/api/jobs", Params: "release=4.16", Requests: 10},
+		{Endpoint: "/api/jobs", Params: "release=4.15", Requests: 5},
+		{Endpoint: "/api/jobs", Params: "filter=*&release=4.16", Requests: 1},
+	}
+
+	result := addTopParams(usage, params, 2)
+	require.Len(t, result[0].TopParams, 2)
+	assert.Equal(t, "release=4.16", result[0].TopParams[0].Params)
+	assert.Equal(t, "release=4.15", result[0].TopParams[1].Params)
+	assert.NotNil(t, result[1].TopParams)
+	assert.Empty(t, result[1].TopParams)
+}
+
+func TestBuildVariantUsage(t *testing.T) {
+	last := time.Date(2024, 5, 15, 9, 0, 0, 0, time.UTC)
+	keys := []apitype.VariantUsage{
+		{Key: "Platform", Requests: 10, FilterRequests: 8, GroupRequests: 3, LastRequest: &last},
+		{Key: "CloudRegion", Requests: 2, FilterRequests: 2, LastRequest: &last},
+	}
+	values := []apitype.VariantValueUsage{
+		{Key: "CloudRegion", Value: "us-east-1", Requests: 2, LastRequest: &last},
+		{Key: "Platform", Value: "aws", Requests: 6, LastRequest: &last},
+		{Key: "Platform", Value: "gcp", Requests: 2, LastRequest: &last},
+	}
+	registered := []apitype.VariantValueCount{
+		{Key: "Network", Value: "ovn", Jobs: 30},
+		{Key: "Platform", Value: "aws", Jobs: 20},
+		{Key: "Platform", Value: "metal", Jobs: 10},
+	}
+
+	usage := buildVariantUsage(keys, values, registered)
+	require.Len(t, usage, 3)
+
+	platform := usage[0]
+	assert.Equal(t, "Platform", platform.Key)
+	assert.True(t, platform.Registered)
+	assert.Equal(t, 30, platform.Jobs)
+	assert.Equal(t, []apitype.VariantValueUsage{
+		{Key: "Platform", Value: "aws", Requests: 6, LastRequest: &last, Jobs: 20},
+		{Key: "Platform", Value: "gcp", Requests: 2, LastRequest: &last},
+	}, platform.Values, "values no request used, like metal, are left out")
+
+	candidate := usage[1]
+	assert.Equal(t, "CloudRegion", candidate.Key)
+	assert.False(t, candidate.Registered, "requested but no job has it")
+	assert.Len(t, candidate.Values, 1)
+
+	unused := usage[2]
+	assert.Equal(t, apitype.VariantUsage{Key: "Network", Registered: true, Jobs: 30, Values: []apitype.VariantValueUsage{}},
+		unused)
+}
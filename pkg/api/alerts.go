@@ -0,0 +1,58 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+// GetAlertFiringReport reports how often alerts fired in a release, by variant, along with the change since the
+// previous release over the same time range. Alerts that have become noisier are sorted first.
+func GetAlertFiringReport(dbc *db.DB, release, variant string, start, end time.Time) ([]apitype.AlertFiringSummary, error) {
+	current, err := query.AlertFiringSummaries(dbc, release, variant, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []apitype.AlertFiringSummary
+	if prevRelease, err := util.PreviousRelease(release); err != nil {
+		log.WithError(err).Warning("unable to determine previous release, deltas will not be reported")
+	} else if previous, err = query.AlertFiringSummaries(dbc, prevRelease, variant, start, end); err != nil {
+		return nil, err
+	}
+
+	return mergeAlertFiringSummaries(current, previous), nil
+}
+
+// mergeAlertFiringSummaries fills in the previous release fields of the current summaries, and sorts them by the
+// largest increase.
+func mergeAlertFiringSummaries(current, previous []apitype.AlertFiringSummary) []apitype.AlertFiringSummary {
+	type key struct{ alert, variant string }
+	prev := make(map[key]apitype.AlertFiringSummary, len(previous))
+	for _, p := range previous {
+		prev[key{p.Alert, p.Variant}] = p
+	}
+
+	for i := range current {
+		if p, ok := prev[key{current[i].Alert, current[i].Variant}]; ok {
+			current[i].PreviousRuns = p.Runs
+			current[i].PreviousTotalRuns = p.TotalRuns
+			current[i].PreviousPercentage = p.Percentage
+		}
+		current[i].Delta = current[i].Percentage - current[i].PreviousPercentage
+	}
+
+	sort.SliceStable(current, func(i, j int) bool {
+		if current[i].Delta != current[j].Delta {
+			return current[i].Delta > current[j].Delta
+		}
+		return current[i].Percentage > current[j].Percentage
+	})
+	return current
+}
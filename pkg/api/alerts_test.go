@@ -0,0 +1,40 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestMergeAlertFiringSummaries(t *testing.T) {
+	current := []apitype.AlertFiringSummary{
+		{Alert: "KubePodNotReady", Variant: "Platform:aws", Runs: 10, TotalRuns: 100, Percentage: 10},
+		{Alert: "etcdMembersDown", Variant: "Platform:aws", Runs: 30, TotalRuns: 100, Percentage: 30},
+		{Alert: "etcdMembersDown", Variant: "Platform:gcp", Runs: 5, TotalRuns: 100, Percentage: 5},
+	}
+	previous := []apitype.AlertFiringSummary{
+		{Alert: "KubePodNotReady", Variant: "Platform:aws", Runs: 40, TotalRuns: 200, Percentage: 20},
+		{Alert: "etcdMembersDown", Variant: "Platform:aws", Runs: 10, TotalRuns: 200, Percentage: 5},
+		{Alert: "AlertThatStopped", Variant: "Platform:aws", Runs: 10, TotalRuns: 200, Percentage: 5},
+	}
+
+	merged := mergeAlertFiringSummaries(current, previous)
+	if assert.Len(t, merged, 3) {
+		// New noise is sorted first
+		assert.Equal(t, "etcdMembersDown", merged[0].Alert)
+		assert.Equal(t, "Platform:aws", merged[0].Variant)
+		assert.Equal(t, 25.0, merged[0].Delta)
+		assert.Equal(t, 10, merged[0].PreviousRuns)
+		assert.Equal(t, 200, merged[0].PreviousTotalRuns)
+
+		// Not seen in the previous release
+		assert.Equal(t, "Platform:gcp", merged[1].Variant)
+		assert.Equal(t, 5.0, merged[1].Delta)
+		assert.Equal(t, 0, merged[1].PreviousTotalRuns)
+
+		assert.Equal(t, "KubePodNotReady", merged[2].Alert)
+		assert.Equal(t, -10.0, merged[2].Delta)
+	}
+}
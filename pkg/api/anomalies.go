@@ -0,0 +1,77 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openshift/sippy/pkg/anomaly"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	AnomalyKindJob       = "job"
+	AnomalyKindComponent = "component"
+)
+
+// GetPassRateAnomalies runs the anomaly detector over the daily pass rates of every job or component in a release
+// between start and end, and returns the flagged days, most recent first. Job runs during known incidents can be
+// excluded so outages that are already understood aren't flagged; component pass rates are not affected.
+func GetPassRateAnomalies(dbc *db.DB, release, kind string, start, end time.Time, excludeIncidents bool, cfg anomaly.Config) ([]apitype.PassRateAnomaly, error) {
+	var rates []query.DailyPassRate
+	var err error
+	switch kind {
+	case AnomalyKindJob:
+		rates, err = query.DailyJobPassRates(dbc, release, start, end, excludeIncidents)
+	case AnomalyKindComponent:
+		rates, err = query.DailyComponentPassRates(dbc, release, start, end)
+	default:
+		return nil, fmt.Errorf("unknown anomaly kind %q: must be job or component", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return detectAnomalies(rates, release, kind, cfg), nil
+}
+
+// detectAnomalies splits rows ordered by name and date into a series per name, and runs the detector over each.
+func detectAnomalies(rates []query.DailyPassRate, release, kind string, cfg anomaly.Config) []apitype.PassRateAnomaly {
+	results := make([]apitype.PassRateAnomaly, 0)
+
+	series := make(map[string][]anomaly.Point)
+	var names []string
+	for _, r := range rates {
+		if _, ok := series[r.Name]; !ok {
+			names = append(names, r.Name)
+		}
+		series[r.Name] = append(series[r.Name], anomaly.Point{
+			Date:           r.Date,
+			Runs:           r.Runs,
+			PassPercentage: r.PassPercentage,
+		})
+	}
+
+	for _, name := range names {
+		for _, a := range anomaly.DetectEWMA(series[name], cfg) {
+			results = append(results, apitype.PassRateAnomaly{
+				Kind:                   kind,
+				Name:                   name,
+				Release:                release,
+				Date:                   a.Date,
+				Runs:                   a.Runs,
+				PassPercentage:         a.PassPercentage,
+				PassPercentageCI:       apitype.PassPercentageInterval(&a.PassPercentage, a.Runs),
+				ExpectedPassPercentage: a.Expected,
+				Deviations:             a.Deviations,
+			})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Date.After(results[j].Date)
+	})
+	return results
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// ErrBasisPinNotFound is returned when a basis pin does not exist.
+var ErrBasisPinNotFound = errors.New("basis pin not found")
+
+// ValidateBasisPin ensures a basis pin submitted via the API is well-formed.
+func ValidateBasisPin(pin *models.BasisPin) error {
+	if pin.Release == "" {
+		return fmt.Errorf("release is required")
+	}
+	if (pin.TestID == "") == (pin.Component == "") {
+		return fmt.Errorf("exactly one of test_id or component is required")
+	}
+	if pin.BaseRelease == "" {
+		return fmt.Errorf("base_release is required")
+	}
+	if pin.BaseStart.IsZero() || pin.BaseEnd.IsZero() {
+		return fmt.Errorf("base_start and base_end are required")
+	}
+	if !pin.BaseEnd.After(pin.BaseStart) {
+		return fmt.Errorf("base_end must be after base_start")
+	}
+	if strings.TrimSpace(pin.Reason) == "" {
+		return fmt.Errorf("reason is required")
+	}
+	return nil
+}
+
+// ListBasisPins returns the basis pins for a release, or all pins if release is empty.
+func ListBasisPins(dbc *db.DB, release string) ([]models.BasisPin, error) {
+	pins := []models.BasisPin{}
+	q := dbc.DB.Order("id")
+	if release != "" {
+		q = q.Where("release = ?", release)
+	}
+	return pins, q.Find(&pins).Error
+}
+
+// CreateBasisPin validates and stores a new basis pin.
+func CreateBasisPin(dbc *db.DB, pin *models.BasisPin) error {
+	pin.Model = models.Model{}
+	if err := ValidateBasisPin(pin); err != nil {
+		return err
+	}
+	return dbc.DB.Create(pin).Error
+}
+
+// DeleteBasisPin soft deletes a basis pin, reports generated afterwards use their default basis again.
+func DeleteBasisPin(dbc *db.DB, id uint, deletedBy string) error {
+	found, err := softDelete(dbc, &models.BasisPin{}, id, deletedBy)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrBasisPinNotFound
+	}
+	return nil
+}
+
+// RestoreBasisPin undeletes a soft deleted basis pin, reports generated afterwards use it again.
+func RestoreBasisPin(dbc *db.DB, id uint) (*models.BasisPin, error) {
+	found, err := restoreDeleted(dbc, &models.BasisPin{}, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrBasisPinNotFound
+	}
+	pin := &models.BasisPin{}
+	return pin, dbc.Primary().DB.First(pin, id).Error
+}
+
+// ListDeletedBasisPins returns the soft deleted basis pins, most recently deleted first.
+func ListDeletedBasisPins(dbc *db.DB) ([]models.BasisPin, error) {
+	pins := []models.BasisPin{}
+	return pins, listDeleted(dbc, &pins)
+}
+
+// ComponentReportBasisPins returns the basis pins for a sample release in the form used when generating
+// component reports. Without a database there are no pins.
+func ComponentReportBasisPins(dbc *db.DB, release string) ([]crtype.BasisPin, error) {
+	if dbc == nil {
+		return nil, nil
+	}
+	pins, err := ListBasisPins(dbc, release)
+	if err != nil {
+		return nil, err
+	}
+	return toComponentReportBasisPins(pins), nil
+}
+
+func toComponentReportBasisPins(pins []models.BasisPin) []crtype.BasisPin {
+	var results []crtype.BasisPin
+	for _, pin := range pins {
+		results = append(results, crtype.BasisPin{
+			ID:        pin.ID,
+			TestID:    pin.TestID,
+			Component: pin.Component,
+			Basis: crtype.RequestReleaseOptions{
+				Release: pin.BaseRelease,
+				Start:   pin.BaseStart,
+				End:     pin.BaseEnd,
+			},
+			Reason: pin.Reason,
+		})
+	}
+	return results
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestValidateBasisPin(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	valid := func() models.BasisPin {
+		return models.BasisPin{
+			Release:     "4.16",
+			TestID:      "openshift-tests:abc",
+			BaseRelease: "4.15",
+			BaseStart:   start,
+			BaseEnd:     start.Add(28 * 24 * time.Hour),
+			Reason:      "known bad basis",
+		}
+	}
+
+	pin := valid()
+	assert.NoError(t, ValidateBasisPin(&pin))
+
+	tests := map[string]func(*models.BasisPin){
+		"no release":             func(p *models.BasisPin) { p.Release = "" },
+		"test and component":     func(p *models.BasisPin) { p.Component = "etcd" },
+		"neither test nor comp.": func(p *models.BasisPin) { p.TestID = "" },
+		"no base release":        func(p *models.BasisPin) { p.BaseRelease = "" },
+		"end before start":       func(p *models.BasisPin) { p.BaseEnd = start.Add(-time.Hour) },
+		"no reason":              func(p *models.BasisPin) { p.Reason = " " },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			pin := valid()
+			mutate(&pin)
+			assert.Error(t, ValidateBasisPin(&pin))
+		})
+	}
+}
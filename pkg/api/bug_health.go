@@ -0,0 +1,81 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// GetComponentBugHealth returns each component's open JIRA bug backlog next to its regressions open in the release
+// as of end, optionally limited to one component. Components with neither open bugs nor regressions are left out.
+func GetComponentBugHealth(dbc *db.DB, release, component string, end time.Time) ([]apitype.ComponentBugHealth, error) {
+	regressions, err := query.TestRegressions(dbc, release, component, end)
+	if err != nil {
+		return nil, err
+	}
+	backlogs, err := query.ComponentBugBacklogs(dbc, component)
+	if err != nil {
+		return nil, err
+	}
+	return buildComponentBugHealth(regressions, backlogs, end), nil
+}
+
+func buildComponentBugHealth(regressions []models.TestRegression, backlogs []models.ComponentBugBacklog, end time.Time) []apitype.ComponentBugHealth {
+	health := map[string]*apitype.ComponentBugHealth{}
+	get := func(component string) *apitype.ComponentBugHealth {
+		h, ok := health[component]
+		if !ok {
+			h = &apitype.ComponentBugHealth{Component: component}
+			health[component] = h
+		}
+		return h
+	}
+
+	for _, r := range regressions {
+		if r.Opened.After(end) || !regressionOpenAt(r, end) {
+			continue
+		}
+		h := get(r.Component)
+		h.OpenRegressions++
+		hours := regressionHours(r, end)
+		if h.OldestRegressionHours == nil || hours > *h.OldestRegressionHours {
+			h.OldestRegressionHours = &hours
+		}
+	}
+
+	for _, b := range backlogs {
+		h := get(b.Component)
+		h.OpenBugs = b.OpenBugs
+		h.CriticalBugs = b.CriticalBugs
+		h.Over90Days = b.Over90Days
+		h.Over365Days = b.Over365Days
+		updated := b.UpdatedAt
+		h.BacklogUpdated = &updated
+		if b.OpenBugs > 0 {
+			median := b.MedianAgeDays
+			oldest := updated.Sub(b.OldestCreated).Hours() / 24
+			h.MedianBugAgeDays = &median
+			h.OldestBugAgeDays = &oldest
+		}
+	}
+
+	results := make([]apitype.ComponentBugHealth, 0, len(health))
+	for _, h := range health {
+		results = append(results, *h)
+	}
+	// Most open regressions first, then the largest backlogs
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].OpenRegressions != results[j].OpenRegressions {
+			return results[i].OpenRegressions > results[j].OpenRegressions
+		}
+		if results[i].OpenBugs != results[j].OpenBugs {
+			return results[i].OpenBugs > results[j].OpenBugs
+		}
+		return results[i].Component < results[j].Component
+	})
+	return results
+}
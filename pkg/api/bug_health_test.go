@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestBuildComponentBugHealth(t *testing.T) {
+	end := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	closed := end.Add(-24 * time.Hour)
+	regressions := []models.TestRegression{
+		{Component: "Networking", Opened: end.Add(-48 * time.Hour)},
+		{Component: "Networking", Opened: end.Add(-10 * time.Hour)},
+		{Component: "etcd", Opened: end.Add(-72 * time.Hour), Closed: &closed},
+		{Component: "etcd", Opened: end.Add(time.Hour)},
+	}
+	backlogs := []models.ComponentBugBacklog{
+		{
+			Model:         models.Model{UpdatedAt: end},
+			Component:     "Storage",
+			OpenBugs:      40,
+			Over90Days:    12,
+			MedianAgeDays: 41,
+			OldestCreated: end.Add(-100 * 24 * time.Hour),
+		},
+		{Model: models.Model{UpdatedAt: end}, Component: "Networking", OpenBugs: 5, MedianAgeDays: 10, OldestCreated: end.Add(-20 * 24 * time.Hour)},
+	}
+
+	results := buildComponentBugHealth(regressions, backlogs, end)
+	require.Len(t, results, 2)
+
+	// Regressions sort first, closed and not yet opened regressions don't count
+	assert.Equal(t, "Networking", results[0].Component)
+	assert.Equal(t, 2, results[0].OpenRegressions)
+	require.NotNil(t, results[0].OldestRegressionHours)
+	assert.Equal(t, 48.0, *results[0].OldestRegressionHours)
+	assert.Equal(t, 5, results[0].OpenBugs)
+
+	assert.Equal(t, "Storage", results[1].Component)
+	assert.Equal(t, 0, results[1].OpenRegressions)
+	assert.Nil(t, results[1].OldestRegressionHours)
+	assert.Equal(t, 12, results[1].Over90Days)
+	require.NotNil(t, results[1].OldestBugAgeDays)
+	assert.Equal(t, 100.0, *results[1].OldestBugAgeDays)
+	assert.Equal(t, 41.0, *results[1].MedianBugAgeDays)
+}
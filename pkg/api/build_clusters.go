@@ -1,11 +1,13 @@
 package api
 
 import (
+	"sort"
 	"time"
 
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/stats"
 )
 
 func GetBuildClusterHealthReport(dbc *db.DB, start, boundary, end time.Time) ([]apitype.BuildClusterHealth, error) {
@@ -45,3 +47,60 @@ func GetBuildClusterHealthAnalysis(dbc *db.DB, period string) (map[string]apityp
 
 	return results, nil
 }
+
+// buildClusterSignificance is the p-value below which a cluster's failure rate is significantly higher than the
+// other clusters'.
+const buildClusterSignificance = 0.05
+
+// GetBuildClusterFailures compares the failure rate of runs on each build cluster to that of runs on the other
+// clusters, to implicate or exonerate the build clusters when failures spike. Results can be limited to a release
+// and job, or to the results of a test.
+func GetBuildClusterFailures(dbc *db.DB, release, job, test string, start, end time.Time) ([]apitype.BuildClusterFailures, error) {
+	counts, err := query.BuildClusterFailures(dbc, release, job, test, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return buildClusterFailures(counts), nil
+}
+
+func buildClusterFailures(counts []query.BuildClusterFailureCount) []apitype.BuildClusterFailures {
+	var totalRuns, totalFailures int
+	for _, c := range counts {
+		totalRuns += c.Runs
+		totalFailures += c.Failures
+	}
+
+	results := make([]apitype.BuildClusterFailures, 0, len(counts))
+	for _, c := range counts {
+		othersRuns := totalRuns - c.Runs
+		othersFailures := totalFailures - c.Failures
+		result := apitype.BuildClusterFailures{
+			Cluster:                c.Cluster,
+			Runs:                   c.Runs,
+			Failures:               c.Failures,
+			InfrastructureFailures: c.InfrastructureFailures,
+			OthersRuns:             othersRuns,
+		}
+		if c.Runs > 0 {
+			result.FailurePercentage = float64(c.Failures) * 100.0 / float64(c.Runs)
+		}
+		if othersRuns > 0 {
+			result.OthersFailurePercentage = float64(othersFailures) * 100.0 / float64(othersRuns)
+			// One-sided: only a higher failure rate than the other clusters implicates this one.
+			p := stats.FisherExact(
+				stats.Counts{Successes: c.Runs - c.Failures, Failures: c.Failures},
+				stats.Counts{Successes: othersRuns - othersFailures, Failures: othersFailures},
+			).Greater
+			result.Implicated = result.FailurePercentage > result.OthersFailurePercentage && p < buildClusterSignificance
+		}
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Implicated != results[j].Implicated {
+			return results[i].Implicated
+		}
+		return results[i].FailurePercentage > results[j].FailurePercentage
+	})
+	return results
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestBuildClusterFailures(t *testing.T) {
+	counts := []query.BuildClusterFailureCount{
+		{Cluster: "build01", Runs: 200, Failures: 20},
+		{Cluster: "build02", Runs: 200, Failures: 90, InfrastructureFailures: 60},
+		{Cluster: "build03", Runs: 200, Failures: 18},
+		{Cluster: "build04", Runs: 3, Failures: 1},
+	}
+
+	results := buildClusterFailures(counts)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, "build02", results[0].Cluster)
+	assert.True(t, results[0].Implicated)
+	assert.Equal(t, 45.0, results[0].FailurePercentage)
+	assert.Equal(t, 403, results[0].OthersRuns)
+	assert.InDelta(t, 39.0*100/403, results[0].OthersFailurePercentage, 0.0001)
+	assert.Equal(t, 60, results[0].InfrastructureFailures)
+
+	for _, r := range results[1:] {
+		assert.False(t, r.Implicated, "%s should not be implicated", r.Cluster)
+	}
+	assert.Equal(t, "build04", results[1].Cluster, "unimplicated clusters should be sorted by failure percentage")
+}
+
+func TestBuildClusterFailuresSingleCluster(t *testing.T) {
+	results := buildClusterFailures([]query.BuildClusterFailureCount{{Cluster: "build01", Runs: 10, Failures: 10}})
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Implicated, "a lone cluster has nothing to compare to")
+	assert.Equal(t, 0, results[0].OthersRuns)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// GetCapabilityCoverage returns the tests covering each capability and feature in a release with their current
+// results, optionally limited to one capability, and to variant combinations containing all the given variants.
+// Capabilities whose tests have no runs in those variants are still listed, so gaps in coverage are visible, unless the
+// tests can never run in the variants, which are marked not applicable.
+func GetCapabilityCoverage(dbc *db.DB, release, capability string, variants []string) ([]apitype.CapabilityCoverage, error) {
+	results, err := query.CapabilityTestResults(dbc, release, capability, variants)
+	if err != nil {
+		return nil, err
+	}
+	return buildCapabilityCoverage(results, variants, testidentification.DefaultNotApplicableRules()), nil
+}
+
+// buildCapabilityCoverage nests test results, which are ordered by capability and test name, by capability and
+// test.
+func buildCapabilityCoverage(results []query.CapabilityTestResult, variants []string, notApplicable *testidentification.NotApplicableRules) []apitype.CapabilityCoverage {
+	coverage := []apitype.CapabilityCoverage{}
+	for _, r := range results {
+		if len(coverage) == 0 || coverage[len(coverage)-1].Capability != r.Capability {
+			coverage = append(coverage, apitype.CapabilityCoverage{Capability: r.Capability, Tests: []apitype.CapabilityTest{}})
+		}
+		capability := &coverage[len(coverage)-1]
+		if len(capability.Tests) == 0 || capability.Tests[len(capability.Tests)-1].Name != r.Name {
+			capability.Tests = append(capability.Tests, apitype.CapabilityTest{
+				Name:      r.Name,
+				Component: r.Component,
+				Variants:  []apitype.CapabilityTestVariants{},
+			})
+		}
+		test := &capability.Tests[len(capability.Tests)-1]
+		if r.Variants == nil {
+			// no results in the release and variants, which is expected if the test can't run in them
+			if rule := notApplicable.MatchVariantList(r.Name, variants); rule != nil {
+				test.NotApplicable = rule.Reason
+			}
+			continue
+		}
+
+		stats := apitype.CapabilityTestStats{
+			CurrentRuns:      r.CurrentRuns,
+			CurrentSuccesses: r.CurrentSuccesses,
+			CurrentFailures:  r.CurrentFailures,
+			CurrentFlakes:    r.CurrentFlakes,
+		}
+		test.Variants = append(test.Variants, apitype.CapabilityTestVariants{Variants: r.Variants, CapabilityTestStats: withPassPercentage(stats)})
+		addCapabilityTestStats(&test.CapabilityTestStats, stats)
+		addCapabilityTestStats(&capability.CapabilityTestStats, stats)
+	}
+	return coverage
+}
+
+func addCapabilityTestStats(total *apitype.CapabilityTestStats, stats apitype.CapabilityTestStats) {
+	total.CurrentRuns += stats.CurrentRuns
+	total.CurrentSuccesses += stats.CurrentSuccesses
+	total.CurrentFailures += stats.CurrentFailures
+	total.CurrentFlakes += stats.CurrentFlakes
+	*total = withPassPercentage(*total)
+}
+
+func withPassPercentage(stats apitype.CapabilityTestStats) apitype.CapabilityTestStats {
+	stats.CurrentPassPercentage = 0
+	if stats.CurrentRuns > 0 {
+		stats.CurrentPassPercentage = float64(stats.CurrentSuccesses) * 100 / float64(stats.CurrentRuns)
+	}
+	stats.CurrentPassPercentageCI = apitype.NewConfidenceInterval(stats.CurrentSuccesses, stats.CurrentRuns)
+	return stats
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+func TestBuildCapabilityCoverage(t *testing.T) {
+	results := []query.CapabilityTestResult{
+		{Capability: "Feature:NetworkPolicy", Name: "test a", Component: "Networking", Variants: []string{"aws", "amd64"}, CurrentRuns: 10, CurrentSuccesses: 9, CurrentFailures: 1},
+		{Capability: "Feature:NetworkPolicy", Name: "test a", Component: "Networking", Variants: []string{"gcp", "amd64"}, CurrentRuns: 10, CurrentSuccesses: 5, CurrentFlakes: 5},
+		{Capability: "Feature:NetworkPolicy", Name: "test b", Component: "Networking"},
+		{Capability: "OVN", Name: "test a", Component: "Networking", Variants: []string{"aws", "amd64"}, CurrentRuns: 10, CurrentSuccesses: 9, CurrentFailures: 1},
+	}
+
+	coverage := buildCapabilityCoverage(results, nil, nil)
+	require.Len(t, coverage, 2)
+
+	policy := coverage[0]
+	assert.Equal(t, "Feature:NetworkPolicy", policy.Capability)
+	assert.Equal(t, 20, policy.CurrentRuns)
+	assert.InDelta(t, 70, policy.CurrentPassPercentage, 0.001)
+	require.Len(t, policy.Tests, 2)
+	assert.Equal(t, "test a", policy.Tests[0].Name)
+	require.Len(t, policy.Tests[0].Variants, 2)
+	assert.InDelta(t, 90, policy.Tests[0].Variants[0].CurrentPassPercentage, 0.001)
+	assert.InDelta(t, 50, policy.Tests[0].Variants[1].CurrentPassPercentage, 0.001)
+	// tests without results in the variants are still listed, so coverage gaps are visible
+	assert.Equal(t, "test b", policy.Tests[1].Name)
+	assert.Empty(t, policy.Tests[1].Variants)
+	assert.Zero(t, policy.Tests[1].CurrentRuns)
+
+	assert.Equal(t, "OVN", coverage[1].Capability)
+	assert.Equal(t, 10, coverage[1].CurrentRuns)
+}
+
+func TestBuildCapabilityCoverageNotApplicable(t *testing.T) {
+	rules, err := testidentification.ParseNotApplicableRules([]byte(`
+- test_pattern: 'sig-cloud-provider-aws'
+  variants:
+    Platform: [metal]
+  reason: AWS only
+`))
+	require.NoError(t, err)
+	results := []query.CapabilityTestResult{
+		{Capability: "CloudProvider", Name: "[sig-cloud-provider-aws] test", Component: "Cloud"},
+		{Capability: "CloudProvider", Name: "test b", Component: "Cloud"},
+	}
+
+	coverage := buildCapabilityCoverage(results, []string{"Platform:metal"}, rules)
+	require.Len(t, coverage, 1)
+	require.Len(t, coverage[0].Tests, 2)
+	assert.Equal(t, "AWS only", coverage[0].Tests[0].NotApplicable)
+	// a gap in coverage
+	assert.Empty(t, coverage[0].Tests[1].NotApplicable)
+
+	coverage = buildCapabilityCoverage(results, []string{"Platform:aws"}, rules)
+	assert.Empty(t, coverage[0].Tests[0].NotApplicable)
+}
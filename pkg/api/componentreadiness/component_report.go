@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"cloud.google.com/go/bigquery"
-	fischer "github.com/glycerine/golang-fisher-exact"
 	"github.com/openshift/sippy/pkg/api"
 	"github.com/openshift/sippy/pkg/componentreadiness/resolvedissues"
 	"github.com/openshift/sippy/pkg/componentreadiness/tracker"
@@ -22,8 +21,12 @@ import (
 
 	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
 	"github.com/openshift/sippy/pkg/apis/cache"
+	"github.com/openshift/sippy/pkg/artifacturls"
 	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/regressionallowances"
+	"github.com/openshift/sippy/pkg/stats"
+	"github.com/openshift/sippy/pkg/testidentification"
+	"github.com/openshift/sippy/pkg/util"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
@@ -36,6 +39,10 @@ const (
 	// an open regression.
 	openRegressionConfidenceAdjustment = 5
 
+	// maxSampleFailedJobRuns bounds the failed job run URLs returned for each regressed test. More failures can be
+	// found on the test details page.
+	maxSampleFailedJobRuns = 10
+
 	// This query de-dupes the test results. There are multiple issues present in
 	// our data set:
 	//
@@ -199,6 +206,7 @@ func GetComponentReportFromBigQuery(client *bqcachedclient.Client, prowURL, gcsB
 		RequestTestIdentificationOptions: reqOptions.TestIDOption,
 		RequestVariantOptions:            reqOptions.VariantOption,
 		RequestAdvancedOptions:           reqOptions.AdvancedOption,
+		BasisPins:                        reqOptions.BasisPins,
 	}
 
 	return api.GetDataFromCacheOrGenerate[crtype.ComponentReport](
@@ -226,7 +234,10 @@ type componentReportGenerator struct {
 	crtype.RequestTestIdentificationOptions
 	crtype.RequestVariantOptions
 	crtype.RequestAdvancedOptions
+	BasisPins       []crtype.BasisPin
 	openRegressions []crtype.TestRegression
+	// memory accounts for the test status read while generating the report
+	memory *util.MemoryBudget
 }
 
 func (c *componentReportGenerator) GetComponentReportCacheKey(prefix string) api.CacheData {
@@ -315,6 +326,8 @@ func (c *componentReportGenerator) GenerateJobVariants() (crtype.JobVariants, []
 
 func (c *componentReportGenerator) GenerateReport() (crtype.ComponentReport, []error) {
 	before := time.Now()
+	c.memory = newReportMemoryBudget()
+	defer c.memory.Close()
 	componentReportTestStatus, errs := c.GenerateComponentReportTestStatus()
 	if len(errs) > 0 {
 		return crtype.ComponentReport{}, errs
@@ -371,6 +384,13 @@ func (c *componentReportGenerator) getCommonTestStatusQuery(allJobVariants crtyp
 		jobNameQueryPortion = pullRequestDynamicJobNameCol
 	}
 
+	// The most recent failures are only needed to link to from regressed tests in the sample.
+	selectFailures := ""
+	if isSample {
+		selectFailures = fmt.Sprintf(`ARRAY_AGG(IF(adjusted_success_val = 0 AND adjusted_flake_count = 0, file_path, NULL) IGNORE NULLS ORDER BY modified_time DESC LIMIT %d) AS failed_file_paths,
+						`, maxSampleFailedJobRuns)
+	}
+
 	// WARNING: returning additional columns from this query will require explicit parsing in deserializeRowToTestStatus
 	// TODO: jira_component and jira_component_id appear to not be used? Could save bigquery costs if we remove them.
 	queryString := fmt.Sprintf(`WITH latest_component_mapping AS (
@@ -387,12 +407,12 @@ func (c *componentReportGenerator) getCommonTestStatusQuery(allJobVariants crtyp
 						COUNT(cm.id) AS total_count,
 						SUM(adjusted_success_val) AS success_count,
 						SUM(adjusted_flake_count) AS flake_count,
-						ANY_VALUE(cm.component) AS component,
+						%sANY_VALUE(cm.component) AS component,
 						ANY_VALUE(cm.capabilities) AS capabilities,
 					FROM (%s)
 					INNER JOIN latest_component_mapping cm ON testsuite = cm.suite AND test_name = cm.name
 `,
-		c.client.Dataset, c.client.Dataset, selectVariants, fmt.Sprintf(dedupedJunitTable, jobNameQueryPortion, c.client.Dataset, c.client.Dataset))
+		c.client.Dataset, c.client.Dataset, selectVariants, selectFailures, fmt.Sprintf(dedupedJunitTable, jobNameQueryPortion, c.client.Dataset, c.client.Dataset))
 
 	queryString += joinVariants
 
@@ -514,7 +534,7 @@ func (b *baseQueryGenerator) queryTestStatus() (crtype.ReportTestStatus, []error
 		},
 	}...)
 
-	baseStatus, baseErrs := fetchTestStatus(baseQuery)
+	baseStatus, baseErrs := fetchTestStatus(baseQuery, b.ComponentReportGenerator.memory)
 
 	if len(baseErrs) != 0 {
 		errs = append(errs, baseErrs...)
@@ -597,7 +617,7 @@ func (s *sampleQueryGenerator) queryTestStatus() (crtype.ReportTestStatus, []err
 		}...)
 	}
 
-	sampleStatus, sampleErrs := fetchTestStatus(sampleQuery)
+	sampleStatus, sampleErrs := fetchTestStatus(sampleQuery, s.ComponentReportGenerator.memory)
 
 	if len(sampleErrs) != 0 {
 		errs = append(errs, sampleErrs...)
@@ -634,11 +654,103 @@ func (c *componentReportGenerator) getTestStatusFromBigQuery() (crtype.ReportTes
 	if len(baseErrs) != 0 || len(sampleErrs) != 0 {
 		errs = append(errs, baseErrs...)
 		errs = append(errs, sampleErrs...)
+	} else {
+		baseStatus, errs = c.applyBasisPins(allJobVariants, baseStatus)
 	}
 	log.Infof("getTestStatusFromBigQuery completed in %s with %d sample results and %d base results from db", time.Since(before), len(sampleStatus), len(baseStatus))
 	return crtype.ReportTestStatus{BaseStatus: baseStatus, SampleStatus: sampleStatus}, errs
 }
 
+// getPinnedBaseQueryStatus returns the basis test status in a pin's basis, for only the pinned test or component.
+func (c *componentReportGenerator) getPinnedBaseQueryStatus(allJobVariants crtype.JobVariants, pin crtype.BasisPin) (map[string]crtype.TestStatus, []error) {
+	pinned := *c
+	pinned.BaseRelease = pin.Basis
+	pinned.BasisPins = nil
+	if pin.TestID != "" {
+		pinned.TestID = pin.TestID
+	}
+	baseQuery, baseGrouping, baseParams := pinned.getCommonTestStatusQuery(allJobVariants, false)
+	if pin.Component != "" {
+		baseQuery += ` AND cm.component = @PinnedComponent`
+		baseParams = append(baseParams, bigquery.QueryParameter{
+			Name:  "PinnedComponent",
+			Value: pin.Component,
+		})
+	}
+	generator := baseQueryGenerator{
+		client:                   c.client,
+		cacheOption:              c.cacheOption,
+		commonQuery:              baseQuery,
+		groupByQuery:             baseGrouping,
+		queryParameters:          baseParams,
+		ComponentReportGenerator: &pinned,
+	}
+
+	componentReportTestStatus, errs := api.GetDataFromCacheOrGenerate[crtype.ReportTestStatus](c.client.Cache, generator.cacheOption, api.GetPrefixedCacheKey("BaseTestStatus~", generator), generator.queryTestStatus, crtype.ReportTestStatus{})
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return componentReportTestStatus.BaseStatus, nil
+}
+
+// applyBasisPins replaces the basis status of pinned tests with their status in the pinned basis. Component pins
+// are applied first, so a test's own pin takes precedence.
+func (c *componentReportGenerator) applyBasisPins(allJobVariants crtype.JobVariants, baseStatus map[string]crtype.TestStatus) (map[string]crtype.TestStatus, []error) {
+	pins := make([]crtype.BasisPin, len(c.BasisPins))
+	copy(pins, c.BasisPins)
+	sort.SliceStable(pins, func(i, j int) bool {
+		return pins[i].TestID == "" && pins[j].TestID != ""
+	})
+	for _, pin := range pins {
+		pinnedStatus, errs := c.getPinnedBaseQueryStatus(allJobVariants, pin)
+		if len(errs) > 0 {
+			return nil, errs
+		}
+		if err := replaceBasisStatus(baseStatus, pinnedStatus, pin); err != nil {
+			return nil, []error{err}
+		}
+	}
+	return baseStatus, nil
+}
+
+// replaceBasisStatus removes the tests matching a pin from the basis status, and adds their pinned status.
+func replaceBasisStatus(baseStatus, pinnedStatus map[string]crtype.TestStatus, pin crtype.BasisPin) error {
+	for key, stats := range baseStatus {
+		if pin.Component != "" {
+			if stats.Component == pin.Component {
+				delete(baseStatus, key)
+			}
+			continue
+		}
+		var testIdentification crtype.TestIdentification
+		if err := json.Unmarshal([]byte(key), &testIdentification); err != nil {
+			return err
+		}
+		if testIdentification.TestID == pin.TestID {
+			delete(baseStatus, key)
+		}
+	}
+	for key, stats := range pinnedStatus {
+		baseStatus[key] = stats
+	}
+	return nil
+}
+
+// basisPinFor returns the pin replacing the basis for a test, if any.
+func (c *componentReportGenerator) basisPinFor(testID, component string) *crtype.BasisPin {
+	var componentPin *crtype.BasisPin
+	for i := range c.BasisPins {
+		pin := &c.BasisPins[i]
+		if pin.TestID != "" && pin.TestID == testID {
+			return pin
+		}
+		if pin.Component != "" && pin.Component == component {
+			componentPin = pin
+		}
+	}
+	return componentPin
+}
+
 var componentAndCapabilityGetter func(test crtype.TestIdentification, stats crtype.TestStatus) (string, []string)
 
 func testToComponentAndCapability(_ crtype.TestIdentification, stats crtype.TestStatus) (string, []string) {
@@ -715,7 +827,8 @@ func (c *componentReportGenerator) getRowColumnIdentifications(testIDStr string,
 	return rows, columns, nil
 }
 
-func fetchTestStatus(query *bigquery.Query) (map[string]crtype.TestStatus, []error) {
+// fetchTestStatus reads the test status from a query, and stops reading once it's over the memory budget.
+func fetchTestStatus(query *bigquery.Query, budget *util.MemoryBudget) (map[string]crtype.TestStatus, []error) {
 	errs := []error{}
 	status := map[string]crtype.TestStatus{}
 	log.Infof("Fetching test status with:\n%s\nParameters:\n%+v\n", query.Q, query.Parameters)
@@ -747,6 +860,11 @@ func fetchTestStatus(query *bigquery.Query) (map[string]crtype.TestStatus, []err
 			errs = append(errs, err2)
 			continue
 		}
+		if err := reserveTestStatus(budget, testIDStr, testStatus); err != nil {
+			log.WithError(err).Warning("stopped reading test status")
+			errs = append(errs, err)
+			return status, errs
+		}
 
 		status[testIDStr] = testStatus
 	}
@@ -809,6 +927,10 @@ func deserializeRowToTestStatus(row []bigquery.Value, schema bigquery.Schema) (s
 			for i := range capArr {
 				cts.Capabilities[i] = capArr[i].(string)
 			}
+		case col == "failed_file_paths":
+			for _, path := range row[i].([]bigquery.Value) {
+				cts.FailedFilePaths = append(cts.FailedFilePaths, path.(string))
+			}
 		case strings.HasPrefix(col, "variant_"):
 			variantName := col[len("variant_"):]
 			if row[i] != nil {
@@ -919,13 +1041,16 @@ type cellStatus struct {
 
 func getNewCellStatus(testID crtype.ReportTestIdentification,
 	testStats crtype.ReportTestStats,
+	sampleFailedJobRuns []string,
 	existingCellStatus *cellStatus,
 	triagedIncidents []crtype.TriagedIncident,
 	openRegressions []crtype.TestRegression) cellStatus {
 	var newCellStatus cellStatus
 	if existingCellStatus != nil {
 		if (testStats.ReportStatus < crtype.NotSignificant && testStats.ReportStatus < existingCellStatus.status) ||
-			(existingCellStatus.status == crtype.NotSignificant && testStats.ReportStatus == crtype.SignificantImprovement) {
+			(existingCellStatus.status == crtype.NotSignificant && testStats.ReportStatus == crtype.SignificantImprovement) ||
+			// any test that can run in the cell says more than one that can't
+			existingCellStatus.status == crtype.NotApplicable {
 			// We want to show the significant improvement if assessment is not regression
 			newCellStatus.status = testStats.ReportStatus
 		} else {
@@ -942,6 +1067,7 @@ func getNewCellStatus(testID crtype.ReportTestIdentification,
 		rt := crtype.ReportTestSummary{
 			ReportTestIdentification: testID,
 			ReportTestStats:          testStats,
+			SampleFailedJobRuns:      sampleFailedJobRuns,
 		}
 		if len(openRegressions) > 0 {
 			release := openRegressions[0].Release // grab release from first regression, they were queried only for sample release
@@ -957,6 +1083,7 @@ func getNewCellStatus(testID crtype.ReportTestIdentification,
 			ReportTestSummary: crtype.ReportTestSummary{
 				ReportTestIdentification: testID,
 				ReportTestStats:          testStats,
+				SampleFailedJobRuns:      sampleFailedJobRuns,
 			}}
 		if len(openRegressions) > 0 {
 			release := openRegressions[0].Release
@@ -975,6 +1102,7 @@ func updateCellStatus(rowIdentifications []crtype.RowIdentification,
 	columnIdentifications []crtype.ColumnID,
 	testID crtype.ReportTestIdentification,
 	testStats crtype.ReportTestStats,
+	sampleFailedJobRuns []string,
 	status map[crtype.RowIdentification]map[crtype.ColumnID]cellStatus,
 	allRows map[crtype.RowIdentification]struct{},
 	allColumns map[crtype.ColumnID]struct{},
@@ -999,16 +1127,16 @@ func updateCellStatus(rowIdentifications []crtype.RowIdentification,
 		if !ok {
 			row = map[crtype.ColumnID]cellStatus{}
 			for _, columnIdentification := range columnIdentifications {
-				row[columnIdentification] = getNewCellStatus(testID, testStats, nil, triagedIncidents, openRegressions)
+				row[columnIdentification] = getNewCellStatus(testID, testStats, sampleFailedJobRuns, nil, triagedIncidents, openRegressions)
 				status[rowIdentification] = row
 			}
 		} else {
 			for _, columnIdentification := range columnIdentifications {
 				existing, ok := row[columnIdentification]
 				if !ok {
-					row[columnIdentification] = getNewCellStatus(testID, testStats, nil, triagedIncidents, openRegressions)
+					row[columnIdentification] = getNewCellStatus(testID, testStats, sampleFailedJobRuns, nil, triagedIncidents, openRegressions)
 				} else {
-					row[columnIdentification] = getNewCellStatus(testID, testStats, &existing, triagedIncidents, openRegressions)
+					row[columnIdentification] = getNewCellStatus(testID, testStats, sampleFailedJobRuns, &existing, triagedIncidents, openRegressions)
 				}
 			}
 		}
@@ -1332,6 +1460,10 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[st
 	// allRows and allColumns are used to make sure rows are ordered and all rows have the same columns in the same order
 	allRows := map[crtype.RowIdentification]struct{}{}
 	allColumns := map[crtype.ColumnID]struct{}{}
+	usedPins := map[uint]bool{}
+	shadow := newShadowEvaluator(c.ShadowAlgorithms)
+	// tests that can never run in some variants are reported as not applicable there, rather than missing or regressed
+	notApplicable := testidentification.DefaultNotApplicableRules()
 	// testID is used to identify the most regressed test. With this, we can
 	// create a shortcut link from any page to go straight to the most regressed test page.
 	for testIdentification, baseStats := range baseStatus {
@@ -1343,15 +1475,22 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[st
 		var testStats crtype.ReportTestStats
 		var triagedIncidents []crtype.TriagedIncident
 		var resolvedIssueCompensation int
+		var sampleFailedJobRuns []string
+		baseRelease := c.BaseRelease.Release
+		pin := c.basisPinFor(testID.TestID, testID.Component)
+		if pin != nil {
+			baseRelease = pin.Basis.Release
+		}
 		sampleStats, ok := sampleStatus[testIdentification]
 		if !ok {
 			testStats.ReportStatus = crtype.MissingSample
 		} else {
+			sampleFailedJobRuns = c.jobRunURLs(sampleStats.FailedFilePaths)
 			var approvedRegression, baseRegression *regressionallowances.IntentionalRegression
 			if len(c.VariantCrossCompare) == 0 { // only really makes sense when not cross-comparing variants:
 				// look for corresponding regressions we can account for in the analysis
 				approvedRegression = regressionallowances.IntentionalRegressionFor(c.SampleRelease.Release, testID.ColumnIdentification, testID.TestID)
-				baseRegression = regressionallowances.IntentionalRegressionFor(c.BaseRelease.Release, testID.ColumnIdentification, testID.TestID)
+				baseRegression = regressionallowances.IntentionalRegressionFor(baseRelease, testID.ColumnIdentification, testID.TestID)
 				// ignore triage if we have an intentional regression
 				if approvedRegression == nil {
 					resolvedIssueCompensation, triagedIncidents = c.triagedIncidentsFor(testID)
@@ -1381,6 +1520,19 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[st
 					testStats.ReportStatus = crtype.NotSignificant
 				}
 			}
+			shadow.evaluate(testID, testStats, shadowInput{
+				requiredConfidence: requiredConfidence,
+				pityFactor:         c.PityFactor,
+				minimumFailure:     c.MinimumFailure,
+			})
+		}
+		if notApplicable.Match(testID.TestName, testID.Variants) != nil {
+			testStats.ReportStatus = crtype.NotApplicable
+		}
+		if pin != nil {
+			testStats.BaseStats.Release = baseRelease
+			testStats.BasisPin = pin
+			usedPins[pin.ID] = true
 		}
 		delete(sampleStatus, testIdentification)
 
@@ -1388,7 +1540,7 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[st
 		if err != nil {
 			return crtype.ComponentReport{}, err
 		}
-		updateCellStatus(rowIdentifications, columnIdentifications, testID, testStats, aggregatedStatus, allRows, allColumns, triagedIncidents, c.openRegressions)
+		updateCellStatus(rowIdentifications, columnIdentifications, testID, testStats, sampleFailedJobRuns, aggregatedStatus, allRows, allColumns, triagedIncidents, c.openRegressions)
 	}
 	// Those sample ones are missing base stats
 	for testIdentification, sampleStats := range sampleStatus {
@@ -1401,7 +1553,10 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[st
 			return crtype.ComponentReport{}, err
 		}
 		testStats := crtype.ReportTestStats{ReportStatus: crtype.MissingBasis}
-		updateCellStatus(rowIdentifications, columnIdentification, testID, testStats, aggregatedStatus, allRows, allColumns, nil, c.openRegressions)
+		if notApplicable.Match(testID.TestName, testID.Variants) != nil {
+			testStats.ReportStatus = crtype.NotApplicable
+		}
+		updateCellStatus(rowIdentifications, columnIdentification, testID, testStats, nil, aggregatedStatus, allRows, allColumns, nil, c.openRegressions)
 	}
 
 	// Sort the row identifications
@@ -1437,6 +1592,12 @@ func (c *componentReportGenerator) generateComponentTestReport(baseStatus map[st
 		return crtype.ComponentReport{}, err
 	}
 	report.Rows = rows
+	report.ShadowEvaluations = shadow.results()
+	for _, pin := range c.BasisPins {
+		if usedPins[pin.ID] {
+			report.BasisPins = append(report.BasisPins, pin)
+		}
+	}
 	return report, nil
 }
 
@@ -1519,6 +1680,32 @@ func buildTestID(stats crtype.TestStatus, testIdentificationStr string) (crtype.
 	return testID, nil
 }
 
+// jobRunURL returns the prow URL of the job run that produced a junit file.
+func jobRunURL(filePath, prowURL, gcsBucket string) string {
+	var path string
+	subs := strings.Split(filePath, "/artifacts/")
+	if len(subs) > 1 {
+		path = subs[0]
+	}
+	return artifacturls.Default().WithLocation(prowURL, gcsBucket).JobRunURL(path)
+}
+
+// jobRunURLs returns the unique job run URLs for junit files, preserving order. A job run can have more than one
+// junit file containing the same test.
+func (c *componentReportGenerator) jobRunURLs(filePaths []string) []string {
+	var urls []string
+	seen := sets.NewString()
+	for _, path := range filePaths {
+		url := jobRunURL(path, c.prowURL, c.gcsBucket)
+		if seen.Has(url) {
+			continue
+		}
+		seen.Insert(url)
+		urls = append(urls, url)
+	}
+	return urls
+}
+
 func getFailureCount(status crtype.JobRunTestStatusRow) int {
 	failure := status.TotalCount - status.SuccessCount - status.FlakeCount
 	if failure < 0 {
@@ -1711,11 +1898,11 @@ func (c *componentReportGenerator) assessComponentStatus(requiredConfidence, sam
 }
 
 func (c *componentReportGenerator) fischerExactTest(confidenceRequired, sampleTotal, sampleSuccess, sampleFlake, baseTotal, baseSuccess, baseFlake int) (bool, float64) {
-	_, _, r, _ := fischer.FisherExactTest(sampleTotal-sampleSuccess-sampleFlake,
-		sampleSuccess+sampleFlake,
-		baseTotal-baseSuccess-baseFlake,
-		baseSuccess+baseFlake)
-	return r < 1-float64(confidenceRequired)/100, r
+	r := stats.FisherExact(
+		stats.Counts{Successes: sampleSuccess + sampleFlake, Failures: sampleTotal - sampleSuccess - sampleFlake},
+		stats.Counts{Successes: baseSuccess + baseFlake, Failures: baseTotal - baseSuccess - baseFlake},
+	).Greater
+	return stats.Significant(r, confidenceRequired), r
 }
 
 func (c *componentReportGenerator) getUniqueJUnitColumnValuesLast60Days(field string, nested bool) ([]string, error) {
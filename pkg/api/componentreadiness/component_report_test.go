@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/openshift/sippy/pkg/util"
 	"github.com/openshift/sippy/pkg/util/sets"
 	"github.com/stretchr/testify/assert"
 )
@@ -1507,3 +1508,133 @@ func Test_componentReportGenerator_assessComponentStatus(t *testing.T) {
 		})
 	}
 }
+
+func Test_componentReportGenerator_sampleFailedJobRuns(t *testing.T) {
+	test := crtype.TestIdentification{
+		TestID: "1",
+		Variants: map[string]string{
+			"Platform":     "aws",
+			"Architecture": "amd64",
+			"Network":      "ovn",
+		},
+	}
+	testBytes, err := json.Marshal(test)
+	assert.NoError(t, err)
+
+	generator := defaultComponentReportGenerator
+	generator.prowURL = "https://prow.ci.openshift.org"
+	baseStatus := map[string]crtype.TestStatus{
+		string(testBytes): {TestName: "test 1", TotalCount: 1000, SuccessCount: 990},
+	}
+	sampleStatus := map[string]crtype.TestStatus{
+		string(testBytes): {
+			TestName:     "test 1",
+			TotalCount:   100,
+			SuccessCount: 50,
+			FailedFilePaths: []string{
+				"logs/periodic-ci-e2e-aws-ovn/3/artifacts/e2e/junit/junit_e2e_1.xml",
+				"logs/periodic-ci-e2e-aws-ovn/3/artifacts/e2e/junit/junit_e2e_2.xml",
+				"logs/periodic-ci-e2e-aws-ovn/2/artifacts/e2e/junit/junit_e2e_1.xml",
+			},
+		},
+	}
+
+	componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+	report, err := generator.generateComponentTestReport(baseStatus, sampleStatus)
+	assert.NoError(t, err)
+	if assert.Len(t, report.Rows, 1) && assert.Len(t, report.Rows[0].Columns, 1) && assert.Len(t, report.Rows[0].Columns[0].RegressedTests, 1) {
+		assert.Equal(t, []string{
+			"https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-e2e-aws-ovn/3",
+			"https://prow.ci.openshift.org/view/gs/test-platform-results/logs/periodic-ci-e2e-aws-ovn/2",
+		}, report.Rows[0].Columns[0].RegressedTests[0].SampleFailedJobRuns)
+	}
+}
+
+func Test_componentReportGenerator_basisPins(t *testing.T) {
+	key := func(testID string) string {
+		b, err := json.Marshal(crtype.TestIdentification{TestID: testID, Variants: map[string]string{"Platform": "aws"}})
+		assert.NoError(t, err)
+		return string(b)
+	}
+	testPin := crtype.BasisPin{ID: 1, TestID: "1", Basis: crtype.RequestReleaseOptions{Release: "4.14"}, Reason: "bad 4.15 GA week"}
+	componentPin := crtype.BasisPin{ID: 2, Component: "component 2", Basis: crtype.RequestReleaseOptions{Release: "4.13"}, Reason: "flaky storage"}
+
+	t.Run("replace basis status", func(t *testing.T) {
+		baseStatus := map[string]crtype.TestStatus{
+			key("1"): {TestName: "test 1", Component: "component 1", TotalCount: 10},
+			key("2"): {TestName: "test 2", Component: "component 2", TotalCount: 10},
+			key("3"): {TestName: "test 3", Component: "component 2", TotalCount: 10},
+		}
+		assert.NoError(t, replaceBasisStatus(baseStatus, map[string]crtype.TestStatus{
+			key("2"): {TestName: "test 2", Component: "component 2", TotalCount: 20},
+		}, componentPin))
+		// test 3 has no runs in the pinned basis, so no longer has a basis
+		assert.Len(t, baseStatus, 2)
+		assert.Equal(t, 20, baseStatus[key("2")].TotalCount)
+
+		assert.NoError(t, replaceBasisStatus(baseStatus, map[string]crtype.TestStatus{
+			key("1"): {TestName: "test 1", Component: "component 1", TotalCount: 30},
+		}, testPin))
+		assert.Equal(t, 30, baseStatus[key("1")].TotalCount)
+		assert.Equal(t, 20, baseStatus[key("2")].TotalCount)
+	})
+
+	t.Run("test pin takes precedence", func(t *testing.T) {
+		generator := componentReportGenerator{BasisPins: []crtype.BasisPin{componentPin, {ID: 3, TestID: "2", Reason: "test"}}}
+		assert.EqualValues(t, 3, generator.basisPinFor("2", "component 2").ID)
+		assert.EqualValues(t, 2, generator.basisPinFor("3", "component 2").ID)
+		assert.Nil(t, generator.basisPinFor("1", "component 1"))
+	})
+
+	t.Run("report annotation", func(t *testing.T) {
+		generator := defaultComponentReportGenerator
+		generator.BaseRelease = crtype.RequestReleaseOptions{Release: "4.15"}
+		generator.BasisPins = []crtype.BasisPin{testPin, componentPin}
+		baseStatus := map[string]crtype.TestStatus{
+			key("1"): {TestName: "test 1", TotalCount: 1000, SuccessCount: 990},
+		}
+		sampleStatus := map[string]crtype.TestStatus{
+			key("1"): {TestName: "test 1", TotalCount: 100, SuccessCount: 50},
+		}
+
+		componentAndCapabilityGetter = fakeComponentAndCapabilityGetter
+		report, err := generator.generateComponentTestReport(baseStatus, sampleStatus)
+		assert.NoError(t, err)
+		assert.Equal(t, []crtype.BasisPin{testPin}, report.BasisPins)
+		if assert.Len(t, report.Rows, 1) && assert.Len(t, report.Rows[0].Columns, 1) && assert.Len(t, report.Rows[0].Columns[0].RegressedTests, 1) {
+			regressed := report.Rows[0].Columns[0].RegressedTests[0]
+			assert.Equal(t, "4.14", regressed.BaseStats.Release)
+			assert.Equal(t, &testPin, regressed.BasisPin)
+		}
+	})
+}
+
+func Test_getNewCellStatus_notApplicable(t *testing.T) {
+	testID := crtype.ReportTestIdentification{}
+	notApplicable := getNewCellStatus(testID, crtype.ReportTestStats{ReportStatus: crtype.NotApplicable}, nil, nil, nil, nil)
+	assert.Equal(t, crtype.NotApplicable, notApplicable.status)
+
+	// a test that can run in the cell replaces one that can't, but not the other way around
+	cell := getNewCellStatus(testID, crtype.ReportTestStats{ReportStatus: crtype.NotSignificant}, nil, &notApplicable, nil, nil)
+	assert.Equal(t, crtype.NotSignificant, cell.status)
+	cell = getNewCellStatus(testID, crtype.ReportTestStats{ReportStatus: crtype.NotApplicable}, nil, &cell, nil, nil)
+	assert.Equal(t, crtype.NotSignificant, cell.status)
+}
+
+func Test_reserveTestStatus(t *testing.T) {
+	status := crtype.TestStatus{TestName: "test", Variants: []string{"Platform:aws", "Network:ovn"}}
+	budget := util.NewMemoryBudget(1000, nil)
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = reserveTestStatus(budget, "key", status)
+	}
+	assert.ErrorIs(t, err, ErrReportTooLarge)
+	assert.Less(t, budget.Used(), int64(1000))
+
+	// a report within its own budget waits on the others when they've used all of the shared one
+	shared := util.NewMemoryBudget(1000, nil)
+	assert.NoError(t, shared.Reserve(900))
+	err = reserveTestStatus(util.NewMemoryBudget(1000, shared), "key", status)
+	assert.ErrorIs(t, err, ErrReportMemoryUnavailable)
+	assert.NotErrorIs(t, err, ErrReportTooLarge)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/openshift/sippy/pkg/api"
@@ -41,12 +42,19 @@ func ParseComponentReportRequest(
 		}
 	} else {
 		opts.BaseRelease.Release = req.URL.Query().Get("baseRelease")
+		opts.SampleRelease.Release = req.URL.Query().Get("sampleRelease")
+		// Cross-comparing variants without a basis release compares the included and compared variants
+		// within the sample release.
+		crossCompareOnly := opts.BaseRelease.Release == "" && opts.SampleRelease.Release != "" &&
+			len(req.URL.Query()["variantCrossCompare"]) > 0
+		if crossCompareOnly {
+			opts.BaseRelease.Release = opts.SampleRelease.Release
+		}
 		if opts.BaseRelease.Release == "" {
 			err = fmt.Errorf("missing baseRelease")
 			return
 		}
 
-		opts.SampleRelease.Release = req.URL.Query().Get("sampleRelease")
 		if opts.SampleRelease.Release == "" {
 			err = fmt.Errorf("missing sampleRelease")
 			return
@@ -63,15 +71,20 @@ func ParseComponentReportRequest(
 
 		// TODO: if specified, allow these to override view defaults for start/end time.
 		// will need to relocate this outside this else.
-		opts.BaseRelease, err = parseDateRange(req, opts.BaseRelease, "baseStartTime", "baseEndTime", crTimeRoundingFactor)
-		if err != nil {
-			return
-		}
 		opts.SampleRelease, err = parseDateRange(req, opts.SampleRelease, "sampleStartTime", "sampleEndTime", crTimeRoundingFactor)
 		if err != nil {
 			return
 		}
-
+		if crossCompareOnly && req.URL.Query().Get("baseStartTime") == "" && req.URL.Query().Get("baseEndTime") == "" {
+			// compare the variants over the same period
+			opts.BaseRelease.Start = opts.SampleRelease.Start
+			opts.BaseRelease.End = opts.SampleRelease.End
+		} else {
+			opts.BaseRelease, err = parseDateRange(req, opts.BaseRelease, "baseStartTime", "baseEndTime", crTimeRoundingFactor)
+			if err != nil {
+				return
+			}
+		}
 	}
 
 	// Params below this point can be used with and without views:
@@ -188,6 +201,13 @@ func parseVariantOptions(req *http.Request, allJobVariants crtype.JobVariants) (
 	}
 
 	opts.VariantCrossCompare = req.URL.Query()["variantCrossCompare"]
+	for _, group := range opts.VariantCrossCompare {
+		// cross-compared variants differ between the basis and sample, so they can't identify the same test in both
+		if opts.DBGroupBy.Has(group) {
+			err = fmt.Errorf("dbGroupBy cannot contain variant being cross-compared: %s", group)
+			return
+		}
+	}
 	if len(opts.VariantCrossCompare) > 0 {
 		// when we are cross-comparing variants, we need to construct the compareVariants map from the parameters.
 		// the resulting compareVariants map is includeVariants...
@@ -265,6 +285,13 @@ func parseAdvancedOptions(req *http.Request) (advancedOption crtype.RequestAdvan
 	if err != nil {
 		return advancedOption, err
 	}
+
+	if shadow := req.URL.Query().Get("shadow"); shadow != "" {
+		advancedOption.ShadowAlgorithms = strings.Split(shadow, ",")
+		if err = ValidateShadowAlgorithms(advancedOption.ShadowAlgorithms); err != nil {
+			return advancedOption, err
+		}
+	}
 	return
 }
 
@@ -331,6 +331,66 @@ func TestParseComponentReportRequest(t *testing.T) {
 				ForceRefresh: false,
 			},
 		},
+		{
+			name: "variant cross-compare within the sample release",
+			queryParams: [][]string{
+				{"columnGroupBy", "Platform,Network"},
+				{"dbGroupBy", "Platform,Network,Upgrade"},
+				{"sampleEndTime", "2024-04-11T23:59:59Z"},
+				{"sampleRelease", "4.16"},
+				{"sampleStartTime", "2024-04-04T00:00:05Z"},
+				{"includeVariant", "Architecture:amd64"},
+				{"variantCrossCompare", "Architecture"},
+				{"compareVariant", "Architecture:arm64"},
+			},
+			variantOption: crtype.RequestVariantOptions{
+				ColumnGroupBy:       sets.NewString("Platform", "Network"),
+				DBGroupBy:           sets.NewString("Platform", "Network", "Upgrade"),
+				IncludeVariants:     map[string][]string{"Architecture": {"amd64"}},
+				CompareVariants:     map[string][]string{"Architecture": {"arm64"}},
+				VariantCrossCompare: []string{"Architecture"},
+				RequestedVariants:   map[string]string{},
+			},
+			baseRelease: crtype.RequestReleaseOptions{
+				Release: "4.16",
+				Start:   time.Date(2024, time.April, 4, 0, 0, 5, 0, time.UTC),
+				End:     time.Date(2024, time.April, 11, 23, 59, 59, 0, time.UTC),
+			},
+			sampleRelease: crtype.RequestReleaseOptions{
+				Release: "4.16",
+				Start:   time.Date(2024, time.April, 4, 0, 0, 5, 0, time.UTC),
+				End:     time.Date(2024, time.April, 11, 23, 59, 59, 0, time.UTC),
+			},
+			testIDOption: crtype.RequestTestIdentificationOptions{},
+			advancedOption: crtype.RequestAdvancedOptions{
+				MinimumFailure:   3,
+				Confidence:       95,
+				PityFactor:       5,
+				IgnoreMissing:    false,
+				IgnoreDisruption: true,
+			},
+			cacheOption: cache.RequestOptions{
+				ForceRefresh: false,
+			},
+		},
+		{
+			name: "variant cross-compare of a db group by variant",
+			queryParams: [][]string{
+				{"columnGroupBy", "Platform,Network"},
+				{"dbGroupBy", "Platform,Network,Architecture"},
+				{"sampleRelease", "4.16"},
+				{"variantCrossCompare", "Architecture"},
+				{"compareVariant", "Architecture:arm64"},
+			},
+			errMessage: "dbGroupBy cannot contain variant being cross-compared",
+		},
+		{
+			name: "missing base release without variant cross-compare",
+			queryParams: [][]string{
+				{"sampleRelease", "4.16"},
+			},
+			errMessage: "missing baseRelease",
+		},
 		{
 			name: "cross-compare view",
 			queryParams: [][]string{
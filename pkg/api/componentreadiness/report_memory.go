@@ -0,0 +1,63 @@
+package componentreadiness
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+var (
+	// ErrReportTooLarge is returned when the test status of a report would take more memory than it's allowed, the
+	// request should be narrowed with variant filters or a shorter time range.
+	ErrReportTooLarge = errors.New("component report is too large")
+	// ErrReportMemoryUnavailable is returned when the reports being generated at once have used all the memory they're
+	// allowed, the request should be retried once they're done.
+	ErrReportMemoryUnavailable = errors.New("too many component reports are being generated")
+)
+
+// testStatusOverhead approximates the memory of a test status and its map entry besides its strings.
+const testStatusOverhead = 256
+
+var (
+	reportMemoryLock      sync.RWMutex
+	reportMemoryPerReport int64
+	reportMemoryTotal     *util.MemoryBudget
+)
+
+// SetReportMemoryLimits bounds the approximate memory used by the test status of a single report being generated,
+// and by all reports being generated at once. A limit of 0 is unlimited.
+func SetReportMemoryLimits(perReport, total int64) {
+	reportMemoryLock.Lock()
+	defer reportMemoryLock.Unlock()
+	reportMemoryPerReport = perReport
+	reportMemoryTotal = util.NewMemoryBudget(total, nil)
+}
+
+// newReportMemoryBudget returns the budget for generating one report. It must be closed once the report is built.
+func newReportMemoryBudget() *util.MemoryBudget {
+	reportMemoryLock.RLock()
+	defer reportMemoryLock.RUnlock()
+	return util.NewMemoryBudget(reportMemoryPerReport, reportMemoryTotal)
+}
+
+// reserveTestStatus accounts for a test status read for a report, failing with ErrReportTooLarge once the report
+// is over its budget, or ErrReportMemoryUnavailable once all the reports being generated are over theirs.
+func reserveTestStatus(budget *util.MemoryBudget, key string, status crtype.TestStatus) error {
+	size := int64(testStatusOverhead + len(key) + len(status.TestName) + len(status.TestSuite) + len(status.Component))
+	for _, values := range [][]string{status.Capabilities, status.Variants, status.FailedFilePaths} {
+		for _, v := range values {
+			size += int64(len(v)) + 16
+		}
+	}
+	err := budget.Reserve(size)
+	if errors.Is(err, util.ErrSharedMemoryBudgetExceeded) {
+		return fmt.Errorf("%w, try again shortly (%s)", ErrReportMemoryUnavailable, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%w, narrow it with variant filters or a shorter time range (%s)", ErrReportTooLarge, err)
+	}
+	return nil
+}
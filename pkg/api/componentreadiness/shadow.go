@@ -0,0 +1,129 @@
+package componentreadiness
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/stats"
+)
+
+// ShadowAlgorithmWilson flags a regression when the upper bound of the Wilson score interval of the sample pass
+// rate, at the required confidence, is below the basis pass rate less the pity factor.
+const ShadowAlgorithmWilson = "wilson"
+
+// shadowInput is what a candidate algorithm gets to decide on a test: the same counts, after discounting
+// triaged job runs, and thresholds the current algorithm used.
+type shadowInput struct {
+	sample             crtype.TestDetailsTestStats
+	base               crtype.TestDetailsTestStats
+	requiredConfidence int
+	pityFactor         int
+	minimumFailure     int
+}
+
+// shadowDetector returns whether a test regressed, and the statistic that decision was based on.
+type shadowDetector func(in shadowInput) (bool, float64)
+
+var shadowDetectors = map[string]shadowDetector{
+	ShadowAlgorithmWilson: wilsonDetector,
+}
+
+// ShadowAlgorithms returns the names of the candidate algorithms that can be run in shadow mode.
+func ShadowAlgorithms() []string {
+	names := make([]string, 0, len(shadowDetectors))
+	for name := range shadowDetectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateShadowAlgorithms checks that each requested shadow algorithm exists.
+func ValidateShadowAlgorithms(algorithms []string) error {
+	for _, a := range algorithms {
+		if _, ok := shadowDetectors[a]; !ok {
+			return fmt.Errorf("unknown shadow algorithm %q, must be one of: %s", a, strings.Join(ShadowAlgorithms(), ", "))
+		}
+	}
+	return nil
+}
+
+// shadowEvaluator runs the requested candidate algorithms on each test assessed for a report, and tallies their
+// agreement with the current algorithm.
+type shadowEvaluator struct {
+	detectors   map[string]shadowDetector
+	evaluations map[string]*crtype.ShadowEvaluation
+}
+
+func newShadowEvaluator(algorithms []string) *shadowEvaluator {
+	e := &shadowEvaluator{detectors: map[string]shadowDetector{}, evaluations: map[string]*crtype.ShadowEvaluation{}}
+	for _, a := range algorithms {
+		if d, ok := shadowDetectors[a]; ok {
+			e.detectors[a] = d
+			e.evaluations[a] = &crtype.ShadowEvaluation{Algorithm: a}
+		}
+	}
+	return e
+}
+
+func (e *shadowEvaluator) evaluate(testID crtype.ReportTestIdentification, testStats crtype.ReportTestStats, in shadowInput) {
+	// Triaged regressions are not listed as regressed tests in the report, nor tracked as regressions.
+	currentRegressed := testStats.ReportStatus < crtype.ExtremeTriagedRegression
+	in.sample = testStats.SampleStats.TestDetailsTestStats
+	in.base = testStats.BaseStats.TestDetailsTestStats
+
+	for name, detect := range e.detectors {
+		candidateRegressed, score := detect(in)
+		evaluation := e.evaluations[name]
+		evaluation.TestsEvaluated++
+		switch {
+		case currentRegressed && candidateRegressed:
+			evaluation.BothRegressed++
+		case currentRegressed:
+			evaluation.CurrentOnly++
+		case candidateRegressed:
+			evaluation.CandidateOnly++
+		default:
+			continue
+		}
+		evaluation.Verdicts = append(evaluation.Verdicts, crtype.ShadowVerdict{
+			ReportTestIdentification: testID,
+			CurrentStatus:            testStats.ReportStatus,
+			CurrentRegressed:         currentRegressed,
+			CandidateRegressed:       candidateRegressed,
+			CandidateScore:           score,
+		})
+	}
+}
+
+func (e *shadowEvaluator) results() []crtype.ShadowEvaluation {
+	if len(e.evaluations) == 0 {
+		return nil
+	}
+	results := make([]crtype.ShadowEvaluation, 0, len(e.evaluations))
+	for _, evaluation := range e.evaluations {
+		sort.Slice(evaluation.Verdicts, func(i, j int) bool {
+			return evaluation.Verdicts[i].TestID < evaluation.Verdicts[j].TestID
+		})
+		results = append(results, *evaluation)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Algorithm < results[j].Algorithm })
+	return results
+}
+
+func wilsonDetector(in shadowInput) (bool, float64) {
+	sampleTotal := in.sample.SuccessCount + in.sample.FailureCount + in.sample.FlakeCount
+	baseTotal := in.base.SuccessCount + in.base.FailureCount + in.base.FlakeCount
+	if sampleTotal == 0 || baseTotal == 0 {
+		return false, 0
+	}
+
+	upper := stats.WilsonUpperBound(in.sample.SuccessCount+in.sample.FlakeCount, sampleTotal, in.requiredConfidence)
+	if in.minimumFailure != 0 && in.sample.FailureCount < in.minimumFailure {
+		return false, upper
+	}
+	basisPassRate := float64(in.base.SuccessCount+in.base.FlakeCount) / float64(baseTotal)
+	return upper < basisPassRate-float64(in.pityFactor)/100, upper
+}
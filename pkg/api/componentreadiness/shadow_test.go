@@ -0,0 +1,83 @@
+package componentreadiness
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+)
+
+func shadowStats(status crtype.Status, sampleSuccess, sampleFailure, baseSuccess, baseFailure int) crtype.ReportTestStats {
+	return crtype.ReportTestStats{
+		ReportStatus: status,
+		SampleStats: crtype.TestDetailsReleaseStats{TestDetailsTestStats: crtype.TestDetailsTestStats{
+			SuccessCount: sampleSuccess, FailureCount: sampleFailure,
+		}},
+		BaseStats: crtype.TestDetailsReleaseStats{TestDetailsTestStats: crtype.TestDetailsTestStats{
+			SuccessCount: baseSuccess, FailureCount: baseFailure,
+		}},
+	}
+}
+
+func TestWilsonDetector(t *testing.T) {
+	in := shadowInput{requiredConfidence: 95, pityFactor: 5, minimumFailure: 3}
+
+	in.sample = crtype.TestDetailsTestStats{SuccessCount: 80, FailureCount: 20}
+	in.base = crtype.TestDetailsTestStats{SuccessCount: 990, FailureCount: 10}
+	regressed, score := wilsonDetector(in)
+	assert.True(t, regressed)
+	assert.Less(t, score, 0.94)
+
+	// Within the pity factor
+	in.sample = crtype.TestDetailsTestStats{SuccessCount: 970, FailureCount: 30}
+	regressed, _ = wilsonDetector(in)
+	assert.False(t, regressed)
+
+	// Below the minimum failures
+	in.sample = crtype.TestDetailsTestStats{SuccessCount: 3, FailureCount: 2}
+	regressed, _ = wilsonDetector(in)
+	assert.False(t, regressed)
+
+	regressed, score = wilsonDetector(shadowInput{})
+	assert.False(t, regressed)
+	assert.Equal(t, 0.0, score)
+}
+
+func TestShadowEvaluator(t *testing.T) {
+	assert.Nil(t, newShadowEvaluator(nil).results())
+	assert.Error(t, ValidateShadowAlgorithms([]string{"bogus"}))
+	require.NoError(t, ValidateShadowAlgorithms([]string{ShadowAlgorithmWilson}))
+
+	e := newShadowEvaluator([]string{ShadowAlgorithmWilson})
+	in := shadowInput{requiredConfidence: 95, pityFactor: 5, minimumFailure: 3}
+	id := func(testID string) crtype.ReportTestIdentification {
+		return crtype.ReportTestIdentification{RowIdentification: crtype.RowIdentification{TestID: testID}}
+	}
+
+	// both regressed
+	e.evaluate(id("d"), shadowStats(crtype.ExtremeRegression, 50, 50, 990, 10), in)
+	// only the current algorithm, the sample has fewer failures than the candidate requires
+	e.evaluate(id("c"), shadowStats(crtype.SignificantRegression, 98, 2, 1000, 0), in)
+	// only the candidate
+	e.evaluate(id("b"), shadowStats(crtype.NotSignificant, 80, 20, 990, 10), in)
+	// neither, triaged regressions don't count as regressed for the current algorithm
+	e.evaluate(id("a"), shadowStats(crtype.SignificantTriagedRegression, 99, 1, 990, 10), in)
+
+	results := e.results()
+	require.Len(t, results, 1)
+	r := results[0]
+	assert.Equal(t, ShadowAlgorithmWilson, r.Algorithm)
+	assert.Equal(t, 4, r.TestsEvaluated)
+	assert.Equal(t, 1, r.BothRegressed)
+	assert.Equal(t, 1, r.CurrentOnly)
+	assert.Equal(t, 1, r.CandidateOnly)
+	require.Len(t, r.Verdicts, 3)
+	assert.Equal(t, "b", r.Verdicts[0].TestID)
+	assert.True(t, r.Verdicts[0].CandidateRegressed)
+	assert.False(t, r.Verdicts[0].CurrentRegressed)
+	assert.Equal(t, "c", r.Verdicts[1].TestID)
+	assert.True(t, r.Verdicts[1].CurrentRegressed)
+	assert.False(t, r.Verdicts[1].CandidateRegressed)
+}
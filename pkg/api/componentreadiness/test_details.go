@@ -4,18 +4,17 @@ import (
 	"fmt"
 	"slices"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
 	bigquery2 "cloud.google.com/go/bigquery"
-	fet "github.com/glycerine/golang-fisher-exact"
 	"github.com/openshift/sippy/pkg/api"
 	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
 	"github.com/openshift/sippy/pkg/apis/cache"
 	"github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/componentreadiness/tracker"
 	"github.com/openshift/sippy/pkg/regressionallowances"
+	"github.com/openshift/sippy/pkg/stats"
 	"github.com/sirupsen/logrus"
 )
 
@@ -31,6 +30,7 @@ func GetTestDetails(client *bigquery.Client, prowURL, gcsBucket string, reqOptio
 		RequestTestIdentificationOptions: reqOptions.TestIDOption,
 		RequestVariantOptions:            reqOptions.VariantOption,
 		RequestAdvancedOptions:           reqOptions.AdvancedOption,
+		BasisPins:                        reqOptions.BasisPins,
 	}
 
 	return api.GetDataFromCacheOrGenerate[crtype.ReportTestDetails](
@@ -50,6 +50,10 @@ func (c *componentReportGenerator) GenerateTestDetailsReport() (crtype.ReportTes
 			return crtype.ReportTestDetails{}, []error{fmt.Errorf("all dbGroupBy variants have to be defined for test details: %s is missing", v)}
 		}
 	}
+	// A pinned test is compared against its pinned basis throughout
+	if pin := c.basisPinFor(c.TestID, c.Component); pin != nil {
+		c.BaseRelease = pin.Basis
+	}
 
 	componentJobRunTestReportStatus, errs := c.GenerateJobRunTestReportStatus()
 	if len(errs) > 0 {
@@ -427,11 +431,11 @@ func (c *componentReportGenerator) internalGenerateTestDetailsReport(baseStatus
 		jobStats.SampleStats.FlakeCount = perJobSampleFlake
 		jobStats.SampleStats.FailureCount = perJobSampleFailure
 		jobStats.SampleStats.SuccessRate = getSuccessRate(perJobSampleSuccess, perJobSampleFailure, perJobSampleFlake)
-		_, _, r, _ := fet.FisherExactTest(perJobSampleFailure,
-			perJobSampleSuccess,
-			perJobBaseFailure,
-			perJobSampleSuccess)
-		jobStats.Significant = r < 1-float64(c.Confidence)/100
+		r := stats.FisherExact(
+			stats.Counts{Successes: perJobSampleSuccess, Failures: perJobSampleFailure},
+			stats.Counts{Successes: perJobSampleSuccess, Failures: perJobBaseFailure},
+		).Greater
+		jobStats.Significant = stats.Significant(r, c.Confidence)
 
 		result.JobStats = append(result.JobStats, jobStats)
 
@@ -460,11 +464,11 @@ func (c *componentReportGenerator) internalGenerateTestDetailsReport(baseStatus
 		jobStats.SampleStats.FailureCount = perJobSampleFailure
 		jobStats.SampleStats.SuccessRate = getSuccessRate(perJobSampleSuccess, perJobSampleFailure, perJobSampleFlake)
 		result.JobStats = append(result.JobStats, jobStats)
-		_, _, r, _ := fet.FisherExactTest(perJobSampleFailure,
-			perJobSampleSuccess+perJobSampleFlake,
-			0,
-			0)
-		jobStats.Significant = r < 1-float64(c.Confidence)/100
+		r := stats.FisherExact(
+			stats.Counts{Successes: perJobSampleSuccess + perJobSampleFlake, Failures: perJobSampleFailure},
+			stats.Counts{},
+		).Greater
+		jobStats.Significant = stats.Significant(r, c.Confidence)
 
 		totalSampleFailure += perJobSampleFailure
 		totalSampleSuccess += perJobSampleSuccess
@@ -488,17 +492,13 @@ func (c *componentReportGenerator) internalGenerateTestDetailsReport(baseStatus
 		baseRegression,
 		resolvedIssueCompensation,
 	)
+	result.BasisPin = c.basisPinFor(c.TestID, c.Component)
 
 	return result
 }
 
 func getJobRunStats(stats crtype.JobRunTestStatusRow, prowURL, gcsBucket string) crtype.TestDetailsJobRunStats {
 	failure := getFailureCount(stats)
-	url := fmt.Sprintf("%s/view/gs/%s/", prowURL, gcsBucket)
-	subs := strings.Split(stats.FilePath, "/artifacts/")
-	if len(subs) > 1 {
-		url += subs[0]
-	}
 	jobRunStats := crtype.TestDetailsJobRunStats{
 		TestStats: crtype.TestDetailsTestStats{
 			SuccessRate:  getSuccessRate(stats.SuccessCount, failure, stats.FlakeCount),
@@ -506,7 +506,7 @@ func getJobRunStats(stats crtype.JobRunTestStatusRow, prowURL, gcsBucket string)
 			FailureCount: failure,
 			FlakeCount:   stats.FlakeCount,
 		},
-		JobURL: url,
+		JobURL: jobRunURL(stats.FilePath, prowURL, gcsBucket),
 	}
 	return jobRunStats
 }
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/dashboards"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+var (
+	// ErrDashboardNotFound is returned when a dashboard does not exist.
+	ErrDashboardNotFound = errors.New("dashboard not found")
+
+	// ErrDashboardReadOnly is returned when modifying a dashboard defined in the repo.
+	ErrDashboardReadOnly = errors.New("dashboard is defined in the repo and can only be changed by a pull request")
+)
+
+// ListDashboards returns the dashboards defined in the repo and stored in the database, sorted by name.
+func ListDashboards(dbc *db.DB) ([]dashboards.Dashboard, error) {
+	result, err := dashboards.Builtin()
+	if err != nil {
+		return nil, err
+	}
+	builtin := map[string]bool{}
+	for _, d := range result {
+		builtin[d.Name] = true
+	}
+
+	var stored []models.Dashboard
+	if res := dbc.DB.Order("name").Find(&stored); res.Error != nil {
+		return nil, res.Error
+	}
+	for _, s := range stored {
+		// Repo dashboards take precedence, in case one was added with the name of a stored dashboard.
+		if builtin[s.Name] {
+			continue
+		}
+		d, err := dashboards.Parse([]byte(s.Definition))
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing stored dashboard %s", s.Name)
+		}
+		d.Source = dashboards.SourceDB
+		result = append(result, *d)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// GetDashboard returns a single dashboard by name.
+func GetDashboard(dbc *db.DB, name string) (*dashboards.Dashboard, error) {
+	all, err := ListDashboards(dbc)
+	if err != nil {
+		return nil, err
+	}
+	for i := range all {
+		if all[i].Name == name {
+			return &all[i], nil
+		}
+	}
+	return nil, ErrDashboardNotFound
+}
+
+// SaveDashboard validates a YAML dashboard definition and stores it, replacing any stored dashboard with the same
+// name. It returns whether the dashboard was created rather than replaced.
+func SaveDashboard(dbc *db.DB, definition []byte, author string) (*dashboards.Dashboard, bool, error) {
+	d, err := dashboards.Parse(definition)
+	if err != nil {
+		return nil, false, err
+	}
+	d.Source = dashboards.SourceDB
+	if err := checkDashboardWritable(d.Name); err != nil {
+		return nil, false, err
+	}
+
+	stored := &models.Dashboard{}
+	res := dbc.DB.Where("name = ?", d.Name).First(stored)
+	created := errors.Is(res.Error, gorm.ErrRecordNotFound)
+	if res.Error != nil && !created {
+		return nil, false, res.Error
+	}
+	stored.Name = d.Name
+	stored.Definition = string(definition)
+	stored.Author = author
+	return d, created, dbc.DB.Save(stored).Error
+}
+
+// DeleteDashboard deletes a dashboard stored in the database. Deletion is permanent so the name can be reused.
+func DeleteDashboard(dbc *db.DB, name string) error {
+	if err := checkDashboardWritable(name); err != nil {
+		return err
+	}
+	res := dbc.DB.Unscoped().Where("name = ?", name).Delete(&models.Dashboard{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDashboardNotFound
+	}
+	return nil
+}
+
+func checkDashboardWritable(name string) error {
+	builtin, err := dashboards.Builtin()
+	if err != nil {
+		return err
+	}
+	for _, d := range builtin {
+		if d.Name == name {
+			return ErrDashboardReadOnly
+		}
+	}
+	return nil
+}
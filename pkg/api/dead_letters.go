@@ -0,0 +1,37 @@
+package api
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// ErrDeadLetterNotFound is returned when retrying a job run that is not dead-lettered.
+var ErrDeadLetterNotFound = errors.New("dead-lettered job run not found")
+
+// ListDeadLetteredJobRuns returns the job runs skipped by imports after failing repeatedly, optionally only those
+// of one job, most recently attempted first.
+func ListDeadLetteredJobRuns(dbc *db.DB, job string) ([]models.JobRunImportFailure, error) {
+	failures := []models.JobRunImportFailure{}
+	q := dbc.DB.Where("attempts >= ?", models.MaxJobRunImportAttempts).Order("last_attempt_at DESC")
+	if job != "" {
+		q = q.Where("prow_job_name = ?", job)
+	}
+	return failures, q.Find(&failures).Error
+}
+
+// RetryDeadLetteredJobRun removes a job run from the dead-letter table, so the next import attempts it again if it
+// is still within the range of job runs being imported.
+func RetryDeadLetteredJobRun(dbc *db.DB, prowJobRunID uint) error {
+	res := dbc.DB.Unscoped().
+		Where("prow_job_run_id = ? AND attempts >= ?", prowJobRunID, models.MaxJobRunImportAttempts).
+		Delete(&models.JobRunImportFailure{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrDeadLetterNotFound
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/featureflags"
+)
+
+// ErrFeatureFlagNotFound is returned when a feature flag is not declared in the config, or has no override.
+var ErrFeatureFlagNotFound = errors.New("feature flag not found")
+
+// RefreshFeatureFlags loads the runtime overrides stored in the database into the manager.
+func RefreshFeatureFlags(dbc *db.DB, manager *featureflags.Manager) error {
+	var stored []models.FeatureFlagOverride
+	if res := dbc.DB.Find(&stored); res.Error != nil {
+		return res.Error
+	}
+	overrides := make([]featureflags.Flag, 0, len(stored))
+	for _, o := range stored {
+		overrides = append(overrides, featureflags.Flag{Name: o.Name, Enabled: o.Enabled, Percentage: o.Percentage})
+	}
+	manager.SetOverrides(overrides)
+	return nil
+}
+
+// SaveFeatureFlagOverride validates and stores a runtime override for a declared flag, and applies it to the manager.
+func SaveFeatureFlagOverride(dbc *db.DB, manager *featureflags.Manager, flag featureflags.Flag, author string) (*featureflags.State, error) {
+	if err := flag.Validate(); err != nil {
+		return nil, err
+	}
+	if !manager.Known(flag.Name) {
+		return nil, ErrFeatureFlagNotFound
+	}
+
+	stored := &models.FeatureFlagOverride{}
+	res := dbc.DB.Where("name = ?", flag.Name).First(stored)
+	if res.Error != nil && !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, res.Error
+	}
+	stored.Name = flag.Name
+	stored.Enabled = flag.Enabled
+	stored.Percentage = flag.Percentage
+	stored.Author = author
+	if err := dbc.DB.Save(stored).Error; err != nil {
+		return nil, err
+	}
+
+	if err := RefreshFeatureFlags(dbc, manager); err != nil {
+		return nil, err
+	}
+	state, _ := manager.Get(flag.Name)
+	return &state, nil
+}
+
+// DeleteFeatureFlagOverride removes a runtime override, returning the flag to its configured state.
+func DeleteFeatureFlagOverride(dbc *db.DB, manager *featureflags.Manager, name string) (*featureflags.State, error) {
+	res := dbc.DB.Unscoped().Where("name = ?", name).Delete(&models.FeatureFlagOverride{})
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrFeatureFlagNotFound
+	}
+
+	if err := RefreshFeatureFlags(dbc, manager); err != nil {
+		return nil, err
+	}
+	state, ok := manager.Get(name)
+	if !ok {
+		return nil, ErrFeatureFlagNotFound
+	}
+	return &state, nil
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// DefaultFingerprintMinRuns is the number of runs a fingerprint value needs to be reported.
+const DefaultFingerprintMinRuns = 10
+
+// GetFingerprintCorrelation compares how often job runs failed with each value of their fingerprint attributes
+// (cloud region, instance types, component versions, etc.) against the runs with the attribute's other values,
+// to find environments that correlate with failures. If testName is set, the test's failures are compared instead.
+func GetFingerprintCorrelation(dbc *db.DB, release, attribute, testName string, start, end time.Time, minRuns int) ([]apitype.FingerprintCorrelation, error) {
+	results, err := query.FingerprintFailures(dbc, release, attribute, testName, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return buildFingerprintCorrelations(results, minRuns), nil
+}
+
+func buildFingerprintCorrelations(results []query.FingerprintResults, minRuns int) []apitype.FingerprintCorrelation {
+	type totals struct {
+		runs     int
+		failures int
+	}
+	attributeTotals := map[string]totals{}
+	for _, r := range results {
+		t := attributeTotals[r.Attribute]
+		t.runs += r.Runs
+		t.failures += r.Failures
+		attributeTotals[r.Attribute] = t
+	}
+
+	correlations := make([]apitype.FingerprintCorrelation, 0)
+	for _, r := range results {
+		if r.Runs < minRuns {
+			continue
+		}
+		t := attributeTotals[r.Attribute]
+		c := apitype.FingerprintCorrelation{
+			Attribute:         r.Attribute,
+			Value:             r.Value,
+			Runs:              r.Runs,
+			Failures:          r.Failures,
+			FailurePercentage: *percentage(r.Failures, r.Runs),
+			BaselineRuns:      t.runs - r.Runs,
+			BaselineFailures:  t.failures - r.Failures,
+		}
+		if c.BaselineRuns > 0 {
+			c.BaselineFailurePercentage = *percentage(c.BaselineFailures, c.BaselineRuns)
+		}
+		if c.BaselineFailurePercentage > 0 {
+			lift := c.FailurePercentage / c.BaselineFailurePercentage
+			c.Lift = &lift
+		}
+		correlations = append(correlations, c)
+	}
+
+	// Most correlated values first within each attribute, with values the baseline can't be compared to last
+	sort.SliceStable(correlations, func(i, j int) bool {
+		a, b := correlations[i], correlations[j]
+		if a.Attribute != b.Attribute {
+			return a.Attribute < b.Attribute
+		}
+		if (a.Lift == nil) != (b.Lift == nil) {
+			return a.Lift != nil
+		}
+		if a.Lift != nil && *a.Lift != *b.Lift {
+			return *a.Lift > *b.Lift
+		}
+		return a.FailurePercentage > b.FailurePercentage
+	})
+	return correlations
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestBuildFingerprintCorrelations(t *testing.T) {
+	results := []query.FingerprintResults{
+		{Attribute: "cloud_region", Value: "us-east-1", Runs: 80, Failures: 8},
+		{Attribute: "cloud_region", Value: "us-west-2", Runs: 20, Failures: 8},
+		{Attribute: "cloud_region", Value: "eu-west-1", Runs: 2, Failures: 2},
+		{Attribute: "os_image", Value: "RHCOS 416.94", Runs: 30, Failures: 3},
+	}
+
+	correlations := buildFingerprintCorrelations(results, 10)
+	require.Len(t, correlations, 3)
+
+	west := correlations[0]
+	assert.Equal(t, "us-west-2", west.Value)
+	assert.InDelta(t, 40.0, west.FailurePercentage, 0.01)
+	// The baseline includes values below min runs
+	assert.Equal(t, 82, west.BaselineRuns)
+	assert.Equal(t, 10, west.BaselineFailures)
+	require.NotNil(t, west.Lift)
+	assert.InDelta(t, 40.0/(1000.0/82), *west.Lift, 0.01)
+
+	assert.Equal(t, "us-east-1", correlations[1].Value)
+
+	// A single value has nothing to compare to
+	image := correlations[2]
+	assert.Equal(t, "os_image", image.Attribute)
+	assert.Equal(t, 0, image.BaselineRuns)
+	assert.Nil(t, image.Lift)
+}
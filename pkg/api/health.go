@@ -3,8 +3,6 @@ package api
 import (
 	"math"
 	"net/http"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/montanaflynn/stats"
@@ -19,29 +17,6 @@ import (
 	"github.com/openshift/sippy/pkg/util"
 )
 
-// useNewInstallTest decides which install test name to use based on releases. For
-// release 4.11 and above, it uses the new install test names
-func useNewInstallTest(release string) bool {
-	digits := strings.Split(release, ".")
-	if len(digits) < 2 {
-		return false
-	}
-	major, err := strconv.Atoi(digits[0])
-	if err != nil {
-		return false
-	}
-	minor, err := strconv.Atoi(digits[1])
-	if err != nil {
-		return false
-	}
-	if major < 4 {
-		return false
-	} else if major == 4 && minor < 11 {
-		return false
-	}
-	return true
-}
-
 // PrintOverallReleaseHealthFromDB gives a summarized status of the overall health, including
 // infrastructure, install, upgrade, and variant success rates.
 func PrintOverallReleaseHealthFromDB(w http.ResponseWriter, dbc *db.DB, release string, reportEnd time.Time) {
@@ -54,7 +29,7 @@ func PrintOverallReleaseHealthFromDB(w http.ResponseWriter, dbc *db.DB, release
 
 	infraTestName := testidentification.InfrastructureTestName
 	installTestName := testidentification.InstallTestName
-	if useNewInstallTest(release) {
+	if testidentification.UseNewInstallTest(release) {
 		infraTestName = testidentification.NewInfrastructureTestName
 		installTestName = testidentification.NewInstallTestName
 	}
@@ -164,7 +139,7 @@ func calculateJobResultStatistics(results []apitype.Job) (currStats, prevStats s
 			continue
 		}
 
-		if util.IsNeverStable(result.Variants) {
+		if util.IsNeverStable(result.Variants) || testidentification.IsRehearsal(result.Variants) {
 			continue
 		}
 
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// importStalledAfter is how long an unfinished import can go without progress before it's reported as stalled.
+// Progress is saved every 30 seconds, and a single job run rarely takes more than a few minutes to import.
+const importStalledAfter = 10 * time.Minute
+
+// GetImportProgress returns the most recent imports, optionally only those by one loader, newest first.
+func GetImportProgress(dbc *db.DB, loader string, limit int, now time.Time) ([]apitype.ImportProgress, error) {
+	var records []models.ImportProgress
+	q := dbc.DB.Order("id DESC").Limit(limit)
+	if loader != "" {
+		q = q.Where("loader = ?", loader)
+	}
+	if err := q.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]apitype.ImportProgress, 0, len(records))
+	for _, r := range records {
+		results = append(results, importProgress(r, now))
+	}
+	return results, nil
+}
+
+func importProgress(r models.ImportProgress, now time.Time) apitype.ImportProgress {
+	running := r.FinishedAt == nil
+	return apitype.ImportProgress{
+		ID:             r.ID,
+		Loader:         r.Loader,
+		StartedAt:      r.CreatedAt,
+		LastProgressAt: r.LastProgressAt,
+		FinishedAt:     r.FinishedAt,
+		Running:        running,
+		Stalled:        running && now.Sub(r.LastProgressAt) > importStalledAfter,
+		Errors:         r.Errors,
+		RowsWritten:    r.RowsWritten,
+		Phases: []apitype.ImportPhase{
+			// discovery lists every job run at once, so is complete as soon as anything is discovered
+			importPhase("jobs_discovered", r.JobRunsDiscovered, r.JobRunsDiscovered, !running),
+			importPhase("runs_fetched", r.JobRunsFetched, r.JobRunsDiscovered, !running),
+			importPhase("junit_parsed", r.JunitParsed, r.JobRunsToImport, !running),
+			importPhase("rows_written", r.JobRunsWritten, r.JobRunsToImport, !running),
+		},
+	}
+}
+
+// importPhase calculates a phase's percentage. Phases with nothing to do are complete only once the import has
+// finished, since more work may still be discovered.
+func importPhase(name string, completed, total int, done bool) apitype.ImportPhase {
+	phase := apitype.ImportPhase{Name: name, Completed: completed, Total: total}
+	switch {
+	case total > 0:
+		phase.Percentage = float64(completed) * 100 / float64(total)
+	case done:
+		phase.Percentage = 100
+	}
+	return phase
+}
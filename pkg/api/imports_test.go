@@ -0,0 +1,55 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestImportProgress(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	record := models.ImportProgress{
+		Model:             models.Model{ID: 1, CreatedAt: now.Add(-time.Hour)},
+		Loader:            "prow",
+		JobRunsDiscovered: 200,
+		JobRunsFetched:    50,
+		JobRunsToImport:   20,
+		JunitParsed:       10,
+		JobRunsWritten:    5,
+		RowsWritten:       5000,
+		LastProgressAt:    now.Add(-time.Minute),
+	}
+
+	progress := importProgress(record, now)
+	assert.True(t, progress.Running)
+	assert.False(t, progress.Stalled)
+	percentages := map[string]float64{}
+	for _, phase := range progress.Phases {
+		percentages[phase.Name] = phase.Percentage
+	}
+	assert.Equal(t, map[string]float64{
+		"jobs_discovered": 100,
+		"runs_fetched":    25,
+		"junit_parsed":    50,
+		"rows_written":    25,
+	}, percentages)
+
+	record.LastProgressAt = now.Add(-time.Hour)
+	assert.True(t, importProgress(record, now).Stalled, "no progress for an hour")
+
+	finished := now
+	record.FinishedAt = &finished
+	assert.False(t, importProgress(record, now).Stalled, "finished imports are never stalled")
+
+	// A finished import with nothing to import is complete
+	empty := importProgress(models.ImportProgress{JobRunsDiscovered: 10, JobRunsFetched: 10, FinishedAt: &finished}, now)
+	for _, phase := range empty.Phases {
+		assert.Equal(t, 100.0, phase.Percentage, phase.Name)
+	}
+	// but while running, more may still be found
+	empty = importProgress(models.ImportProgress{JobRunsDiscovered: 10, JobRunsFetched: 10}, now)
+	assert.Equal(t, 0.0, empty.Phases[2].Percentage)
+}
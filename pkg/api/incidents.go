@@ -0,0 +1,120 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// ErrIncidentNotFound is returned when an incident does not exist.
+var ErrIncidentNotFound = errors.New("incident not found")
+
+var validIncidentKinds = map[string]bool{
+	models.IncidentKindInfrastructure: true,
+	models.IncidentKindPayload:        true,
+	models.IncidentKindOther:          true,
+}
+
+// ValidateIncident ensures an incident submitted via the API is well-formed.
+func ValidateIncident(incident *models.Incident) error {
+	if strings.TrimSpace(incident.Title) == "" {
+		return fmt.Errorf("title is required")
+	}
+	if incident.Kind == "" {
+		incident.Kind = models.IncidentKindOther
+	}
+	if !validIncidentKinds[incident.Kind] {
+		return fmt.Errorf("invalid kind %q: must be infrastructure, payload, or other", incident.Kind)
+	}
+	if incident.StartTime.IsZero() {
+		return fmt.Errorf("start_time is required")
+	}
+	if incident.EndTime != nil && !incident.EndTime.After(incident.StartTime) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	for _, v := range incident.Variants {
+		if !strings.Contains(v, ":") {
+			return fmt.Errorf("invalid variant %q: must be in the form Name:value", v)
+		}
+	}
+	return nil
+}
+
+// ListIncidents returns incidents overlapping the time range, optionally limited to a release and job.
+func ListIncidents(dbc *db.DB, release, job string, start, end time.Time) ([]models.Incident, error) {
+	return query.IncidentsOverlapping(dbc, release, job, start, end)
+}
+
+// GetIncident returns a single incident by ID.
+func GetIncident(dbc *db.DB, id uint) (*models.Incident, error) {
+	incident := &models.Incident{}
+	res := dbc.DB.First(incident, id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrIncidentNotFound
+	}
+	return incident, res.Error
+}
+
+// CreateIncident validates and stores a new incident.
+func CreateIncident(dbc *db.DB, incident *models.Incident) error {
+	incident.Model = models.Model{}
+	if err := ValidateIncident(incident); err != nil {
+		return err
+	}
+	return dbc.DB.Create(incident).Error
+}
+
+// UpdateIncident replaces the fields of an existing incident.
+func UpdateIncident(dbc *db.DB, id uint, incident *models.Incident) error {
+	existing, err := GetIncident(dbc, id)
+	if err != nil {
+		return err
+	}
+	if err := ValidateIncident(incident); err != nil {
+		return err
+	}
+	incident.Model = existing.Model
+	// The JIRA link is maintained by the jira-sync loader, keep it when the update doesn't include it
+	if incident.JiraKey == "" {
+		incident.JiraKey = existing.JiraKey
+		incident.TriageState = existing.TriageState
+	}
+	return dbc.DB.Save(incident).Error
+}
+
+// DeleteIncident soft deletes an incident, recording who deleted it so it can be restored.
+func DeleteIncident(dbc *db.DB, id uint, deletedBy string) error {
+	found, err := softDelete(dbc, &models.Incident{}, id, deletedBy)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrIncidentNotFound
+	}
+	return nil
+}
+
+// RestoreIncident undeletes a soft deleted incident.
+func RestoreIncident(dbc *db.DB, id uint) (*models.Incident, error) {
+	found, err := restoreDeleted(dbc, &models.Incident{}, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrIncidentNotFound
+	}
+	return GetIncident(dbc.Primary(), id)
+}
+
+// ListDeletedIncidents returns the soft deleted incidents, most recently deleted first.
+func ListDeletedIncidents(dbc *db.DB) ([]models.Incident, error) {
+	incidents := []models.Incident{}
+	return incidents, listDeleted(dbc, &incidents)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestValidateIncident(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+	after := start.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		incident     models.Incident
+		expectErr    bool
+		expectedKind string
+	}{
+		{
+			name:         "valid with default kind",
+			incident:     models.Incident{Title: "AWS quota exhausted", StartTime: start, EndTime: &after, Variants: pq.StringArray{"Platform:aws"}},
+			expectedKind: models.IncidentKindOther,
+		},
+		{
+			name:         "ongoing",
+			incident:     models.Incident{Title: "Bad payload", Kind: models.IncidentKindPayload, StartTime: start},
+			expectedKind: models.IncidentKindPayload,
+		},
+		{
+			name:      "missing title",
+			incident:  models.Incident{StartTime: start},
+			expectErr: true,
+		},
+		{
+			name:      "missing start",
+			incident:  models.Incident{Title: "Registry outage"},
+			expectErr: true,
+		},
+		{
+			name:      "end before start",
+			incident:  models.Incident{Title: "Registry outage", StartTime: start, EndTime: &before},
+			expectErr: true,
+		},
+		{
+			name:      "invalid kind",
+			incident:  models.Incident{Title: "Registry outage", Kind: "bogus", StartTime: start},
+			expectErr: true,
+		},
+		{
+			name:      "invalid variant",
+			incident:  models.Incident{Title: "Registry outage", StartTime: start, Variants: pq.StringArray{"aws"}},
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateIncident(&tt.incident)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedKind, tt.incident.Kind)
+		})
+	}
+}
@@ -19,16 +19,10 @@ import (
 func PrintInstallJSONReportFromDB(w http.ResponseWriter, dbc *db.DB, release string) {
 	excludedVariants := testidentification.DefaultExcludedVariants
 	excludedVariants = append(excludedVariants, "upgrade-minor")
-	exactTestNames := sets.NewString()
-	testPrefixes := sets.NewString(testidentification.OperatorInstallPrefix)
-	if useNewInstallTest(release) {
-		testPrefixes.Insert(testidentification.InstallTestNamePrefix)
-	} else {
-		exactTestNames = exactTestNames.Insert(testidentification.InstallTestName)
-	}
+	reportTests := testidentification.InstallReportTests(release)
 
 	variantColumns, tests, err := VariantTestsReport(dbc, release, v1.CurrentReport,
-		exactTestNames, testPrefixes, sets.NewString(), excludedVariants)
+		reportTests.Names, reportTests.Prefixes, reportTests.SubStrings, excludedVariants)
 	if err != nil {
 		log.WithError(err).Error("could not generate install report")
 		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Could not generate install report: " + err.Error()})
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// DefaultJobRunSearchLimit is how many runs a search returns by default.
+	DefaultJobRunSearchLimit = 100
+	// MaxJobRunSearchLimit is the most runs a search can return.
+	MaxJobRunSearchLimit = 1000
+)
+
+var jobRunResults = map[string]bool{
+	string(v1.JobSucceeded):             true,
+	string(v1.JobRunning):               true,
+	string(v1.JobInfrastructureFailure): true,
+	string(v1.JobInstallFailure):        true,
+	string(v1.JobUpgradeFailure):        true,
+	string(v1.JobTestFailure):           true,
+	string(v1.JobFailureBeforeSetup):    true,
+	string(v1.JobAborted):               true,
+	string(v1.JobUnknown):               true,
+}
+
+// pullRequestRef matches pull requests given as org/repo#number.
+var pullRequestRef = regexp.MustCompile(`^([^/#\s]+)/([^/#\s]+)#(\d+)$`)
+
+// ParseJobRunSearch reads the metadata to search job runs by from the request's params. The pull_request param is
+// either a link to the pull request or org/repo#number, variant and result may be repeated, and result may also be
+// comma separated.
+func ParseJobRunSearch(req *http.Request, start, end time.Time) (query.JobRunSearch, error) {
+	params := req.URL.Query()
+	search := query.JobRunSearch{
+		Release:      params.Get("release"),
+		Job:          params.Get("job"),
+		Payload:      params.Get("payload"),
+		BuildCluster: params.Get("build_cluster"),
+		Start:        start,
+		End:          end,
+		Limit:        DefaultJobRunSearchLimit,
+	}
+
+	if pr := params.Get("pull_request"); pr != "" {
+		if m := pullRequestRef.FindStringSubmatch(pr); m != nil {
+			search.PullRequestOrg, search.PullRequestRepo = m[1], m[2]
+			search.PullRequestNumber, _ = strconv.Atoi(m[3])
+		} else if u, err := url.Parse(pr); err == nil && u.Scheme != "" && u.Host != "" {
+			search.PullRequestLink = pr
+		} else {
+			return search, fmt.Errorf("invalid pull_request %q: must be a link or org/repo#number", pr)
+		}
+	}
+
+	for _, v := range params["variant"] {
+		if !strings.Contains(v, ":") {
+			return search, fmt.Errorf("invalid variant %q: must be in the form Name:value", v)
+		}
+		search.Variants = append(search.Variants, v)
+	}
+
+	for _, param := range params["result"] {
+		for _, result := range strings.Split(param, ",") {
+			if !jobRunResults[result] {
+				return search, fmt.Errorf("invalid result %q: must be an overall result code such as S or F", result)
+			}
+			search.Results = append(search.Results, result)
+		}
+	}
+
+	if limitParam := params.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > MaxJobRunSearchLimit {
+			return search, fmt.Errorf("invalid limit %q: must be between 1 and %d", limitParam, MaxJobRunSearchLimit)
+		}
+		search.Limit = limit
+	}
+	return search, nil
+}
+
+// SearchJobRuns returns the most recent job runs matching a search, with links to investigate each.
+func SearchJobRuns(dbc *db.DB, search query.JobRunSearch) ([]apitype.JobRunSummary, error) {
+	runs, err := query.SearchJobRuns(dbc, search)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		runs[i].URL, runs[i].ArtifactsURL = artifactURLs(runs[i].URL)
+		runs[i].Links = jobRunLinks(runs[i].ID)
+	}
+	return runs, nil
+}
+
+// jobRunLinks returns API links to a job run's risk analysis and intervals.
+func jobRunLinks(id uint) map[string]string {
+	return map[string]string{
+		"risk_analysis": fmt.Sprintf("/api/jobs/runs/risk_analysis?prow_job_run_id=%d", id),
+		"intervals":     fmt.Sprintf("/api/jobs/runs/intervals?prow_job_run_id=%d", id),
+	}
+}
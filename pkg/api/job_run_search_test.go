@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJobRunSearch(t *testing.T) {
+	end := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-14 * 24 * time.Hour)
+
+	req := httptest.NewRequest("GET", "/api/jobs/runs/search?release=4.16&payload=4.16.0-0.nightly-2024-05-14-000000"+
+		"&pull_request=openshift/origin%2328000&variant=Platform:aws&variant=Network:ovn&result=F,I&result=U&limit=10", nil)
+	search, err := ParseJobRunSearch(req, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, "4.16", search.Release)
+	assert.Equal(t, "4.16.0-0.nightly-2024-05-14-000000", search.Payload)
+	assert.Equal(t, "openshift", search.PullRequestOrg)
+	assert.Equal(t, "origin", search.PullRequestRepo)
+	assert.Equal(t, 28000, search.PullRequestNumber)
+	assert.Equal(t, []string{"Platform:aws", "Network:ovn"}, search.Variants)
+	assert.Equal(t, []string{"F", "I", "U"}, search.Results)
+	assert.Equal(t, 10, search.Limit)
+	assert.Equal(t, start, search.Start)
+
+	req = httptest.NewRequest("GET", "/api/jobs/runs/search?pull_request=https://github.com/openshift/origin/pull/28000", nil)
+	search, err = ParseJobRunSearch(req, start, end)
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/openshift/origin/pull/28000", search.PullRequestLink)
+	assert.Equal(t, DefaultJobRunSearchLimit, search.Limit)
+
+	for _, params := range []string{"pull_request=origin", "variant=aws", "result=X", "limit=0", "limit=1001"} {
+		_, err := ParseJobRunSearch(httptest.NewRequest("GET", "/api/jobs/runs/search?"+params, nil), start, end)
+		assert.Error(t, err, params)
+	}
+}
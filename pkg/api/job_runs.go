@@ -11,6 +11,7 @@ import (
 
 	"github.com/hashicorp/go-version"
 	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/artifacturls"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/db/query"
@@ -91,12 +92,30 @@ func JobsRunsReportFromDB(dbc *db.DB, filterOpts *filter.FilterOptions, release
 	}
 
 	res := q.Scan(&jobsResult)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	for i := range jobsResult {
+		jobsResult[i].URL, jobsResult[i].ArtifactsURL = artifactURLs(jobsResult[i].URL)
+	}
+
+	rows, err := filter.ProjectColumns(jobsResult, filterOpts.Columns)
 	return &apitype.PaginationResult{
-		Rows:      jobsResult,
+		Rows:      rows,
 		TotalRows: rowCount,
 		PageSize:  pagination.PerPage,
 		Page:      pagination.Page,
-	}, res.Error
+	}, err
+}
+
+// artifactURLs returns a run's URL and the URL browsing its artifacts, built from the deployment's templates. Runs
+// whose URL has no bucket path keep the URL prow reported.
+func artifactURLs(runURL string) (string, string) {
+	jobRun, browse, ok := artifacturls.Default().RunLinks(runURL)
+	if !ok {
+		return runURL, ""
+	}
+	return jobRun, browse
 }
 
 func FetchJobRun(dbc *db.DB, jobRunID int64, logger *log.Entry) (*models.ProwJobRun, int, error) {
@@ -370,7 +389,7 @@ func variantsTestResultFunc(dbc *db.DB) testResultsByVariantsFunc {
 			LinkOperator: "and",
 		}
 		testResults, overallTest, err := BuildTestsResults(dbc, release, "default", false, true,
-			fil)
+			apitype.PassRateStrict, &filter.FilterOptions{Filter: fil})
 		if err != nil {
 			return nil, err
 		}
@@ -442,3 +442,14 @@ func TestSelectRiskAnalysisResult(t *testing.T) {
 		})
 	}
 }
+
+func TestArtifactURLs(t *testing.T) {
+	runURL := "https://prow.ci.openshift.org/view/gs/origin-ci-test/logs/periodic-ci-openshift-release-master-ci-4.14-e2e-aws-ovn/1700000000000000000"
+	url, artifactsURL := artifactURLs(runURL)
+	assert.Equal(t, runURL, url)
+	assert.Equal(t, "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/origin-ci-test/logs/periodic-ci-openshift-release-master-ci-4.14-e2e-aws-ovn/1700000000000000000", artifactsURL)
+
+	url, artifactsURL = artifactURLs("https://example.com/job/1")
+	assert.Equal(t, "https://example.com/job/1", url, "URLs without a bucket path are kept")
+	assert.Empty(t, artifactsURL)
+}
@@ -2,7 +2,6 @@ package jobrunintervals
 
 import (
 	"context"
-	"encoding/json"
 	"regexp"
 	"strings"
 
@@ -89,34 +88,10 @@ func JobRunIntervals(gcsClient *storage.Client, dbc *db.DB, jobRunID int64, gcsB
 		logger.WithError(err).Errorf("error getting content for file: %s", fullGCSIntervalFile)
 		return nil, err
 	}
-	var newIntervals apitype.EventIntervalList
-	var legacyIntervals apitype.LegacyEventIntervalList
-	if err := json.Unmarshal(content, &newIntervals); err != nil {
-		log.WithError(err).Error("error unmarshaling intervals file, attempting to parse legacy schema instead")
-		if err := json.Unmarshal(content, &legacyIntervals); err != nil {
-			log.WithError(err).Error("error unmarshaling legacy intervals file, giving up")
-			return nil, err
-		}
-		log.Info("legacy interval files detected, successfully parsed")
-	}
-
-	// If legacy intervals is populated, we failed to parse this file with the new schema, so it must be an older
-	// intervals file. Translate it to look like a new.
-	// Memory use getting even worse in this path through the code but hopefully it won't be around for too long.
-	if len(legacyIntervals.Items) > 0 {
-		newIntervals = apitype.EventIntervalList{Items: make([]apitype.EventInterval, len(legacyIntervals.Items))}
-		for i, li := range legacyIntervals.Items {
-			interval := apitype.EventInterval{
-				Level:             li.Level,
-				Display:           li.Display,
-				Source:            li.Source,
-				StructuredLocator: li.StructuredLocator,
-				StructuredMessage: li.StructuredMessage,
-				From:              li.From,
-				To:                li.To,
-			}
-			newIntervals.Items[i] = interval
-		}
+	newIntervals, err := apitype.ParseEventIntervals(content)
+	if err != nil {
+		log.WithError(err).Error("error unmarshaling intervals file, giving up")
+		return nil, err
 	}
 
 	for i := range newIntervals.Items {
@@ -125,5 +100,5 @@ func JobRunIntervals(gcsClient *storage.Client, dbc *db.DB, jobRunID int64, gcsB
 
 	newIntervals.IntervalFilesAvailable = intervalFilesAvailable
 
-	return &newIntervals, nil
+	return newIntervals, nil
 }
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	gosort "sort"
 	"strconv"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -14,7 +15,10 @@ import (
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/featureflags"
 	"github.com/openshift/sippy/pkg/filter"
+	"github.com/openshift/sippy/pkg/stats"
+	"github.com/openshift/sippy/pkg/util"
 
 	v1sippyprocessing "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 )
@@ -24,6 +28,13 @@ type jobsAPIResult []apitype.Job
 const periodTwoDay = "twoDay"
 const currentPassPercentage = "current_pass_percentage"
 
+// DefaultPassRateHalfLife is how quickly runs lose weight with exponential weighting unless half_life is given.
+const DefaultPassRateHalfLife = 72 * time.Hour
+
+// ExponentialWeightingFlag is the feature flag that makes exponential weighting the default for job reports that
+// don't ask for a weighting, so it can be rolled out to a percentage of requests and compared with flat weighting.
+const ExponentialWeightingFlag = "exponential-job-pass-rates"
+
 func (jobs jobsAPIResult) sort(req *http.Request) jobsAPIResult {
 	sortField := req.URL.Query().Get("sortField")
 	sort := apitype.Sort(req.URL.Query().Get("sort"))
@@ -60,70 +71,107 @@ func PrintVariantReportFromDB(w http.ResponseWriter, req *http.Request,
 	dbc *db.DB, release string, reportEnd time.Time) {
 	// Preferred method of slicing is with start->boundary->end query params in the format ?start=2021-12-02&boundary=2021-12-07.
 	// 'end' can be specified if you wish to view historical reports rather than now, which is assumed if end param is absent.
-	var start time.Time
-	var boundary time.Time
-	var end time.Time
-	var err error
-
-	startParam := req.URL.Query().Get("start")
-	switch {
-	case startParam != "":
-		start, err = time.Parse("2006-01-02", startParam)
-		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding start param: %s", err.Error())})
-			return
-		}
-	case req.URL.Query().Get("period") == periodTwoDay:
-		// twoDay report period starts 9 days ago, (comparing last 2 days vs previous 7)
-		start = reportEnd.Add(-9 * 24 * time.Hour)
-	default:
-		// Default start to 14 days ago
-		start = reportEnd.Add(-14 * 24 * time.Hour)
-	}
-
-	// TODO: currently we're assuming dates use the 00:00:00, is it more logical to add 23:23 for boundary and end? or
-	// for callers to know to specify one day beyond.
-	boundaryParam := req.URL.Query().Get("boundary")
-	switch {
-	case boundaryParam != "":
-		boundary, err = time.Parse("2006-01-02", boundaryParam)
-		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding boundary param: %s", err.Error())})
-			return
-		}
-	case req.URL.Query().Get("period") == periodTwoDay:
-		boundary = reportEnd.Add(-2 * 24 * time.Hour)
-	default:
-		// Default boundary to 7 days ago
-		boundary = reportEnd.Add(-7 * 24 * time.Hour)
-	}
-
-	endParam := req.URL.Query().Get("end")
-	if endParam != "" {
-		end, err = time.Parse("2006-01-02", endParam)
-		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding end param: %s", err.Error())})
-			return
-		}
-	} else {
-		// Default end to now
-		end = reportEnd
+	start, boundary, end, err := ParsePeriodWindow(req, req.URL.Query().Get("period"), reportEnd)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
 	}
+	SetTimeWindowHeaders(w, start, boundary, end)
 
 	log.Debugf("Querying between %s -> %s -> %s", start.Format(time.RFC3339), boundary.Format(time.RFC3339), end.Format(time.RFC3339))
 
+	filterOpts, err := filter.FilterOptionsFromRequest(req, "name", apitype.SortAscending)
+	if err == nil {
+		err = filterOpts.Validate(apitype.Variant{})
+	}
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
 	variantsResult, err := query.VariantReports(dbc, release, start, boundary, end)
 	if err != nil {
 		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building variant report:" + err.Error()})
 		return
 	}
 
-	RespondWithJSON(http.StatusOK, w, variantsResult)
+	gosort.SliceStable(variantsResult, func(i, j int) bool {
+		if filterOpts.Sort == apitype.SortAscending {
+			return filter.Compare(variantsResult[i], variantsResult[j], filterOpts.SortField)
+		}
+		return filter.Compare(variantsResult[j], variantsResult[i], filterOpts.SortField)
+	})
+	if filterOpts.Limit > 0 && len(variantsResult) > filterOpts.Limit {
+		variantsResult = variantsResult[:filterOpts.Limit]
+	}
+
+	projected, err := filter.ProjectColumns(variantsResult, filterOpts.Columns)
+	if err != nil {
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building variant report:" + err.Error()})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, projected)
 }
 
 // PrintJobsReportFromDB renders a filtered summary of matching jobs.
 func PrintJobsReportFromDB(w http.ResponseWriter, req *http.Request,
 	dbc *db.DB, release string, reportEnd time.Time) {
+	jobsResult, filterOpts, ok := jobsReportFromRequest(w, req, dbc, release, reportEnd)
+	if !ok {
+		return
+	}
+
+	projected, err := filter.ProjectColumns(jobsResult, filterOpts.Columns)
+	if err != nil {
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, projected)
+}
+
+// PrintJobsReportV2FromDB renders the same report as PrintJobsReportFromDB, but with each job's variants keyed by
+// variant name.
+func PrintJobsReportV2FromDB(w http.ResponseWriter, req *http.Request,
+	dbc *db.DB, release string, reportEnd time.Time) {
+	jobsResult, filterOpts, ok := jobsReportFromRequest(w, req, dbc, release, reportEnd)
+	if !ok {
+		return
+	}
+
+	jobs := make([]apitype.JobV2, 0, len(jobsResult))
+	for _, job := range jobsResult {
+		jobs = append(jobs, apitype.JobV2{
+			Job:      job,
+			Variants: TypedVariants(job.Variants),
+		})
+	}
+
+	projected, err := filter.ProjectColumns(jobs, filterOpts.Columns)
+	if err != nil {
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, projected)
+}
+
+// TypedVariants converts a list of "Name:value" variants to a map of variant name to value, as used by the
+// variant registry. Legacy variants without a name are keyed by the variant itself, with an empty value.
+func TypedVariants(variants []string) map[string]string {
+	typed := make(map[string]string, len(variants))
+	for _, v := range variants {
+		name, value, _ := strings.Cut(v, ":")
+		typed[name] = value
+	}
+	return typed
+}
+
+// jobsReportFromRequest parses the job report parameters and queries the report, responding with an error and
+// returning false if it fails.
+func jobsReportFromRequest(w http.ResponseWriter, req *http.Request,
+	dbc *db.DB, release string, reportEnd time.Time) ([]apitype.Job, *filter.FilterOptions, bool) {
 
 	var fil *filter.Filter
 
@@ -132,61 +180,137 @@ func PrintJobsReportFromDB(w http.ResponseWriter, req *http.Request,
 		fil = &filter.Filter{}
 		if err := json.Unmarshal([]byte(queryFilter), fil); err != nil {
 			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
-			return
+			return nil, nil, false
 		}
 	}
 
 	// Preferred method of slicing is with start->boundary->end query params in the format ?start=2021-12-02&boundary=2021-12-07.
 	// 'end' can be specified if you wish to view historical reports rather than now, which is assumed if end param is absent.
-	var start time.Time
-	var boundary time.Time
-	var end time.Time
-	var err error
-
-	startParam := req.URL.Query().Get("start")
-	if startParam != "" {
-		start, err = time.Parse("2006-01-02", startParam)
-		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding start param: %s", err.Error())})
-			return
-		}
+	start, boundary, end, err := ParsePeriodWindow(req, req.URL.Query().Get("period"), reportEnd)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return nil, nil, false
 	}
+	SetTimeWindowHeaders(w, start, boundary, end)
 
-	// TODO: currently we're assuming dates use the 00:00:00, is it more logical to add 23:23 for boundary and end? or
-	// for callers to know to specify one day beyond.
-	boundaryParam := req.URL.Query().Get("boundary")
-	if boundaryParam != "" {
-		boundary, err = time.Parse("2006-01-02", boundaryParam)
-		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding boundary param: %s", err.Error())})
-			return
-		}
+	log.Debugf("Querying between %s -> %s -> %s", start.Format(time.RFC3339), boundary.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	filterOpts, err := filter.FilterOptionsFromRequest(req, currentPassPercentage, apitype.SortDescending)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Error building job report:" + err.Error()})
+		return nil, nil, false
+	}
+	if err := filterOpts.Validate(apitype.Job{}); err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return nil, nil, false
 	}
 
-	endParam := req.URL.Query().Get("end")
-	if endParam != "" {
-		end, err = time.Parse("2006-01-02", endParam)
+	weighting, halfLife, err := parsePassRateWeighting(req)
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return nil, nil, false
+	}
+	// Weighting changes the pass percentages after the query, so it has to sort and limit the results itself
+	limit := filterOpts.Limit
+	if weighting == apitype.PassRateExponential {
+		filterOpts.Limit = 0
+	}
+
+	jobsResult, err := JobReportsFromDB(dbc, release, req.URL.Query().Get("period"), filterOpts, start, boundary, end, reportEnd)
+	if err != nil {
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
+		return nil, nil, false
+	}
+
+	if weighting == apitype.PassRateExponential {
+		hourly, err := query.JobHourlyResults(dbc, release, start, boundary, end)
 		if err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": fmt.Sprintf("Error decoding end param: %s", err.Error())})
-			return
+			RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
+			return nil, nil, false
+		}
+		weighJobPassRates(jobsResult, hourly, boundary, end, halfLife)
+
+		filterOpts.Limit = limit
+		gosort.SliceStable(jobsResult, func(i, j int) bool {
+			if filterOpts.Sort == apitype.SortAscending {
+				return filter.Compare(jobsResult[i], jobsResult[j], filterOpts.SortField)
+			}
+			return filter.Compare(jobsResult[j], jobsResult[i], filterOpts.SortField)
+		})
+		if limit > 0 && len(jobsResult) > limit {
+			jobsResult = jobsResult[:limit]
 		}
 	}
 
-	log.Debugf("Querying between %s -> %s -> %s", start.Format(time.RFC3339), boundary.Format(time.RFC3339), end.Format(time.RFC3339))
+	return jobsResult, filterOpts, true
+}
 
-	filterOpts, err := filter.FilterOptionsFromRequest(req, currentPassPercentage, apitype.SortDescending)
+// parsePassRateWeighting parses the weighting and half_life params. The weighting defaults to exponential when
+// ExponentialWeightingFlag is enabled for the request. The half-life defaults to DefaultPassRateHalfLife, and is
+// only allowed with exponential weighting.
+func parsePassRateWeighting(req *http.Request) (apitype.PassRateWeighting, time.Duration, error) {
+	param := req.URL.Query().Get("weighting")
+	if param == "" && featureflags.Enabled(req.Context(), ExponentialWeightingFlag) {
+		param = string(apitype.PassRateExponential)
+	}
+	weighting, err := apitype.ParsePassRateWeighting(param)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
-		return
+		return "", 0, err
 	}
-
-	jobsResult, err := JobReportsFromDB(dbc, release, req.URL.Query().Get("period"), filterOpts, start, boundary, end, reportEnd)
+	halfLifeParam := req.URL.Query().Get("half_life")
+	if halfLifeParam == "" {
+		return weighting, DefaultPassRateHalfLife, nil
+	}
+	if weighting != apitype.PassRateExponential {
+		return "", 0, fmt.Errorf("half_life requires exponential weighting")
+	}
+	halfLife, err := parseWindowLength(halfLifeParam)
 	if err != nil {
-		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
-		return
+		return "", 0, fmt.Errorf("invalid half_life %q: must be a duration such as 36h, 3d or 1w", halfLifeParam)
+	}
+	return weighting, halfLife, nil
+}
+
+// weighJobPassRates replaces the flat pass percentages of jobs with ones where each run's weight halves every
+// halfLife before the end of its period, i.e. boundary for the previous period. Runs of jobs merged from before
+// the release branched count towards their release branch job.
+func weighJobPassRates(jobs []apitype.Job, hourly []query.JobHourlyRuns, boundary, end time.Time, halfLife time.Duration) {
+	type periods struct {
+		current, previous stats.WeightedCounts
+	}
+	byName := map[string]*periods{}
+	for _, h := range hourly {
+		p, ok := byName[h.Name]
+		if !ok {
+			p = &periods{}
+			byName[h.Name] = p
+		}
+		counts := stats.Counts{Successes: h.Passes, Failures: h.Runs - h.Passes}
+		// Runs are aged from the middle of their hour
+		at := h.Hour.Add(30 * time.Minute)
+		if h.Current {
+			p.current.Add(counts, stats.DecayWeight(end.Sub(at), halfLife))
+		} else {
+			p.previous.Add(counts, stats.DecayWeight(boundary.Sub(at), halfLife))
+		}
 	}
 
-	RespondWithJSON(http.StatusOK, w, jobsResult)
+	for i := range jobs {
+		job := &jobs[i]
+		var current, previous stats.WeightedCounts
+		for _, name := range append([]string{job.Name}, job.PreBranchNames...) {
+			if p, ok := byName[name]; ok {
+				current.Successes += p.current.Successes
+				current.Total += p.current.Total
+				previous.Successes += p.previous.Successes
+				previous.Total += p.previous.Total
+			}
+		}
+		job.CurrentPassPercentage = current.PassRate() * 100
+		job.PreviousPassPercentage = previous.PassRate() * 100
+		job.NetImprovement = job.CurrentPassPercentage - job.PreviousPassPercentage
+		job.SetConfidenceIntervals()
+	}
 }
 
 func JobReportsFromDB(dbc *db.DB, release, period string, filterOpts *filter.FilterOptions, start, boundary, end, reportEnd time.Time) ([]apitype.Job, error) {
@@ -221,13 +345,115 @@ func JobReportsFromDB(dbc *db.DB, release, period string, filterOpts *filter.Fil
 		end = reportEnd
 	}
 
-	jobsResult, err := query.JobReports(dbc, filterOpts, release, start, boundary, end)
+	// Pre-branch jobs are merged into their release branch job before filtering, sorting and limiting, which would
+	// otherwise drop them or order jobs by their unmerged results.
+	jobsResult, err := query.JobReports(dbc, &filter.FilterOptions{Filter: &filter.Filter{}}, release, start, boundary, end)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return jobsResult, nil
+	jobsResult = mergeBranchedJobs(release, jobsResult)
+	for i := range jobsResult {
+		jobsResult[i].SetConfidenceIntervals()
+	}
+	return filterJobs(jobsResult, filterOpts)
+}
+
+// filterJobs applies the filter, sort and limit of filterOpts to jobs, as query.JobReports would in the database.
+func filterJobs(jobs []apitype.Job, filterOpts *filter.FilterOptions) ([]apitype.Job, error) {
+	filtered := make([]apitype.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if filterOpts.Filter != nil {
+			include, err := filterOpts.Filter.Filter(job)
+			if err != nil {
+				return nil, err
+			}
+			if !include {
+				continue
+			}
+		}
+		filtered = append(filtered, job)
+	}
+
+	if filterOpts.SortField != "" {
+		gosort.SliceStable(filtered, func(i, j int) bool {
+			if filterOpts.Sort == apitype.SortAscending {
+				return filter.Compare(filtered[i], filtered[j], filterOpts.SortField)
+			}
+			return filter.Compare(filtered[j], filtered[i], filterOpts.SortField)
+		})
+	}
+	if filterOpts.Limit > 0 && len(filtered) > filterOpts.Limit {
+		filtered = filtered[:filterOpts.Limit]
+	}
+	return filtered, nil
+}
+
+// mergeBranchedJobs folds the results of jobs that ran against the development branch before the release branch was
+// cut into their release branch job, so a job's pass rate history doesn't reset at branch cut. Merged jobs keep the
+// position of their release branch job.
+func mergeBranchedJobs(release string, jobs []apitype.Job) []apitype.Job {
+	byName := make(map[string]int, len(jobs))
+	for i, job := range jobs {
+		byName[job.Name] = i
+	}
+
+	merged := map[string]bool{}
+	for i := range jobs {
+		job := &jobs[i]
+		for _, name := range util.PreBranchJobNames(job.Name, release) {
+			pre, ok := byName[name]
+			if !ok {
+				continue
+			}
+			mergeJobResults(job, jobs[pre])
+			job.PreBranchNames = append(job.PreBranchNames, name)
+			merged[name] = true
+		}
+	}
+	if len(merged) == 0 {
+		return jobs
+	}
+
+	results := make([]apitype.Job, 0, len(jobs)-len(merged))
+	for _, job := range jobs {
+		if !merged[job.Name] {
+			results = append(results, job)
+		}
+	}
+	return results
+}
+
+// mergeJobResults adds the runs of pre to job and recalculates its percentages.
+func mergeJobResults(job *apitype.Job, pre apitype.Job) {
+	job.CurrentRuns += pre.CurrentRuns
+	job.CurrentPasses += pre.CurrentPasses
+	job.CurrentFails += pre.CurrentFails
+	job.CurrentInfraFails += pre.CurrentInfraFails
+	job.PreviousRuns += pre.PreviousRuns
+	job.PreviousPasses += pre.PreviousPasses
+	job.PreviousFails += pre.PreviousFails
+	job.PreviousInfraFails += pre.PreviousInfraFails
+	job.OpenBugs += pre.OpenBugs
+	if job.LastPass == nil || (pre.LastPass != nil && pre.LastPass.After(*job.LastPass)) {
+		job.LastPass = pre.LastPass
+	}
+	if job.AverageRetestsToMerge == 0 {
+		job.AverageRetestsToMerge = pre.AverageRetestsToMerge
+	}
+
+	rate := func(n, runs int) float64 {
+		if runs == 0 {
+			return 0
+		}
+		return float64(n) * 100 / float64(runs)
+	}
+	job.CurrentPassPercentage = rate(job.CurrentPasses, job.CurrentRuns)
+	job.CurrentProjectedPassPercentage = rate(job.CurrentPasses+job.CurrentInfraFails, job.CurrentRuns)
+	job.PreviousPassPercentage = rate(job.PreviousPasses, job.PreviousRuns)
+	job.PreviousProjectedPassPercentage = rate(job.PreviousPasses+job.PreviousInfraFails, job.PreviousRuns)
+	job.NetImprovement = job.CurrentPassPercentage - job.PreviousPassPercentage
 }
 
 type jobDetail struct {
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/featureflags"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+func TestTypedVariants(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"Platform":     "aws",
+		"Architecture": "amd64",
+		"never-stable": "",
+	}, TypedVariants([]string{"Platform:aws", "Architecture:amd64", "never-stable"}))
+	assert.Empty(t, TypedVariants(nil))
+}
+
+func TestMergeBranchedJobs(t *testing.T) {
+	jobs := []apitype.Job{
+		{Name: "pull-ci-openshift-origin-release-4.16-e2e-aws", CurrentRuns: 10, CurrentPasses: 9, CurrentFails: 1},
+		{Name: "pull-ci-openshift-origin-master-e2e-gcp", CurrentRuns: 4, CurrentPasses: 4},
+		{Name: "pull-ci-openshift-origin-master-e2e-aws", CurrentRuns: 10, CurrentPasses: 5, CurrentFails: 5,
+			PreviousRuns: 10, PreviousPasses: 8, PreviousFails: 2},
+	}
+
+	merged := mergeBranchedJobs("4.16", jobs)
+	require.Len(t, merged, 2)
+	aws := merged[0]
+	assert.Equal(t, "pull-ci-openshift-origin-release-4.16-e2e-aws", aws.Name)
+	assert.Equal(t, []string{"pull-ci-openshift-origin-master-e2e-aws"}, aws.PreBranchNames)
+	assert.Equal(t, 20, aws.CurrentRuns)
+	assert.InDelta(t, 70.0, aws.CurrentPassPercentage, 0.01)
+	assert.InDelta(t, 80.0, aws.PreviousPassPercentage, 0.01)
+	assert.InDelta(t, -10.0, aws.NetImprovement, 0.01)
+	// Not branched yet
+	assert.Equal(t, "pull-ci-openshift-origin-master-e2e-gcp", merged[1].Name)
+
+	// Other releases' branches are separate jobs
+	assert.Len(t, mergeBranchedJobs("4.17", jobs), 3)
+}
+
+func TestFilterMergedJobs(t *testing.T) {
+	jobs := mergeBranchedJobs("4.16", []apitype.Job{
+		{Name: "pull-ci-openshift-origin-release-4.16-e2e-aws", CurrentRuns: 2, CurrentPasses: 2, CurrentPassPercentage: 100},
+		{Name: "pull-ci-openshift-origin-release-4.16-e2e-gcp", CurrentRuns: 10, CurrentPasses: 8, CurrentFails: 2,
+			CurrentPassPercentage: 80},
+		{Name: "pull-ci-openshift-origin-master-e2e-aws", CurrentRuns: 8, CurrentPasses: 2, CurrentFails: 6},
+	})
+
+	filtered, err := filterJobs(jobs, &filter.FilterOptions{
+		Filter: &filter.Filter{Items: []filter.FilterItem{
+			{Field: "name", Operator: filter.OperatorContains, Value: "release-4.16"},
+		}},
+		SortField: "current_pass_percentage",
+		Sort:      apitype.SortAscending,
+		Limit:     1,
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "pull-ci-openshift-origin-release-4.16-e2e-aws", filtered[0].Name,
+		"jobs are sorted by their results merged with their pre-branch job's")
+	assert.InDelta(t, 40.0, filtered[0].CurrentPassPercentage, 0.01)
+}
+
+func TestWeighJobPassRates(t *testing.T) {
+	end := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	boundary := end.Add(-7 * 24 * time.Hour)
+	jobs := []apitype.Job{
+		{Name: "pull-ci-openshift-origin-release-4.16-e2e-aws", PreBranchNames: []string{"pull-ci-openshift-origin-master-e2e-aws"}},
+		{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp"},
+	}
+	hourly := []query.JobHourlyRuns{
+		// Failing early in the current period, passing at the end of it
+		{Name: "pull-ci-openshift-origin-release-4.16-e2e-aws", Hour: boundary, Current: true, Runs: 10, Passes: 0},
+		{Name: "pull-ci-openshift-origin-master-e2e-aws", Hour: end.Add(-time.Hour), Current: true, Runs: 10, Passes: 10},
+		{Name: "pull-ci-openshift-origin-release-4.16-e2e-aws", Hour: boundary.Add(-time.Hour), Runs: 4, Passes: 2},
+		{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp", Hour: end.Add(-time.Hour), Current: true, Runs: 3, Passes: 3},
+	}
+
+	weighJobPassRates(jobs, hourly, boundary, end, 72*time.Hour)
+
+	aws := jobs[0]
+	// The failures are 7 days old, at a weight of about 0.2, against a flat pass percentage of 50
+	assert.InDelta(t, 83.3, aws.CurrentPassPercentage, 0.1)
+	assert.InDelta(t, 50.0, aws.PreviousPassPercentage, 0.01)
+	assert.InDelta(t, aws.CurrentPassPercentage-50, aws.NetImprovement, 0.01)
+	assert.Equal(t, 100.0, jobs[1].CurrentPassPercentage)
+	assert.Equal(t, 0.0, jobs[1].PreviousPassPercentage)
+}
+
+func TestParsePassRateWeighting(t *testing.T) {
+	parse := func(query string) (apitype.PassRateWeighting, time.Duration, error) {
+		return parsePassRateWeighting(httptest.NewRequest(http.MethodGet, "/api/jobs?"+query, nil))
+	}
+
+	weighting, halfLife, err := parse("")
+	require.NoError(t, err)
+	assert.Equal(t, apitype.PassRateFlat, weighting)
+	assert.Equal(t, DefaultPassRateHalfLife, halfLife)
+
+	weighting, halfLife, err = parse("weighting=exponential&half_life=2d")
+	require.NoError(t, err)
+	assert.Equal(t, apitype.PassRateExponential, weighting)
+	assert.Equal(t, 48*time.Hour, halfLife)
+
+	_, _, err = parse("weighting=linear")
+	assert.Error(t, err)
+	_, _, err = parse("half_life=2d")
+	assert.Error(t, err, "half_life without exponential weighting")
+	_, _, err = parse("weighting=exponential&half_life=soon")
+	assert.Error(t, err)
+
+	// the feature flag changes the default, but not an explicit weighting
+	flagged := func(query string) apitype.PassRateWeighting {
+		req := httptest.NewRequest(http.MethodGet, "/api/jobs?"+query, nil)
+		req = req.WithContext(featureflags.NewContext(req.Context(), featureflags.Set{ExponentialWeightingFlag: true}))
+		weighting, _, err := parsePassRateWeighting(req)
+		require.NoError(t, err)
+		return weighting
+	}
+	assert.Equal(t, apitype.PassRateExponential, flagged(""))
+	assert.Equal(t, apitype.PassRateFlat, flagged("weighting=flat"))
+}
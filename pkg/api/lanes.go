@@ -0,0 +1,170 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// ErrLaneNotFound is returned when a lane does not exist.
+var ErrLaneNotFound = errors.New("lane not found")
+
+// ValidateLane ensures a lane submitted via the API is well-formed.
+func ValidateLane(lane *models.Lane) error {
+	if strings.TrimSpace(lane.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(lane.JobNames) == 0 && lane.JobPattern == "" {
+		return fmt.Errorf("one of job_names or job_pattern is required")
+	}
+	for _, name := range lane.JobNames {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("job_names must not be empty")
+		}
+	}
+	if lane.JobPattern != "" {
+		if _, err := regexp.Compile(lane.JobPattern); err != nil {
+			return fmt.Errorf("invalid job_pattern: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListLanes returns all lanes, sorted by name.
+func ListLanes(dbc *db.DB) ([]models.Lane, error) {
+	lanes := make([]models.Lane, 0)
+	res := dbc.DB.Order("name").Find(&lanes)
+	return lanes, res.Error
+}
+
+// GetLane returns a single lane by ID.
+func GetLane(dbc *db.DB, id uint) (*models.Lane, error) {
+	lane := &models.Lane{}
+	res := dbc.DB.First(lane, id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrLaneNotFound
+	}
+	return lane, res.Error
+}
+
+// GetLaneByName returns a single lane by name.
+func GetLaneByName(dbc *db.DB, name string) (*models.Lane, error) {
+	lane := &models.Lane{}
+	res := dbc.DB.Where("name = ?", name).First(lane)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrLaneNotFound
+	}
+	return lane, res.Error
+}
+
+// CreateLane validates and stores a new lane.
+func CreateLane(dbc *db.DB, lane *models.Lane) error {
+	lane.Model = models.Model{}
+	if err := ValidateLane(lane); err != nil {
+		return err
+	}
+	return dbc.DB.Create(lane).Error
+}
+
+// UpdateLane replaces the fields of an existing lane.
+func UpdateLane(dbc *db.DB, id uint, lane *models.Lane) error {
+	existing, err := GetLane(dbc, id)
+	if err != nil {
+		return err
+	}
+	if err := ValidateLane(lane); err != nil {
+		return err
+	}
+	lane.Model = existing.Model
+	return dbc.DB.Save(lane).Error
+}
+
+// DeleteLane soft deletes a lane.
+func DeleteLane(dbc *db.DB, id uint) error {
+	res := dbc.DB.Delete(&models.Lane{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLaneNotFound
+	}
+	return nil
+}
+
+// GetLaneReports reports on the named lane, or every lane if name is empty, optionally limited to a release.
+func GetLaneReports(dbc *db.DB, name, release string, start, boundary, end time.Time) ([]apitype.LaneReport, error) {
+	var lanes []models.Lane
+	if name != "" {
+		lane, err := GetLaneByName(dbc, name)
+		if err != nil {
+			return nil, err
+		}
+		lanes = []models.Lane{*lane}
+	} else {
+		var err error
+		if lanes, err = ListLanes(dbc); err != nil {
+			return nil, err
+		}
+	}
+
+	reports := make([]apitype.LaneReport, 0, len(lanes))
+	for _, lane := range lanes {
+		jobs, err := query.LaneJobResults(dbc, lane, release, start, boundary, end)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, buildLaneReport(lane, jobs))
+	}
+	return reports, nil
+}
+
+// buildLaneReport totals the results of a lane's jobs.
+func buildLaneReport(lane models.Lane, jobs []query.LaneJobRuns) apitype.LaneReport {
+	report := apitype.LaneReport{
+		Lane:        lane.Name,
+		Description: lane.Description,
+		Jobs:        make([]apitype.LaneJobResults, 0, len(jobs)),
+	}
+	var total query.LaneJobRuns
+	for _, job := range jobs {
+		total.CurrentRuns += job.CurrentRuns
+		total.CurrentPasses += job.CurrentPasses
+		total.PreviousRuns += job.PreviousRuns
+		total.PreviousPasses += job.PreviousPasses
+		report.Jobs = append(report.Jobs, apitype.LaneJobResults{
+			Name:        job.Name,
+			Release:     job.Release,
+			LaneResults: laneResults(job),
+		})
+	}
+	report.LaneResults = laneResults(total)
+	return report
+}
+
+func laneResults(runs query.LaneJobRuns) apitype.LaneResults {
+	results := apitype.LaneResults{
+		CurrentRuns:            runs.CurrentRuns,
+		CurrentPasses:          runs.CurrentPasses,
+		CurrentPassPercentage:  percentage(runs.CurrentPasses, runs.CurrentRuns),
+		PreviousRuns:           runs.PreviousRuns,
+		PreviousPasses:         runs.PreviousPasses,
+		PreviousPassPercentage: percentage(runs.PreviousPasses, runs.PreviousRuns),
+
+		CurrentPassPercentageCI:  apitype.NewConfidenceInterval(runs.CurrentPasses, runs.CurrentRuns),
+		PreviousPassPercentageCI: apitype.NewConfidenceInterval(runs.PreviousPasses, runs.PreviousRuns),
+	}
+	if results.CurrentPassPercentage != nil && results.PreviousPassPercentage != nil {
+		net := *results.CurrentPassPercentage - *results.PreviousPassPercentage
+		results.NetImprovement = &net
+	}
+	return results
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestValidateLane(t *testing.T) {
+	assert.NoError(t, ValidateLane(&models.Lane{Name: "aws-ovn-serial", JobNames: pq.StringArray{"e2e-aws-ovn-serial"}}))
+	assert.NoError(t, ValidateLane(&models.Lane{Name: "aws-ovn-serial", JobPattern: "e2e-aws-ovn-serial$"}))
+	assert.Error(t, ValidateLane(&models.Lane{JobPattern: "e2e-aws-ovn-serial$"}))
+	assert.Error(t, ValidateLane(&models.Lane{Name: "aws-ovn-serial"}))
+	assert.Error(t, ValidateLane(&models.Lane{Name: "aws-ovn-serial", JobNames: pq.StringArray{" "}}))
+	assert.Error(t, ValidateLane(&models.Lane{Name: "aws-ovn-serial", JobPattern: "e2e-(aws"}))
+}
+
+func TestBuildLaneReport(t *testing.T) {
+	report := buildLaneReport(models.Lane{Name: "aws-ovn-serial"}, []query.LaneJobRuns{
+		{Name: "e2e-4.16-aws-ovn-serial", Release: "4.16", CurrentRuns: 10, CurrentPasses: 8, PreviousRuns: 10, PreviousPasses: 9},
+		{Name: "e2e-4.15-aws-ovn-serial", Release: "4.15", CurrentRuns: 10, CurrentPasses: 10},
+	})
+	assert.Equal(t, "aws-ovn-serial", report.Lane)
+	assert.Equal(t, 20, report.CurrentRuns)
+	require.NotNil(t, report.CurrentPassPercentage)
+	assert.InDelta(t, 90.0, *report.CurrentPassPercentage, 0.01)
+	require.NotNil(t, report.NetImprovement)
+	assert.InDelta(t, 0.0, *report.NetImprovement, 0.01)
+
+	require.Len(t, report.Jobs, 2)
+	assert.InDelta(t, -10.0, *report.Jobs[0].NetImprovement, 0.01)
+	// No previous runs to compare with
+	assert.Nil(t, report.Jobs[1].PreviousPassPercentage)
+	assert.Nil(t, report.Jobs[1].NetImprovement)
+}
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// MilestoneSnapshotPeriod is how far back from a milestone, or from now for the current state, pass rates are
+// computed over.
+const MilestoneSnapshotPeriod = 7 * 24 * time.Hour
+
+var (
+	// ErrReleaseMilestoneNotFound is returned when a release milestone does not exist.
+	ErrReleaseMilestoneNotFound = errors.New("release milestone not found")
+
+	// ErrMilestoneSnapshotNotFound is returned when comparing against a milestone that has not been snapshotted yet.
+	ErrMilestoneSnapshotNotFound = errors.New("release milestone has not been snapshotted yet")
+)
+
+var validMilestones = map[string]bool{
+	models.MilestoneFeatureFreeze: true,
+	models.MilestoneCodeFreeze:    true,
+	models.MilestoneGA:            true,
+}
+
+// ValidateReleaseMilestone ensures a milestone submitted via the API is well-formed.
+func ValidateReleaseMilestone(milestone *models.ReleaseMilestone) error {
+	if strings.TrimSpace(milestone.Release) == "" {
+		return fmt.Errorf("release is required")
+	}
+	if !validMilestones[milestone.Name] {
+		return fmt.Errorf("invalid name %q: must be feature-freeze, code-freeze, or ga", milestone.Name)
+	}
+	if milestone.Date.IsZero() {
+		return fmt.Errorf("date is required")
+	}
+	return nil
+}
+
+// ListReleaseMilestones returns the milestones of a release, or of all releases, in date order along with when
+// they were snapshotted.
+func ListReleaseMilestones(dbc *db.DB, release string) ([]models.ReleaseMilestone, error) {
+	milestones := []models.ReleaseMilestone{}
+	q := dbc.DB.Preload("Snapshot").Order("date")
+	if release != "" {
+		q = q.Where("release = ?", release)
+	}
+	res := q.Find(&milestones)
+	return milestones, res.Error
+}
+
+// SaveReleaseMilestone validates and stores a milestone, replacing the date of the release's existing milestone
+// with the same name. Moving a milestone discards its snapshot, so it is taken again as of the new date. It
+// returns whether the milestone was created rather than replaced.
+func SaveReleaseMilestone(dbc *db.DB, milestone *models.ReleaseMilestone) (bool, error) {
+	milestone.Model = models.Model{}
+	milestone.Snapshot = nil
+	if err := ValidateReleaseMilestone(milestone); err != nil {
+		return false, err
+	}
+
+	created := false
+	err := dbc.DB.Transaction(func(tx *gorm.DB) error {
+		existing := &models.ReleaseMilestone{}
+		res := tx.Where("release = ? AND name = ?", milestone.Release, milestone.Name).First(existing)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			created = true
+			return tx.Create(milestone).Error
+		} else if res.Error != nil {
+			return res.Error
+		}
+
+		if !existing.Date.Equal(milestone.Date) {
+			if err := deleteMilestoneSnapshot(tx, existing.ID); err != nil {
+				return err
+			}
+		}
+		existing.Date = milestone.Date
+		*milestone = *existing
+		return tx.Save(milestone).Error
+	})
+	return created, err
+}
+
+// DeleteReleaseMilestone permanently deletes a milestone and its snapshot.
+func DeleteReleaseMilestone(dbc *db.DB, id uint) error {
+	return dbc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := deleteMilestoneSnapshot(tx, id); err != nil {
+			return err
+		}
+		res := tx.Unscoped().Delete(&models.ReleaseMilestone{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrReleaseMilestoneNotFound
+		}
+		return nil
+	})
+}
+
+func deleteMilestoneSnapshot(tx *gorm.DB, milestoneID uint) error {
+	snapshot := &models.MilestoneSnapshot{}
+	res := tx.Where("release_milestone_id = ?", milestoneID).First(snapshot)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil
+	} else if res.Error != nil {
+		return res.Error
+	}
+	if err := tx.Unscoped().Where("milestone_snapshot_id = ?", snapshot.ID).Delete(&models.MilestonePassRate{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(snapshot).Error
+}
+
+// PendingMilestones returns the milestones that have passed but have not been snapshotted yet.
+func PendingMilestones(dbc *db.DB, now time.Time) ([]models.ReleaseMilestone, error) {
+	milestones := []models.ReleaseMilestone{}
+	res := dbc.DB.
+		Where("date <= ?", now).
+		Where("NOT EXISTS (SELECT 1 FROM milestone_snapshots WHERE milestone_snapshots.release_milestone_id = release_milestones.id)").
+		Order("date").
+		Find(&milestones)
+	return milestones, res.Error
+}
+
+// SnapshotMilestone stores the component and variant pass rates of the milestone's release over the period
+// ending at the milestone.
+func SnapshotMilestone(dbc *db.DB, milestone *models.ReleaseMilestone) error {
+	end := milestone.Date
+	start := end.Add(-MilestoneSnapshotPeriod)
+	passRates, err := milestonePassRates(dbc, milestone.Release, start, end)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &models.MilestoneSnapshot{
+		ReleaseMilestoneID: milestone.ID,
+		Start:              start,
+		End:                end,
+		PassRates:          passRates,
+	}
+	return dbc.DB.Create(snapshot).Error
+}
+
+func milestonePassRates(dbc *db.DB, release string, start, end time.Time) ([]models.MilestonePassRate, error) {
+	components, err := query.ComponentPassRates(dbc, release, start, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying component pass rates")
+	}
+	variants, err := query.VariantPassRates(dbc, release, start, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying variant pass rates")
+	}
+
+	passRates := make([]models.MilestonePassRate, 0, len(components)+len(variants))
+	for kind, counts := range map[string][]query.PassRateCounts{
+		models.MilestonePassRateComponent: components,
+		models.MilestonePassRateVariant:   variants,
+	} {
+		for _, c := range counts {
+			passRates = append(passRates, models.MilestonePassRate{
+				Kind:      kind,
+				Name:      c.Name,
+				Runs:      c.Runs,
+				Successes: c.Successes,
+				Flakes:    c.Flakes,
+			})
+		}
+	}
+	return passRates, nil
+}
+
+// CompareToMilestone compares the release's pass rates over the period ending at end with those snapshotted at
+// the named milestone, optionally limited to component or variant pass rates.
+func CompareToMilestone(dbc *db.DB, release, name, kind string, end time.Time) (*apitype.MilestoneComparison, error) {
+	milestone := &models.ReleaseMilestone{}
+	res := dbc.DB.Preload("Snapshot.PassRates").Where("release = ? AND name = ?", release, name).First(milestone)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrReleaseMilestoneNotFound
+	} else if res.Error != nil {
+		return nil, res.Error
+	}
+	if milestone.Snapshot == nil {
+		return nil, ErrMilestoneSnapshotNotFound
+	}
+
+	start := end.Add(-MilestoneSnapshotPeriod)
+	current, err := milestonePassRates(dbc, release, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apitype.MilestoneComparison{
+		Release:       release,
+		Milestone:     name,
+		MilestoneDate: milestone.Date,
+		SnapshotStart: milestone.Snapshot.Start,
+		SnapshotEnd:   milestone.Snapshot.End,
+		CurrentStart:  start,
+		CurrentEnd:    end,
+		PassRates:     compareMilestonePassRates(milestone.Snapshot.PassRates, current, kind),
+	}, nil
+}
+
+// compareMilestonePassRates pairs up the milestone and current pass rates, sorted by net improvement so the most
+// regressed come first. Those only present on one side sort last.
+func compareMilestonePassRates(milestone, current []models.MilestonePassRate, kind string) []apitype.MilestonePassRateComparison {
+	type key struct{ kind, name string }
+	comparisons := map[key]*apitype.MilestonePassRateComparison{}
+	get := func(p models.MilestonePassRate) *apitype.MilestonePassRateComparison {
+		k := key{p.Kind, p.Name}
+		if _, ok := comparisons[k]; !ok {
+			comparisons[k] = &apitype.MilestonePassRateComparison{Kind: p.Kind, Name: p.Name}
+		}
+		return comparisons[k]
+	}
+	for _, p := range milestone {
+		if kind != "" && p.Kind != kind {
+			continue
+		}
+		c := get(p)
+		c.MilestoneRuns = p.Runs
+		c.MilestonePassPercentage = passPercentage(p)
+		c.MilestonePassPercentageCI = apitype.PassPercentageInterval(&c.MilestonePassPercentage, p.Runs)
+	}
+	for _, p := range current {
+		if kind != "" && p.Kind != kind {
+			continue
+		}
+		c := get(p)
+		c.CurrentRuns = p.Runs
+		c.CurrentPassPercentage = passPercentage(p)
+		c.CurrentPassPercentageCI = apitype.PassPercentageInterval(&c.CurrentPassPercentage, p.Runs)
+	}
+
+	results := make([]apitype.MilestonePassRateComparison, 0, len(comparisons))
+	for _, c := range comparisons {
+		if c.MilestoneRuns > 0 && c.CurrentRuns > 0 {
+			c.NetImprovement = c.CurrentPassPercentage - c.MilestonePassPercentage
+		}
+		results = append(results, *c)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		iBoth := results[i].MilestoneRuns > 0 && results[i].CurrentRuns > 0
+		jBoth := results[j].MilestoneRuns > 0 && results[j].CurrentRuns > 0
+		if iBoth != jBoth {
+			return iBoth
+		}
+		if results[i].NetImprovement != results[j].NetImprovement {
+			return results[i].NetImprovement < results[j].NetImprovement
+		}
+		if results[i].Kind != results[j].Kind {
+			return results[i].Kind < results[j].Kind
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+func passPercentage(p models.MilestonePassRate) float64 {
+	if p.Runs == 0 {
+		return 0
+	}
+	return float64(p.Successes+p.Flakes) / float64(p.Runs) * 100
+}
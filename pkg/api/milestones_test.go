@@ -0,0 +1,63 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestValidateReleaseMilestone(t *testing.T) {
+	valid := models.ReleaseMilestone{Release: "4.16", Name: models.MilestoneCodeFreeze, Date: time.Date(2024, 5, 24, 0, 0, 0, 0, time.UTC)}
+	assert.NoError(t, ValidateReleaseMilestone(&valid))
+
+	noRelease := valid
+	noRelease.Release = " "
+	assert.Error(t, ValidateReleaseMilestone(&noRelease))
+
+	badName := valid
+	badName.Name = "branching"
+	assert.Error(t, ValidateReleaseMilestone(&badName))
+
+	noDate := valid
+	noDate.Date = time.Time{}
+	assert.Error(t, ValidateReleaseMilestone(&noDate))
+}
+
+func TestCompareMilestonePassRates(t *testing.T) {
+	milestone := []models.MilestonePassRate{
+		{Kind: models.MilestonePassRateComponent, Name: "Networking", Runs: 100, Successes: 95, Flakes: 4},
+		{Kind: models.MilestonePassRateComponent, Name: "Storage", Runs: 100, Successes: 90},
+		{Kind: models.MilestonePassRateVariant, Name: "Platform:aws", Runs: 50, Successes: 40},
+		{Kind: models.MilestonePassRateComponent, Name: "Removed", Runs: 10, Successes: 10},
+	}
+	current := []models.MilestonePassRate{
+		{Kind: models.MilestonePassRateComponent, Name: "Networking", Runs: 100, Successes: 89},
+		{Kind: models.MilestonePassRateComponent, Name: "Storage", Runs: 100, Successes: 95},
+		{Kind: models.MilestonePassRateVariant, Name: "Platform:aws", Runs: 50, Successes: 45},
+		{Kind: models.MilestonePassRateVariant, Name: "Platform:metal", Runs: 20, Successes: 10},
+	}
+
+	result := compareMilestonePassRates(milestone, current, "")
+	require.Len(t, result, 5)
+	assert.Equal(t, "Networking", result[0].Name)
+	assert.InDelta(t, 99.0, result[0].MilestonePassPercentage, 0.001)
+	assert.InDelta(t, -10.0, result[0].NetImprovement, 0.001)
+	assert.Equal(t, "Storage", result[1].Name)
+	assert.Equal(t, models.MilestonePassRateVariant, result[2].Kind)
+	assert.InDelta(t, 10.0, result[2].NetImprovement, 0.001)
+	// Only present on one side
+	assert.Equal(t, "Removed", result[3].Name)
+	assert.Zero(t, result[3].CurrentRuns)
+	assert.Zero(t, result[3].NetImprovement)
+	assert.Equal(t, "Platform:metal", result[4].Name)
+	assert.Zero(t, result[4].MilestoneRuns)
+
+	variants := compareMilestonePassRates(milestone, current, models.MilestonePassRateVariant)
+	require.Len(t, variants, 2)
+	assert.Equal(t, "Platform:aws", variants[0].Name)
+	assert.Equal(t, "Platform:metal", variants[1].Name)
+}
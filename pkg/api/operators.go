@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+var operatorNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ValidateOperatorName ensures an operator name is a valid cluster operator name.
+func ValidateOperatorName(operator string) error {
+	if !operatorNameRegex.MatchString(operator) {
+		return fmt.Errorf("invalid operator name %q", operator)
+	}
+	return nil
+}
+
+// GetOperatorHealth combines the install and upgrade test pass rates of a cluster operator over the current
+// report period, how often it went Degraded between start and end, and its open regressions, by variant.
+func GetOperatorHealth(dbc *db.DB, release, operator string, start, end time.Time) (*apitype.OperatorHealth, error) {
+	if err := ValidateOperatorName(operator); err != nil {
+		return nil, err
+	}
+
+	// Minor upgrades install a previous version and should not be counted against the current version's install stat.
+	excludedInstallVariants := append([]string{}, testidentification.DefaultExcludedVariants...)
+	excludedInstallVariants = append(excludedInstallVariants, "upgrade-minor")
+	installTests, err := query.TestReportsByVariant(dbc, release, v1.CurrentReport,
+		[]string{testidentification.OperatorInstallPrefix + operator}, excludedInstallVariants)
+	if err != nil {
+		return nil, err
+	}
+
+	upgradeTests, err := query.TestReportsByVariant(dbc, release, v1.CurrentReport,
+		[]string{testidentification.OperatorUpgradePrefix + operator, testidentification.SippyOperatorUpgradePrefix + operator},
+		testidentification.DefaultExcludedVariants)
+	if err != nil {
+		return nil, err
+	}
+
+	conditions, err := query.OperatorConditionSummaries(dbc, release, "", start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Regressions open now, i.e. not closed before the current time.
+	regressions, err := query.TestRegressions(dbc, release, "", time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return buildOperatorHealth(release, operator, installTests, upgradeTests, conditions, regressions), nil
+}
+
+func buildOperatorHealth(release, operator string, installTests, upgradeTests []apitype.Test,
+	conditions []apitype.OperatorConditionSummary, regressions []models.TestRegression) *apitype.OperatorHealth {
+
+	variants := map[string]*apitype.OperatorVariantHealth{}
+	variant := func(name string) *apitype.OperatorVariantHealth {
+		if _, ok := variants[name]; !ok {
+			variants[name] = &apitype.OperatorVariantHealth{Variant: name}
+		}
+		return variants[name]
+	}
+
+	// The test queries match by substring, so only use the operator's own tests, i.e. not "etcd-operator" for "etcd".
+	installSuccesses := map[string]int{}
+	for _, t := range installTests {
+		if t.Name != testidentification.OperatorInstallPrefix+operator {
+			continue
+		}
+		v := variant(t.Variant)
+		v.InstallRuns += t.CurrentRuns
+		installSuccesses[t.Variant] += t.CurrentSuccesses
+	}
+	upgradeSuccesses := map[string]int{}
+	for _, t := range upgradeTests {
+		if t.Name != testidentification.OperatorUpgradePrefix+operator && t.Name != testidentification.SippyOperatorUpgradePrefix+operator {
+			continue
+		}
+		v := variant(t.Variant)
+		v.UpgradeRuns += t.CurrentRuns
+		upgradeSuccesses[t.Variant] += t.CurrentSuccesses
+	}
+	for _, c := range conditions {
+		if c.Operator != operator || c.Condition != "Degraded" {
+			continue
+		}
+		v := variant(c.Variant)
+		v.DegradedRuns = c.Runs
+		v.TotalRuns = c.TotalRuns
+		v.DegradedPercentage = c.Percentage
+	}
+
+	// Regressions are related if their test names the operator, or their component is the operator's, which is how
+	// component readiness groups most operator tests.
+	operatorWord := regexp.MustCompile(`(^|[^a-z0-9-])` + regexp.QuoteMeta(operator) + `($|[^a-z0-9-])`)
+	related := []models.TestRegression{}
+	for _, r := range regressions {
+		if !operatorWord.MatchString(strings.ToLower(r.TestName)) && !operatorWord.MatchString(strings.ToLower(r.Component)) {
+			continue
+		}
+		related = append(related, r)
+		for _, rv := range r.Variants {
+			variant(rv).OpenRegressions++
+		}
+	}
+
+	health := &apitype.OperatorHealth{
+		Operator:    operator,
+		Release:     release,
+		Variants:    make([]apitype.OperatorVariantHealth, 0, len(variants)),
+		Regressions: related,
+	}
+	for name, v := range variants {
+		if v.InstallRuns > 0 {
+			p := float64(installSuccesses[name]) * 100.0 / float64(v.InstallRuns)
+			v.InstallPassPercentage = &p
+		}
+		v.InstallPassPercentageCI = apitype.NewConfidenceInterval(installSuccesses[name], v.InstallRuns)
+		if v.UpgradeRuns > 0 {
+			p := float64(upgradeSuccesses[name]) * 100.0 / float64(v.UpgradeRuns)
+			v.UpgradePassPercentage = &p
+		}
+		v.UpgradePassPercentageCI = apitype.NewConfidenceInterval(upgradeSuccesses[name], v.UpgradeRuns)
+		health.Variants = append(health.Variants, *v)
+	}
+	sort.Slice(health.Variants, func(i, j int) bool {
+		return health.Variants[i].Variant < health.Variants[j].Variant
+	})
+	return health
+}
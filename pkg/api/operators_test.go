@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestBuildOperatorHealth(t *testing.T) {
+	installTests := []apitype.Test{
+		{Name: "operator install etcd", Variant: "Platform:aws", CurrentRuns: 10, CurrentSuccesses: 9},
+		{Name: "operator install etcd", Variant: "Platform:metal", CurrentRuns: 4, CurrentSuccesses: 4},
+		// substring matches for other operators are ignored
+		{Name: "operator install etcd-operator", Variant: "Platform:aws", CurrentRuns: 10, CurrentSuccesses: 0},
+	}
+	upgradeTests := []apitype.Test{
+		{Name: "Operator upgrade etcd", Variant: "Platform:aws", CurrentRuns: 3, CurrentSuccesses: 3},
+		{Name: "[sig-sippy] operator upgrade etcd", Variant: "Platform:aws", CurrentRuns: 1, CurrentSuccesses: 0},
+	}
+	conditions := []apitype.OperatorConditionSummary{
+		{Operator: "etcd", Condition: "Degraded", Variant: "Platform:aws", Runs: 2, TotalRuns: 20, Percentage: 10},
+		{Operator: "etcd", Condition: "Unavailable", Variant: "Platform:aws", Runs: 5, TotalRuns: 20, Percentage: 25},
+		{Operator: "dns", Condition: "Degraded", Variant: "Platform:gcp", Runs: 1, TotalRuns: 20, Percentage: 5},
+	}
+	regressions := []models.TestRegression{
+		{TestName: "[sig-etcd] etcd leader changes are not excessive", Component: "Etcd",
+			Variants: []string{"Platform:aws"}, Opened: time.Now()},
+		{TestName: "some test", Component: "Etcd", Variants: []string{"Platform:vsphere"}, Opened: time.Now()},
+		{TestName: "[sig-network] etcdish test", Component: "Networking", Variants: []string{"Platform:aws"}, Opened: time.Now()},
+	}
+
+	health := buildOperatorHealth("4.16", "etcd", installTests, upgradeTests, conditions, regressions)
+	assert.Equal(t, "etcd", health.Operator)
+	require.Len(t, health.Variants, 3)
+	assert.Len(t, health.Regressions, 2)
+
+	aws := health.Variants[0]
+	assert.Equal(t, "Platform:aws", aws.Variant)
+	assert.Equal(t, 10, aws.InstallRuns)
+	require.NotNil(t, aws.InstallPassPercentage)
+	assert.InDelta(t, 90, *aws.InstallPassPercentage, 0.001)
+	assert.Equal(t, 4, aws.UpgradeRuns)
+	require.NotNil(t, aws.UpgradePassPercentage)
+	assert.InDelta(t, 75, *aws.UpgradePassPercentage, 0.001)
+	assert.Equal(t, 2, aws.DegradedRuns)
+	assert.Equal(t, 20, aws.TotalRuns)
+	assert.Equal(t, 1, aws.OpenRegressions)
+
+	metal := health.Variants[1]
+	assert.Equal(t, "Platform:metal", metal.Variant)
+	assert.Nil(t, metal.UpgradePassPercentage)
+
+	vsphere := health.Variants[2]
+	assert.Equal(t, "Platform:vsphere", vsphere.Variant)
+	assert.Equal(t, 1, vsphere.OpenRegressions)
+	assert.Nil(t, vsphere.InstallPassPercentage)
+}
+
+func TestValidateOperatorName(t *testing.T) {
+	assert.NoError(t, ValidateOperatorName("kube-apiserver"))
+	assert.Error(t, ValidateOperatorName(""))
+	assert.Error(t, ValidateOperatorName("etcd'; --"))
+	assert.Error(t, ValidateOperatorName("Etcd"))
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+// DefaultOwnerJobSLO is the job pass percentage jobs are expected to meet.
+const DefaultOwnerJobSLO = 80.0
+
+const ownerVariant = "Owner"
+
+// GetOwnerReport rolls up job health, test flakes, open regressions and SLO compliance for each Owner variant
+// value in a release. Test results are only counted after the boundary.
+func GetOwnerReport(dbc *db.DB, views []crtype.View, release string, start, boundary, end time.Time, slo float64) (*apitype.OwnerReport, error) {
+	jobs, err := query.OwnerJobs(dbc, release, start, boundary, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying owner jobs")
+	}
+	flakes, err := query.OwnerTestFlakes(dbc, release, boundary, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying owner test flakes")
+	}
+	regressions, err := query.TestRegressions(dbc, release, "", end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying regressions")
+	}
+
+	return &apitype.OwnerReport{
+		Release:  release,
+		Start:    start,
+		Boundary: boundary,
+		End:      end,
+		SLO:      slo,
+		Owners:   buildOwnerReport(release, jobs, flakes, openRegressions(regressions, end), viewOwners(views), slo),
+	}, nil
+}
+
+func openRegressions(regressions []models.TestRegression, at time.Time) []models.TestRegression {
+	open := make([]models.TestRegression, 0, len(regressions))
+	for _, r := range regressions {
+		if !r.Opened.After(at) && regressionOpenAt(r, at) {
+			open = append(open, r)
+		}
+	}
+	return open
+}
+
+// viewOwners maps each component readiness view to the owners whose jobs it includes. Views that are not limited
+// to any owner are omitted, as their regressions can't be attributed.
+func viewOwners(views []crtype.View) map[string][]string {
+	owners := map[string][]string{}
+	for _, v := range views {
+		if o := v.VariantOptions.IncludeVariants[ownerVariant]; len(o) > 0 {
+			owners[v.Name] = o
+		}
+	}
+	return owners
+}
+
+func buildOwnerReport(release string, jobs []query.OwnerJobRuns, flakes []query.OwnerTestResults,
+	regressions []models.TestRegression, viewOwners map[string][]string, slo float64) []apitype.OwnerHealth {
+	owners := map[string]*apitype.OwnerHealth{}
+	get := func(owner string) *apitype.OwnerHealth {
+		if _, ok := owners[owner]; !ok {
+			owners[owner] = &apitype.OwnerHealth{
+				Owner:           owner,
+				FailingSLOJobs:  []string{},
+				RegressionViews: []string{},
+				Links:           ownerLinks(release, owner),
+			}
+		}
+		return owners[owner]
+	}
+
+	currentPasses := map[string]int{}
+	previousPasses := map[string]int{}
+	jobsWithRuns := map[string]int{}
+	for _, j := range jobs {
+		o := get(j.Owner)
+		o.Jobs++
+		o.CurrentRuns += j.CurrentRuns
+		o.PreviousRuns += j.PreviousRuns
+		currentPasses[j.Owner] += j.CurrentPasses
+		previousPasses[j.Owner] += j.PreviousPasses
+		if j.CurrentRuns == 0 {
+			continue
+		}
+		jobsWithRuns[j.Owner]++
+		if float64(j.CurrentPasses)*100/float64(j.CurrentRuns) >= slo {
+			o.JobsMeetingSLO++
+		} else {
+			o.FailingSLOJobs = append(o.FailingSLOJobs, j.JobName)
+		}
+	}
+
+	for _, f := range flakes {
+		o := get(f.Owner)
+		o.TestRuns = f.Runs
+		o.FlakePercentage = percentage(f.Flakes, f.Runs)
+	}
+
+	views := map[string]map[string]bool{}
+	for _, r := range regressions {
+		for _, owner := range viewOwners[r.View] {
+			get(owner).OpenRegressions++
+			if views[owner] == nil {
+				views[owner] = map[string]bool{}
+			}
+			views[owner][r.View] = true
+		}
+	}
+
+	results := make([]apitype.OwnerHealth, 0, len(owners))
+	for name, o := range owners {
+		o.CurrentPassPercentage = percentage(currentPasses[name], o.CurrentRuns)
+		o.PreviousPassPercentage = percentage(previousPasses[name], o.PreviousRuns)
+		o.CurrentPassPercentageCI = apitype.NewConfidenceInterval(currentPasses[name], o.CurrentRuns)
+		o.PreviousPassPercentageCI = apitype.NewConfidenceInterval(previousPasses[name], o.PreviousRuns)
+		if o.CurrentPassPercentage != nil && o.PreviousPassPercentage != nil {
+			net := *o.CurrentPassPercentage - *o.PreviousPassPercentage
+			o.NetImprovement = &net
+		}
+		o.SLOCompliance = percentage(o.JobsMeetingSLO, jobsWithRuns[name])
+		for view := range views[name] {
+			o.RegressionViews = append(o.RegressionViews, view)
+		}
+		sort.Strings(o.RegressionViews)
+		results = append(results, *o)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Owner < results[j].Owner
+	})
+	return results
+}
+
+func percentage(n, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	p := float64(n) * 100 / float64(total)
+	return &p
+}
+
+// ownerLinks returns API links drilling down into an owner's jobs and its job pass rate over time.
+func ownerLinks(release, owner string) map[string]string {
+	variant := ownerVariant + ":" + owner
+	jobsFilter, _ := json.Marshal(filter.Filter{Items: []filter.FilterItem{{
+		Field:    "variants",
+		Operator: filter.OperatorContains,
+		Value:    variant,
+	}}})
+	return map[string]string{
+		"jobs": fmt.Sprintf("/api/jobs?release=%s&filter=%s", url.QueryEscape(release), url.QueryEscape(string(jobsFilter))),
+		"timeseries": fmt.Sprintf("/api/timeseries?release=%s&variant=%s&granularity=day",
+			url.QueryEscape(release), url.QueryEscape(variant)),
+	}
+}
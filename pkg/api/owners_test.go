@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestViewOwners(t *testing.T) {
+	views := []crtype.View{
+		{Name: "4.16-main", VariantOptions: crtype.RequestVariantOptions{
+			IncludeVariants: map[string][]string{"Owner": {"eng"}, "Platform": {"aws"}},
+		}},
+		{Name: "4.16-all", VariantOptions: crtype.RequestVariantOptions{
+			IncludeVariants: map[string][]string{"Platform": {"aws"}},
+		}},
+	}
+	assert.Equal(t, map[string][]string{"4.16-main": {"eng"}}, viewOwners(views))
+}
+
+func TestBuildOwnerReport(t *testing.T) {
+	jobs := []query.OwnerJobRuns{
+		{Owner: "eng", JobName: "job-a", PreviousRuns: 10, PreviousPasses: 9, CurrentRuns: 10, CurrentPasses: 9},
+		{Owner: "eng", JobName: "job-b", PreviousRuns: 10, PreviousPasses: 9, CurrentRuns: 10, CurrentPasses: 5},
+		// No current runs, not counted towards the SLO
+		{Owner: "eng", JobName: "job-c", PreviousRuns: 10, PreviousPasses: 10},
+		{Owner: "cnf", JobName: "job-d", CurrentRuns: 4, CurrentPasses: 4},
+	}
+	flakes := []query.OwnerTestResults{
+		{Owner: "eng", Runs: 1000, Flakes: 20},
+	}
+	opened := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	regressions := []models.TestRegression{
+		{View: "4.16-main", Opened: opened},
+		{View: "4.16-main", Opened: opened},
+		{View: "4.16-all", Opened: opened},
+	}
+
+	result := buildOwnerReport("4.16", jobs, flakes, regressions, map[string][]string{"4.16-main": {"eng"}}, 80)
+	require.Len(t, result, 2)
+
+	assert.Equal(t, "cnf", result[0].Owner)
+	assert.Nil(t, result[0].PreviousPassPercentage)
+	assert.Nil(t, result[0].NetImprovement)
+	require.NotNil(t, result[0].SLOCompliance)
+	assert.InDelta(t, 100.0, *result[0].SLOCompliance, 0.001)
+	assert.Zero(t, result[0].OpenRegressions)
+	assert.Nil(t, result[0].FlakePercentage)
+
+	eng := result[1]
+	assert.Equal(t, "eng", eng.Owner)
+	assert.Equal(t, 3, eng.Jobs)
+	assert.Equal(t, 20, eng.CurrentRuns)
+	require.NotNil(t, eng.CurrentPassPercentage)
+	assert.InDelta(t, 70.0, *eng.CurrentPassPercentage, 0.001)
+	require.NotNil(t, eng.NetImprovement)
+	assert.InDelta(t, -23.333, *eng.NetImprovement, 0.001)
+	assert.Equal(t, 1, eng.JobsMeetingSLO)
+	assert.Equal(t, []string{"job-b"}, eng.FailingSLOJobs)
+	require.NotNil(t, eng.SLOCompliance)
+	assert.InDelta(t, 50.0, *eng.SLOCompliance, 0.001)
+	require.NotNil(t, eng.FlakePercentage)
+	assert.InDelta(t, 2.0, *eng.FlakePercentage, 0.001)
+	assert.Equal(t, 2, eng.OpenRegressions)
+	assert.Equal(t, []string{"4.16-main"}, eng.RegressionViews)
+
+	jobsLink, err := url.Parse(eng.Links["jobs"])
+	require.NoError(t, err)
+	assert.Equal(t, "/api/jobs", jobsLink.Path)
+	assert.Contains(t, jobsLink.Query().Get("filter"), `"value":"Owner:eng"`)
+}
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	RegressionStatusOpen   = "open"
+	RegressionStatusClosed = "closed"
+
+	RegressionGroupByComponent = "component"
+	RegressionGroupByLead      = "lead"
+)
+
+// ValidateRegressionStatus checks the status filter for listing regressions, an empty status lists all.
+func ValidateRegressionStatus(status string) error {
+	switch status {
+	case "", RegressionStatusOpen, RegressionStatusClosed:
+		return nil
+	default:
+		return fmt.Errorf("invalid status %q: must be open or closed", status)
+	}
+}
+
+// ListRegressions returns the regressions for a release that were open at any point between start and end,
+// optionally limited to a component, and to those open or closed as of end.
+func ListRegressions(dbc *db.DB, release, component, status string, start, end time.Time) ([]apitype.Regression, error) {
+	regressions, err := query.TestRegressions(dbc, release, component, start)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]apitype.Regression, 0, len(regressions))
+	for _, r := range regressions {
+		if r.Opened.After(end) {
+			continue
+		}
+		open := regressionOpenAt(r, end)
+		if (status == RegressionStatusOpen && !open) || (status == RegressionStatusClosed && open) {
+			continue
+		}
+		results = append(results, apitype.Regression{TestRegression: r, Hours: regressionHours(r, end)})
+	}
+	return results, nil
+}
+
+// GetRegressionStatsByComponent returns open regression counts and time to resolution for each component with
+// regressions open between start and end.
+func GetRegressionStatsByComponent(dbc *db.DB, release string, start, end time.Time) ([]apitype.ComponentRegressionStats, error) {
+	regressions, err := query.TestRegressions(dbc, release, "", start)
+	if err != nil {
+		return nil, err
+	}
+	return regressionStatsByComponent(regressions, start, end), nil
+}
+
+// GetRegressionReport summarizes the regressions opened and resolved in the week before end, and lists the limit
+// oldest regressions still open.
+func GetRegressionReport(dbc *db.DB, release string, end time.Time, limit int) (apitype.RegressionReport, error) {
+	start := end.Add(-7 * 24 * time.Hour)
+	regressions, err := query.TestRegressions(dbc, release, "", start)
+	if err != nil {
+		return apitype.RegressionReport{}, err
+	}
+	return buildRegressionReport(release, regressions, start, end, limit), nil
+}
+
+func regressionOpenAt(r models.TestRegression, at time.Time) bool {
+	return r.Closed == nil || r.Closed.After(at)
+}
+
+// regressionHours returns how long a regression was open for, or has been open for as of end.
+func regressionHours(r models.TestRegression, end time.Time) float64 {
+	if regressionOpenAt(r, end) {
+		return end.Sub(r.Opened).Hours()
+	}
+	return r.Closed.Sub(r.Opened).Hours()
+}
+
+func regressionStatsByComponent(regressions []models.TestRegression, start, end time.Time) []apitype.ComponentRegressionStats {
+	stats := map[string]*apitype.ComponentRegressionStats{}
+	resolutionHours := map[string][]float64{}
+	for _, r := range regressions {
+		if r.Opened.After(end) {
+			continue
+		}
+		s, ok := stats[r.Component]
+		if !ok {
+			s = &apitype.ComponentRegressionStats{Component: r.Component}
+			stats[r.Component] = s
+		}
+
+		hours := regressionHours(r, end)
+		if regressionOpenAt(r, end) {
+			s.Open++
+			if s.OldestOpenHours == nil || hours > *s.OldestOpenHours {
+				s.OldestOpenHours = &hours
+			}
+		} else if !r.Closed.Before(start) {
+			s.Resolved++
+			resolutionHours[r.Component] = append(resolutionHours[r.Component], hours)
+		}
+	}
+
+	results := make([]apitype.ComponentRegressionStats, 0, len(stats))
+	for component, s := range stats {
+		if hours := resolutionHours[component]; len(hours) > 0 {
+			mean, median := meanAndMedian(hours)
+			s.MeanHoursToResolve = &mean
+			s.MedianHoursToResolve = &median
+		}
+		results = append(results, *s)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Component < results[j].Component
+	})
+	return results
+}
+
+func buildRegressionReport(release string, regressions []models.TestRegression, start, end time.Time, limit int) apitype.RegressionReport {
+	report := apitype.RegressionReport{
+		Release: release,
+		Start:   start,
+		End:     end,
+		Oldest:  []apitype.Regression{},
+	}
+	for _, r := range regressions {
+		if r.Opened.After(end) {
+			continue
+		}
+		if !r.Opened.Before(start) {
+			report.Opened++
+		}
+		if regressionOpenAt(r, end) {
+			report.Oldest = append(report.Oldest, apitype.Regression{TestRegression: r, Hours: regressionHours(r, end)})
+		} else if !r.Closed.Before(start) {
+			report.Resolved++
+		}
+	}
+
+	sort.SliceStable(report.Oldest, func(i, j int) bool {
+		return report.Oldest[i].Opened.Before(report.Oldest[j].Opened)
+	})
+	if limit > 0 && len(report.Oldest) > limit {
+		report.Oldest = report.Oldest[:limit]
+	}
+	return report
+}
+
+// meanAndMedian returns the mean and median of a non-empty list, sorting it in place.
+func meanAndMedian(values []float64) (mean, median float64) {
+	sort.Float64s(values)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean = sum / float64(len(values))
+
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		median = (values[mid-1] + values[mid]) / 2
+	} else {
+		median = values[mid]
+	}
+	return mean, median
+}
+
+// ValidateRegressionBurndownRequest checks the grouping and granularity of a burn-down request.
+func ValidateRegressionBurndownRequest(groupBy string, granularity apitype.TimeSeriesGranularity) error {
+	if groupBy != RegressionGroupByComponent && groupBy != RegressionGroupByLead {
+		return fmt.Errorf("invalid group_by %q: must be component or lead", groupBy)
+	}
+	if granularity != apitype.TimeSeriesDay && granularity != apitype.TimeSeriesWeek {
+		return fmt.Errorf("invalid granularity %q: must be day or week", granularity)
+	}
+	return nil
+}
+
+// GetRegressionBurndown returns open regression counts over time for a release, overall and grouped by component
+// or by component lead, with buckets starting at midnight in loc. If start is zero, the burn-down begins when the
+// release's first regression was opened.
+func GetRegressionBurndown(dbc *db.DB, release, groupBy string, granularity apitype.TimeSeriesGranularity, loc *time.Location, start, end time.Time) (apitype.RegressionBurndown, error) {
+	regressions, err := query.TestRegressions(dbc, release, "", start)
+	if err != nil {
+		return apitype.RegressionBurndown{}, err
+	}
+
+	if start.IsZero() {
+		start = end
+		for _, r := range regressions {
+			if r.Opened.Before(start) {
+				start = r.Opened
+			}
+		}
+	}
+
+	groupName := func(r models.TestRegression) string { return r.Component }
+	if groupBy == RegressionGroupByLead {
+		leads, err := query.ComponentLeads(dbc)
+		if err != nil {
+			return apitype.RegressionBurndown{}, err
+		}
+		groupName = func(r models.TestRegression) string { return leads[r.Component] }
+	}
+
+	return buildRegressionBurndown(release, groupBy, granularity, loc, regressions, groupName, start, end), nil
+}
+
+func buildRegressionBurndown(release, groupBy string, granularity apitype.TimeSeriesGranularity, loc *time.Location,
+	regressions []models.TestRegression, groupName func(models.TestRegression) string, start, end time.Time) apitype.RegressionBurndown {
+	buckets := burndownBuckets(granularity, loc, start, end)
+	burndown := apitype.RegressionBurndown{
+		Release:            release,
+		GroupBy:            groupBy,
+		Granularity:        granularity,
+		Timezone:           loc.String(),
+		Start:              start,
+		End:                end,
+		MeanHoursToResolve: meanHoursToResolve(regressions, start, end),
+		Buckets:            countBurndownBuckets(regressions, granularity, buckets, end),
+		Groups:             []apitype.RegressionBurndownGroup{},
+	}
+
+	groups := map[string][]models.TestRegression{}
+	for _, r := range regressions {
+		if r.Opened.After(end) {
+			continue
+		}
+		name := groupName(r)
+		groups[name] = append(groups[name], r)
+	}
+	for name, groupRegressions := range groups {
+		burndown.Groups = append(burndown.Groups, apitype.RegressionBurndownGroup{
+			Name:               name,
+			MeanHoursToResolve: meanHoursToResolve(groupRegressions, start, end),
+			Buckets:            countBurndownBuckets(groupRegressions, granularity, buckets, end),
+		})
+	}
+	sort.Slice(burndown.Groups, func(i, j int) bool {
+		return burndown.Groups[i].Name < burndown.Groups[j].Name
+	})
+	return burndown
+}
+
+// burndownBuckets returns the start of each bucket between start and end, aligned to midnight in loc, or to Monday
+// for weekly buckets.
+func burndownBuckets(granularity apitype.TimeSeriesGranularity, loc *time.Location, start, end time.Time) []time.Time {
+	start = start.In(loc)
+	bucket := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	if granularity == apitype.TimeSeriesWeek {
+		bucket = bucket.AddDate(0, 0, -(int(bucket.Weekday())+6)%7)
+	}
+
+	var buckets []time.Time
+	for ; bucket.Before(end); bucket = nextBurndownBucket(granularity, bucket) {
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+func nextBurndownBucket(granularity apitype.TimeSeriesGranularity, bucket time.Time) time.Time {
+	if granularity == apitype.TimeSeriesWeek {
+		return bucket.AddDate(0, 0, 7)
+	}
+	return bucket.AddDate(0, 0, 1)
+}
+
+func countBurndownBuckets(regressions []models.TestRegression, granularity apitype.TimeSeriesGranularity,
+	buckets []time.Time, end time.Time) []apitype.RegressionBurndownBucket {
+	results := make([]apitype.RegressionBurndownBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		bucketEnd := nextBurndownBucket(granularity, bucket)
+		if bucketEnd.After(end) {
+			bucketEnd = end
+		}
+
+		result := apitype.RegressionBurndownBucket{Bucket: bucket}
+		for _, r := range regressions {
+			if !r.Opened.Before(bucketEnd) {
+				continue
+			}
+			if !r.Opened.Before(bucket) {
+				result.Opened++
+			}
+			if regressionOpenAt(r, bucketEnd) {
+				result.Open++
+			} else if !r.Closed.Before(bucket) {
+				result.Resolved++
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// meanHoursToResolve returns the mean time to resolution of regressions closed between start and end, or nil if
+// none were.
+func meanHoursToResolve(regressions []models.TestRegression, start, end time.Time) *float64 {
+	var hours []float64
+	for _, r := range regressions {
+		if r.Closed != nil && !r.Closed.Before(start) && !r.Closed.After(end) {
+			hours = append(hours, r.Closed.Sub(r.Opened).Hours())
+		}
+	}
+	if len(hours) == 0 {
+		return nil
+	}
+	mean, _ := meanAndMedian(hours)
+	return &mean
+}
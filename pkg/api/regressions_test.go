@@ -0,0 +1,155 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestRegressionLifecycle(t *testing.T) {
+	end := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-7 * 24 * time.Hour)
+	at := func(hoursBeforeEnd int) *time.Time {
+		t := end.Add(-time.Duration(hoursBeforeEnd) * time.Hour)
+		return &t
+	}
+	regression := func(id uint, component string, opened int, closed *time.Time) models.TestRegression {
+		return models.TestRegression{Model: models.Model{ID: id}, Component: component, Opened: *at(opened), Closed: closed}
+	}
+
+	regressions := []models.TestRegression{
+		// open for 30 days
+		regression(1, "etcd", 720, nil),
+		// resolved in the range after 10 and 20 hours
+		regression(2, "etcd", 40, at(30)),
+		regression(3, "etcd", 60, at(40)),
+		// resolved before the range, only counted as having been open
+		regression(4, "etcd", 400, at(300)),
+		// opened in the range, still open
+		regression(5, "networking", 12, nil),
+		// closed after the end of the range, so still open as of end
+		regression(6, "networking", 100, func() *time.Time { t := end.Add(time.Hour); return &t }()),
+		// opened after the end of the range
+		regression(7, "networking", -5, nil),
+	}
+
+	t.Run("stats by component", func(t *testing.T) {
+		stats := regressionStatsByComponent(regressions, start, end)
+		require.Len(t, stats, 2)
+
+		etcd := stats[0]
+		assert.Equal(t, "etcd", etcd.Component)
+		assert.Equal(t, 1, etcd.Open)
+		assert.Equal(t, 2, etcd.Resolved)
+		require.NotNil(t, etcd.MeanHoursToResolve)
+		assert.InDelta(t, 15, *etcd.MeanHoursToResolve, 0.001)
+		assert.InDelta(t, 15, *etcd.MedianHoursToResolve, 0.001)
+		assert.InDelta(t, 720, *etcd.OldestOpenHours, 0.001)
+
+		networking := stats[1]
+		assert.Equal(t, 2, networking.Open)
+		assert.Equal(t, 0, networking.Resolved)
+		assert.Nil(t, networking.MeanHoursToResolve)
+		assert.InDelta(t, 100, *networking.OldestOpenHours, 0.001)
+	})
+
+	t.Run("weekly report", func(t *testing.T) {
+		report := buildRegressionReport("4.16", regressions, start, end, 2)
+		assert.Equal(t, 4, report.Opened)
+		assert.Equal(t, 2, report.Resolved)
+		require.Len(t, report.Oldest, 2)
+		assert.EqualValues(t, 1, report.Oldest[0].ID)
+		assert.InDelta(t, 720, report.Oldest[0].Hours, 0.001)
+		assert.EqualValues(t, 6, report.Oldest[1].ID)
+	})
+}
+
+func TestMeanAndMedian(t *testing.T) {
+	mean, median := meanAndMedian([]float64{9, 1, 2})
+	assert.InDelta(t, 4, mean, 0.001)
+	assert.InDelta(t, 2, median, 0.001)
+
+	mean, median = meanAndMedian([]float64{4, 1, 2, 3})
+	assert.InDelta(t, 2.5, mean, 0.001)
+	assert.InDelta(t, 2.5, median, 0.001)
+}
+
+func TestRegressionBurndown(t *testing.T) {
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 6, d, hour, 0, 0, 0, time.UTC)
+	}
+	ptr := func(t time.Time) *time.Time { return &t }
+	regressions := []models.TestRegression{
+		{Component: "etcd", Opened: day(3, 12), Closed: ptr(day(4, 12))},
+		{Component: "etcd", Opened: day(3, 18)},
+		{Component: "networking", Opened: day(4, 6), Closed: ptr(day(5, 18))},
+	}
+	leads := map[string]string{"etcd": "alice", "networking": "alice"}
+
+	t.Run("daily by component", func(t *testing.T) {
+		burndown := buildRegressionBurndown("4.16", RegressionGroupByComponent, apitype.TimeSeriesDay, time.UTC, regressions,
+			func(r models.TestRegression) string { return r.Component }, day(3, 12), day(6, 0))
+
+		assert.Equal(t, []apitype.RegressionBurndownBucket{
+			{Bucket: day(3, 0), Open: 2, Opened: 2},
+			{Bucket: day(4, 0), Open: 2, Opened: 1, Resolved: 1},
+			{Bucket: day(5, 0), Open: 1, Resolved: 1},
+		}, burndown.Buckets)
+		require.NotNil(t, burndown.MeanHoursToResolve)
+		assert.InDelta(t, 30, *burndown.MeanHoursToResolve, 0.001)
+
+		require.Len(t, burndown.Groups, 2)
+		assert.Equal(t, "etcd", burndown.Groups[0].Name)
+		assert.Equal(t, 1, burndown.Groups[0].Buckets[2].Open)
+		assert.InDelta(t, 24, *burndown.Groups[0].MeanHoursToResolve, 0.001)
+		assert.Equal(t, "networking", burndown.Groups[1].Name)
+		assert.Equal(t, 0, burndown.Groups[1].Buckets[0].Open)
+	})
+
+	t.Run("weekly by lead", func(t *testing.T) {
+		burndown := buildRegressionBurndown("4.16", RegressionGroupByLead, apitype.TimeSeriesWeek, time.UTC, regressions,
+			func(r models.TestRegression) string { return leads[r.Component] }, day(3, 12), day(12, 0))
+
+		// June 3rd 2024 is a Monday
+		assert.Equal(t, []apitype.RegressionBurndownBucket{
+			{Bucket: day(3, 0), Open: 1, Opened: 3, Resolved: 2},
+			{Bucket: day(10, 0), Open: 1},
+		}, burndown.Buckets)
+		require.Len(t, burndown.Groups, 1)
+		assert.Equal(t, "alice", burndown.Groups[0].Name)
+	})
+
+	t.Run("daily in a timezone", func(t *testing.T) {
+		loc, err := ParseTimezone("America/New_York")
+		require.NoError(t, err)
+		burndown := buildRegressionBurndown("4.16", RegressionGroupByComponent, apitype.TimeSeriesDay, loc, regressions,
+			func(r models.TestRegression) string { return r.Component }, day(3, 12), day(6, 0))
+
+		// Midnight in New York is 04:00 UTC in June
+		assert.Equal(t, "America/New_York", burndown.Timezone)
+		require.Len(t, burndown.Buckets, 3)
+		for i, b := range burndown.Buckets {
+			assert.True(t, day(3+i, 4).Equal(b.Bucket), "bucket %d starts at %s", i, b.Bucket)
+		}
+	})
+}
+
+func TestParseTimezone(t *testing.T) {
+	loc, err := ParseTimezone("")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	loc, err = ParseTimezone("Europe/Prague")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Prague", loc.String())
+
+	_, err = ParseTimezone("Mars/Olympus_Mons")
+	assert.Error(t, err)
+	_, err = ParseTimezone("Local")
+	assert.Error(t, err)
+}
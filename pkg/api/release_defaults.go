@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// DefaultReleaseDateRangeDays is how many days date ranges cover unless the views file configures otherwise,
+// matching the reports' own default.
+const DefaultReleaseDateRangeDays = 14
+
+// ValidateReleaseDefaults checks the release defaults configured in the views file.
+func ValidateReleaseDefaults(defaults map[string]apitype.ReleaseDefaults) error {
+	for release, d := range defaults {
+		switch d.Period {
+		case "", "default", periodTwoDay:
+		default:
+			return fmt.Errorf("release defaults %s: invalid period %q: must be default or %s", release, d.Period, periodTwoDay)
+		}
+		if d.DateRangeDays < 0 {
+			return fmt.Errorf("release defaults %s: date_range_days must not be negative", release)
+		}
+		for _, v := range append(append([]string{}, d.IncludeVariants...), d.ExcludeVariants...) {
+			if !strings.Contains(v, ":") {
+				return fmt.Errorf("release defaults %s: invalid variant %q: must be in the form Name:value", release, v)
+			}
+		}
+	}
+	return nil
+}
+
+// ResolveReleaseDefaults returns the defaults of a release, starting from the built in defaults, then applying the
+// defaults for all releases, and the release's own.
+func ResolveReleaseDefaults(defaults map[string]apitype.ReleaseDefaults, release string) apitype.ReleaseDefaults {
+	resolved := apitype.ReleaseDefaults{
+		Release:         release,
+		ColumnVariants:  []string{},
+		IncludeVariants: []string{},
+		ExcludeVariants: []string{},
+		Period:          "default",
+		DateRangeDays:   DefaultReleaseDateRangeDays,
+	}
+	for _, key := range []string{apitype.ReleaseDefaultsAll, release} {
+		d, ok := defaults[key]
+		if !ok {
+			continue
+		}
+		if d.ColumnVariants != nil {
+			resolved.ColumnVariants = d.ColumnVariants
+		}
+		if d.IncludeVariants != nil {
+			resolved.IncludeVariants = d.IncludeVariants
+		}
+		if d.ExcludeVariants != nil {
+			resolved.ExcludeVariants = d.ExcludeVariants
+		}
+		if d.BlockingJobs != nil {
+			resolved.BlockingJobs = d.BlockingJobs
+		}
+		if d.Period != "" {
+			resolved.Period = d.Period
+		}
+		if d.DateRangeDays > 0 {
+			resolved.DateRangeDays = d.DateRangeDays
+		}
+	}
+	return resolved
+}
+
+// GetReleaseDefaults returns the default view configuration of a release. Blocking jobs that aren't configured are
+// the jobs that blocked the release's payloads within the default date range.
+func GetReleaseDefaults(dbc *db.DB, defaults map[string]apitype.ReleaseDefaults, release string, reportEnd time.Time) (apitype.ReleaseDefaults, error) {
+	resolved := ResolveReleaseDefaults(defaults, release)
+	if resolved.BlockingJobs == nil {
+		since := reportEnd.Add(-time.Duration(resolved.DateRangeDays) * 24 * time.Hour)
+		jobs, err := query.BlockingJobNames(dbc, release, since)
+		if err != nil {
+			return resolved, err
+		}
+		resolved.BlockingJobs = jobs
+	}
+	return resolved, nil
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestResolveReleaseDefaults(t *testing.T) {
+	defaults := map[string]apitype.ReleaseDefaults{
+		apitype.ReleaseDefaultsAll: {
+			ColumnVariants:  []string{"Platform", "Network"},
+			ExcludeVariants: []string{"Owner:eng-special"},
+			Period:          "twoDay",
+		},
+		"4.16": {
+			ColumnVariants: []string{"Platform"},
+			BlockingJobs:   []string{"e2e-aws"},
+			DateRangeDays:  7,
+		},
+	}
+
+	assert.Equal(t, apitype.ReleaseDefaults{
+		Release:         "4.16",
+		ColumnVariants:  []string{"Platform"},
+		IncludeVariants: []string{},
+		ExcludeVariants: []string{"Owner:eng-special"},
+		BlockingJobs:    []string{"e2e-aws"},
+		Period:          "twoDay",
+		DateRangeDays:   7,
+	}, ResolveReleaseDefaults(defaults, "4.16"))
+
+	// Releases without their own entry get the defaults for all releases
+	other := ResolveReleaseDefaults(defaults, "4.15")
+	assert.Equal(t, []string{"Platform", "Network"}, other.ColumnVariants)
+	assert.Nil(t, other.BlockingJobs)
+	assert.Equal(t, DefaultReleaseDateRangeDays, other.DateRangeDays)
+
+	// Nothing configured
+	none := ResolveReleaseDefaults(nil, "4.15")
+	assert.Equal(t, "default", none.Period)
+	assert.Empty(t, none.ColumnVariants)
+}
+
+func TestValidateReleaseDefaults(t *testing.T) {
+	assert.NoError(t, ValidateReleaseDefaults(map[string]apitype.ReleaseDefaults{
+		"4.16": {Period: "twoDay", IncludeVariants: []string{"Platform:aws"}},
+	}))
+	assert.Error(t, ValidateReleaseDefaults(map[string]apitype.ReleaseDefaults{"4.16": {Period: "weekly"}}))
+	assert.Error(t, ValidateReleaseDefaults(map[string]apitype.ReleaseDefaults{"4.16": {DateRangeDays: -1}}))
+	assert.Error(t, ValidateReleaseDefaults(map[string]apitype.ReleaseDefaults{"4.16": {ExcludeVariants: []string{"aws"}}}))
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	bqclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	DefaultReleaseTrendReleases    = 4
+	DefaultReleaseTrendWeeksBefore = 12
+	DefaultReleaseTrendWeeksAfter  = 4
+
+	// maxReleaseTrendReleases bounds the number of per release queries a single request can make.
+	maxReleaseTrendReleases = 10
+	maxReleaseTrendWeeks    = 52
+)
+
+// ValidateReleaseTrendRequest ensures exactly one of test or component is set and the number of releases and weeks
+// is sane.
+func ValidateReleaseTrendRequest(test, component string, releases, weeksBefore, weeksAfter int) error {
+	if (test == "") == (component == "") {
+		return fmt.Errorf("exactly one of test or component must be specified")
+	}
+	if releases < 1 || releases > maxReleaseTrendReleases {
+		return fmt.Errorf("releases must be between 1 and %d", maxReleaseTrendReleases)
+	}
+	if weeksBefore < 0 || weeksAfter < 0 || weeksBefore+weeksAfter == 0 || weeksBefore+weeksAfter > maxReleaseTrendWeeks {
+		return fmt.Errorf("weeks_before and weeks_after must not be negative and add up to between 1 and %d", maxReleaseTrendWeeks)
+	}
+	return nil
+}
+
+// ReleaseGADates returns the GA date of each release that has one, from the releases table in BigQuery when
+// available, falling back to the known GA dates. Releases that have not GA'd yet use their ga release milestone.
+func ReleaseGADates(dbc *db.DB, releases []query.Release) (map[string]time.Time, error) {
+	gaDates := make(map[string]time.Time)
+	maps.Copy(gaDates, releaseloader.GADateMap)
+	for _, r := range releases {
+		if r.GADate != nil {
+			gaDates[r.Release] = *r.GADate
+		}
+	}
+
+	milestones := []models.ReleaseMilestone{}
+	if res := dbc.DB.Where("name = ?", models.MilestoneGA).Find(&milestones); res.Error != nil {
+		return nil, res.Error
+	}
+	for _, m := range milestones {
+		if _, ok := gaDates[m.Release]; !ok {
+			gaDates[m.Release] = m.Date.UTC()
+		}
+	}
+	return gaDates, nil
+}
+
+// GetReleaseTrend returns the weekly pass rate of a test, or of a component's tests, in each of the most recent
+// releases with a known or planned GA date, aligned by weeks to GA so structural changes between releases stand out.
+// Only results still in the database are counted.
+func GetReleaseTrend(dbc *db.DB, bqc *bqclient.Client, test, component string, releases, weeksBefore, weeksAfter int) (*apitype.ReleaseTrend, error) {
+	if err := ValidateReleaseTrendRequest(test, component, releases, weeksBefore, weeksAfter); err != nil {
+		return nil, err
+	}
+
+	allReleases, err := GetReleases(dbc, bqc)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing releases")
+	}
+	gaDates, err := ReleaseGADates(dbc, allReleases)
+	if err != nil {
+		return nil, errors.Wrap(err, "error listing release GA dates")
+	}
+
+	// Releases are listed newest first, but are charted oldest first.
+	selected := []string{}
+	for _, r := range allReleases {
+		if _, ok := gaDates[r.Release]; ok && len(selected) < releases {
+			selected = append([]string{r.Release}, selected...)
+		}
+	}
+
+	result := &apitype.ReleaseTrend{
+		Test:        test,
+		Component:   component,
+		WeeksBefore: weeksBefore,
+		WeeksAfter:  weeksAfter,
+		Releases:    make([]apitype.ReleaseTrendSeries, 0, len(selected)),
+	}
+	for _, release := range selected {
+		ga := gaDates[release]
+		weeks, err := query.ReleaseTrendWeeks(dbc, release, test, component, ga, weeksBefore, weeksAfter)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error querying %s trend", release)
+		}
+		result.Releases = append(result.Releases, buildReleaseTrendSeries(release, ga, weeks, weeksBefore, weeksAfter))
+	}
+	return result, nil
+}
+
+// buildReleaseTrendSeries fills in the weeks without results, so every release has the same weeks and can be
+// charted against the others.
+func buildReleaseTrendSeries(release string, ga time.Time, counts []query.WeekCounts, weeksBefore, weeksAfter int) apitype.ReleaseTrendSeries {
+	byWeek := make(map[int]query.WeekCounts, len(counts))
+	for _, c := range counts {
+		byWeek[c.Week] = c
+	}
+
+	series := apitype.ReleaseTrendSeries{
+		Release: release,
+		GADate:  ga,
+		Weeks:   make([]apitype.ReleaseTrendWeek, 0, weeksBefore+weeksAfter),
+	}
+	passes := 0
+	for w := -weeksBefore; w < weeksAfter; w++ {
+		c := byWeek[w]
+		week := apitype.ReleaseTrendWeek{
+			WeeksToGA: w,
+			Start:     ga.AddDate(0, 0, 7*w),
+			Runs:      c.Runs,
+			Passes:    c.Passes,
+			Flakes:    c.Flakes,
+			Failures:  c.Failures,
+
+			PassPercentageCI: apitype.NewConfidenceInterval(c.Passes, c.Runs),
+		}
+		if c.Runs > 0 {
+			pct := float64(c.Passes) * 100 / float64(c.Runs)
+			week.PassPercentage = &pct
+		}
+		series.Weeks = append(series.Weeks, week)
+		series.Runs += c.Runs
+		passes += c.Passes
+	}
+	if series.Runs > 0 {
+		pct := float64(passes) * 100 / float64(series.Runs)
+		series.PassPercentage = &pct
+	}
+	series.PassPercentageCI = apitype.NewConfidenceInterval(passes, series.Runs)
+	return series
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestValidateReleaseTrendRequest(t *testing.T) {
+	assert.NoError(t, ValidateReleaseTrendRequest("test a", "", 4, 12, 4))
+	assert.NoError(t, ValidateReleaseTrendRequest("", "Networking", 1, 0, 4))
+	assert.Error(t, ValidateReleaseTrendRequest("", "", 4, 12, 4))
+	assert.Error(t, ValidateReleaseTrendRequest("test a", "Networking", 4, 12, 4))
+	assert.Error(t, ValidateReleaseTrendRequest("test a", "", 0, 12, 4))
+	assert.Error(t, ValidateReleaseTrendRequest("test a", "", 11, 12, 4))
+	assert.Error(t, ValidateReleaseTrendRequest("test a", "", 4, -1, 4))
+	assert.Error(t, ValidateReleaseTrendRequest("test a", "", 4, 0, 0))
+	assert.Error(t, ValidateReleaseTrendRequest("test a", "", 4, 50, 4))
+}
+
+func TestBuildReleaseTrendSeries(t *testing.T) {
+	ga := time.Date(2024, 6, 27, 0, 0, 0, 0, time.UTC)
+	counts := []query.WeekCounts{
+		{Week: -3, Runs: 100, Passes: 90, Flakes: 5, Failures: 5},
+		{Week: -1, Runs: 100, Passes: 70, Failures: 30},
+		{Week: 1, Runs: 50, Passes: 50},
+	}
+
+	series := buildReleaseTrendSeries("4.16", ga, counts, 3, 2)
+	assert.Equal(t, "4.16", series.Release)
+	require.Len(t, series.Weeks, 5)
+	assert.Equal(t, -3, series.Weeks[0].WeeksToGA)
+	assert.Equal(t, ga.AddDate(0, 0, -21), series.Weeks[0].Start)
+	require.NotNil(t, series.Weeks[0].PassPercentage)
+	assert.InDelta(t, 90.0, *series.Weeks[0].PassPercentage, 0.001)
+	// Weeks without results are filled in
+	assert.Equal(t, -2, series.Weeks[1].WeeksToGA)
+	assert.Zero(t, series.Weeks[1].Runs)
+	assert.Nil(t, series.Weeks[1].PassPercentage)
+	assert.Equal(t, 0, series.Weeks[3].WeeksToGA)
+	assert.Equal(t, ga, series.Weeks[3].Start)
+	assert.Equal(t, 1, series.Weeks[4].WeeksToGA)
+
+	assert.Equal(t, 250, series.Runs)
+	require.NotNil(t, series.PassPercentage)
+	assert.InDelta(t, 84.0, *series.PassPercentage, 0.001)
+}
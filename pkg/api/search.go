@@ -0,0 +1,206 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+const (
+	// DefaultSearchLimit is the number of results of each kind a search returns by default.
+	DefaultSearchLimit = 10
+	// MaxSearchLimit is the most results of each kind a search can return.
+	MaxSearchLimit = 50
+	// minSearchLength is the shortest query searched, as shorter ones match too much to be useful.
+	minSearchLength = 2
+
+	// searchCandidates is how many more records than requested are ranked for each kind, as the database orders
+	// them more coarsely than searchScore.
+	searchCandidates = 4
+)
+
+var searchKinds = map[string]bool{
+	apitype.SearchKindTests:       true,
+	apitype.SearchKindJobs:        true,
+	apitype.SearchKindComponents:  true,
+	apitype.SearchKindVariants:    true,
+	apitype.SearchKindRegressions: true,
+	apitype.SearchKindIncidents:   true,
+}
+
+// SearchRequest is a search of the names of several kinds of records.
+type SearchRequest struct {
+	Terms   []string
+	Release string
+	Kinds   []string
+	Limit   int
+}
+
+// ParseSearch reads a search from the request's params. q is split into whitespace separated terms which must all
+// match, and kinds may be repeated or comma separated, defaulting to all kinds.
+func ParseSearch(req *http.Request) (SearchRequest, error) {
+	params := req.URL.Query()
+	search := SearchRequest{
+		Terms:   strings.Fields(params.Get("q")),
+		Release: params.Get("release"),
+		Limit:   DefaultSearchLimit,
+	}
+	if len(strings.Join(search.Terms, " ")) < minSearchLength {
+		return search, fmt.Errorf("q must be at least %d characters", minSearchLength)
+	}
+
+	for _, param := range params["kinds"] {
+		for _, kind := range strings.Split(param, ",") {
+			if !searchKinds[kind] {
+				return search, fmt.Errorf("invalid kind %q: must be one of %s", kind, strings.Join(apitype.SearchKinds, ", "))
+			}
+			search.Kinds = append(search.Kinds, kind)
+		}
+	}
+	if len(search.Kinds) == 0 {
+		search.Kinds = apitype.SearchKinds
+	}
+
+	if limitParam := params.Get("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit < 1 || limit > MaxSearchLimit {
+			return search, fmt.Errorf("invalid limit %q: must be between 1 and %d", limitParam, MaxSearchLimit)
+		}
+		search.Limit = limit
+	}
+	return search, nil
+}
+
+// Search searches the names of each kind of record for all the terms of a search, returning up to its limit of each
+// kind ranked by how closely they match.
+func Search(dbc *db.DB, search SearchRequest) (*apitype.SearchResults, error) {
+	matches, err := query.SearchEntities(dbc, search.Kinds, search.Terms, search.Release, search.Limit*searchCandidates)
+	if err != nil {
+		return nil, errors.Wrap(err, "error searching")
+	}
+	return &apitype.SearchResults{
+		Query:   strings.Join(search.Terms, " "),
+		Release: search.Release,
+		Groups:  searchGroups(search.Kinds, matches, search.Terms, search.Release, search.Limit),
+	}, nil
+}
+
+func searchGroups(kinds []string, matches map[string][]query.SearchMatch, terms []string, release string, limit int) []apitype.SearchGroup {
+	groups := make([]apitype.SearchGroup, 0, len(kinds))
+	for _, kind := range kinds {
+		group := apitype.SearchGroup{Kind: kind, Results: []apitype.SearchResult{}}
+		for _, m := range matches[kind] {
+			group.Results = append(group.Results, apitype.SearchResult{
+				ID:      m.ID,
+				Name:    m.Name,
+				Release: m.Release,
+				Detail:  m.Detail,
+				Score:   searchScore(m.Name, terms),
+				Link:    searchLink(kind, m, release),
+			})
+		}
+		sort.SliceStable(group.Results, func(i, j int) bool {
+			return group.Results[i].Score > group.Results[j].Score
+		})
+		if len(group.Results) > limit {
+			group.Results = group.Results[:limit]
+			group.More = true
+		}
+		groups = append(groups, group)
+	}
+
+	// kinds are kept in the requested order when their best results are equally relevant
+	sort.SliceStable(groups, func(i, j int) bool {
+		return topScore(groups[i]) > topScore(groups[j])
+	})
+	return groups
+}
+
+func topScore(group apitype.SearchGroup) float64 {
+	if len(group.Results) == 0 {
+		return 0
+	}
+	return group.Results[0].Score
+}
+
+// searchScore ranks how closely a name matches the terms of a search, from 0 to 1: names equal to the terms rank
+// highest, then names starting with them, containing them as a word, containing them, and last containing each term
+// separately. Within each, names the terms make up more of rank higher.
+func searchScore(name string, terms []string) float64 {
+	n := strings.ToLower(name)
+	phrase := strings.ToLower(strings.Join(terms, " "))
+	if n == "" || phrase == "" {
+		return 0
+	}
+
+	var match float64
+	idx := strings.Index(n, phrase)
+	switch {
+	case n == phrase:
+		match = 1
+	case idx == 0:
+		match = 0.8
+	case idx > 0 && wordBoundary(n, idx):
+		match = 0.6
+	case idx > 0:
+		match = 0.4
+	default:
+		match = 0.2
+	}
+	coverage := math.Min(1, float64(len(phrase))/float64(len(n)))
+	return math.Round((0.8*match+0.2*coverage)*1000) / 1000
+}
+
+// wordBoundary returns whether the text at idx starts a word, i.e. the previous character is not a letter or
+// number.
+func wordBoundary(s string, idx int) bool {
+	prev := rune(s[idx-1])
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
+
+// searchLink returns the API endpoint a search result can be looked up with, if there is one. Tests, components and
+// variants are only reported per release, so are only linked when searching a release.
+func searchLink(kind string, m query.SearchMatch, release string) string {
+	switch kind {
+	case apitype.SearchKindTests:
+		if release != "" {
+			return fmt.Sprintf("/api/tests/details?release=%s&test=%s", url.QueryEscape(release), url.QueryEscape(m.Name))
+		}
+	case apitype.SearchKindJobs:
+		return fmt.Sprintf("/api/jobs/details?release=%s&job=%s", url.QueryEscape(m.Release), url.QueryEscape(m.Name))
+	case apitype.SearchKindComponents:
+		if release != "" {
+			return fmt.Sprintf("/api/component_readiness/regressions?release=%s&component=%s",
+				url.QueryEscape(release), url.QueryEscape(m.Name))
+		}
+	case apitype.SearchKindVariants:
+		if release != "" {
+			variantFilter, _ := json.Marshal(filter.Filter{Items: []filter.FilterItem{{
+				Field:    "variants",
+				Operator: filter.OperatorContains,
+				Value:    m.Name,
+			}}})
+			return fmt.Sprintf("/api/jobs?release=%s&filter=%s", url.QueryEscape(release),
+				url.QueryEscape(string(variantFilter)))
+		}
+	case apitype.SearchKindRegressions:
+		return fmt.Sprintf("/api/component_readiness/regressions?release=%s&component=%s",
+			url.QueryEscape(m.Release), url.QueryEscape(m.Component))
+	case apitype.SearchKindIncidents:
+		return fmt.Sprintf("/api/incidents/timeline?id=%d", m.ID)
+	}
+	return ""
+}
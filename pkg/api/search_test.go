@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestParseSearch(t *testing.T) {
+	search, err := ParseSearch(httptest.NewRequest("GET", "/api/search?q=+etcd++leader+&release=4.16", nil))
+	require.NoError(t, err)
+	assert.Equal(t, SearchRequest{
+		Terms:   []string{"etcd", "leader"},
+		Release: "4.16",
+		Kinds:   apitype.SearchKinds,
+		Limit:   DefaultSearchLimit,
+	}, search)
+
+	search, err = ParseSearch(httptest.NewRequest("GET", "/api/search?q=aws&kinds=jobs,variants&kinds=tests&limit=5", nil))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"jobs", "variants", "tests"}, search.Kinds)
+	assert.Equal(t, 5, search.Limit)
+
+	for params, expected := range map[string]string{
+		"q=+a+":            "at least 2 characters",
+		"q=aws&kinds=bugs": `invalid kind "bugs"`,
+		"q=aws&limit=0":    `invalid limit "0"`,
+		"q=aws&limit=100":  `invalid limit "100"`,
+		"q=aws&limit=lots": `invalid limit "lots"`,
+		"release=4.16&q=":  "at least 2 characters",
+	} {
+		_, err := ParseSearch(httptest.NewRequest("GET", "/api/search?"+params, nil))
+		assert.ErrorContains(t, err, expected, params)
+	}
+}
+
+func TestSearchScore(t *testing.T) {
+	terms := []string{"etcd"}
+	exact := searchScore("etcd", terms)
+	prefix := searchScore("etcd-operator", terms)
+	word := searchScore("[sig-etcd] leader changes", terms)
+	substring := searchScore("kube-fetcd", terms)
+	assert.Equal(t, 1.0, exact)
+	assert.Greater(t, exact, prefix)
+	assert.Greater(t, prefix, word)
+	assert.Greater(t, word, substring)
+	assert.Greater(t, searchScore("etcd-a", terms), searchScore("etcd-operator", terms), "shorter names rank higher")
+
+	separate := searchScore("etcd leader changes", []string{"leader", "etcd"})
+	assert.Greater(t, substring, separate, "terms matching separately rank lowest")
+	assert.Greater(t, separate, 0.0)
+
+	assert.Equal(t, searchScore("ETCD", terms), exact, "matching is case insensitive")
+}
+
+func TestSearchGroups(t *testing.T) {
+	matches := map[string][]query.SearchMatch{
+		apitype.SearchKindJobs: {
+			{ID: 1, Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws", Release: "4.16"},
+			{ID: 2, Name: "aws-e2e", Release: "4.16"},
+			{ID: 3, Name: "e2e-aws-upgrade", Release: "4.16"},
+		},
+		apitype.SearchKindVariants: {
+			{Name: "Platform:aws", Detail: "512 jobs"},
+		},
+		apitype.SearchKindIncidents: {
+			{ID: 7, Name: "AWS quota exhausted", Release: "", Detail: "infrastructure"},
+		},
+	}
+	kinds := []string{apitype.SearchKindJobs, apitype.SearchKindVariants, apitype.SearchKindIncidents, apitype.SearchKindTests}
+
+	groups := searchGroups(kinds, matches, []string{"aws"}, "4.16", 2)
+	require.Len(t, groups, 4)
+
+	jobs := groups[0]
+	assert.Equal(t, apitype.SearchKindJobs, jobs.Kind, "starts with a job name prefixed by the query")
+	require.Len(t, jobs.Results, 2)
+	assert.True(t, jobs.More)
+	assert.Equal(t, "aws-e2e", jobs.Results[0].Name)
+	assert.Equal(t, "e2e-aws-upgrade", jobs.Results[1].Name)
+	assert.Equal(t, "/api/jobs/details?release=4.16&job=aws-e2e", jobs.Results[0].Link)
+
+	assert.Equal(t, apitype.SearchKindIncidents, groups[1].Kind)
+	assert.Equal(t, "/api/incidents/timeline?id=7", groups[1].Results[0].Link)
+	assert.Equal(t, apitype.SearchKindVariants, groups[2].Kind)
+	assert.False(t, groups[2].More)
+
+	assert.Equal(t, apitype.SearchGroup{Kind: apitype.SearchKindTests, Results: []apitype.SearchResult{}}, groups[3],
+		"kinds without results are still listed")
+}
+
+func TestSearchLink(t *testing.T) {
+	variant := query.SearchMatch{Name: "Platform:aws"}
+	link, err := url.Parse(searchLink(apitype.SearchKindVariants, variant, "4.16"))
+	require.NoError(t, err)
+	assert.Equal(t, "/api/jobs", link.Path)
+	assert.Equal(t, "4.16", link.Query().Get("release"))
+	assert.Contains(t, link.Query().Get("filter"), `"value":"Platform:aws"`)
+
+	assert.Empty(t, searchLink(apitype.SearchKindVariants, variant, ""), "jobs are only listed for a release")
+	assert.Empty(t, searchLink(apitype.SearchKindTests, query.SearchMatch{Name: "a test"}, ""))
+
+	regression := query.SearchMatch{ID: 3, Name: "a test", Release: "4.15", Component: "Networking / ovn"}
+	assert.Equal(t, "/api/component_readiness/regressions?release=4.15&component=Networking+%2F+ovn",
+		searchLink(apitype.SearchKindRegressions, regression, ""))
+}
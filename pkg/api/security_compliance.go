@@ -0,0 +1,201 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/variantregistry"
+)
+
+const (
+	// DefaultSecurityMinTestRuns is the number of runs in a profile's jobs a test needs before it's compared to the
+	// baseline.
+	DefaultSecurityMinTestRuns = 10
+	// securityWorseTestDelta is how many points lower a test's pass percentage in a profile must be than in the
+	// baseline to be reported.
+	securityWorseTestDelta = 10.0
+	// securityMaxWorseTests limits the tests reported per profile to the largest differences.
+	securityMaxWorseTests = 50
+)
+
+// securityConfigurationVariants make up the configuration of a job when matching security profile jobs to the
+// baseline matrix.
+var securityConfigurationVariants = []string{
+	variantregistry.VariantPlatform,
+	variantregistry.VariantArch,
+	variantregistry.VariantNetwork,
+	variantregistry.VariantNetworkStack,
+	variantregistry.VariantTopology,
+	variantregistry.VariantInstaller,
+	variantregistry.VariantUpgrade,
+	variantregistry.VariantFeatureSet,
+}
+
+// GetSecurityComplianceReport reports on the jobs of each non-default SecurityMode variant value in a release, e.g.
+// fips, compared to the default jobs: pass rates, the configurations of the default matrix no profile job covers,
+// and tests that pass less often in the profile. If profile is set only that SecurityMode value is reported.
+func GetSecurityComplianceReport(dbc *db.DB, release, profile string, start, end time.Time, minTestRuns int) (*apitype.SecurityComplianceReport, error) {
+	jobs, err := query.SecurityModeJobs(dbc, release, start, end)
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying security mode jobs")
+	}
+
+	profiles := buildSecurityProfiles(jobs, profile)
+	for i := range profiles {
+		tests, err := query.SecurityModeTests(dbc, release, profiles[i].Profile, variantregistry.VariantDefaultValue, start, end, minTestRuns)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error querying %s tests", profiles[i].Profile)
+		}
+		profiles[i].WorseTests = worseSecurityTests(tests)
+	}
+
+	return &apitype.SecurityComplianceReport{
+		Release:               release,
+		Start:                 start,
+		End:                   end,
+		ConfigurationVariants: securityConfigurationVariants,
+		Profiles:              profiles,
+	}, nil
+}
+
+// securityConfiguration returns the sorted configuration variants of a job.
+func securityConfiguration(variants []string) []string {
+	configuration := []string{}
+	for _, v := range variants {
+		name, _, _ := strings.Cut(v, ":")
+		for _, c := range securityConfigurationVariants {
+			if name == c {
+				configuration = append(configuration, v)
+				break
+			}
+		}
+	}
+	sort.Strings(configuration)
+	return configuration
+}
+
+type securityBaseline struct {
+	configuration []string
+	jobs          []string
+	runs          int
+	passes        int
+}
+
+func buildSecurityProfiles(jobs []query.SecurityModeJobRuns, profile string) []apitype.SecurityProfileCompliance {
+	baselines := map[string]*securityBaseline{}
+	byProfile := map[string][]query.SecurityModeJobRuns{}
+	for _, j := range jobs {
+		if j.SecurityMode != variantregistry.VariantDefaultValue {
+			if profile == "" || j.SecurityMode == profile {
+				byProfile[j.SecurityMode] = append(byProfile[j.SecurityMode], j)
+			}
+			continue
+		}
+		configuration := securityConfiguration(j.Variants)
+		key := strings.Join(configuration, ",")
+		b, ok := baselines[key]
+		if !ok {
+			b = &securityBaseline{configuration: configuration}
+			baselines[key] = b
+		}
+		b.jobs = append(b.jobs, j.JobName)
+		b.runs += j.Runs
+		b.passes += j.Passes
+	}
+
+	results := make([]apitype.SecurityProfileCompliance, 0, len(byProfile))
+	for name, profileJobs := range byProfile {
+		p := apitype.SecurityProfileCompliance{
+			Profile:                name,
+			BaselineConfigurations: len(baselines),
+			CoverageGaps:           []apitype.SecurityCoverageGap{},
+			JobResults:             []apitype.SecurityProfileJob{},
+			WorseTests:             []apitype.SecurityProfileTest{},
+		}
+		covered := map[string]bool{}
+		var passes, baselineRuns, baselinePasses int
+		for _, j := range profileJobs {
+			configuration := securityConfiguration(j.Variants)
+			key := strings.Join(configuration, ",")
+			result := apitype.SecurityProfileJob{
+				Name:             j.JobName,
+				Configuration:    configuration,
+				Runs:             j.Runs,
+				PassPercentage:   percentage(j.Passes, j.Runs),
+				PassPercentageCI: apitype.NewConfidenceInterval(j.Passes, j.Runs),
+			}
+			if b, ok := baselines[key]; ok {
+				result.BaselineRuns = b.runs
+				result.BaselinePassPercentage = percentage(b.passes, b.runs)
+				result.BaselinePassPercentageCI = apitype.NewConfidenceInterval(b.passes, b.runs)
+				if !covered[key] {
+					baselineRuns += b.runs
+					baselinePasses += b.passes
+				}
+				covered[key] = true
+			}
+			p.Jobs++
+			p.Runs += j.Runs
+			passes += j.Passes
+			p.JobResults = append(p.JobResults, result)
+		}
+		p.PassPercentage = percentage(passes, p.Runs)
+		p.PassPercentageCI = apitype.NewConfidenceInterval(passes, p.Runs)
+		p.BaselinePassPercentage = percentage(baselinePasses, baselineRuns)
+		p.CoveredConfigurations = len(covered)
+		p.CoveragePercentage = percentage(p.CoveredConfigurations, p.BaselineConfigurations)
+
+		for key, b := range baselines {
+			if !covered[key] {
+				sort.Strings(b.jobs)
+				p.CoverageGaps = append(p.CoverageGaps, apitype.SecurityCoverageGap{Configuration: b.configuration, BaselineJobs: b.jobs})
+			}
+		}
+		sort.Slice(p.CoverageGaps, func(i, j int) bool {
+			return strings.Join(p.CoverageGaps[i].Configuration, ",") < strings.Join(p.CoverageGaps[j].Configuration, ",")
+		})
+		results = append(results, p)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Profile < results[j].Profile
+	})
+	return results
+}
+
+// worseSecurityTests returns the tests whose pass percentage in a profile's jobs is well below their pass percentage
+// in the baseline jobs, largest difference first. Tests the baseline jobs don't run can't be compared.
+func worseSecurityTests(tests []query.SecurityModeTestResults) []apitype.SecurityProfileTest {
+	worse := []apitype.SecurityProfileTest{}
+	for _, t := range tests {
+		if t.Runs == 0 || t.BaselineRuns == 0 {
+			continue
+		}
+		pass := float64(t.Passes) * 100 / float64(t.Runs)
+		baselinePass := float64(t.BaselinePasses) * 100 / float64(t.BaselineRuns)
+		if baselinePass-pass < securityWorseTestDelta {
+			continue
+		}
+		worse = append(worse, apitype.SecurityProfileTest{
+			Name:                     t.TestName,
+			Runs:                     t.Runs,
+			PassPercentage:           pass,
+			PassPercentageCI:         apitype.NewConfidenceInterval(t.Passes, t.Runs),
+			BaselineRuns:             t.BaselineRuns,
+			BaselinePassPercentage:   baselinePass,
+			BaselinePassPercentageCI: apitype.NewConfidenceInterval(t.BaselinePasses, t.BaselineRuns),
+		})
+	}
+	sort.SliceStable(worse, func(i, j int) bool {
+		return worse[i].BaselinePassPercentage-worse[i].PassPercentage > worse[j].BaselinePassPercentage-worse[j].PassPercentage
+	})
+	if len(worse) > securityMaxWorseTests {
+		worse = worse[:securityMaxWorseTests]
+	}
+	return worse
+}
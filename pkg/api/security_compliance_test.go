@@ -0,0 +1,64 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestSecurityConfiguration(t *testing.T) {
+	assert.Equal(t, []string{"Architecture:amd64", "Platform:aws", "Upgrade:none"},
+		securityConfiguration([]string{"Upgrade:none", "Owner:eng", "Platform:aws", "SecurityMode:fips", "Architecture:amd64"}))
+}
+
+func TestBuildSecurityProfiles(t *testing.T) {
+	jobs := []query.SecurityModeJobRuns{
+		{JobName: "e2e-aws", SecurityMode: "default", Variants: pq.StringArray{"Platform:aws", "SecurityMode:default"}, Runs: 10, Passes: 9},
+		{JobName: "e2e-aws-serial", SecurityMode: "default", Variants: pq.StringArray{"Platform:aws", "SecurityMode:default", "Suite:serial"}, Runs: 10, Passes: 7},
+		{JobName: "e2e-gcp", SecurityMode: "default", Variants: pq.StringArray{"Platform:gcp", "SecurityMode:default"}, Runs: 5, Passes: 5},
+		{JobName: "e2e-aws-fips", SecurityMode: "fips", Variants: pq.StringArray{"Platform:aws", "SecurityMode:fips"}, Runs: 4, Passes: 2},
+		// Not in the default matrix, reported but not counted as coverage
+		{JobName: "e2e-metal-fips", SecurityMode: "fips", Variants: pq.StringArray{"Platform:metal", "SecurityMode:fips"}, Runs: 2, Passes: 2},
+	}
+
+	profiles := buildSecurityProfiles(jobs, "")
+	require.Len(t, profiles, 1)
+	fips := profiles[0]
+	assert.Equal(t, "fips", fips.Profile)
+	assert.Equal(t, 2, fips.Jobs)
+	assert.Equal(t, 6, fips.Runs)
+	require.NotNil(t, fips.PassPercentage)
+	assert.InDelta(t, 66.67, *fips.PassPercentage, 0.01)
+	// Both aws default jobs share the configuration
+	require.NotNil(t, fips.BaselinePassPercentage)
+	assert.InDelta(t, 80.0, *fips.BaselinePassPercentage, 0.01)
+
+	assert.Equal(t, 2, fips.BaselineConfigurations)
+	assert.Equal(t, 1, fips.CoveredConfigurations)
+	require.Len(t, fips.CoverageGaps, 1)
+	assert.Equal(t, []string{"Platform:gcp"}, fips.CoverageGaps[0].Configuration)
+	assert.Equal(t, []string{"e2e-gcp"}, fips.CoverageGaps[0].BaselineJobs)
+
+	require.Len(t, fips.JobResults, 2)
+	assert.Equal(t, 20, fips.JobResults[0].BaselineRuns)
+	assert.Nil(t, fips.JobResults[1].BaselinePassPercentage)
+
+	assert.Empty(t, buildSecurityProfiles(jobs, "other"))
+}
+
+func TestWorseSecurityTests(t *testing.T) {
+	worse := worseSecurityTests([]query.SecurityModeTestResults{
+		{TestName: "slightly worse", Runs: 100, Passes: 95, BaselineRuns: 100, BaselinePasses: 100},
+		{TestName: "much worse", Runs: 10, Passes: 5, BaselineRuns: 100, BaselinePasses: 99},
+		{TestName: "worse", Runs: 10, Passes: 8, BaselineRuns: 100, BaselinePasses: 100},
+		{TestName: "profile only", Runs: 10, Passes: 0},
+	})
+	require.Len(t, worse, 2)
+	assert.Equal(t, "much worse", worse[0].Name)
+	assert.InDelta(t, 50.0, worse[0].PassPercentage, 0.01)
+	assert.Equal(t, "worse", worse[1].Name)
+}
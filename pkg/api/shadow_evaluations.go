@@ -0,0 +1,145 @@
+package api
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// SaveShadowEvaluations records the shadow algorithm evaluations of a view's report. Reports are cached, so an
+// evaluation of a report that was already recorded is skipped.
+func SaveShadowEvaluations(dbc *db.DB, view crtype.View, report *crtype.ComponentReport) error {
+	if len(report.ShadowEvaluations) == 0 {
+		return nil
+	}
+	generatedAt := time.Now()
+	if report.GeneratedAt != nil {
+		generatedAt = *report.GeneratedAt
+	}
+
+	return dbc.DB.Transaction(func(tx *gorm.DB) error {
+		for _, e := range report.ShadowEvaluations {
+			existing := &models.ShadowEvaluation{}
+			res := tx.Where("view = ? AND algorithm = ? AND generated_at = ?", view.Name, e.Algorithm, generatedAt).First(existing)
+			if res.Error == nil {
+				continue
+			} else if !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+				return res.Error
+			}
+
+			evaluation := models.ShadowEvaluation{
+				View:           view.Name,
+				Release:        view.SampleRelease.Release,
+				Algorithm:      e.Algorithm,
+				GeneratedAt:    generatedAt,
+				TestsEvaluated: e.TestsEvaluated,
+				BothRegressed:  e.BothRegressed,
+				CurrentOnly:    e.CurrentOnly,
+				CandidateOnly:  e.CandidateOnly,
+			}
+			for _, v := range e.Verdicts {
+				evaluation.Verdicts = append(evaluation.Verdicts, models.ShadowVerdict{
+					TestID:             v.TestID,
+					TestName:           v.TestName,
+					Component:          v.Component,
+					Variants:           variantStrings(v.Variants),
+					CurrentStatus:      int(v.CurrentStatus),
+					CurrentRegressed:   v.CurrentRegressed,
+					CandidateRegressed: v.CandidateRegressed,
+					CandidateScore:     v.CandidateScore,
+				})
+			}
+			if err := tx.Create(&evaluation).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetShadowComparisons summarizes the shadow evaluations of a release's views between start and end, for each view
+// and algorithm, optionally limited to one view or algorithm.
+func GetShadowComparisons(dbc *db.DB, release, view, algorithm string, start, end time.Time) ([]apitype.ShadowComparison, error) {
+	q := dbc.DB.Where("release = ? AND generated_at BETWEEN ? AND ?", release, start, end)
+	if view != "" {
+		q = q.Where("view = ?", view)
+	}
+	if algorithm != "" {
+		q = q.Where("algorithm = ?", algorithm)
+	}
+	var evaluations []models.ShadowEvaluation
+	if res := q.Order("generated_at").Find(&evaluations); res.Error != nil {
+		return nil, res.Error
+	}
+
+	type key struct{ view, algorithm string }
+	comparisons := map[key]*apitype.ShadowComparison{}
+	latest := map[key]uint{}
+	for _, e := range evaluations {
+		k := key{e.View, e.Algorithm}
+		c, ok := comparisons[k]
+		if !ok {
+			c = &apitype.ShadowComparison{View: e.View, Release: e.Release, Algorithm: e.Algorithm}
+			comparisons[k] = c
+		}
+		c.Evaluations++
+		c.TestsEvaluated += e.TestsEvaluated
+		c.BothRegressed += e.BothRegressed
+		c.CurrentOnly += e.CurrentOnly
+		c.CandidateOnly += e.CandidateOnly
+		c.LatestGeneratedAt = e.GeneratedAt
+		latest[k] = e.ID
+	}
+
+	results := make([]apitype.ShadowComparison, 0, len(comparisons))
+	for k, c := range comparisons {
+		if c.TestsEvaluated > 0 {
+			c.AgreementPercentage = float64(c.TestsEvaluated-c.CurrentOnly-c.CandidateOnly) / float64(c.TestsEvaluated) * 100
+		}
+		if flagged := c.BothRegressed + c.CurrentOnly + c.CandidateOnly; flagged > 0 {
+			c.RegressionAgreementPercentage = float64(c.BothRegressed) / float64(flagged) * 100
+		}
+
+		var verdicts []models.ShadowVerdict
+		res := dbc.DB.Where("shadow_evaluation_id = ? AND current_regressed != candidate_regressed", latest[k]).
+			Order("test_id").Find(&verdicts)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		c.CurrentOnlyTests = []models.ShadowVerdict{}
+		c.CandidateOnlyTests = []models.ShadowVerdict{}
+		for _, v := range verdicts {
+			if v.CurrentRegressed {
+				c.CurrentOnlyTests = append(c.CurrentOnlyTests, v)
+			} else {
+				c.CandidateOnlyTests = append(c.CandidateOnlyTests, v)
+			}
+		}
+		results = append(results, *c)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].View != results[j].View {
+			return results[i].View < results[j].View
+		}
+		return results[i].Algorithm < results[j].Algorithm
+	})
+	return results, nil
+}
+
+// variantStrings returns a column's variants in the form Name:value, sorted by name.
+func variantStrings(variants map[string]string) []string {
+	result := make([]string, 0, len(variants))
+	for key, value := range variants {
+		result = append(result, key+":"+value)
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"time"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// Human-entered records, like incidents and basis pins, are soft deleted with who deleted them so accidental
+// deletions can be restored. Deleted records are left out of queries unless they're listed explicitly.
+
+// softDelete marks the record of the model with the given id deleted by deletedBy, returning false if there's no
+// such record that isn't already deleted.
+func softDelete(dbc *db.DB, model interface{}, id uint, deletedBy string) (bool, error) {
+	res := dbc.DB.Model(model).Where("id = ?", id).Updates(map[string]interface{}{
+		"deleted_at": time.Now(),
+		"deleted_by": deletedBy,
+	})
+	return res.RowsAffected > 0, res.Error
+}
+
+// restoreDeleted undeletes the record of the model with the given id, returning false if there's no such deleted
+// record.
+func restoreDeleted(dbc *db.DB, model interface{}, id uint) (bool, error) {
+	res := dbc.DB.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"deleted_by": "",
+	})
+	return res.RowsAffected > 0, res.Error
+}
+
+// listDeleted finds the deleted records of a model, most recently deleted first.
+func listDeleted(dbc *db.DB, records interface{}) error {
+	return dbc.DB.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(records).Error
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/stats"
+)
+
+const (
+	StreamNightly = "nightly"
+	StreamCI      = "ci"
+
+	// DefaultStreamMinRuns is the fewest runs a job or test needs in each stream to be compared.
+	DefaultStreamMinRuns = 10
+
+	// streamSignificance is the p-value below which a difference between the streams is significant.
+	streamSignificance = 0.05
+)
+
+var streamJobRegex = regexp.MustCompile(`-master-(ci|nightly)-`)
+
+// streamPair returns the name shared by a job and its equivalent in the other stream, i.e.
+// periodic-ci-openshift-release-master-4.16-e2e-aws for periodic-ci-openshift-release-master-nightly-4.16-e2e-aws.
+func streamPair(job string) string {
+	return streamJobRegex.ReplaceAllString(job, "-master-")
+}
+
+// GetStreamComparison compares a release's nightly and ci payload streams. Jobs are compared with their equivalent
+// in the other stream, tests across the jobs present in both streams; only tests that differ significantly are
+// returned.
+func GetStreamComparison(dbc *db.DB, release string, start, end time.Time, minRuns int) (*apitype.StreamComparison, error) {
+	jobs, err := query.StreamJobRuns(dbc, release, start, end)
+	if err != nil {
+		return nil, err
+	}
+	tests, err := query.StreamTestRuns(dbc, release, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildStreamComparison(jobs, tests, minRuns)
+	result.Release = release
+	result.Start = start
+	result.End = end
+	return result, nil
+}
+
+func buildStreamComparison(jobs, tests []query.StreamRuns, minRuns int) *apitype.StreamComparison {
+	result := &apitype.StreamComparison{
+		Jobs:  make([]apitype.StreamJobComparison, 0),
+		Tests: make([]apitype.StreamTestComparison, 0),
+	}
+
+	pairs := map[string]map[string]query.StreamRuns{}
+	for _, j := range jobs {
+		pair := streamPair(j.Name)
+		if pairs[pair] == nil {
+			pairs[pair] = map[string]query.StreamRuns{}
+		}
+		pairs[pair][j.Stream] = j
+	}
+	for pair, streams := range pairs {
+		nightly, ok := streams[StreamNightly]
+		if !ok {
+			continue
+		}
+		ci, ok := streams[StreamCI]
+		if !ok || nightly.Runs < minRuns || ci.Runs < minRuns {
+			continue
+		}
+		difference, significant := compareStreams(nightly, ci)
+		result.Jobs = append(result.Jobs, apitype.StreamJobComparison{
+			Name:        pair,
+			NightlyJob:  nightly.Name,
+			CIJob:       ci.Name,
+			Nightly:     streamPassRate(nightly),
+			CI:          streamPassRate(ci),
+			Difference:  difference,
+			Significant: significant,
+		})
+	}
+
+	byTest := map[string]map[string]query.StreamRuns{}
+	for _, t := range tests {
+		if byTest[t.Name] == nil {
+			byTest[t.Name] = map[string]query.StreamRuns{}
+		}
+		byTest[t.Name][t.Stream] = t
+	}
+	for name, streams := range byTest {
+		nightly, ci := streams[StreamNightly], streams[StreamCI]
+		if nightly.Runs < minRuns || ci.Runs < minRuns {
+			continue
+		}
+		difference, significant := compareStreams(nightly, ci)
+		if !significant {
+			continue
+		}
+		result.Tests = append(result.Tests, apitype.StreamTestComparison{
+			TestName:    name,
+			Nightly:     streamPassRate(nightly),
+			CI:          streamPassRate(ci),
+			Difference:  difference,
+			Significant: significant,
+		})
+	}
+
+	// Significant differences first, then the largest.
+	sort.Slice(result.Jobs, func(i, j int) bool {
+		a, b := result.Jobs[i], result.Jobs[j]
+		if a.Significant != b.Significant {
+			return a.Significant
+		}
+		if math.Abs(a.Difference) != math.Abs(b.Difference) {
+			return math.Abs(a.Difference) > math.Abs(b.Difference)
+		}
+		return a.Name < b.Name
+	})
+	sort.Slice(result.Tests, func(i, j int) bool {
+		a, b := result.Tests[i], result.Tests[j]
+		if math.Abs(a.Difference) != math.Abs(b.Difference) {
+			return math.Abs(a.Difference) > math.Abs(b.Difference)
+		}
+		return a.TestName < b.TestName
+	})
+	return result
+}
+
+// compareStreams returns the nightly pass percentage minus the ci pass percentage, and whether Fisher's exact test
+// finds the difference significant.
+func compareStreams(nightly, ci query.StreamRuns) (float64, bool) {
+	difference := streamPassRate(nightly).PassPercentage - streamPassRate(ci).PassPercentage
+	p := stats.FisherExact(
+		stats.Counts{Successes: nightly.Successes, Failures: nightly.Runs - nightly.Successes},
+		stats.Counts{Successes: ci.Successes, Failures: ci.Runs - ci.Successes},
+	).TwoSided
+	return difference, p < streamSignificance
+}
+
+func streamPassRate(r query.StreamRuns) apitype.StreamPassRate {
+	rate := apitype.StreamPassRate{
+		Runs:             r.Runs,
+		Successes:        r.Successes,
+		PassPercentageCI: apitype.NewConfidenceInterval(r.Successes, r.Runs),
+	}
+	if r.Runs > 0 {
+		rate.PassPercentage = float64(r.Successes) * 100.0 / float64(r.Runs)
+	}
+	return rate
+}
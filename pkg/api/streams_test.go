@@ -0,0 +1,73 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestStreamPair(t *testing.T) {
+	assert.Equal(t, "periodic-ci-openshift-release-master-4.16-e2e-aws",
+		streamPair("periodic-ci-openshift-release-master-nightly-4.16-e2e-aws"))
+	assert.Equal(t, "periodic-ci-openshift-release-master-4.16-e2e-aws",
+		streamPair("periodic-ci-openshift-release-master-ci-4.16-e2e-aws"))
+	assert.Equal(t, "periodic-ci-openshift-multiarch-master-4.16-ocp-e2e-aws-arm64",
+		streamPair("periodic-ci-openshift-multiarch-master-nightly-4.16-ocp-e2e-aws-arm64"))
+}
+
+func TestBuildStreamComparison(t *testing.T) {
+	jobs := []query.StreamRuns{
+		{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws", Stream: StreamNightly, Runs: 100, Successes: 95},
+		{Name: "periodic-ci-openshift-release-master-ci-4.16-e2e-aws", Stream: StreamCI, Runs: 100, Successes: 60},
+		{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-gcp", Stream: StreamNightly, Runs: 50, Successes: 45},
+		{Name: "periodic-ci-openshift-release-master-ci-4.16-e2e-gcp", Stream: StreamCI, Runs: 50, Successes: 44},
+		// Only in one stream
+		{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-metal", Stream: StreamNightly, Runs: 50, Successes: 10},
+		// Too few runs
+		{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-azure", Stream: StreamNightly, Runs: 5, Successes: 5},
+		{Name: "periodic-ci-openshift-release-master-ci-4.16-e2e-azure", Stream: StreamCI, Runs: 50, Successes: 0},
+	}
+	tests := []query.StreamRuns{
+		{Name: "test a", Stream: StreamNightly, Runs: 200, Successes: 120},
+		{Name: "test a", Stream: StreamCI, Runs: 200, Successes: 198},
+		{Name: "test b", Stream: StreamNightly, Runs: 200, Successes: 199},
+		{Name: "test b", Stream: StreamCI, Runs: 200, Successes: 198},
+		{Name: "test c", Stream: StreamNightly, Runs: 200, Successes: 200},
+	}
+
+	result := buildStreamComparison(jobs, tests, DefaultStreamMinRuns)
+
+	require.Len(t, result.Jobs, 2)
+	assert.Equal(t, "periodic-ci-openshift-release-master-4.16-e2e-aws", result.Jobs[0].Name)
+	assert.Equal(t, "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws", result.Jobs[0].NightlyJob)
+	assert.Equal(t, "periodic-ci-openshift-release-master-ci-4.16-e2e-aws", result.Jobs[0].CIJob)
+	assert.InDelta(t, 35.0, result.Jobs[0].Difference, 0.001)
+	assert.True(t, result.Jobs[0].Significant)
+	assert.Equal(t, "periodic-ci-openshift-release-master-4.16-e2e-gcp", result.Jobs[1].Name)
+	assert.False(t, result.Jobs[1].Significant)
+
+	require.Len(t, result.Tests, 1, "only significantly different tests in both streams should be returned")
+	assert.Equal(t, "test a", result.Tests[0].TestName)
+	assert.InDelta(t, -39.0, result.Tests[0].Difference, 0.001)
+	assert.Equal(t, 200, result.Tests[0].CI.Runs)
+}
+
+func TestStreamPassRateConfidenceInterval(t *testing.T) {
+	few := streamPassRate(query.StreamRuns{Runs: 3, Successes: 3})
+	many := streamPassRate(query.StreamRuns{Runs: 300, Successes: 300})
+
+	assert.Equal(t, few.PassPercentage, many.PassPercentage)
+	require.NotNil(t, few.PassPercentageCI)
+	require.NotNil(t, many.PassPercentageCI)
+	assert.Equal(t, 3, few.PassPercentageCI.Runs)
+	assert.InDelta(t, 43.85, few.PassPercentageCI.Lower, 0.01)
+	assert.InDelta(t, 98.74, many.PassPercentageCI.Lower, 0.01)
+	assert.InDelta(t, 100.0, many.PassPercentageCI.Upper, 0.001)
+
+	none := streamPassRate(query.StreamRuns{})
+	assert.Equal(t, 0.0, none.PassPercentageCI.Lower)
+	assert.Equal(t, 100.0, none.PassPercentageCI.Upper)
+}
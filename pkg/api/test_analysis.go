@@ -89,7 +89,7 @@ func GetTestAnalysisByJobFromDB(dbc *db.DB, filters *filter.Filter, release, tes
 		Select(`test_id,
 			test_name,
 			to_date((date at time zone 'UTC')::text, 'YYYY-MM-DD'::text)::text as date,
-			prow_jobs.release,
+			prow_test_analysis_by_job_14d_matview.release,
 			job_name as group,
 			runs,
 			passes,
@@ -100,7 +100,7 @@ func GetTestAnalysisByJobFromDB(dbc *db.DB, filters *filter.Filter, release, tes
 			flakes * 100.0 / NULLIF(runs, 0) AS flake_percentage,
 			failures * 100.0 / NULLIF(runs, 0) AS fail_percentage`).
 		Joins("INNER JOIN prow_jobs on prow_jobs.name = job_name").
-		Where("prow_jobs.release = ?", release).
+		Where("prow_test_analysis_by_job_14d_matview.release = ?", release).
 		Where("test_name = ?", testName).
 		Where("date <= ?", reportEnd).
 		Order("date ASC")
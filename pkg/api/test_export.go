@@ -0,0 +1,239 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// TestExportPolarion is JUnit XML with the properties Polarion's XUnit importer reads, which TestRail's CLI
+	// also imports.
+	TestExportPolarion = "polarion"
+	// TestExportTestRail is a CSV of results for TestRail's CSV import.
+	TestExportTestRail = "testrail"
+
+	// DefaultExportMinPassPercentage is the pass percentage, counting flakes as passes, a test and variant
+	// combination needs to be exported as passed.
+	DefaultExportMinPassPercentage = 95.0
+
+	exportStatusPassed   = "Passed"
+	exportStatusFailed   = "Failed"
+	exportStatusUntested = "Untested"
+)
+
+// TestExportOptions selects the tests exported and how they're marked.
+type TestExportOptions struct {
+	Release string
+	// TestRegex is matched case-insensitively against test names, and is required so exports stay bounded.
+	TestRegex string
+	// Variants the jobs must all have, in the form Name:value.
+	Variants          []string
+	Start             time.Time
+	End               time.Time
+	Format            string
+	MinPassPercentage float64
+	// ProjectID is the Polarion project results are imported into, optional.
+	ProjectID string
+}
+
+// ValidateTestExportOptions checks the options for exporting test evidence.
+func ValidateTestExportOptions(opts TestExportOptions) error {
+	if opts.TestRegex == "" {
+		return fmt.Errorf("test is required")
+	}
+	if _, err := regexp.Compile(opts.TestRegex); err != nil {
+		return fmt.Errorf("invalid test regex: %w", err)
+	}
+	for _, v := range opts.Variants {
+		if !strings.Contains(v, ":") {
+			return fmt.Errorf("invalid variant %q: must be in the form Name:value", v)
+		}
+	}
+	switch opts.Format {
+	case TestExportPolarion, TestExportTestRail:
+	default:
+		return fmt.Errorf("invalid format %q: must be %s or %s", opts.Format, TestExportPolarion, TestExportTestRail)
+	}
+	if opts.MinPassPercentage < 0 || opts.MinPassPercentage > 100 {
+		return fmt.Errorf("min_pass_percentage must be between 0 and 100")
+	}
+	if !opts.Start.Before(opts.End) {
+		return fmt.Errorf("start must be before end")
+	}
+	return nil
+}
+
+// TestExportContentType returns the content type and file extension of an export format.
+func TestExportContentType(format string) (string, string) {
+	if format == TestExportTestRail {
+		return "text/csv", "csv"
+	}
+	return "application/xml", "xml"
+}
+
+// ExportTestEvidence writes pass/fail evidence for each matching test and variant combination in the requested
+// format, for attaching CI results to test plans in test management systems.
+func ExportTestEvidence(dbc *db.DB, opts TestExportOptions, w io.Writer) error {
+	evidence, err := query.TestEvidenceByVariants(dbc, opts.Release, opts.TestRegex, opts.Variants, opts.Start, opts.End)
+	if err != nil {
+		return err
+	}
+	if opts.Format == TestExportTestRail {
+		return writeTestRailCSV(w, opts, evidence)
+	}
+	return writePolarionXML(w, opts, evidence)
+}
+
+// exportStatus returns whether a test and variant combination passed, counting flakes as passes.
+func exportStatus(e query.TestEvidence, minPassPercentage float64) (string, float64) {
+	if e.Runs == 0 {
+		return exportStatusUntested, 0
+	}
+	passPercentage := float64(e.Passes+e.Flakes) * 100 / float64(e.Runs)
+	if passPercentage >= minPassPercentage {
+		return exportStatusPassed, passPercentage
+	}
+	return exportStatusFailed, passPercentage
+}
+
+// exportTitle names a test case after the test and the variants it ran with, so each combination is a separate case.
+func exportTitle(e query.TestEvidence) string {
+	return fmt.Sprintf("%s [%s]", e.TestName, strings.Join(e.Variants, " "))
+}
+
+func exportComment(e query.TestEvidence, passPercentage float64, opts TestExportOptions) string {
+	comment := fmt.Sprintf("%d runs between %s and %s: %d passed, %d flaked, %d failed (%.2f%% pass, %.2f%% required). Last run %s",
+		e.Runs, opts.Start.UTC().Format(time.RFC3339), opts.End.UTC().Format(time.RFC3339), e.Passes, e.Flakes, e.Failures,
+		passPercentage, opts.MinPassPercentage, e.LastRunURL)
+	if e.LastFailureURL != "" {
+		comment += ", last failure " + e.LastFailureURL
+	}
+	return comment
+}
+
+type polarionProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type polarionFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type polarionTestCase struct {
+	Name       string             `xml:"name,attr"`
+	Classname  string             `xml:"classname,attr"`
+	Properties []polarionProperty `xml:"properties>property"`
+	Failure    *polarionFailure   `xml:"failure"`
+	Skipped    *struct{}          `xml:"skipped"`
+	SystemOut  string             `xml:"system-out"`
+}
+
+type polarionTestSuite struct {
+	Name      string             `xml:"name,attr"`
+	Tests     int                `xml:"tests,attr"`
+	Failures  int                `xml:"failures,attr"`
+	Skipped   int                `xml:"skipped,attr"`
+	TestCases []polarionTestCase `xml:"testcase"`
+}
+
+type polarionTestSuites struct {
+	XMLName    xml.Name            `xml:"testsuites"`
+	Properties []polarionProperty  `xml:"properties>property"`
+	Suites     []polarionTestSuite `xml:"testsuite"`
+}
+
+func writePolarionXML(w io.Writer, opts TestExportOptions, evidence []query.TestEvidence) error {
+	suites := polarionTestSuites{
+		Properties: []polarionProperty{
+			{Name: "polarion-testrun-title", Value: fmt.Sprintf("Sippy %s evidence %s to %s", opts.Release,
+				opts.Start.UTC().Format("2006-01-02"), opts.End.UTC().Format("2006-01-02"))},
+			{Name: "polarion-lookup-method", Value: "name"},
+		},
+	}
+	if opts.ProjectID != "" {
+		suites.Properties = append(suites.Properties, polarionProperty{Name: "polarion-project-id", Value: opts.ProjectID})
+	}
+
+	// One suite per junit suite, in the order the evidence is sorted
+	suiteIndex := map[string]int{}
+	for _, e := range evidence {
+		i, ok := suiteIndex[e.SuiteName]
+		if !ok {
+			i = len(suites.Suites)
+			suiteIndex[e.SuiteName] = i
+			suites.Suites = append(suites.Suites, polarionTestSuite{Name: e.SuiteName})
+		}
+		suite := &suites.Suites[i]
+
+		status, passPercentage := exportStatus(e, opts.MinPassPercentage)
+		comment := exportComment(e, passPercentage, opts)
+		tc := polarionTestCase{
+			Name:      exportTitle(e),
+			Classname: e.SuiteName,
+			Properties: []polarionProperty{
+				{Name: "sippy-release", Value: opts.Release},
+				{Name: "sippy-variants", Value: strings.Join(e.Variants, ",")},
+				{Name: "sippy-runs", Value: strconv.Itoa(e.Runs)},
+				{Name: "sippy-pass-percentage", Value: strconv.FormatFloat(passPercentage, 'f', 2, 64)},
+				{Name: "sippy-last-run-url", Value: e.LastRunURL},
+			},
+			SystemOut: comment,
+		}
+		suite.Tests++
+		switch status {
+		case exportStatusFailed:
+			tc.Failure = &polarionFailure{Message: comment}
+			suite.Failures++
+		case exportStatusUntested:
+			tc.Skipped = &struct{}{}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(suites)
+}
+
+func writeTestRailCSV(w io.Writer, opts TestExportOptions, evidence []query.TestEvidence) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"Title", "Section", "Variants", "Status", "Runs", "Passes", "Flakes", "Failures",
+		"Pass Percentage", "Evidence", "Comment"}); err != nil {
+		return err
+	}
+	for _, e := range evidence {
+		status, passPercentage := exportStatus(e, opts.MinPassPercentage)
+		err := cw.Write([]string{
+			exportTitle(e),
+			e.SuiteName,
+			strings.Join(e.Variants, " "),
+			status,
+			strconv.Itoa(e.Runs),
+			strconv.Itoa(e.Passes),
+			strconv.Itoa(e.Flakes),
+			strconv.Itoa(e.Failures),
+			strconv.FormatFloat(passPercentage, 'f', 2, 64),
+			e.LastRunURL,
+			exportComment(e, passPercentage, opts),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+var exportEvidence = []query.TestEvidence{
+	{
+		TestName:   "[sig-network] pods should connect",
+		SuiteName:  "openshift-tests",
+		Variants:   pq.StringArray{"Platform:aws", "Upgrade:none"},
+		Runs:       100,
+		Passes:     94,
+		Flakes:     2,
+		Failures:   4,
+		LastRunURL: "https://prow.ci.openshift.org/view/gs/job/2",
+	},
+	{
+		TestName:       "[sig-network] pods should connect",
+		SuiteName:      "openshift-tests",
+		Variants:       pq.StringArray{"Platform:gcp", "Upgrade:none"},
+		Runs:           20,
+		Passes:         15,
+		Failures:       5,
+		LastRunURL:     "https://prow.ci.openshift.org/view/gs/job/4",
+		LastFailureURL: "https://prow.ci.openshift.org/view/gs/job/3",
+	},
+}
+
+func exportOptions(format string) TestExportOptions {
+	return TestExportOptions{
+		Release:           "4.16",
+		TestRegex:         "sig-network",
+		Start:             time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		End:               time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+		Format:            format,
+		MinPassPercentage: DefaultExportMinPassPercentage,
+		ProjectID:         "OSE",
+	}
+}
+
+func TestValidateTestExportOptions(t *testing.T) {
+	assert.NoError(t, ValidateTestExportOptions(exportOptions(TestExportPolarion)))
+
+	opts := exportOptions(TestExportTestRail)
+	opts.TestRegex = ""
+	assert.Error(t, ValidateTestExportOptions(opts))
+
+	opts = exportOptions("junit")
+	assert.Error(t, ValidateTestExportOptions(opts))
+
+	opts = exportOptions(TestExportPolarion)
+	opts.Variants = []string{"aws"}
+	assert.Error(t, ValidateTestExportOptions(opts))
+
+	opts = exportOptions(TestExportPolarion)
+	opts.MinPassPercentage = 101
+	assert.Error(t, ValidateTestExportOptions(opts))
+}
+
+func TestWritePolarionXML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writePolarionXML(&buf, exportOptions(TestExportPolarion), exportEvidence))
+
+	var suites polarionTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+	assert.Contains(t, suites.Properties, polarionProperty{Name: "polarion-project-id", Value: "OSE"})
+	require.Len(t, suites.Suites, 1)
+	suite := suites.Suites[0]
+	assert.Equal(t, 2, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	require.Len(t, suite.TestCases, 2)
+
+	// Flakes count as passes
+	assert.Equal(t, "[sig-network] pods should connect [Platform:aws Upgrade:none]", suite.TestCases[0].Name)
+	assert.Nil(t, suite.TestCases[0].Failure)
+	require.NotNil(t, suite.TestCases[1].Failure)
+	assert.Contains(t, suite.TestCases[1].Failure.Message, "75.00% pass")
+	assert.Contains(t, suite.TestCases[1].Failure.Message, "last failure https://prow.ci.openshift.org/view/gs/job/3")
+}
+
+func TestWriteTestRailCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeTestRailCSV(&buf, exportOptions(TestExportTestRail), exportEvidence))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "Title", records[0][0])
+	assert.Equal(t, []string{"Platform:aws Upgrade:none", exportStatusPassed, "100", "94", "2", "4", "96.00"}, records[1][2:9])
+	assert.Equal(t, []string{"Platform:gcp Upgrade:none", exportStatusFailed, "20", "15", "0", "5", "75.00"}, records[2][2:9])
+}
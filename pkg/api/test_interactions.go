@@ -0,0 +1,58 @@
+package api
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// minTestCoFailures is the minimum number of runs two tests must fail together in to be considered.
+	minTestCoFailures = 5
+	// maxRunTestFailures excludes runs with more failures than this, which are usually mass failures.
+	maxRunTestFailures = 20
+	// minTestInteractionLift and minTestInteractionCorrelation determine how strongly failures must be related
+	// to be reported.
+	minTestInteractionLift        = 2.0
+	minTestInteractionCorrelation = 0.3
+)
+
+// GetTestInteractions reports pairs of tests whose failures are strongly correlated within runs, most correlated
+// first.
+func GetTestInteractions(dbc *db.DB, release, variant string, start, end time.Time, mode apitype.PassRateMode) ([]apitype.TestInteraction, error) {
+	pairs, err := query.TestCoFailures(dbc, release, variant, start, end, minTestCoFailures, maxRunTestFailures, mode)
+	if err != nil {
+		return nil, err
+	}
+	return scoreTestInteractions(pairs), nil
+}
+
+// scoreTestInteractions calculates the lift and phi coefficient of each pair, and returns only those correlated
+// beyond chance.
+func scoreTestInteractions(pairs []apitype.TestInteraction) []apitype.TestInteraction {
+	results := make([]apitype.TestInteraction, 0)
+	for _, p := range pairs {
+		n, a, b, ab := float64(p.Runs), float64(p.FailuresA), float64(p.FailuresB), float64(p.CoFailures)
+		if n == 0 || a == 0 || b == 0 {
+			continue
+		}
+
+		p.Lift = ab * n / (a * b)
+		if denominator := math.Sqrt(a * b * (n - a) * (n - b)); denominator > 0 {
+			p.Correlation = (n*ab - a*b) / denominator
+		}
+		if p.Lift < minTestInteractionLift || p.Correlation < minTestInteractionCorrelation {
+			continue
+		}
+		results = append(results, p)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Correlation > results[j].Correlation
+	})
+	return results
+}
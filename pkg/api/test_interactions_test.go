@@ -0,0 +1,44 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestScoreTestInteractions(t *testing.T) {
+	pairs := []apitype.TestInteraction{
+		{
+			// Always fail together
+			TestA: "a", TestB: "b",
+			CoFailures: 10, FailuresA: 10, FailuresB: 10, Runs: 1000,
+		},
+		{
+			// Frequently failing tests that overlap about as often as chance predicts
+			TestA: "c", TestB: "d",
+			CoFailures: 25, FailuresA: 500, FailuresB: 50, Runs: 1000,
+		},
+		{
+			// Mostly together
+			TestA: "e", TestB: "f",
+			CoFailures: 8, FailuresA: 10, FailuresB: 20, Runs: 1000,
+		},
+		{
+			TestA: "g", TestB: "h",
+			CoFailures: 5, FailuresA: 5, FailuresB: 5, Runs: 0,
+		},
+	}
+
+	results := scoreTestInteractions(pairs)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "a", results[0].TestA)
+		assert.InDelta(t, 100, results[0].Lift, 0.001)
+		assert.InDelta(t, 1, results[0].Correlation, 0.001)
+
+		assert.Equal(t, "e", results[1].TestA)
+		assert.InDelta(t, 40, results[1].Lift, 0.001)
+		assert.Greater(t, results[1].Correlation, 0.5)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+const (
+	// DefaultTestRecentRuns is how many of a test's runs are returned unless limit is given.
+	DefaultTestRecentRuns = 10
+	// MaxTestRecentRuns bounds limit, larger histories should use the tests report or time series.
+	MaxTestRecentRuns = 100
+	// testRecentRunsLookback is how far back runs are looked for, so the query only reads recent results.
+	testRecentRunsLookback = 14 * 24 * time.Hour
+
+	testRunPass  = "pass"
+	testRunFlake = "flake"
+	testRunFail  = "fail"
+)
+
+// ValidateTestRecentRunsOptions checks the parameters of a recent runs request.
+func ValidateTestRecentRunsOptions(test string, variants []string, limit int) error {
+	if test == "" {
+		return fmt.Errorf("test is required")
+	}
+	for _, v := range variants {
+		if !strings.Contains(v, ":") {
+			return fmt.Errorf("invalid variant %q: must be in the form Name:value", v)
+		}
+	}
+	if limit < 1 || limit > MaxTestRecentRuns {
+		return fmt.Errorf("limit must be between 1 and %d", MaxTestRecentRuns)
+	}
+	return nil
+}
+
+// GetTestRecentRuns returns a test's results in its most recent runs, up to limit, in jobs with all the given
+// variants. Only runs in the two weeks before reportEnd are considered.
+func GetTestRecentRuns(dbc *db.DB, release, test string, variants []string, limit int, reportEnd time.Time) (apitype.TestRecentRuns, error) {
+	runs, err := query.TestRecentRuns(dbc, release, test, variants, reportEnd.Add(-testRecentRunsLookback), limit)
+	if err != nil {
+		return apitype.TestRecentRuns{}, err
+	}
+	return buildTestRecentRuns(release, test, variants, runs), nil
+}
+
+func buildTestRecentRuns(release, test string, variants []string, runs []query.TestRun) apitype.TestRecentRuns {
+	result := apitype.TestRecentRuns{
+		Release:  release,
+		TestName: test,
+		Variants: variants,
+		Runs:     make([]apitype.TestRecentRun, 0, len(runs)),
+	}
+	for _, r := range runs {
+		run := apitype.TestRecentRun{
+			ProwJobRunID: r.ProwJobRunID,
+			JobName:      r.JobName,
+			Timestamp:    r.Timestamp,
+		}
+		run.URL, run.ArtifactsURL = artifactURLs(r.URL)
+		switch v1.TestStatus(r.Status) {
+		case v1.TestStatusSuccess:
+			run.Status = testRunPass
+			result.Passes++
+		case v1.TestStatusFlake:
+			run.Status = testRunFlake
+			result.Flakes++
+		default:
+			run.Status = testRunFail
+			result.Failures++
+			if result.LastFailure == nil || r.Timestamp.After(*result.LastFailure) {
+				ts := r.Timestamp
+				result.LastFailure = &ts
+			}
+		}
+		result.Runs = append(result.Runs, run)
+	}
+	return result
+}
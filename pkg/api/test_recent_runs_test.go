@@ -0,0 +1,45 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestValidateTestRecentRunsOptions(t *testing.T) {
+	assert.NoError(t, ValidateTestRecentRunsOptions("some test", []string{"Platform:aws"}, DefaultTestRecentRuns))
+	assert.Error(t, ValidateTestRecentRunsOptions("", nil, DefaultTestRecentRuns))
+	assert.Error(t, ValidateTestRecentRunsOptions("some test", []string{"aws"}, DefaultTestRecentRuns))
+	assert.Error(t, ValidateTestRecentRunsOptions("some test", nil, 0))
+	assert.Error(t, ValidateTestRecentRunsOptions("some test", nil, MaxTestRecentRuns+1))
+}
+
+func TestBuildTestRecentRuns(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	runs := []query.TestRun{
+		{ProwJobRunID: 4, JobName: "job-a", URL: "https://prow/4", Timestamp: now, Status: int(v1.TestStatusSuccess)},
+		{ProwJobRunID: 3, JobName: "job-b", URL: "https://prow/3", Timestamp: now.Add(-time.Hour), Status: int(v1.TestStatusFailure)},
+		{ProwJobRunID: 2, JobName: "job-a", URL: "https://prow/2", Timestamp: now.Add(-2 * time.Hour), Status: int(v1.TestStatusFlake)},
+		{ProwJobRunID: 1, JobName: "job-b", URL: "https://prow/1", Timestamp: now.Add(-3 * time.Hour), Status: int(v1.TestStatusFailure)},
+	}
+
+	result := buildTestRecentRuns("4.16", "some test", []string{"Platform:aws"}, runs)
+	assert.Equal(t, 1, result.Passes)
+	assert.Equal(t, 1, result.Flakes)
+	assert.Equal(t, 2, result.Failures)
+	require.NotNil(t, result.LastFailure)
+	assert.Equal(t, now.Add(-time.Hour), *result.LastFailure)
+	require.Len(t, result.Runs, 4)
+	assert.Equal(t, []string{"pass", "fail", "flake", "fail"},
+		[]string{result.Runs[0].Status, result.Runs[1].Status, result.Runs[2].Status, result.Runs[3].Status})
+	assert.Equal(t, "https://prow/4", result.Runs[0].URL)
+
+	empty := buildTestRecentRuns("4.16", "some test", nil, nil)
+	assert.Nil(t, empty.LastFailure)
+	assert.NotNil(t, empty.Runs, "no runs should encode as an empty list")
+}
@@ -1,11 +1,9 @@
 package api
 
 import (
-	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
-	gosort "sort"
 	"strconv"
 	"time"
 
@@ -16,6 +14,7 @@ import (
 	"github.com/openshift/sippy/pkg/db/query"
 	"github.com/openshift/sippy/pkg/filter"
 	"github.com/openshift/sippy/pkg/html/installhtml"
+	"github.com/openshift/sippy/pkg/util/sets"
 )
 
 const (
@@ -69,40 +68,25 @@ func GetTestDurationsFromDB(dbc *db.DB, release, test string, filters *filter.Fi
 
 type testsAPIResult []apitype.Test
 
-func (tests testsAPIResult) sort(req *http.Request) testsAPIResult {
-	sortField := req.URL.Query().Get("sortField")
-	sort := req.URL.Query().Get("sort")
+// testVariantFields are the fields of apitype.Test only in uncollapsed tests reports, which are per variant combination.
+var testVariantFields = sets.NewString("suite_name", "variants",
+	"delta_from_working_average", "working_average", "working_standard_deviation",
+	"delta_from_passing_average", "passing_average", "passing_standard_deviation",
+	"delta_from_flake_average", "flake_average", "flake_standard_deviation")
 
-	if sortField == "" {
-		sortField = "current_pass_percentage"
-	}
+// testComputedFields are the fields of apitype.Test that are not columns of the tests report query, so it can't be
+// sorted by them.
+var testComputedFields = sets.NewString("variant", "tags", "current_pass_percentage_ci", "previous_pass_percentage_ci")
 
-	if sort == "" {
-		sort = "asc"
+// validateTestsSort ensures a tests report can be sorted by a field, which must be one of its query's columns.
+func validateTestsSort(sortField string, collapse bool) error {
+	if testComputedFields.Has(sortField) || (collapse && testVariantFields.Has(sortField)) {
+		return fmt.Errorf("tests can't be sorted by %q", sortField)
 	}
-
-	gosort.Slice(tests, func(i, j int) bool {
-		if sort == "asc" {
-			return filter.Compare(tests[i], tests[j], sortField)
-		}
-		return filter.Compare(tests[j], tests[i], sortField)
-	})
-
-	return tests
-}
-
-func (tests testsAPIResult) limit(req *http.Request) testsAPIResult {
-	limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
-	if limit == 0 || len(tests) < limit {
-		return tests
-	}
-
-	return tests[:limit]
+	return nil
 }
 
 func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Request, dbc *db.DB) {
-	var fil *filter.Filter
-
 	// Collapse means to produce an aggregated test result of all variant (NURP+ - network, upgrade, release, platform)
 	// combos. Uncollapsed results shows you the per-NURP+ result for each test (currently approx. 50,000 rows: filtering
 	// is advised)
@@ -118,15 +102,6 @@ func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Reque
 		includeOverall, _ = strconv.ParseBool(overallStr)
 	}
 
-	queryFilter := req.URL.Query().Get("filter")
-	if queryFilter != "" {
-		fil = &filter.Filter{}
-		if err := json.Unmarshal([]byte(queryFilter), fil); err != nil {
-			RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
-			return
-		}
-	}
-
 	// If requesting a two day report, we make the comparison between the last
 	// period (typically 7 days) and the last two days.
 	period := req.URL.Query().Get("period")
@@ -135,18 +110,41 @@ func PrintTestsJSONFromDB(release string, w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	testsResult, overall, err := BuildTestsResults(dbc, release, period, collapse, includeOverall, fil)
+	mode, err := apitype.ParsePassRateMode(req.URL.Query().Get("pass_rate"))
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	filterOpts, err := filter.FilterOptionsFromRequest(req, "current_pass_percentage", apitype.SortAscending)
+	if err == nil {
+		err = filterOpts.Validate(apitype.Test{})
+	}
+	if err == nil {
+		err = validateTestsSort(filterOpts.SortField, collapse)
+	}
+	if err != nil {
+		RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
+
+	testsResult, overall, err := BuildTestsResults(dbc, release, period, collapse, includeOverall, mode, filterOpts)
 	if err != nil {
 		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building job report:" + err.Error()})
 		return
 	}
 
-	testsResult = testsResult.sort(req).limit(req)
 	if overall != nil {
 		testsResult = append([]apitype.Test{*overall}, testsResult...)
 	}
 
-	RespondWithJSON(http.StatusOK, w, testsResult)
+	projected, err := filter.ProjectColumns(testsResult, filterOpts.Columns)
+	if err != nil {
+		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building test report:" + err.Error()})
+		return
+	}
+
+	RespondWithJSON(http.StatusOK, w, projected)
 }
 
 func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
@@ -160,7 +158,7 @@ func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
 		},
 	}
 
-	results, _, err := BuildTestsResults(dbc, release, "default", true, false, &f)
+	results, _, err := BuildTestsResults(dbc, release, "default", true, false, apitype.PassRateStrict, &filter.FilterOptions{Filter: &f})
 	if err != nil {
 		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Error building test report:" + err.Error()})
 		return
@@ -172,15 +170,20 @@ func PrintCanaryTestsFromDB(release string, w http.ResponseWriter, dbc *db.DB) {
 	}
 }
 
-func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOverall bool, fil *filter.Filter) (testsAPIResult, *apitype.Test, error) { //lint:ignore
+// BuildTestsResults returns the tests report of a release, filtered, sorted and limited by filterOpts, along with an
+// "overall" test summarizing all the tests matching the filter if includeOverall is set.
+func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOverall bool, mode apitype.PassRateMode, filterOpts *filter.FilterOptions) (testsAPIResult, *apitype.Test, error) { //lint:ignore
 	now := time.Now()
+	if filterOpts == nil {
+		filterOpts = &filter.FilterOptions{}
+	}
 
 	// Test results are generated by using two subqueries, which need to be filtered separately. Once during
 	// pre-processing where we're evaluating summed variant results, and in post-processing after we've
 	// assembled our final temporary table.
 	var rawFilter, processedFilter *filter.Filter
-	if fil != nil {
-		rawFilter, processedFilter = fil.Split([]string{"name", "variants"})
+	if filterOpts.Filter != nil {
+		rawFilter, processedFilter = filterOpts.Filter.Split([]string{"name", "variants"})
 	}
 
 	table := testReport7dMatView
@@ -189,7 +192,7 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 	}
 
 	rawQuery := dbc.DB.
-		Table(table).
+		Table(query.TestReportSource(table, mode)).
 		Where("release = ?", release)
 
 	// Collapse groups the test results together -- otherwise we return the test results per-variant combo (NURP+)
@@ -197,7 +200,7 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 	if collapse {
 		rawQuery = rawQuery.Select(`name,watchlist,jira_component,jira_component_id,` + query.QueryTestSummer).Group("name,watchlist,jira_component,jira_component_id")
 	} else {
-		rawQuery = query.TestsByNURPAndStandardDeviation(dbc, release, table)
+		rawQuery = query.TestsByNURPAndStandardDeviation(dbc, release, table, mode)
 		variantSelect = "suite_name, variants," +
 			"delta_from_working_average, working_average, working_standard_deviation, " +
 			"delta_from_passing_average, passing_average, passing_standard_deviation, " +
@@ -220,9 +223,16 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 		finalResults = processedFilter.ToSQL(finalResults, apitype.Test{})
 	}
 
-	frr := finalResults.Scan(&testReports)
+	// Sorting and limiting apply to the tests returned, and not to the overall test, which summarizes every match.
+	sortedResults, err := filter.FilterableDBResult(dbc.DB.Table("(?) as sorted_results", finalResults),
+		&filter.FilterOptions{Filter: &filter.Filter{}, SortField: filterOpts.SortField, Sort: filterOpts.Sort, Limit: filterOpts.Limit},
+		apitype.Test{})
+	if err != nil {
+		return []apitype.Test{}, nil, err
+	}
+	frr := sortedResults.Scan(&testReports)
 	if frr.Error != nil {
-		log.WithError(finalResults.Error).Error("error querying test reports")
+		log.WithError(frr.Error).Error("error querying test reports")
 		return []apitype.Test{}, nil, frr.Error
 	}
 
@@ -238,6 +248,10 @@ func BuildTestsResults(dbc *db.DB, release, period string, collapse, includeOver
 		}
 		// TODO: column open_bugs does not exist here?
 		summaryResult.Scan(overallTest)
+		overallTest.SetConfidenceIntervals()
+	}
+	for i := range testReports {
+		testReports[i].SetConfidenceIntervals()
 	}
 
 	elapsed := time.Since(now)
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintTestsJSONFromDBInvalidSort(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{name: "unknown field", query: "sort=bogus:asc"},
+		{name: "unknown direction", query: "sortField=name&sort=sideways"},
+		{name: "computed field", query: "sort=current_pass_percentage_ci:desc"},
+		{name: "per-variant field of collapsed tests", query: "sort=working_average:desc"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			PrintTestsJSONFromDB("4.16", w, httptest.NewRequest(http.MethodGet, "/api/tests?"+tc.query, nil), nil)
+			assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+		})
+	}
+}
+
+func TestValidateTestsSort(t *testing.T) {
+	assert.NoError(t, validateTestsSort("current_pass_percentage", true))
+	assert.NoError(t, validateTestsSort("", true))
+	assert.NoError(t, validateTestsSort("suite_name", false), "per-variant fields are columns of uncollapsed reports")
+	assert.Error(t, validateTestsSort("suite_name", true))
+	assert.Error(t, validateTestsSort("tags", false))
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openshift/sippy/pkg/util"
+)
+
+const (
+	// DefaultTimeWindow is how long report windows are unless start or window is given.
+	DefaultTimeWindow = 14 * 24 * time.Hour
+	// MaxTimeWindow bounds report windows so a typo can't scan all history.
+	MaxTimeWindow = 366 * 24 * time.Hour
+
+	// WindowStartHeader, WindowBoundaryHeader and WindowEndHeader echo the window a report covers.
+	WindowStartHeader    = "X-Sippy-Window-Start"
+	WindowBoundaryHeader = "X-Sippy-Window-Boundary"
+	WindowEndHeader      = "X-Sippy-Window-End"
+)
+
+// ParseTimeWindow reads the start and end params, or a relative window ending at end, e.g. 36h, 7d or 2w. Without
+// start or window the window is defaultLength long, and end defaults to the report end.
+func ParseTimeWindow(req *http.Request, reportEnd time.Time, defaultLength time.Duration) (start, end time.Time, err error) {
+	end = reportEnd
+	endp, err := ParseTimeParam("end", req)
+	if err != nil {
+		return start, end, err
+	}
+	if endp != nil {
+		end = *endp
+	}
+
+	startp, err := ParseTimeParam("start", req)
+	if err != nil {
+		return start, end, err
+	}
+	windowParam := req.URL.Query().Get("window")
+	switch {
+	case startp != nil && windowParam != "":
+		return start, end, fmt.Errorf("only one of start and window can be given")
+	case startp != nil:
+		start = *startp
+	case windowParam != "":
+		length, err := parseWindowLength(windowParam)
+		if err != nil {
+			return start, end, err
+		}
+		start = end.Add(-length)
+	default:
+		start = end.Add(-defaultLength)
+	}
+
+	if !start.Before(end) {
+		return start, end, fmt.Errorf("start must be before end")
+	}
+	if end.Sub(start) > MaxTimeWindow {
+		return start, end, fmt.Errorf("window must not be longer than %d days", int(MaxTimeWindow.Hours()/24))
+	}
+	return start, end, nil
+}
+
+// ParsePeriodWindow returns the window of a report comparing the time after boundary with the time before it. The
+// period, i.e. default or twoDay, sets the default window and boundary, which the start, end, window and boundary
+// params override. A boundary that would fall outside a shorter window is moved to the middle of it.
+func ParsePeriodWindow(req *http.Request, period string, reportEnd time.Time) (start, boundary, end time.Time, err error) {
+	periodStart, periodBoundary, periodEnd := util.PeriodToDates(period, reportEnd)
+	start, end, err = ParseTimeWindow(req, reportEnd, periodEnd.Sub(periodStart))
+	if err != nil {
+		return start, boundary, end, err
+	}
+
+	boundary = end.Add(-periodEnd.Sub(periodBoundary))
+	if !boundary.After(start) {
+		boundary = start.Add(end.Sub(start) / 2)
+	}
+	boundaryp, err := ParseTimeParam("boundary", req)
+	if err != nil {
+		return start, boundary, end, err
+	}
+	if boundaryp != nil {
+		if !boundaryp.After(start) || !boundaryp.Before(end) {
+			return start, boundary, end, fmt.Errorf("boundary must be between start and end")
+		}
+		boundary = *boundaryp
+	}
+	return start, boundary, end, nil
+}
+
+// SetTimeWindowHeaders echoes the window a report covers in the response headers. A zero boundary is left out.
+func SetTimeWindowHeaders(w http.ResponseWriter, start, boundary, end time.Time) {
+	w.Header().Set(WindowStartHeader, start.UTC().Format(time.RFC3339))
+	if !boundary.IsZero() {
+		w.Header().Set(WindowBoundaryHeader, boundary.UTC().Format(time.RFC3339))
+	}
+	w.Header().Set(WindowEndHeader, end.UTC().Format(time.RFC3339))
+}
+
+// ParseTimeParam parses a date (YYYY-MM-DD) or RFC3339 time param, returning nil if it wasn't given.
+func ParseTimeParam(paramName string, req *http.Request) (*time.Time, error) {
+	param := req.URL.Query().Get(paramName)
+	if param == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{"2006-01-02", time.RFC3339} {
+		if t, err := time.Parse(layout, param); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid %s %q: must be a date (YYYY-MM-DD) or RFC3339 time", paramName, param)
+}
+
+// parseWindowLength parses a relative window, a Go duration or a number of days or weeks, e.g. 36h, 7d or 2w.
+func parseWindowLength(param string) (time.Duration, error) {
+	length, err := time.ParseDuration(param)
+	if unit := param[len(param)-1:]; unit == "d" || unit == "w" {
+		var n int
+		n, err = strconv.Atoi(param[:len(param)-1])
+		length = time.Duration(n) * 24 * time.Hour
+		if unit == "w" {
+			length *= 7
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid window %q: must be a duration such as 36h, 7d or 2w", param)
+	}
+	if length <= 0 {
+		return 0, fmt.Errorf("window must be positive")
+	}
+	return length, nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTimeWindow(t *testing.T) {
+	reportEnd := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name      string
+		query     string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{name: "default", query: "", wantStart: reportEnd.Add(-14 * day), wantEnd: reportEnd},
+		{name: "dates", query: "start=2024-03-01&end=2024-03-04",
+			wantStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), wantEnd: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{name: "rfc3339", query: "start=2024-03-01T06:00:00Z&end=2024-03-01T18:00:00Z",
+			wantStart: time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC), wantEnd: time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)},
+		{name: "window in days", query: "window=3d&end=2024-03-04",
+			wantStart: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), wantEnd: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)},
+		{name: "window in weeks", query: "window=2w", wantStart: reportEnd.Add(-14 * day), wantEnd: reportEnd},
+		{name: "window in hours", query: "window=36h", wantStart: reportEnd.Add(-36 * time.Hour), wantEnd: reportEnd},
+		{name: "start and window", query: "start=2024-03-01&window=3d", wantErr: true},
+		{name: "start after end", query: "start=2024-03-05&end=2024-03-04", wantErr: true},
+		{name: "invalid start", query: "start=yesterday", wantErr: true},
+		{name: "invalid window", query: "window=3months", wantErr: true},
+		{name: "negative window", query: "window=-3d", wantErr: true},
+		{name: "too long", query: "window=400d", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/test?"+tt.query, nil)
+			start, end, err := ParseTimeWindow(req, reportEnd, DefaultTimeWindow)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestParsePeriodWindow(t *testing.T) {
+	reportEnd := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	req := httptest.NewRequest("GET", "/api/jobs", nil)
+	start, boundary, end, err := ParsePeriodWindow(req, periodTwoDay, reportEnd)
+	require.NoError(t, err)
+	assert.Equal(t, reportEnd.Add(-9*day), start)
+	assert.Equal(t, reportEnd.Add(-2*day), boundary)
+	assert.Equal(t, reportEnd, end)
+
+	// A window shorter than the period's current length moves the boundary to the middle
+	req = httptest.NewRequest("GET", "/api/jobs?window=4d", nil)
+	start, boundary, _, err = ParsePeriodWindow(req, "default", reportEnd)
+	require.NoError(t, err)
+	assert.Equal(t, reportEnd.Add(-4*day), start)
+	assert.Equal(t, reportEnd.Add(-2*day), boundary)
+
+	req = httptest.NewRequest("GET", "/api/jobs?start=2024-05-01&boundary=2024-05-10&end=2024-05-12", nil)
+	_, boundary, _, err = ParsePeriodWindow(req, "default", reportEnd)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), boundary)
+
+	req = httptest.NewRequest("GET", "/api/jobs?start=2024-05-01&boundary=2024-05-13&end=2024-05-12", nil)
+	_, _, _, err = ParsePeriodWindow(req, "default", reportEnd)
+	assert.Error(t, err)
+}
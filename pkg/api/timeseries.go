@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	// Embedded so timezones resolve without the host's zoneinfo, which the server image doesn't have.
+	_ "time/tzdata"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// maxTimeSeriesBuckets guards against requests that would generate an unreasonable number of buckets,
+// i.e. hourly granularity over several months.
+const maxTimeSeriesBuckets = 2000
+
+var granularityDurations = map[apitype.TimeSeriesGranularity]time.Duration{
+	apitype.TimeSeriesHour: time.Hour,
+	apitype.TimeSeriesDay:  24 * time.Hour,
+	apitype.TimeSeriesWeek: 7 * 24 * time.Hour,
+}
+
+// ParseTimezone returns the location daily and weekly buckets start at midnight in, an IANA timezone name such as
+// America/New_York. It defaults to UTC.
+func ParseTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	// Local is the server's timezone, not the viewer's
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("unknown timezone %q: must be an IANA timezone name such as America/New_York", name)
+	}
+	return loc, nil
+}
+
+// GetPassRateTimeSeries returns a gap filled series of pass/fail/flake counts for the selected job, test, or variant,
+// with buckets starting at midnight in loc.
+func GetPassRateTimeSeries(dbc *db.DB, selector apitype.TimeSeriesSelector, granularity apitype.TimeSeriesGranularity, loc *time.Location, start, end time.Time) (*apitype.TimeSeries, error) {
+	if err := ValidateTimeSeriesRequest(selector, granularity, start, end); err != nil {
+		return nil, err
+	}
+
+	buckets, err := query.PassRateTimeSeries(dbc, selector, granularity, loc, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	incidents, err := query.IncidentsOverlapping(dbc, selector.Release, selector.Job, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apitype.TimeSeries{
+		Selector:    selector,
+		Granularity: granularity,
+		Timezone:    loc.String(),
+		Buckets:     buckets,
+		Incidents:   incidents,
+	}, nil
+}
+
+// ValidateTimeSeriesRequest ensures exactly one selector is set and that the requested range and granularity
+// produce a sane number of buckets.
+func ValidateTimeSeriesRequest(selector apitype.TimeSeriesSelector, granularity apitype.TimeSeriesGranularity, start, end time.Time) error {
+	selected := 0
+	for _, s := range []string{selector.Job, selector.Test, selector.Variant} {
+		if s != "" {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return fmt.Errorf("exactly one of job, test, or variant must be specified")
+	}
+
+	d, ok := granularityDurations[granularity]
+	if !ok {
+		return fmt.Errorf("unknown granularity %q: must be hour, day, or week", granularity)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if buckets := int(end.Sub(start) / d); buckets > maxTimeSeriesBuckets {
+		return fmt.Errorf("requested range would produce %d buckets, the maximum is %d", buckets, maxTimeSeriesBuckets)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestValidateTimeSeriesRequest(t *testing.T) {
+	end := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		selector    apitype.TimeSeriesSelector
+		granularity apitype.TimeSeriesGranularity
+		start       time.Time
+		expectErr   bool
+	}{
+		{
+			name:        "job by day",
+			selector:    apitype.TimeSeriesSelector{Job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"},
+			granularity: apitype.TimeSeriesDay,
+			start:       end.Add(-14 * 24 * time.Hour),
+		},
+		{
+			name:        "no selector",
+			granularity: apitype.TimeSeriesDay,
+			start:       end.Add(-14 * 24 * time.Hour),
+			expectErr:   true,
+		},
+		{
+			name:        "multiple selectors",
+			selector:    apitype.TimeSeriesSelector{Job: "job", Variant: "aws"},
+			granularity: apitype.TimeSeriesDay,
+			start:       end.Add(-14 * 24 * time.Hour),
+			expectErr:   true,
+		},
+		{
+			name:        "unknown granularity",
+			selector:    apitype.TimeSeriesSelector{Test: "test"},
+			granularity: "fortnight",
+			start:       end.Add(-14 * 24 * time.Hour),
+			expectErr:   true,
+		},
+		{
+			name:        "too many buckets",
+			selector:    apitype.TimeSeriesSelector{Variant: "aws"},
+			granularity: apitype.TimeSeriesHour,
+			start:       end.Add(-365 * 24 * time.Hour),
+			expectErr:   true,
+		},
+		{
+			name:        "start after end",
+			selector:    apitype.TimeSeriesSelector{Variant: "aws"},
+			granularity: apitype.TimeSeriesWeek,
+			start:       end.Add(24 * time.Hour),
+			expectErr:   true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateTimeSeriesRequest(tc.selector, tc.granularity, tc.start, end)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
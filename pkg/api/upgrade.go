@@ -9,28 +9,15 @@ import (
 	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/testidentification"
-	"github.com/openshift/sippy/pkg/util/sets"
 )
 
 // PrintUpgradeJSONReportFromDB reports on the success/fail of operator upgrades.
 func PrintUpgradeJSONReportFromDB(w http.ResponseWriter, req *http.Request, dbc *db.DB, release string) {
 
-	exactTestNames := sets.NewString(
-		testidentification.UpgradeTestName,
-	)
-	testPrefixes := sets.NewString(
-		testidentification.OperatorUpgradePrefix, // "old" upgrade test
-	)
-	// Some of these are substring matches due to suites being included in the test name but not in sippy code.
-	testSubStrings := sets.NewString(
-		testidentification.OperatorsUpgradedTest,
-		testidentification.APIsRemainAvailTest,
-		testidentification.MachineConfigsUpgradedTest,
-		testidentification.CVOAcknowledgesUpgradeTest,
-	)
+	reportTests := testidentification.UpgradeReportTests(release)
 
 	variantColumns, tests, err := VariantTestsReport(dbc, release, v1.CurrentReport,
-		exactTestNames, testPrefixes, testSubStrings, testidentification.DefaultExcludedVariants)
+		reportTests.Names, reportTests.Prefixes, reportTests.SubStrings, testidentification.DefaultExcludedVariants)
 	if err != nil {
 		log.WithError(err).Error("could not generate upgrade report")
 		RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": "Could not generate install report: " + err.Error()})
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+// DefaultCoverageDimensions are the variant keys combinations are made of unless others are requested: the ones a
+// GA blocker is usually specific to.
+var DefaultCoverageDimensions = []string{"Platform", "Architecture", "Network", "Topology"}
+
+// ValidateVariantCoverageOptions returns the base release to compare a release with, defaulting to the one before
+// it, and an error if the options are invalid.
+func ValidateVariantCoverageOptions(release, baseRelease string, dimensions []string) (string, error) {
+	if baseRelease == "" {
+		var err error
+		if baseRelease, err = util.PreviousRelease(release); err != nil {
+			return "", fmt.Errorf("base_release is required: %v", err)
+		}
+	}
+	if baseRelease == release {
+		return "", fmt.Errorf("base_release must differ from release")
+	}
+	for _, d := range dimensions {
+		if d == "" || strings.Contains(d, ":") {
+			return "", fmt.Errorf("invalid dimension %q: must be a variant key such as Platform", d)
+		}
+	}
+	return baseRelease, nil
+}
+
+// GetVariantCoverageComparison compares the combinations of variants a release's jobs ran with between start and
+// end to those of a base release's jobs in the same window.
+func GetVariantCoverageComparison(dbc *db.DB, release, baseRelease string, dimensions []string, start, end time.Time) (*apitype.VariantCoverageComparison, error) {
+	jobs, err := query.ReleaseJobVariantRuns(dbc, release, start, end)
+	if err != nil {
+		return nil, err
+	}
+	baseJobs, err := query.ReleaseJobVariantRuns(dbc, baseRelease, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildVariantCoverageComparison(jobs, baseJobs, dimensions)
+	result.Release = release
+	result.BaseRelease = baseRelease
+	result.Start = start
+	result.End = end
+	return result, nil
+}
+
+func buildVariantCoverageComparison(jobs, baseJobs []query.JobVariantRuns, dimensions []string) *apitype.VariantCoverageComparison {
+	result := &apitype.VariantCoverageComparison{
+		Dimensions: dimensions,
+		Added:      make([]apitype.VariantCombination, 0),
+		Dropped:    make([]apitype.VariantCombination, 0),
+	}
+
+	combinations := variantCombinations(jobs, dimensions)
+	baseCombinations := variantCombinations(baseJobs, dimensions)
+	for key, c := range combinations {
+		if _, ok := baseCombinations[key]; ok {
+			result.Covered++
+			continue
+		}
+		result.Added = append(result.Added, *c)
+	}
+	for key, c := range baseCombinations {
+		if _, ok := combinations[key]; !ok {
+			result.Dropped = append(result.Dropped, *c)
+		}
+	}
+
+	for _, list := range [][]apitype.VariantCombination{result.Added, result.Dropped} {
+		sort.Slice(list, func(i, j int) bool {
+			return strings.Join(list[i].Variants, ",") < strings.Join(list[j].Variants, ",")
+		})
+	}
+	return result
+}
+
+// variantCombinations groups jobs by their values of the dimensions, in the order of the dimensions. A job without
+// a value for a dimension is grouped without it, rather than dropped, so jobs missing a variant still count as
+// coverage.
+func variantCombinations(jobs []query.JobVariantRuns, dimensions []string) map[string]*apitype.VariantCombination {
+	combinations := map[string]*apitype.VariantCombination{}
+	for _, job := range jobs {
+		values := map[string]string{}
+		for _, v := range job.Variants {
+			if key, value, ok := strings.Cut(v, ":"); ok {
+				values[key] = value
+			}
+		}
+
+		variants := make([]string, 0, len(dimensions))
+		for _, d := range dimensions {
+			if value, ok := values[d]; ok {
+				variants = append(variants, d+":"+value)
+			}
+		}
+		key := strings.Join(variants, ",")
+		c, ok := combinations[key]
+		if !ok {
+			c = &apitype.VariantCombination{Variants: variants, Jobs: make([]string, 0)}
+			combinations[key] = c
+		}
+		c.Jobs = append(c.Jobs, job.Name)
+		c.Runs += job.Runs
+	}
+	return combinations
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+func TestValidateVariantCoverageOptions(t *testing.T) {
+	base, err := ValidateVariantCoverageOptions("4.16", "", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "4.15", base)
+
+	base, err = ValidateVariantCoverageOptions("4.16", "4.14", DefaultCoverageDimensions)
+	require.NoError(t, err)
+	assert.Equal(t, "4.14", base)
+
+	_, err = ValidateVariantCoverageOptions("Presubmits", "", nil)
+	assert.ErrorContains(t, err, "base_release is required")
+
+	_, err = ValidateVariantCoverageOptions("4.16", "4.16", nil)
+	assert.ErrorContains(t, err, "must differ")
+
+	_, err = ValidateVariantCoverageOptions("4.16", "", []string{"Platform:aws"})
+	assert.ErrorContains(t, err, "invalid dimension")
+}
+
+func TestBuildVariantCoverageComparison(t *testing.T) {
+	jobs := []query.JobVariantRuns{
+		{Name: "4.16-e2e-aws-ovn", Variants: []string{"Platform:aws", "Network:ovn", "Suite:parallel"}, Runs: 10},
+		{Name: "4.16-e2e-aws-ovn-serial", Variants: []string{"Platform:aws", "Network:ovn", "Suite:serial"}, Runs: 5},
+		{Name: "4.16-e2e-gcp-ovn", Variants: []string{"Platform:gcp", "Network:ovn"}, Runs: 7},
+		{Name: "4.16-e2e-nutanix", Variants: []string{"Platform:nutanix"}, Runs: 2},
+	}
+	baseJobs := []query.JobVariantRuns{
+		{Name: "4.15-e2e-aws-ovn", Variants: []string{"Platform:aws", "Network:ovn"}, Runs: 12},
+		{Name: "4.15-e2e-gcp-ovn", Variants: []string{"Platform:gcp", "Network:ovn"}, Runs: 8},
+		{Name: "4.15-e2e-vsphere-ovn", Variants: []string{"Platform:vsphere", "Network:ovn"}, Runs: 6},
+		{Name: "4.15-e2e-vsphere-ovn-serial", Variants: []string{"Platform:vsphere", "Network:ovn", "Suite:serial"}, Runs: 3},
+	}
+
+	result := buildVariantCoverageComparison(jobs, baseJobs, []string{"Platform", "Network"})
+	assert.Equal(t, 2, result.Covered, "aws and gcp with ovn are covered in both releases")
+	assert.Equal(t, []apitype.VariantCombination{
+		{Variants: []string{"Platform:nutanix"}, Jobs: []string{"4.16-e2e-nutanix"}, Runs: 2},
+	}, result.Added)
+	assert.Equal(t, []apitype.VariantCombination{
+		{
+			Variants: []string{"Platform:vsphere", "Network:ovn"},
+			Jobs:     []string{"4.15-e2e-vsphere-ovn", "4.15-e2e-vsphere-ovn-serial"},
+			Runs:     9,
+		},
+	}, result.Dropped)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	bqcachedclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/variantregistry"
+)
+
+// ErrVariantRulesConflict is returned when committing rules proposed against a revision that is no longer active.
+var ErrVariantRulesConflict = errors.New("variant rules have changed since the proposal was made, preview again against the active revision")
+
+// ValidateVariantRulesRevision ensures a rules revision submitted via the API is well-formed, and returns the
+// parsed rules.
+func ValidateVariantRulesRevision(revision *models.VariantRulesRevision) (*variantregistry.ReleaseDefaults, error) {
+	if strings.TrimSpace(revision.Author) == "" {
+		return nil, fmt.Errorf("author is required")
+	}
+	if strings.TrimSpace(revision.Comment) == "" {
+		return nil, fmt.Errorf("comment is required")
+	}
+	return variantregistry.ParseReleaseDefaults([]byte(revision.Rules))
+}
+
+// GetVariantRules returns the active rules revision and the revision history. Without a database the built-in
+// rules are active.
+func GetVariantRules(dbc *db.DB) (*apitype.VariantRules, error) {
+	rules := &apitype.VariantRules{
+		Active:    models.VariantRulesRevision{Rules: variantregistry.DefaultReleaseDefaults().YAML()},
+		Revisions: []models.VariantRulesRevision{},
+	}
+	if dbc == nil {
+		return rules, nil
+	}
+	if err := dbc.DB.Order("id DESC").Find(&rules.Revisions).Error; err != nil {
+		return nil, err
+	}
+	if len(rules.Revisions) > 0 {
+		rules.Active = rules.Revisions[0]
+	}
+	return rules, nil
+}
+
+// ActiveReleaseDefaults returns the release defaults from the active rules revision.
+func ActiveReleaseDefaults(dbc *db.DB) (*variantregistry.ReleaseDefaults, error) {
+	rules, err := GetVariantRules(dbc)
+	if err != nil {
+		return nil, err
+	}
+	return variantregistry.ParseReleaseDefaults([]byte(rules.Active.Rules))
+}
+
+// PreviewVariantRules dry-runs proposed rules against the variants currently in the registry.
+func PreviewVariantRules(dbc *db.DB, bqc *bqcachedclient.Client, proposed *variantregistry.ReleaseDefaults) (*variantregistry.RulesPreview, error) {
+	active, err := ActiveReleaseDefaults(dbc)
+	if err != nil {
+		return nil, errors.Wrap(err, "error loading active variant rules")
+	}
+	loader := variantregistry.NewJobVariantsLoader(bqc.BQ, bqc.BQ.Project(), bqc.Dataset,
+		bqcachedclient.JobVariantsTable, nil, nil, "")
+	current, err := loader.CurrentJobVariants()
+	if err != nil {
+		return nil, err
+	}
+	preview := variantregistry.PreviewReleaseDefaults(current, active, proposed)
+	return &preview, nil
+}
+
+// CommitVariantRules validates and stores a new rules revision, making it active. The revision must have been
+// proposed against the currently active revision.
+func CommitVariantRules(dbc *db.DB, revision *models.VariantRulesRevision) error {
+	revision.Model = models.Model{}
+	if _, err := ValidateVariantRulesRevision(revision); err != nil {
+		return err
+	}
+	return dbc.DB.Transaction(func(tx *gorm.DB) error {
+		// Serialize commits so two proposals against the same base can't both succeed.
+		if err := tx.Exec("LOCK TABLE variant_rules_revisions IN EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+		var activeID uint
+		if err := tx.Model(&models.VariantRulesRevision{}).Select("COALESCE(MAX(id), 0)").Scan(&activeID).Error; err != nil {
+			return err
+		}
+		if activeID != revision.BaseRevisionID {
+			return ErrVariantRulesConflict
+		}
+		return tx.Create(revision).Error
+	})
+}
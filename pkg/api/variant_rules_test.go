@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/variantregistry"
+)
+
+func TestValidateVariantRulesRevision(t *testing.T) {
+	valid := func() models.VariantRulesRevision {
+		return models.VariantRulesRevision{
+			Rules:   variantregistry.DefaultReleaseDefaults().YAML(),
+			Author:  "jdoe",
+			Comment: "no change",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(r *models.VariantRulesRevision)
+		wantErr string
+	}{
+		{name: "valid", mutate: func(r *models.VariantRulesRevision) {}},
+		{name: "missing author", mutate: func(r *models.VariantRulesRevision) { r.Author = " " }, wantErr: "author is required"},
+		{name: "missing comment", mutate: func(r *models.VariantRulesRevision) { r.Comment = "" }, wantErr: "comment is required"},
+		{name: "invalid rules", mutate: func(r *models.VariantRulesRevision) { r.Rules = "ContainerRuntime: runc" }, wantErr: "invalid release defaults"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := valid()
+			test.mutate(&r)
+			_, err := ValidateVariantRulesRevision(&r)
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.wantErr)
+			}
+		})
+	}
+}
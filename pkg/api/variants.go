@@ -0,0 +1,32 @@
+package api
+
+import (
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// GetVariantKeys returns every variant key with its distinct values and the number of jobs with each, optionally
+// only for one release.
+func GetVariantKeys(dbc *db.DB, release string) ([]apitype.VariantKey, error) {
+	counts, err := query.VariantValueCounts(dbc, release)
+	if err != nil {
+		return nil, err
+	}
+	return groupVariantValues(counts), nil
+}
+
+// groupVariantValues groups value counts, which are in key order, by key.
+func groupVariantValues(counts []apitype.VariantValueCount) []apitype.VariantKey {
+	keys := []apitype.VariantKey{}
+	for _, c := range counts {
+		if len(keys) == 0 || keys[len(keys)-1].Key != c.Key {
+			keys = append(keys, apitype.VariantKey{Key: c.Key, Values: []apitype.VariantValueCount{}})
+		}
+		key := &keys[len(keys)-1]
+		// A job has one value per key, so the jobs with the key are the sum of the jobs with each value.
+		key.Jobs += c.Jobs
+		key.Values = append(key.Values, c)
+	}
+	return keys
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestGroupVariantValues(t *testing.T) {
+	counts := []apitype.VariantValueCount{
+		{Key: "Network", Value: "ovn", Jobs: 10},
+		{Key: "Network", Value: "sdn", Jobs: 2},
+		{Key: "Platform", Value: "aws", Jobs: 7},
+	}
+	assert.Equal(t, []apitype.VariantKey{
+		{
+			Key:  "Network",
+			Jobs: 12,
+			Values: []apitype.VariantValueCount{
+				{Key: "Network", Value: "ovn", Jobs: 10},
+				{Key: "Network", Value: "sdn", Jobs: 2},
+			},
+		},
+		{
+			Key:    "Platform",
+			Jobs:   7,
+			Values: []apitype.VariantValueCount{{Key: "Platform", Value: "aws", Jobs: 7}},
+		},
+	}, groupVariantValues(counts))
+
+	assert.Equal(t, []apitype.VariantKey{}, groupVariantValues(nil))
+}
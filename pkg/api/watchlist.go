@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// ErrWatchSubscriptionNotFound is returned when a watch subscription does not exist.
+var ErrWatchSubscriptionNotFound = errors.New("watch subscription not found")
+
+// ValidateWatchSubscription ensures a watch subscription submitted via the API is well-formed.
+func ValidateWatchSubscription(sub *models.WatchSubscription) error {
+	if strings.TrimSpace(sub.Subscriber) == "" {
+		return fmt.Errorf("subscriber is required")
+	}
+	if sub.Release == "" {
+		return fmt.Errorf("release is required")
+	}
+	if sub.TestName != "" && sub.JobName != "" {
+		return fmt.Errorf("only one of test_name or job_name may be set")
+	}
+	if sub.TestName == "" && sub.JobName == "" && len(sub.Variants) == 0 {
+		return fmt.Errorf("one of test_name, job_name or variants is required")
+	}
+	for _, v := range sub.Variants {
+		if !strings.Contains(v, ":") {
+			return fmt.Errorf("invalid variant %q: must be in the form Name:value", v)
+		}
+	}
+	if sub.Threshold < 0 || sub.Threshold > 100 {
+		return fmt.Errorf("threshold must be between 0 and 100")
+	}
+	if sub.Threshold == 0 && !sub.NotifyRegressions {
+		return fmt.Errorf("one of threshold or notify_regressions is required")
+	}
+	if sub.NotifyRegressions && sub.JobName != "" {
+		return fmt.Errorf("notify_regressions is not supported for job subscriptions")
+	}
+	if sub.WebhookURL == "" && len(sub.Email) == 0 {
+		return fmt.Errorf("one of webhook_url or email is required")
+	}
+	if sub.WebhookURL != "" {
+		if u, err := url.Parse(sub.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook_url %q", sub.WebhookURL)
+		}
+	}
+	for _, e := range sub.Email {
+		if !strings.Contains(e, "@") {
+			return fmt.Errorf("invalid email %q", e)
+		}
+	}
+	return nil
+}
+
+// ListWatchSubscriptions returns watch subscriptions, optionally limited to a subscriber.
+func ListWatchSubscriptions(dbc *db.DB, subscriber string) ([]models.WatchSubscription, error) {
+	subs := make([]models.WatchSubscription, 0)
+	q := dbc.DB.Order("id")
+	if subscriber != "" {
+		q = q.Where("subscriber = ?", subscriber)
+	}
+	res := q.Find(&subs)
+	return subs, res.Error
+}
+
+// GetWatchSubscription returns a single watch subscription by ID.
+func GetWatchSubscription(dbc *db.DB, id uint) (*models.WatchSubscription, error) {
+	sub := &models.WatchSubscription{}
+	res := dbc.DB.First(sub, id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrWatchSubscriptionNotFound
+	}
+	return sub, res.Error
+}
+
+// CreateWatchSubscription validates and stores a new watch subscription.
+func CreateWatchSubscription(dbc *db.DB, sub *models.WatchSubscription) error {
+	sub.Model = models.Model{}
+	resetWatchState(sub)
+	if err := ValidateWatchSubscription(sub); err != nil {
+		return err
+	}
+	return dbc.DB.Create(sub).Error
+}
+
+// UpdateWatchSubscription replaces the fields of an existing watch subscription. Its state is reset, so the next
+// check records a new baseline rather than notifying against the old threshold.
+func UpdateWatchSubscription(dbc *db.DB, id uint, sub *models.WatchSubscription) error {
+	existing, err := GetWatchSubscription(dbc, id)
+	if err != nil {
+		return err
+	}
+	resetWatchState(sub)
+	if err := ValidateWatchSubscription(sub); err != nil {
+		return err
+	}
+	sub.Model = existing.Model
+	return dbc.DB.Save(sub).Error
+}
+
+// DeleteWatchSubscription soft deletes a watch subscription.
+func DeleteWatchSubscription(dbc *db.DB, id uint) error {
+	res := dbc.DB.Delete(&models.WatchSubscription{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrWatchSubscriptionNotFound
+	}
+	return nil
+}
+
+func resetWatchState(sub *models.WatchSubscription) {
+	sub.LastState = ""
+	sub.LastPassPercentage = nil
+	sub.LastCheckedAt = nil
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestValidateWatchSubscription(t *testing.T) {
+	valid := func(mutate func(sub *models.WatchSubscription)) models.WatchSubscription {
+		sub := models.WatchSubscription{
+			Subscriber: "jdoe",
+			Release:    "4.16",
+			TestName:   "[sig-network] pods should successfully create sandboxes by other",
+			Threshold:  95,
+			WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX",
+		}
+		if mutate != nil {
+			mutate(&sub)
+		}
+		return sub
+	}
+
+	tests := []struct {
+		name      string
+		sub       models.WatchSubscription
+		expectErr bool
+	}{
+		{
+			name: "valid test subscription",
+			sub:  valid(nil),
+		},
+		{
+			name: "valid variants subscription notifying of regressions by e-mail",
+			sub: valid(func(sub *models.WatchSubscription) {
+				sub.TestName = ""
+				sub.Variants = pq.StringArray{"Platform:aws", "Network:ovn"}
+				sub.Threshold = 0
+				sub.NotifyRegressions = true
+				sub.WebhookURL = ""
+				sub.Email = pq.StringArray{"jdoe@example.com"}
+			}),
+		},
+		{
+			name:      "missing subscriber",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.Subscriber = " " }),
+			expectErr: true,
+		},
+		{
+			name:      "missing release",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.Release = "" }),
+			expectErr: true,
+		},
+		{
+			name: "test and job",
+			sub: valid(func(sub *models.WatchSubscription) {
+				sub.JobName = "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"
+			}),
+			expectErr: true,
+		},
+		{
+			name:      "no target",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.TestName = "" }),
+			expectErr: true,
+		},
+		{
+			name:      "invalid variant",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.Variants = pq.StringArray{"aws"} }),
+			expectErr: true,
+		},
+		{
+			name:      "threshold out of range",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.Threshold = 101 }),
+			expectErr: true,
+		},
+		{
+			name:      "nothing to notify of",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.Threshold = 0 }),
+			expectErr: true,
+		},
+		{
+			name: "regressions for a job",
+			sub: valid(func(sub *models.WatchSubscription) {
+				sub.TestName = ""
+				sub.JobName = "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"
+				sub.NotifyRegressions = true
+			}),
+			expectErr: true,
+		},
+		{
+			name:      "no destination",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.WebhookURL = "" }),
+			expectErr: true,
+		},
+		{
+			name:      "invalid webhook",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.WebhookURL = "hooks.slack.com" }),
+			expectErr: true,
+		},
+		{
+			name:      "invalid email",
+			sub:       valid(func(sub *models.WatchSubscription) { sub.Email = pq.StringArray{"jdoe"} }),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sub := tc.sub
+			err := ValidateWatchSubscription(&sub)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -61,6 +61,18 @@ type RequestOptions struct {
 	VariantOption  RequestVariantOptions
 	AdvancedOption RequestAdvancedOptions
 	CacheOption    cache.RequestOptions
+	// BasisPins replace the basis for specific tests or components.
+	BasisPins []BasisPin
+}
+
+// BasisPin replaces the basis for a test, or every test in a component, when the default basis includes a known
+// bad period. A test's pin takes precedence over its component's.
+type BasisPin struct {
+	ID        uint                  `json:"id"`
+	TestID    string                `json:"test_id,omitempty"`
+	Component string                `json:"component,omitempty"`
+	Basis     RequestReleaseOptions `json:"basis"`
+	Reason    string                `json:"reason"`
 }
 
 // View is a server side construct representing a predefined view over the component readiness data.
@@ -74,6 +86,8 @@ type View struct {
 
 	Metrics            ViewMetrics            `json:"metrics" yaml:"metrics"`
 	RegressionTracking ViewRegressionTracking `json:"regression_tracking" yaml:"regression_tracking"`
+	// Notifications are not served with the view, as webhook URLs are credentials.
+	Notifications ViewNotifications `json:"-" yaml:"notifications"`
 }
 
 type ViewMetrics struct {
@@ -84,12 +98,32 @@ type ViewRegressionTracking struct {
 	Enabled bool `json:"enabled" yaml:"enabled"`
 }
 
+// ViewNotifications configures where changes in the view's report are sent when it is regenerated.
+type ViewNotifications struct {
+	// WebhookURL receives a json payload. Slack and Google Chat incoming webhooks are detected by host and sent
+	// just the message text.
+	WebhookURL string `yaml:"webhook_url"`
+	// Email lists addresses to mail, which requires an SMTP server to be configured.
+	Email []string `yaml:"email"`
+	// Template is an optional text/template for the message text, executed with the message's Subject, Text and
+	// Data (the view's diff).
+	Template string `yaml:"template"`
+}
+
+// Enabled returns true if any notification channel is configured.
+func (n ViewNotifications) Enabled() bool {
+	return n.WebhookURL != "" || len(n.Email) > 0
+}
+
 type RequestAdvancedOptions struct {
 	MinimumFailure   int  `json:"minimum_failure" yaml:"minimum_failure"`
 	Confidence       int  `json:"confidence" yaml:"confidence"`
 	PityFactor       int  `json:"pity_factor" yaml:"pity_factor"`
 	IgnoreMissing    bool `json:"ignore_missing" yaml:"ignore_missing"`
 	IgnoreDisruption bool `json:"ignore_disruption" yaml:"ignore_disruption"`
+	// ShadowAlgorithms are candidate regression detection algorithms to run alongside the current one, without
+	// affecting the report, so they can be evaluated on live data before switching over.
+	ShadowAlgorithms []string `json:"shadow_algorithms,omitempty" yaml:"shadow_algorithms,omitempty"`
 }
 
 type TestStatus struct {
@@ -101,6 +135,8 @@ type TestStatus struct {
 	TotalCount   int      `json:"total_count"`
 	SuccessCount int      `json:"success_count"`
 	FlakeCount   int      `json:"flake_count"`
+	// FailedFilePaths are the junit paths of the most recent failures, most recent first. Only set for the sample.
+	FailedFilePaths []string `json:"failed_file_paths,omitempty"`
 }
 
 type ReportTestStatus struct {
@@ -125,6 +161,32 @@ type TestIdentification struct {
 type ComponentReport struct {
 	Rows        []ReportRow `json:"rows,omitempty"`
 	GeneratedAt *time.Time  `json:"generated_at"`
+	// BasisPins lists the pins that replaced the basis of tests in the report.
+	BasisPins []BasisPin `json:"basis_pins,omitempty"`
+	// ShadowEvaluations compare each requested shadow algorithm with the current one.
+	ShadowEvaluations []ShadowEvaluation `json:"shadow_evaluations,omitempty"`
+}
+
+// ShadowEvaluation compares the verdicts of a candidate regression detection algorithm, run in shadow mode, with
+// those of the current algorithm over every test in a report.
+type ShadowEvaluation struct {
+	Algorithm      string `json:"algorithm"`
+	TestsEvaluated int    `json:"tests_evaluated"`
+	BothRegressed  int    `json:"both_regressed"`
+	CurrentOnly    int    `json:"current_only"`
+	CandidateOnly  int    `json:"candidate_only"`
+	// Verdicts are the tests either algorithm found regressed, all other tests were found regressed by neither.
+	Verdicts []ShadowVerdict `json:"verdicts,omitempty"`
+}
+
+// ShadowVerdict is both algorithms' verdict for a single test.
+type ShadowVerdict struct {
+	ReportTestIdentification
+	CurrentStatus      Status `json:"current_status"`
+	CurrentRegressed   bool   `json:"current_regressed"`
+	CandidateRegressed bool   `json:"candidate_regressed"`
+	// CandidateScore is the statistic the candidate based its verdict on, which depends on the algorithm.
+	CandidateScore float64 `json:"candidate_score"`
 }
 
 type ReportRow struct {
@@ -170,6 +232,10 @@ type ReportTestSummary struct {
 	// is being used, without overriding the start/end dates.
 	Opened *time.Time `json:"opened"`
 
+	// SampleFailedJobRuns are the URLs of the most recent job runs in the sample where the test failed, most recent
+	// first, so the UI can link to failures without querying the test details.
+	SampleFailedJobRuns []string `json:"sample_failed_job_runs,omitempty"`
+
 	ReportTestStats
 }
 
@@ -181,6 +247,8 @@ type ReportTestStats struct {
 	FisherExact  float64                 `json:"fisher_exact"`
 	SampleStats  TestDetailsReleaseStats `json:"sample_stats"`
 	BaseStats    TestDetailsReleaseStats `json:"base_stats"`
+	// BasisPin is set when the base stats come from a pinned basis rather than the report's.
+	BasisPin *BasisPin `json:"basis_pin,omitempty"`
 }
 
 type ReportTestDetails struct {
@@ -277,6 +345,8 @@ const (
 	MissingBasisAndSample Status = 2
 	// SignificantImprovement indicates improved sample rate
 	SignificantImprovement Status = 3
+	// NotApplicable indicates a test that can never run in the variants, so missing or failing data is expected
+	NotApplicable Status = 4
 )
 
 type ReportResponse []ReportRow
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"math"
+
+	"github.com/openshift/sippy/pkg/stats"
+)
+
+// PassRateConfidence is the confidence level, as a percentage, of the pass percentage intervals in API responses.
+const PassRateConfidence = 95
+
+// ConfidenceInterval is the range a pass percentage is within at PassRateConfidence, given the number of runs it's
+// over. It's wide when there are few runs, so a pass percentage of 100 over 3 runs isn't mistaken for 100 over 300.
+type ConfidenceInterval struct {
+	Runs  int     `json:"runs"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// NewConfidenceInterval returns the Wilson score interval, as percentages, of passes out of runs.
+func NewConfidenceInterval(passes, runs int) *ConfidenceInterval {
+	lower, upper := stats.WilsonInterval(passes, runs, PassRateConfidence)
+	return &ConfidenceInterval{Runs: runs, Lower: lower * 100, Upper: upper * 100}
+}
+
+// PassPercentageInterval returns the confidence interval of a pass percentage over runs, for results that don't
+// carry their pass count. A nil pass percentage, for results without runs, has the widest interval.
+func PassPercentageInterval(passPercentage *float64, runs int) *ConfidenceInterval {
+	if passPercentage == nil || runs <= 0 {
+		return NewConfidenceInterval(0, 0)
+	}
+	return NewConfidenceInterval(int(math.Round(*passPercentage*float64(runs)/100)), runs)
+}
+
+// SetConfidenceIntervals sets the confidence intervals of the current and previous pass percentages.
+func (v *Variant) SetConfidenceIntervals() {
+	v.CurrentPassPercentageCI = PassPercentageInterval(&v.CurrentPassPercentage, v.CurrentRuns)
+	v.PreviousPassPercentageCI = PassPercentageInterval(&v.PreviousPassPercentage, v.PreviousRuns)
+}
+
+// SetConfidenceIntervals sets the confidence intervals of the current and previous pass percentages.
+func (job *Job) SetConfidenceIntervals() {
+	job.CurrentPassPercentageCI = PassPercentageInterval(&job.CurrentPassPercentage, job.CurrentRuns)
+	job.PreviousPassPercentageCI = PassPercentageInterval(&job.PreviousPassPercentage, job.PreviousRuns)
+}
+
+// SetConfidenceIntervals sets the confidence intervals of the current and previous pass percentages.
+func (test *Test) SetConfidenceIntervals() {
+	test.CurrentPassPercentageCI = PassPercentageInterval(&test.CurrentPassPercentage, test.CurrentRuns)
+	test.PreviousPassPercentageCI = PassPercentageInterval(&test.PreviousPassPercentage, test.PreviousRuns)
+}
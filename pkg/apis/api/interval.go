@@ -1,6 +1,9 @@
 package api
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Types originally from origin monitorapi package
 type Locator struct {
@@ -58,3 +61,30 @@ type LegacyEventIntervalList struct {
 	Items                  []LegacyEventInterval `json:"items"`
 	IntervalFilesAvailable []string              `json:"intervalFilesAvailable"`
 }
+
+// ParseEventIntervals parses the contents of an intervals file, falling back to the legacy schema and
+// translating it to the new one if required.
+func ParseEventIntervals(content []byte) (*EventIntervalList, error) {
+	var newIntervals EventIntervalList
+	if err := json.Unmarshal(content, &newIntervals); err == nil {
+		return &newIntervals, nil
+	}
+
+	var legacyIntervals LegacyEventIntervalList
+	if err := json.Unmarshal(content, &legacyIntervals); err != nil {
+		return nil, err
+	}
+	newIntervals = EventIntervalList{Items: make([]EventInterval, len(legacyIntervals.Items))}
+	for i, li := range legacyIntervals.Items {
+		newIntervals.Items[i] = EventInterval{
+			Level:             li.Level,
+			Display:           li.Display,
+			Source:            li.Source,
+			StructuredLocator: li.StructuredLocator,
+			StructuredMessage: li.StructuredMessage,
+			From:              li.From,
+			To:                li.To,
+		}
+	}
+	return &newIntervals, nil
+}
@@ -210,6 +210,60 @@ type Variant struct {
 	PreviousFails          int     `json:"previous_fails,omitempty"`
 
 	NetImprovement float64 `json:"net_improvement"`
+
+	// CurrentPassPercentageCI and PreviousPassPercentageCI bound the pass percentages given their number of runs.
+	CurrentPassPercentageCI  *ConfidenceInterval `json:"current_pass_percentage_ci,omitempty" gorm:"-"`
+	PreviousPassPercentageCI *ConfidenceInterval `json:"previous_pass_percentage_ci,omitempty" gorm:"-"`
+}
+
+func (v Variant) GetFieldType(param string) ColumnType {
+	switch param {
+	//nolint:goconst
+	case "name":
+		return ColumnTypeString
+	default:
+		return ColumnTypeNumerical
+	}
+}
+
+func (v Variant) GetStringValue(param string) (string, error) {
+	switch param {
+	case "name":
+		return v.Name, nil
+	default:
+		return "", fmt.Errorf("unknown string field %s", param)
+	}
+}
+
+func (v Variant) GetNumericalValue(param string) (float64, error) {
+	switch param {
+	case "id":
+		return float64(v.ID), nil
+	case "current_pass_percentage":
+		return v.CurrentPassPercentage, nil
+	case "current_runs":
+		return float64(v.CurrentRuns), nil
+	case "current_passes":
+		return float64(v.CurrentPasses), nil
+	case "current_fails":
+		return float64(v.CurrentFails), nil
+	case "previous_pass_percentage":
+		return v.PreviousPassPercentage, nil
+	case "previous_runs":
+		return float64(v.PreviousRuns), nil
+	case "previous_passes":
+		return float64(v.PreviousPasses), nil
+	case "previous_fails":
+		return float64(v.PreviousFails), nil
+	case "net_improvement":
+		return v.NetImprovement, nil
+	default:
+		return 0, fmt.Errorf("unknown numerical field %s", param)
+	}
+}
+
+func (v Variant) GetArrayValue(param string) ([]string, error) {
+	return nil, fmt.Errorf("unknown array value field %s", param)
 }
 
 // Job contains the full accounting of a job's history, with a synthetic ID. The format of
@@ -241,8 +295,22 @@ type Job struct {
 	PreviousInfraFails              int     `json:"previous_infra_fails,omitempty"`
 	NetImprovement                  float64 `json:"net_improvement"`
 
+	// CurrentPassPercentageCI and PreviousPassPercentageCI bound the pass percentages given their number of runs.
+	CurrentPassPercentageCI  *ConfidenceInterval `json:"current_pass_percentage_ci,omitempty" gorm:"-"`
+	PreviousPassPercentageCI *ConfidenceInterval `json:"previous_pass_percentage_ci,omitempty" gorm:"-"`
+
 	TestGridURL string `json:"test_grid_url"`
 	OpenBugs    int    `json:"open_bugs"`
+
+	// PreBranchNames are the job's names before its release branch was cut, whose runs are included in its results.
+	PreBranchNames []string `json:"pre_branch_names,omitempty" gorm:"-"`
+}
+
+// JobV2 is a job report row as returned by /api/v2, with variants keyed by variant name as in the variant
+// registry, i.e. {"Platform": "aws"}, rather than a list of "Platform:aws" strings.
+type JobV2 struct {
+	Job
+	Variants map[string]string `json:"variants"`
 }
 
 func (job Job) GetFieldType(param string) ColumnType {
@@ -340,6 +408,7 @@ type JobRun struct {
 	Job                   string              `json:"job"`
 	Cluster               string              `json:"cluster"`
 	URL                   string              `json:"url"`
+	ArtifactsURL          string              `json:"artifacts_url,omitempty" gorm:"-"`
 	TestFlakes            int                 `json:"test_flakes"`
 	FlakedTestNames       pq.StringArray      `json:"flaked_test_names" gorm:"type:text[]"`
 	TestFailures          int                 `json:"test_failures"`
@@ -355,6 +424,11 @@ type JobRun struct {
 	PullRequestLink       string              `json:"pull_request_link"`
 	PullRequestSHA        string              `json:"pull_request_sha"`
 	PullRequestAuthor     string              `json:"pull_request_author"`
+	// Provenance is manual for runs of tests QE ran by hand, with the Tester and Build they ran them on, and
+	// automated otherwise.
+	Provenance string `json:"provenance"`
+	Tester     string `json:"tester,omitempty"`
+	Build      string `json:"build,omitempty"`
 }
 
 func (run JobRun) GetFieldType(param string) ColumnType {
@@ -389,6 +463,8 @@ func (run JobRun) GetFieldType(param string) ColumnType {
 		return ColumnTypeString
 	case "pull_request_link":
 		return ColumnTypeString
+	case "provenance", "tester", "build":
+		return ColumnTypeString
 	default:
 		return ColumnTypeNumerical
 	}
@@ -414,6 +490,12 @@ func (run JobRun) GetStringValue(param string) (string, error) {
 		return run.PullRequestSHA, nil
 	case "pull_request_link":
 		return run.PullRequestLink, nil
+	case "provenance":
+		return run.Provenance, nil
+	case "tester":
+		return run.Tester, nil
+	case "build":
+		return run.Build, nil
 	default:
 		return "", fmt.Errorf("unknown string field %s", param)
 	}
@@ -482,6 +564,10 @@ type Test struct {
 	NetWorkingImprovement float64 `json:"net_working_improvement"`
 	NetImprovement        float64 `json:"net_improvement"`
 
+	// CurrentPassPercentageCI and PreviousPassPercentageCI bound the pass percentages given their number of runs.
+	CurrentPassPercentageCI  *ConfidenceInterval `json:"current_pass_percentage_ci,omitempty" gorm:"-"`
+	PreviousPassPercentageCI *ConfidenceInterval `json:"previous_pass_percentage_ci,omitempty" gorm:"-"`
+
 	WorkingAverage           float64 `json:"working_average,omitempty"`
 	WorkingStandardDeviation float64 `json:"working_standard_deviation,omitempty"`
 	DeltaFromWorkingAverage  float64 `json:"delta_from_working_average,omitempty"`
@@ -703,6 +789,19 @@ type BuildClusterHealthAnalysis struct {
 
 type BuildClusterHealth = models.BuildClusterHealthReport
 
+// BuildClusterFailures is how often runs on a build cluster failed compared to runs on every other cluster over
+// the same period. Implicated is true when the cluster's failure rate is significantly higher than the others'.
+type BuildClusterFailures struct {
+	Cluster                 string  `json:"cluster"`
+	Runs                    int     `json:"runs"`
+	Failures                int     `json:"failures"`
+	InfrastructureFailures  int     `json:"infrastructure_failures"`
+	FailurePercentage       float64 `json:"failure_percentage"`
+	OthersRuns              int     `json:"others_runs"`
+	OthersFailurePercentage float64 `json:"others_failure_percentage"`
+	Implicated              bool    `json:"implicated"`
+}
+
 type AnalysisResult struct {
 	TotalRuns        int                         `json:"total_runs"`
 	ResultCount      map[v1.JobOverallResult]int `json:"result_count"`
@@ -851,4 +950,853 @@ type ReleaseRow struct {
 
 type SippyViews struct {
 	ComponentReadiness []crtype.View `json:"component_readiness" yaml:"component_readiness"`
+	// ReleaseDefaults are the views frontends show by default for each release. The ReleaseDefaultsAll entry applies
+	// to every release, and a release's own entry overrides the fields it sets.
+	ReleaseDefaults map[string]ReleaseDefaults `json:"release_defaults" yaml:"release_defaults"`
+}
+
+// ReleaseDefaultsAll is the ReleaseDefaults key applying to every release.
+const ReleaseDefaultsAll = "default"
+
+// ReleaseDefaults is the default view configuration of a release, served so every frontend shows the same defaults
+// and changing one doesn't need a coordinated UI release.
+type ReleaseDefaults struct {
+	Release string `json:"release" yaml:"-"`
+	// ColumnVariants are the variant names shown as columns in job and test reports.
+	ColumnVariants []string `json:"column_variants" yaml:"column_variants"`
+	// IncludeVariants and ExcludeVariants filter reports by default, in the form Name:value.
+	IncludeVariants []string `json:"include_variants" yaml:"include_variants"`
+	ExcludeVariants []string `json:"exclude_variants" yaml:"exclude_variants"`
+	// BlockingJobs are the jobs shown as blocking. When not configured they're the jobs that blocked the release's
+	// recent payloads.
+	BlockingJobs []string `json:"blocking_jobs" yaml:"blocking_jobs"`
+	// Period is the report period, i.e. default or twoDay.
+	Period string `json:"period" yaml:"period"`
+	// DateRangeDays is how many days before now date ranges start.
+	DateRangeDays int `json:"date_range_days" yaml:"date_range_days"`
+}
+
+// TimeSeriesGranularity is the width of each bucket in a time series.
+type TimeSeriesGranularity string
+
+const (
+	TimeSeriesHour TimeSeriesGranularity = "hour"
+	TimeSeriesDay  TimeSeriesGranularity = "day"
+	TimeSeriesWeek TimeSeriesGranularity = "week"
+)
+
+// TimeSeriesSelector identifies what a time series is computed over. Exactly one of Job, Test or Variant
+// should be set, Release is optional.
+type TimeSeriesSelector struct {
+	Release string `json:"release,omitempty"`
+	Job     string `json:"job,omitempty"`
+	Test    string `json:"test,omitempty"`
+	Variant string `json:"variant,omitempty"`
+	// BuildCluster limits results to runs that executed on a build cluster. Unlike variants, which describe the
+	// job, the build cluster can differ between runs of the same job.
+	BuildCluster string `json:"build_cluster,omitempty"`
+	// ExcludeIncidents omits runs that occurred during a known incident affecting their job.
+	ExcludeIncidents bool `json:"exclude_incidents,omitempty"`
+	// PrimaryFailuresOnly omits test failures that were caused by an earlier failure in the same run.
+	PrimaryFailuresOnly bool `json:"primary_failures_only,omitempty"`
+	// PassRateMode selects whether failures that passed elsewhere in the same payload count as flakes.
+	PassRateMode PassRateMode `json:"pass_rate,omitempty"`
+}
+
+// TimeSeriesBucket contains the pass/fail/flake counts for a single bucket in a time series. Buckets with no
+// results are still present with zero counts, so consumers can chart them without filling gaps.
+type TimeSeriesBucket struct {
+	Bucket           time.Time           `json:"bucket"`
+	Runs             int                 `json:"runs"`
+	Passes           int                 `json:"passes"`
+	Flakes           int                 `json:"flakes"`
+	Failures         int                 `json:"failures"`
+	PassPercentage   *float64            `json:"pass_percentage"`
+	PassPercentageCI *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+}
+
+// TimeSeries is the result of a time series query.
+type TimeSeries struct {
+	Selector    TimeSeriesSelector    `json:"selector"`
+	Granularity TimeSeriesGranularity `json:"granularity"`
+	// Timezone is the IANA timezone daily and weekly buckets start at midnight in.
+	Timezone string             `json:"timezone"`
+	Buckets  []TimeSeriesBucket `json:"buckets"`
+	// Incidents overlapping the time range that affect the selection, for overlaying on charts.
+	Incidents []models.Incident `json:"incidents"`
+}
+
+// DurationPercentiles contains job run duration percentiles, in seconds, for a job or variant over a time range.
+type DurationPercentiles struct {
+	Name string  `json:"name"`
+	Runs int     `json:"runs"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+}
+
+// PassRateAnomaly is a day where a job or component pass rate dropped significantly below its recent baseline.
+type PassRateAnomaly struct {
+	// Kind is the type of entity the pass rate is for, "job" or "component".
+	Kind    string    `json:"kind"`
+	Name    string    `json:"name"`
+	Release string    `json:"release"`
+	Date    time.Time `json:"date"`
+	Runs    int       `json:"runs"`
+	// PassPercentage is the observed pass rate for the day.
+	PassPercentage   float64             `json:"pass_percentage"`
+	PassPercentageCI *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+	// ExpectedPassPercentage is the baseline pass rate prior to this day.
+	ExpectedPassPercentage float64 `json:"expected_pass_percentage"`
+	// Deviations is how many standard deviations below the baseline the observed pass rate is.
+	Deviations float64 `json:"deviations"`
+}
+
+// OperatorConditionSummary reports how often a cluster operator went Degraded or Unavailable during job runs
+// for a variant.
+type OperatorConditionSummary struct {
+	Operator  string `json:"operator"`
+	Condition string `json:"condition"`
+	Variant   string `json:"variant"`
+	// Runs is the number of job runs where the operator reported the condition.
+	Runs int `json:"runs"`
+	// TotalRuns is the number of job runs for the variant with operator conditions loaded.
+	TotalRuns  int     `json:"total_runs"`
+	Percentage float64 `json:"percentage"`
+	// ExampleRuns contains links to a few job runs where the condition was seen.
+	ExampleRuns pq.StringArray `json:"example_runs" gorm:"type:text[]"`
+}
+
+// AlertFiringSummary reports how often an alert fired during job runs for a variant, compared to the
+// previous release.
+type AlertFiringSummary struct {
+	Alert    string `json:"alert"`
+	Severity string `json:"severity"`
+	Variant  string `json:"variant"`
+	// Runs is the number of job runs where the alert fired.
+	Runs int `json:"runs"`
+	// TotalRuns is the number of job runs for the variant with alerts loaded.
+	TotalRuns  int     `json:"total_runs"`
+	Percentage float64 `json:"percentage"`
+
+	PreviousRuns       int     `json:"previous_runs"`
+	PreviousTotalRuns  int     `json:"previous_total_runs"`
+	PreviousPercentage float64 `json:"previous_percentage"`
+	// Delta is the change in percentage of runs firing the alert since the previous release.
+	Delta float64 `json:"delta"`
+}
+
+// EventPatternSummary reports how often an abnormal event pattern occurred in a namespace for a variant on a day.
+type EventPatternSummary struct {
+	Date      time.Time `json:"date"`
+	Pattern   string    `json:"pattern"`
+	Namespace string    `json:"namespace"`
+	Variant   string    `json:"variant"`
+	// Runs is the number of job runs where the pattern was seen.
+	Runs int `json:"runs"`
+	// TotalRuns is the number of job runs for the variant on that day with event patterns loaded.
+	TotalRuns int `json:"total_runs"`
+	// Events is the total number of occurrences across all runs.
+	Events int `json:"events"`
+}
+
+// TestRecentRun is the result of a test in one job run.
+type TestRecentRun struct {
+	ProwJobRunID uint      `json:"prow_job_run_id"`
+	JobName      string    `json:"job_name"`
+	URL          string    `json:"url"`
+	ArtifactsURL string    `json:"artifacts_url,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+	// Status is pass, flake or fail.
+	Status string `json:"status"`
+}
+
+// TestRecentRuns are a test's results in its most recent job runs, newest first, for quick status checks such as
+// PR comment bots and hover cards.
+type TestRecentRuns struct {
+	Release  string   `json:"release"`
+	TestName string   `json:"test_name"`
+	Variants []string `json:"variants,omitempty"`
+	Passes   int      `json:"passes"`
+	Flakes   int      `json:"flakes"`
+	Failures int      `json:"failures"`
+	// LastFailure is when the test last failed in the runs returned, nil if it didn't.
+	LastFailure *time.Time      `json:"last_failure"`
+	Runs        []TestRecentRun `json:"runs"`
+}
+
+// TestInteraction is a pair of tests whose failures are correlated within job runs more strongly than would be
+// expected by chance, suggesting a shared fixture or ordering dependency.
+type TestInteraction struct {
+	TestA string `json:"test_a"`
+	TestB string `json:"test_b"`
+	// CoFailures is the number of runs where both tests failed.
+	CoFailures int `json:"co_failures"`
+	FailuresA  int `json:"failures_a"`
+	FailuresB  int `json:"failures_b"`
+	// Runs is the number of job runs considered.
+	Runs int `json:"runs"`
+	// Lift is how many times more often the tests fail together than expected if they were independent.
+	Lift float64 `json:"lift"`
+	// Correlation is the phi coefficient of the two tests' failures, from -1 to 1.
+	Correlation float64 `json:"correlation"`
+}
+
+// PassRateMode determines how test failures are classified when calculating pass rates.
+type PassRateMode string
+
+const (
+	// PassRateStrict only counts a failure as a flake if the test passed on retry within the same job run.
+	PassRateStrict PassRateMode = "strict"
+	// PassRateLenient additionally counts a failure as a flake if the test passed in another job run against the
+	// same payload.
+	PassRateLenient PassRateMode = "lenient"
+)
+
+// ParsePassRateMode parses the pass_rate request parameter, defaulting to strict.
+func ParsePassRateMode(param string) (PassRateMode, error) {
+	switch PassRateMode(param) {
+	case "", PassRateStrict:
+		return PassRateStrict, nil
+	case PassRateLenient:
+		return PassRateLenient, nil
+	default:
+		return "", fmt.Errorf("invalid pass_rate %q: must be strict or lenient", param)
+	}
+}
+
+// PassRateWeighting determines how runs are weighted by age when calculating pass rates.
+type PassRateWeighting string
+
+const (
+	// PassRateFlat weighs every run in a period equally.
+	PassRateFlat PassRateWeighting = "flat"
+	// PassRateExponential halves the weight of runs every half-life before the end of their period, so a recent
+	// improvement or regression isn't hidden by older runs.
+	PassRateExponential PassRateWeighting = "exponential"
+)
+
+// ParsePassRateWeighting parses the weighting request parameter, defaulting to flat.
+func ParsePassRateWeighting(param string) (PassRateWeighting, error) {
+	switch PassRateWeighting(param) {
+	case "", PassRateFlat:
+		return PassRateFlat, nil
+	case PassRateExponential:
+		return PassRateExponential, nil
+	default:
+		return "", fmt.Errorf("invalid weighting %q: must be flat or exponential", param)
+	}
+}
+
+// Regression is a tracked component readiness regression.
+type Regression struct {
+	models.TestRegression
+	// Hours is how long the regression has been open, or how long it was open for if it's closed.
+	Hours float64 `json:"hours"`
+}
+
+// ComponentRegressionStats summarizes the regressions tracked for a component.
+type ComponentRegressionStats struct {
+	Component string `json:"component"`
+	// Open is the number of regressions currently open.
+	Open int `json:"open"`
+	// Resolved is the number of regressions closed in the time range.
+	Resolved int `json:"resolved"`
+	// MeanHoursToResolve and MedianHoursToResolve are over the regressions closed in the time range, nil if there
+	// were none.
+	MeanHoursToResolve   *float64 `json:"mean_hours_to_resolve"`
+	MedianHoursToResolve *float64 `json:"median_hours_to_resolve"`
+	// OldestOpenHours is the age of the oldest open regression, nil if none are open.
+	OldestOpenHours *float64 `json:"oldest_open_hours"`
+}
+
+// ComponentBugHealth is a component's open JIRA bug backlog alongside its open component readiness regressions, so
+// components with chronic quality problems stand out next to those with only current regressions.
+type ComponentBugHealth struct {
+	Component string `json:"component"`
+	// OpenRegressions is the number of regressions open for the release, OldestRegressionHours the age of the
+	// oldest, nil if none are open.
+	OpenRegressions       int      `json:"open_regressions"`
+	OldestRegressionHours *float64 `json:"oldest_regression_hours"`
+	OpenBugs              int      `json:"open_bugs"`
+	CriticalBugs          int      `json:"critical_bugs"`
+	// Over90Days and Over365Days count open bugs created more than that long ago.
+	Over90Days  int `json:"over_90_days"`
+	Over365Days int `json:"over_365_days"`
+	// MedianBugAgeDays and OldestBugAgeDays are nil if the component has no open bugs.
+	MedianBugAgeDays *float64 `json:"median_bug_age_days"`
+	OldestBugAgeDays *float64 `json:"oldest_bug_age_days"`
+	// BacklogUpdated is when the bug counts were last loaded from JIRA.
+	BacklogUpdated *time.Time `json:"backlog_updated"`
+}
+
+// RegressionReport summarizes regression activity for a release over a time range, typically the past week.
+type RegressionReport struct {
+	Release string    `json:"release"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	// Opened and Resolved are the number of regressions opened and closed in the time range.
+	Opened   int `json:"opened"`
+	Resolved int `json:"resolved"`
+	// Oldest are the longest open regressions, oldest first.
+	Oldest []Regression `json:"oldest"`
+}
+
+// RegressionBurndownBucket contains regression counts for a single bucket of a burn-down.
+type RegressionBurndownBucket struct {
+	Bucket time.Time `json:"bucket"`
+	// Open is the number of regressions open at the end of the bucket.
+	Open int `json:"open"`
+	// Opened and Resolved are the number of regressions opened and closed during the bucket.
+	Opened   int `json:"opened"`
+	Resolved int `json:"resolved"`
+}
+
+// RegressionBurndownGroup is the burn-down for a single component or team.
+type RegressionBurndownGroup struct {
+	Name               string                     `json:"name"`
+	MeanHoursToResolve *float64                   `json:"mean_hours_to_resolve"`
+	Buckets            []RegressionBurndownBucket `json:"buckets"`
+}
+
+// RegressionBurndown tracks open regressions over a release cycle, overall and for each component or team.
+type RegressionBurndown struct {
+	Release     string                `json:"release"`
+	GroupBy     string                `json:"group_by"`
+	Granularity TimeSeriesGranularity `json:"granularity"`
+	// Timezone is the IANA timezone buckets start at midnight in.
+	Timezone string    `json:"timezone"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	// MeanHoursToResolve is the mean time to resolution of regressions closed in the time range, nil if none were.
+	MeanHoursToResolve *float64                   `json:"mean_hours_to_resolve"`
+	Buckets            []RegressionBurndownBucket `json:"buckets"`
+	Groups             []RegressionBurndownGroup  `json:"groups"`
+}
+
+// CapabilityTestStats are the current results of the tests covering a capability.
+type CapabilityTestStats struct {
+	CurrentRuns             int                 `json:"current_runs"`
+	CurrentSuccesses        int                 `json:"current_successes"`
+	CurrentFailures         int                 `json:"current_failures"`
+	CurrentFlakes           int                 `json:"current_flakes"`
+	CurrentPassPercentage   float64             `json:"current_pass_percentage"`
+	CurrentPassPercentageCI *ConfidenceInterval `json:"current_pass_percentage_ci,omitempty" gorm:"-"`
+}
+
+// CapabilityTestVariants are a test's results in a single variant combination.
+type CapabilityTestVariants struct {
+	Variants []string `json:"variants"`
+	CapabilityTestStats
+}
+
+// CapabilityTest is a test covering a capability, with its results overall and by variant combination.
+type CapabilityTest struct {
+	Name      string `json:"name"`
+	Component string `json:"component"`
+	// NotApplicable is why the test can never run in the requested variants, when it has no results there because of
+	// it, so it isn't a gap in coverage.
+	NotApplicable string `json:"not_applicable,omitempty"`
+	CapabilityTestStats
+	Variants []CapabilityTestVariants `json:"variants"`
+}
+
+// CapabilityCoverage maps a capability, or a feature annotated in test names, to the tests covering it and their
+// current health.
+type CapabilityCoverage struct {
+	Capability string `json:"capability"`
+	CapabilityTestStats
+	Tests []CapabilityTest `json:"tests"`
+}
+
+// ImportPhase is the progress of an import through one of its phases.
+type ImportPhase struct {
+	Name       string  `json:"name"`
+	Completed  int     `json:"completed"`
+	Total      int     `json:"total"`
+	Percentage float64 `json:"percentage"`
+}
+
+// ImportProgress is the progress of a running or recently finished import. Stalled is set for unfinished imports
+// that haven't made progress recently, which have likely hung or exited without recording that they finished.
+type ImportProgress struct {
+	ID             uint          `json:"id"`
+	Loader         string        `json:"loader"`
+	StartedAt      time.Time     `json:"started_at"`
+	LastProgressAt time.Time     `json:"last_progress_at"`
+	FinishedAt     *time.Time    `json:"finished_at"`
+	Running        bool          `json:"running"`
+	Stalled        bool          `json:"stalled"`
+	Errors         int           `json:"errors"`
+	RowsWritten    int           `json:"rows_written"`
+	Phases         []ImportPhase `json:"phases"`
+}
+
+// VariantKeyChurn is how often a variant key changed value across all of a release's jobs.
+type VariantKeyChurn struct {
+	Key           string    `json:"key"`
+	Changes       int       `json:"changes"`
+	Jobs          int       `json:"jobs"`
+	LastChangedAt time.Time `json:"last_changed_at"`
+}
+
+// JobVariantChurn is how often a variant key changed value for one job, and the values it changed between.
+type JobVariantChurn struct {
+	JobName       string         `json:"job_name"`
+	Key           string         `json:"key"`
+	Changes       int            `json:"changes"`
+	Values        pq.StringArray `json:"values" gorm:"type:text[]"`
+	LastChangedAt time.Time      `json:"last_changed_at"`
+}
+
+// VariantChurn reports variant keys that change value across syncs. Keys that change often usually point to a
+// parsing bug, or a nondeterministic source of variant data.
+type VariantChurn struct {
+	Keys []VariantKeyChurn `json:"keys"`
+	Jobs []JobVariantChurn `json:"jobs"`
+}
+
+// VariantRules is the active variant rules revision and the history of committed revisions, newest first. The
+// active revision has ID 0 when no revision has been committed and the rules built into sippy are in use.
+type VariantRules struct {
+	Active    models.VariantRulesRevision   `json:"active"`
+	Revisions []models.VariantRulesRevision `json:"revisions"`
+}
+
+// VariantValueCount is the number of jobs with a variant key set to a value.
+type VariantValueCount struct {
+	Key   string `json:"-"`
+	Value string `json:"value"`
+	Jobs  int    `json:"jobs"`
+}
+
+// VariantKey is a variant key with each distinct value jobs have for it. Jobs is the number of jobs with the key.
+type VariantKey struct {
+	Key    string              `json:"key"`
+	Jobs   int                 `json:"jobs"`
+	Values []VariantValueCount `json:"values"`
+}
+
+// OperatorVariantHealth combines the signals for one cluster operator in one variant. Pass percentages are nil when
+// the variant had no runs of the operator's install or upgrade tests.
+type OperatorVariantHealth struct {
+	Variant                 string              `json:"variant"`
+	InstallRuns             int                 `json:"install_runs"`
+	InstallPassPercentage   *float64            `json:"install_pass_percentage"`
+	InstallPassPercentageCI *ConfidenceInterval `json:"install_pass_percentage_ci,omitempty" gorm:"-"`
+	UpgradeRuns             int                 `json:"upgrade_runs"`
+	UpgradePassPercentage   *float64            `json:"upgrade_pass_percentage"`
+	UpgradePassPercentageCI *ConfidenceInterval `json:"upgrade_pass_percentage_ci,omitempty" gorm:"-"`
+	// DegradedRuns is the number of job runs where the operator went Degraded, of TotalRuns with operator
+	// conditions loaded.
+	DegradedRuns       int     `json:"degraded_runs"`
+	TotalRuns          int     `json:"total_runs"`
+	DegradedPercentage float64 `json:"degraded_percentage"`
+	OpenRegressions    int     `json:"open_regressions"`
+}
+
+// OperatorHealth is the health of one cluster operator in a release, by variant, with the open regressions related
+// to it.
+type OperatorHealth struct {
+	Operator    string                  `json:"operator"`
+	Release     string                  `json:"release"`
+	Variants    []OperatorVariantHealth `json:"variants"`
+	Regressions []models.TestRegression `json:"regressions"`
+}
+
+// StreamPassRate is how a job, or a test across paired jobs, did in one payload stream.
+type StreamPassRate struct {
+	Runs             int                 `json:"runs"`
+	Successes        int                 `json:"successes"`
+	PassPercentage   float64             `json:"pass_percentage"`
+	PassPercentageCI *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+}
+
+// StreamJobComparison compares a nightly stream job with the equivalent ci stream job. Difference is the nightly
+// pass percentage minus the ci pass percentage.
+type StreamJobComparison struct {
+	Name        string         `json:"name"`
+	NightlyJob  string         `json:"nightly_job"`
+	CIJob       string         `json:"ci_job"`
+	Nightly     StreamPassRate `json:"nightly"`
+	CI          StreamPassRate `json:"ci"`
+	Difference  float64        `json:"difference"`
+	Significant bool           `json:"significant"`
+}
+
+// StreamTestComparison compares a test's results in nightly stream jobs with those in the equivalent ci stream jobs.
+type StreamTestComparison struct {
+	TestName    string         `json:"test_name"`
+	Nightly     StreamPassRate `json:"nightly"`
+	CI          StreamPassRate `json:"ci"`
+	Difference  float64        `json:"difference"`
+	Significant bool           `json:"significant"`
+}
+
+// StreamComparison compares a release's nightly and ci payload streams. Problems in only one stream point at the
+// build or compose pipeline rather than the code, which both streams share.
+type StreamComparison struct {
+	Release string                 `json:"release"`
+	Start   time.Time              `json:"start"`
+	End     time.Time              `json:"end"`
+	Jobs    []StreamJobComparison  `json:"jobs"`
+	Tests   []StreamTestComparison `json:"tests"`
+}
+
+// VariantCombination is a combination of variant values, i.e. Platform:aws with Network:ovn, and the jobs that ran
+// with it.
+type VariantCombination struct {
+	Variants []string `json:"variants"`
+	Jobs     []string `json:"jobs"`
+	Runs     int      `json:"runs"`
+}
+
+// VariantCoverageComparison compares the variant combinations a release's jobs ran with to those of a base release,
+// normally the one before it, so lanes dropped at branch time are noticed before a blocker turns up on an untested
+// platform.
+type VariantCoverageComparison struct {
+	Release     string    `json:"release"`
+	BaseRelease string    `json:"base_release"`
+	Dimensions  []string  `json:"dimensions"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	// Covered is the number of combinations both releases ran with.
+	Covered int `json:"covered"`
+	// Added are the combinations only the release ran with, and its jobs that ran with them.
+	Added []VariantCombination `json:"added"`
+	// Dropped are the combinations only the base release ran with, and its jobs that ran with them.
+	Dropped []VariantCombination `json:"dropped"`
+}
+
+// ShadowComparison summarizes how a candidate regression detection algorithm, run in shadow mode on a view,
+// agreed with the current algorithm over a time range.
+type ShadowComparison struct {
+	View      string `json:"view"`
+	Release   string `json:"release"`
+	Algorithm string `json:"algorithm"`
+	// Evaluations is the number of distinct reports the algorithms were compared on.
+	Evaluations    int `json:"evaluations"`
+	TestsEvaluated int `json:"tests_evaluated"`
+	BothRegressed  int `json:"both_regressed"`
+	CurrentOnly    int `json:"current_only"`
+	CandidateOnly  int `json:"candidate_only"`
+	// AgreementPercentage is the percentage of test verdicts the algorithms agreed on. Since most tests are not
+	// regressed, RegressionAgreementPercentage, the percentage of tests either found regressed that both did, is
+	// usually the more telling number.
+	AgreementPercentage           float64 `json:"agreement_percentage"`
+	RegressionAgreementPercentage float64 `json:"regression_agreement_percentage"`
+	// LatestGeneratedAt is when the most recent report compared was generated. The unique flags are from it.
+	LatestGeneratedAt  time.Time              `json:"latest_generated_at"`
+	CurrentOnlyTests   []models.ShadowVerdict `json:"current_only_tests"`
+	CandidateOnlyTests []models.ShadowVerdict `json:"candidate_only_tests"`
+}
+
+// MilestonePassRateComparison compares a component's test pass rate, or a variant's job pass rate, with what it was
+// at a release milestone. NetImprovement is the current pass percentage minus the milestone's, and is only set
+// when there were runs in both.
+type MilestonePassRateComparison struct {
+	Kind                      string              `json:"kind"`
+	Name                      string              `json:"name"`
+	MilestoneRuns             int                 `json:"milestone_runs"`
+	MilestonePassPercentage   float64             `json:"milestone_pass_percentage"`
+	MilestonePassPercentageCI *ConfidenceInterval `json:"milestone_pass_percentage_ci,omitempty" gorm:"-"`
+	CurrentRuns               int                 `json:"current_runs"`
+	CurrentPassPercentage     float64             `json:"current_pass_percentage"`
+	CurrentPassPercentageCI   *ConfidenceInterval `json:"current_pass_percentage_ci,omitempty" gorm:"-"`
+	NetImprovement            float64             `json:"net_improvement"`
+}
+
+// MilestoneComparison compares a release's current pass rates with those snapshotted at one of its milestones,
+// most regressed first.
+type MilestoneComparison struct {
+	Release       string                        `json:"release"`
+	Milestone     string                        `json:"milestone"`
+	MilestoneDate time.Time                     `json:"milestone_date"`
+	SnapshotStart time.Time                     `json:"snapshot_start"`
+	SnapshotEnd   time.Time                     `json:"snapshot_end"`
+	CurrentStart  time.Time                     `json:"current_start"`
+	CurrentEnd    time.Time                     `json:"current_end"`
+	PassRates     []MilestonePassRateComparison `json:"pass_rates"`
+}
+
+// ReleaseTrendWeek contains the results of a test or component in one week of a release, relative to its GA date.
+// WeeksToGA is 0 for the week starting at GA and -1 for the week before it.
+type ReleaseTrendWeek struct {
+	WeeksToGA        int                 `json:"weeks_to_ga"`
+	Start            time.Time           `json:"start"`
+	Runs             int                 `json:"runs"`
+	Passes           int                 `json:"passes"`
+	Flakes           int                 `json:"flakes"`
+	Failures         int                 `json:"failures"`
+	PassPercentage   *float64            `json:"pass_percentage"`
+	PassPercentageCI *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+}
+
+// ReleaseTrendSeries contains the weekly results of a test or component in one release. PassPercentage is over
+// all of its weeks, so releases can be compared at a glance.
+type ReleaseTrendSeries struct {
+	Release          string              `json:"release"`
+	GADate           time.Time           `json:"ga_date"`
+	Runs             int                 `json:"runs"`
+	PassPercentage   *float64            `json:"pass_percentage"`
+	PassPercentageCI *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+	Weeks            []ReleaseTrendWeek  `json:"weeks"`
+}
+
+// ReleaseTrend charts a test's or component's pass rate across releases, aligned by weeks to GA, oldest release
+// first.
+type ReleaseTrend struct {
+	Test        string               `json:"test,omitempty"`
+	Component   string               `json:"component,omitempty"`
+	WeeksBefore int                  `json:"weeks_before"`
+	WeeksAfter  int                  `json:"weeks_after"`
+	Releases    []ReleaseTrendSeries `json:"releases"`
+}
+
+// OwnerHealth rolls up the health of everything owned by one Owner variant value, e.g. eng or service-delivery.
+// Job pass rates compare the current period with the previous one. Jobs meet the SLO when their current pass
+// percentage is at least the SLO; jobs without current runs are not counted. Open regressions are those in
+// component readiness views limited to the owner's jobs.
+type OwnerHealth struct {
+	Owner string `json:"owner"`
+
+	Jobs                     int                 `json:"jobs"`
+	CurrentRuns              int                 `json:"current_runs"`
+	CurrentPassPercentage    *float64            `json:"current_pass_percentage"`
+	CurrentPassPercentageCI  *ConfidenceInterval `json:"current_pass_percentage_ci,omitempty" gorm:"-"`
+	PreviousRuns             int                 `json:"previous_runs"`
+	PreviousPassPercentage   *float64            `json:"previous_pass_percentage"`
+	PreviousPassPercentageCI *ConfidenceInterval `json:"previous_pass_percentage_ci,omitempty" gorm:"-"`
+	NetImprovement           *float64            `json:"net_improvement"`
+
+	JobsMeetingSLO  int      `json:"jobs_meeting_slo"`
+	SLOCompliance   *float64 `json:"slo_compliance"`
+	FailingSLOJobs  []string `json:"failing_slo_jobs"`
+	TestRuns        int      `json:"test_runs"`
+	FlakePercentage *float64 `json:"flake_percentage"`
+	OpenRegressions int      `json:"open_regressions"`
+	RegressionViews []string `json:"regression_views"`
+
+	// Links drill down into the owner's jobs and job pass rate over time.
+	Links map[string]string `json:"links"`
+}
+
+// OwnerReport is the health of each Owner variant value in a release.
+type OwnerReport struct {
+	Release  string        `json:"release"`
+	Start    time.Time     `json:"start"`
+	Boundary time.Time     `json:"boundary"`
+	End      time.Time     `json:"end"`
+	SLO      float64       `json:"slo"`
+	Owners   []OwnerHealth `json:"owners"`
+}
+
+// SecurityProfileJob is the pass rate of a job run in a security profile, with the pass rate of the baseline jobs
+// with the same configuration to compare against.
+type SecurityProfileJob struct {
+	Name                     string              `json:"name"`
+	Configuration            []string            `json:"configuration"`
+	Runs                     int                 `json:"runs"`
+	PassPercentage           *float64            `json:"pass_percentage"`
+	PassPercentageCI         *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+	BaselineRuns             int                 `json:"baseline_runs"`
+	BaselinePassPercentage   *float64            `json:"baseline_pass_percentage"`
+	BaselinePassPercentageCI *ConfidenceInterval `json:"baseline_pass_percentage_ci,omitempty" gorm:"-"`
+}
+
+// SecurityCoverageGap is a configuration tested by baseline jobs but by no job in a security profile.
+type SecurityCoverageGap struct {
+	Configuration []string `json:"configuration"`
+	BaselineJobs  []string `json:"baseline_jobs"`
+}
+
+// SecurityProfileTest is a test that passes less often in a security profile than in the baseline jobs.
+type SecurityProfileTest struct {
+	Name                     string              `json:"name"`
+	Runs                     int                 `json:"runs"`
+	PassPercentage           float64             `json:"pass_percentage"`
+	PassPercentageCI         *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+	BaselineRuns             int                 `json:"baseline_runs"`
+	BaselinePassPercentage   float64             `json:"baseline_pass_percentage"`
+	BaselinePassPercentageCI *ConfidenceInterval `json:"baseline_pass_percentage_ci,omitempty" gorm:"-"`
+}
+
+// SecurityProfileCompliance is the results of the jobs in one security profile, and how they cover the
+// configurations tested by the baseline jobs.
+type SecurityProfileCompliance struct {
+	// Profile is the SecurityMode variant value, e.g. fips.
+	Profile          string              `json:"profile"`
+	Jobs             int                 `json:"jobs"`
+	Runs             int                 `json:"runs"`
+	PassPercentage   *float64            `json:"pass_percentage"`
+	PassPercentageCI *ConfidenceInterval `json:"pass_percentage_ci,omitempty" gorm:"-"`
+	// BaselinePassPercentage is the pass percentage of the baseline jobs with the configurations the profile covers.
+	BaselinePassPercentage *float64 `json:"baseline_pass_percentage"`
+
+	BaselineConfigurations int                   `json:"baseline_configurations"`
+	CoveredConfigurations  int                   `json:"covered_configurations"`
+	CoveragePercentage     *float64              `json:"coverage_percentage"`
+	CoverageGaps           []SecurityCoverageGap `json:"coverage_gaps"`
+
+	JobResults []SecurityProfileJob  `json:"job_results"`
+	WorseTests []SecurityProfileTest `json:"worse_tests"`
+}
+
+// SecurityComplianceReport is the results of the security profile jobs in a release compared to the baseline jobs.
+type SecurityComplianceReport struct {
+	Release string    `json:"release"`
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	// ConfigurationVariants are the variants that make up a configuration when matching jobs to the baseline.
+	ConfigurationVariants []string                    `json:"configuration_variants"`
+	Profiles              []SecurityProfileCompliance `json:"profiles"`
+}
+
+// FingerprintCorrelation is how often job runs, or a test, failed with one value of a job run fingerprint
+// attribute, compared to the runs with the attribute's other values.
+type FingerprintCorrelation struct {
+	Attribute         string  `json:"attribute"`
+	Value             string  `json:"value"`
+	Runs              int     `json:"runs"`
+	Failures          int     `json:"failures"`
+	FailurePercentage float64 `json:"failure_percentage"`
+	// BaselineRuns, and failures, are the runs with any other value of the attribute.
+	BaselineRuns              int     `json:"baseline_runs"`
+	BaselineFailures          int     `json:"baseline_failures"`
+	BaselineFailurePercentage float64 `json:"baseline_failure_percentage"`
+	// Lift is FailurePercentage divided by BaselineFailurePercentage, nil when the baseline never failed.
+	Lift *float64 `json:"lift"`
+}
+
+// JobRunSummary is a job run found by searching its metadata, with links to investigate it further.
+type JobRunSummary struct {
+	ID              uint                `json:"id"`
+	Job             string              `json:"job"`
+	Release         string              `json:"release"`
+	Variants        pq.StringArray      `json:"variants" gorm:"type:text[]"`
+	Cluster         string              `json:"cluster"`
+	Timestamp       time.Time           `json:"timestamp"`
+	OverallResult   v1.JobOverallResult `json:"overall_result"`
+	Succeeded       bool                `json:"succeeded"`
+	TestFailures    int                 `json:"test_failures"`
+	Payload         string              `json:"payload,omitempty"`
+	PullRequestLink string              `json:"pull_request_link,omitempty"`
+	URL             string              `json:"url"`
+	ArtifactsURL    string              `json:"artifacts_url,omitempty" gorm:"-"`
+	// Links are API links to the run's risk analysis and intervals.
+	Links map[string]string `json:"links" gorm:"-"`
+}
+
+// LaneResults are the runs and passes of a lane, or one of its jobs, in the current and previous periods.
+type LaneResults struct {
+	CurrentRuns              int                 `json:"current_runs"`
+	CurrentPasses            int                 `json:"current_passes"`
+	CurrentPassPercentage    *float64            `json:"current_pass_percentage"`
+	CurrentPassPercentageCI  *ConfidenceInterval `json:"current_pass_percentage_ci,omitempty" gorm:"-"`
+	PreviousRuns             int                 `json:"previous_runs"`
+	PreviousPasses           int                 `json:"previous_passes"`
+	PreviousPassPercentage   *float64            `json:"previous_pass_percentage"`
+	PreviousPassPercentageCI *ConfidenceInterval `json:"previous_pass_percentage_ci,omitempty" gorm:"-"`
+	// NetImprovement is the change in pass percentage, nil unless both periods had runs.
+	NetImprovement *float64 `json:"net_improvement"`
+}
+
+// LaneJobResults are the results of one of a lane's jobs.
+type LaneJobResults struct {
+	Name    string `json:"name"`
+	Release string `json:"release"`
+	LaneResults
+}
+
+// LaneReport is the pass rate of a lane's jobs together, across releases, and of each job.
+type LaneReport struct {
+	Lane        string `json:"lane"`
+	Description string `json:"description"`
+	LaneResults
+	Jobs []LaneJobResults `json:"jobs"`
+}
+
+// EndpointParamsUsage is how often an endpoint was called with a set of normalized parameters.
+type EndpointParamsUsage struct {
+	Endpoint string `json:"-"`
+	Params   string `json:"params"`
+	Requests int    `json:"requests"`
+}
+
+// EndpointUsage summarizes how an API endpoint was used, from the access logs. CacheHitPercentage is only set for
+// cached endpoints.
+type EndpointUsage struct {
+	Endpoint           string                `json:"endpoint"`
+	Requests           int                   `json:"requests"`
+	ClientErrors       int                   `json:"client_errors"`
+	ServerErrors       int                   `json:"server_errors"`
+	P50LatencyMillis   float64               `json:"p50_latency_millis"`
+	P95LatencyMillis   float64               `json:"p95_latency_millis"`
+	CacheHitPercentage *float64              `json:"cache_hit_percentage"`
+	LastRequest        time.Time             `json:"last_request"`
+	TopParams          []EndpointParamsUsage `json:"top_params" gorm:"-"`
+}
+
+// VariantValueUsage is how many API requests filtered on a variant value, and how many jobs in the registry have it.
+type VariantValueUsage struct {
+	Key         string     `json:"-"`
+	Value       string     `json:"value"`
+	Requests    int        `json:"requests"`
+	LastRequest *time.Time `json:"last_request"`
+	Jobs        int        `json:"jobs" gorm:"-"`
+}
+
+// VariantUsage is how API requests used a variant key, from the access logs: Requests used it at all, FilterRequests
+// filtered on its values and GroupRequests grouped or compared by it. Keys no job in the registry has, but requests
+// asked for anyway, are not Registered, and are candidates for new variants.
+type VariantUsage struct {
+	Key            string              `json:"key"`
+	Registered     bool                `json:"registered" gorm:"-"`
+	Jobs           int                 `json:"jobs" gorm:"-"`
+	Requests       int                 `json:"requests"`
+	FilterRequests int                 `json:"filter_requests"`
+	GroupRequests  int                 `json:"group_requests"`
+	LastRequest    *time.Time          `json:"last_request"`
+	Values         []VariantValueUsage `json:"values" gorm:"-"`
+}
+
+// Kinds of records searched by /api/search, in the order their groups are listed when equally relevant.
+const (
+	SearchKindTests       = "tests"
+	SearchKindJobs        = "jobs"
+	SearchKindComponents  = "components"
+	SearchKindVariants    = "variants"
+	SearchKindRegressions = "regressions"
+	SearchKindIncidents   = "incidents"
+)
+
+// SearchKinds are all kinds of records searched by /api/search.
+var SearchKinds = []string{
+	SearchKindTests, SearchKindJobs, SearchKindComponents, SearchKindVariants, SearchKindRegressions, SearchKindIncidents,
+}
+
+// SearchResult is a record matching a search. Score ranks how closely its name matches, from 0 to 1, and Link is
+// the API endpoint to look it up with, if there is one.
+type SearchResult struct {
+	ID      uint    `json:"id,omitempty"`
+	Name    string  `json:"name"`
+	Release string  `json:"release,omitempty"`
+	Detail  string  `json:"detail,omitempty"`
+	Score   float64 `json:"score"`
+	Link    string  `json:"link,omitempty"`
+}
+
+// SearchGroup is the results of a search of one kind of record, most relevant first. More is set when there are
+// more results than were returned.
+type SearchGroup struct {
+	Kind    string         `json:"kind"`
+	Results []SearchResult `json:"results"`
+	More    bool           `json:"more"`
+}
+
+// SearchResults are the results of a search grouped by kind, the group with the most relevant result first.
+type SearchResults struct {
+	Query   string        `json:"query"`
+	Release string        `json:"release,omitempty"`
+	Groups  []SearchGroup `json:"groups"`
 }
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePassRateMode(t *testing.T) {
+	tests := []struct {
+		param     string
+		expected  PassRateMode
+		expectErr bool
+	}{
+		{param: "", expected: PassRateStrict},
+		{param: "strict", expected: PassRateStrict},
+		{param: "lenient", expected: PassRateLenient},
+		{param: "Lenient", expectErr: true},
+		{param: "relaxed", expectErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.param, func(t *testing.T) {
+			mode, err := ParsePassRateMode(tc.param)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, mode)
+		})
+	}
+}
@@ -0,0 +1,1085 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        v3.21.12
+// source: pkg/apis/grpc/v1/sippy.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PassRateMode determines how test failures are classified when calculating pass rates.
+type PassRateMode int32
+
+const (
+	// Unspecified is treated as strict.
+	PassRateMode_PASS_RATE_MODE_UNSPECIFIED PassRateMode = 0
+	// Only failures that passed on retry within the same job run are flakes.
+	PassRateMode_PASS_RATE_MODE_STRICT PassRateMode = 1
+	// Failures that passed in another job run against the same payload are also flakes.
+	PassRateMode_PASS_RATE_MODE_LENIENT PassRateMode = 2
+)
+
+// Enum value maps for PassRateMode.
+var (
+	PassRateMode_name = map[int32]string{
+		0: "PASS_RATE_MODE_UNSPECIFIED",
+		1: "PASS_RATE_MODE_STRICT",
+		2: "PASS_RATE_MODE_LENIENT",
+	}
+	PassRateMode_value = map[string]int32{
+		"PASS_RATE_MODE_UNSPECIFIED": 0,
+		"PASS_RATE_MODE_STRICT":      1,
+		"PASS_RATE_MODE_LENIENT":     2,
+	}
+)
+
+func (x PassRateMode) Enum() *PassRateMode {
+	p := new(PassRateMode)
+	*p = x
+	return p
+}
+
+func (x PassRateMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PassRateMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_pkg_apis_grpc_v1_sippy_proto_enumTypes[0].Descriptor()
+}
+
+func (PassRateMode) Type() protoreflect.EnumType {
+	return &file_pkg_apis_grpc_v1_sippy_proto_enumTypes[0]
+}
+
+func (x PassRateMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PassRateMode.Descriptor instead.
+func (PassRateMode) EnumDescriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{0}
+}
+
+type GetJobVariantsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobNames []string `protobuf:"bytes,1,rep,name=job_names,json=jobNames,proto3" json:"job_names,omitempty"`
+}
+
+func (x *GetJobVariantsRequest) Reset() {
+	*x = GetJobVariantsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetJobVariantsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobVariantsRequest) ProtoMessage() {}
+
+func (x *GetJobVariantsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobVariantsRequest.ProtoReflect.Descriptor instead.
+func (*GetJobVariantsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetJobVariantsRequest) GetJobNames() []string {
+	if x != nil {
+		return x.JobNames
+	}
+	return nil
+}
+
+type JobVariants struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	JobName string `protobuf:"bytes,1,opt,name=job_name,json=jobName,proto3" json:"job_name,omitempty"`
+	Release string `protobuf:"bytes,2,opt,name=release,proto3" json:"release,omitempty"`
+	// Variants are keyed by variant name, i.e. {"Platform": "aws"}.
+	Variants map[string]string `protobuf:"bytes,3,rep,name=variants,proto3" json:"variants,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *JobVariants) Reset() {
+	*x = JobVariants{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *JobVariants) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobVariants) ProtoMessage() {}
+
+func (x *JobVariants) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobVariants.ProtoReflect.Descriptor instead.
+func (*JobVariants) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *JobVariants) GetJobName() string {
+	if x != nil {
+		return x.JobName
+	}
+	return ""
+}
+
+func (x *JobVariants) GetRelease() string {
+	if x != nil {
+		return x.Release
+	}
+	return ""
+}
+
+func (x *JobVariants) GetVariants() map[string]string {
+	if x != nil {
+		return x.Variants
+	}
+	return nil
+}
+
+type GetJobVariantsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Jobs sippy doesn't know about are omitted.
+	Jobs []*JobVariants `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+}
+
+func (x *GetJobVariantsResponse) Reset() {
+	*x = GetJobVariantsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetJobVariantsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobVariantsResponse) ProtoMessage() {}
+
+func (x *GetJobVariantsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobVariantsResponse.ProtoReflect.Descriptor instead.
+func (*GetJobVariantsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetJobVariantsResponse) GetJobs() []*JobVariants {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type GetTestPassRatesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Release      string       `protobuf:"bytes,1,opt,name=release,proto3" json:"release,omitempty"`
+	TestNames    []string     `protobuf:"bytes,2,rep,name=test_names,json=testNames,proto3" json:"test_names,omitempty"`
+	PassRateMode PassRateMode `protobuf:"varint,3,opt,name=pass_rate_mode,json=passRateMode,proto3,enum=sippy.v1.PassRateMode" json:"pass_rate_mode,omitempty"`
+}
+
+func (x *GetTestPassRatesRequest) Reset() {
+	*x = GetTestPassRatesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTestPassRatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTestPassRatesRequest) ProtoMessage() {}
+
+func (x *GetTestPassRatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTestPassRatesRequest.ProtoReflect.Descriptor instead.
+func (*GetTestPassRatesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetTestPassRatesRequest) GetRelease() string {
+	if x != nil {
+		return x.Release
+	}
+	return ""
+}
+
+func (x *GetTestPassRatesRequest) GetTestNames() []string {
+	if x != nil {
+		return x.TestNames
+	}
+	return nil
+}
+
+func (x *GetTestPassRatesRequest) GetPassRateMode() PassRateMode {
+	if x != nil {
+		return x.PassRateMode
+	}
+	return PassRateMode_PASS_RATE_MODE_UNSPECIFIED
+}
+
+type TestPassRate struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TestName               string  `protobuf:"bytes,1,opt,name=test_name,json=testName,proto3" json:"test_name,omitempty"`
+	CurrentRuns            int32   `protobuf:"varint,2,opt,name=current_runs,json=currentRuns,proto3" json:"current_runs,omitempty"`
+	CurrentSuccesses       int32   `protobuf:"varint,3,opt,name=current_successes,json=currentSuccesses,proto3" json:"current_successes,omitempty"`
+	CurrentFailures        int32   `protobuf:"varint,4,opt,name=current_failures,json=currentFailures,proto3" json:"current_failures,omitempty"`
+	CurrentFlakes          int32   `protobuf:"varint,5,opt,name=current_flakes,json=currentFlakes,proto3" json:"current_flakes,omitempty"`
+	CurrentPassPercentage  float64 `protobuf:"fixed64,6,opt,name=current_pass_percentage,json=currentPassPercentage,proto3" json:"current_pass_percentage,omitempty"`
+	PreviousRuns           int32   `protobuf:"varint,7,opt,name=previous_runs,json=previousRuns,proto3" json:"previous_runs,omitempty"`
+	PreviousSuccesses      int32   `protobuf:"varint,8,opt,name=previous_successes,json=previousSuccesses,proto3" json:"previous_successes,omitempty"`
+	PreviousFailures       int32   `protobuf:"varint,9,opt,name=previous_failures,json=previousFailures,proto3" json:"previous_failures,omitempty"`
+	PreviousFlakes         int32   `protobuf:"varint,10,opt,name=previous_flakes,json=previousFlakes,proto3" json:"previous_flakes,omitempty"`
+	PreviousPassPercentage float64 `protobuf:"fixed64,11,opt,name=previous_pass_percentage,json=previousPassPercentage,proto3" json:"previous_pass_percentage,omitempty"`
+}
+
+func (x *TestPassRate) Reset() {
+	*x = TestPassRate{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestPassRate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestPassRate) ProtoMessage() {}
+
+func (x *TestPassRate) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestPassRate.ProtoReflect.Descriptor instead.
+func (*TestPassRate) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *TestPassRate) GetTestName() string {
+	if x != nil {
+		return x.TestName
+	}
+	return ""
+}
+
+func (x *TestPassRate) GetCurrentRuns() int32 {
+	if x != nil {
+		return x.CurrentRuns
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetCurrentSuccesses() int32 {
+	if x != nil {
+		return x.CurrentSuccesses
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetCurrentFailures() int32 {
+	if x != nil {
+		return x.CurrentFailures
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetCurrentFlakes() int32 {
+	if x != nil {
+		return x.CurrentFlakes
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetCurrentPassPercentage() float64 {
+	if x != nil {
+		return x.CurrentPassPercentage
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetPreviousRuns() int32 {
+	if x != nil {
+		return x.PreviousRuns
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetPreviousSuccesses() int32 {
+	if x != nil {
+		return x.PreviousSuccesses
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetPreviousFailures() int32 {
+	if x != nil {
+		return x.PreviousFailures
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetPreviousFlakes() int32 {
+	if x != nil {
+		return x.PreviousFlakes
+	}
+	return 0
+}
+
+func (x *TestPassRate) GetPreviousPassPercentage() float64 {
+	if x != nil {
+		return x.PreviousPassPercentage
+	}
+	return 0
+}
+
+type GetTestPassRatesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Tests with no runs in the release are omitted.
+	Tests []*TestPassRate `protobuf:"bytes,1,rep,name=tests,proto3" json:"tests,omitempty"`
+}
+
+func (x *GetTestPassRatesResponse) Reset() {
+	*x = GetTestPassRatesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTestPassRatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTestPassRatesResponse) ProtoMessage() {}
+
+func (x *GetTestPassRatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTestPassRatesResponse.ProtoReflect.Descriptor instead.
+func (*GetTestPassRatesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetTestPassRatesResponse) GetTests() []*TestPassRate {
+	if x != nil {
+		return x.Tests
+	}
+	return nil
+}
+
+type AnalyzeJobRunRiskRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The ID of a job run already imported by sippy.
+	ProwJobRunId int64 `protobuf:"varint,1,opt,name=prow_job_run_id,json=prowJobRunId,proto3" json:"prow_job_run_id,omitempty"`
+}
+
+func (x *AnalyzeJobRunRiskRequest) Reset() {
+	*x = AnalyzeJobRunRiskRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeJobRunRiskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeJobRunRiskRequest) ProtoMessage() {}
+
+func (x *AnalyzeJobRunRiskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeJobRunRiskRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeJobRunRiskRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *AnalyzeJobRunRiskRequest) GetProwJobRunId() int64 {
+	if x != nil {
+		return x.ProwJobRunId
+	}
+	return 0
+}
+
+type RiskLevel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Name is a human readable name for the risk level, i.e. High.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Level is a numerical risk level, higher implies more risk.
+	Level int32 `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+}
+
+func (x *RiskLevel) Reset() {
+	*x = RiskLevel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RiskLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RiskLevel) ProtoMessage() {}
+
+func (x *RiskLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RiskLevel.ProtoReflect.Descriptor instead.
+func (*RiskLevel) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RiskLevel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RiskLevel) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+type TestRisk struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TestName              string     `protobuf:"bytes,1,opt,name=test_name,json=testName,proto3" json:"test_name,omitempty"`
+	Risk                  *RiskLevel `protobuf:"bytes,2,opt,name=risk,proto3" json:"risk,omitempty"`
+	Reasons               []string   `protobuf:"bytes,3,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	CurrentRuns           int32      `protobuf:"varint,4,opt,name=current_runs,json=currentRuns,proto3" json:"current_runs,omitempty"`
+	CurrentPasses         int32      `protobuf:"varint,5,opt,name=current_passes,json=currentPasses,proto3" json:"current_passes,omitempty"`
+	CurrentPassPercentage float64    `protobuf:"fixed64,6,opt,name=current_pass_percentage,json=currentPassPercentage,proto3" json:"current_pass_percentage,omitempty"`
+}
+
+func (x *TestRisk) Reset() {
+	*x = TestRisk{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TestRisk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TestRisk) ProtoMessage() {}
+
+func (x *TestRisk) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TestRisk.ProtoReflect.Descriptor instead.
+func (*TestRisk) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TestRisk) GetTestName() string {
+	if x != nil {
+		return x.TestName
+	}
+	return ""
+}
+
+func (x *TestRisk) GetRisk() *RiskLevel {
+	if x != nil {
+		return x.Risk
+	}
+	return nil
+}
+
+func (x *TestRisk) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *TestRisk) GetCurrentRuns() int32 {
+	if x != nil {
+		return x.CurrentRuns
+	}
+	return 0
+}
+
+func (x *TestRisk) GetCurrentPasses() int32 {
+	if x != nil {
+		return x.CurrentPasses
+	}
+	return 0
+}
+
+func (x *TestRisk) GetCurrentPassPercentage() float64 {
+	if x != nil {
+		return x.CurrentPassPercentage
+	}
+	return 0
+}
+
+type AnalyzeJobRunRiskResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ProwJobName  string      `protobuf:"bytes,1,opt,name=prow_job_name,json=prowJobName,proto3" json:"prow_job_name,omitempty"`
+	ProwJobRunId uint64      `protobuf:"varint,2,opt,name=prow_job_run_id,json=prowJobRunId,proto3" json:"prow_job_run_id,omitempty"`
+	Release      string      `protobuf:"bytes,3,opt,name=release,proto3" json:"release,omitempty"`
+	OverallRisk  *RiskLevel  `protobuf:"bytes,4,opt,name=overall_risk,json=overallRisk,proto3" json:"overall_risk,omitempty"`
+	Reasons      []string    `protobuf:"bytes,5,rep,name=reasons,proto3" json:"reasons,omitempty"`
+	Tests        []*TestRisk `protobuf:"bytes,6,rep,name=tests,proto3" json:"tests,omitempty"`
+}
+
+func (x *AnalyzeJobRunRiskResponse) Reset() {
+	*x = AnalyzeJobRunRiskResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AnalyzeJobRunRiskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeJobRunRiskResponse) ProtoMessage() {}
+
+func (x *AnalyzeJobRunRiskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_apis_grpc_v1_sippy_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeJobRunRiskResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeJobRunRiskResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AnalyzeJobRunRiskResponse) GetProwJobName() string {
+	if x != nil {
+		return x.ProwJobName
+	}
+	return ""
+}
+
+func (x *AnalyzeJobRunRiskResponse) GetProwJobRunId() uint64 {
+	if x != nil {
+		return x.ProwJobRunId
+	}
+	return 0
+}
+
+func (x *AnalyzeJobRunRiskResponse) GetRelease() string {
+	if x != nil {
+		return x.Release
+	}
+	return ""
+}
+
+func (x *AnalyzeJobRunRiskResponse) GetOverallRisk() *RiskLevel {
+	if x != nil {
+		return x.OverallRisk
+	}
+	return nil
+}
+
+func (x *AnalyzeJobRunRiskResponse) GetReasons() []string {
+	if x != nil {
+		return x.Reasons
+	}
+	return nil
+}
+
+func (x *AnalyzeJobRunRiskResponse) GetTests() []*TestRisk {
+	if x != nil {
+		return x.Tests
+	}
+	return nil
+}
+
+var File_pkg_apis_grpc_v1_sippy_proto protoreflect.FileDescriptor
+
+var file_pkg_apis_grpc_v1_sippy_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x73, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f,
+	0x76, 0x31, 0x2f, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x08,
+	0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x22, 0x34, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4a,
+	0x6f, 0x62, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6a, 0x6f, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x6a, 0x6f, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0xc0,
+	0x01, 0x0a, 0x0b, 0x4a, 0x6f, 0x62, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x12, 0x19,
+	0x0a, 0x08, 0x6a, 0x6f, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6a, 0x6f, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6c,
+	0x65, 0x61, 0x73, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x08, 0x76, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31,
+	0x2e, 0x4a, 0x6f, 0x62, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x2e, 0x56, 0x61, 0x72,
+	0x69, 0x61, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x76, 0x61, 0x72, 0x69,
+	0x61, 0x6e, 0x74, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x43, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x56, 0x61, 0x72, 0x69, 0x61,
+	0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x04, 0x6a,
+	0x6f, 0x62, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x73, 0x69, 0x70, 0x70,
+	0x79, 0x2e, 0x76, 0x31, 0x2e, 0x4a, 0x6f, 0x62, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73,
+	0x52, 0x04, 0x6a, 0x6f, 0x62, 0x73, 0x22, 0x90, 0x01, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x54, 0x65,
+	0x73, 0x74, 0x50, 0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a,
+	0x74, 0x65, 0x73, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09,
+	0x52, 0x09, 0x74, 0x65, 0x73, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x3c, 0x0a, 0x0e, 0x70,
+	0x61, 0x73, 0x73, 0x5f, 0x72, 0x61, 0x74, 0x65, 0x5f, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x50,
+	0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x0c, 0x70, 0x61, 0x73,
+	0x73, 0x52, 0x61, 0x74, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x22, 0xe9, 0x03, 0x0a, 0x0c, 0x54, 0x65,
+	0x73, 0x74, 0x50, 0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x65,
+	0x73, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74,
+	0x65, 0x73, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x74, 0x5f, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x63,
+	0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x52, 0x75, 0x6e, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x10, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x75,
+	0x63, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x12, 0x29, 0x0a, 0x10, 0x63, 0x75, 0x72, 0x72, 0x65,
+	0x6e, 0x74, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0f, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72,
+	0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x66, 0x6c,
+	0x61, 0x6b, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x74, 0x46, 0x6c, 0x61, 0x6b, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x17, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x15, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x74, 0x50, 0x61, 0x73, 0x73, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67,
+	0x65, 0x12, 0x23, 0x0a, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x72, 0x75,
+	0x6e, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0c, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f,
+	0x75, 0x73, 0x52, 0x75, 0x6e, 0x73, 0x12, 0x2d, 0x0a, 0x12, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f,
+	0x75, 0x73, 0x5f, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x11, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x53, 0x75, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75,
+	0x73, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x10, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72,
+	0x65, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x66,
+	0x6c, 0x61, 0x6b, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0e, 0x70, 0x72, 0x65,
+	0x76, 0x69, 0x6f, 0x75, 0x73, 0x46, 0x6c, 0x61, 0x6b, 0x65, 0x73, 0x12, 0x38, 0x0a, 0x18, 0x70,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x5f, 0x70, 0x65, 0x72,
+	0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x16, 0x70,
+	0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x50, 0x61, 0x73, 0x73, 0x50, 0x65, 0x72, 0x63, 0x65,
+	0x6e, 0x74, 0x61, 0x67, 0x65, 0x22, 0x48, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x54, 0x65, 0x73, 0x74,
+	0x50, 0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x2c, 0x0a, 0x05, 0x74, 0x65, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x16, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x73, 0x74,
+	0x50, 0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x52, 0x05, 0x74, 0x65, 0x73, 0x74, 0x73, 0x22,
+	0x41, 0x0a, 0x18, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65, 0x4a, 0x6f, 0x62, 0x52, 0x75, 0x6e,
+	0x52, 0x69, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x25, 0x0a, 0x0f, 0x70,
+	0x72, 0x6f, 0x77, 0x5f, 0x6a, 0x6f, 0x62, 0x5f, 0x72, 0x75, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x77, 0x4a, 0x6f, 0x62, 0x52, 0x75, 0x6e,
+	0x49, 0x64, 0x22, 0x35, 0x0a, 0x09, 0x52, 0x69, 0x73, 0x6b, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x6c, 0x65, 0x76, 0x65, 0x6c, 0x22, 0xec, 0x01, 0x0a, 0x08, 0x54, 0x65,
+	0x73, 0x74, 0x52, 0x69, 0x73, 0x6b, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x65, 0x73, 0x74, 0x5f, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x65, 0x73, 0x74, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x04, 0x72, 0x69, 0x73, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x69, 0x73,
+	0x6b, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x04, 0x72, 0x69, 0x73, 0x6b, 0x12, 0x18, 0x0a, 0x07,
+	0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x74, 0x5f, 0x72, 0x75, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x63, 0x75,
+	0x72, 0x72, 0x65, 0x6e, 0x74, 0x52, 0x75, 0x6e, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x75, 0x72,
+	0x72, 0x65, 0x6e, 0x74, 0x5f, 0x70, 0x61, 0x73, 0x73, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0d, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x61, 0x73, 0x73, 0x65, 0x73,
+	0x12, 0x36, 0x0a, 0x17, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x70, 0x61, 0x73, 0x73,
+	0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x15, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x50, 0x61, 0x73, 0x73, 0x50, 0x65,
+	0x72, 0x63, 0x65, 0x6e, 0x74, 0x61, 0x67, 0x65, 0x22, 0xfc, 0x01, 0x0a, 0x19, 0x41, 0x6e, 0x61,
+	0x6c, 0x79, 0x7a, 0x65, 0x4a, 0x6f, 0x62, 0x52, 0x75, 0x6e, 0x52, 0x69, 0x73, 0x6b, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x22, 0x0a, 0x0d, 0x70, 0x72, 0x6f, 0x77, 0x5f, 0x6a,
+	0x6f, 0x62, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x70,
+	0x72, 0x6f, 0x77, 0x4a, 0x6f, 0x62, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0f, 0x70, 0x72,
+	0x6f, 0x77, 0x5f, 0x6a, 0x6f, 0x62, 0x5f, 0x72, 0x75, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x70, 0x72, 0x6f, 0x77, 0x4a, 0x6f, 0x62, 0x52, 0x75, 0x6e, 0x49,
+	0x64, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x36, 0x0a, 0x0c, 0x6f,
+	0x76, 0x65, 0x72, 0x61, 0x6c, 0x6c, 0x5f, 0x72, 0x69, 0x73, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x13, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x69, 0x73,
+	0x6b, 0x4c, 0x65, 0x76, 0x65, 0x6c, 0x52, 0x0b, 0x6f, 0x76, 0x65, 0x72, 0x61, 0x6c, 0x6c, 0x52,
+	0x69, 0x73, 0x6b, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x73, 0x12, 0x28, 0x0a,
+	0x05, 0x74, 0x65, 0x73, 0x74, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x73,
+	0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x73, 0x74, 0x52, 0x69, 0x73, 0x6b,
+	0x52, 0x05, 0x74, 0x65, 0x73, 0x74, 0x73, 0x2a, 0x65, 0x0a, 0x0c, 0x50, 0x61, 0x73, 0x73, 0x52,
+	0x61, 0x74, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x1e, 0x0a, 0x1a, 0x50, 0x41, 0x53, 0x53, 0x5f,
+	0x52, 0x41, 0x54, 0x45, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
+	0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x19, 0x0a, 0x15, 0x50, 0x41, 0x53, 0x53, 0x5f,
+	0x52, 0x41, 0x54, 0x45, 0x5f, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x53, 0x54, 0x52, 0x49, 0x43, 0x54,
+	0x10, 0x01, 0x12, 0x1a, 0x0a, 0x16, 0x50, 0x41, 0x53, 0x53, 0x5f, 0x52, 0x41, 0x54, 0x45, 0x5f,
+	0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x4c, 0x45, 0x4e, 0x49, 0x45, 0x4e, 0x54, 0x10, 0x02, 0x32, 0x95,
+	0x02, 0x0a, 0x05, 0x53, 0x69, 0x70, 0x70, 0x79, 0x12, 0x53, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x4a,
+	0x6f, 0x62, 0x56, 0x61, 0x72, 0x69, 0x61, 0x6e, 0x74, 0x73, 0x12, 0x1f, 0x2e, 0x73, 0x69, 0x70,
+	0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x56, 0x61, 0x72, 0x69,
+	0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20, 0x2e, 0x73, 0x69,
+	0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4a, 0x6f, 0x62, 0x56, 0x61, 0x72,
+	0x69, 0x61, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59, 0x0a,
+	0x10, 0x47, 0x65, 0x74, 0x54, 0x65, 0x73, 0x74, 0x50, 0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65,
+	0x73, 0x12, 0x21, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x54, 0x65, 0x73, 0x74, 0x50, 0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x54, 0x65, 0x73, 0x74, 0x50, 0x61, 0x73, 0x73, 0x52, 0x61, 0x74, 0x65, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5c, 0x0a, 0x11, 0x41, 0x6e, 0x61, 0x6c,
+	0x79, 0x7a, 0x65, 0x4a, 0x6f, 0x62, 0x52, 0x75, 0x6e, 0x52, 0x69, 0x73, 0x6b, 0x12, 0x22, 0x2e,
+	0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61, 0x6c, 0x79, 0x7a, 0x65,
+	0x4a, 0x6f, 0x62, 0x52, 0x75, 0x6e, 0x52, 0x69, 0x73, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x23, 0x2e, 0x73, 0x69, 0x70, 0x70, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x6e, 0x61,
+	0x6c, 0x79, 0x7a, 0x65, 0x4a, 0x6f, 0x62, 0x52, 0x75, 0x6e, 0x52, 0x69, 0x73, 0x6b, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6f, 0x70, 0x65, 0x6e, 0x73, 0x68, 0x69, 0x66, 0x74, 0x2f, 0x73,
+	0x69, 0x70, 0x70, 0x79, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x61, 0x70, 0x69, 0x73, 0x2f, 0x67, 0x72,
+	0x70, 0x63, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_apis_grpc_v1_sippy_proto_rawDescOnce sync.Once
+	file_pkg_apis_grpc_v1_sippy_proto_rawDescData = file_pkg_apis_grpc_v1_sippy_proto_rawDesc
+)
+
+func file_pkg_apis_grpc_v1_sippy_proto_rawDescGZIP() []byte {
+	file_pkg_apis_grpc_v1_sippy_proto_rawDescOnce.Do(func() {
+		file_pkg_apis_grpc_v1_sippy_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_apis_grpc_v1_sippy_proto_rawDescData)
+	})
+	return file_pkg_apis_grpc_v1_sippy_proto_rawDescData
+}
+
+var file_pkg_apis_grpc_v1_sippy_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_pkg_apis_grpc_v1_sippy_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_pkg_apis_grpc_v1_sippy_proto_goTypes = []interface{}{
+	(PassRateMode)(0),                 // 0: sippy.v1.PassRateMode
+	(*GetJobVariantsRequest)(nil),     // 1: sippy.v1.GetJobVariantsRequest
+	(*JobVariants)(nil),               // 2: sippy.v1.JobVariants
+	(*GetJobVariantsResponse)(nil),    // 3: sippy.v1.GetJobVariantsResponse
+	(*GetTestPassRatesRequest)(nil),   // 4: sippy.v1.GetTestPassRatesRequest
+	(*TestPassRate)(nil),              // 5: sippy.v1.TestPassRate
+	(*GetTestPassRatesResponse)(nil),  // 6: sippy.v1.GetTestPassRatesResponse
+	(*AnalyzeJobRunRiskRequest)(nil),  // 7: sippy.v1.AnalyzeJobRunRiskRequest
+	(*RiskLevel)(nil),                 // 8: sippy.v1.RiskLevel
+	(*TestRisk)(nil),                  // 9: sippy.v1.TestRisk
+	(*AnalyzeJobRunRiskResponse)(nil), // 10: sippy.v1.AnalyzeJobRunRiskResponse
+	nil,                               // 11: sippy.v1.JobVariants.VariantsEntry
+}
+var file_pkg_apis_grpc_v1_sippy_proto_depIdxs = []int32{
+	11, // 0: sippy.v1.JobVariants.variants:type_name -> sippy.v1.JobVariants.VariantsEntry
+	2,  // 1: sippy.v1.GetJobVariantsResponse.jobs:type_name -> sippy.v1.JobVariants
+	0,  // 2: sippy.v1.GetTestPassRatesRequest.pass_rate_mode:type_name -> sippy.v1.PassRateMode
+	5,  // 3: sippy.v1.GetTestPassRatesResponse.tests:type_name -> sippy.v1.TestPassRate
+	8,  // 4: sippy.v1.TestRisk.risk:type_name -> sippy.v1.RiskLevel
+	8,  // 5: sippy.v1.AnalyzeJobRunRiskResponse.overall_risk:type_name -> sippy.v1.RiskLevel
+	9,  // 6: sippy.v1.AnalyzeJobRunRiskResponse.tests:type_name -> sippy.v1.TestRisk
+	1,  // 7: sippy.v1.Sippy.GetJobVariants:input_type -> sippy.v1.GetJobVariantsRequest
+	4,  // 8: sippy.v1.Sippy.GetTestPassRates:input_type -> sippy.v1.GetTestPassRatesRequest
+	7,  // 9: sippy.v1.Sippy.AnalyzeJobRunRisk:input_type -> sippy.v1.AnalyzeJobRunRiskRequest
+	3,  // 10: sippy.v1.Sippy.GetJobVariants:output_type -> sippy.v1.GetJobVariantsResponse
+	6,  // 11: sippy.v1.Sippy.GetTestPassRates:output_type -> sippy.v1.GetTestPassRatesResponse
+	10, // 12: sippy.v1.Sippy.AnalyzeJobRunRisk:output_type -> sippy.v1.AnalyzeJobRunRiskResponse
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_pkg_apis_grpc_v1_sippy_proto_init() }
+func file_pkg_apis_grpc_v1_sippy_proto_init() {
+	if File_pkg_apis_grpc_v1_sippy_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetJobVariantsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*JobVariants); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetJobVariantsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTestPassRatesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TestPassRate); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTestPassRatesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyzeJobRunRiskRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RiskLevel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TestRisk); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_apis_grpc_v1_sippy_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AnalyzeJobRunRiskResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_pkg_apis_grpc_v1_sippy_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pkg_apis_grpc_v1_sippy_proto_goTypes,
+		DependencyIndexes: file_pkg_apis_grpc_v1_sippy_proto_depIdxs,
+		EnumInfos:         file_pkg_apis_grpc_v1_sippy_proto_enumTypes,
+		MessageInfos:      file_pkg_apis_grpc_v1_sippy_proto_msgTypes,
+	}.Build()
+	File_pkg_apis_grpc_v1_sippy_proto = out.File
+	file_pkg_apis_grpc_v1_sippy_proto_rawDesc = nil
+	file_pkg_apis_grpc_v1_sippy_proto_goTypes = nil
+	file_pkg_apis_grpc_v1_sippy_proto_depIdxs = nil
+}
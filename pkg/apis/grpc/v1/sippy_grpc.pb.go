@@ -0,0 +1,189 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v3.21.12
+// source: pkg/apis/grpc/v1/sippy.proto
+
+package v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Sippy_GetJobVariants_FullMethodName    = "/sippy.v1.Sippy/GetJobVariants"
+	Sippy_GetTestPassRates_FullMethodName  = "/sippy.v1.Sippy/GetTestPassRates"
+	Sippy_AnalyzeJobRunRisk_FullMethodName = "/sippy.v1.Sippy/AnalyzeJobRunRisk"
+)
+
+// SippyClient is the client API for Sippy service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SippyClient interface {
+	// GetJobVariants returns the variants of each named job that sippy knows about.
+	GetJobVariants(ctx context.Context, in *GetJobVariantsRequest, opts ...grpc.CallOption) (*GetJobVariantsResponse, error)
+	// GetTestPassRates returns the pass rates of the named tests over the last 7 days, and the 7 days before that.
+	GetTestPassRates(ctx context.Context, in *GetTestPassRatesRequest, opts ...grpc.CallOption) (*GetTestPassRatesResponse, error)
+	// AnalyzeJobRunRisk returns the risk that a job run's failures are regressions rather than known flakes.
+	AnalyzeJobRunRisk(ctx context.Context, in *AnalyzeJobRunRiskRequest, opts ...grpc.CallOption) (*AnalyzeJobRunRiskResponse, error)
+}
+
+type sippyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSippyClient(cc grpc.ClientConnInterface) SippyClient {
+	return &sippyClient{cc}
+}
+
+func (c *sippyClient) GetJobVariants(ctx context.Context, in *GetJobVariantsRequest, opts ...grpc.CallOption) (*GetJobVariantsResponse, error) {
+	out := new(GetJobVariantsResponse)
+	err := c.cc.Invoke(ctx, Sippy_GetJobVariants_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sippyClient) GetTestPassRates(ctx context.Context, in *GetTestPassRatesRequest, opts ...grpc.CallOption) (*GetTestPassRatesResponse, error) {
+	out := new(GetTestPassRatesResponse)
+	err := c.cc.Invoke(ctx, Sippy_GetTestPassRates_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sippyClient) AnalyzeJobRunRisk(ctx context.Context, in *AnalyzeJobRunRiskRequest, opts ...grpc.CallOption) (*AnalyzeJobRunRiskResponse, error) {
+	out := new(AnalyzeJobRunRiskResponse)
+	err := c.cc.Invoke(ctx, Sippy_AnalyzeJobRunRisk_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SippyServer is the server API for Sippy service.
+// All implementations must embed UnimplementedSippyServer
+// for forward compatibility
+type SippyServer interface {
+	// GetJobVariants returns the variants of each named job that sippy knows about.
+	GetJobVariants(context.Context, *GetJobVariantsRequest) (*GetJobVariantsResponse, error)
+	// GetTestPassRates returns the pass rates of the named tests over the last 7 days, and the 7 days before that.
+	GetTestPassRates(context.Context, *GetTestPassRatesRequest) (*GetTestPassRatesResponse, error)
+	// AnalyzeJobRunRisk returns the risk that a job run's failures are regressions rather than known flakes.
+	AnalyzeJobRunRisk(context.Context, *AnalyzeJobRunRiskRequest) (*AnalyzeJobRunRiskResponse, error)
+	mustEmbedUnimplementedSippyServer()
+}
+
+// UnimplementedSippyServer must be embedded to have forward compatible implementations.
+type UnimplementedSippyServer struct {
+}
+
+func (UnimplementedSippyServer) GetJobVariants(context.Context, *GetJobVariantsRequest) (*GetJobVariantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJobVariants not implemented")
+}
+func (UnimplementedSippyServer) GetTestPassRates(context.Context, *GetTestPassRatesRequest) (*GetTestPassRatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTestPassRates not implemented")
+}
+func (UnimplementedSippyServer) AnalyzeJobRunRisk(context.Context, *AnalyzeJobRunRiskRequest) (*AnalyzeJobRunRiskResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeJobRunRisk not implemented")
+}
+func (UnimplementedSippyServer) mustEmbedUnimplementedSippyServer() {}
+
+// UnsafeSippyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SippyServer will
+// result in compilation errors.
+type UnsafeSippyServer interface {
+	mustEmbedUnimplementedSippyServer()
+}
+
+func RegisterSippyServer(s grpc.ServiceRegistrar, srv SippyServer) {
+	s.RegisterService(&Sippy_ServiceDesc, srv)
+}
+
+func _Sippy_GetJobVariants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobVariantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SippyServer).GetJobVariants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sippy_GetJobVariants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SippyServer).GetJobVariants(ctx, req.(*GetJobVariantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sippy_GetTestPassRates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTestPassRatesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SippyServer).GetTestPassRates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sippy_GetTestPassRates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SippyServer).GetTestPassRates(ctx, req.(*GetTestPassRatesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sippy_AnalyzeJobRunRisk_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeJobRunRiskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SippyServer).AnalyzeJobRunRisk(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sippy_AnalyzeJobRunRisk_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SippyServer).AnalyzeJobRunRisk(ctx, req.(*AnalyzeJobRunRiskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Sippy_ServiceDesc is the grpc.ServiceDesc for Sippy service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sippy_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sippy.v1.Sippy",
+	HandlerType: (*SippyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetJobVariants",
+			Handler:    _Sippy_GetJobVariants_Handler,
+		},
+		{
+			MethodName: "GetTestPassRates",
+			Handler:    _Sippy_GetTestPassRates_Handler,
+		},
+		{
+			MethodName: "AnalyzeJobRunRisk",
+			Handler:    _Sippy_AnalyzeJobRunRisk_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/apis/grpc/v1/sippy.proto",
+}
@@ -10,19 +10,20 @@ type Issue struct {
 }
 
 type Fields struct {
-	IssueType      IssueType `json:"issuetype"`
-	Project        Project   `json:"project"`
-	Watches        Watches   `json:"watches"`
-	Created        string    `json:"created"`
-	ResolutionDate string    `json:"resolutiondate"`
-	Priority       Priority  `json:"priority"`
-	Labels         []string  `json:"labels"`
-	Updated        string    `json:"updated"`
-	Status         Status    `json:"status"`
-	Description    string    `json:"description"`
-	Summary        string    `json:"summary"`
-	Creator        User      `json:"creator"`
-	Reporter       User      `json:"reporter"`
+	IssueType      IssueType   `json:"issuetype"`
+	Project        Project     `json:"project"`
+	Watches        Watches     `json:"watches"`
+	Created        string      `json:"created"`
+	ResolutionDate string      `json:"resolutiondate"`
+	Priority       Priority    `json:"priority"`
+	Labels         []string    `json:"labels"`
+	Updated        string      `json:"updated"`
+	Status         Status      `json:"status"`
+	Description    string      `json:"description"`
+	Summary        string      `json:"summary"`
+	Creator        User        `json:"creator"`
+	Reporter       User        `json:"reporter"`
+	Components     []Component `json:"components"`
 }
 
 type IssueType struct {
@@ -64,6 +64,28 @@ type ProwJobSpec struct {
 
 	// Refs is the code under test, determined at runtime by Prow itself
 	Refs *Refs `json:"refs,omitempty"`
+
+	// PodSpec is the pod the job runs in, only the parts sippy reads are decoded
+	PodSpec *PodSpec `json:"pod_spec,omitempty"`
+}
+
+// PodSpec is the subset of a Kubernetes pod spec describing what a job's containers run.
+type PodSpec struct {
+	Containers []Container `json:"containers,omitempty"`
+}
+
+// Container is the subset of a Kubernetes container with its command line and environment.
+type Container struct {
+	Command []string `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Env     []EnvVar `json:"env,omitempty"`
+}
+
+// EnvVar is an environment variable set in a container. Values from references to secrets or config maps are not
+// decoded.
+type EnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
 }
 
 type ProwJobStatus struct {
@@ -0,0 +1,154 @@
+package apischema
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "record compatible changes to the response schema snapshots")
+
+const snapshotDir = "testdata/schemas"
+
+// snapshotFile names a snapshot after the endpoint path without the /api prefix, with dots for slashes, as the
+// fake server's fixtures are.
+func snapshotFile(endpoint string) string {
+	name := strings.NewReplacer("/", ".", "{", "", "}", "").Replace(strings.TrimPrefix(endpoint, "/api/"))
+	return filepath.Join(snapshotDir, name+".json")
+}
+
+// TestResponseSchemasCompatible fails when a public endpoint's response changed in a way that could break its
+// consumers. Compatible changes, like added fields, must be recorded with go test ./pkg/apischema -update.
+func TestResponseSchemasCompatible(t *testing.T) {
+	snapshots := map[string]bool{}
+	for _, e := range Endpoints {
+		file := snapshotFile(e.Path)
+		snapshots[filepath.Base(file)] = true
+		t.Run(e.Path, func(t *testing.T) {
+			current := ForValue(e.Response)
+			data, err := os.ReadFile(file)
+			if os.IsNotExist(err) {
+				if !*update {
+					t.Fatalf("no schema snapshot for %s, record one with go test ./pkg/apischema -update", e.Path)
+				}
+				writeSnapshot(t, file, current)
+				return
+			}
+			require.NoError(t, err)
+
+			old := &Schema{}
+			require.NoError(t, json.Unmarshal(data, old))
+			if problems := Incompatibilities(old, current); len(problems) > 0 {
+				t.Fatalf("the %s response changed in ways that could break consumers, add a new version of the "+
+					"endpoint under /api/v2 instead:\n%s", e.Path, strings.Join(problems, "\n"))
+			}
+			if *update {
+				writeSnapshot(t, file, current)
+			} else if !reflect.DeepEqual(old, current) {
+				t.Fatalf("the %s response changed compatibly, record it with go test ./pkg/apischema -update", e.Path)
+			}
+		})
+	}
+
+	entries, err := os.ReadDir(snapshotDir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.True(t, snapshots[e.Name()], "snapshot %s has no endpoint, removing an endpoint breaks its consumers", e.Name())
+	}
+}
+
+func writeSnapshot(t *testing.T, file string, s *Schema) {
+	data, err := json.MarshalIndent(s, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(file, append(data, '\n'), 0o644))
+}
+
+type testNode struct {
+	Name     string      `json:"name"`
+	Children []*testNode `json:"children,omitempty"`
+}
+
+type testEmbedded struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type testResponse struct {
+	testEmbedded
+	Name      string            `json:"display_name"`
+	Count     *int              `json:"count"`
+	Ratio     float64           `json:"ratio"`
+	When      time.Time         `json:"when"`
+	Labels    map[string]string `json:"labels"`
+	Tree      testNode          `json:"tree"`
+	Big       int64             `json:"big,string"`
+	Untyped   interface{}       `json:"untyped"`
+	Internal  string            `json:"-"`
+	unexposed string
+}
+
+func TestForValue(t *testing.T) {
+	s := ForValue(testResponse{})
+	assert.Equal(t, TypeObject, s.Type)
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, []string{"id", "name", "display_name", "count", "ratio", "when", "labels", "tree", "big", "untyped"}, names)
+
+	assert.Equal(t, &Schema{Type: TypeInteger, Nullable: true}, s.Properties["count"])
+	assert.Equal(t, &Schema{Type: TypeString, Format: "date-time"}, s.Properties["when"])
+	assert.Equal(t, TypeString, s.Properties["big"].Type)
+	assert.Equal(t, TypeAny, s.Properties["untyped"].Type)
+	assert.Equal(t, &Schema{Type: TypeString}, s.Properties["labels"].AdditionalProperties)
+
+	children := s.Properties["tree"].Properties["children"]
+	assert.True(t, children.Optional)
+	assert.Equal(t, "apischema.testNode", children.Items.Ref)
+}
+
+func TestIncompatibilities(t *testing.T) {
+	old := &Schema{Type: TypeObject, Properties: map[string]*Schema{
+		"name":  {Type: TypeString},
+		"runs":  {Type: TypeInteger},
+		"rate":  {Type: TypeNumber},
+		"items": {Type: TypeArray, Nullable: true, Items: &Schema{Type: TypeObject, Properties: map[string]*Schema{"id": {Type: TypeInteger}}}},
+		"extra": {Type: TypeAny},
+		"label": {Type: TypeString, Nullable: true},
+	}}
+
+	compatible := &Schema{Type: TypeObject, Properties: map[string]*Schema{
+		"name":  {Type: TypeString},
+		"runs":  {Type: TypeInteger},
+		"rate":  {Type: TypeNumber},
+		"items": {Type: TypeArray, Nullable: true, Items: &Schema{Type: TypeObject, Properties: map[string]*Schema{"id": {Type: TypeInteger}, "new": {Type: TypeString}}}},
+		"extra": {Type: TypeObject},
+		"label": {Type: TypeString},
+		"added": {Type: TypeBoolean},
+	}}
+	assert.Empty(t, Incompatibilities(old, compatible))
+
+	breaking := &Schema{Type: TypeObject, Properties: map[string]*Schema{
+		"name":  {Type: TypeString, Optional: true},
+		"runs":  {Type: TypeString},
+		"rate":  {Type: TypeNumber, Nullable: true},
+		"items": {Type: TypeArray, Nullable: true, Items: &Schema{Type: TypeObject, Properties: map[string]*Schema{}}},
+		"extra": {Type: TypeAny},
+		"label": {Type: TypeString, Nullable: true},
+	}}
+	assert.Equal(t, []string{
+		"$.items[].id was removed",
+		"$.name can now be left out",
+		"$.rate can now be null",
+		"$.runs changed from integer to string",
+	}, Incompatibilities(old, breaking))
+}
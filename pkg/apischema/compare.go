@@ -0,0 +1,62 @@
+package apischema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Incompatibilities returns the changes from old to current that could break a consumer of old: removed
+// properties, changed types or formats, and values or properties that can now be null or left out. Added
+// properties, and values that can no longer be null, are compatible. Each is described with the JSON path to the
+// value, i.e. $.buckets[].runs.
+func Incompatibilities(old, current *Schema) []string {
+	var problems []string
+	compare("$", old, current, &problems)
+	return problems
+}
+
+func compare(path string, old, current *Schema, problems *[]string) {
+	if old.Type == TypeAny {
+		return
+	}
+	if old.Type != current.Type {
+		*problems = append(*problems, fmt.Sprintf("%s changed from %s to %s", path, old.Type, current.Type))
+		return
+	}
+	if old.Format != current.Format {
+		*problems = append(*problems, fmt.Sprintf("%s format changed from %q to %q", path, old.Format, current.Format))
+	}
+	if !old.Nullable && current.Nullable {
+		*problems = append(*problems, fmt.Sprintf("%s can now be null", path))
+	}
+	// Self references were described by the enclosing schema
+	if old.Ref != "" || current.Ref != "" {
+		return
+	}
+
+	if old.Items != nil && current.Items != nil {
+		compare(path+"[]", old.Items, current.Items, problems)
+	}
+	if old.AdditionalProperties != nil && current.AdditionalProperties != nil {
+		compare(path+"{}", old.AdditionalProperties, current.AdditionalProperties, problems)
+	}
+
+	names := make([]string, 0, len(old.Properties))
+	for name := range old.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertyPath := path + "." + name
+		op := old.Properties[name]
+		cp, ok := current.Properties[name]
+		if !ok {
+			*problems = append(*problems, fmt.Sprintf("%s was removed", propertyPath))
+			continue
+		}
+		if !op.Optional && cp.Optional {
+			*problems = append(*problems, fmt.Sprintf("%s can now be left out", propertyPath))
+		}
+		compare(propertyPath, op, cp, problems)
+	}
+}
@@ -0,0 +1,43 @@
+package apischema
+
+import (
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// Endpoint is a public API endpoint, and an example of the type its response is encoded from.
+type Endpoint struct {
+	Path     string
+	Response interface{}
+}
+
+// jobRunsPage is the paginated response of /api/jobs/runs.
+type jobRunsPage struct {
+	Rows      []apitype.JobRun `json:"rows"`
+	PageSize  int              `json:"page_size"`
+	Page      int              `json:"page"`
+	TotalRows int64            `json:"total_rows"`
+}
+
+// Endpoints are the endpoints whose responses must stay backward compatible: those the Go client uses, and
+// others with consumers outside sippy. Changing a response in a way that could break consumers needs a new
+// version of the endpoint under /api/v2, with the old one deprecated.
+var Endpoints = []Endpoint{
+	{Path: "/api/jobs", Response: []apitype.Job{}},
+	{Path: "/api/v2/jobs", Response: []apitype.JobV2{}},
+	{Path: "/api/tests", Response: []apitype.Test{}},
+	{Path: "/api/tests/recent_runs", Response: apitype.TestRecentRuns{}},
+	{Path: "/api/variants", Response: []apitype.Variant{}},
+	{Path: "/api/variants/keys", Response: []apitype.VariantKey{}},
+	{Path: "/api/job_variants", Response: crtype.JobVariants{}},
+	{Path: "/api/jobs/runs", Response: jobRunsPage{}},
+	{Path: "/api/jobs/runs/risk_analysis", Response: apitype.ProwJobRunRiskAnalysis{}},
+	{Path: "/api/jobs/runs/search", Response: []apitype.JobRunSummary{}},
+	{Path: "/api/jobs/runs/fingerprint_correlation", Response: []apitype.FingerprintCorrelation{}},
+	{Path: "/api/timeseries", Response: apitype.TimeSeries{}},
+	{Path: "/api/incidents/timeline", Response: []models.Incident{}},
+	{Path: "/api/component_readiness/regressions/burndown", Response: apitype.RegressionBurndown{}},
+	{Path: "/api/security/compliance", Response: apitype.SecurityComplianceReport{}},
+	{Path: "/api/releases/{release}/defaults", Response: apitype.ReleaseDefaults{}},
+}
@@ -0,0 +1,167 @@
+// Package apischema records the JSON shape of public API responses, derived from the Go types they're encoded from,
+// so tests can detect changes that would break existing consumers.
+package apischema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Types of values in a schema.
+const (
+	TypeAny     = "any"
+	TypeArray   = "array"
+	TypeBoolean = "boolean"
+	TypeInteger = "integer"
+	TypeNumber  = "number"
+	TypeObject  = "object"
+	TypeString  = "string"
+)
+
+// Schema is a simplified JSON schema: the type of a value, whether it can be null, and the schemas of its
+// properties, items or map values.
+type Schema struct {
+	Type     string `json:"type"`
+	Format   string `json:"format,omitempty"`
+	Nullable bool   `json:"nullable,omitempty"`
+	// Optional properties are left out of objects when empty.
+	Optional   bool               `json:"optional,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Items is the schema of array items.
+	Items *Schema `json:"items,omitempty"`
+	// AdditionalProperties is the schema of map values.
+	AdditionalProperties *Schema `json:"additional_properties,omitempty"`
+	// Ref names a type that contains itself, whose schema was already given by an enclosing schema.
+	Ref string `json:"ref,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+)
+
+// ForValue returns the schema of v encoded by encoding/json.
+func ForValue(v interface{}) *Schema {
+	return forType(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func forType(t reflect.Type, enclosing map[reflect.Type]bool) *Schema {
+	if t == nil {
+		return &Schema{Type: TypeAny}
+	}
+	if t.Kind() == reflect.Ptr {
+		s := forType(t.Elem(), enclosing)
+		s.Nullable = true
+		return s
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: TypeString, Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{Type: TypeAny}
+	case t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonMarshalerType):
+		// Encodes itself, so its shape can't be known from its type
+		return &Schema{Type: TypeAny}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: TypeString}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: TypeNumber}
+	case reflect.String:
+		return &Schema{Type: TypeString}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: TypeString, Format: "byte", Nullable: true}
+		}
+		return &Schema{Type: TypeArray, Nullable: true, Items: forType(t.Elem(), enclosing)}
+	case reflect.Array:
+		return &Schema{Type: TypeArray, Items: forType(t.Elem(), enclosing)}
+	case reflect.Map:
+		return &Schema{Type: TypeObject, Nullable: true, AdditionalProperties: forType(t.Elem(), enclosing)}
+	case reflect.Struct:
+		if enclosing[t] {
+			return &Schema{Type: TypeObject, Ref: t.String()}
+		}
+		enclosing[t] = true
+		defer delete(enclosing, t)
+		s := &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+		addStructProperties(s, t, enclosing)
+		return s
+	default:
+		// interface{}, and anything else encoding/json can't describe statically
+		return &Schema{Type: TypeAny}
+	}
+}
+
+// addStructProperties adds the properties of a struct's fields, including the fields of embedded structs, which
+// encoding/json promotes unless a field of the same name is closer to the top.
+func addStructProperties(s *Schema, t reflect.Type, enclosing map[reflect.Type]bool) {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded = append(embedded, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		var fs *Schema
+		if hasOption(opts, "string") {
+			fs = &Schema{Type: TypeString}
+		} else {
+			fs = forType(f.Type, enclosing)
+		}
+		fs.Optional = hasOption(opts, "omitempty")
+		s.Properties[name] = fs
+	}
+
+	sort.Slice(embedded, func(i, j int) bool { return embedded[i].String() < embedded[j].String() })
+	for _, et := range embedded {
+		promoted := &Schema{Properties: map[string]*Schema{}}
+		addStructProperties(promoted, et, enclosing)
+		for name, ps := range promoted.Properties {
+			if _, ok := s.Properties[name]; !ok {
+				s.Properties[name] = ps
+			}
+		}
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for _, o := range strings.Split(opts, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
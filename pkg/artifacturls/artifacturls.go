@@ -0,0 +1,141 @@
+// Package artifacturls builds links to job runs and their artifacts. Deployments configure the templates once, so
+// moving a bucket or deck instance changes the links in every API response and log message rather than each place
+// that used to format its own.
+package artifacturls
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultProwURL is the deck instance job runs are viewed on.
+	DefaultProwURL = "https://prow.ci.openshift.org"
+	// DefaultBucket is the GCS bucket job runs upload their artifacts to.
+	DefaultBucket = "test-platform-results"
+
+	// DefaultJobRunTemplate views a job run in Spyglass.
+	DefaultJobRunTemplate = "{prow_url}/view/gs/{bucket}/{path}"
+	// DefaultBrowseTemplate browses a job run's files, or opens one of them, in gcsweb.
+	DefaultBrowseTemplate = "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/{bucket}/{path}"
+)
+
+// Placeholders replaced in templates. {path} is the path in the bucket, i.e. logs/<job>/<id> for a job run.
+const (
+	prowURLPlaceholder = "{prow_url}"
+	bucketPlaceholder  = "{bucket}"
+	pathPlaceholder    = "{path}"
+)
+
+// runURLPath matches the bucket and path of a Spyglass or gcsweb URL, i.e. /view/gs/<bucket>/<path> or
+// /gcs/<bucket>/<path>.
+var runURLPath = regexp.MustCompile(`/(?:gs|gcs)/([^/]+)/(.+)$`)
+
+// Templates are the URL templates of a deployment. Empty templates use the defaults.
+type Templates struct {
+	JobRun string
+	Browse string
+}
+
+// Builder builds URLs from templates for a deck instance and bucket.
+type Builder struct {
+	templates Templates
+	prowURL   string
+	bucket    string
+}
+
+// New returns a builder for templates, returning an error if a template cannot link to a path.
+func New(templates Templates, prowURL, bucket string) (*Builder, error) {
+	if templates.JobRun == "" {
+		templates.JobRun = DefaultJobRunTemplate
+	}
+	if templates.Browse == "" {
+		templates.Browse = DefaultBrowseTemplate
+	}
+	for name, tmpl := range map[string]string{"job run": templates.JobRun, "browse": templates.Browse} {
+		if !strings.Contains(tmpl, pathPlaceholder) {
+			return nil, fmt.Errorf("%s URL template %q must contain %s", name, tmpl, pathPlaceholder)
+		}
+	}
+	return &Builder{
+		templates: templates,
+		prowURL:   strings.TrimSuffix(prowURL, "/"),
+		bucket:    bucket,
+	}, nil
+}
+
+// WithLocation returns a copy of the builder for another deck instance and bucket. Empty values keep the builder's.
+func (b *Builder) WithLocation(prowURL, bucket string) *Builder {
+	c := *b
+	if prowURL != "" {
+		c.prowURL = strings.TrimSuffix(prowURL, "/")
+	}
+	if bucket != "" {
+		c.bucket = bucket
+	}
+	return &c
+}
+
+// JobRunURL returns the URL viewing the job run at a path in the bucket.
+func (b *Builder) JobRunURL(path string) string {
+	return b.expand(b.templates.JobRun, b.bucket, path)
+}
+
+// BrowseURL returns the URL browsing a job run's files, or opening a file, at a path in the bucket.
+func (b *Builder) BrowseURL(path string) string {
+	return b.expand(b.templates.Browse, b.bucket, path)
+}
+
+// RunLinks returns the job run and browse URLs of a job run from the URL prow reported for it. The run keeps its own
+// bucket, as runs recorded before a bucket move still live in the old one. ok is false if the URL has no bucket path.
+func (b *Builder) RunLinks(runURL string) (jobRun, browse string, ok bool) {
+	bucket, path, err := ParseRunURL(runURL)
+	if err != nil {
+		return "", "", false
+	}
+	return b.expand(b.templates.JobRun, bucket, path), b.expand(b.templates.Browse, bucket, path), true
+}
+
+func (b *Builder) expand(tmpl, bucket, path string) string {
+	return strings.NewReplacer(
+		prowURLPlaceholder, b.prowURL,
+		bucketPlaceholder, bucket,
+		pathPlaceholder, strings.Trim(path, "/"),
+	).Replace(tmpl)
+}
+
+// ParseRunURL returns the bucket and path of a job run from its Spyglass or gcsweb URL.
+func ParseRunURL(runURL string) (bucket, path string, err error) {
+	u, err := url.Parse(runURL)
+	if err != nil {
+		return "", "", err
+	}
+	m := runURLPath.FindStringSubmatch(u.Path)
+	if m == nil {
+		return "", "", fmt.Errorf("job run URL %q has no bucket path", runURL)
+	}
+	return m[1], strings.TrimSuffix(m[2], "/"), nil
+}
+
+var (
+	lock              sync.RWMutex
+	defaultBuilder, _ = New(Templates{}, DefaultProwURL, DefaultBucket)
+)
+
+// SetDefault sets the builder used by Default, normally once at startup from flags.
+func SetDefault(b *Builder) {
+	lock.Lock()
+	defer lock.Unlock()
+	defaultBuilder = b
+}
+
+// Default returns the deployment's builder, or one for the default templates, deck instance and bucket if none was
+// set.
+func Default() *Builder {
+	lock.RLock()
+	defer lock.RUnlock()
+	return defaultBuilder
+}
@@ -0,0 +1,89 @@
+package artifacturls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const runPath = "logs/periodic-ci-openshift-release-master-ci-4.16-e2e-aws-ovn/1780000000000000000"
+
+func TestDefaultTemplates(t *testing.T) {
+	b, err := New(Templates{}, DefaultProwURL+"/", DefaultBucket)
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://prow.ci.openshift.org/view/gs/test-platform-results/"+runPath, b.JobRunURL(runPath))
+	assert.Equal(t, "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/"+runPath,
+		b.BrowseURL("/"+runPath+"/"))
+}
+
+func TestConfiguredTemplates(t *testing.T) {
+	b, err := New(Templates{
+		JobRun: "{prow_url}/spyglass/{bucket}/{path}",
+		Browse: "https://storage.example.com/{bucket}/{path}",
+	}, "https://deck.example.com", "results")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://deck.example.com/spyglass/results/"+runPath, b.JobRunURL(runPath))
+	assert.Equal(t, "https://storage.example.com/results/"+runPath, b.BrowseURL(runPath))
+
+	moved := b.WithLocation("", "other-results")
+	assert.Equal(t, "https://deck.example.com/spyglass/other-results/"+runPath, moved.JobRunURL(runPath))
+	assert.Equal(t, "https://deck.example.com/spyglass/results/"+runPath, b.JobRunURL(runPath), "copy changed the original")
+}
+
+func TestTemplatesRequirePath(t *testing.T) {
+	_, err := New(Templates{JobRun: "{prow_url}/view/gs/{bucket}"}, DefaultProwURL, DefaultBucket)
+	assert.ErrorContains(t, err, "{path}")
+}
+
+func TestRunLinks(t *testing.T) {
+	b, err := New(Templates{}, "https://deck.example.com", "results")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		runURL     string
+		wantJobRun string
+		wantBrowse string
+		wantOK     bool
+	}{
+		{
+			name:       "spyglass URL keeps its bucket",
+			runURL:     "https://prow.ci.openshift.org/view/gs/origin-ci-test/" + runPath,
+			wantJobRun: "https://deck.example.com/view/gs/origin-ci-test/" + runPath,
+			wantBrowse: "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/origin-ci-test/" + runPath,
+			wantOK:     true,
+		},
+		{
+			name:       "gcsweb URL",
+			runURL:     "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/" + runPath + "/",
+			wantJobRun: "https://deck.example.com/view/gs/test-platform-results/" + runPath,
+			wantBrowse: "https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/" + runPath,
+			wantOK:     true,
+		},
+		{
+			name:   "no bucket path",
+			runURL: "https://prow.ci.openshift.org/",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			jobRun, browse, ok := b.RunLinks(tc.runURL)
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.wantJobRun, jobRun)
+			assert.Equal(t, tc.wantBrowse, browse)
+		})
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	original := Default()
+	defer SetDefault(original)
+
+	b, err := New(Templates{}, "https://deck.example.com", "results")
+	require.NoError(t, err)
+	SetDefault(b)
+	assert.Equal(t, "https://deck.example.com/view/gs/results/"+runPath, Default().JobRunURL(runPath))
+}
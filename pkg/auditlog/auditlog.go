@@ -0,0 +1,227 @@
+// Package auditlog keeps a tamper-evident log of the changes people make through the API, i.e. opening incidents or
+// pinning a component readiness basis, and exports it signed, for organizations that need evidence of CI exceptions
+// in compliance audits.
+//
+// Each event's hash covers its fields and the hash of the event before it, so altering, inserting or removing an
+// event breaks the chain from there on. An export carries the events of a time range, the hash they chain from and
+// the hash they end with, signed with an ed25519 key. Anyone holding the public key can check the export wasn't
+// modified after it was produced.
+package auditlog
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// appendLockID is the Postgres advisory lock serializing appends, so each event chains from the one before it.
+const appendLockID = 7293015
+
+// Append records an event, timestamped now and chained to the last recorded event.
+func Append(dbc *db.DB, event *models.AuditEvent) error {
+	return dbc.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", appendLockID).Error; err != nil {
+			return errors.Wrap(err, "error locking audit log")
+		}
+		var last models.AuditEvent
+		if err := tx.Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+			return errors.Wrap(err, "error finding last audit event")
+		}
+
+		// stamped under the lock, so timestamps follow the chain, at the precision Postgres stores
+		event.Timestamp = time.Now().UTC().Truncate(time.Microsecond)
+		event.PrevHash = last.Hash
+		event.Hash = Hash(event)
+		return tx.Create(event).Error
+	})
+}
+
+// hashedEvent is what an event's hash covers, in a fixed field order.
+type hashedEvent struct {
+	Timestamp string `json:"timestamp"`
+	Actor     string `json:"actor"`
+	Category  string `json:"category"`
+	Method    string `json:"method"`
+	Endpoint  string `json:"endpoint"`
+	Params    string `json:"params"`
+	Body      string `json:"body"`
+	Status    int    `json:"status"`
+	PrevHash  string `json:"prev_hash"`
+}
+
+// Hash returns the hex encoded SHA-256 hash of an event's fields and PrevHash. The ID and Hash are not covered.
+func Hash(event *models.AuditEvent) string {
+	// marshaling a struct of strings and an int can't fail
+	fields, _ := json.Marshal(hashedEvent{
+		Timestamp: event.Timestamp.UTC().Format(time.RFC3339Nano),
+		Actor:     event.Actor,
+		Category:  event.Category,
+		Method:    event.Method,
+		Endpoint:  event.Endpoint,
+		Params:    event.Params,
+		Body:      event.Body,
+		Status:    event.Status,
+		PrevHash:  event.PrevHash,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyChain checks each event chains from the one before it, the first from chainFrom, and that each hash matches
+// the event's fields. It returns the hash of the last event, or chainFrom if there are none.
+func VerifyChain(chainFrom string, events []models.AuditEvent) (string, error) {
+	prev := chainFrom
+	for i := range events {
+		e := &events[i]
+		if e.PrevHash != prev {
+			return "", fmt.Errorf("audit event %d does not chain from the event before it", e.ID)
+		}
+		if Hash(e) != e.Hash {
+			return "", fmt.Errorf("audit event %d does not match its hash", e.ID)
+		}
+		prev = e.Hash
+	}
+	return prev, nil
+}
+
+// Export is the audit log of a time range, signed.
+type Export struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// ChainFrom is the hash of the event before the first exported one, empty if the log starts with it, and Head the
+	// hash of the last exported one.
+	ChainFrom string              `json:"chain_from"`
+	Head      string              `json:"head"`
+	Events    []models.AuditEvent `json:"events"`
+	// PublicKey is the base64 encoded key the export was signed with, and Signature the base64 encoded signature.
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+// NewExport returns the unsigned export of the events recorded from start until end, failing if the recorded
+// events don't chain.
+func NewExport(dbc *db.DB, start, end time.Time) (*Export, error) {
+	events := []models.AuditEvent{}
+	if err := dbc.DB.Where("timestamp >= ? AND timestamp < ?", start, end).Order("id").Find(&events).Error; err != nil {
+		return nil, errors.Wrap(err, "error querying audit events")
+	}
+	export := &Export{
+		Start:       start.UTC(),
+		End:         end.UTC(),
+		GeneratedAt: time.Now().UTC(),
+		Events:      events,
+	}
+	for i := range events {
+		events[i].Timestamp = events[i].Timestamp.UTC()
+	}
+	if len(events) > 0 {
+		export.ChainFrom = events[0].PrevHash
+	}
+
+	head, err := VerifyChain(export.ChainFrom, events)
+	if err != nil {
+		return nil, errors.Wrap(err, "the audit log has been tampered with")
+	}
+	export.Head = head
+	return export, nil
+}
+
+// signedPayload is what an export's signature covers. The events are covered through the head of their chain.
+func (e *Export) signedPayload() []byte {
+	return []byte(fmt.Sprintf("sippy audit export\nstart=%s\nend=%s\ngenerated_at=%s\nchain_from=%s\nhead=%s\nevents=%d\n",
+		e.Start.UTC().Format(time.RFC3339Nano), e.End.UTC().Format(time.RFC3339Nano),
+		e.GeneratedAt.UTC().Format(time.RFC3339Nano), e.ChainFrom, e.Head, len(e.Events)))
+}
+
+// Sign signs an export with a private key.
+func (e *Export) Sign(key ed25519.PrivateKey) {
+	e.PublicKey = base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+	e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, e.signedPayload()))
+}
+
+// Verify checks an export was signed with the private key of a public key, and that its events are in its range and
+// chain to its signed head.
+func (e *Export) Verify(key ed25519.PublicKey) error {
+	signature, err := base64.StdEncoding.DecodeString(e.Signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+	if !ed25519.Verify(key, e.signedPayload(), signature) {
+		return fmt.Errorf("the export was not signed with the given key, or its range or head were modified")
+	}
+
+	head, err := VerifyChain(e.ChainFrom, e.Events)
+	if err != nil {
+		return err
+	}
+	if head != e.Head {
+		return fmt.Errorf("the exported events do not end with the signed head, events were removed or added")
+	}
+	for _, event := range e.Events {
+		if event.Timestamp.Before(e.Start) || !event.Timestamp.Before(e.End) {
+			return fmt.Errorf("audit event %d is outside the exported range", event.ID)
+		}
+	}
+	return nil
+}
+
+// LoadPrivateKey reads a PEM encoded PKCS #8 ed25519 private key, as created by
+// "openssl genpkey -algorithm ed25519".
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	der, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing private key %s", path)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 private key", path)
+	}
+	return edKey, nil
+}
+
+// LoadPublicKey reads a PEM encoded PKIX ed25519 public key, as created by "openssl pkey -pubout".
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	der, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing public key %s", path)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ed25519 public key", path)
+	}
+	return edKey, nil
+}
+
+func readPEM(path, blockType string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s does not contain a PEM encoded %s", path, blockType)
+	}
+	return block.Bytes, nil
+}
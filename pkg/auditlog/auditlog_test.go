@@ -0,0 +1,184 @@
+package auditlog
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+var exportStart = time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// chain returns events chained from chainFrom, an hour apart from the start of the export range.
+func chain(chainFrom string, actors ...string) []models.AuditEvent {
+	events := make([]models.AuditEvent, 0, len(actors))
+	prev := chainFrom
+	for i, actor := range actors {
+		e := models.AuditEvent{
+			ID:        uint(i + 1),
+			Timestamp: exportStart.Add(time.Duration(i+1) * time.Hour),
+			Actor:     actor,
+			Category:  "incident",
+			Method:    "POST",
+			Endpoint:  "/api/incidents/timeline",
+			Body:      `{"summary":"registry outage"}`,
+			Status:    200,
+			PrevHash:  prev,
+		}
+		e.Hash = Hash(&e)
+		prev = e.Hash
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestHash(t *testing.T) {
+	e := chain("", "alice")[0]
+	assert.Len(t, e.Hash, 64)
+
+	local := e
+	local.Timestamp = e.Timestamp.In(time.FixedZone("EST", -5*3600))
+	assert.Equal(t, e.Hash, Hash(&local), "the same instant in another zone hashes the same")
+
+	local.ID = 42
+	assert.Equal(t, e.Hash, Hash(&local), "the ID is assigned after hashing and not covered")
+
+	changed := e
+	changed.Body = `{"summary":"nothing happened"}`
+	assert.NotEqual(t, e.Hash, Hash(&changed))
+
+	rechained := e
+	rechained.PrevHash = "abc"
+	assert.NotEqual(t, e.Hash, Hash(&rechained))
+}
+
+func TestVerifyChain(t *testing.T) {
+	events := chain("prev", "alice", "bob", "carol")
+	head, err := VerifyChain("prev", events)
+	require.NoError(t, err)
+	assert.Equal(t, events[2].Hash, head)
+
+	head, err = VerifyChain("prev", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "prev", head)
+
+	_, err = VerifyChain("", events)
+	assert.ErrorContains(t, err, "event 1 does not chain")
+
+	altered := chain("prev", "alice", "bob", "carol")
+	altered[1].Actor = "mallory"
+	_, err = VerifyChain("prev", altered)
+	assert.ErrorContains(t, err, "event 2 does not match its hash")
+
+	rehashed := chain("prev", "alice", "bob", "carol")
+	rehashed[1].Actor = "mallory"
+	rehashed[1].Hash = Hash(&rehashed[1])
+	_, err = VerifyChain("prev", rehashed)
+	assert.ErrorContains(t, err, "event 3 does not chain", "rehashing an altered event breaks the next link")
+
+	removed := chain("prev", "alice", "bob", "carol")
+	_, err = VerifyChain("prev", append(removed[:1], removed[2]))
+	assert.ErrorContains(t, err, "event 3 does not chain")
+}
+
+func signedExport(t *testing.T, key ed25519.PrivateKey) *Export {
+	events := chain("prev", "alice", "bob")
+	export := &Export{
+		Start:       exportStart,
+		End:         exportStart.AddDate(0, 0, 1),
+		GeneratedAt: exportStart.AddDate(0, 0, 2),
+		ChainFrom:   "prev",
+		Head:        events[1].Hash,
+		Events:      events,
+	}
+	export.Sign(key)
+
+	// verify what an auditor would read back
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+	read := &Export{}
+	require.NoError(t, json.Unmarshal(data, read))
+	return read
+}
+
+func TestExportVerify(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	assert.NoError(t, signedExport(t, key).Verify(pub))
+	assert.ErrorContains(t, signedExport(t, key).Verify(otherPub), "not signed with the given key")
+
+	tests := map[string]struct {
+		tamper func(e *Export)
+		err    string
+	}{
+		"altered event": {
+			tamper: func(e *Export) { e.Events[0].Status = 500 },
+			err:    "event 1 does not match its hash",
+		},
+		"removed last event": {
+			tamper: func(e *Export) { e.Events = e.Events[:1] },
+			err:    "not signed with the given key",
+		},
+		"replaced events": {
+			tamper: func(e *Export) { e.Events = chain("prev", "mallory", "bob") },
+			err:    "do not end with the signed head",
+		},
+		"widened range": {
+			tamper: func(e *Export) { e.End = e.End.AddDate(0, 0, 1) },
+			err:    "not signed with the given key",
+		},
+		"event outside range": {
+			tamper: func(e *Export) {
+				e.Events[1].Timestamp = e.End
+				e.Events[1].Hash = Hash(&e.Events[1])
+				e.Head = e.Events[1].Hash
+			},
+			err: "not signed with the given key",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			export := signedExport(t, key)
+			tc.tamper(export)
+			assert.ErrorContains(t, export.Verify(pub), tc.err)
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	der, err = x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	pubFile := filepath.Join(dir, "pub.pem")
+	require.NoError(t, os.WriteFile(pubFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	loadedKey, err := LoadPrivateKey(keyFile)
+	require.NoError(t, err)
+	assert.Equal(t, key, loadedKey)
+	loadedPub, err := LoadPublicKey(pubFile)
+	require.NoError(t, err)
+	assert.Equal(t, pub, loadedPub)
+
+	_, err = LoadPrivateKey(pubFile)
+	assert.ErrorContains(t, err, "does not contain a PEM encoded PRIVATE KEY")
+}
@@ -0,0 +1,271 @@
+package bigquery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/api/googleapi"
+)
+
+// TableSchema is the schema sippy expects of a BigQuery table.
+type TableSchema struct {
+	Table       string
+	Description string
+	// Owned tables are written by sippy, and their schema is complete. The others are written by other CI tooling,
+	// and list only the fields sippy reads, so extra fields in the live table are expected.
+	Owned  bool
+	Schema bigquery.Schema
+	// TimePartitioning and Clustering are applied when the table is created by CreateMissingTables.
+	TimePartitioning *bigquery.TimePartitioning
+	Clustering       *bigquery.Clustering
+}
+
+const (
+	JobVariantsTable      = "job_variants"
+	JobVariantsAuditTable = "job_variants_audit"
+	TestRegressionsTable  = "test_regressions"
+	JunitTable            = "junit"
+	JobsTable             = "jobs"
+)
+
+var variantsSchema = bigquery.Schema{
+	{Name: "key", Type: bigquery.StringFieldType},
+	{Name: "value", Type: bigquery.StringFieldType},
+}
+
+// ExpectedSchemas are the BigQuery tables sippy writes, or reads and depends on the shape of.
+var ExpectedSchemas = []TableSchema{
+	{
+		Table:       JobVariantsTable,
+		Description: "The variant registry, written by the job-variants loader and joined to junit by job name for component readiness",
+		Owned:       true,
+		Schema: bigquery.Schema{
+			{Name: "job_name", Type: bigquery.StringFieldType},
+			{Name: "variant_name", Type: bigquery.StringFieldType},
+			{Name: "variant_value", Type: bigquery.StringFieldType},
+		},
+		Clustering: &bigquery.Clustering{Fields: []string{"job_name"}},
+	},
+	{
+		Table:       JobVariantsAuditTable,
+		Description: "Every change the job-variants loader made to the variant registry, appended when run with --job-variants-audit",
+		Owned:       true,
+		Schema: bigquery.Schema{
+			{Name: "job_name", Type: bigquery.StringFieldType},
+			{Name: "variant_name", Type: bigquery.StringFieldType},
+			{Name: "action", Type: bigquery.StringFieldType},
+			{Name: "old_value", Type: bigquery.StringFieldType},
+			{Name: "new_value", Type: bigquery.StringFieldType},
+			{Name: "actor", Type: bigquery.StringFieldType},
+			{Name: "reason", Type: bigquery.StringFieldType},
+			{Name: "changed_at", Type: bigquery.TimestampFieldType},
+		},
+		TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "changed_at"},
+		Clustering:       &bigquery.Clustering{Fields: []string{"job_name", "variant_name"}},
+	},
+	{
+		Table:       TestRegressionsTable,
+		Description: "Regressions in component readiness views, written by the regression tracker",
+		Owned:       true,
+		Schema: bigquery.Schema{
+			{Name: "view", Type: bigquery.StringFieldType},
+			{Name: "release", Type: bigquery.StringFieldType},
+			{Name: "test_id", Type: bigquery.StringFieldType},
+			{Name: "test_name", Type: bigquery.StringFieldType},
+			{Name: "regression_id", Type: bigquery.StringFieldType},
+			{Name: "opened", Type: bigquery.TimestampFieldType},
+			{Name: "closed", Type: bigquery.TimestampFieldType},
+			{Name: "variants", Type: bigquery.RecordFieldType, Repeated: true, Schema: variantsSchema},
+		},
+		TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "opened"},
+		Clustering:       &bigquery.Clustering{Fields: []string{"release", "view"}},
+	},
+	{
+		Table:       JunitTable,
+		Description: "Test results parsed from job run junit files, read by component readiness",
+		Schema: bigquery.Schema{
+			{Name: "prowjob_build_id", Type: bigquery.StringFieldType},
+			{Name: "prowjob_name", Type: bigquery.StringFieldType},
+			{Name: "file_path", Type: bigquery.StringFieldType},
+			{Name: "test_name", Type: bigquery.StringFieldType},
+			{Name: "testsuite", Type: bigquery.StringFieldType},
+			{Name: "success_val", Type: bigquery.IntegerFieldType},
+			{Name: "flake_count", Type: bigquery.IntegerFieldType},
+			{Name: "skipped", Type: bigquery.BooleanFieldType},
+			{Name: "modified_time", Type: bigquery.DateTimeFieldType},
+			{Name: "branch", Type: bigquery.StringFieldType},
+		},
+		TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "modified_time"},
+		Clustering:       &bigquery.Clustering{Fields: []string{"prowjob_name"}},
+	},
+	{
+		Table:       JobsTable,
+		Description: "Prow job runs, read by component readiness and the prow loader",
+		Schema: bigquery.Schema{
+			{Name: "prowjob_job_name", Type: bigquery.StringFieldType},
+			{Name: "prowjob_state", Type: bigquery.StringFieldType},
+			{Name: "prowjob_build_id", Type: bigquery.StringFieldType},
+			{Name: "prowjob_type", Type: bigquery.StringFieldType},
+			{Name: "prowjob_cluster", Type: bigquery.StringFieldType},
+			{Name: "prowjob_url", Type: bigquery.StringFieldType},
+			{Name: "prowjob_start", Type: bigquery.DateTimeFieldType},
+			{Name: "prowjob_completion", Type: bigquery.DateTimeFieldType},
+			{Name: "prowjob_annotations", Type: bigquery.StringFieldType, Repeated: true},
+			{Name: "pr_sha", Type: bigquery.StringFieldType},
+			{Name: "pr_author", Type: bigquery.StringFieldType},
+			{Name: "pr_number", Type: bigquery.StringFieldType},
+			{Name: "org", Type: bigquery.StringFieldType},
+			{Name: "repo", Type: bigquery.StringFieldType},
+		},
+		TimePartitioning: &bigquery.TimePartitioning{Type: bigquery.DayPartitioningType, Field: "prowjob_start"},
+		Clustering:       &bigquery.Clustering{Fields: []string{"prowjob_job_name"}},
+	},
+}
+
+// StartupTables are the tables verified when a BigQuery client is created. The regression tracker's and the
+// variant audit tables are only needed when those features are enabled, so are left to the bigquery-schemas command.
+var StartupTables = []string{JobVariantsTable, JunitTable, JobsTable}
+
+// ExpectedTables returns the names of all tables with an expected schema.
+func ExpectedTables() []string {
+	tables := make([]string, 0, len(ExpectedSchemas))
+	for _, s := range ExpectedSchemas {
+		tables = append(tables, s.Table)
+	}
+	return tables
+}
+
+// ExpectedSchema returns the expected schema of a table.
+func ExpectedSchema(table string) (TableSchema, bool) {
+	for _, s := range ExpectedSchemas {
+		if s.Table == table {
+			return s, true
+		}
+	}
+	return TableSchema{}, false
+}
+
+// DiffSchema returns the differences between the expected and actual schema of a table, one per line. For tables
+// sippy doesn't own, fields only in the actual schema are ignored.
+func DiffSchema(expected TableSchema, actual bigquery.Schema) []string {
+	return diffFields("", expected.Schema, actual, expected.Owned)
+}
+
+func diffFields(prefix string, expected, actual bigquery.Schema, owned bool) []string {
+	var diffs []string
+	actualFields := map[string]*bigquery.FieldSchema{}
+	for _, f := range actual {
+		actualFields[strings.ToLower(f.Name)] = f
+	}
+
+	expectedFields := map[string]bool{}
+	for _, want := range expected {
+		name := prefix + want.Name
+		expectedFields[strings.ToLower(want.Name)] = true
+		got, ok := actualFields[strings.ToLower(want.Name)]
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("missing field %s %s", name, fieldType(want)))
+			continue
+		}
+		if got.Type != want.Type || got.Repeated != want.Repeated {
+			diffs = append(diffs, fmt.Sprintf("field %s is %s, expected %s", name, fieldType(got), fieldType(want)))
+			continue
+		}
+		if want.Type == bigquery.RecordFieldType {
+			diffs = append(diffs, diffFields(name+".", want.Schema, got.Schema, owned)...)
+		}
+	}
+
+	if owned {
+		for _, got := range actual {
+			if !expectedFields[strings.ToLower(got.Name)] {
+				diffs = append(diffs, fmt.Sprintf("unexpected field %s %s", prefix+got.Name, fieldType(got)))
+			}
+		}
+	}
+	return diffs
+}
+
+func fieldType(f *bigquery.FieldSchema) string {
+	if f.Repeated {
+		return "REPEATED " + string(f.Type)
+	}
+	return string(f.Type)
+}
+
+// VerifySchemas compares the live schema of each table in the client's dataset against the expected schema, and
+// returns an error describing every difference, so a mismatch fails fast with a precise diff rather than with
+// query or insert errors part way through a sync.
+func (c *Client) VerifySchemas(ctx context.Context, tables []string) error {
+	var diffs []string
+	for _, table := range tables {
+		expected, ok := ExpectedSchema(table)
+		if !ok {
+			return fmt.Errorf("no expected schema for table %s", table)
+		}
+
+		md, err := c.BQ.Dataset(c.Dataset).Table(table).Metadata(ctx)
+		if err != nil {
+			var apiErr *googleapi.Error
+			if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotFound {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: table does not exist", c.Dataset, table))
+				continue
+			}
+			return errors.Wrapf(err, "couldn't get schema of %s.%s", c.Dataset, table)
+		}
+		for _, diff := range DiffSchema(expected, md.Schema) {
+			diffs = append(diffs, fmt.Sprintf("%s.%s: %s", c.Dataset, table, diff))
+		}
+	}
+	if len(diffs) > 0 {
+		return fmt.Errorf("BigQuery tables don't match the expected schemas, see the bigquery-schemas command:\n%s",
+			strings.Join(diffs, "\n"))
+	}
+	return nil
+}
+
+// tableMetadata returns the metadata a missing table is created with.
+func tableMetadata(s TableSchema) *bigquery.TableMetadata {
+	return &bigquery.TableMetadata{
+		Description:      s.Description,
+		Schema:           s.Schema,
+		TimePartitioning: s.TimePartitioning,
+		Clustering:       s.Clustering,
+	}
+}
+
+// CreateMissingTables creates each table that does not exist in the client's dataset, with its expected schema,
+// partitioning and clustering, and returns the tables created. Tables sippy doesn't own are created with only the
+// fields sippy reads, which is enough for a new deployment to start, and are extended by the tooling that writes
+// them. Existing tables are left alone, use VerifySchemas to check them.
+func (c *Client) CreateMissingTables(ctx context.Context, tables []string) ([]string, error) {
+	var created []string
+	for _, table := range tables {
+		expected, ok := ExpectedSchema(table)
+		if !ok {
+			return created, fmt.Errorf("no expected schema for table %s", table)
+		}
+
+		ref := c.BQ.Dataset(c.Dataset).Table(table)
+		_, err := ref.Metadata(ctx)
+		if err == nil {
+			continue
+		}
+		var apiErr *googleapi.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound {
+			return created, errors.Wrapf(err, "couldn't check for %s.%s", c.Dataset, table)
+		}
+
+		if err := ref.Create(ctx, tableMetadata(expected)); err != nil {
+			return created, errors.Wrapf(err, "couldn't create %s.%s", c.Dataset, table)
+		}
+		log.WithFields(log.Fields{"dataset": c.Dataset, "table": table}).Info("created BigQuery table")
+		created = append(created, table)
+	}
+	return created, nil
+}
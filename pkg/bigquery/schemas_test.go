@@ -0,0 +1,118 @@
+package bigquery
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+)
+
+func TestDiffSchema(t *testing.T) {
+	expected := TableSchema{
+		Table: "example",
+		Schema: bigquery.Schema{
+			{Name: "name", Type: bigquery.StringFieldType},
+			{Name: "count", Type: bigquery.IntegerFieldType},
+			{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+			{Name: "variants", Type: bigquery.RecordFieldType, Repeated: true, Schema: variantsSchema},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		owned  bool
+		actual bigquery.Schema
+		want   []string
+	}{
+		{
+			name: "matching schema",
+			actual: bigquery.Schema{
+				{Name: "NAME", Type: bigquery.StringFieldType},
+				{Name: "count", Type: bigquery.IntegerFieldType},
+				{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+				{Name: "variants", Type: bigquery.RecordFieldType, Repeated: true, Schema: variantsSchema},
+			},
+		},
+		{
+			name: "missing and mistyped fields",
+			actual: bigquery.Schema{
+				{Name: "count", Type: bigquery.StringFieldType},
+				{Name: "tags", Type: bigquery.StringFieldType},
+				{Name: "variants", Type: bigquery.RecordFieldType, Repeated: true, Schema: bigquery.Schema{
+					{Name: "key", Type: bigquery.StringFieldType},
+					{Name: "value", Type: bigquery.IntegerFieldType},
+				}},
+			},
+			want: []string{
+				"missing field name STRING",
+				"field count is STRING, expected INTEGER",
+				"field tags is STRING, expected REPEATED STRING",
+				"field variants.value is INTEGER, expected STRING",
+			},
+		},
+		{
+			name: "extra fields are ignored in tables sippy doesn't own",
+			actual: bigquery.Schema{
+				{Name: "name", Type: bigquery.StringFieldType},
+				{Name: "count", Type: bigquery.IntegerFieldType},
+				{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+				{Name: "variants", Type: bigquery.RecordFieldType, Repeated: true, Schema: variantsSchema},
+				{Name: "extra", Type: bigquery.BooleanFieldType},
+			},
+		},
+		{
+			name:  "extra fields are reported in owned tables",
+			owned: true,
+			actual: bigquery.Schema{
+				{Name: "name", Type: bigquery.StringFieldType},
+				{Name: "count", Type: bigquery.IntegerFieldType},
+				{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+				{Name: "variants", Type: bigquery.RecordFieldType, Repeated: true, Schema: variantsSchema},
+				{Name: "extra", Type: bigquery.BooleanFieldType},
+			},
+			want: []string{"unexpected field extra BOOLEAN"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected.Owned = tt.owned
+			assert.Equal(t, tt.want, DiffSchema(expected, tt.actual))
+		})
+	}
+}
+
+func TestRegressionSchemaMatchesType(t *testing.T) {
+	expected, ok := ExpectedSchema(TestRegressionsTable)
+	require.True(t, ok)
+
+	inferred, err := bigquery.InferSchema(crtype.TestRegression{})
+	require.NoError(t, err)
+	// inferred fields are required unless nullable, which the live table doesn't enforce
+	assert.Empty(t, DiffSchema(expected, inferred))
+}
+
+func TestPartitioningFieldsExist(t *testing.T) {
+	for _, s := range ExpectedSchemas {
+		fields := map[string]bigquery.FieldType{}
+		for _, f := range s.Schema {
+			fields[f.Name] = f.Type
+		}
+
+		md := tableMetadata(s)
+		if md.TimePartitioning != nil {
+			fieldType, ok := fields[md.TimePartitioning.Field]
+			assert.True(t, ok, "%s is partitioned by missing field %s", s.Table, md.TimePartitioning.Field)
+			assert.Contains(t, []bigquery.FieldType{bigquery.DateFieldType, bigquery.DateTimeFieldType, bigquery.TimestampFieldType},
+				fieldType, "%s is partitioned by non-date field %s", s.Table, md.TimePartitioning.Field)
+		}
+		if md.Clustering != nil {
+			for _, f := range md.Clustering.Fields {
+				_, ok := fields[f]
+				assert.True(t, ok, "%s is clustered by missing field %s", s.Table, f)
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package client is a Go client for the sippy HTTP API. It handles authentication, retries and pagination, and
+// decodes responses into the same types the server uses, so consumers don't need to hand roll requests against
+// the API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = 500 * time.Millisecond
+	// maxBackoff caps both exponential backoff and server provided Retry-After delays.
+	maxBackoff = 30 * time.Second
+)
+
+// Options configures a Client. The zero value is usable.
+type Options struct {
+	// HTTPClient is used to make requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// Token, if set, is sent as a bearer token, for sippy instances behind an authenticating proxy.
+	Token string
+	// Headers are added to every request.
+	Headers http.Header
+	// MaxRetries is the number of times a request is retried after a network error, 429 or 5xx response.
+	// Defaults to 3, set to a negative value to disable retries.
+	MaxRetries int
+	// Backoff is the delay before the first retry, doubling on each subsequent retry. Defaults to 500ms.
+	Backoff time.Duration
+}
+
+// Client makes requests to a sippy instance.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	headers    http.Header
+	maxRetries int
+	backoff    time.Duration
+}
+
+// New returns a client for the sippy instance at baseURL, i.e. https://sippy.dptools.openshift.org.
+func New(baseURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid sippy URL %q: %w", baseURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid sippy URL %q: scheme and host are required", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: opts.HTTPClient,
+		token:      opts.Token,
+		headers:    opts.Headers,
+		maxRetries: opts.MaxRetries,
+		backoff:    opts.Backoff,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.maxRetries == 0 {
+		c.maxRetries = defaultMaxRetries
+	} else if c.maxRetries < 0 {
+		c.maxRetries = 0
+	}
+	if c.backoff <= 0 {
+		c.backoff = defaultBackoff
+	}
+	return c, nil
+}
+
+// APIError is returned when sippy responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	// Message is the error message from the response body, or the body itself if it wasn't a sippy error.
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("sippy returned %d: %s", e.StatusCode, e.Message)
+}
+
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil && parsed.Message != "" {
+		apiErr.Message = parsed.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+// retryable reports whether a response status indicates a transient failure.
+func retryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
+// do sends a request and decodes the JSON response into out, if non-nil. POST requests aren't retried, as they
+// aren't idempotent.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body, out interface{}) error {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = params.Encode()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("error encoding request body: %w", err)
+		}
+	}
+
+	attempts := c.maxRetries + 1
+	if method == http.MethodPost {
+		attempts = 1
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := c.backoff << (attempt - 1)
+			if ra, ok := lastErr.(*retryAfterError); ok && ra.after > 0 {
+				delay = ra.after
+			}
+			if delay > maxBackoff {
+				delay = maxBackoff
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		retry, err := c.attempt(ctx, method, u.String(), payload, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	if ra, ok := lastErr.(*retryAfterError); ok {
+		return ra.APIError
+	}
+	return lastErr
+}
+
+// retryAfterError wraps a retryable APIError with the delay the server asked for, if any.
+type retryAfterError struct {
+	*APIError
+	after time.Duration
+}
+
+func (c *Client) attempt(ctx context.Context, method, u string, payload []byte, out interface{}) (retry bool, err error) {
+	var reqBody io.Reader
+	if payload != nil {
+		reqBody = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return false, err
+	}
+	for k, v := range c.headers {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Network errors are retried unless the caller gave up.
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return true, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := newAPIError(resp.StatusCode, respBody)
+		if !retryable(resp.StatusCode) {
+			return false, apiErr
+		}
+		ra := &retryAfterError{APIError: apiErr}
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+			ra.after = time.Duration(secs) * time.Second
+		}
+		return true, ra
+	}
+
+	if out == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return false, fmt.Errorf("error decoding response from %s: %w", u, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts Options) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	if opts.Backoff == 0 {
+		opts.Backoff = time.Millisecond
+	}
+	c, err := New(srv.URL, opts)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNewRequiresAbsoluteURL(t *testing.T) {
+	_, err := New("sippy.example.com", Options{})
+	assert.Error(t, err)
+}
+
+func TestAuthAndHeaders(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "my-bot", r.Header.Get("User-Agent"))
+		assert.Equal(t, "4.14", r.URL.Query().Get("release"))
+		_, _ = w.Write([]byte(`[{"name": "aws"}]`))
+	}, Options{Token: "secret", Headers: http.Header{"User-Agent": []string{"my-bot"}}})
+
+	variants, err := c.Variants(context.Background(), "4.14", nil)
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+	assert.Equal(t, "aws", variants[0].Name)
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		status       int
+		failures     int32
+		wantRequests int32
+		wantErr      bool
+	}{
+		{name: "retries server errors", method: http.MethodGet, status: http.StatusBadGateway, failures: 2, wantRequests: 3},
+		{name: "retries rate limiting", method: http.MethodGet, status: http.StatusTooManyRequests, failures: 1, wantRequests: 2},
+		{name: "gives up after max retries", method: http.MethodGet, status: http.StatusInternalServerError, failures: 10, wantRequests: 4, wantErr: true},
+		{name: "does not retry client errors", method: http.MethodGet, status: http.StatusBadRequest, failures: 10, wantRequests: 1, wantErr: true},
+		{name: "does not retry posts", method: http.MethodPost, status: http.StatusInternalServerError, failures: 10, wantRequests: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= tt.failures {
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(`{"code": ` + strconv.Itoa(tt.status) + `, "message": "boom"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{}`))
+			}, Options{})
+
+			err := c.do(context.Background(), tt.method, "/api/test", nil, nil, &struct{}{})
+			assert.Equal(t, tt.wantRequests, atomic.LoadInt32(&requests))
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var apiErr *APIError
+			require.True(t, errors.As(err, &apiErr), "expected an APIError, got %v", err)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, "boom", apiErr.Message)
+		})
+	}
+}
+
+func TestJobRunsPagination(t *testing.T) {
+	const total = 7
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("perPage"))
+		assert.Empty(t, r.URL.Query().Get("limit"))
+
+		var rows []apitype.JobRun
+		for id := page * perPage; id < total && id < (page+1)*perPage; id++ {
+			rows = append(rows, apitype.JobRun{ID: id})
+		}
+		_ = json.NewEncoder(w).Encode(apitype.PaginationResult{Rows: rows, Page: page, PageSize: perPage, TotalRows: total})
+	}, Options{})
+
+	t.Run("fetches every page", func(t *testing.T) {
+		runs, err := c.JobRuns(context.Background(), "4.14", nil)
+		require.NoError(t, err)
+		assert.Len(t, runs, total)
+	})
+
+	t.Run("single page", func(t *testing.T) {
+		runs, totalRows, err := c.JobRunsPage(context.Background(), "4.14", nil, 1, 3)
+		require.NoError(t, err)
+		assert.EqualValues(t, total, totalRows)
+		require.Len(t, runs, 3)
+		assert.EqualValues(t, 3, runs[0].ID)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		runs, err := c.JobRuns(context.Background(), "4.14", &ListOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, runs, 2)
+	})
+}
+
+func TestComponentReportWaitsForQueuedReports(t *testing.T) {
+	var requests int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "4.14-main", r.URL.Query().Get("view"))
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"code": 202, "message": "the report is being generated, retry the request to get it"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"rows": [{"component": "Networking"}], "generated_at": "2023-06-20T12:00:00Z"}`))
+	}, Options{})
+
+	report, err := c.ComponentReport(context.Background(), "4.14-main")
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+	require.Len(t, report.Rows, 1)
+	assert.Equal(t, "Networking", report.Rows[0].Component)
+}
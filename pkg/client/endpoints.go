@@ -0,0 +1,378 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+// defaultPageSize is the number of rows requested per page when listing paginated endpoints.
+const defaultPageSize = 500
+
+// ListOptions filters and sorts report endpoints. The zero value returns all rows in the server's default order.
+type ListOptions struct {
+	Filter    *filter.Filter
+	SortField string
+	Sort      apitype.Sort
+	Limit     int
+}
+
+func (o *ListOptions) params(release string) (url.Values, error) {
+	params := url.Values{}
+	if release != "" {
+		params.Set("release", release)
+	}
+	if o == nil {
+		return params, nil
+	}
+	if o.Filter != nil {
+		f, err := json.Marshal(o.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding filter: %w", err)
+		}
+		params.Set("filter", string(f))
+	}
+	if o.SortField != "" {
+		params.Set("sortField", o.SortField)
+	}
+	if o.Sort != "" {
+		params.Set("sort", string(o.Sort))
+	}
+	if o.Limit > 0 {
+		params.Set("limit", strconv.Itoa(o.Limit))
+	}
+	return params, nil
+}
+
+// Jobs returns the jobs report for a release, with variants keyed by name.
+func (c *Client) Jobs(ctx context.Context, release string, opts *ListOptions) ([]apitype.JobV2, error) {
+	params, err := opts.params(release)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []apitype.JobV2
+	return jobs, c.do(ctx, http.MethodGet, "/api/v2/jobs", params, nil, &jobs)
+}
+
+// Tests returns the tests report for a release. An empty mode uses the server default, strict.
+func (c *Client) Tests(ctx context.Context, release string, mode apitype.PassRateMode, opts *ListOptions) ([]apitype.Test, error) {
+	params, err := opts.params(release)
+	if err != nil {
+		return nil, err
+	}
+	if mode != "" {
+		params.Set("pass_rate", string(mode))
+	}
+	var tests []apitype.Test
+	return tests, c.do(ctx, http.MethodGet, "/api/tests", params, nil, &tests)
+}
+
+// TestRecentRuns returns a test's results in its most recent runs in a release, newest first, in jobs with all
+// the given variants, i.e. Platform:aws. A limit of 0 uses the server default of 10.
+func (c *Client) TestRecentRuns(ctx context.Context, release, test string, variants []string, limit int) (*apitype.TestRecentRuns, error) {
+	params := url.Values{}
+	params.Set("release", release)
+	params.Set("test", test)
+	for _, v := range variants {
+		params.Add("variant", v)
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	runs := &apitype.TestRecentRuns{}
+	if err := c.do(ctx, http.MethodGet, "/api/tests/recent_runs", params, nil, runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// Variants returns the variants report for a release.
+func (c *Client) Variants(ctx context.Context, release string, opts *ListOptions) ([]apitype.Variant, error) {
+	params, err := opts.params(release)
+	if err != nil {
+		return nil, err
+	}
+	var variants []apitype.Variant
+	return variants, c.do(ctx, http.MethodGet, "/api/variants", params, nil, &variants)
+}
+
+// JobVariants returns every variant and its possible values, as used by component readiness.
+func (c *Client) JobVariants(ctx context.Context) (crtype.JobVariants, error) {
+	var variants crtype.JobVariants
+	return variants, c.do(ctx, http.MethodGet, "/api/job_variants", nil, nil, &variants)
+}
+
+// VariantKeys returns every variant key with its distinct values and job counts, for all releases if release is
+// empty.
+func (c *Client) VariantKeys(ctx context.Context, release string) ([]apitype.VariantKey, error) {
+	params := url.Values{}
+	if release != "" {
+		params.Set("release", release)
+	}
+	var keys []apitype.VariantKey
+	return keys, c.do(ctx, http.MethodGet, "/api/variants/keys", params, nil, &keys)
+}
+
+// Health returns the health summary of a release: pass rates of the key indicators and when data was last
+// imported.
+func (c *Client) Health(ctx context.Context, release string) (*apitype.Health, error) {
+	params := url.Values{}
+	params.Set("release", release)
+	health := &apitype.Health{}
+	if err := c.do(ctx, http.MethodGet, "/api/health", params, nil, health); err != nil {
+		return nil, err
+	}
+	return health, nil
+}
+
+// InstallReport is the install pass rate of each operator by variant.
+type InstallReport struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	ColumnNames []string `json:"column_names"`
+	// Tests maps each install test to its results by variant column.
+	Tests map[string]map[string]apitype.Test `json:"tests"`
+}
+
+// InstallReport returns the install report of a release.
+func (c *Client) InstallReport(ctx context.Context, release string) (*InstallReport, error) {
+	params := url.Values{}
+	params.Set("release", release)
+	report := &InstallReport{}
+	if err := c.do(ctx, http.MethodGet, "/api/install", params, nil, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ComponentReadinessViews returns the predefined component readiness views, with their relative dates resolved.
+func (c *Client) ComponentReadinessViews(ctx context.Context) ([]crtype.View, error) {
+	var views []crtype.View
+	return views, c.do(ctx, http.MethodGet, "/api/component_readiness/views", nil, nil, &views)
+}
+
+// ComponentReport returns the component readiness report of a view. Servers that generate reports in report
+// workers ask clients to retry while a report is being generated, which is done until it's ready or ctx is done.
+func (c *Client) ComponentReport(ctx context.Context, view string) (*crtype.ComponentReport, error) {
+	params := url.Values{}
+	params.Set("view", view)
+	for {
+		var report struct {
+			crtype.ComponentReport
+			Code int `json:"code"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/api/component_readiness", params, nil, &report); err != nil {
+			return nil, err
+		}
+		if report.Code != http.StatusAccepted {
+			return &report.ComponentReport, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.backoff):
+		}
+	}
+}
+
+// JobRuns returns all job runs matching opts for a release, fetching them a page at a time. opts.Limit caps the
+// total number of runs returned.
+func (c *Client) JobRuns(ctx context.Context, release string, opts *ListOptions) ([]apitype.JobRun, error) {
+	var runs []apitype.JobRun
+	for page := 0; ; page++ {
+		rows, total, err := c.JobRunsPage(ctx, release, opts, page, defaultPageSize)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, rows...)
+		if opts != nil && opts.Limit > 0 && len(runs) >= opts.Limit {
+			return runs[:opts.Limit], nil
+		}
+		if len(rows) == 0 || int64(len(runs)) >= total {
+			return runs, nil
+		}
+	}
+}
+
+// JobRunsPage returns a single page of job runs, and the total number of runs matching opts. Pages are numbered
+// from zero.
+func (c *Client) JobRunsPage(ctx context.Context, release string, opts *ListOptions, page, perPage int) ([]apitype.JobRun, int64, error) {
+	params, err := opts.params(release)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Limit is applied across pages by JobRuns, the server ignores it when paginating.
+	params.Del("limit")
+	params.Set("page", strconv.Itoa(page))
+	params.Set("perPage", strconv.Itoa(perPage))
+
+	var result struct {
+		Rows      []apitype.JobRun `json:"rows"`
+		TotalRows int64            `json:"total_rows"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/jobs/runs", params, nil, &result); err != nil {
+		return nil, 0, err
+	}
+	return result.Rows, result.TotalRows, nil
+}
+
+// JobRunRiskAnalysis returns the risk analysis for a job run already imported by sippy.
+func (c *Client) JobRunRiskAnalysis(ctx context.Context, prowJobRunID int64) (*apitype.ProwJobRunRiskAnalysis, error) {
+	params := url.Values{}
+	params.Set("prow_job_run_id", strconv.FormatInt(prowJobRunID, 10))
+	result := &apitype.ProwJobRunRiskAnalysis{}
+	if err := c.do(ctx, http.MethodGet, "/api/jobs/runs/risk_analysis", params, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// JobRunSearch is the metadata to search job runs by, see SearchJobRuns. Empty fields match any run.
+type JobRunSearch struct {
+	Release string
+	Job     string
+	// Payload is the release tag of a payload the run was part of.
+	Payload string
+	// PullRequest is a link to a pull request the run tested, or org/repo#number.
+	PullRequest  string
+	BuildCluster string
+	// Variants must all be variants of the run's job, in the form Name:value.
+	Variants []string
+	// Results are the overall results to match, i.e. F for test failures.
+	Results []string
+	// Start and End are the time range to search, zero times use the server defaults.
+	Start, End time.Time
+	Limit      int
+}
+
+// SearchJobRuns returns the most recent job runs matching a search.
+func (c *Client) SearchJobRuns(ctx context.Context, search JobRunSearch) ([]apitype.JobRunSummary, error) {
+	params := url.Values{}
+	for k, v := range map[string]string{
+		"release":       search.Release,
+		"job":           search.Job,
+		"payload":       search.Payload,
+		"pull_request":  search.PullRequest,
+		"build_cluster": search.BuildCluster,
+	} {
+		if v != "" {
+			params.Set(k, v)
+		}
+	}
+	for _, v := range search.Variants {
+		params.Add("variant", v)
+	}
+	for _, r := range search.Results {
+		params.Add("result", r)
+	}
+	if search.Limit > 0 {
+		params.Set("limit", strconv.Itoa(search.Limit))
+	}
+	setDateParams(params, search.Start, search.End)
+
+	var runs []apitype.JobRunSummary
+	return runs, c.do(ctx, http.MethodGet, "/api/jobs/runs/search", params, nil, &runs)
+}
+
+// TimeSeries returns pass/fail/flake counts over time for the selected job, test or variant. Zero start or end
+// times use the server defaults.
+func (c *Client) TimeSeries(ctx context.Context, selector apitype.TimeSeriesSelector, granularity apitype.TimeSeriesGranularity, start, end time.Time) (*apitype.TimeSeries, error) {
+	params := url.Values{}
+	for k, v := range map[string]string{
+		"release":     selector.Release,
+		"job":         selector.Job,
+		"test":        selector.Test,
+		"variant":     selector.Variant,
+		"pass_rate":   string(selector.PassRateMode),
+		"granularity": string(granularity),
+	} {
+		if v != "" {
+			params.Set(k, v)
+		}
+	}
+	if selector.ExcludeIncidents {
+		params.Set("exclude_incidents", "true")
+	}
+	if selector.PrimaryFailuresOnly {
+		params.Set("primary_failures_only", "true")
+	}
+	setDateParams(params, start, end)
+
+	result := &apitype.TimeSeries{}
+	if err := c.do(ctx, http.MethodGet, "/api/timeseries", params, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Incidents returns the triaged incidents overlapping start and end, optionally limited to a release or job.
+func (c *Client) Incidents(ctx context.Context, release, job string, start, end time.Time) ([]models.Incident, error) {
+	params := url.Values{}
+	if release != "" {
+		params.Set("release", release)
+	}
+	if job != "" {
+		params.Set("job", job)
+	}
+	setDateParams(params, start, end)
+
+	var incidents []models.Incident
+	return incidents, c.do(ctx, http.MethodGet, "/api/incidents/timeline", params, nil, &incidents)
+}
+
+// Incident returns a single incident by ID.
+func (c *Client) Incident(ctx context.Context, id uint) (*models.Incident, error) {
+	incident := &models.Incident{}
+	if err := c.do(ctx, http.MethodGet, "/api/incidents/timeline", idParams(id), nil, incident); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+// CreateIncident triages a new incident, returning it as stored.
+func (c *Client) CreateIncident(ctx context.Context, incident *models.Incident) (*models.Incident, error) {
+	created := &models.Incident{}
+	if err := c.do(ctx, http.MethodPost, "/api/incidents/timeline", nil, incident, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateIncident replaces the incident with the given ID, returning it as stored.
+func (c *Client) UpdateIncident(ctx context.Context, id uint, incident *models.Incident) (*models.Incident, error) {
+	updated := &models.Incident{}
+	if err := c.do(ctx, http.MethodPut, "/api/incidents/timeline", idParams(id), incident, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteIncident removes the incident with the given ID.
+func (c *Client) DeleteIncident(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, "/api/incidents/timeline", idParams(id), nil, nil)
+}
+
+func idParams(id uint) url.Values {
+	params := url.Values{}
+	params.Set("id", strconv.FormatUint(uint64(id), 10))
+	return params
+}
+
+// setDateParams sets the start and end params, which the server parses as dates.
+func setDateParams(params url.Values, start, end time.Time) {
+	if !start.IsZero() {
+		params.Set("start", start.Format("2006-01-02"))
+	}
+	if !end.IsZero() {
+		params.Set("end", end.Format("2006-01-02"))
+	}
+}
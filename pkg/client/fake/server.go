@@ -0,0 +1,245 @@
+// Package fake provides a sippy API server that serves canned responses from fixtures, so consumers of the client
+// package can run integration tests without a database or network access.
+package fake
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openshift/sippy/pkg/client"
+)
+
+// fixtures contain an example response for each endpoint, named after the endpoint path without the /api prefix
+// and with dots for slashes, i.e. jobs.runs.json for /api/jobs/runs. Examples from the API docs are used where
+// there are any. Endpoints that don't return JSON, like exports, have fixtures with their own extension.
+//
+//go:embed fixtures/*.json fixtures/*.xml
+var fixtures embed.FS
+
+// Request is a request received by the server.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+type response struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// Server is a fake sippy API. GET requests return the fixture for the path, regardless of query parameters other
+// than pagination. Incidents can be created, updated and deleted, but changes aren't persisted.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]response
+	requests  []Request
+}
+
+// NewServer starts a fake server with the default fixtures. Callers should Close it when done.
+func NewServer() *Server {
+	s := &Server{responses: map[string]response{}}
+
+	entries, err := fixtures.ReadDir("fixtures")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range entries {
+		body, err := fixtures.ReadFile(path.Join("fixtures", e.Name()))
+		if err != nil {
+			panic(err)
+		}
+		ext := path.Ext(e.Name())
+		endpoint := "/api/" + strings.ReplaceAll(strings.TrimSuffix(e.Name(), ext), ".", "/")
+		s.responses[endpoint] = response{status: http.StatusOK, contentType: mime.TypeByExtension(ext), body: body}
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// SippyClient returns a sippy API client for the server.
+func (s *Server) SippyClient(opts client.Options) *client.Client {
+	c, err := client.New(s.URL, opts)
+	if err != nil {
+		// The server URL is always valid.
+		panic(err)
+	}
+	return c
+}
+
+// SetResponse replaces the response for an endpoint path, i.e. /api/jobs, with v encoded as JSON.
+func (s *Server) SetResponse(endpoint string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[endpoint] = response{status: http.StatusOK, body: body}
+	return nil
+}
+
+// SetError makes an endpoint path respond with a sippy error, to test how consumers handle failures.
+func (s *Server) SetError(endpoint string, status int, message string) {
+	body, _ := json.Marshal(map[string]interface{}{"code": status, "message": message})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[endpoint] = response{status: status, body: body}
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{Method: req.Method, Path: req.URL.Path, Query: req.URL.RawQuery, Body: body})
+	resp, ok := s.responses[req.URL.Path]
+	s.mu.Unlock()
+
+	if !ok {
+		respondWithError(w, http.StatusNotFound, fmt.Sprintf("no fixture for %s", req.URL.Path))
+		return
+	}
+	if resp.status != http.StatusOK {
+		respond(w, resp.status, resp.body)
+		return
+	}
+
+	switch {
+	case resp.contentType != "" && !strings.HasPrefix(resp.contentType, "application/json"):
+		w.Header().Set("Content-Type", resp.contentType)
+		_, _ = w.Write(resp.body)
+	case req.URL.Path == "/api/incidents/timeline":
+		serveIncidents(w, req, resp.body, body)
+	case req.Method != http.MethodGet:
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+	case req.URL.Query().Get("perPage") != "":
+		servePage(w, req, resp.body)
+	default:
+		respond(w, http.StatusOK, resp.body)
+	}
+}
+
+// servePage wraps a page of a list fixture in a pagination result, as the server does when perPage is set.
+func servePage(w http.ResponseWriter, req *http.Request, fixture []byte) {
+	var rows []json.RawMessage
+	if err := json.Unmarshal(fixture, &rows); err != nil {
+		respond(w, http.StatusOK, fixture)
+		return
+	}
+	perPage, err := strconv.Atoi(req.URL.Query().Get("perPage"))
+	if err != nil || perPage < 1 {
+		respondWithError(w, http.StatusBadRequest, "invalid perPage")
+		return
+	}
+	page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+
+	pageRows := []json.RawMessage{}
+	if start := page * perPage; start < len(rows) {
+		end := start + perPage
+		if end > len(rows) {
+			end = len(rows)
+		}
+		pageRows = rows[start:end]
+	}
+	respondWithJSON(w, http.StatusOK, map[string]interface{}{
+		"rows":       pageRows,
+		"page_size":  perPage,
+		"page":       page,
+		"total_rows": len(rows),
+	})
+}
+
+// serveIncidents fakes incident CRUD against the incidents fixture. Created and updated incidents are echoed
+// back, but later requests still see the fixture.
+func serveIncidents(w http.ResponseWriter, req *http.Request, fixture, body []byte) {
+	id, _ := strconv.ParseUint(req.URL.Query().Get("id"), 10, 64)
+	if id == 0 && (req.Method == http.MethodPut || req.Method == http.MethodDelete) {
+		respondWithError(w, http.StatusBadRequest, "id is required")
+		return
+	}
+
+	var incidents []map[string]interface{}
+	if err := json.Unmarshal(fixture, &incidents); err != nil {
+		respondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	find := func() map[string]interface{} {
+		for _, incident := range incidents {
+			if incidentID, ok := incident["id"].(float64); ok && uint64(incidentID) == id {
+				return incident
+			}
+		}
+		return nil
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+		if id == 0 {
+			respond(w, http.StatusOK, fixture)
+		} else if incident := find(); incident != nil {
+			respondWithJSON(w, http.StatusOK, incident)
+		} else {
+			respondWithError(w, http.StatusNotFound, "incident not found")
+		}
+	case http.MethodPost, http.MethodPut:
+		var incident map[string]interface{}
+		if err := json.Unmarshal(body, &incident); err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Sprintf("error decoding incident json in request body: %s", err))
+			return
+		}
+		status := http.StatusOK
+		if req.Method == http.MethodPost {
+			status = http.StatusCreated
+			id = uint64(len(incidents) + 1)
+		} else if find() == nil {
+			respondWithError(w, http.StatusNotFound, "incident not found")
+			return
+		}
+		incident["id"] = id
+		respondWithJSON(w, status, incident)
+	case http.MethodDelete:
+		if find() == nil {
+			respondWithError(w, http.StatusNotFound, "incident not found")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, map[string]interface{}{"id": id})
+	default:
+		respondWithError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func respond(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+func respondWithJSON(w http.ResponseWriter, status int, v interface{}) {
+	body, _ := json.Marshal(v)
+	respond(w, status, body)
+}
+
+func respondWithError(w http.ResponseWriter, status int, message string) {
+	respondWithJSON(w, status, map[string]interface{}{"code": status, "message": message})
+}
@@ -0,0 +1,151 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/client"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// TestDocumentedEndpointsHaveFixtures ensures new endpoints added to the API docs get a fixture.
+func TestDocumentedEndpointsHaveFixtures(t *testing.T) {
+	readme, err := os.ReadFile("../../api/README.md")
+	require.NoError(t, err)
+
+	s := NewServer()
+	defer s.Close()
+
+	endpoints := regexp.MustCompile("Endpoint: `(/api/[a-z0-9_/]+)`").FindAllSubmatch(readme, -1)
+	require.NotEmpty(t, endpoints)
+	for _, m := range endpoints {
+		_, ok := s.responses[string(m[1])]
+		assert.True(t, ok, "no fixture for documented endpoint %s", m[1])
+	}
+}
+
+// TestClient exercises each client method against the fixtures, which also checks the fixtures decode into the
+// API types.
+func TestClient(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := s.SippyClient(client.Options{})
+	ctx := context.Background()
+
+	jobs, err := c.Jobs(ctx, "4.14", nil)
+	require.NoError(t, err)
+	require.NotEmpty(t, jobs)
+	assert.NotEmpty(t, jobs[0].Variants["Platform"])
+
+	tests, err := c.Tests(ctx, "4.14", apitype.PassRateLenient, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tests)
+
+	recent, err := c.TestRecentRuns(ctx, "4.14", "[sig-network] pods should connect", []string{"Platform:aws"}, 3)
+	require.NoError(t, err)
+	require.Len(t, recent.Runs, 3)
+	assert.Equal(t, "fail", recent.Runs[1].Status)
+	assert.NotNil(t, recent.LastFailure)
+
+	variants, err := c.Variants(ctx, "4.14", nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, variants)
+
+	jobVariants, err := c.JobVariants(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, jobVariants.Variants["Platform"])
+
+	keys, err := c.VariantKeys(ctx, "4.14")
+	require.NoError(t, err)
+	require.NotEmpty(t, keys)
+	assert.NotEmpty(t, keys[0].Values)
+
+	health, err := c.Health(ctx, "4.14")
+	require.NoError(t, err)
+	assert.NotEmpty(t, health.Indicators)
+	assert.False(t, health.LastUpdated.IsZero())
+
+	install, err := c.InstallReport(ctx, "4.14")
+	require.NoError(t, err)
+	assert.Contains(t, install.ColumnNames, "All")
+	assert.NotEmpty(t, install.Tests)
+
+	views, err := c.ComponentReadinessViews(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, views)
+	report, err := c.ComponentReport(ctx, views[0].Name)
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Rows)
+	assert.NotNil(t, report.GeneratedAt)
+
+	runs, err := c.JobRuns(ctx, "4.14", nil)
+	require.NoError(t, err)
+	assert.Len(t, runs, 3)
+	runs, total, err := c.JobRunsPage(ctx, "4.14", nil, 1, 2)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	assert.Len(t, runs, 1)
+
+	found, err := c.SearchJobRuns(ctx, client.JobRunSearch{Payload: "4.14.0-0.nightly-2023-06-19-121456", Results: []string{"F"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, found)
+	assert.NotEmpty(t, found[0].Links["risk_analysis"])
+
+	risk, err := c.JobRunRiskAnalysis(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "High", risk.OverallRisk.Level.Name)
+
+	series, err := c.TimeSeries(ctx, apitype.TimeSeriesSelector{Job: "some-job"}, apitype.TimeSeriesDay, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, series.Buckets)
+
+	incidents, err := c.Incidents(ctx, "", "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.NotEmpty(t, incidents)
+	incident, err := c.Incident(ctx, incidents[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, incidents[0].Title, incident.Title)
+	created, err := c.CreateIncident(ctx, &models.Incident{Title: "new", StartTime: time.Now()})
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, "new", created.Title)
+	require.NoError(t, c.DeleteIncident(ctx, incidents[0].ID))
+
+	var apiErr *client.APIError
+	_, err = c.Incident(ctx, 12345)
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+}
+
+func TestOverrides(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	c := s.SippyClient(client.Options{MaxRetries: -1})
+	ctx := context.Background()
+
+	require.NoError(t, s.SetResponse("/api/variants", []apitype.Variant{{Name: "custom"}}))
+	variants, err := c.Variants(ctx, "4.14", nil)
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+	assert.Equal(t, "custom", variants[0].Name)
+
+	s.SetError("/api/tests", http.StatusInternalServerError, "database unavailable")
+	_, err = c.Tests(ctx, "4.14", "", nil)
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "database unavailable", apiErr.Message)
+
+	requests := s.Requests()
+	require.Len(t, requests, 2)
+	assert.Equal(t, "/api/tests", requests[1].Path)
+	assert.Equal(t, "release=4.14", requests[1].Query)
+}
@@ -0,0 +1,205 @@
+// Package notifier sends the changes in a component readiness view's report since its previous generation to the
+// view's configured channels, so teams are told when cells go red or recover without watching the dashboard.
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/apis/cache"
+	"github.com/openshift/sippy/pkg/notification"
+)
+
+// snapshotDuration is how long a view's last notified state is kept. If it expires, the next generation is
+// recorded as a new baseline without notifying.
+const snapshotDuration = 30 * 24 * time.Hour
+
+// Diff is the change in a view's regressed cells between two report generations.
+type Diff struct {
+	View string `json:"view"`
+	// Regressed are cells that are newly significantly regressed.
+	Regressed []string `json:"regressed"`
+	// Resolved are cells that were significantly regressed in the previous generation but no longer are.
+	Resolved []string `json:"resolved"`
+}
+
+// Empty returns true if nothing changed.
+func (d Diff) Empty() bool {
+	return len(d.Regressed) == 0 && len(d.Resolved) == 0
+}
+
+// Text renders the diff as a short plain text message.
+func (d Diff) Text() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Component readiness changes for view %s:\n", d.View)
+	for _, cell := range d.Regressed {
+		fmt.Fprintf(&sb, "• regressed: %s\n", cell)
+	}
+	for _, cell := range d.Resolved {
+		fmt.Fprintf(&sb, "• resolved: %s\n", cell)
+	}
+	return sb.String()
+}
+
+// WebhookPayload is the body posted to a view's webhook. The text field makes it usable as a Slack incoming
+// webhook message, the remaining fields are for other consumers.
+type WebhookPayload struct {
+	Text string `json:"text"`
+	Diff
+}
+
+// snapshot is the state of a view's report at the last generation the notifier saw.
+type snapshot struct {
+	GeneratedAt *time.Time `json:"generated_at"`
+	Regressed   []string   `json:"regressed"`
+}
+
+// Notifier compares each generation of a view's report against the previous one, and sends the differences to
+// the view's notification channels.
+type Notifier struct {
+	smtp notification.SMTPConfig
+}
+
+func New(smtpConfig notification.SMTPConfig) *Notifier {
+	return &Notifier{smtp: smtpConfig}
+}
+
+// Notify records the regressed cells of the view's report in the cache, and if they differ from the previous
+// generation, sends the difference to the view's channels. The first generation seen is only recorded.
+func (n *Notifier) Notify(ctx context.Context, c cache.Cache, view crtype.View, report *crtype.ComponentReport) error {
+	key := "component-readiness-notifier~" + view.Name
+	current := snapshot{GeneratedAt: report.GeneratedAt, Regressed: RegressedCells(report)}
+
+	var previous *snapshot
+	if b, err := c.Get(key); err == nil {
+		previous = &snapshot{}
+		if err := json.Unmarshal(b, previous); err != nil {
+			log.WithError(err).WithField("view", view.Name).Warning("discarding unreadable notification snapshot")
+			previous = nil
+		}
+	}
+	if previous != nil && previous.GeneratedAt != nil && current.GeneratedAt != nil &&
+		previous.GeneratedAt.Equal(*current.GeneratedAt) {
+		// Same generation as last time, nothing new to compare
+		return nil
+	}
+
+	if previous != nil {
+		diff := DiffCells(view.Name, previous.Regressed, current.Regressed)
+		if !diff.Empty() {
+			if err := n.send(ctx, view.Notifications, diff); err != nil {
+				// Leave the snapshot alone so the diff is sent on the next generation
+				return err
+			}
+		}
+	}
+
+	b, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	return c.Set(key, b, snapshotDuration)
+}
+
+func (n *Notifier) send(ctx context.Context, notifications crtype.ViewNotifications, diff Diff) error {
+	destinations, err := Destinations(notifications, n.smtp)
+	if err != nil {
+		return err
+	}
+	msg := notification.Message{
+		Subject: "Component readiness changes for " + diff.View,
+		Text:    diff.Text(),
+		Data:    WebhookPayload{Text: diff.Text(), Diff: diff},
+	}
+	if err := notification.Send(ctx, msg, destinations...); err != nil {
+		return fmt.Errorf("error sending notifications for view %s: %w", diff.View, err)
+	}
+	return nil
+}
+
+// Destinations returns where a view's notifications are sent, each rendered with the view's template.
+func Destinations(notifications crtype.ViewNotifications, smtpConfig notification.SMTPConfig) ([]notification.Destination, error) {
+	var tmpl *template.Template
+	if notifications.Template != "" {
+		var err error
+		if tmpl, err = template.New("view").Parse(notifications.Template); err != nil {
+			return nil, fmt.Errorf("invalid notification template: %w", err)
+		}
+	}
+
+	destinations := []notification.Destination{}
+	if notifications.WebhookURL != "" {
+		destinations = append(destinations, notification.Destination{
+			Transport: notification.WebhookURL(notifications.WebhookURL),
+			Template:  tmpl,
+		})
+	}
+	if len(notifications.Email) > 0 {
+		destinations = append(destinations, notification.Destination{
+			Transport: notification.NewEmailTransport(smtpConfig, notifications.Email),
+			Template:  tmpl,
+		})
+	}
+	return destinations, nil
+}
+
+// RegressedCells returns a description of each significantly regressed cell in the report, sorted. Triaged
+// regressions are not included.
+func RegressedCells(report *crtype.ComponentReport) []string {
+	cells := []string{}
+	for _, row := range report.Rows {
+		for _, col := range row.Columns {
+			if col.Status <= crtype.SignificantRegression {
+				cells = append(cells, cellName(row.RowIdentification, col.ColumnIdentification))
+			}
+		}
+	}
+	sort.Strings(cells)
+	return cells
+}
+
+func cellName(row crtype.RowIdentification, col crtype.ColumnIdentification) string {
+	name := row.Component
+	if row.Capability != "" {
+		name += "/" + row.Capability
+	}
+
+	variants := make([]string, 0, len(col.Variants))
+	for key, value := range col.Variants {
+		variants = append(variants, key+":"+value)
+	}
+	sort.Strings(variants)
+	return fmt.Sprintf("%s [%s]", name, strings.Join(variants, " "))
+}
+
+// DiffCells compares the regressed cells of two generations of a view's report.
+func DiffCells(view string, previous, current []string) Diff {
+	diff := Diff{View: view, Regressed: []string{}, Resolved: []string{}}
+	previousSet := map[string]bool{}
+	for _, cell := range previous {
+		previousSet[cell] = true
+	}
+	currentSet := map[string]bool{}
+	for _, cell := range current {
+		currentSet[cell] = true
+		if !previousSet[cell] {
+			diff.Regressed = append(diff.Regressed, cell)
+		}
+	}
+	for _, cell := range previous {
+		if !currentSet[cell] {
+			diff.Resolved = append(diff.Resolved, cell)
+		}
+	}
+	sort.Strings(diff.Regressed)
+	sort.Strings(diff.Resolved)
+	return diff
+}
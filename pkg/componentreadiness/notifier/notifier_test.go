@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/notification"
+)
+
+type memoryCache map[string][]byte
+
+func (m memoryCache) Get(key string) ([]byte, error) {
+	if b, ok := m[key]; ok {
+		return b, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (m memoryCache) Set(key string, content []byte, _ time.Duration) error {
+	m[key] = content
+	return nil
+}
+
+func report(generatedAt time.Time, statuses map[string]crtype.Status) *crtype.ComponentReport {
+	r := &crtype.ComponentReport{GeneratedAt: &generatedAt}
+	for component, status := range statuses {
+		r.Rows = append(r.Rows, crtype.ReportRow{
+			RowIdentification: crtype.RowIdentification{Component: component},
+			Columns: []crtype.ReportColumn{{
+				ColumnIdentification: crtype.ColumnIdentification{Variants: map[string]string{"Platform": "aws", "Arch": "amd64"}},
+				Status:               status,
+			}},
+		})
+	}
+	return r
+}
+
+func TestRegressedCells(t *testing.T) {
+	cells := RegressedCells(report(time.Now(), map[string]crtype.Status{
+		"networking": crtype.ExtremeRegression,
+		"etcd":       crtype.SignificantRegression,
+		"storage":    crtype.SignificantTriagedRegression,
+		"apiserver":  crtype.NotSignificant,
+	}))
+	assert.Equal(t, []string{"etcd [Arch:amd64 Platform:aws]", "networking [Arch:amd64 Platform:aws]"}, cells)
+}
+
+func TestDiffCells(t *testing.T) {
+	diff := DiffCells("main", []string{"a", "b"}, []string{"b", "c"})
+	assert.Equal(t, []string{"c"}, diff.Regressed)
+	assert.Equal(t, []string{"a"}, diff.Resolved)
+	assert.False(t, diff.Empty())
+	assert.True(t, DiffCells("main", []string{"a"}, []string{"a"}).Empty())
+}
+
+func TestNotify(t *testing.T) {
+	var payloads []WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p WebhookPayload
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		payloads = append(payloads, p)
+	}))
+	defer server.Close()
+
+	c := memoryCache{}
+	n := New(notification.SMTPConfig{})
+	view := crtype.View{Name: "main", Notifications: crtype.ViewNotifications{WebhookURL: server.URL}}
+	ctx := context.Background()
+	generated := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// The first generation is only recorded
+	require.NoError(t, n.Notify(ctx, c, view, report(generated, map[string]crtype.Status{"etcd": crtype.SignificantRegression})))
+	assert.Empty(t, payloads)
+
+	// A new generation with changes is sent
+	generated = generated.Add(4 * time.Hour)
+	second := report(generated, map[string]crtype.Status{"networking": crtype.ExtremeRegression, "etcd": crtype.NotSignificant})
+	require.NoError(t, n.Notify(ctx, c, view, second))
+	require.Len(t, payloads, 1)
+	assert.Equal(t, []string{"networking [Arch:amd64 Platform:aws]"}, payloads[0].Regressed)
+	assert.Equal(t, []string{"etcd [Arch:amd64 Platform:aws]"}, payloads[0].Resolved)
+	assert.Contains(t, payloads[0].Text, "regressed: networking")
+
+	// The same generation again is not re-sent
+	require.NoError(t, n.Notify(ctx, c, view, second))
+	assert.Len(t, payloads, 1)
+
+	// Neither is a new generation without changes
+	generated = generated.Add(4 * time.Hour)
+	require.NoError(t, n.Notify(ctx, c, view, report(generated, map[string]crtype.Status{"networking": crtype.ExtremeRegression})))
+	assert.Len(t, payloads, 1)
+}
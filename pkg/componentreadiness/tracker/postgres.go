@@ -0,0 +1,103 @@
+package tracker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// PostgresRegressionStore stores regressions in the local database, so their lifecycle can be reported on
+// alongside the rest of sippy's data.
+type PostgresRegressionStore struct {
+	dbc *db.DB
+}
+
+func NewPostgresRegressionStore(dbc *db.DB) RegressionStore {
+	return &PostgresRegressionStore{dbc: dbc}
+}
+
+func (p *PostgresRegressionStore) ListCurrentRegressionsForRelease(release string) ([]crtype.TestRegression, error) {
+	// As with BigQuery, include recently closed regressions so tests flapping in and out of the report re-open
+	// their existing regression.
+	var dbRegressions []models.TestRegression
+	res := p.dbc.DB.
+		Where("release = ?", release).
+		Where("closed IS NULL OR closed > ?", time.Now().Add(-48*time.Hour)).
+		Find(&dbRegressions)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	regressions := make([]crtype.TestRegression, 0, len(dbRegressions))
+	for _, r := range dbRegressions {
+		regressions = append(regressions, toTestRegression(r))
+	}
+	return regressions, nil
+}
+
+func (p *PostgresRegressionStore) OpenRegression(view crtype.View, newRegressedTest crtype.ReportTestSummary) (*crtype.TestRegression, error) {
+	variants := make([]string, 0, len(newRegressedTest.Variants))
+	for key, value := range newRegressedTest.Variants {
+		variants = append(variants, key+":"+value)
+	}
+	sort.Strings(variants)
+
+	newRegression := models.TestRegression{
+		View:      view.Name,
+		Release:   view.SampleRelease.Release,
+		TestID:    newRegressedTest.TestID,
+		TestName:  newRegressedTest.TestName,
+		Component: newRegressedTest.Component,
+		Variants:  variants,
+		Opened:    time.Now(),
+	}
+	if res := p.dbc.DB.Create(&newRegression); res.Error != nil {
+		return nil, res.Error
+	}
+	tr := toTestRegression(newRegression)
+	return &tr, nil
+}
+
+func (p *PostgresRegressionStore) ReOpenRegression(regressionID string) error {
+	return p.updateClosed(regressionID, nil)
+}
+
+func (p *PostgresRegressionStore) CloseRegression(regressionID string, closedAt time.Time) error {
+	return p.updateClosed(regressionID, &closedAt)
+}
+
+func (p *PostgresRegressionStore) updateClosed(regressionID string, closed *time.Time) error {
+	id, err := strconv.ParseUint(regressionID, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid regression id %q: %w", regressionID, err)
+	}
+	return p.dbc.DB.Model(&models.TestRegression{}).Where("id = ?", id).Update("closed", closed).Error
+}
+
+// toTestRegression converts a database regression to the type shared with the BigQuery store.
+func toTestRegression(r models.TestRegression) crtype.TestRegression {
+	tr := crtype.TestRegression{
+		View:         bigquery.NullString{StringVal: r.View, Valid: true},
+		Release:      r.Release,
+		TestID:       r.TestID,
+		TestName:     r.TestName,
+		RegressionID: strconv.FormatUint(uint64(r.ID), 10),
+		Opened:       r.Opened,
+	}
+	if r.Closed != nil {
+		tr.Closed = bigquery.NullTimestamp{Timestamp: *r.Closed, Valid: true}
+	}
+	for _, v := range r.Variants {
+		key, value, _ := strings.Cut(v, ":")
+		tr.Variants = append(tr.Variants, crtype.Variant{Key: key, Value: value})
+	}
+	return tr
+}
@@ -0,0 +1,166 @@
+// Package dashboards defines dashboards as code: named panels, each bound to a report served by the sippy API, that
+// the frontend renders generically. Dashboards are defined in YAML, either in the definitions directory, which is
+// built into sippy so teams can add theirs with a PR, or stored in the database through the API.
+package dashboards
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	PanelTable     = "table"
+	PanelLineChart = "line_chart"
+	PanelBarChart  = "bar_chart"
+	PanelStat      = "stat"
+
+	SourceRepo = "repo"
+	SourceDB   = "db"
+
+	// ReleasePlaceholder in a report parameter is replaced by the release the dashboard is rendered for.
+	ReleasePlaceholder = "${release}"
+)
+
+//go:embed definitions/*.yaml
+var definitions embed.FS
+
+var (
+	validPanelTypes = map[string]bool{
+		PanelTable:     true,
+		PanelLineChart: true,
+		PanelBarChart:  true,
+		PanelStat:      true,
+	}
+	nameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+)
+
+// Dashboard is a named, ordered set of panels.
+type Dashboard struct {
+	Name        string  `json:"name" yaml:"name"`
+	Title       string  `json:"title" yaml:"title"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Owner       string  `json:"owner,omitempty" yaml:"owner,omitempty"`
+	Panels      []Panel `json:"panels" yaml:"panels"`
+
+	// Source is where the dashboard is defined, repo or db.
+	Source string `json:"source" yaml:"-"`
+}
+
+// Panel renders one report.
+type Panel struct {
+	Name  string `json:"name" yaml:"name"`
+	Title string `json:"title" yaml:"title"`
+	Type  string `json:"type" yaml:"type"`
+	// Width is the panel's share of a 12 column row, defaulting to the full row.
+	Width  int    `json:"width" yaml:"width,omitempty"`
+	Report Report `json:"report" yaml:"report"`
+}
+
+// Report is the API request a panel renders, with any columns or fields to show from its response.
+type Report struct {
+	Endpoint string            `json:"endpoint" yaml:"endpoint"`
+	Params   map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	Fields   []string          `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// Parse parses and validates a YAML dashboard definition.
+func Parse(data []byte) (*Dashboard, error) {
+	d := &Dashboard{}
+	if err := yaml.Unmarshal(data, d); err != nil {
+		return nil, errors.Wrap(err, "invalid dashboard yaml")
+	}
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Validate ensures a dashboard is well-formed, and defaults panel widths.
+func (d *Dashboard) Validate() error {
+	if !nameRegex.MatchString(d.Name) {
+		return fmt.Errorf("invalid dashboard name %q: must be lowercase letters, digits and dashes", d.Name)
+	}
+	if strings.TrimSpace(d.Title) == "" {
+		return fmt.Errorf("dashboard %s has no title", d.Name)
+	}
+	if len(d.Panels) == 0 {
+		return fmt.Errorf("dashboard %s has no panels", d.Name)
+	}
+
+	names := map[string]bool{}
+	for i := range d.Panels {
+		p := &d.Panels[i]
+		if !nameRegex.MatchString(p.Name) {
+			return fmt.Errorf("invalid panel name %q in dashboard %s", p.Name, d.Name)
+		}
+		if names[p.Name] {
+			return fmt.Errorf("duplicate panel %s in dashboard %s", p.Name, d.Name)
+		}
+		names[p.Name] = true
+		if !validPanelTypes[p.Type] {
+			return fmt.Errorf("panel %s has invalid type %q: must be table, line_chart, bar_chart or stat", p.Name, p.Type)
+		}
+		if p.Width == 0 {
+			p.Width = 12
+		}
+		if p.Width < 1 || p.Width > 12 {
+			return fmt.Errorf("panel %s width must be between 1 and 12", p.Name)
+		}
+		if !strings.HasPrefix(p.Report.Endpoint, "/api/") || strings.ContainsAny(p.Report.Endpoint, "?#") {
+			return fmt.Errorf("panel %s report endpoint %q must be an /api/ path, with parameters in params",
+				p.Name, p.Report.Endpoint)
+		}
+	}
+	return nil
+}
+
+// ForRelease returns a copy of the dashboard with the release placeholder in report parameters replaced.
+func (d Dashboard) ForRelease(release string) Dashboard {
+	panels := make([]Panel, len(d.Panels))
+	for i, p := range d.Panels {
+		if len(p.Report.Params) > 0 {
+			params := make(map[string]string, len(p.Report.Params))
+			for k, v := range p.Report.Params {
+				params[k] = strings.ReplaceAll(v, ReleasePlaceholder, release)
+			}
+			p.Report.Params = params
+		}
+		panels[i] = p
+	}
+	d.Panels = panels
+	return d
+}
+
+// Builtin returns the dashboards defined in the repo, sorted by name.
+func Builtin() ([]Dashboard, error) {
+	entries, err := definitions.ReadDir("definitions")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Dashboard
+	for _, e := range entries {
+		data, err := definitions.ReadFile(path.Join("definitions", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		d, err := Parse(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing %s", e.Name())
+		}
+		if d.Name+".yaml" != e.Name() {
+			return nil, fmt.Errorf("dashboard %s must be defined in %s.yaml", d.Name, d.Name)
+		}
+		d.Source = SourceRepo
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
@@ -0,0 +1,81 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validDashboard = `
+name: networking
+title: Networking
+panels:
+  - name: jobs
+    title: OVN jobs
+    type: table
+    width: 6
+    report:
+      endpoint: /api/v2/jobs
+      params:
+        release: ${release}
+        filter: '{"items":[{"columnField":"name","operatorValue":"contains","value":"ovn"}]}'
+  - name: anomalies
+    title: Anomalies
+    type: bar_chart
+    report:
+      endpoint: /api/anomalies
+`
+
+func TestParse(t *testing.T) {
+	d, err := Parse([]byte(validDashboard))
+	require.NoError(t, err)
+	assert.Equal(t, "networking", d.Name)
+	require.Len(t, d.Panels, 2)
+	assert.Equal(t, 6, d.Panels[0].Width)
+	assert.Equal(t, 12, d.Panels[1].Width, "width should default to the full row")
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(d *Dashboard)
+	}{
+		{name: "invalid name", mutate: func(d *Dashboard) { d.Name = "Networking Team" }},
+		{name: "missing title", mutate: func(d *Dashboard) { d.Title = "" }},
+		{name: "no panels", mutate: func(d *Dashboard) { d.Panels = nil }},
+		{name: "duplicate panel", mutate: func(d *Dashboard) { d.Panels[1].Name = d.Panels[0].Name }},
+		{name: "invalid type", mutate: func(d *Dashboard) { d.Panels[0].Type = "pie" }},
+		{name: "invalid width", mutate: func(d *Dashboard) { d.Panels[0].Width = 13 }},
+		{name: "non-api endpoint", mutate: func(d *Dashboard) { d.Panels[0].Report.Endpoint = "https://example.com/api/jobs" }},
+		{name: "query in endpoint", mutate: func(d *Dashboard) { d.Panels[0].Report.Endpoint = "/api/jobs?release=4.16" }},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := Parse([]byte(validDashboard))
+			require.NoError(t, err)
+			tc.mutate(d)
+			assert.Error(t, d.Validate())
+		})
+	}
+}
+
+func TestForRelease(t *testing.T) {
+	d, err := Parse([]byte(validDashboard))
+	require.NoError(t, err)
+
+	rendered := d.ForRelease("4.16")
+	assert.Equal(t, "4.16", rendered.Panels[0].Report.Params["release"])
+	assert.Equal(t, ReleasePlaceholder, d.Panels[0].Report.Params["release"], "original should be unchanged")
+	assert.Nil(t, rendered.Panels[1].Report.Params)
+}
+
+func TestBuiltin(t *testing.T) {
+	builtin, err := Builtin()
+	require.NoError(t, err)
+	require.NotEmpty(t, builtin)
+	for _, d := range builtin {
+		assert.Equal(t, SourceRepo, d.Source)
+	}
+}
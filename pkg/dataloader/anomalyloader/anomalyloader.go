@@ -0,0 +1,82 @@
+package anomalyloader
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/anomaly"
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+var logger = logging.ForSubsystem("anomalyloader")
+
+// AnomalyLoader detects pass rate anomalies for jobs and components and, if configured, posts any
+// found on the most recent day to a webhook. Runs during known incidents are excluded.
+type AnomalyLoader struct {
+	ctx        context.Context
+	dbc        *db.DB
+	releases   []string
+	webhookURL string
+	errors     []error
+}
+
+func New(ctx context.Context, dbc *db.DB, releases []string, webhookURL string) *AnomalyLoader {
+	return &AnomalyLoader{
+		ctx:        ctx,
+		dbc:        dbc,
+		releases:   releases,
+		webhookURL: webhookURL,
+	}
+}
+
+func (al *AnomalyLoader) Name() string {
+	return "anomalies"
+}
+
+func (al *AnomalyLoader) Errors() []error {
+	return al.errors
+}
+
+func (al *AnomalyLoader) Load() {
+	end := time.Now().UTC()
+	start := end.Add(-14 * 24 * time.Hour)
+	// Only notify for anomalies on the last full day and today, older ones were reported by previous runs.
+	notifyAfter := end.Truncate(24 * time.Hour).Add(-24 * time.Hour)
+
+	var notify []apitype.PassRateAnomaly
+	for _, release := range al.releases {
+		for _, kind := range []string{api.AnomalyKindJob, api.AnomalyKindComponent} {
+			anomalies, err := api.GetPassRateAnomalies(al.dbc, release, kind, start, end, true, anomaly.DefaultConfig())
+			if err != nil {
+				al.errors = append(al.errors, errors.Wrapf(err, "error detecting %s anomalies for %s", kind, release))
+				continue
+			}
+			logger.WithFields(log.Fields{
+				"release":   release,
+				"kind":      kind,
+				"anomalies": len(anomalies),
+			}).Info("detected pass rate anomalies")
+
+			for _, a := range anomalies {
+				if !a.Date.Before(notifyAfter) {
+					notify = append(notify, a)
+				}
+			}
+		}
+	}
+
+	if al.webhookURL == "" || len(notify) == 0 {
+		return
+	}
+	if err := anomaly.PostWebhook(al.ctx, al.webhookURL, notify); err != nil {
+		al.errors = append(al.errors, errors.Wrap(err, "error posting anomalies to webhook"))
+		return
+	}
+	logger.Infof("posted %d anomalies to webhook", len(notify))
+}
@@ -17,9 +17,12 @@ import (
 	"github.com/openshift/sippy/pkg/bigquery"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/testidentification"
 )
 
+var logger = logging.ForSubsystem("bugloader")
+
 const (
 	// Unfortunate cross-project join
 	ComponentMappingProject = "openshift-gce-devel"
@@ -131,7 +134,7 @@ func (bl *BugLoader) Load() {
 			UpdateAll: true,
 		}).Create(bug)
 		if res.Error != nil {
-			log.Errorf("error creating bug: %s %v", res.Error, bug)
+			logger.Errorf("error creating bug: %s %v", res.Error, bug)
 			err := errors.Wrap(res.Error, "error creating bug")
 			bl.errors = append(bl.errors, err)
 			continue
@@ -139,20 +142,20 @@ func (bl *BugLoader) Load() {
 		// With gorm we need to explicitly replace the associations to tests and jobs to get them to take effect:
 		err := bl.dbc.DB.Model(bug).Association("Tests").Replace(bug.Tests)
 		if err != nil {
-			log.Errorf("error updating bug test associations: %s %v", err, bug)
+			logger.Errorf("error updating bug test associations: %s %v", err, bug)
 			err := errors.Wrap(res.Error, "error updating bug test assocations")
 			bl.errors = append(bl.errors, err)
 			continue
 		}
 		err = bl.dbc.DB.Model(bug).Association("Jobs").Replace(bug.Jobs)
 		if err != nil {
-			log.Errorf("error updating bug job associations: %s %v", err, bug)
+			logger.Errorf("error updating bug job associations: %s %v", err, bug)
 			err := errors.Wrap(res.Error, "error updating bug job assocations")
 			bl.errors = append(bl.errors, err)
 			continue
 		}
 	}
-	log.Infof("created or updated %d bugs", len(expectedBugIDs))
+	logger.Infof("created or updated %d bugs", len(expectedBugIDs))
 
 	// Remove old unseen bugs
 	res := bl.dbc.DB.Where("id not in ?", expectedBugIDs).Unscoped().Delete(&models.Bug{})
@@ -160,7 +163,7 @@ func (bl *BugLoader) Load() {
 		err := errors.Wrap(res.Error, "error deleting stale bugs")
 		bl.errors = append(bl.errors, err)
 	}
-	log.Infof("deleted %d stale bugs", res.RowsAffected)
+	logger.Infof("deleted %d stale bugs", res.RowsAffected)
 
 	// Update watch list
 	if err := updateWatchlist(bl.dbc); err != nil {
@@ -178,7 +181,7 @@ func (bl *BugLoader) getTestBugMappings(ctx context.Context, testCache map[strin
 	querySQL := fmt.Sprintf(
 		`%s CROSS JOIN %s.%s.%s j WHERE j.name != "upgrade" AND (STRPOS(t.summary, j.name) > 0 OR STRPOS(t.description, j.name) > 0 OR STRPOS(t.comment, j.name) > 0)`,
 		TicketDataQuery, ComponentMappingProject, ComponentMappingDataset, ComponentMappingTable)
-	log.Debugf(querySQL)
+	logger.Debugf(querySQL)
 	query := bl.bqc.BQ.Query(querySQL)
 
 	it, err := query.Read(ctx)
@@ -210,7 +213,7 @@ func (bl *BugLoader) getTestBugMappings(ctx context.Context, testCache map[strin
 
 		if _, ok := testCache[bwt.LinkName]; !ok {
 			// This is probably common since we're using ci-test-mapping test names, and sippy may not know all of them
-			log.Debugf("test name was in jira issue but not known by sippy: %s", bwt.LinkName)
+			logger.Debugf("test name was in jira issue but not known by sippy: %s", bwt.LinkName)
 			continue
 		}
 
@@ -232,7 +235,7 @@ func (bl *BugLoader) getJobBugMappings(ctx context.Context, jobCache map[string]
 	querySQL := fmt.Sprintf(
 		`%s CROSS JOIN (SELECT DISTINCT prowjob_job_name AS name FROM openshift-gce-devel.ci_analysis_us.jobs WHERE prowjob_job_name IS NOT NULL AND prowjob_job_name != "") j WHERE (STRPOS(t.summary, j.name) > 0 OR STRPOS(t.description, j.name) > 0 OR STRPOS(t.comment, j.name) > 0)`,
 		TicketDataQuery)
-	log.Debugf(querySQL)
+	logger.Debugf(querySQL)
 	query := bl.bqc.BQ.Query(querySQL)
 
 	it, err := query.Read(ctx)
@@ -265,7 +268,7 @@ func (bl *BugLoader) getJobBugMappings(ctx context.Context, jobCache map[string]
 
 		if _, ok := jobCache[bwj.LinkName]; !ok {
 			// This is probably common because sippy probably doesn't know about *all* jobs like the BQ table does
-			log.Debugf("job name was in jira issue but not known by sippy: %s", bwj.LinkName)
+			logger.Debugf("job name was in jira issue but not known by sippy: %s", bwj.LinkName)
 			continue
 		}
 
@@ -302,7 +305,7 @@ func loadTestCache(dbc *db.DB, preloads []string) (map[string]*models.Test, erro
 		return map[string]*models.Test{}, res.Error
 	}
 
-	log.Infof("test cache created with %d entries from database", len(testCache))
+	logger.Infof("test cache created with %d entries from database", len(testCache))
 	return testCache, nil
 }
 
@@ -318,7 +321,7 @@ func loadProwJobCache(dbc *db.DB) (map[string]*models.ProwJob, error) {
 			prowJobCache[j.Name] = j
 		}
 	}
-	log.Infof("job cache created with %d entries from database", len(prowJobCache))
+	logger.Infof("job cache created with %d entries from database", len(prowJobCache))
 	return prowJobCache, nil
 }
 
@@ -333,11 +336,11 @@ func updateWatchlist(dbc *db.DB) []error {
 	for testName, test := range testCache {
 		expected := testidentification.IsTestOnWatchlist(test)
 		if test.Watchlist != expected {
-			log.WithFields(log.Fields{"old": test.Watchlist, "new": expected}).Infof("test watchlist status changed for %s", testName)
+			logger.WithFields(log.Fields{"old": test.Watchlist, "new": expected}).Infof("test watchlist status changed for %s", testName)
 			test.Watchlist = expected
 			res := dbc.DB.Save(test)
 			if res.Error != nil {
-				log.WithError(err).Errorf("error updating test watchlist status for: %s", testName)
+				logger.WithError(err).Errorf("error updating test watchlist status for: %s", testName)
 				errs = append(errs, errors.Wrapf(err, "error updating test watchlist status for: %s", testName))
 			}
 		}
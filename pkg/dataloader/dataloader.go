@@ -10,3 +10,8 @@ type DataLoader interface {
 	// Errors returns a slice of errors that occurred during the data loading process.
 	Errors() []error
 }
+
+// Summarizer is implemented by loaders that can summarize what they loaded for the run report.
+type Summarizer interface {
+	Summary() LoaderSummary
+}
@@ -9,14 +9,15 @@ import (
 	"strconv"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
 	v1jira "github.com/openshift/sippy/pkg/apis/jira/v1"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
+var logger = logging.ForSubsystem("incidentloader")
+
 type IncidentLoader struct {
 	dbc    *db.DB
 	errors []error
@@ -36,17 +37,17 @@ func (jl *IncidentLoader) Name() string {
 
 func (jl *IncidentLoader) Load() {
 	start := time.Now()
-	log.Infof("populating unresolved jira incident cache...")
+	logger.Infof("populating unresolved jira incident cache...")
 	var dbIssues []string
 	jl.dbc.DB.Table("jira_incidents").Where("resolution_time IS NULL").Pluck("key", &dbIssues)
 	// unseenUnresolvedIssues contains the set of unresolved issues we have in the DB, but didn't see yet from the jira API. At the end,
 	// we'll query to see what happened to the unseen issues. Most likely, we removed the trt-incident label, so we need
 	// to dig into the changelog and find that state transition and consider the incident closed then.
 	unseenUnresolvedIssues := sets.NewString(dbIssues...)
-	log.Infof("cache populated in %+v with %d records", time.Since(start), len(dbIssues))
+	logger.Infof("cache populated in %+v with %d records", time.Since(start), len(dbIssues))
 
 	start = time.Now()
-	log.Infof("fetching incidents from jira...")
+	logger.Infof("fetching incidents from jira...")
 
 	client := &http.Client{}
 	req, err := http.NewRequest("GET", "https://issues.redhat.com/rest/api/2/search?jql=labels%20%3D%20%22trt-incident%22%20AND%20updated%20%3E%3D%20-60d&expand=changelog", nil)
@@ -63,7 +64,7 @@ func (jl *IncidentLoader) Load() {
 	// as a Red Hat employee.
 	token := os.Getenv("JIRA_TOKEN")
 	if token == "" {
-		log.Warningf("not all jira api queries are available without a token; some requests may fail")
+		logger.Warningf("not all jira api queries are available without a token; some requests may fail")
 	} else {
 		req.Header.Add("Authorization", "Bearer "+token)
 	}
@@ -98,38 +99,38 @@ func (jl *IncidentLoader) Load() {
 
 		model, err := issueToDB(&issues.Issues[i])
 		if err != nil {
-			log.WithError(err).Errorf("couldn't convert jira issue to db model")
+			logger.WithError(err).Errorf("couldn't convert jira issue to db model")
 			continue
 		}
 		if res := jl.dbc.DB.Save(model); res.Error != nil {
-			log.WithError(err).Errorf("couldn't save jira incident to DB")
+			logger.WithError(err).Errorf("couldn't save jira incident to DB")
 			jl.errors = append(jl.errors, err)
 			return
 		}
 	}
 
-	log.Infof("we have %d unseen and unresolved jira incidents", unseenUnresolvedIssues.Len())
+	logger.Infof("we have %d unseen and unresolved jira incidents", unseenUnresolvedIssues.Len())
 	for _, unseen := range unseenUnresolvedIssues.List() {
-		log.Infof("processing unseen, unresolved jira incidents (trt-incident label removed?)...")
+		logger.Infof("processing unseen, unresolved jira incidents (trt-incident label removed?)...")
 		issue, err := queryJiraAPI(unseen)
 		if err != nil {
-			log.WithError(err).Errorf("couldn't query details for %+v", issue)
+			logger.WithError(err).Errorf("couldn't query details for %+v", issue)
 			continue
 		}
 
 		model, err := issueToDB(issue)
 		if err != nil {
-			log.WithError(err).Errorf("couldn't convert jira issue to db model")
+			logger.WithError(err).Errorf("couldn't convert jira issue to db model")
 			continue
 		}
 		if res := jl.dbc.DB.Save(model); res.Error != nil {
-			log.WithError(err).Errorf("couldn't save jira incident to DB")
+			logger.WithError(err).Errorf("couldn't save jira incident to DB")
 			jl.errors = append(jl.errors, err)
 			return
 		}
 	}
 
-	log.Infof("jira incident fetch complete in %+v", time.Since(start))
+	logger.Infof("jira incident fetch complete in %+v", time.Since(start))
 }
 
 func (jl *IncidentLoader) Errors() []error {
@@ -148,13 +149,13 @@ func findResolutionTime(issue *v1jira.Issue) *time.Time {
 			if !issueContainsLabel(issue, "trt-incident") && item.Field == "labels" && item.FromString == "trt-incident" && item.ToString != "trt-incident" {
 				createdTime, err := time.Parse(changelogLayout, history.Created)
 				if err != nil {
-					log.WithError(err).Warningf("parsing error: %s", history.Created)
+					logger.WithError(err).Warningf("parsing error: %s", history.Created)
 					continue
 				}
 				// We pick the oldest time we removed the trt-incident label (maybe we toggled back and forth a few
 				// times).
 				if resolutionTime == nil || resolutionTime.Before(createdTime) {
-					log.Debugf("trt-incident label was removed from %s at %+v", issue.Key, createdTime)
+					logger.Debugf("trt-incident label was removed from %s at %+v", issue.Key, createdTime)
 					resolutionTime = &createdTime
 				}
 			}
@@ -167,12 +168,12 @@ func findResolutionTime(issue *v1jira.Issue) *time.Time {
 				if item.ToString == status {
 					createdTime, err := time.Parse(changelogLayout, history.Created)
 					if err != nil {
-						log.WithError(err).Warningf("parsing error: %s", history.Created)
+						logger.WithError(err).Warningf("parsing error: %s", history.Created)
 						continue
 					}
 					// We pick the oldest state change
 					if resolutionTime == nil || resolutionTime.After(createdTime) {
-						log.Debugf("%s to %s at %+v", issue.Key, status, createdTime)
+						logger.Debugf("%s to %s at %+v", issue.Key, status, createdTime)
 						resolutionTime = &createdTime
 					}
 				}
@@ -186,7 +187,7 @@ func findResolutionTime(issue *v1jira.Issue) *time.Time {
 		if err != nil {
 			fmt.Printf("parsing error: %+v", err)
 		}
-		log.Debugf("resolution time for %s is %+v", issue.Key, jiraResolutionTime)
+		logger.Debugf("resolution time for %s is %+v", issue.Key, jiraResolutionTime)
 		resolutionTime = &jiraResolutionTime
 	}
 
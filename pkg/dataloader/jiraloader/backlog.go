@@ -0,0 +1,140 @@
+package jiraloader
+
+import (
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm/clause"
+
+	v1jira "github.com/openshift/sippy/pkg/apis/jira/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	// openBugsJQL selects the open OCPBUGS bugs counted towards each component's backlog.
+	openBugsJQL = "project = OCPBUGS AND issuetype = Bug AND resolution = Unresolved"
+	// backlogPageSize is the number of issues fetched per search, the most JIRA allows.
+	backlogPageSize = 1000
+)
+
+// backlogLoader counts the open bugs and their ages for each OCPBUGS component, so chronic backlogs can be shown
+// next to component readiness regressions.
+func (jl *JiraLoader) backlogLoader() {
+	start := time.Now()
+	logger.Infof("loading open ocpbugs bugs...")
+
+	var issues []v1jira.Issue
+	for startAt := 0; ; startAt += backlogPageSize {
+		params := url.Values{}
+		params.Set("jql", openBugsJQL)
+		params.Set("fields", "components,created,priority")
+		params.Set("startAt", strconv.Itoa(startAt))
+		params.Set("maxResults", strconv.Itoa(backlogPageSize))
+		body, err := jiraRequest("https://issues.redhat.com/rest/api/2/search?" + params.Encode())
+		if err != nil {
+			jl.errors = append(jl.errors, errors.Wrap(err, "error searching open bugs"))
+			return
+		}
+
+		var page struct {
+			Total  int            `json:"total"`
+			Issues []v1jira.Issue `json:"issues"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			jl.errors = append(jl.errors, errors.Wrap(err, "error parsing open bugs"))
+			return
+		}
+		issues = append(issues, page.Issues...)
+		if len(page.Issues) == 0 || len(issues) >= page.Total {
+			break
+		}
+	}
+
+	backlogs := summarizeBacklogs(issues, time.Now())
+	components := []string{}
+	for i := range backlogs {
+		err := jl.dbc.DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "component"}},
+			DoUpdates: clause.AssignmentColumns([]string{"updated_at", "open_bugs", "critical_bugs", "over30_days", "over90_days", "over365_days", "median_age_days", "oldest_created"}),
+		}).Create(&backlogs[i]).Error
+		if err != nil {
+			jl.errors = append(jl.errors, errors.Wrapf(err, "error saving bug backlog for %q", backlogs[i].Component))
+			continue
+		}
+		components = append(components, backlogs[i].Component)
+	}
+
+	// Components whose bugs were all resolved no longer have a backlog
+	res := jl.dbc.DB.Where("component NOT IN ?", append(components, "")).Unscoped().Delete(&models.ComponentBugBacklog{})
+	if res.Error != nil {
+		jl.errors = append(jl.errors, errors.Wrap(res.Error, "error deleting old bug backlogs"))
+	}
+
+	logger.WithFields(log.Fields{
+		"bugs":       len(issues),
+		"components": len(components),
+		"cleared":    res.RowsAffected,
+	}).Infof("bug backlog load complete in %+v", time.Since(start))
+}
+
+// summarizeBacklogs counts the open bugs of each component as of now. A bug with several components counts
+// towards each of them.
+func summarizeBacklogs(issues []v1jira.Issue, now time.Time) []models.ComponentBugBacklog {
+	byComponent := map[string]*models.ComponentBugBacklog{}
+	ages := map[string][]float64{}
+	for _, issue := range issues {
+		created, err := time.Parse(jiraTimeLayout, issue.Fields.Created)
+		if err != nil {
+			logger.WithError(err).Warningf("couldn't parse created time of %s", issue.Key)
+			continue
+		}
+		age := now.Sub(created).Hours() / 24
+
+		for _, c := range issue.Fields.Components {
+			b, ok := byComponent[c.Name]
+			if !ok {
+				b = &models.ComponentBugBacklog{Component: c.Name, OldestCreated: created}
+				byComponent[c.Name] = b
+			}
+			b.OpenBugs++
+			if issue.Fields.Priority.Name == "Critical" || issue.Fields.Priority.Name == "Blocker" {
+				b.CriticalBugs++
+			}
+			if age > 30 {
+				b.Over30Days++
+			}
+			if age > 90 {
+				b.Over90Days++
+			}
+			if age > 365 {
+				b.Over365Days++
+			}
+			if created.Before(b.OldestCreated) {
+				b.OldestCreated = created
+			}
+			ages[c.Name] = append(ages[c.Name], age)
+		}
+	}
+
+	backlogs := make([]models.ComponentBugBacklog, 0, len(byComponent))
+	for component, b := range byComponent {
+		componentAges := ages[component]
+		sort.Float64s(componentAges)
+		mid := len(componentAges) / 2
+		if len(componentAges)%2 == 0 {
+			b.MedianAgeDays = (componentAges[mid-1] + componentAges[mid]) / 2
+		} else {
+			b.MedianAgeDays = componentAges[mid]
+		}
+		backlogs = append(backlogs, *b)
+	}
+	sort.Slice(backlogs, func(i, j int) bool {
+		return backlogs[i].Component < backlogs[j].Component
+	})
+	return backlogs
+}
@@ -0,0 +1,47 @@
+package jiraloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1jira "github.com/openshift/sippy/pkg/apis/jira/v1"
+)
+
+func bug(key, created, priority string, components ...string) v1jira.Issue {
+	issue := v1jira.Issue{Key: key, Fields: v1jira.Fields{Created: created, Priority: v1jira.Priority{Name: priority}}}
+	for _, c := range components {
+		issue.Fields.Components = append(issue.Fields.Components, v1jira.Component{Name: c})
+	}
+	return issue
+}
+
+func TestSummarizeBacklogs(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	issues := []v1jira.Issue{
+		bug("OCPBUGS-1", "2024-06-05T00:00:00.000+0000", "Critical", "Networking"),
+		bug("OCPBUGS-2", "2024-01-01T00:00:00.000+0000", "Normal", "Networking", "Storage"),
+		bug("OCPBUGS-3", "2022-06-10T00:00:00.000+0000", "Major", "Networking"),
+		bug("OCPBUGS-4", "not a time", "Critical", "Storage"),
+	}
+
+	backlogs := summarizeBacklogs(issues, now)
+	require.Len(t, backlogs, 2)
+
+	networking := backlogs[0]
+	assert.Equal(t, "Networking", networking.Component)
+	assert.Equal(t, 3, networking.OpenBugs)
+	assert.Equal(t, 1, networking.CriticalBugs)
+	assert.Equal(t, 2, networking.Over30Days)
+	assert.Equal(t, 2, networking.Over90Days)
+	assert.Equal(t, 1, networking.Over365Days)
+	assert.Equal(t, 161.0, networking.MedianAgeDays)
+	assert.True(t, networking.OldestCreated.Equal(time.Date(2022, 6, 10, 0, 0, 0, 0, time.UTC)))
+
+	// The unparseable bug is skipped
+	assert.Equal(t, "Storage", backlogs[1].Component)
+	assert.Equal(t, 1, backlogs[1].OpenBugs)
+	assert.Equal(t, 0, backlogs[1].CriticalBugs)
+}
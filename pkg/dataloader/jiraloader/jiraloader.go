@@ -16,9 +16,12 @@ import (
 	v1jira "github.com/openshift/sippy/pkg/apis/jira/v1"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
+var logger = logging.ForSubsystem("jiraloader")
+
 // JiraLoader loads various data sources directly from the Jira API, such as TRT incidents and OCPBUGS components.
 type JiraLoader struct {
 	dbc    *db.DB
@@ -41,6 +44,9 @@ func (jl *JiraLoader) Load() {
 	// Load components into DB
 	jl.componentLoader()
 
+	// Load open bug counts per component
+	jl.backlogLoader()
+
 	// Load incidents
 	jl.incidentLoader()
 }
@@ -61,13 +67,13 @@ func findResolutionTime(issue *v1jira.Issue) *time.Time {
 			if !issueContainsLabel(issue, "trt-incident") && item.Field == "labels" && item.FromString == "trt-incident" && item.ToString != "trt-incident" {
 				createdTime, err := time.Parse(changelogLayout, history.Created)
 				if err != nil {
-					log.WithError(err).Warningf("parsing error: %s", history.Created)
+					logger.WithError(err).Warningf("parsing error: %s", history.Created)
 					continue
 				}
 				// We pick the oldest time we removed the trt-incident label (maybe we toggled back and forth a few
 				// times).
 				if resolutionTime == nil || resolutionTime.Before(createdTime) {
-					log.Debugf("trt-incident label was removed from %s at %+v", issue.Key, createdTime)
+					logger.Debugf("trt-incident label was removed from %s at %+v", issue.Key, createdTime)
 					resolutionTime = &createdTime
 				}
 			}
@@ -80,12 +86,12 @@ func findResolutionTime(issue *v1jira.Issue) *time.Time {
 				if item.ToString == status {
 					createdTime, err := time.Parse(changelogLayout, history.Created)
 					if err != nil {
-						log.WithError(err).Warningf("parsing error: %s", history.Created)
+						logger.WithError(err).Warningf("parsing error: %s", history.Created)
 						continue
 					}
 					// We pick the oldest state change
 					if resolutionTime == nil || resolutionTime.After(createdTime) {
-						log.Debugf("%s to %s at %+v", issue.Key, status, createdTime)
+						logger.Debugf("%s to %s at %+v", issue.Key, status, createdTime)
 						resolutionTime = &createdTime
 					}
 				}
@@ -99,7 +105,7 @@ func findResolutionTime(issue *v1jira.Issue) *time.Time {
 		if err != nil {
 			fmt.Printf("parsing error: %+v", err)
 		}
-		log.Debugf("resolution time for %s is %+v", issue.Key, jiraResolutionTime)
+		logger.Debugf("resolution time for %s is %+v", issue.Key, jiraResolutionTime)
 		resolutionTime = &jiraResolutionTime
 	}
 
@@ -118,7 +124,7 @@ func issueContainsLabel(issue *v1jira.Issue, label string) bool {
 
 func (jl *JiraLoader) componentLoader() {
 	start := time.Now()
-	log.Infof("loading jira ocpbugs component information...")
+	logger.Infof("loading jira ocpbugs component information...")
 	body, err := jiraRequest("https://issues.redhat.com/rest/api/2/project/12332330/components")
 	if err != nil {
 		jl.errors = append(jl.errors, err)
@@ -137,7 +143,7 @@ func (jl *JiraLoader) componentLoader() {
 		jiraID, err := strconv.ParseUint(c.ID, 10, 64)
 		if err != nil {
 			msg := "error parsing jira ID"
-			log.WithError(err).Warn(msg)
+			logger.WithError(err).Warn(msg)
 			jl.errors = append(jl.errors, errors.WithMessage(err, msg))
 			continue
 		}
@@ -154,20 +160,20 @@ func (jl *JiraLoader) componentLoader() {
 
 		if err := jl.dbc.DB.Clauses(clause.OnConflict{UpdateAll: true}).Save(&mc).Error; err != nil {
 			jl.errors = append(jl.errors, err)
-			log.WithError(err).Warningf("failed to save component %q", c.Name)
+			logger.WithError(err).Warningf("failed to save component %q", c.Name)
 			continue
 		}
 		ids = append(ids, mc.ID)
 	}
 
-	log.Infof("deleting old records...")
+	logger.Infof("deleting old records...")
 	oldRecords := jl.dbc.DB.Where("id NOT IN ?", ids).Unscoped().Delete(&models.JiraComponent{})
 	if oldRecords.Error != nil {
-		log.WithError(oldRecords.Error).Warningf("couldn't delete old records")
+		logger.WithError(oldRecords.Error).Warningf("couldn't delete old records")
 		jl.errors = append(jl.errors, oldRecords.Error)
 	}
 
-	log.WithFields(log.Fields{
+	logger.WithFields(log.Fields{
 		"component_count": len(components),
 		"obsolete":        oldRecords.RowsAffected,
 	}).Infof("component load complete in %+v", time.Since(start))
@@ -175,17 +181,17 @@ func (jl *JiraLoader) componentLoader() {
 
 func (jl *JiraLoader) incidentLoader() {
 	start := time.Now()
-	log.Infof("populating unresolved jira incident cache...")
+	logger.Infof("populating unresolved jira incident cache...")
 	var dbIssues []string
 	jl.dbc.DB.Table("jira_incidents").Where("resolution_time IS NULL").Pluck("key", &dbIssues)
 	// unseenUnresolvedIssues contains the set of unresolved issues we have in the DB, but didn't see yet from the jira API. At the end,
 	// we'll query to see what happened to the unseen issues. Most likely, we removed the trt-incident label, so we need
 	// to dig into the changelog and find that state transition and consider the incident closed then.
 	unseenUnresolvedIssues := sets.NewString(dbIssues...)
-	log.Infof("cache populated in %+v with %d records", time.Since(start), len(dbIssues))
+	logger.Infof("cache populated in %+v with %d records", time.Since(start), len(dbIssues))
 
 	start = time.Now()
-	log.Infof("fetching incidents from jira...")
+	logger.Infof("fetching incidents from jira...")
 
 	body, err := jiraRequest("https://issues.redhat.com/rest/api/2/search?jql=labels%20%3D%20%22trt-incident%22%20AND%20updated%20%3E%3D%20-60d&expand=changelog")
 	if err != nil {
@@ -207,38 +213,38 @@ func (jl *JiraLoader) incidentLoader() {
 
 		model, err := issueToDB(&issues.Issues[i])
 		if err != nil {
-			log.WithError(err).Errorf("couldn't convert jira issue to db model")
+			logger.WithError(err).Errorf("couldn't convert jira issue to db model")
 			continue
 		}
 		if res := jl.dbc.DB.Save(model); res.Error != nil {
-			log.WithError(err).Errorf("couldn't save jira incident to DB")
+			logger.WithError(err).Errorf("couldn't save jira incident to DB")
 			jl.errors = append(jl.errors, err)
 			return
 		}
 	}
 
-	log.Infof("we have %d unseen and unresolved jira incidents", unseenUnresolvedIssues.Len())
+	logger.Infof("we have %d unseen and unresolved jira incidents", unseenUnresolvedIssues.Len())
 	for _, unseen := range unseenUnresolvedIssues.List() {
-		log.Infof("processing unseen, unresolved jira incidents (trt-incident label removed?)...")
+		logger.Infof("processing unseen, unresolved jira incidents (trt-incident label removed?)...")
 		issue, err := queryJiraAPI(unseen)
 		if err != nil {
-			log.WithError(err).Errorf("couldn't query details for %+v", issue)
+			logger.WithError(err).Errorf("couldn't query details for %+v", issue)
 			continue
 		}
 
 		model, err := issueToDB(issue)
 		if err != nil {
-			log.WithError(err).Errorf("couldn't convert jira issue to db model")
+			logger.WithError(err).Errorf("couldn't convert jira issue to db model")
 			continue
 		}
 		if res := jl.dbc.DB.Save(model); res.Error != nil {
-			log.WithError(err).Errorf("couldn't save jira incident to DB")
+			logger.WithError(err).Errorf("couldn't save jira incident to DB")
 			jl.errors = append(jl.errors, err)
 			return
 		}
 	}
 
-	log.Infof("jira incident fetch complete in %+v", time.Since(start))
+	logger.Infof("jira incident fetch complete in %+v", time.Since(start))
 }
 
 // queryJiraAPI returns a singular jira issue
@@ -313,7 +319,7 @@ func jiraRequest(apiURL string) ([]byte, error) {
 	// as a Red Hat employee.
 	token := os.Getenv("JIRA_TOKEN")
 	if token == "" {
-		log.Warningf("not all jira api queries are available without a token; some requests may fail")
+		logger.Warningf("not all jira api queries are available without a token; some requests may fail")
 	} else {
 		req.Header.Add("Authorization", "Bearer "+token)
 	}
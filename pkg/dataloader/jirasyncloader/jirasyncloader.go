@@ -0,0 +1,47 @@
+package jirasyncloader
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/jirasync"
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+var logger = logging.ForSubsystem("jirasyncloader")
+
+// JiraSyncLoader opens JIRA issues for new automatically opened incidents and component readiness regressions, and
+// reflects the status of linked issues back into their triage state.
+type JiraSyncLoader struct {
+	ctx    context.Context
+	syncer *jirasync.Syncer
+	errors []error
+}
+
+func New(ctx context.Context, dbc *db.DB, client jirasync.Client, config jirasync.Config) *JiraSyncLoader {
+	return &JiraSyncLoader{
+		ctx:    ctx,
+		syncer: jirasync.New(dbc, client, config),
+	}
+}
+
+func (jl *JiraSyncLoader) Name() string {
+	return "jira-sync"
+}
+
+func (jl *JiraSyncLoader) Errors() []error {
+	return jl.errors
+}
+
+func (jl *JiraSyncLoader) Load() {
+	result, errs := jl.syncer.Sync(jl.ctx)
+	jl.errors = append(jl.errors, errs...)
+	logger.WithFields(log.Fields{
+		"created":  result.Created,
+		"updated":  result.Updated,
+		"comments": result.Comments,
+		"errors":   len(errs),
+	}).Info("synced incidents and regressions with jira")
+}
@@ -9,11 +9,13 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/push"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift/sippy/pkg/dataloader"
+	"github.com/openshift/sippy/pkg/logging"
 )
 
+var logger = logging.ForSubsystem("loaderwithmetrics")
+
 var loadMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Name:    "sippy_data_load_millis",
 	Help:    "Milliseconds to load data into the DB",
@@ -29,6 +31,7 @@ var errorMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
 type LoaderWithMetrics struct {
 	loaders    []dataloader.DataLoader
 	promPusher *push.Pusher
+	report     dataloader.RunReport
 }
 
 func New(wrappedLoaders []dataloader.DataLoader) *LoaderWithMetrics {
@@ -47,31 +50,39 @@ func New(wrappedLoaders []dataloader.DataLoader) *LoaderWithMetrics {
 
 func (l *LoaderWithMetrics) Load() {
 	overallStart := time.Now()
-	log.Infof("starting %d loaders...", len(l.loaders))
+	logger.Infof("starting %d loaders...", len(l.loaders))
+	reports := make([]dataloader.LoaderReport, 0, len(l.loaders))
 	for _, loader := range l.loaders {
-		log.Infof("starting loader %q with metrics wrapper", loader.Name())
+		logger.Infof("starting loader %q with metrics wrapper", loader.Name())
 		start := time.Now()
 		loader.Load()
 		totalTime := time.Since(start)
-		log.Infof("loader %q complete after %+v", loader.Name(), totalTime)
+		logger.Infof("loader %q complete after %+v", loader.Name(), totalTime)
+		reports = append(reports, dataloader.NewLoaderReport(loader, start, totalTime))
 
 		loadMetric.WithLabelValues(loader.Name()).Observe(float64(totalTime.Milliseconds()))
 		errorMetric.WithLabelValues(loader.Name()).Observe(float64(len(loader.Errors())))
 	}
 	overallDuration := time.Since(overallStart)
-	log.Infof("%d loaders finished in %+v...", len(l.loaders), overallDuration)
+	l.report = dataloader.NewRunReport(overallStart, overallStart.Add(overallDuration), reports)
+	logger.Infof("%d loaders finished in %+v...", len(l.loaders), overallDuration)
 	loadMetric.WithLabelValues("total").Observe(float64(overallDuration.Milliseconds()))
 
 	if l.promPusher != nil {
-		log.Info("pushing metrics to prometheus gateway")
+		logger.Info("pushing metrics to prometheus gateway")
 		if err := l.promPusher.Add(); err != nil {
-			log.WithError(err).Error("could not push to prometheus pushgateway")
+			logger.WithError(err).Error("could not push to prometheus pushgateway")
 		} else {
-			log.Info("successfully pushed metrics to prometheus gateway")
+			logger.Info("successfully pushed metrics to prometheus gateway")
 		}
 	}
 }
 
+// Report returns the report of the last run of the loaders.
+func (l *LoaderWithMetrics) Report() dataloader.RunReport {
+	return l.report
+}
+
 func (l *LoaderWithMetrics) Errors() []error {
 	var errs []error
 	for _, loader := range l.loaders {
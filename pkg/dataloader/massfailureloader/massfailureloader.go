@@ -0,0 +1,166 @@
+package massfailureloader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/logging"
+	"github.com/openshift/sippy/pkg/notification"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+var logger = logging.ForSubsystem("massfailureloader")
+
+const (
+	// lookback is how far back to look for failure spikes, this should comfortably cover the time between loads.
+	lookback = 6 * time.Hour
+	// minJobs is the number of distinct jobs that must fail with the same signature in an hour.
+	minJobs = 10
+	// minPlatforms is the number of distinct platforms the failed jobs must span, so a spike in a single family of
+	// jobs is left to be investigated as a regular regression.
+	minPlatforms = 3
+	// minFraction is the fraction of jobs running a signature in an hour that must have failed it.
+	minFraction = 0.5
+)
+
+// WebhookPayload is the body posted to the webhook when a provisional incident is opened.
+type WebhookPayload struct {
+	Message  string          `json:"message"`
+	Incident models.Incident `json:"incident"`
+}
+
+// MassFailureLoader looks for large simultaneous failure spikes across many unrelated jobs, and opens a
+// provisional incident for each so they can be confirmed by a human, rather than investigated job by job.
+type MassFailureLoader struct {
+	ctx        context.Context
+	dbc        *db.DB
+	webhookURL string
+	errors     []error
+}
+
+func New(ctx context.Context, dbc *db.DB, webhookURL string) *MassFailureLoader {
+	return &MassFailureLoader{
+		ctx:        ctx,
+		dbc:        dbc,
+		webhookURL: webhookURL,
+	}
+}
+
+func (ml *MassFailureLoader) Name() string {
+	return "mass-failures"
+}
+
+func (ml *MassFailureLoader) Errors() []error {
+	return ml.errors
+}
+
+func (ml *MassFailureLoader) Load() {
+	spikes, err := query.FailureSpikes(ml.dbc, time.Now().Add(-lookback), minJobs, minPlatforms, minFraction)
+	if err != nil {
+		ml.errors = append(ml.errors, errors.Wrap(err, "error querying failure spikes"))
+		return
+	}
+
+	for _, spike := range spikes {
+		incident, created, err := ml.recordSpike(spike)
+		if err != nil {
+			ml.errors = append(ml.errors, errors.Wrapf(err, "error recording failure spike for %q", spike.Signature))
+			continue
+		}
+		if !created || ml.webhookURL == "" {
+			continue
+		}
+
+		message := fmt.Sprintf("Provisional incident %d opened: %s. Please confirm or delete it.", incident.ID, incident.Title)
+		err = notification.Send(ml.ctx, notification.Message{
+			Subject: fmt.Sprintf("Provisional incident %d opened", incident.ID),
+			Text:    message,
+			Data:    WebhookPayload{Message: message, Incident: *incident},
+		}, notification.Destination{Transport: notification.WebhookURL(ml.webhookURL)})
+		if err != nil {
+			ml.errors = append(ml.errors, errors.Wrapf(err, "error notifying webhook of incident %d", incident.ID))
+		}
+	}
+}
+
+// recordSpike opens a provisional incident for a spike, or extends an existing incident for the same signature if the
+// spike overlaps or immediately follows it. Returns true if a new incident was created.
+func (ml *MassFailureLoader) recordSpike(spike query.FailureSpike) (*models.Incident, bool, error) {
+	start := spike.Bucket
+	end := spike.Bucket.Add(time.Hour)
+	logger := logger.WithFields(log.Fields{
+		"signature": spike.Signature,
+		"bucket":    spike.Bucket,
+		"jobs":      spike.FailedJobs,
+	})
+
+	var existing []models.Incident
+	res := ml.dbc.DB.Where("signature = ?", spike.Signature).
+		Where("start_time <= ?", end).
+		Where("end_time IS NULL OR end_time >= ?", start).
+		Find(&existing)
+	if res.Error != nil {
+		return nil, false, res.Error
+	}
+	if len(existing) > 0 {
+		incident := &existing[0]
+		if incident.Provisional && incident.EndTime != nil && incident.EndTime.Before(end) {
+			logger.WithField("incident", incident.ID).Info("extending provisional incident")
+			incident.EndTime = &end
+			return incident, false, ml.dbc.DB.Save(incident).Error
+		}
+		return incident, false, nil
+	}
+
+	var jobs []models.ProwJob
+	if res := ml.dbc.DB.Where("name IN ?", []string(spike.JobNames)).Find(&jobs); res.Error != nil {
+		return nil, false, res.Error
+	}
+	release, variants := incidentScope(jobs)
+
+	incident := &models.Incident{
+		Title:       fmt.Sprintf("%d jobs failed %s", spike.FailedJobs, spike.Signature),
+		Description: fmt.Sprintf("Automatically opened after %d of %d jobs on %d platforms failed with this signature.", spike.FailedJobs, spike.Jobs, spike.Platforms),
+		Kind:        models.IncidentKindOther,
+		StartTime:   start,
+		EndTime:     &end,
+		Release:     release,
+		Variants:    variants,
+		Provisional: true,
+		Signature:   spike.Signature,
+	}
+	if spike.Signature == query.InfrastructureFailureSignature {
+		incident.Kind = models.IncidentKindInfrastructure
+	}
+	if err := ml.dbc.DB.Create(incident).Error; err != nil {
+		return nil, false, err
+	}
+	logger.WithField("incident", incident.ID).Info("opened provisional incident for failure spike")
+	return incident, true, nil
+}
+
+// incidentScope determines the narrowest scope covering all the affected jobs: their release if they share one,
+// and the variants they all have in common.
+func incidentScope(jobs []models.ProwJob) (string, []string) {
+	if len(jobs) == 0 {
+		return "", []string{}
+	}
+
+	release := jobs[0].Release
+	common := sets.NewString(jobs[0].Variants...)
+	for _, j := range jobs[1:] {
+		if j.Release != release {
+			release = ""
+		}
+		common = common.Intersection(sets.NewString(j.Variants...))
+	}
+
+	return release, common.List()
+}
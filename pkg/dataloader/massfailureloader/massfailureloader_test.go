@@ -0,0 +1,50 @@
+package massfailureloader
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestIncidentScope(t *testing.T) {
+	tests := []struct {
+		name             string
+		jobs             []models.ProwJob
+		expectedRelease  string
+		expectedVariants []string
+	}{
+		{
+			name:             "no jobs",
+			expectedVariants: []string{},
+		},
+		{
+			name: "shared release and platform",
+			jobs: []models.ProwJob{
+				{Release: "4.16", Variants: pq.StringArray{"Platform:aws", "Network:ovn", "Upgrade:none"}},
+				{Release: "4.16", Variants: pq.StringArray{"Platform:aws", "Network:sdn", "Upgrade:none"}},
+				{Release: "4.16", Variants: pq.StringArray{"Platform:aws", "Network:ovn", "Upgrade:micro"}},
+			},
+			expectedRelease:  "4.16",
+			expectedVariants: []string{"Platform:aws"},
+		},
+		{
+			name: "unrelated jobs",
+			jobs: []models.ProwJob{
+				{Release: "4.16", Variants: pq.StringArray{"Platform:aws", "Network:ovn"}},
+				{Release: "4.15", Variants: pq.StringArray{"Platform:gcp", "Network:ovn"}},
+				{Release: "4.16", Variants: pq.StringArray{"Platform:metal"}},
+			},
+			expectedVariants: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release, variants := incidentScope(tt.jobs)
+			assert.Equal(t, tt.expectedRelease, release)
+			assert.Equal(t, tt.expectedVariants, variants)
+		})
+	}
+}
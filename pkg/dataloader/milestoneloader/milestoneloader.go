@@ -0,0 +1,51 @@
+package milestoneloader
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+var logger = logging.ForSubsystem("milestoneloader")
+
+// MilestoneLoader snapshots the component and variant pass rates of every release milestone that has passed but
+// not been snapshotted yet.
+type MilestoneLoader struct {
+	dbc    *db.DB
+	errors []error
+}
+
+func New(dbc *db.DB) *MilestoneLoader {
+	return &MilestoneLoader{dbc: dbc}
+}
+
+func (ml *MilestoneLoader) Name() string {
+	return "milestones"
+}
+
+func (ml *MilestoneLoader) Errors() []error {
+	return ml.errors
+}
+
+func (ml *MilestoneLoader) Load() {
+	milestones, err := api.PendingMilestones(ml.dbc, time.Now())
+	if err != nil {
+		ml.errors = append(ml.errors, errors.Wrap(err, "error listing pending release milestones"))
+		return
+	}
+
+	for i := range milestones {
+		m := &milestones[i]
+		logger := logger.WithFields(log.Fields{"release": m.Release, "milestone": m.Name})
+		if err := api.SnapshotMilestone(ml.dbc, m); err != nil {
+			ml.errors = append(ml.errors, errors.Wrapf(err, "error snapshotting %s %s", m.Release, m.Name))
+			continue
+		}
+		logger.Info("snapshotted release milestone")
+	}
+}
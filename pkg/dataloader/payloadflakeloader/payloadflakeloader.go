@@ -0,0 +1,41 @@
+package payloadflakeloader
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// lookback is how far back to classify failures. Payloads are tested over several days as jobs are retried, so a
+// failure may only become a payload flake well after it was imported.
+const lookback = 14 * 24 * time.Hour
+
+// PayloadFlakeLoader classifies test failures as payload flakes, where the same test passed in another job run
+// against the same payload. It should run after the prow and releases loaders.
+type PayloadFlakeLoader struct {
+	dbc    *db.DB
+	errors []error
+}
+
+func New(dbc *db.DB) *PayloadFlakeLoader {
+	return &PayloadFlakeLoader{
+		dbc: dbc,
+	}
+}
+
+func (pl *PayloadFlakeLoader) Name() string {
+	return "payload-flakes"
+}
+
+func (pl *PayloadFlakeLoader) Errors() []error {
+	return pl.errors
+}
+
+func (pl *PayloadFlakeLoader) Load() {
+	if _, err := query.ClassifyPayloadFlakes(pl.dbc, time.Now().Add(-lookback)); err != nil {
+		pl.errors = append(pl.errors, errors.Wrap(err, "error classifying payload flakes"))
+	}
+}
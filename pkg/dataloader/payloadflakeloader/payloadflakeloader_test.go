@@ -0,0 +1,116 @@
+package payloadflakeloader_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/dataloader/payloadflakeloader"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/test/golden"
+)
+
+func TestMain(m *testing.M) {
+	golden.Main(m)
+}
+
+func TestClassifyPayloadFlakes(t *testing.T) {
+	// the database is shared with other tests, so everything is created in a transaction that is rolled back
+	tx := golden.NewDB(t, "../../../test/golden/testdata/fixtures.json").DB.Begin()
+	defer tx.Rollback()
+	dbc := &db.DB{DB: tx}
+
+	now := time.Now()
+	job := models.ProwJob{Kind: models.ProwPeriodic, Name: "payload-flake-test-job", Release: "4.16"}
+	require.NoError(t, tx.Create(&job).Error)
+	runs := 0
+	newRun := func(payload models.ReleaseTag) models.ProwJobRun {
+		runs++
+		run := models.ProwJobRun{ProwJobID: job.ID, URL: fmt.Sprintf("https://example.com/%s/%d", job.Name, runs), Timestamp: now}
+		require.NoError(t, tx.Create(&run).Error)
+		require.NoError(t, tx.Create(&models.ReleaseJobRun{ReleaseTagID: fmt.Sprint(payload.ID), Name: run.ID}).Error)
+		return run
+	}
+	newPayload := func(tag string) models.ReleaseTag {
+		payload := models.ReleaseTag{ReleaseTag: tag, Release: "4.16"}
+		require.NoError(t, tx.Create(&payload).Error)
+		return payload
+	}
+
+	payload, otherPayload := newPayload("4.16.0-0.nightly-a"), newPayload("4.16.0-0.nightly-b")
+	failedRun, samePayloadRun, otherPayloadRun := newRun(payload), newRun(payload), newRun(otherPayload)
+
+	tests := []struct {
+		name string
+		// otherStatus is the test's result in another run, in otherRun or the run against the same payload by default
+		otherStatus *v1.TestStatus
+		otherRun    *models.ProwJobRun
+		otherDelete bool
+		// imported is how long ago the failure was imported
+		imported time.Duration
+		expected bool
+	}{
+		{name: "passed against the same payload", otherStatus: status(v1.TestStatusSuccess), expected: true},
+		{name: "flaked against the same payload", otherStatus: status(v1.TestStatusFlake), expected: true},
+		{name: "failed against the same payload", otherStatus: status(v1.TestStatusFailure)},
+		{name: "not run against the same payload"},
+		{name: "passed against another payload", otherStatus: status(v1.TestStatusSuccess), otherRun: &otherPayloadRun},
+		{name: "passed in a deleted result", otherStatus: status(v1.TestStatusSuccess), otherDelete: true},
+		{name: "imported before the lookback", otherStatus: status(v1.TestStatusSuccess), imported: 15 * 24 * time.Hour},
+	}
+
+	failures := map[string]uint{}
+	for _, tc := range tests {
+		test := models.Test{Name: "payload flake " + tc.name}
+		require.NoError(t, tx.Create(&test).Error)
+		failure := models.ProwJobRunTest{
+			ProwJobRunID: failedRun.ID,
+			TestID:       test.ID,
+			Status:       int(v1.TestStatusFailure),
+			CreatedAt:    now.Add(-tc.imported),
+		}
+		require.NoError(t, tx.Create(&failure).Error)
+		failures[tc.name] = failure.ID
+
+		if tc.otherStatus == nil {
+			continue
+		}
+		other := samePayloadRun
+		if tc.otherRun != nil {
+			other = *tc.otherRun
+		}
+		result := models.ProwJobRunTest{ProwJobRunID: other.ID, TestID: test.ID, Status: int(*tc.otherStatus)}
+		require.NoError(t, tx.Create(&result).Error)
+		if tc.otherDelete {
+			require.NoError(t, tx.Delete(&result).Error)
+		}
+	}
+
+	loader := payloadflakeloader.New(dbc)
+	loader.Load()
+	require.Empty(t, loader.Errors())
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var result models.ProwJobRunTest
+			require.NoError(t, tx.First(&result, failures[tc.name]).Error)
+			assert.Equal(t, tc.expected, result.PayloadFlake)
+			assert.Equal(t, int(v1.TestStatusFailure), result.Status, "the failure itself is left as is")
+		})
+	}
+
+	// failures are only classified once
+	classified, err := query.ClassifyPayloadFlakes(dbc, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, classified)
+}
+
+func status(s v1.TestStatus) *v1.TestStatus {
+	return &s
+}
@@ -0,0 +1,148 @@
+package dataloader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+var logger = logging.ForSubsystem("dataloader")
+
+// progressInterval is how often import progress is logged and saved to the database.
+const progressInterval = 30 * time.Second
+
+// Progress tracks a running import's progress through its phases, periodically logging it and saving it to the
+// database where the API reports it. Counters are safe to update from concurrent workers.
+type Progress struct {
+	dbc    *db.DB
+	record models.ImportProgress
+	lock   sync.Mutex
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	jobRunsDiscovered atomic.Int64
+	jobRunsFetched    atomic.Int64
+	jobRunsToImport   atomic.Int64
+	junitParsed       atomic.Int64
+	jobRunsWritten    atomic.Int64
+	rowsWritten       atomic.Int64
+}
+
+// NewProgress records the start of an import by the named loader. A nil database only logs progress.
+func NewProgress(dbc *db.DB, loader string) *Progress {
+	now := time.Now()
+	p := &Progress{
+		dbc:    dbc,
+		record: models.ImportProgress{Loader: loader, LastProgressAt: now},
+		done:   make(chan struct{}),
+	}
+	if dbc != nil {
+		if err := dbc.DB.Create(&p.record).Error; err != nil {
+			logger.WithError(err).WithField("loader", loader).Warning("error recording import progress")
+		}
+	}
+	return p
+}
+
+// Start saves progress every progressInterval until Finish is called or the context is cancelled.
+func (p *Progress) Start(ctx context.Context) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.save()
+			case <-p.done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (p *Progress) AddJobRunsDiscovered(n int) { p.jobRunsDiscovered.Add(int64(n)) }
+func (p *Progress) AddJobRunFetched()          { p.jobRunsFetched.Add(1) }
+func (p *Progress) AddJobRunToImport()         { p.jobRunsToImport.Add(1) }
+func (p *Progress) AddJunitParsed()            { p.junitParsed.Add(1) }
+
+// AddJobRunWritten records a job run written to the database along with the number of rows written for it.
+func (p *Progress) AddJobRunWritten(rows int) {
+	p.jobRunsWritten.Add(1)
+	p.rowsWritten.Add(int64(rows))
+}
+
+// Finish stops periodic saving and records the import as finished.
+func (p *Progress) Finish(errs int) {
+	close(p.done)
+	p.wg.Wait()
+
+	p.lock.Lock()
+	now := time.Now()
+	p.record.FinishedAt = &now
+	p.record.Errors = errs
+	p.lock.Unlock()
+	p.save()
+}
+
+// Snapshot returns the current state of the import.
+func (p *Progress) Snapshot() models.ImportProgress {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.update(time.Now())
+	return p.record
+}
+
+// update copies the counters to the record, and moves LastProgressAt forward if any changed. Callers must hold
+// the lock.
+func (p *Progress) update(now time.Time) {
+	previous := p.record
+	p.record.JobRunsDiscovered = int(p.jobRunsDiscovered.Load())
+	p.record.JobRunsFetched = int(p.jobRunsFetched.Load())
+	p.record.JobRunsToImport = int(p.jobRunsToImport.Load())
+	p.record.JunitParsed = int(p.junitParsed.Load())
+	p.record.JobRunsWritten = int(p.jobRunsWritten.Load())
+	p.record.RowsWritten = int(p.rowsWritten.Load())
+	if p.record.JobRunsDiscovered != previous.JobRunsDiscovered ||
+		p.record.JobRunsFetched != previous.JobRunsFetched ||
+		p.record.JobRunsToImport != previous.JobRunsToImport ||
+		p.record.JunitParsed != previous.JunitParsed ||
+		p.record.JobRunsWritten != previous.JobRunsWritten ||
+		p.record.RowsWritten != previous.RowsWritten {
+		p.record.LastProgressAt = now
+	}
+}
+
+func (p *Progress) save() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.update(time.Now())
+
+	logger.WithFields(log.Fields{
+		"loader":            p.record.Loader,
+		"jobRunsDiscovered": p.record.JobRunsDiscovered,
+		"jobRunsFetched":    p.record.JobRunsFetched,
+		"jobRunsToImport":   p.record.JobRunsToImport,
+		"junitParsed":       p.record.JunitParsed,
+		"jobRunsWritten":    p.record.JobRunsWritten,
+		"rowsWritten":       p.record.RowsWritten,
+		"lastProgressAt":    p.record.LastProgressAt,
+		"finished":          p.record.FinishedAt != nil,
+	}).Info("import progress")
+
+	if p.dbc != nil && p.record.ID != 0 {
+		if err := p.dbc.DB.Save(&p.record).Error; err != nil {
+			logger.WithError(err).WithField("loader", p.record.Loader).Warning("error saving import progress")
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package dataloader
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	p := NewProgress(nil, "prow")
+	p.Start(context.Background())
+	started := p.Snapshot().LastProgressAt
+
+	p.AddJobRunsDiscovered(3)
+	p.AddJobRunFetched()
+	p.AddJobRunToImport()
+	p.AddJunitParsed()
+	p.AddJobRunWritten(100)
+
+	snapshot := p.Snapshot()
+	assert.Equal(t, 3, snapshot.JobRunsDiscovered)
+	assert.Equal(t, 1, snapshot.JobRunsFetched)
+	assert.Equal(t, 1, snapshot.JobRunsToImport)
+	assert.Equal(t, 1, snapshot.JunitParsed)
+	assert.Equal(t, 1, snapshot.JobRunsWritten)
+	assert.Equal(t, 100, snapshot.RowsWritten)
+	assert.False(t, snapshot.LastProgressAt.Before(started))
+
+	// LastProgressAt only moves when a counter changes
+	assert.Equal(t, snapshot.LastProgressAt, p.Snapshot().LastProgressAt)
+
+	p.Finish(2)
+	finished := p.Snapshot()
+	require.NotNil(t, finished.FinishedAt)
+	assert.Equal(t, 2, finished.Errors)
+}
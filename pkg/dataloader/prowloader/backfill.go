@@ -0,0 +1,60 @@
+package prowloader
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// BackfillOptions configures the loader to re-import a past date range for a subset of jobs, i.e. to repair data
+// after fixing an ingestion bug.
+type BackfillOptions struct {
+	// Job runs that completed after Start, and started before End, are re-imported.
+	Start time.Time
+	End   time.Time
+	// JobRegex limits the backfill to matching job names.
+	JobRegex *regexp.Regexp
+	// Concurrency is the number of job runs to import at once. Backfills are typically large, so this defaults
+	// to lower than a regular load to avoid starving the database.
+	Concurrency int
+}
+
+// DefaultBackfillConcurrency is the number of job runs a backfill or re-parse imports at once unless configured
+// otherwise.
+const DefaultBackfillConcurrency = 2
+
+// EnableBackfill switches the loader to backfill mode. Rather than importing jobs completed since the last import,
+// it imports job runs in the given range, replacing any existing data for those runs. Backfilling requires the
+// OpenShift CI BigQuery jobs table, as prow itself only keeps recent jobs. PR statuses are not synced and GitHub
+// comments are not queued, as the runs are historical.
+func (pl *ProwLoader) EnableBackfill(opts BackfillOptions) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultBackfillConcurrency
+	}
+	pl.backfill = &opts
+	pl.maxConcurrency = opts.Concurrency
+}
+
+// EnableFingerprints records the environment each imported job run ran in, such as its cloud region and node
+// instance types, from its cluster data and gathered nodes.
+func (pl *ProwLoader) EnableFingerprints() {
+	pl.loadFingerprints = true
+}
+
+// deleteJobRunData removes the data imported for a job run, so it can be re-imported by a backfill.
+func (pl *ProwLoader) deleteJobRunData(ctx context.Context, id uint) error {
+	for _, model := range []interface{}{
+		&models.ProwJobRunTest{},
+		&models.ProwJobRunOperatorCondition{},
+		&models.ProwJobRunAlert{},
+		&models.ProwJobRunEventPattern{},
+		&models.ProwJobRunFingerprint{},
+	} {
+		if res := pl.dbc.DB.WithContext(ctx).Unscoped().Where("prow_job_run_id = ?", id).Delete(model); res.Error != nil {
+			return res.Error
+		}
+	}
+	return nil
+}
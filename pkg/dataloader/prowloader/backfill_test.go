@@ -0,0 +1,70 @@
+package prowloader_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/dataloader/prowloader"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/test/golden"
+)
+
+func TestMain(m *testing.M) {
+	golden.Main(m)
+}
+
+func TestDeleteJobRunData(t *testing.T) {
+	// the database is shared with other tests, so everything is created in a transaction that is rolled back
+	tx := golden.NewDB(t, "../../../test/golden/testdata/fixtures.json").DB.Begin()
+	defer tx.Rollback()
+	dbc := &db.DB{DB: tx}
+
+	job := models.ProwJob{Kind: models.ProwPeriodic, Name: "backfill-test-job", Release: "4.16"}
+	require.NoError(t, tx.Create(&job).Error)
+	test := models.Test{Name: "backfill test"}
+	require.NoError(t, tx.Create(&test).Error)
+
+	now := time.Now()
+	newRun := func(n int) models.ProwJobRun {
+		run := models.ProwJobRun{ProwJobID: job.ID, URL: fmt.Sprintf("https://example.com/%s/%d", job.Name, n), Timestamp: now}
+		require.NoError(t, tx.Create(&run).Error)
+		for _, row := range []interface{}{
+			&models.ProwJobRunTest{ProwJobRunID: run.ID, TestID: test.ID, Status: 1},
+			&models.ProwJobRunOperatorCondition{ProwJobRunID: run.ID, Operator: "etcd", Condition: "Degraded", From: now, To: now},
+			&models.ProwJobRunAlert{ProwJobRunID: run.ID, Name: "KubePodNotReady", From: now, To: now},
+			&models.ProwJobRunEventPattern{ProwJobRunID: run.ID, Pattern: "crashloop", Count: 1},
+			&models.ProwJobRunFingerprint{ProwJobRunID: run.ID, Attribute: "region", Value: "us-east-1"},
+		} {
+			require.NoError(t, tx.Create(row).Error)
+		}
+		return run
+	}
+	backfilled, other := newRun(1), newRun(2)
+
+	require.NoError(t, prowloader.DeleteJobRunData(context.Background(), dbc, backfilled.ID))
+
+	for _, model := range []interface{}{
+		&models.ProwJobRunTest{},
+		&models.ProwJobRunOperatorCondition{},
+		&models.ProwJobRunAlert{},
+		&models.ProwJobRunEventPattern{},
+		&models.ProwJobRunFingerprint{},
+	} {
+		name := fmt.Sprintf("%T", model)
+		var count int64
+		require.NoError(t, tx.Unscoped().Model(model).Where("prow_job_run_id = ?", backfilled.ID).Count(&count).Error)
+		assert.Zero(t, count, "%s of the backfilled run are removed, not soft deleted", name)
+		require.NoError(t, tx.Model(model).Where("prow_job_run_id = ?", other.ID).Count(&count).Error)
+		assert.Equal(t, int64(1), count, "%s of other runs are kept", name)
+	}
+
+	var runs int64
+	require.NoError(t, tx.Model(&models.ProwJobRun{}).Where("id IN ?", []uint{backfilled.ID, other.ID}).Count(&runs).Error)
+	assert.Equal(t, int64(2), runs, "the runs themselves are kept, to be updated by the re-import")
+}
@@ -7,12 +7,31 @@ import (
 
 	"cloud.google.com/go/bigquery"
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	"google.golang.org/api/iterator"
 
 	"github.com/openshift/sippy/pkg/apis/prow"
 )
 
+// importWindow returns which job runs to import from BigQuery: those completed after from and started before to, with
+// names matching jobRegex if set. A backfill imports its configured range; otherwise runs completed since the last
+// imported run started, lastImported, are imported, or the last two weeks if nothing has been imported.
+func (pl *ProwLoader) importWindow(lastImported, now time.Time) (from, to time.Time, jobRegex string) {
+	if pl.backfill != nil {
+		if pl.backfill.JobRegex != nil {
+			jobRegex = pl.backfill.JobRegex.String()
+		}
+		return pl.backfill.Start, pl.backfill.End, jobRegex
+	}
+	if lastImported.IsZero() {
+		return now.Add(-14 * 24 * time.Hour), now, ""
+	}
+	// adjust the last job run time, we're querying all jobs that have completed since our last recorded
+	// job START time, but we need to subtract our max job runtime in-case a job ended early and was our last
+	// imported start time, while others that started before it hadn't completed yet.
+	// 12 hours should safely cover our max timeout.
+	return lastImported.Add(-12 * time.Hour), now, ""
+}
+
 func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []error) {
 	errs := []error{}
 
@@ -20,17 +39,16 @@ func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []er
 	var lastProwJobRun time.Time
 	row := pl.dbc.DB.Table("prow_job_runs").Select("max(timestamp)").Row()
 	err := row.Scan(&lastProwJobRun)
-	if err != nil || lastProwJobRun.IsZero() {
-		log.WithError(err).Warn("no last prow job run found (new database?), importing last two weeks")
-		lastProwJobRun = time.Now().Add(-14 * 24 * time.Hour)
-	} else {
-		// adjust the last job run time, we're querying all jobs that have completed since our last recorded
-		// job START time, but we need to subtract our max job runtime in-case a job ended early and was our last
-		// imported start time, while others that started before it hadn't completed yet.
-		// 12 hours should safely cover our max timeout.
-		lastProwJobRun = lastProwJobRun.Add(-12 * time.Hour)
+	if err != nil {
+		lastProwJobRun = time.Time{}
 	}
-	log.Infof("Loading prow jobs from bigquery completed since: %s", lastProwJobRun.UTC().Format(time.RFC3339))
+	queryFrom, queryTo, jobRegex := pl.importWindow(lastProwJobRun, time.Now())
+	if pl.backfill != nil {
+		logger.Infof("Backfilling prow jobs started before %s matching %q", queryTo.UTC().Format(time.RFC3339), jobRegex)
+	} else if lastProwJobRun.IsZero() {
+		logger.WithError(err).Warn("no last prow job run found (new database?), importing last two weeks")
+	}
+	logger.Infof("Loading prow jobs from bigquery completed since: %s", queryFrom.UTC().Format(time.RFC3339))
 
 	// NOTE: casting a couple datetime columns to timestamps, it does appear they go in as UTC, and thus come out
 	// as the default UTC correctly.
@@ -51,18 +69,28 @@ func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []er
 			TIMESTAMP(prowjob_completion) AS prowjob_completion_ts ` +
 		"FROM `ci_analysis_us.jobs` " +
 		`WHERE TIMESTAMP(prowjob_completion) > @queryFrom
+	       AND TIMESTAMP(prowjob_start) < @queryTo
+	       AND (@jobRegex = '' OR REGEXP_CONTAINS(prowjob_job_name, @jobRegex))
 	       AND prowjob_url IS NOT NULL
 	       ORDER BY prowjob_start_ts`)
 	query.Parameters = []bigquery.QueryParameter{
 		{
 			Name:  "queryFrom",
-			Value: lastProwJobRun,
+			Value: queryFrom,
+		},
+		{
+			Name:  "queryTo",
+			Value: queryTo,
+		},
+		{
+			Name:  "jobRegex",
+			Value: jobRegex,
 		},
 	}
 	it, err := query.Read(context.TODO())
 	if err != nil {
 		errs = append(errs, err)
-		log.WithError(err).Error("error querying jobs from bigquery")
+		logger.WithError(err).Error("error querying jobs from bigquery")
 		return []prow.ProwJob{}, errs
 	}
 
@@ -76,7 +104,7 @@ func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []er
 			break
 		}
 		if err != nil {
-			log.WithError(err).Error("error parsing prowjob from bigquery")
+			logger.WithError(err).Error("error parsing prowjob from bigquery")
 			errs = append(errs, errors.Wrap(err, "error parsing prowjob from bigquery"))
 			continue
 		}
@@ -85,7 +113,7 @@ func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []er
 		if bqjr.PRNumber.StringVal != "" {
 			prNumber, err := strconv.Atoi(bqjr.PRNumber.StringVal)
 			if err != nil {
-				log.WithError(err).Errorf("Invalid pull request number from big query fetch prow jobs")
+				logger.WithError(err).Errorf("Invalid pull request number from big query fetch prow jobs")
 			} else {
 				refs = &prow.Refs{Org: bqjr.PROrg.StringVal, Repo: bqjr.PRRepo.StringVal}
 				pulls := make([]prow.Pull, 0)
@@ -93,12 +121,12 @@ func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []er
 				refs.Pulls = append(pulls, pull)
 			}
 		} else if bqjr.Type == "presubmit" {
-			log.Warningf("Presubmit job found without matching PR data for: %s", bqjr.JobName)
+			logger.Warningf("Presubmit job found without matching PR data for: %s", bqjr.JobName)
 		}
 		// Convert to a prow.ProwJob:
 		// If we read in an invalid StartTime, skip this job but put out an error.
 		if !bqjr.StartTime.Valid {
-			log.WithField("job", bqjr.JobName).Error("invalid start time for prowjob")
+			logger.WithField("job", bqjr.JobName).Error("invalid start time for prowjob")
 			// Do not return an error as that will cause the job to fail.
 			continue
 		}
@@ -125,7 +153,7 @@ func (pl *ProwLoader) fetchProwJobsFromOpenShiftBigQuery() ([]prow.ProwJob, []er
 		prowJobsList = append(prowJobsList, job)
 	}
 
-	log.Infof("found %d jobs (%d dupes) in bigquery since last import (roughly)", len(prowJobs), count-len(prowJobs))
+	logger.Infof("found %d jobs (%d dupes) in bigquery since last import (roughly)", len(prowJobs), count-len(prowJobs))
 	return prowJobsList, errs
 }
 
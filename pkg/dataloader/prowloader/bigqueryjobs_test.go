@@ -0,0 +1,58 @@
+package prowloader
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportWindow(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	lastImported := now.Add(-time.Hour)
+	backfill := BackfillOptions{Start: now.Add(-30 * 24 * time.Hour), End: now.Add(-20 * 24 * time.Hour)}
+
+	tests := []struct {
+		name         string
+		backfill     *BackfillOptions
+		lastImported time.Time
+		from, to     time.Time
+		jobRegex     string
+	}{
+		{
+			name:         "runs completed since 12 hours before the last imported run started",
+			lastImported: lastImported,
+			from:         lastImported.Add(-12 * time.Hour),
+			to:           now,
+		},
+		{
+			name: "the last two weeks for a new database",
+			from: now.Add(-14 * 24 * time.Hour),
+			to:   now,
+		},
+		{
+			name:         "a backfill's range regardless of the last imported run",
+			backfill:     &backfill,
+			lastImported: lastImported,
+			from:         backfill.Start,
+			to:           backfill.End,
+		},
+		{
+			name:     "a backfill's range for a new database, limited to matching jobs",
+			backfill: &BackfillOptions{Start: backfill.Start, End: backfill.End, JobRegex: regexp.MustCompile(`-e2e-aws-`)},
+			from:     backfill.Start,
+			to:       backfill.End,
+			jobRegex: `-e2e-aws-`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pl := &ProwLoader{backfill: tc.backfill}
+			from, to, jobRegex := pl.importWindow(tc.lastImported, now)
+			assert.Equal(t, tc.from, from)
+			assert.Equal(t, tc.to, to)
+			assert.Equal(t, tc.jobRegex, jobRegex)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package prowloader
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/sippy/pkg/apis/junit"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// cascadeWindow is how soon after a failure another failure must start, with no passing tests in between, to be
+// considered part of the same cascade.
+const cascadeWindow = 5 * time.Minute
+
+// cascadeSignatures are failure messages that indicate a test failed because the cluster was left broken by an
+// earlier failure, rather than due to a problem with the test itself.
+var cascadeSignatures = []string{
+	"connection refused",
+	"no route to host",
+	"i/o timeout",
+	"TLS handshake timeout",
+	"the server is currently unable to handle the request",
+	"the server was unable to return a response",
+	"etcdserver: request timed out",
+	"etcdserver: leader changed",
+	"context deadline exceeded",
+}
+
+func isSerialTest(name string) bool {
+	return strings.Contains(name, "[Serial]") || strings.Contains(name, "[Suite:openshift/conformance/serial]")
+}
+
+func hasCascadeSignature(tc *junit.TestCase) bool {
+	output := tc.FailureOutput.Message + "\n" + tc.FailureOutput.Output
+	for _, s := range cascadeSignatures {
+		if strings.Contains(output, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// cascadeFailures returns the names of serial test failures in a suite that appear to be a consequence of an earlier
+// failure in the same run, rather than a genuine failure. Serial tests run one at a time in the order they appear in
+// the junit, so each test's start time can be estimated from the durations of the tests before it. The first failure
+// is always considered primary; later failures are cascades if they match a known cascade signature, or if they
+// started shortly after the previous failure with no passing test in between.
+func cascadeFailures(testCases []*junit.TestCase) map[string]bool {
+	cascades := make(map[string]bool)
+
+	var offset, lastFailureEnd time.Duration
+	seenFailure, passedSinceFailure := false, false
+	for _, tc := range testCases {
+		start := offset
+		offset += time.Duration(tc.Duration * float64(time.Second))
+		if tc.SkipMessage != nil || !isSerialTest(tc.Name) {
+			continue
+		}
+		if tc.FailureOutput == nil {
+			passedSinceFailure = true
+			continue
+		}
+
+		if seenFailure && (hasCascadeSignature(tc) || (!passedSinceFailure && start-lastFailureEnd <= cascadeWindow)) {
+			cascades[tc.Name] = true
+		}
+		seenFailure, passedSinceFailure = true, false
+		lastFailureEnd = offset
+	}
+	return cascades
+}
+
+// markCascadeFailures flags failed tests in the suite, and its children, that were caused by an earlier failure.
+func markCascadeFailures(suite *junit.TestSuite, testCases map[string]*models.ProwJobRunTest) {
+	for name := range cascadeFailures(suite.TestCases) {
+		if t, ok := testCases[fmt.Sprintf("%s.%s", suite.Name, name)]; ok && t.Status == int(sippyprocessingv1.TestStatusFailure) {
+			t.CascadeFailure = true
+		}
+	}
+
+	for _, c := range suite.Children {
+		markCascadeFailures(c, testCases)
+	}
+}
@@ -0,0 +1,100 @@
+package prowloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/apis/junit"
+)
+
+func serialCase(name string, duration float64, failure string) *junit.TestCase {
+	tc := &junit.TestCase{
+		Name:     name + " [Serial] [Suite:openshift/conformance/serial]",
+		Duration: duration,
+	}
+	if failure != "" {
+		tc.FailureOutput = &junit.FailureOutput{Output: failure}
+	}
+	return tc
+}
+
+func TestCascadeFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		cases    []*junit.TestCase
+		expected []string
+	}{
+		{
+			name: "single failure is primary",
+			cases: []*junit.TestCase{
+				serialCase("a", 60, ""),
+				serialCase("b", 60, "expected 3 replicas, got 2"),
+				serialCase("c", 60, ""),
+			},
+			expected: []string{},
+		},
+		{
+			name: "consecutive failures cascade",
+			cases: []*junit.TestCase{
+				serialCase("a", 60, "node did not become ready"),
+				serialCase("b", 60, "expected 3 replicas, got 2"),
+				serialCase("c", 60, "pod did not start"),
+			},
+			expected: []string{"b", "c"},
+		},
+		{
+			name: "a pass in between breaks the cascade",
+			cases: []*junit.TestCase{
+				serialCase("a", 60, "node did not become ready"),
+				serialCase("b", 60, ""),
+				serialCase("c", 60, "pod did not start"),
+			},
+			expected: []string{},
+		},
+		{
+			name: "failure long after the previous one is primary",
+			cases: []*junit.TestCase{
+				serialCase("a", 60, "node did not become ready"),
+				{Name: "not serial", Duration: 600},
+				serialCase("b", 60, "pod did not start"),
+				serialCase("c", 60, "pod did not start"),
+			},
+			expected: []string{"c"},
+		},
+		{
+			name: "cascade signature after a pass",
+			cases: []*junit.TestCase{
+				serialCase("a", 60, "node did not become ready"),
+				serialCase("b", 60, ""),
+				serialCase("c", 60, "dial tcp 10.0.0.1:6443: connect: connection refused"),
+			},
+			expected: []string{"c"},
+		},
+		{
+			name: "signature on the first failure is still primary",
+			cases: []*junit.TestCase{
+				serialCase("a", 60, "context deadline exceeded"),
+			},
+			expected: []string{},
+		},
+		{
+			name: "parallel tests are ignored",
+			cases: []*junit.TestCase{
+				{Name: "a", Duration: 60, FailureOutput: &junit.FailureOutput{Output: "boom"}},
+				{Name: "b", Duration: 60, FailureOutput: &junit.FailureOutput{Output: "connection refused"}},
+			},
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cascades := cascadeFailures(tt.cases)
+			expected := make(map[string]bool)
+			for _, e := range tt.expected {
+				expected[e+" [Serial] [Suite:openshift/conformance/serial]"] = true
+			}
+			assert.Equal(t, expected, cascades)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package prowloader
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// loadImportFailureCache returns the number of failed import attempts of each job run that has failed to import.
+func loadImportFailureCache(dbc *db.DB) map[uint]int {
+	failures := []models.JobRunImportFailure{}
+	dbc.DB.Select("prow_job_run_id", "attempts").Find(&failures)
+	cache := make(map[uint]int, len(failures))
+	for _, f := range failures {
+		cache[f.ProwJobRunID] = f.Attempts
+	}
+	return cache
+}
+
+// deadLettered returns true if the job run has failed to import too many times, and should be skipped until it's
+// retried through the API.
+func (pl *ProwLoader) deadLettered(id uint) bool {
+	pl.importFailureCacheLock.RLock()
+	defer pl.importFailureCacheLock.RUnlock()
+	return pl.importFailureCache[id] >= models.MaxJobRunImportAttempts
+}
+
+// recordImportFailure records a failed attempt to import a job run, dead-lettering it once it has failed
+// models.MaxJobRunImportAttempts times. Failures due to the load being cancelled aren't the job run's fault, and
+// aren't recorded.
+func (pl *ProwLoader) recordImportFailure(ctx context.Context, pj *prow.ProwJob, importErr error) {
+	if ctx.Err() != nil {
+		return
+	}
+	id, err := strconv.ParseUint(pj.Status.BuildID, 0, 64)
+	if err != nil {
+		return
+	}
+
+	failure := models.JobRunImportFailure{
+		ProwJobRunID:  uint(id),
+		ProwJobName:   pj.Spec.Job,
+		URL:           pj.Status.URL,
+		Attempts:      1,
+		Error:         importErr.Error(),
+		LastAttemptAt: time.Now(),
+	}
+	err = pl.dbc.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "prow_job_run_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"attempts":        gorm.Expr("job_run_import_failures.attempts + 1"),
+			"error":           failure.Error,
+			"last_attempt_at": failure.LastAttemptAt,
+			"updated_at":      failure.LastAttemptAt,
+		}),
+	}).Create(&failure).Error
+	if err != nil {
+		logger.WithError(err).WithField("prowJobRunID", id).Warning("error recording job run import failure")
+		return
+	}
+
+	pl.importFailureCacheLock.Lock()
+	pl.importFailureCache[uint(id)]++
+	attempts := pl.importFailureCache[uint(id)]
+	pl.importFailureCacheLock.Unlock()
+	if attempts >= models.MaxJobRunImportAttempts {
+		logger.WithFields(log.Fields{
+			"job":      pj.Spec.Job,
+			"buildID":  pj.Status.BuildID,
+			"attempts": attempts,
+		}).Warning("job run dead-lettered, it will be skipped until retried")
+	}
+}
+
+// clearImportFailure removes the failure record of a job run that has now been imported.
+func (pl *ProwLoader) clearImportFailure(ctx context.Context, id uint) error {
+	pl.importFailureCacheLock.RLock()
+	_, failed := pl.importFailureCache[id]
+	pl.importFailureCacheLock.RUnlock()
+	if !failed {
+		return nil
+	}
+
+	if err := pl.dbc.DB.WithContext(ctx).Unscoped().Where("prow_job_run_id = ?", id).Delete(&models.JobRunImportFailure{}).Error; err != nil {
+		return err
+	}
+	pl.importFailureCacheLock.Lock()
+	delete(pl.importFailureCache, id)
+	pl.importFailureCacheLock.Unlock()
+	return nil
+}
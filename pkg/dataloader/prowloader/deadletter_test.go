@@ -0,0 +1,31 @@
+package prowloader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+)
+
+func TestDeadLettered(t *testing.T) {
+	pl := &ProwLoader{importFailureCache: map[uint]int{1: 1, 2: 3, 3: 4}}
+	assert.False(t, pl.deadLettered(1), "failed once")
+	assert.True(t, pl.deadLettered(2), "failed the maximum number of times")
+	assert.True(t, pl.deadLettered(3))
+	assert.False(t, pl.deadLettered(4), "never failed")
+}
+
+func TestRecordImportFailureIgnoresCancellation(t *testing.T) {
+	// Without a database, this would panic if the failure were recorded
+	pl := &ProwLoader{importFailureCache: map[uint]int{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pj := &prow.ProwJob{}
+	pj.Status.BuildID = "1796523477898416128"
+	pl.recordImportFailure(ctx, pj, errors.New("context canceled"))
+	assert.Empty(t, pl.importFailureCache)
+}
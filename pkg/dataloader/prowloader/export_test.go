@@ -0,0 +1,13 @@
+package prowloader
+
+import (
+	"context"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// DeleteJobRunData exposes deleteJobRunData to the database tests in prowloader_test, which can't be in this package
+// as the golden database harness imports it.
+func DeleteJobRunData(ctx context.Context, dbc *db.DB, id uint) error {
+	return (&ProwLoader{dbc: dbc}).deleteJobRunData(ctx, id)
+}
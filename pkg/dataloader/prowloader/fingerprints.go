@@ -0,0 +1,103 @@
+package prowloader
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// Fingerprint attributes recorded for job runs.
+const (
+	FingerprintCloudRegion              = "cloud_region"
+	FingerprintCloudZone                = "cloud_zone"
+	FingerprintControlPlaneInstanceType = "control_plane_instance_type"
+	FingerprintWorkerInstanceType       = "worker_instance_type"
+	FingerprintOSImage                  = "os_image"
+	FingerprintKernelVersion            = "kernel_version"
+	FingerprintContainerRuntime         = "container_runtime"
+	FingerprintKubeletVersion           = "kubelet_version"
+)
+
+// nodeList is the part of the gathered nodes.json used for fingerprints.
+type nodeList struct {
+	Items []struct {
+		Metadata struct {
+			Labels map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Status struct {
+			NodeInfo struct {
+				OSImage                 string `json:"osImage"`
+				KernelVersion           string `json:"kernelVersion"`
+				ContainerRuntimeVersion string `json:"containerRuntimeVersion"`
+				KubeletVersion          string `json:"kubeletVersion"`
+			} `json:"nodeInfo"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// fingerprintsFromArtifacts extracts the environment a job run ran in from its cluster data, and the nodes gathered
+// at the end of the run if there are any. Attributes that couldn't be determined are left out.
+func fingerprintsFromArtifacts(jobRunID uint, cd models.ClusterData, nodesJSON []byte) ([]*models.ProwJobRunFingerprint, error) {
+	values := map[string]map[string]bool{}
+	add := func(attribute, value string) {
+		if value == "" {
+			return
+		}
+		if values[attribute] == nil {
+			values[attribute] = map[string]bool{}
+		}
+		values[attribute][value] = true
+	}
+	add(FingerprintCloudRegion, cd.CloudRegion)
+	add(FingerprintCloudZone, cd.CloudZone)
+
+	var parseErr error
+	if len(nodesJSON) > 0 {
+		var nodes nodeList
+		if err := json.Unmarshal(nodesJSON, &nodes); err != nil {
+			// Still record what the cluster data had
+			parseErr = err
+		}
+		for _, n := range nodes.Items {
+			labels := n.Metadata.Labels
+			instanceType := labels["node.kubernetes.io/instance-type"]
+			if instanceType == "" {
+				instanceType = labels["beta.kubernetes.io/instance-type"]
+			}
+			// Compact clusters' nodes have both roles
+			if _, ok := labels["node-role.kubernetes.io/master"]; ok {
+				add(FingerprintControlPlaneInstanceType, instanceType)
+			} else if _, ok := labels["node-role.kubernetes.io/control-plane"]; ok {
+				add(FingerprintControlPlaneInstanceType, instanceType)
+			}
+			if _, ok := labels["node-role.kubernetes.io/worker"]; ok {
+				add(FingerprintWorkerInstanceType, instanceType)
+			}
+			info := n.Status.NodeInfo
+			add(FingerprintOSImage, info.OSImage)
+			add(FingerprintKernelVersion, info.KernelVersion)
+			add(FingerprintContainerRuntime, info.ContainerRuntimeVersion)
+			add(FingerprintKubeletVersion, info.KubeletVersion)
+		}
+	}
+
+	fingerprints := make([]*models.ProwJobRunFingerprint, 0, len(values))
+	for attribute, set := range values {
+		distinct := make([]string, 0, len(set))
+		for v := range set {
+			distinct = append(distinct, v)
+		}
+		sort.Strings(distinct)
+		fingerprints = append(fingerprints, &models.ProwJobRunFingerprint{
+			ProwJobRunID: jobRunID,
+			Attribute:    attribute,
+			Value:        strings.Join(distinct, ","),
+		})
+	}
+	sort.Slice(fingerprints, func(i, j int) bool {
+		return fingerprints[i].Attribute < fingerprints[j].Attribute
+	})
+	return fingerprints, parseErr
+}
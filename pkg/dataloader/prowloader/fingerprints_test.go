@@ -0,0 +1,57 @@
+package prowloader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestFingerprintsFromArtifacts(t *testing.T) {
+	nodes := `{"items": [
+	{"metadata": {"labels": {"node-role.kubernetes.io/master": "", "node.kubernetes.io/instance-type": "m6a.xlarge"}},
+	 "status": {"nodeInfo": {"osImage": "RHCOS 416.94", "kernelVersion": "5.14.0-427", "containerRuntimeVersion": "cri-o://1.29.1", "kubeletVersion": "v1.29.4"}}},
+	{"metadata": {"labels": {"node-role.kubernetes.io/worker": "", "node.kubernetes.io/instance-type": "m6a.2xlarge"}},
+	 "status": {"nodeInfo": {"osImage": "RHCOS 416.94", "kernelVersion": "5.14.0-427", "containerRuntimeVersion": "cri-o://1.29.1", "kubeletVersion": "v1.29.4"}}},
+	{"metadata": {"labels": {"node-role.kubernetes.io/worker": "", "beta.kubernetes.io/instance-type": "m5.2xlarge"}},
+	 "status": {"nodeInfo": {"osImage": "RHCOS 416.94", "kernelVersion": "5.14.0-427", "containerRuntimeVersion": "cri-o://1.29.1", "kubeletVersion": "v1.29.4"}}}
+]}`
+	fingerprints, err := fingerprintsFromArtifacts(7, models.ClusterData{CloudRegion: "us-east-1"}, []byte(nodes))
+	require.NoError(t, err)
+
+	values := map[string]string{}
+	for _, f := range fingerprints {
+		assert.Equal(t, uint(7), f.ProwJobRunID)
+		values[f.Attribute] = f.Value
+	}
+	assert.Equal(t, map[string]string{
+		FingerprintCloudRegion:              "us-east-1",
+		FingerprintControlPlaneInstanceType: "m6a.xlarge",
+		FingerprintWorkerInstanceType:       "m5.2xlarge,m6a.2xlarge",
+		FingerprintOSImage:                  "RHCOS 416.94",
+		FingerprintKernelVersion:            "5.14.0-427",
+		FingerprintContainerRuntime:         "cri-o://1.29.1",
+		FingerprintKubeletVersion:           "v1.29.4",
+	}, values)
+	assert.Equal(t, FingerprintCloudRegion, fingerprints[0].Attribute, "fingerprints should be sorted by attribute")
+}
+
+func TestFingerprintsFromArtifactsCompactCluster(t *testing.T) {
+	nodes := `{"items": [{"metadata": {"labels": {"node-role.kubernetes.io/control-plane": "", "node-role.kubernetes.io/worker": "", "node.kubernetes.io/instance-type": "c5n.metal"}}}]}`
+	fingerprints, err := fingerprintsFromArtifacts(1, models.ClusterData{}, []byte(nodes))
+	require.NoError(t, err)
+	require.Len(t, fingerprints, 2)
+	assert.Equal(t, FingerprintControlPlaneInstanceType, fingerprints[0].Attribute)
+	assert.Equal(t, "c5n.metal", fingerprints[0].Value)
+	assert.Equal(t, FingerprintWorkerInstanceType, fingerprints[1].Attribute)
+	assert.Equal(t, "c5n.metal", fingerprints[1].Value)
+}
+
+func TestFingerprintsFromArtifactsInvalidNodes(t *testing.T) {
+	fingerprints, err := fingerprintsFromArtifacts(1, models.ClusterData{CloudRegion: "eastus"}, []byte("not json"))
+	assert.Error(t, err)
+	require.Len(t, fingerprints, 1)
+	assert.Equal(t, "eastus", fingerprints[0].Value)
+}
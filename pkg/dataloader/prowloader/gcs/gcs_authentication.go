@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 
 	"cloud.google.com/go/storage"
-	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
@@ -61,13 +60,13 @@ func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
 
 	var authCode string
 	if _, err := fmt.Scan(&authCode); err != nil {
-		log.Errorf("Unable to read authorization code: %v", err)
+		logger.Errorf("Unable to read authorization code: %v", err)
 		return nil
 	}
 
 	tok, err := config.Exchange(context.TODO(), authCode)
 	if err != nil {
-		log.Errorf("Unable to retrieve token from web: %v", err)
+		logger.Errorf("Unable to retrieve token from web: %v", err)
 		return nil
 	}
 
@@ -91,11 +90,11 @@ func saveToken(path string, token *oauth2.Token) {
 	fmt.Printf("Saving credential file to: %s\n", path)
 	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
 	if err != nil {
-		log.Errorf("Unable to cache oauth token: %v", err)
+		logger.Errorf("Unable to cache oauth token: %v", err)
 		return
 	}
 	defer f.Close()
 	if err := json.NewEncoder(f).Encode(token); err != nil {
-		log.Errorf(err.Error())
+		logger.Errorf(err.Error())
 	}
 }
@@ -5,25 +5,32 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"regexp"
 
 	"cloud.google.com/go/storage"
-	log "github.com/sirupsen/logrus"
 	"google.golang.org/api/iterator"
 
 	"github.com/openshift/sippy/pkg/apis/junit"
+	"github.com/openshift/sippy/pkg/logging"
 )
 
+var logger = logging.ForSubsystem("prowloader-gcs")
+
 const TestFailureSummaryFilePrefix = "risk-analysis"
 const ClusterDataFilePrefix = "cluster-data_"
 const JunitRegExStr = "\\/junit.*xml"
 const intervalFilesRegExStr = "\\/(e2e-events|e2e-timelines).*json"
+const nodesFileRegExStr = "\\/gather-extra\\/artifacts\\/nodes\\.json$"
 
 var (
 	defaultRiskAnalysisSummaryFileRegEx *regexp.Regexp
 	defaultClusterDataFileRegEx         *regexp.Regexp
 	defaultJunitFileRegEx               *regexp.Regexp
 	intervalFilesRegex                  *regexp.Regexp
+	nodesFileRegex                      *regexp.Regexp
 )
 
 func GetDefaultRiskAnalysisSummaryFile() *regexp.Regexp {
@@ -54,6 +61,14 @@ func GetIntervalFile() *regexp.Regexp {
 	return intervalFilesRegex
 }
 
+// GetNodesFile matches the nodes gathered from the cluster at the end of a job run.
+func GetNodesFile() *regexp.Regexp {
+	if nodesFileRegex == nil {
+		nodesFileRegex = regexp.MustCompile(nodesFileRegExStr)
+	}
+	return nodesFileRegex
+}
+
 type GCSJobRun struct {
 	// retrieval mechanisms
 	bkt *storage.BucketHandle
@@ -62,6 +77,9 @@ type GCSJobRun struct {
 	gcsJunitPaths  []string
 
 	pathToContent map[string][]byte
+
+	// cacheDir, if set, is a local directory artifacts are read from before GCS, and written to after.
+	cacheDir string
 }
 
 func NewGCSJobRun(bkt *storage.BucketHandle, path string) *GCSJobRun {
@@ -71,11 +89,20 @@ func NewGCSJobRun(bkt *storage.BucketHandle, path string) *GCSJobRun {
 	}
 }
 
+// SetCacheDir enables a local artifact cache. Objects are stored in the directory under their GCS object names, so
+// artifacts can be re-read without fetching them again.
+func (j *GCSJobRun) SetCacheDir(dir string) {
+	j.cacheDir = dir
+}
+
 func (j *GCSJobRun) SetGCSJunitPaths(paths []string) {
 	j.gcsJunitPaths = paths
 }
 
 func (j *GCSJobRun) GetGCSJunitPaths() []string {
+	if len(j.gcsJunitPaths) == 0 {
+		j.gcsJunitPaths = j.cachedMatches(GetDefaultJunitFile())
+	}
 	if len(j.gcsJunitPaths) == 0 {
 		matches := j.FindAllMatches([]*regexp.Regexp{GetDefaultJunitFile()})
 
@@ -109,7 +136,7 @@ func (j *GCSJobRun) GetCombinedJUnitTestSuites(ctx context.Context) (*junit.Test
 
 		currTestSuite := &junit.TestSuite{}
 		if testSuiteErr := xml.Unmarshal(junitContent, currTestSuite); testSuiteErr != nil {
-			log.WithError(testSuiteErr).Warningf("error parsing content for jobrun in file %s path %s", junitFile, j.gcsProwJobPath)
+			logger.WithError(testSuiteErr).Warningf("error parsing content for jobrun in file %s path %s", junitFile, j.gcsProwJobPath)
 			continue
 		}
 		testSuites.Suites = append(testSuites.Suites, currTestSuite)
@@ -125,6 +152,11 @@ func (j *GCSJobRun) GetContent(ctx context.Context, path string) ([]byte, error)
 	if content, ok := j.pathToContent[path]; ok {
 		return content, nil
 	}
+	if j.cacheDir != "" {
+		if content, err := os.ReadFile(filepath.Join(j.cacheDir, path)); err == nil {
+			return content, nil
+		}
+	}
 
 	// Get an Object handle for the path
 	obj := j.bkt.Object(path)
@@ -145,7 +177,48 @@ func (j *GCSJobRun) GetContent(ctx context.Context, path string) ([]byte, error)
 	}
 	defer gcsReader.Close()
 
-	return io.ReadAll(gcsReader)
+	content, err := io.ReadAll(gcsReader)
+	if err != nil {
+		return nil, err
+	}
+	if j.cacheDir != "" {
+		j.writeCache(path, content)
+	}
+	return content, nil
+}
+
+func (j *GCSJobRun) writeCache(path string, content []byte) {
+	cachePath := filepath.Join(j.cacheDir, path)
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		logger.WithError(err).Warningf("error creating artifact cache directory for %s", path)
+		return
+	}
+	if err := os.WriteFile(cachePath, content, 0644); err != nil {
+		logger.WithError(err).Warningf("error caching artifact %s", path)
+	}
+}
+
+// cachedMatches returns the names of cached objects for the job run matching a regex.
+func (j *GCSJobRun) cachedMatches(filename *regexp.Regexp) []string {
+	if j.cacheDir == "" {
+		return nil
+	}
+	var matches []string
+	root := filepath.Join(j.cacheDir, j.gcsProwJobPath)
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(j.cacheDir, path)
+		if err != nil {
+			return nil
+		}
+		if name := filepath.ToSlash(rel); filename.MatchString(name) {
+			matches = append(matches, name)
+		}
+		return nil
+	})
+	return matches
 }
 
 func (j *GCSJobRun) ContentExists(ctx context.Context, path string) bool {
@@ -173,7 +246,7 @@ func (j *GCSJobRun) FindFirstFile(root string, filename *regexp.Regexp) []byte {
 
 			// if we had an error keep looking, or bail?
 			if err != nil {
-				log.WithError(err).Errorf("Error reading file: %s/%s", root, attrs.Name)
+				logger.WithError(err).Errorf("Error reading file: %s/%s", root, attrs.Name)
 				return nil
 			}
 			return data
@@ -0,0 +1,31 @@
+package gcs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedArtifacts(t *testing.T) {
+	cacheDir := t.TempDir()
+	runPath := "logs/periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn/1790000000000000000"
+	junit := runPath + "/artifacts/e2e-aws-ovn/openshift-e2e-test/artifacts/junit/junit_e2e.xml"
+	for _, name := range []string{junit, runPath + "/prowjob.json"} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(cacheDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(cacheDir, name), []byte(`<testsuite name="openshift-tests"></testsuite>`), 0644))
+	}
+
+	// No bucket is set, so anything not served from the cache would panic.
+	jobRun := NewGCSJobRun(nil, runPath)
+	jobRun.SetCacheDir(cacheDir)
+
+	assert.Equal(t, []string{junit}, jobRun.GetGCSJunitPaths())
+	suites, err := jobRun.GetCombinedJUnitTestSuites(context.Background())
+	require.NoError(t, err)
+	require.Len(t, suites.Suites, 1)
+	assert.Equal(t, "openshift-tests", suites.Suites[0].Name)
+}
@@ -11,11 +11,14 @@ import (
 	"time"
 
 	gh "github.com/google/go-github/v45/github"
-	log "github.com/sirupsen/logrus"
 	"github.com/tcnksm/go-gitconfig"
 	"golang.org/x/oauth2"
+
+	"github.com/openshift/sippy/pkg/logging"
 )
 
+var logger = logging.ForSubsystem("prowloader-github")
+
 const commentIDRegex = `META\s*=\s*{(?P<meta>[^}]*)`
 
 // if we have fewer than this threshold remaining we will report rate limited
@@ -59,11 +62,11 @@ func New(ctx context.Context) *Client {
 	}
 	token := os.Getenv("GITHUB_TOKEN")
 	if token == "" {
-		log.Infof("No GitHub token environment variable, checking git config")
+		logger.Infof("No GitHub token environment variable, checking git config")
 		var err error
 		token, err = gitconfig.GithubToken()
 		if err != nil {
-			log.WithError(err).Warningf("unable to retrieve GitHub token from git config")
+			logger.WithError(err).Warningf("unable to retrieve GitHub token from git config")
 		}
 	}
 
@@ -78,7 +81,7 @@ func New(ctx context.Context) *Client {
 		tc := oauth2.NewClient(client.ctx, ts)
 		ghc = gh.NewClient(tc)
 	} else {
-		log.Warningf("using unathenticated GitHub client, requests will be rate-limited")
+		logger.Warningf("using unathenticated GitHub client, requests will be rate-limited")
 		ghc = gh.NewClient(nil)
 	}
 
@@ -168,7 +171,7 @@ func (c *Client) IsPrRecentlyMerged(org, repo string, number int) (*time.Time, *
 		// we expect that gitHubListClosedPRs will return a map, possibly partially filled
 		// so log the error for now and then we will return it once we check to see if we have data for this request or not
 		if err != nil {
-			log.WithError(err).Errorf("Error fetching closed PRs for %s/%s", org, repo)
+			logger.WithError(err).Errorf("Error fetching closed PRs for %s/%s", org, repo)
 		}
 	}
 
@@ -193,7 +196,7 @@ func (c *Client) IsWithinRateLimitThreshold() bool {
 		return true
 	}
 
-	log.Infof("Github Limit:%d, Remaining:%d", rate.Limit, rate.Remaining)
+	logger.Infof("Github Limit:%d, Remaining:%d", rate.Limit, rate.Remaining)
 
 	return rate.Remaining < rateLimitThreshold
 }
@@ -250,7 +253,7 @@ func (c *Client) GetPREntry(org, repo string, number int) (*PREntry, error) {
 	// Get PR from GitHub
 	pr, err := c.PRFetch(prl.org, prl.repo, prl.number)
 	if err != nil {
-		log.WithError(err).
+		logger.WithError(err).
 			WithField("org", prl.org).
 			WithField("repo", prl.repo).
 			WithField("number", prl.number).
@@ -337,7 +340,7 @@ func (c *Client) isCommentIDMatch(comment, commentKey, commentID string) bool {
 			err := json.Unmarshal([]byte(metaJSON), &result)
 
 			if err != nil {
-				log.WithError(err).Errorf("Error searching for commentId: %s, match", commentID)
+				logger.WithError(err).Errorf("Error searching for commentId: %s, match", commentID)
 			} else {
 				if value, ok := result[commentKey]; ok {
 					if value == commentID {
@@ -0,0 +1,186 @@
+package prowloader
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	operatorStateSource = "OperatorState"
+	alertSource         = "Alert"
+
+	OperatorConditionDegraded    = "Degraded"
+	OperatorConditionUnavailable = "Unavailable"
+
+	EventPatternCrashLoop        = "crashloop"
+	EventPatternOOMKill          = "oomkill"
+	EventPatternImagePullBackOff = "image-pull-backoff"
+	EventPatternNodeNotReady     = "node-not-ready"
+)
+
+// intervalsFromGCS loads and parses the full e2e-events interval files for a job run. There is usually one for
+// each phase of the job, i.e. upgrade and conformance. The e2e-timelines files are subsets of the same data, and
+// are skipped.
+func intervalsFromGCS(ctx context.Context, gcsJobRun *gcs.GCSJobRun, paths []string) ([]apitype.EventInterval, error) {
+	var intervals []apitype.EventInterval
+	for _, path := range paths {
+		if !strings.Contains(path, "/e2e-events") {
+			continue
+		}
+		content, err := gcsJobRun.GetContent(ctx, path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error getting interval file %s", path)
+		}
+		list, err := apitype.ParseEventIntervals(content)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error parsing interval file %s", path)
+		}
+		intervals = append(intervals, list.Items...)
+	}
+	return intervals, nil
+}
+
+// operatorConditionsFromIntervals extracts the periods where a cluster operator reported Degraded=True or
+// Available=False. Intervals repeated across interval files are only recorded once.
+func operatorConditionsFromIntervals(jobRunID uint, intervals []apitype.EventInterval) []*models.ProwJobRunOperatorCondition {
+	type key struct {
+		operator, condition string
+		from                time.Time
+	}
+	seen := make(map[key]*models.ProwJobRunOperatorCondition)
+	var conditions []*models.ProwJobRunOperatorCondition
+	for _, i := range intervals {
+		if i.Source != operatorStateSource || i.From == nil || i.To == nil {
+			continue
+		}
+		operator := i.StructuredLocator.Keys["clusteroperator"]
+		if operator == "" {
+			continue
+		}
+
+		var condition string
+		annotations := i.StructuredMessage.Annotations
+		switch {
+		case annotations["condition"] == "Degraded" && annotations["status"] == "True":
+			condition = OperatorConditionDegraded
+		case annotations["condition"] == "Available" && annotations["status"] == "False":
+			condition = OperatorConditionUnavailable
+		default:
+			continue
+		}
+
+		k := key{operator: operator, condition: condition, from: *i.From}
+		if existing, ok := seen[k]; ok {
+			if i.To.After(existing.To) {
+				existing.To = *i.To
+			}
+			continue
+		}
+		seen[k] = &models.ProwJobRunOperatorCondition{
+			ProwJobRunID: jobRunID,
+			Operator:     operator,
+			Condition:    condition,
+			Reason:       i.StructuredMessage.Reason,
+			From:         *i.From,
+			To:           *i.To,
+		}
+		conditions = append(conditions, seen[k])
+	}
+	return conditions
+}
+
+// alertsFromIntervals extracts the periods where an alert was firing. Pending alerts are ignored, and an alert
+// firing in the same namespace from the same time is only recorded once.
+func alertsFromIntervals(jobRunID uint, intervals []apitype.EventInterval) []*models.ProwJobRunAlert {
+	type key struct {
+		name, namespace string
+		from            time.Time
+	}
+	seen := make(map[key]*models.ProwJobRunAlert)
+	var alerts []*models.ProwJobRunAlert
+	for _, i := range intervals {
+		if i.Source != alertSource || i.From == nil || i.To == nil {
+			continue
+		}
+		name := i.StructuredLocator.Keys["alert"]
+		if name == "" || i.StructuredMessage.Annotations["alertstate"] != "firing" {
+			continue
+		}
+
+		k := key{name: name, namespace: i.StructuredLocator.Keys["namespace"], from: *i.From}
+		if existing, ok := seen[k]; ok {
+			if i.To.After(existing.To) {
+				existing.To = *i.To
+			}
+			continue
+		}
+		seen[k] = &models.ProwJobRunAlert{
+			ProwJobRunID: jobRunID,
+			Name:         name,
+			Namespace:    k.namespace,
+			Severity:     i.StructuredMessage.Annotations["severity"],
+			From:         *i.From,
+			To:           *i.To,
+		}
+		alerts = append(alerts, seen[k])
+	}
+	return alerts
+}
+
+// eventPattern classifies an interval as one of the abnormal event patterns we track, returning an empty string if
+// it is not one.
+func eventPattern(i apitype.EventInterval) string {
+	reason := i.StructuredMessage.Reason
+	message := strings.ToLower(i.StructuredMessage.HumanMessage)
+	switch {
+	case reason == "CrashLoopBackOff",
+		reason == "BackOff" && strings.Contains(message, "restarting failed container"):
+		return EventPatternCrashLoop
+	case reason == "OOMKilled", reason == "OOMKilling":
+		return EventPatternOOMKill
+	case reason == "ImagePullBackOff", reason == "ErrImagePull",
+		reason == "BackOff" && strings.Contains(message, "pulling image"):
+		return EventPatternImagePullBackOff
+	case reason == "NodeNotReady":
+		return EventPatternNodeNotReady
+	}
+	return ""
+}
+
+// eventPatternsFromIntervals counts abnormal event patterns by namespace.
+func eventPatternsFromIntervals(jobRunID uint, intervals []apitype.EventInterval) []*models.ProwJobRunEventPattern {
+	type key struct{ pattern, namespace string }
+	counts := make(map[key]int)
+	for _, i := range intervals {
+		pattern := eventPattern(i)
+		if pattern == "" {
+			continue
+		}
+		counts[key{pattern, i.StructuredLocator.Keys["namespace"]}]++
+	}
+
+	patterns := make([]*models.ProwJobRunEventPattern, 0, len(counts))
+	for k, count := range counts {
+		patterns = append(patterns, &models.ProwJobRunEventPattern{
+			ProwJobRunID: jobRunID,
+			Pattern:      k.pattern,
+			Namespace:    k.namespace,
+			Count:        count,
+		})
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Pattern != patterns[j].Pattern {
+			return patterns[i].Pattern < patterns[j].Pattern
+		}
+		return patterns[i].Namespace < patterns[j].Namespace
+	})
+	return patterns
+}
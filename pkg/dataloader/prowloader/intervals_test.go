@@ -0,0 +1,138 @@
+package prowloader
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func operatorInterval(operator, condition, status string, from, to *time.Time) apitype.EventInterval {
+	return apitype.EventInterval{
+		Source: operatorStateSource,
+		StructuredLocator: apitype.Locator{
+			Type: "ClusterOperator",
+			Keys: map[string]string{"clusteroperator": operator},
+		},
+		StructuredMessage: apitype.Message{
+			Reason:      "SomethingBroke",
+			Annotations: map[string]string{"condition": condition, "status": status},
+		},
+		From: from,
+		To:   to,
+	}
+}
+
+func TestOperatorConditionsFromIntervals(t *testing.T) {
+	from := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	later := to.Add(time.Minute)
+
+	intervals := []apitype.EventInterval{
+		operatorInterval("etcd", "Degraded", "True", &from, &to),
+		operatorInterval("kube-apiserver", "Available", "False", &from, &to),
+		// Repeated in a second interval file, extending the end
+		operatorInterval("etcd", "Degraded", "True", &from, &later),
+		// Not problems
+		operatorInterval("etcd", "Degraded", "False", &from, &to),
+		operatorInterval("etcd", "Progressing", "True", &from, &to),
+		operatorInterval("dns", "Available", "True", &from, &to),
+		// Incomplete
+		operatorInterval("network", "Degraded", "True", &from, nil),
+		operatorInterval("", "Degraded", "True", &from, &to),
+		{
+			Source: "KubeEvent",
+			From:   &from,
+			To:     &to,
+		},
+	}
+
+	conditions := operatorConditionsFromIntervals(42, intervals)
+	if assert.Len(t, conditions, 2) {
+		assert.Equal(t, "etcd", conditions[0].Operator)
+		assert.Equal(t, OperatorConditionDegraded, conditions[0].Condition)
+		assert.Equal(t, uint(42), conditions[0].ProwJobRunID)
+		assert.Equal(t, "SomethingBroke", conditions[0].Reason)
+		assert.Equal(t, from, conditions[0].From)
+		assert.Equal(t, later, conditions[0].To)
+
+		assert.Equal(t, "kube-apiserver", conditions[1].Operator)
+		assert.Equal(t, OperatorConditionUnavailable, conditions[1].Condition)
+	}
+}
+
+func TestAlertsFromIntervals(t *testing.T) {
+	from := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+
+	alert := func(name, state string) apitype.EventInterval {
+		return apitype.EventInterval{
+			Source: alertSource,
+			StructuredLocator: apitype.Locator{
+				Type: "Alert",
+				Keys: map[string]string{"alert": name, "namespace": "openshift-etcd"},
+			},
+			StructuredMessage: apitype.Message{
+				Annotations: map[string]string{"alertstate": state, "severity": "warning"},
+			},
+			From: &from,
+			To:   &to,
+		}
+	}
+
+	intervals := []apitype.EventInterval{
+		alert("etcdMembersDown", "firing"),
+		alert("KubePodNotReady", "pending"),
+		alert("", "firing"),
+		operatorInterval("etcd", "Degraded", "True", &from, &to),
+	}
+
+	alerts := alertsFromIntervals(42, intervals)
+	if assert.Len(t, alerts, 1) {
+		assert.Equal(t, "etcdMembersDown", alerts[0].Name)
+		assert.Equal(t, "openshift-etcd", alerts[0].Namespace)
+		assert.Equal(t, "warning", alerts[0].Severity)
+		assert.Equal(t, uint(42), alerts[0].ProwJobRunID)
+	}
+}
+
+func TestEventPatternsFromIntervals(t *testing.T) {
+	event := func(reason, message, namespace string) apitype.EventInterval {
+		keys := map[string]string{}
+		if namespace != "" {
+			keys["namespace"] = namespace
+		}
+		return apitype.EventInterval{
+			Source:            "KubeEvent",
+			StructuredLocator: apitype.Locator{Keys: keys},
+			StructuredMessage: apitype.Message{Reason: reason, HumanMessage: message},
+		}
+	}
+
+	intervals := []apitype.EventInterval{
+		event("BackOff", "Back-off restarting failed container", "openshift-etcd"),
+		event("BackOff", "Back-off restarting failed container", "openshift-etcd"),
+		event("CrashLoopBackOff", "", "openshift-dns"),
+		event("BackOff", "Back-off pulling image \"quay.io/foo\"", "e2e-test-1234"),
+		event("OOMKilling", "", "openshift-monitoring"),
+		event("NodeNotReady", "", ""),
+		event("Scheduled", "Successfully assigned", "openshift-etcd"),
+	}
+
+	patterns := eventPatternsFromIntervals(42, intervals)
+	var got []string
+	for _, p := range patterns {
+		assert.Equal(t, uint(42), p.ProwJobRunID)
+		got = append(got, fmt.Sprintf("%s/%s/%d", p.Pattern, p.Namespace, p.Count))
+	}
+	assert.Equal(t, []string{
+		"crashloop/openshift-dns/1",
+		"crashloop/openshift-etcd/2",
+		"image-pull-backoff/e2e-test-1234/1",
+		"node-not-ready//1",
+		"oomkill/openshift-monitoring/1",
+	}, got)
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -26,18 +27,22 @@ import (
 	"github.com/openshift/sippy/pkg/apis/junit"
 	"github.com/openshift/sippy/pkg/apis/prow"
 	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/dataloader"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/github"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/testconversion"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/github/commenter"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/synthetictests"
 	"github.com/openshift/sippy/pkg/testidentification"
 	"github.com/openshift/sippy/pkg/util"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
+var logger = logging.ForSubsystem("prowloader")
+
 // gcsPathStrip is used to strip out everything but the path, i.e. match "/view/gs/origin-ci-test/"
 // from the path "/view/gs/origin-ci-test/logs/periodic-ci-openshift-release-master-nightly-4.14-e2e-gcp-sdn/1737420379221135360"
 var gcsPathStrip = regexp.MustCompile(`.*/gs/[^/]+/`)
@@ -57,6 +62,9 @@ type ProwLoader struct {
 	prowJobRunCacheLock     sync.RWMutex
 	prowJobRunTestCache     map[string]uint
 	prowJobRunTestCacheLock sync.RWMutex
+	importFailureCache      map[uint]int
+	importFailureCacheLock  sync.RWMutex
+	deadLetteredCount       atomic.Int32
 	variantManager          testidentification.VariantManager
 	suiteCache              map[string]*uint
 	suiteCacheLock          sync.RWMutex
@@ -65,6 +73,12 @@ type ProwLoader struct {
 	config                  *v1config.SippyConfig
 	ghCommenter             *commenter.GitHubCommenter
 	jobsImportedCount       atomic.Int32
+	loadIntervals           bool
+	loadEventPatterns       bool
+	loadFingerprints        bool
+	backfill                *BackfillOptions
+	progress                *dataloader.Progress
+	artifactCacheDir        string
 }
 
 func New(
@@ -78,7 +92,8 @@ func New(
 	syntheticTestManager synthetictests.SyntheticTestManager,
 	releases []string,
 	config *v1config.SippyConfig,
-	ghCommenter *commenter.GitHubCommenter) *ProwLoader {
+	ghCommenter *commenter.GitHubCommenter,
+	loadIntervals, loadEventPatterns bool) *ProwLoader {
 
 	bkt := gcsClient.Bucket(gcsBucket)
 
@@ -93,12 +108,15 @@ func New(
 		prowJobRunCache:      loadProwJobRunCache(dbc),
 		prowJobCache:         loadProwJobCache(dbc),
 		prowJobRunTestCache:  make(map[string]uint),
+		importFailureCache:   loadImportFailureCache(dbc),
 		suiteCache:           make(map[string]*uint),
 		syntheticTestManager: syntheticTestManager,
 		variantManager:       variantManager,
 		releases:             releases,
 		config:               config,
 		ghCommenter:          ghCommenter,
+		loadIntervals:        loadIntervals,
+		loadEventPatterns:    loadEventPatterns,
 	}
 }
 
@@ -119,7 +137,7 @@ func loadProwJobCache(dbc *db.DB) map[string]*models.ProwJob {
 			prowJobCache[j.Name] = j
 		}
 	}
-	log.Infof("job cache created with %d entries from database", len(prowJobCache))
+	logger.Infof("job cache created with %d entries from database", len(prowJobCache))
 	return prowJobCache
 }
 
@@ -146,13 +164,42 @@ func (pl *ProwLoader) Errors() []error {
 	return pl.errors
 }
 
+// Summary returns the job runs seen in each phase of the import, and the rows written.
+func (pl *ProwLoader) Summary() dataloader.LoaderSummary {
+	if pl.progress == nil {
+		return dataloader.LoaderSummary{}
+	}
+	p := pl.progress.Snapshot()
+	return dataloader.LoaderSummary{
+		Counts: map[string]int{
+			"job_runs_discovered":    p.JobRunsDiscovered,
+			"job_runs_fetched":       p.JobRunsFetched,
+			"job_runs_to_import":     p.JobRunsToImport,
+			"junit_parsed":           p.JunitParsed,
+			"job_runs_dead_lettered": int(pl.deadLetteredCount.Load()),
+		},
+		Diff: map[string]int{
+			"job_runs_written": p.JobRunsWritten,
+			"rows_written":     p.RowsWritten,
+		},
+	}
+}
+
 func (pl *ProwLoader) Load() {
 	start := time.Now()
-	log.Infof("started loading prow jobs to DB...")
+	logger.Infof("started loading prow jobs to DB...")
+
+	pl.progress = dataloader.NewProgress(pl.dbc, pl.Name())
+	pl.progress.Start(pl.ctx)
+	defer func() {
+		pl.progress.Finish(len(pl.errors))
+	}()
 
 	// Update unmerged PR statuses in case any have merged
-	if err := pl.syncPRStatus(); err != nil {
-		pl.errors = append(pl.errors, errors.Wrap(err, "error in syncPRStatus"))
+	if pl.backfill == nil {
+		if err := pl.syncPRStatus(); err != nil {
+			pl.errors = append(pl.errors, errors.Wrap(err, "error in syncPRStatus"))
+		}
 	}
 
 	// Grab the ProwJob definitions from prow or CI bigquery. Note that these are the Kube
@@ -165,6 +212,9 @@ func (pl *ProwLoader) Load() {
 		if len(bqErrs) > 0 {
 			pl.errors = append(pl.errors, bqErrs...)
 		}
+	} else if pl.backfill != nil {
+		pl.errors = append(pl.errors, errors.New("backfill requires loading from OpenShift CI BigQuery"))
+		return
 	} else {
 		jobsJSON, err := fetchJobsJSON(pl.config.Prow.URL)
 		if err != nil {
@@ -181,6 +231,7 @@ func (pl *ProwLoader) Load() {
 	queue := make(chan *prow.ProwJob)
 	errsCh := make(chan error, len(prowJobs))
 	total := len(prowJobs)
+	pl.progress.AddJobRunsDiscovered(total)
 
 	// Producer to keep feeding the queue
 	go prowJobsProducer(pl.ctx, queue, prowJobs)
@@ -194,15 +245,16 @@ func (pl *ProwLoader) Load() {
 			for job := range queue {
 				if err := ctx.Err(); err != nil {
 					errsCh <- err
-					log.WithError(err).Warningf("consumer exiting, got error")
+					logger.WithError(err).Warningf("consumer exiting, got error")
 					break
 				}
 				if err := pl.processProwJob(ctx, job); err != nil {
 					errsCh <- err
-					log.WithError(err).Warningf("couldn't import job %s/%s, continuing", job.Spec.Job, job.Status.BuildID)
+					logger.WithError(err).Warningf("couldn't import job %s/%s, continuing", job.Spec.Job, job.Status.BuildID)
 				}
 				pl.jobsImportedCount.Add(1)
-				log.Infof("%d of %d job runs processed", pl.jobsImportedCount.Load(), total)
+				pl.progress.AddJobRunFetched()
+				logger.Infof("%d of %d job runs processed", pl.jobsImportedCount.Load(), total)
 			}
 		}(pl.ctx)
 	}
@@ -213,10 +265,13 @@ func (pl *ProwLoader) Load() {
 		pl.errors = append(pl.errors, err)
 	}
 
+	if count := pl.deadLetteredCount.Load(); count > 0 {
+		logger.Warningf("skipped %d dead-lettered job runs, see /api/load/dead_letters", count)
+	}
 	if len(pl.errors) > 0 {
-		log.Warningf("encountered %d errors while importing job runs", len(pl.errors))
+		logger.Warningf("encountered %d errors while importing job runs", len(pl.errors))
 	}
-	log.Infof("finished importing new job runs in %+v", time.Since(start))
+	logger.Infof("finished importing new job runs in %+v", time.Since(start))
 }
 
 func prowJobsProducer(ctx context.Context, queue chan *prow.ProwJob, jobs []prow.ProwJob) {
@@ -231,7 +286,7 @@ func prowJobsProducer(ctx context.Context, queue chan *prow.ProwJob, jobs []prow
 }
 
 func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) error {
-	pjLog := log.WithFields(log.Fields{
+	pjLog := logger.WithFields(log.Fields{
 		"job":     pj.Spec.Job,
 		"buildID": pj.Status.BuildID,
 	})
@@ -239,7 +294,7 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 	for _, release := range pl.releases {
 		cfg, ok := pl.config.Releases[release]
 		if !ok {
-			log.Warningf("configuration not found for release %q", release)
+			logger.Warningf("configuration not found for release %q", release)
 			continue
 		}
 
@@ -247,6 +302,7 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 			if err := pl.prowJobToJobRun(ctx, pj, release); err != nil {
 				err = errors.Wrapf(err, "error converting prow job to job run: %s", pj.Spec.Job)
 				pjLog.WithError(err).Warning("prow import error")
+				pl.recordImportFailure(ctx, pj, err)
 				return err
 			}
 			return nil
@@ -256,7 +312,7 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 			re, err := regexp.Compile(expr)
 			if err != nil {
 				err = errors.Wrap(err, "invalid regex in configuration")
-				log.WithError(err).Errorf("config regex error")
+				logger.WithError(err).Errorf("config regex error")
 				continue
 			}
 
@@ -264,6 +320,7 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 				if err := pl.prowJobToJobRun(ctx, pj, release); err != nil {
 					err = errors.Wrapf(err, "error converting prow job to job run: %s", pj.Spec.Job)
 					pjLog.WithError(err).Warning("prow import error")
+					pl.recordImportFailure(ctx, pj, err)
 					return err
 				}
 				return nil
@@ -277,7 +334,7 @@ func (pl *ProwLoader) processProwJob(ctx context.Context, pj *prow.ProwJob) erro
 
 func (pl *ProwLoader) syncPRStatus() error {
 	if pl.githubClient == nil {
-		log.Infof("No GitHub client, skipping PR sync")
+		logger.Infof("No GitHub client, skipping PR sync")
 		return nil
 	}
 
@@ -289,7 +346,7 @@ func (pl *ProwLoader) syncPRStatus() error {
 	}
 
 	for _, pr := range pulls {
-		logger := log.WithField("org", pr.Org).
+		prLogger := logger.WithField("org", pr.Org).
 			WithField("repo", pr.Repo).
 			WithField("number", pr.Number).
 			WithField("sha", pr.SHA)
@@ -313,7 +370,7 @@ func (pl *ProwLoader) syncPRStatus() error {
 				if pr.MergedAt != recentMergedAt {
 					pr.MergedAt = recentMergedAt
 					if res := pl.dbc.DB.Save(pr); res.Error != nil {
-						logger.WithError(res.Error).Errorf("unexpected error updating pull request %s (%s)", pr.Link, pr.SHA)
+						prLogger.WithError(res.Error).Errorf("unexpected error updating pull request %s (%s)", pr.Link, pr.SHA)
 						continue
 					}
 				}
@@ -326,7 +383,7 @@ func (pl *ProwLoader) syncPRStatus() error {
 				pendingComments, err := pl.ghCommenter.QueryPRPendingComments(pr.Org, pr.Repo, pr.Number, models.CommentTypeRiskAnalysis)
 
 				if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-					logger.WithError(err).Error("Unable to fetch pending comments ")
+					prLogger.WithError(err).Error("Unable to fetch pending comments ")
 				}
 
 				for _, pc := range pendingComments {
@@ -388,29 +445,55 @@ func GetClusterDataBytes(ctx context.Context, bkt *storage.BucketHandle, path st
 
 	bytes, err := gcsJobRun.GetContent(ctx, match)
 	if err != nil {
-		log.WithError(err).Errorf("failed to read cluster-data bytes for: %s", match)
+		logger.WithError(err).Errorf("failed to read cluster-data bytes for: %s", match)
 		return []byte{}, err
 	} else if bytes == nil {
-		log.Warnf("empty cluster-data bytes found for: %s", match)
+		logger.Warnf("empty cluster-data bytes found for: %s", match)
 		return []byte{}, nil
 	}
 
 	return bytes, nil
 }
 
+// releaseInJobName matches the release in job names like periodic-ci-openshift-release-master-nightly-4.16-e2e-aws.
+var releaseInJobName = regexp.MustCompile(`\d+\.\d+`)
+
+// isBranchAgnostic returns true for jobs without a release in their name, which may test several releases'
+// payloads using environment overrides.
+func isBranchAgnostic(jobName string) bool {
+	return !releaseInJobName.MatchString(jobName)
+}
+
+// resolveRunRelease returns the release a branch-agnostic job's run tested according to its cluster data, if it's
+// one being loaded and differs from the job's release. Otherwise it returns empty, and the run is reported under
+// the job's release.
+func (pl *ProwLoader) resolveRunRelease(pjLog log.FieldLogger, jobRelease string, cd models.ClusterData) string {
+	if cd.Release == "" || cd.Release == jobRelease {
+		return ""
+	}
+	for _, r := range pl.releases {
+		if r == cd.Release {
+			pjLog.WithField("release", cd.Release).Info("run tested a different release than its job")
+			return cd.Release
+		}
+	}
+	pjLog.WithField("release", cd.Release).Debug("run tested a release not being loaded, using the job's release")
+	return ""
+}
+
 func GetClusterData(ctx context.Context, bkt *storage.BucketHandle, path string, matches []string) models.ClusterData {
 	cd := models.ClusterData{}
 	bytes, err := GetClusterDataBytes(ctx, bkt, path, matches)
 	if err != nil {
-		log.WithError(err).Error("failed to get prow job variant data, returning empty cluster data and proceeding")
+		logger.WithError(err).Error("failed to get prow job variant data, returning empty cluster data and proceeding")
 		return cd
 	} else if bytes == nil {
-		log.Warnf("empty job variant data file, returning empty cluster data and proceeding")
+		logger.Warnf("empty job variant data file, returning empty cluster data and proceeding")
 		return cd
 	}
 	err = json.Unmarshal(bytes, &cd)
 	if err != nil {
-		log.WithError(err).Error("failed to unmarshal cluster-data bytes, returning empty cluster data")
+		logger.WithError(err).Error("failed to unmarshal cluster-data bytes, returning empty cluster data")
 		return cd
 	}
 
@@ -421,7 +504,7 @@ func ParseVariantDataFile(bytes []byte) (map[string]string, error) {
 	rawJSONMap := make(map[string]interface{})
 	err := json.Unmarshal(bytes, &rawJSONMap)
 	if err != nil {
-		log.WithError(err).Errorf("failed to unmarshal prow cluster data")
+		logger.WithError(err).Errorf("failed to unmarshal prow cluster data")
 		return map[string]string{}, err
 	}
 	// Convert the raw json map to string->string, discarding anything that doesn't parse to a string.
@@ -465,7 +548,7 @@ func findMostRecentDateTimeMatch(names []string) string {
 
 func extractDateTimeName(name string) *DateTimeName {
 	if !clusterDataDateTimeName.MatchString(name) {
-		log.Errorf("Name did not match date time format: %s", name)
+		logger.Errorf("Name did not match date time format: %s", name)
 		return nil
 	}
 
@@ -491,12 +574,12 @@ func extractDateTimeName(name string) *DateTimeName {
 func mostRecentDateTimeName(one, two DateTimeName) DateTimeName {
 	oneDate, err := strconv.ParseInt(one.Date, 10, 64)
 	if err != nil {
-		log.WithError(err).Errorf("Error parsing date for %s", one.Name)
+		logger.WithError(err).Errorf("Error parsing date for %s", one.Name)
 	}
 
 	twoDate, err := strconv.ParseInt(two.Date, 10, 64)
 	if err != nil {
-		log.WithError(err).Errorf("Error parsing date for %s", two.Name)
+		logger.WithError(err).Errorf("Error parsing date for %s", two.Name)
 	}
 
 	if oneDate > twoDate {
@@ -510,12 +593,12 @@ func mostRecentDateTimeName(one, two DateTimeName) DateTimeName {
 	// they are the same so compare the times
 	oneTime, err := strconv.ParseInt(one.Time, 10, 64)
 	if err != nil {
-		log.WithError(err).Errorf("Error parsing time for %s", one.Name)
+		logger.WithError(err).Errorf("Error parsing time for %s", one.Name)
 	}
 
 	twoTime, err := strconv.ParseInt(two.Time, 10, 64)
 	if err != nil {
-		log.WithError(err).Errorf("Error parsing time for %s", two.Name)
+		logger.WithError(err).Errorf("Error parsing time for %s", two.Name)
 	}
 
 	if oneTime > twoTime {
@@ -526,7 +609,7 @@ func mostRecentDateTimeName(one, two DateTimeName) DateTimeName {
 }
 
 func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, release string) error {
-	pjLog := log.WithFields(log.Fields{
+	pjLog := logger.WithFields(log.Fields{
 		"job":     pj.Spec.Job,
 		"buildID": pj.Status.BuildID,
 		"start":   pj.Status.StartTime,
@@ -543,6 +626,12 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 		return nil
 	}
 
+	if pl.deadLettered(uint(id)) {
+		pl.deadLetteredCount.Add(1)
+		pjLog.Debug("skipping, job run is dead-lettered after repeated import failures")
+		return nil
+	}
+
 	pjLog.Infof("starting processing")
 
 	// find all files here then pass to getClusterData
@@ -555,11 +644,30 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 		return err
 	}
 	gcsJobRun := gcs.NewGCSJobRun(pl.bkt, path)
-	allMatches := gcsJobRun.FindAllMatches([]*regexp.Regexp{gcs.GetDefaultJunitFile()})
-	var junitMatches []string
-	if len(allMatches) > 0 {
-		junitMatches = allMatches[0]
+	gcsJobRun.SetCacheDir(pl.artifactCacheDir)
+	fileRegexes := []*regexp.Regexp{gcs.GetDefaultJunitFile()}
+	intervalIndex, clusterDataIndex, nodesIndex := -1, -1, -1
+	if pl.loadIntervals || pl.loadEventPatterns {
+		intervalIndex = len(fileRegexes)
+		fileRegexes = append(fileRegexes, gcs.GetIntervalFile())
+	}
+	branchAgnostic := isBranchAgnostic(pj.Spec.Job)
+	if branchAgnostic || pl.loadFingerprints {
+		clusterDataIndex = len(fileRegexes)
+		fileRegexes = append(fileRegexes, gcs.GetDefaultClusterDataFile())
+	}
+	if pl.loadFingerprints {
+		nodesIndex = len(fileRegexes)
+		fileRegexes = append(fileRegexes, gcs.GetNodesFile())
+	}
+	allMatches := gcsJobRun.FindAllMatches(fileRegexes)
+	matches := func(i int) []string {
+		if i < 0 || i >= len(allMatches) {
+			return nil
+		}
+		return allMatches[i]
 	}
+	junitMatches, intervalMatches := matches(0), matches(intervalIndex)
 
 	// Lock the whole prow job block to avoid trying to create the pj multiple times concurrently\
 	// (resulting in a DB error)
@@ -582,7 +690,9 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 	} else {
 		saveDB := false
 		newVariants := pl.variantManager.IdentifyVariants(pj.Spec.Job)
+		var variantChanges []models.VariantChange
 		if !reflect.DeepEqual(newVariants, []string(dbProwJob.Variants)) || dbProwJob.Kind != models.ProwKind(pj.Spec.Type) {
+			variantChanges = models.DiffVariants(dbProwJob, dbProwJob.Variants, newVariants)
 			dbProwJob.Kind = models.ProwKind(pj.Spec.Type)
 			dbProwJob.Variants = newVariants
 			saveDB = true
@@ -598,34 +708,94 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 				return res.Error
 			}
 		}
+		if len(variantChanges) > 0 {
+			if res := pl.dbc.DB.WithContext(ctx).Create(&variantChanges); res.Error != nil {
+				pjLog.WithError(res.Error).Warning("error recording variant changes")
+			}
+		}
 	}
 	pl.prowJobCacheLock.Unlock()
 
 	pl.prowJobRunCacheLock.RLock()
 	_, ok := pl.prowJobRunCache[uint(id)]
 	pl.prowJobRunCacheLock.RUnlock()
+	if ok && pl.backfill != nil {
+		pjLog.Infof("job run was already processed, replacing for backfill")
+		if err := pl.deleteJobRunData(ctx, uint(id)); err != nil {
+			return errors.Wrap(err, "error deleting job run data for backfill")
+		}
+		ok = false
+	}
 	if ok {
 		pjLog.Infof("job run was already processed")
 	} else {
 		pjLog.Info("processing GCS bucket")
+		pl.progress.AddJobRunToImport()
 
 		tests, failures, overallResult, err := pl.prowJobRunTestsFromGCS(ctx, pj, uint(id), path, junitMatches)
 		if err != nil {
 			return err
 		}
+		pl.progress.AddJunitParsed()
 
 		pulls := pl.findOrAddPullRequests(pj.Spec.Refs, path)
 
+		var clusterData models.ClusterData
+		if clusterDataMatches := matches(clusterDataIndex); len(clusterDataMatches) > 0 {
+			clusterData = GetClusterData(ctx, pl.bkt, path, clusterDataMatches)
+		}
+		var runRelease string
+		if branchAgnostic {
+			runRelease = pl.resolveRunRelease(pjLog, release, clusterData)
+		}
+
+		var fingerprints []*models.ProwJobRunFingerprint
+		if pl.loadFingerprints {
+			var nodesJSON []byte
+			if nodesMatches := matches(nodesIndex); len(nodesMatches) > 0 {
+				if nodesJSON, err = gcsJobRun.GetContent(ctx, nodesMatches[0]); err != nil {
+					pjLog.WithError(err).Warning("error loading nodes, continuing")
+				}
+			}
+			if fingerprints, err = fingerprintsFromArtifacts(uint(id), clusterData, nodesJSON); err != nil {
+				pjLog.WithError(err).Warning("error parsing nodes, continuing")
+			}
+		}
+
+		// Interval files are large, and only present for some jobs, so a failure here shouldn't prevent
+		// importing the run.
+		var operatorConditions []*models.ProwJobRunOperatorCondition
+		var alerts []*models.ProwJobRunAlert
+		var eventPatterns []*models.ProwJobRunEventPattern
+		var intervalsLoaded, eventPatternsLoaded bool
+		if len(intervalMatches) > 0 {
+			intervals, err := intervalsFromGCS(ctx, gcsJobRun, intervalMatches)
+			if err != nil {
+				pjLog.WithError(err).Warning("error loading intervals, continuing")
+			} else {
+				if pl.loadIntervals {
+					operatorConditions = operatorConditionsFromIntervals(uint(id), intervals)
+					alerts = alertsFromIntervals(uint(id), intervals)
+					intervalsLoaded = true
+				}
+				if pl.loadEventPatterns {
+					eventPatterns = eventPatternsFromIntervals(uint(id), intervals)
+					eventPatternsLoaded = true
+				}
+			}
+		}
+
 		var duration time.Duration
 		if pj.Status.CompletionTime != nil {
 			duration = pj.Status.CompletionTime.Sub(pj.Status.StartTime)
 		}
 
-		err = pl.dbc.DB.WithContext(ctx).Create(&models.ProwJobRun{
+		err = pl.dbc.DB.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&models.ProwJobRun{
 			Model: gorm.Model{
 				ID: uint(id),
 			},
 			Cluster:       pj.Spec.Cluster,
+			Release:       runRelease,
 			Duration:      duration,
 			ProwJob:       *dbProwJob,
 			ProwJobID:     dbProwJob.ID,
@@ -635,6 +805,10 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 			PullRequests:  pulls,
 			TestFailures:  failures,
 			Succeeded:     overallResult == sippyprocessingv1.JobSucceeded,
+
+			IntervalsLoaded:     intervalsLoaded,
+			EventPatternsLoaded: eventPatternsLoaded,
+			JunitParserVersion:  JunitParserVersion,
 		}).Error
 		if err != nil {
 			return err
@@ -644,16 +818,90 @@ func (pl *ProwLoader) prowJobToJobRun(ctx context.Context, pj *prow.ProwJob, rel
 		pl.prowJobRunCache[uint(id)] = true
 		pl.prowJobRunCacheLock.Unlock()
 
-		err = pl.dbc.DB.WithContext(ctx).Debug().CreateInBatches(tests, 1000).Error
+		// Everything below is upserted on its natural key, so re-importing a run that was partially loaded
+		// doesn't duplicate its results.
+		outputs, err := saveJobRunTests(pl.dbc.DB.WithContext(ctx), tests)
 		if err != nil {
 			return err
 		}
+
+		if len(operatorConditions) > 0 {
+			err := pl.dbc.DB.WithContext(ctx).
+				Clauses(db.ProwJobRunOperatorConditionKey.OnConflict("reason", "to")).
+				CreateInBatches(operatorConditions, 1000).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(alerts) > 0 {
+			err := pl.dbc.DB.WithContext(ctx).
+				Clauses(db.ProwJobRunAlertKey.OnConflict("severity", "to")).
+				CreateInBatches(alerts, 1000).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(eventPatterns) > 0 {
+			err := pl.dbc.DB.WithContext(ctx).
+				Clauses(db.ProwJobRunEventPatternKey.OnConflict("count")).
+				CreateInBatches(eventPatterns, 1000).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(fingerprints) > 0 {
+			err := pl.dbc.DB.WithContext(ctx).
+				Clauses(db.ProwJobRunFingerprintKey.OnConflict("value")).
+				Create(fingerprints).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := pl.clearImportFailure(ctx, uint(id)); err != nil {
+			pjLog.WithError(err).Warning("error clearing job run import failure")
+		}
+		pl.progress.AddJobRunWritten(1 + len(tests) + outputs + len(operatorConditions) + len(alerts) + len(eventPatterns) + len(fingerprints))
 	}
 
 	pjLog.Infof("processing complete")
 	return nil
 }
 
+// saveJobRunTests upserts a job run's test results and their outputs, returning the number of outputs written.
+func saveJobRunTests(tx *gorm.DB, tests []*models.ProwJobRunTest) (int, error) {
+	if len(tests) == 0 {
+		return 0, nil
+	}
+	err := tx.Debug().
+		Clauses(db.ProwJobRunTestKey.OnConflict("status", "duration", "cascade_failure")).
+		Omit("ProwJobRunTestOutput").
+		CreateInBatches(tests, 1000).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// Outputs are upserted separately, as gorm only resolves association conflicts on the primary key.
+	var outputs []*models.ProwJobRunTestOutput
+	for _, test := range tests {
+		if test.ProwJobRunTestOutput != nil {
+			test.ProwJobRunTestOutput.ProwJobRunTestID = test.ID
+			outputs = append(outputs, test.ProwJobRunTestOutput)
+		}
+	}
+	if len(outputs) > 0 {
+		err := tx.
+			Clauses(db.ProwJobRunTestOutputKey.OnConflict("output")).
+			CreateInBatches(outputs, 1000).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(outputs), nil
+}
+
 func GetGCSPathForProwJobURL(pjLog log.FieldLogger, prowJobURL string) (string, error) {
 	// this err validation has moved up
 	// and will exit before we save / update the ProwJob
@@ -677,9 +925,9 @@ func GetGCSPathForProwJobURL(pjLog log.FieldLogger, prowJobURL string) (string,
 func (pl *ProwLoader) findOrAddPullRequests(refs *prow.Refs, pjPath string) []models.ProwPullRequest {
 	if refs == nil || pl.githubClient == nil {
 		if refs == nil {
-			log.Debug("findOrAddPullRequests nil refs")
+			logger.Debug("findOrAddPullRequests nil refs")
 		} else {
-			log.Debug("findOrAddPullRequests nil githubclient")
+			logger.Debug("findOrAddPullRequests nil githubclient")
 		}
 		return nil
 	}
@@ -693,13 +941,13 @@ func (pl *ProwLoader) findOrAddPullRequests(refs *prow.Refs, pjPath string) []mo
 
 		mergedAt, err := pl.githubClient.GetPRSHAMerged(refs.Org, refs.Repo, pr.Number, pr.SHA)
 		if err != nil {
-			log.WithError(err).Warningf("could not fetch pull request status from GitHub; org=%q repo=%q number=%q sha=%q", refs.Org, refs.Repo, pr.Number, pr.SHA)
+			logger.WithError(err).Warningf("could not fetch pull request status from GitHub; org=%q repo=%q number=%q sha=%q", refs.Org, refs.Repo, pr.Number, pr.SHA)
 		} else {
 			// pr should be cached from lookup above
 			if pr.Title == "" {
 				ghTitle, err := pl.githubClient.GetPRTitle(refs.Org, refs.Repo, pr.Number)
 				if err != nil {
-					log.WithError(err).Warningf("could not fetch pull request title from GitHub; org=%q repo=%q number=%q sha=%q", refs.Org, refs.Repo, pr.Number, pr.SHA)
+					logger.WithError(err).Warningf("could not fetch pull request title from GitHub; org=%q repo=%q number=%q sha=%q", refs.Org, refs.Repo, pr.Number, pr.SHA)
 				} else if ghTitle != nil {
 					pr.Title = *ghTitle
 				}
@@ -707,7 +955,7 @@ func (pl *ProwLoader) findOrAddPullRequests(refs *prow.Refs, pjPath string) []mo
 			if pr.Link == "" {
 				ghLink, err := pl.githubClient.GetPRURL(refs.Org, refs.Repo, pr.Number)
 				if err != nil {
-					log.WithError(err).Warningf("could not fetch pull request url from GitHub; org=%q repo=%q number=%q sha=%q", refs.Org, refs.Repo, pr.Number, pr.SHA)
+					logger.WithError(err).Warningf("could not fetch pull request url from GitHub; org=%q repo=%q number=%q sha=%q", refs.Org, refs.Repo, pr.Number, pr.SHA)
 				} else if ghLink != nil {
 					pr.Link = *ghLink
 				}
@@ -715,14 +963,16 @@ func (pl *ProwLoader) findOrAddPullRequests(refs *prow.Refs, pjPath string) []mo
 		}
 
 		if pr.Link == "" {
-			log.Debugf("findOrAddPullRequests skipping empty link for sha: %s", pr.SHA)
+			logger.Debugf("findOrAddPullRequests skipping empty link for sha: %s", pr.SHA)
 			continue
 		}
 
 		// any concerns if we are missing title?
 
 		// create / update any presubmit comment records
-		pl.ghCommenter.UpdatePendingCommentRecords(refs.Org, refs.Repo, pr.Number, pr.SHA, models.CommentTypeRiskAnalysis, mergedAt, pjPath)
+		if pl.backfill == nil {
+			pl.ghCommenter.UpdatePendingCommentRecords(refs.Org, refs.Repo, pr.Number, pr.SHA, models.CommentTypeRiskAnalysis, mergedAt, pjPath)
+		}
 
 		pull := models.ProwPullRequest{}
 		res := pl.dbc.DB.Where("link = ? and sha = ?", pr.Link, pr.SHA).First(&pull)
@@ -738,19 +988,19 @@ func (pl *ProwLoader) findOrAddPullRequests(refs *prow.Refs, pjPath string) []mo
 			pull.Number = pr.Number
 			res := pl.dbc.DB.Save(&pull)
 			if res.Error != nil {
-				log.WithError(res.Error).Warningf("could not save pull request %s (%s)", pr.Link, pr.SHA)
+				logger.WithError(res.Error).Warningf("could not save pull request %s (%s)", pr.Link, pr.SHA)
 				continue
 			}
 
 		} else if res.Error != nil {
-			log.WithError(res.Error).Errorf("unexpected error looking for pull request %s (%s)", pr.Link, pr.SHA)
+			logger.WithError(res.Error).Errorf("unexpected error looking for pull request %s (%s)", pr.Link, pr.SHA)
 			continue
 		}
 
 		if pull.MergedAt == nil || *pull.MergedAt != *mergedAt {
 			pull.MergedAt = mergedAt
 			if res := pl.dbc.DB.Save(pull); res.Error != nil {
-				log.WithError(res.Error).Errorf("unexpected error updating pull request %s (%s)", pr.Link, pr.SHA)
+				logger.WithError(res.Error).Errorf("unexpected error updating pull request %s (%s)", pr.Link, pr.SHA)
 				continue
 			}
 		}
@@ -777,7 +1027,7 @@ func (pl *ProwLoader) findOrAddTest(name string) (uint, error) {
 		test.Name = name
 		tx := pl.dbc.DB.Save(test)
 		if tx.Error != nil {
-			log.WithError(tx.Error).Warningf("failed to create test %q", name)
+			logger.WithError(tx.Error).Warningf("failed to create test %q", name)
 			return 0, tx.Error
 		}
 	}
@@ -815,21 +1065,23 @@ func (pl *ProwLoader) prowJobRunTestsFromGCS(ctx context.Context, pj *prow.ProwJ
 	failures := 0
 
 	gcsJobRun := gcs.NewGCSJobRun(pl.bkt, path)
+	gcsJobRun.SetCacheDir(pl.artifactCacheDir)
 	gcsJobRun.SetGCSJunitPaths(junitPaths)
 	suites, err := gcsJobRun.GetCombinedJUnitTestSuites(ctx)
 	if err != nil {
-		log.Warningf("failed to get junit test suites: %s", err.Error())
+		logger.Warningf("failed to get junit test suites: %s", err.Error())
 		return []*models.ProwJobRunTest{}, 0, "", err
 	}
 	testCases := make(map[string]*models.ProwJobRunTest)
 	for _, suite := range suites.Suites {
 		suiteID := pl.findSuite(suite.Name)
 		if suiteID == nil {
-			log.Infof("skipping suite %q as it's not listed for import", suite.Name)
+			logger.Infof("skipping suite %q as it's not listed for import", suite.Name)
 			continue
 		}
 
 		pl.extractTestCases(suite, suiteID, testCases)
+		markCascadeFailures(suite, testCases)
 	}
 
 	syntheticSuite, jobResult := testconversion.ConvertProwJobRunToSyntheticTests(*pj, testCases, pl.syntheticTestManager)
@@ -840,17 +1092,12 @@ func (pl *ProwLoader) prowJobRunTestsFromGCS(ctx context.Context, pj *prow.ProwJ
 		panic("synthetic suite is missing from the database")
 	}
 	pl.extractTestCases(syntheticSuite, suiteID, testCases)
-	log.Infof("synthetic suite had %d tests", syntheticSuite.NumTests)
-
-	results := make([]*models.ProwJobRunTest, 0)
-	for k := range testCases {
-		if testidentification.IsIgnoredTest(k) {
-			continue
-		}
+	logger.Infof("synthetic suite had %d tests", syntheticSuite.NumTests)
 
-		testCases[k].ProwJobRunID = id
-		results = append(results, testCases[k])
-		if testCases[k].Status == 12 {
+	results := dedupeTestResults(testCases)
+	for _, r := range results {
+		r.ProwJobRunID = id
+		if r.Status == 12 {
 			failures++
 		}
 	}
@@ -858,6 +1105,61 @@ func (pl *ProwLoader) prowJobRunTestsFromGCS(ctx context.Context, pj *prow.ProwJ
 	return results, failures, jobResult, nil
 }
 
+// testResultKey is the natural key of a test result within a job run.
+type testResultKey struct {
+	testID  uint
+	suiteID uint
+}
+
+// dedupeTestResults returns the test results to store, merging those sharing a natural key. Child suites are
+// stored under their parent's suite, so a test repeated across them would otherwise be upserted twice in one
+// statement, which postgres rejects.
+func dedupeTestResults(testCases map[string]*models.ProwJobRunTest) []*models.ProwJobRunTest {
+	names := make([]string, 0, len(testCases))
+	for k := range testCases {
+		if !testidentification.IsIgnoredTest(k) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+
+	results := make([]*models.ProwJobRunTest, 0, len(names))
+	byKey := map[testResultKey]*models.ProwJobRunTest{}
+	for _, k := range names {
+		tc := testCases[k]
+		key := testResultKey{testID: tc.TestID}
+		if tc.SuiteID != nil {
+			key.suiteID = *tc.SuiteID
+		}
+		if existing, ok := byKey[key]; ok {
+			mergeTestResult(existing, tc)
+			continue
+		}
+		byKey[key] = tc
+		results = append(results, tc)
+	}
+	return results
+}
+
+// mergeTestResult folds another execution of a test into an existing result. As within a suite, one pass among
+// failures makes a flake.
+func mergeTestResult(existing, other *models.ProwJobRunTest) {
+	success, failure, flake := int(sippyprocessingv1.TestStatusSuccess), int(sippyprocessingv1.TestStatusFailure),
+		int(sippyprocessingv1.TestStatusFlake)
+	switch {
+	case existing.Status == other.Status:
+	case existing.Status == flake || other.Status == flake,
+		existing.Status == success && other.Status == failure,
+		existing.Status == failure && other.Status == success:
+		existing.Status = flake
+	}
+	if existing.ProwJobRunTestOutput == nil {
+		existing.ProwJobRunTestOutput = other.ProwJobRunTestOutput
+	}
+	// A failure is only a cascade if every execution was.
+	existing.CascadeFailure = existing.Status == failure && existing.CascadeFailure && other.CascadeFailure
+}
+
 func (pl *ProwLoader) extractTestCases(suite *junit.TestSuite, suiteID *uint, testCases map[string]*models.ProwJobRunTest) {
 	testOutputMetadataExtractor := TestFailureMetadataExtractor{}
 
@@ -887,7 +1189,7 @@ func (pl *ProwLoader) extractTestCases(suite *junit.TestSuite, suiteID *uint, te
 				for _, m := range extractedMetadata {
 					jsonb := pgtype.JSONB{}
 					if err := jsonb.Set(m); err != nil {
-						log.WithError(err).Error("error setting jsonb value with extracted test metadata")
+						logger.WithError(err).Error("error setting jsonb value with extracted test metadata")
 					}
 					failureOutput.Metadata = append(failureOutput.Metadata, models.ProwJobRunTestOutputMetadata{
 						Metadata: jsonb,
@@ -899,7 +1201,7 @@ func (pl *ProwLoader) extractTestCases(suite *junit.TestSuite, suiteID *uint, te
 		if existing, ok := testCases[testCacheKey]; !ok {
 			testID, err := pl.findOrAddTest(tc.Name)
 			if err != nil {
-				log.WithError(err).Warningf("could not find or create test %q", tc.Name)
+				logger.WithError(err).Warningf("could not find or create test %q", tc.Name)
 				continue
 			}
 
@@ -4,6 +4,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/apis/junit"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db/models"
 )
 
 func TestDateTimeNameComparisons(t *testing.T) {
@@ -86,3 +91,59 @@ func TestParseVariantDataFile(t *testing.T) {
 	assert.Equal(t, "IPv4", clusterData["NetworkStack"])
 	assert.Equal(t, "foo", clusterData["AddonProp1"])
 }
+
+func TestIsBranchAgnostic(t *testing.T) {
+	assert.False(t, isBranchAgnostic("periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"))
+	assert.False(t, isBranchAgnostic("periodic-ci-openshift-release-master-ci-4.16-upgrade-from-stable-4.15-e2e-aws-ovn-upgrade"))
+	assert.True(t, isBranchAgnostic("periodic-ci-openshift-hypershift-main-periodics-e2e-aws-ovn"))
+}
+
+func TestExtractTestCasesNestedSuites(t *testing.T) {
+	pl := &ProwLoader{prowJobRunTestCache: map[string]uint{"test a": 1, "test b": 2, "test c": 3}}
+	failed := func(name string) *junit.TestCase {
+		return &junit.TestCase{Name: name, FailureOutput: &junit.FailureOutput{Output: name + " failed"}}
+	}
+	suite := &junit.TestSuite{
+		Name:      "openshift-tests",
+		TestCases: []*junit.TestCase{{Name: "test c"}},
+		Children: []*junit.TestSuite{
+			{Name: "shard-1", TestCases: []*junit.TestCase{{Name: "test a"}, failed("test b")}},
+			{Name: "shard-2", TestCases: []*junit.TestCase{failed("test a"), failed("test b")}, Children: []*junit.TestSuite{
+				{Name: "retries", TestCases: []*junit.TestCase{{Name: "test c"}}},
+			}},
+		},
+	}
+	suiteID := uint(7)
+	testCases := map[string]*models.ProwJobRunTest{}
+	pl.extractTestCases(suite, &suiteID, testCases)
+	// a suite-less result of the same test is a different row
+	testCases["test c"] = &models.ProwJobRunTest{TestID: 3, Status: int(sippyprocessingv1.TestStatusFailure)}
+
+	results := dedupeTestResults(testCases)
+	require.Len(t, results, 4, "one result per test and suite, though child suites repeat tests")
+	statuses := map[uint]map[bool]int{}
+	for _, r := range results {
+		if statuses[r.TestID] == nil {
+			statuses[r.TestID] = map[bool]int{}
+		}
+		statuses[r.TestID][r.SuiteID != nil] = r.Status
+		if r.TestID == 2 {
+			assert.Equal(t, "test b failed", r.ProwJobRunTestOutput.Output)
+		}
+	}
+	assert.Equal(t, map[uint]map[bool]int{
+		1: {true: int(sippyprocessingv1.TestStatusFlake)},
+		2: {true: int(sippyprocessingv1.TestStatusFailure)},
+		3: {true: int(sippyprocessingv1.TestStatusSuccess), false: int(sippyprocessingv1.TestStatusFailure)},
+	}, statuses)
+}
+
+func TestMergeTestResultCascades(t *testing.T) {
+	failure := int(sippyprocessingv1.TestStatusFailure)
+	existing := &models.ProwJobRunTest{Status: failure, CascadeFailure: true}
+	mergeTestResult(existing, &models.ProwJobRunTest{Status: failure, CascadeFailure: true})
+	assert.True(t, existing.CascadeFailure)
+
+	mergeTestResult(existing, &models.ProwJobRunTest{Status: failure})
+	assert.False(t, existing.CascadeFailure, "a failure is primary if any execution was")
+}
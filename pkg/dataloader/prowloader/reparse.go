@@ -0,0 +1,153 @@
+package prowloader
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+	sippyprocessingv1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// JunitParserVersion is the version of the conversion from junit artifacts to test results, recorded on each
+// imported job run. Bump it when changing the conversion, i.e. flake or cascade failure detection, synthetic tests
+// or ignored tests, and run the reparse command to apply the change to runs that were already imported.
+const JunitParserVersion = 1
+
+// ReparseOptions selects the job runs to re-parse.
+type ReparseOptions struct {
+	// Start limits re-parsing to job runs that started after it, if set.
+	Start time.Time
+	// JobRegex limits re-parsing to matching job names, using postgres regular expression syntax.
+	JobRegex *regexp.Regexp
+	// Limit is the most job runs to re-parse, the most recent first, 0 for no limit.
+	Limit int
+	// Concurrency is the number of job runs to re-parse at once.
+	Concurrency int
+}
+
+// SetArtifactCacheDir caches the artifacts the loader reads in a local directory, and reads them from there when
+// present, so runs can later be re-parsed without fetching their artifacts again.
+func (pl *ProwLoader) SetArtifactCacheDir(dir string) {
+	pl.artifactCacheDir = dir
+}
+
+type reparseRun struct {
+	ID  uint
+	URL string
+}
+
+// Reparse re-imports the test results of job runs imported with an older junit parser version, from the artifact
+// cache or GCS, replacing their existing results. It returns the number of runs re-parsed; errors are available
+// from Errors.
+func (pl *ProwLoader) Reparse(opts ReparseOptions) int {
+	q := pl.dbc.DB.Table("prow_job_runs").
+		Select("prow_job_runs.id, prow_job_runs.url").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where("prow_job_runs.junit_parser_version < ?", JunitParserVersion).
+		Where("prow_job_runs.deleted_at IS NULL").
+		Order("prow_job_runs.timestamp DESC")
+	if !opts.Start.IsZero() {
+		q = q.Where("prow_job_runs.timestamp >= ?", opts.Start)
+	}
+	if opts.JobRegex != nil {
+		q = q.Where("prow_jobs.name ~ ?", opts.JobRegex.String())
+	}
+	if opts.Limit > 0 {
+		q = q.Limit(opts.Limit)
+	}
+	var runs []reparseRun
+	if res := q.Scan(&runs); res.Error != nil {
+		pl.errors = append(pl.errors, errors.Wrap(res.Error, "error listing job runs to re-parse"))
+		return 0
+	}
+	logger.Infof("re-parsing %d job runs imported with junit parser versions before %d", len(runs), JunitParserVersion)
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBackfillConcurrency
+	}
+	queue := make(chan reparseRun)
+	go func() {
+		defer close(queue)
+		for _, r := range runs {
+			select {
+			case queue <- r:
+			case <-pl.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	reparsed := 0
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range queue {
+				err := pl.reparseJobRun(pl.ctx, r)
+				lock.Lock()
+				if err != nil {
+					logger.WithError(err).Warningf("couldn't re-parse job run %d, continuing", r.ID)
+					pl.errors = append(pl.errors, errors.Wrapf(err, "error re-parsing job run %d", r.ID))
+				} else {
+					reparsed++
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	logger.Infof("re-parsed %d of %d job runs", reparsed, len(runs))
+	return reparsed
+}
+
+func (pl *ProwLoader) reparseJobRun(ctx context.Context, run reparseRun) error {
+	pjLog := logger.WithField("jobRun", run.ID)
+	path, err := GetGCSPathForProwJobURL(pjLog, run.URL)
+	if err != nil {
+		return err
+	}
+
+	// The prow job is needed for synthetic tests, and is stored alongside the run's artifacts.
+	gcsJobRun := gcs.NewGCSJobRun(pl.bkt, path)
+	gcsJobRun.SetCacheDir(pl.artifactCacheDir)
+	content, err := gcsJobRun.GetContent(ctx, path+"/prowjob.json")
+	if err != nil {
+		return errors.Wrap(err, "error reading prowjob.json")
+	}
+	pj := &prow.ProwJob{}
+	if err := json.Unmarshal(content, pj); err != nil {
+		return errors.Wrap(err, "error decoding prowjob.json")
+	}
+
+	tests, failures, overallResult, err := pl.prowJobRunTestsFromGCS(ctx, pj, run.ID, path, gcsJobRun.GetGCSJunitPaths())
+	if err != nil {
+		return err
+	}
+
+	return pl.dbc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if res := tx.Unscoped().Where("prow_job_run_id = ?", run.ID).Delete(&models.ProwJobRunTest{}); res.Error != nil {
+			return res.Error
+		}
+		if _, err := saveJobRunTests(tx, tests); err != nil {
+			return err
+		}
+		return tx.Model(&models.ProwJobRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+			"test_failures":        failures,
+			"overall_result":       overallResult,
+			"succeeded":            overallResult == sippyprocessingv1.JobSucceeded,
+			"junit_parser_version": JunitParserVersion,
+		}).Error
+	})
+}
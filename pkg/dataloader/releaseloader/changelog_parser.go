@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/anaskhan96/soup"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift/sippy/pkg/db/models"
 )
@@ -163,7 +162,7 @@ func (c *Changelog) PullRequests() []models.ReleasePullRequest {
 				name: row.Name,
 			}
 			if _, ok := rows[prl]; ok {
-				log.Warningf("duplicate PR in %q: %q, %q", c.releaseTag, row.URL, row.Name)
+				logger.Warningf("duplicate PR in %q: %q, %q", c.releaseTag, row.URL, row.Name)
 			} else {
 				rows[prl] = row
 			}
@@ -177,7 +176,7 @@ func (c *Changelog) PullRequests() []models.ReleasePullRequest {
 		// so this check is here to prevent something like that from ever happening again.  2,500 seems like a very
 		// reasonable upper bound.
 		if items > 2500 {
-			log.Warningf("%q had more than 2,500 PR's! Ignoring the rest to protect ourself.", c.releaseTag)
+			logger.Warningf("%q had more than 2,500 PR's! Ignoring the rest to protect ourself.", c.releaseTag)
 			break
 		}
 		result = append(result, v)
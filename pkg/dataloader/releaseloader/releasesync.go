@@ -12,14 +12,16 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	log "github.com/sirupsen/logrus"
 	"gorm.io/gorm/clause"
 
 	"github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
 )
 
+var logger = logging.ForSubsystem("releaseloader")
+
 const (
 	releaseTagsTable = "release_tags"
 	succeeded        = "Succeeded"
@@ -60,7 +62,7 @@ func (r *ReleaseLoader) Errors() []error {
 
 func (r *ReleaseLoader) Load() {
 	for _, release := range r.releases {
-		log.Infof("Fetching release %s from release controller...", release)
+		logger.Infof("Fetching release %s from release controller...", release)
 		allTags := r.fetchReleaseTags(release)
 
 		for _, tags := range allTags {
@@ -70,18 +72,18 @@ func (r *ReleaseLoader) Load() {
 				// expect Phase to be populated if the record is present
 				if len(mReleaseTag.Phase) > 0 {
 					if mReleaseTag.Phase != tag.Phase {
-						log.Warningf("Phase change detected (%q to %q) -- updating tag %s...", mReleaseTag.Phase, tag.Phase, tag.Name)
+						logger.Warningf("Phase change detected (%q to %q) -- updating tag %s...", mReleaseTag.Phase, tag.Phase, tag.Name)
 						mReleaseTag.Phase = tag.Phase
 						mReleaseTag.Forced = true
 						if err := r.db.DB.Clauses(clause.OnConflict{UpdateAll: true}).Table(releaseTagsTable).Save(mReleaseTag).Error; err != nil {
-							log.WithError(err).Errorf("error updating release tag")
+							logger.WithError(err).Errorf("error updating release tag")
 							r.errors = append(r.errors, errors.Wrapf(err, "error updating release tag %s for new phase: %s -> %s", tag.Name, mReleaseTag.Phase, tag.Phase))
 						}
 					}
 					continue
 				}
 
-				log.Infof("Fetching tag %s from release controller...", tag.Name)
+				logger.Infof("Fetching tag %s from release controller...", tag.Name)
 				releaseTag := r.buildReleaseTag(tags.Architecture, release, tag)
 
 				if releaseTag == nil {
@@ -154,12 +156,12 @@ func (r *ReleaseLoader) fetchReleaseTags(release string) []ReleaseTags {
 			panic(err)
 		}
 		if resp.StatusCode != http.StatusOK {
-			log.Errorf("release controller returned non-200 error code for %s: %d %s", uri, resp.StatusCode, resp.Status)
+			logger.Errorf("release controller returned non-200 error code for %s: %d %s", uri, resp.StatusCode, resp.Status)
 			continue
 		}
 
 		if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
-			log.Errorf("couldn't decode json: %v", err)
+			logger.Errorf("couldn't decode json: %v", err)
 			resp.Body.Close()
 			continue
 		}
@@ -303,7 +305,7 @@ func parseChangeLogJSON(releaseTag string, changeLogJSON ChangeLog) models.Relea
 				name: releasePRRow.Name,
 			}
 			if _, ok := releasePRRows[prl]; ok {
-				log.Warningf("duplicate PR in %q: %q, %q", releaseTag, releasePRRow.URL, releasePRRow.Name)
+				logger.Warningf("duplicate PR in %q: %q, %q", releaseTag, releasePRRow.URL, releasePRRow.Name)
 			} else {
 				releasePRRows[prl] = releasePRRow
 			}
@@ -320,7 +322,7 @@ func parseChangeLogJSON(releaseTag string, changeLogJSON ChangeLog) models.Relea
 		// so this check is here to prevent something like that from ever happening again.  2,500 seems like a very
 		// reasonable upper bound.
 		if items > 2500 {
-			log.Warningf("%q had more than 2,500 PR's! Ignoring the rest to protect ourself.", releaseTag)
+			logger.Warningf("%q had more than 2,500 PR's! Ignoring the rest to protect ourself.", releaseTag)
 			break
 		}
 		releasePullRequestResult = append(releasePullRequestResult, v)
@@ -340,7 +342,7 @@ func releaseJobRunsToDB(details ReleaseDetails) []models.ReleaseJobRun {
 		for platform, jobResult := range jobs {
 			id, err := idFromURL(jobResult.URL)
 			if id == 0 || err != nil {
-				log.WithFields(map[string]interface{}{
+				logger.WithFields(map[string]interface{}{
 					"id":         id,
 					"releaseTag": details.Name,
 					"url":        jobResult.URL,
@@ -366,7 +368,7 @@ func releaseJobRunsToDB(details ReleaseDetails) []models.ReleaseJobRun {
 		for platform, jobResult := range jobs {
 			id, err := idFromURL(jobResult.URL)
 			if id == 0 || err != nil {
-				log.WithFields(map[string]interface{}{
+				logger.WithFields(map[string]interface{}{
 					"id":         id,
 					"releaseTag": details.Name,
 					"url":        jobResult.URL,
@@ -393,7 +395,7 @@ func releaseJobRunsToDB(details ReleaseDetails) []models.ReleaseJobRun {
 		for _, run := range upgrade.History {
 			id, err := idFromURL(run.URL)
 			if id == 0 || err != nil {
-				log.WithFields(map[string]interface{}{
+				logger.WithFields(map[string]interface{}{
 					"id":         id,
 					"releaseTag": details.Name,
 					"url":        run.URL,
@@ -0,0 +1,111 @@
+package dataloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+)
+
+// LoaderSummary is a loader's own account of a run. Counts are what it processed, and Diff what it changed.
+type LoaderSummary struct {
+	Counts map[string]int `json:"counts,omitempty"`
+	Diff   map[string]int `json:"diff,omitempty"`
+}
+
+// LoaderReport is the outcome of running one loader.
+type LoaderReport struct {
+	Name            string    `json:"name"`
+	Started         time.Time `json:"started"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Errors          []string  `json:"errors"`
+	LoaderSummary
+}
+
+// RunReport is a machine-readable record of a load run, written for the CI jobs running loaders to archive, and
+// to fail on.
+type RunReport struct {
+	Started         time.Time      `json:"started"`
+	Finished        time.Time      `json:"finished"`
+	DurationSeconds float64        `json:"duration_seconds"`
+	Succeeded       bool           `json:"succeeded"`
+	ErrorCount      int            `json:"error_count"`
+	Loaders         []LoaderReport `json:"loaders"`
+}
+
+// NewLoaderReport reports on a loader that has finished running.
+func NewLoaderReport(loader DataLoader, started time.Time, duration time.Duration) LoaderReport {
+	report := LoaderReport{
+		Name:            loader.Name(),
+		Started:         started,
+		DurationSeconds: duration.Seconds(),
+		Errors:          []string{},
+	}
+	for _, err := range loader.Errors() {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	if s, ok := loader.(Summarizer); ok {
+		report.LoaderSummary = s.Summary()
+	}
+	return report
+}
+
+// NewRunReport reports on a run of the given loaders.
+func NewRunReport(started, finished time.Time, loaders []LoaderReport) RunReport {
+	report := RunReport{
+		Started:         started,
+		Finished:        finished,
+		DurationSeconds: finished.Sub(started).Seconds(),
+		Loaders:         loaders,
+	}
+	for _, l := range loaders {
+		report.ErrorCount += len(l.Errors)
+	}
+	report.Succeeded = report.ErrorCount == 0
+	return report
+}
+
+// WriteReport writes the report as JSON to path, which is either a local file or a gs://bucket/object URL. The
+// GCS client is only needed for the latter.
+func WriteReport(ctx context.Context, report RunReport, path string, gcsClient *storage.Client) error {
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "couldn't encode run report")
+	}
+
+	if !strings.HasPrefix(path, "gs://") {
+		return os.WriteFile(path, b, 0o644) //nolint:gosec
+	}
+	bucket, object, ok := ParseGCSPath(path)
+	if !ok {
+		return fmt.Errorf("invalid GCS path %s, expected gs://bucket/object", path)
+	}
+	if gcsClient == nil {
+		return fmt.Errorf("a GCS client is required to write the run report to %s", path)
+	}
+	w := gcsClient.Bucket(bucket).Object(object).NewWriter(ctx)
+	w.ContentType = "application/json"
+	if _, err := w.Write(b); err != nil {
+		_ = w.Close()
+		return errors.Wrapf(err, "couldn't write run report to %s", path)
+	}
+	return errors.Wrapf(w.Close(), "couldn't write run report to %s", path)
+}
+
+// ParseGCSPath splits a gs://bucket/object URL into its bucket and object.
+func ParseGCSPath(path string) (bucket, object string, ok bool) {
+	rest, ok := strings.CutPrefix(path, "gs://")
+	if !ok {
+		return "", "", false
+	}
+	bucket, object, ok = strings.Cut(rest, "/")
+	if !ok || bucket == "" || object == "" {
+		return "", "", false
+	}
+	return bucket, object, true
+}
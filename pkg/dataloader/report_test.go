@@ -0,0 +1,79 @@
+package dataloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLoader struct {
+	name    string
+	errors  []error
+	summary *LoaderSummary
+}
+
+func (f *fakeLoader) Name() string    { return f.name }
+func (f *fakeLoader) Load()           {}
+func (f *fakeLoader) Errors() []error { return f.errors }
+
+type fakeSummarizer struct {
+	fakeLoader
+}
+
+func (f *fakeSummarizer) Summary() LoaderSummary { return *f.summary }
+
+func TestRunReport(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	summary := LoaderSummary{
+		Counts: map[string]int{"expected_jobs": 10},
+		Diff:   map[string]int{"inserted_variants": 2},
+	}
+
+	reports := []LoaderReport{
+		NewLoaderReport(&fakeSummarizer{fakeLoader{name: "job-variants", summary: &summary}}, start, time.Minute),
+		NewLoaderReport(&fakeLoader{name: "releases", errors: []error{fmt.Errorf("release controller unavailable")}},
+			start.Add(time.Minute), 30*time.Second),
+	}
+	assert.Equal(t, summary, reports[0].LoaderSummary)
+	assert.Equal(t, []string{}, reports[0].Errors)
+	assert.Equal(t, LoaderSummary{}, reports[1].LoaderSummary, "loaders without a summary only report errors and duration")
+	assert.Equal(t, []string{"release controller unavailable"}, reports[1].Errors)
+
+	report := NewRunReport(start, start.Add(90*time.Second), reports)
+	assert.Equal(t, 90.0, report.DurationSeconds)
+	assert.Equal(t, 1, report.ErrorCount)
+	assert.False(t, report.Succeeded)
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, WriteReport(context.Background(), report, path, nil))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &written))
+	assert.Equal(t, false, written["succeeded"])
+	loaders := written["loaders"].([]interface{})
+	assert.Equal(t, map[string]interface{}{"expected_jobs": 10.0}, loaders[0].(map[string]interface{})["counts"],
+		"summary fields are inlined in the loader report")
+
+	assert.Error(t, WriteReport(context.Background(), report, "gs://bucket/report.json", nil))
+	assert.Error(t, WriteReport(context.Background(), report, "gs://bucket", nil))
+}
+
+func TestParseGCSPath(t *testing.T) {
+	bucket, object, ok := ParseGCSPath("gs://sippy-reports/load/report.json")
+	assert.True(t, ok)
+	assert.Equal(t, "sippy-reports", bucket)
+	assert.Equal(t, "load/report.json", object)
+
+	for _, path := range []string{"/tmp/report.json", "gs://sippy-reports", "gs:///report.json"} {
+		_, _, ok := ParseGCSPath(path)
+		assert.False(t, ok, path)
+	}
+}
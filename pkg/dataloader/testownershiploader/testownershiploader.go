@@ -12,8 +12,12 @@ import (
 
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
+	"github.com/openshift/sippy/pkg/testidentification"
 )
 
+var logger = logging.ForSubsystem("testownershiploader")
+
 // TestOwnershipLoader loads test ownership information from BigQuery. This data is generated and
 // pushed to BigQuery from https://github.com/openshift-eng/ci-test-mapping
 type TestOwnershipLoader struct {
@@ -59,7 +63,7 @@ func (tol *TestOwnershipLoader) Load() {
 		var test models.Test
 		res := tol.dbc.DB.Table("tests").First(&test, "name = ?", m.Name)
 		if res.Error == gorm.ErrRecordNotFound {
-			log.WithFields(log.Fields{
+			logger.WithFields(log.Fields{
 				"testname": m.Name,
 			}).Warningf("sippy doesn't know about this test")
 			unknown++
@@ -70,7 +74,7 @@ func (tol *TestOwnershipLoader) Load() {
 			return
 		}
 		if test.ID == 0 {
-			log.Warningf("test %q has id 0", m.Name)
+			logger.Warningf("test %q has id 0", m.Name)
 			continue
 		}
 
@@ -100,7 +104,7 @@ func (tol *TestOwnershipLoader) Load() {
 			if res.Error != nil {
 				msg := fmt.Sprintf("error with jira component %q", m.JIRAComponent)
 				tol.errors = append(tol.errors, errors.WithMessage(res.Error, msg))
-				log.WithError(err).Warningf(msg)
+				logger.WithError(err).Warningf(msg)
 				continue
 			}
 			id := jiraComponent.ID
@@ -117,6 +121,7 @@ func (tol *TestOwnershipLoader) Load() {
 			Component:             m.Component,
 			JiraComponent:         m.JIRAComponent,
 			Capabilities:          m.Capabilities,
+			Features:              testidentification.FeatureAnnotations(m.Name),
 			Priority:              m.Priority,
 			StaffApprovedObsolete: m.StaffApprovedObsolete,
 			TestID:                test.ID,
@@ -130,20 +135,20 @@ func (tol *TestOwnershipLoader) Load() {
 		}).Save(tom)
 		if res.Error != nil {
 			tol.errors = append(tol.errors, res.Error)
-			log.WithError(err).Warningf("error saving test ownership record for %q", m.Name)
+			logger.WithError(err).Warningf("error saving test ownership record for %q", m.Name)
 			return
 		}
 		ids = append(ids, tom.ID)
 	}
 
-	log.Infof("deleting old records...")
+	logger.Infof("deleting old records...")
 	oldRecords := tol.dbc.DB.Where("id NOT IN ?", ids).Unscoped().Delete(&models.TestOwnership{})
 	if oldRecords.Error != nil {
-		log.WithError(oldRecords.Error).Warningf("couldn't delete old records")
+		logger.WithError(oldRecords.Error).Warningf("couldn't delete old records")
 		tol.errors = append(tol.errors, oldRecords.Error)
 	}
 
-	log.WithFields(log.Fields{
+	logger.WithFields(log.Fields{
 		"known":    known,
 		"unknown":  unknown,
 		"obsolete": oldRecords.RowsAffected,
@@ -8,15 +8,22 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	bqcached "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/dataloader"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/testidentification"
 )
 
+var logger = logging.ForSubsystem("variantsyncer")
+
 type VariantSyncer struct {
 	dbc    *db.DB
 	mgr    testidentification.VariantManager
 	errors []error
+
+	jobs        int
+	updatedJobs int
 }
 
 func New(dbc *db.DB, bqc *bqcached.Client) (*VariantSyncer, error) {
@@ -39,20 +46,37 @@ func (vl *VariantSyncer) Errors() []error {
 	return vl.errors
 }
 
+// Summary returns the number of jobs checked, and the number whose variants were updated.
+func (vl *VariantSyncer) Summary() dataloader.LoaderSummary {
+	return dataloader.LoaderSummary{
+		Counts: map[string]int{"jobs": vl.jobs},
+		Diff:   map[string]int{"updated_jobs": vl.updatedJobs},
+	}
+}
+
 func (vl *VariantSyncer) Load() {
 	allJobs := loadAllProwJobs(vl.dbc)
+	vl.jobs = len(allJobs)
 	for _, j := range allJobs {
-		log.Debugf("syncing variants for %s", j.Name)
+		logger.Debugf("syncing variants for %s", j.Name)
 		newVariants := vl.mgr.IdentifyVariants(j.Name)
 		if !reflect.DeepEqual(newVariants, []string(j.Variants)) {
-			log.WithFields(log.Fields{
+			logger.WithFields(log.Fields{
 				"job":      j.Name,
 				"original": strings.Join(j.Variants, ", "),
 				"updated":  strings.Join(newVariants, ", "),
 			}).Debugf("mismatched; updating database")
+			changes := models.DiffVariants(j, j.Variants, newVariants)
 			j.Variants = newVariants
 			if res := vl.dbc.DB.WithContext(context.TODO()).Save(j); res.Error != nil {
 				vl.errors = append(vl.errors, res.Error)
+				continue
+			}
+			vl.updatedJobs++
+			if len(changes) > 0 {
+				if res := vl.dbc.DB.WithContext(context.TODO()).Create(&changes); res.Error != nil {
+					logger.WithError(res.Error).WithField("job", j.Name).Warning("error recording variant changes")
+				}
 			}
 		}
 	}
@@ -67,6 +91,6 @@ func loadAllProwJobs(dbc *db.DB) map[string]*models.ProwJob {
 			results[j.Name] = j
 		}
 	}
-	log.Infof("jobs fetched with %d entries from database", len(results))
+	logger.Infof("jobs fetched with %d entries from database", len(results))
 	return results
 }
@@ -0,0 +1,113 @@
+package watchlistloader
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/logging"
+	"github.com/openshift/sippy/pkg/notification"
+	"github.com/openshift/sippy/pkg/watchlist"
+)
+
+var logger = logging.ForSubsystem("watchlistloader")
+
+// WatchlistLoader checks every watch subscription, notifies subscribers whose pass rate crossed their threshold or
+// whose related regressions opened or closed since the last check, and records the subscriptions' new state.
+type WatchlistLoader struct {
+	ctx    context.Context
+	dbc    *db.DB
+	smtp   notification.SMTPConfig
+	errors []error
+}
+
+func New(ctx context.Context, dbc *db.DB, smtpConfig notification.SMTPConfig) *WatchlistLoader {
+	return &WatchlistLoader{
+		ctx:  ctx,
+		dbc:  dbc,
+		smtp: smtpConfig,
+	}
+}
+
+func (wl *WatchlistLoader) Name() string {
+	return "watchlist"
+}
+
+func (wl *WatchlistLoader) Errors() []error {
+	return wl.errors
+}
+
+func (wl *WatchlistLoader) Load() {
+	var subs []models.WatchSubscription
+	if res := wl.dbc.DB.Order("id").Find(&subs); res.Error != nil {
+		wl.errors = append(wl.errors, errors.Wrap(res.Error, "error listing watch subscriptions"))
+		return
+	}
+
+	now := time.Now().UTC()
+	regressions := map[string][]models.TestRegression{}
+	sent := 0
+	for i := range subs {
+		sub := &subs[i]
+		// Subscriptions checked for the first time only look back a day for regressions.
+		since := now.Add(-24 * time.Hour)
+		if sub.LastCheckedAt != nil {
+			since = *sub.LastCheckedAt
+		}
+
+		var events []watchlist.Event
+		if sub.Threshold > 0 {
+			runs, err := query.WatchedPassRate(wl.dbc, sub, now.Add(-7*24*time.Hour))
+			if err != nil {
+				wl.errors = append(wl.errors, errors.Wrapf(err, "error querying pass rate for subscription %d", sub.ID))
+				continue
+			}
+			event, state := watchlist.PassRateEvent(sub, runs.Runs, runs.Successes)
+			if event != nil {
+				events = append(events, *event)
+			}
+			sub.LastState = state
+			if runs.Runs > 0 {
+				percentage := float64(runs.Successes) * 100.0 / float64(runs.Runs)
+				sub.LastPassPercentage = &percentage
+			}
+		}
+
+		if sub.NotifyRegressions {
+			if _, ok := regressions[sub.Release]; !ok {
+				rs, err := query.TestRegressions(wl.dbc, sub.Release, "", now.Add(-7*24*time.Hour))
+				if err != nil {
+					wl.errors = append(wl.errors, errors.Wrapf(err, "error listing regressions for %s", sub.Release))
+					continue
+				}
+				regressions[sub.Release] = rs
+			}
+			events = append(events, watchlist.RegressionEvents(sub, regressions[sub.Release], since)...)
+		}
+
+		if len(events) > 0 {
+			n := watchlist.NewNotification(sub, events)
+			if err := watchlist.Send(wl.ctx, wl.smtp, sub, n); err != nil {
+				// Leave the subscription unchanged so the notification is retried on the next run.
+				wl.errors = append(wl.errors, err)
+				continue
+			}
+			sent++
+		}
+
+		sub.LastCheckedAt = &now
+		if res := wl.dbc.DB.Model(sub).Select("last_state", "last_pass_percentage", "last_checked_at").Updates(sub); res.Error != nil {
+			wl.errors = append(wl.errors, errors.Wrapf(res.Error, "error saving subscription %d", sub.ID))
+		}
+	}
+
+	logger.WithFields(log.Fields{
+		"subscriptions": len(subs),
+		"notified":      sent,
+	}).Info("checked watchlist subscriptions")
+}
@@ -20,6 +20,7 @@ const (
 	hashTypeMatView      SchemaHashType = "matview"
 	hashTypeMatViewIndex SchemaHashType = "matview_index"
 	hashTypeFunction     SchemaHashType = "function"
+	hashTypeIndex        SchemaHashType = "index"
 )
 
 type DB struct {
@@ -28,6 +29,8 @@ type DB struct {
 	// BatchSize is used for how many insertions we should do at once. Postgres supports
 	// a maximum of 2^16 records per insert.
 	BatchSize int
+
+	replicas *replicaSet
 }
 
 // log2LogrusWriter bridges gorm logging to logrus logging.
@@ -40,8 +43,8 @@ func (w log2LogrusWriter) Printf(msg string, args ...interface{}) {
 	w.entry.Debugf(msg, args...)
 }
 
-func New(dsn string, logLevel gormlogger.LogLevel) (*DB, error) {
-	gormLogger := gormlogger.New(
+func newGormLogger(logLevel gormlogger.LogLevel) gormlogger.Interface {
+	return gormlogger.New(
 		log2LogrusWriter{entry: log.WithField("source", "gorm")},
 		gormlogger.Config{
 			SlowThreshold:             2 * time.Second,
@@ -50,9 +53,11 @@ func New(dsn string, logLevel gormlogger.LogLevel) (*DB, error) {
 			Colorful:                  false,
 		},
 	)
+}
 
+func New(dsn string, logLevel gormlogger.LogLevel) (*DB, error) {
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: gormLogger,
+		Logger: newGormLogger(logLevel),
 	})
 	if err != nil {
 		return nil, err
@@ -98,17 +103,40 @@ func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 	}
 
 	if err := d.DB.AutoMigrate(&models.ProwJobRunTest{}); err != nil {
-		return err
+		return naturalKeyMigrationError(err)
+	}
+	if err := syncNaturalKeyIndexes(d.DB); err != nil {
+		return naturalKeyMigrationError(err)
+	}
+
+	if err := d.DB.AutoMigrate(&models.ProwJobRunOperatorCondition{}); err != nil {
+		return naturalKeyMigrationError(err)
+	}
+
+	if err := d.DB.AutoMigrate(&models.ProwJobRunAlert{}); err != nil {
+		return naturalKeyMigrationError(err)
+	}
+
+	if err := d.DB.AutoMigrate(&models.ProwJobRunEventPattern{}); err != nil {
+		return naturalKeyMigrationError(err)
+	}
+
+	if err := d.DB.AutoMigrate(&models.ProwJobRunFingerprint{}); err != nil {
+		return naturalKeyMigrationError(err)
 	}
 
 	if err := d.DB.AutoMigrate(&models.ProwJobRunTestOutput{}); err != nil {
-		return err
+		return naturalKeyMigrationError(err)
 	}
 
 	if err := d.DB.AutoMigrate(&models.ProwJobRunTestOutputMetadata{}); err != nil {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.ManualJobRun{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.APISnapshot{}); err != nil {
 		return err
 	}
@@ -133,14 +161,98 @@ func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.Incident{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.JiraComponent{}); err != nil {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.ComponentBugBacklog{}); err != nil {
+		return err
+	}
+
 	if err := d.DB.AutoMigrate(&models.TestOwnership{}); err != nil {
 		return err
 	}
 
+	if err := d.DB.AutoMigrate(&models.TestRegression{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.BasisPin{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.ImportProgress{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.JobRunImportFailure{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.VariantChange{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.JobOwnerChange{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.VariantRulesRevision{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.WatchSubscription{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.Dashboard{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.Lane{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.ReportJob{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.FeatureFlagOverride{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.ShadowEvaluation{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.ShadowVerdict{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.ReleaseMilestone{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.MilestoneSnapshot{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.MilestonePassRate{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.AccessLog{}); err != nil {
+		return err
+	}
+
+	if err := d.DB.AutoMigrate(&models.AuditEvent{}); err != nil {
+		return err
+	}
+
 	if err := populateTestSuitesInDB(d.DB); err != nil {
 		return err
 	}
@@ -152,6 +264,12 @@ func (d *DB) UpdateSchema(reportEnd *time.Time) error {
 	return syncPostgresFunctions(d.DB)
 }
 
+// naturalKeyMigrationError annotates migration errors for tables with natural key unique indexes, which can't be
+// created while older duplicate rows remain.
+func naturalKeyMigrationError(err error) error {
+	return fmt.Errorf("%w (if a unique index could not be created, run 'sippy integrity-check --fix' to remove duplicate rows)", err)
+}
+
 // syncSchema will update generic db resources if their schema has changed. (functions, materialized views, indexes)
 // This is useful for resources that cannot be updated incrementally with goose, and can cause conflict / last write
 // wins problems with concurrent development.
@@ -47,12 +47,12 @@ WITH results AS (
     coalesce(count(case when status = 13 AND timestamp BETWEEN $2 AND $3 then 1 end), 0) AS current_flakes,
     coalesce(count(case when status = 12 AND timestamp BETWEEN $2 AND $3 then 1 end), 0) AS current_failures,
     coalesce(count(case when timestamp BETWEEN $2 AND $3 then 1 end), 0) as current_runs,
-    prow_jobs.release
+    ` + RunRelease + ` AS release
 FROM prow_job_run_tests
     JOIN tests ON tests.id = prow_job_run_tests.test_id
     JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
     JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id
-GROUP BY tests.id, prow_jobs.release
+GROUP BY tests.id, ` + RunRelease + `
 )
 SELECT tests.id,
        tests.name,
@@ -113,8 +113,9 @@ results AS (
         FROM prow_job_runs
         JOIN prow_jobs
                 ON prow_jobs.id = prow_job_runs.prow_job_id
-                                AND prow_jobs.release = $1
+                                AND ` + RunRelease + ` = $1
                 AND timestamp BETWEEN $2 AND $4
+                AND ` + notRehearsal + `
    		LEFT JOIN bug_jobs on prow_jobs.id = bug_jobs.prow_job_id
         LEFT JOIN bugs on bugs.id = bug_jobs.bug_id AND lower(bugs.status) NOT IN ('verified', 'modified', 'closed', 'on_qa')
         group by prow_jobs.name, prow_jobs.variants
@@ -140,7 +141,7 @@ SELECT pj_name,
        prow_jobs.updated_at,
        prow_jobs.deleted_at,
        name,
-       release,
+       $1 AS release,
        variants,
        test_grid_url,
        kind,
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NaturalKey is the set of columns that identify a row in an ingested table, independent of its surrogate ID.
+// Loaders upsert on the natural key so re-running them is idempotent.
+type NaturalKey struct {
+	Table   string
+	Columns []string
+	// Nullable are the integer columns of the key that may be NULL. Postgres considers NULLs distinct, so they are
+	// keyed as COALESCE(column, 0) for rows missing them to still conflict. Keys with nullable columns can't be
+	// declared with gorm tags, and their unique index is created with Index instead.
+	Nullable []string
+	// Index names the key's unique index, when created with Index.
+	Index string
+}
+
+var (
+	ProwJobRunTestKey              = NaturalKey{Table: "prow_job_run_tests", Columns: []string{"prow_job_run_id", "test_id", "suite_id"}, Nullable: []string{"suite_id"}, Index: "idx_prow_job_run_tests_natural_key"}
+	ProwJobRunTestOutputKey        = NaturalKey{Table: "prow_job_run_test_outputs", Columns: []string{"prow_job_run_test_id"}}
+	ProwJobRunOperatorConditionKey = NaturalKey{Table: "prow_job_run_operator_conditions", Columns: []string{"prow_job_run_id", "operator", "condition", "from"}}
+	ProwJobRunAlertKey             = NaturalKey{Table: "prow_job_run_alerts", Columns: []string{"prow_job_run_id", "name", "namespace", "from"}}
+	ProwJobRunEventPatternKey      = NaturalKey{Table: "prow_job_run_event_patterns", Columns: []string{"prow_job_run_id", "pattern", "namespace"}}
+	ProwJobRunFingerprintKey       = NaturalKey{Table: "prow_job_run_fingerprints", Columns: []string{"prow_job_run_id", "attribute"}}
+)
+
+// NaturalKeys are the tables checked for duplicates by the integrity check. Tables keyed on a single unique
+// column, such as prow_jobs.name, can't hold duplicates and aren't listed.
+var NaturalKeys = []NaturalKey{
+	ProwJobRunTestKey,
+	ProwJobRunTestOutputKey,
+	ProwJobRunOperatorConditionKey,
+	ProwJobRunAlertKey,
+	ProwJobRunEventPatternKey,
+	ProwJobRunFingerprintKey,
+}
+
+// OnConflict returns an upsert clause on the natural key, updating the given columns of the existing row.
+func (k NaturalKey) OnConflict(updates ...string) clause.OnConflict {
+	columns := make([]clause.Column, 0, len(k.Columns))
+	for _, c := range k.Columns {
+		if k.nullable(c) {
+			// Postgres only infers expression indexes from a parenthesized expression.
+			columns = append(columns, clause.Column{Name: "(" + k.term(c) + ")", Raw: true})
+		} else {
+			columns = append(columns, clause.Column{Name: c})
+		}
+	}
+	return clause.OnConflict{
+		Columns:   columns,
+		DoUpdates: clause.AssignmentColumns(append([]string{"updated_at", "deleted_at"}, updates...)),
+	}
+}
+
+// IndexSQL returns the statement creating the key's unique index, for keys with nullable columns.
+func (k NaturalKey) IndexSQL() string {
+	terms := make([]string, 0, len(k.Columns))
+	for _, c := range k.Columns {
+		if k.nullable(c) {
+			terms = append(terms, "("+k.term(c)+")")
+		} else {
+			terms = append(terms, k.term(c))
+		}
+	}
+	return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", k.Index, k.Table, strings.Join(terms, ", "))
+}
+
+func (k NaturalKey) nullable(column string) bool {
+	for _, c := range k.Nullable {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// term returns how a column of the key is compared.
+func (k NaturalKey) term(column string) string {
+	if k.nullable(column) {
+		return fmt.Sprintf("COALESCE(%q, 0)", column)
+	}
+	return fmt.Sprintf("%q", column)
+}
+
+// syncNaturalKeyIndexes creates the unique indexes of keys with nullable columns, replacing any earlier index of
+// the same name.
+func syncNaturalKeyIndexes(db *gorm.DB) error {
+	for _, k := range NaturalKeys {
+		if k.Index == "" {
+			continue
+		}
+		dropSQL := fmt.Sprintf("DROP INDEX IF EXISTS %s", k.Index)
+		if _, err := syncSchema(db, hashTypeIndex, k.Index, k.IndexSQL(), dropSQL, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k NaturalKey) quotedColumns() string {
+	quoted := make([]string, 0, len(k.Columns))
+	for _, c := range k.Columns {
+		quoted = append(quoted, k.term(c))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func (k NaturalKey) countDuplicatesSQL() string {
+	return fmt.Sprintf(`SELECT COUNT(*) AS keys, COALESCE(SUM(rows - 1), 0) AS extra_rows
+FROM (SELECT COUNT(*) AS rows FROM %s GROUP BY %s HAVING COUNT(*) > 1) dupes`, k.Table, k.quotedColumns())
+}
+
+// removeDuplicatesSQL keeps the most recently inserted row for each key, as that's the one a re-import wrote.
+func (k NaturalKey) removeDuplicatesSQL() string {
+	return fmt.Sprintf(`DELETE FROM %s WHERE id IN (
+	SELECT id FROM (
+		SELECT id, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY id DESC) AS rn FROM %s
+	) ranked WHERE rn > 1
+)`, k.Table, k.quotedColumns(), k.Table)
+}
+
+// DuplicateReport summarizes rows sharing a natural key in a table.
+type DuplicateReport struct {
+	Table string
+	// Keys is the number of natural keys with more than one row.
+	Keys int64
+	// ExtraRows is the number of rows that would be removed to leave one row per key.
+	ExtraRows int64
+}
+
+// FindDuplicates reports tables with multiple rows for the same natural key. Duplicates prevent the unique
+// indexes on those keys from being created, and can be removed with RemoveDuplicates.
+func (d *DB) FindDuplicates() ([]DuplicateReport, error) {
+	var reports []DuplicateReport
+	for _, k := range NaturalKeys {
+		now := time.Now()
+		report := DuplicateReport{Table: k.Table}
+		row := d.DB.Raw(k.countDuplicatesSQL()).Row()
+		if err := row.Scan(&report.Keys, &report.ExtraRows); err != nil {
+			return nil, err
+		}
+		log.WithFields(log.Fields{
+			"table":     k.Table,
+			"keys":      report.Keys,
+			"extraRows": report.ExtraRows,
+			"elapsed":   time.Since(now),
+		}).Info("checked for duplicates")
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// RemoveDuplicates hard deletes all but the most recent row for each natural key, returning the number of rows
+// removed from each table.
+func (d *DB) RemoveDuplicates() ([]DuplicateReport, error) {
+	var reports []DuplicateReport
+	for _, k := range NaturalKeys {
+		now := time.Now()
+		res := d.DB.Exec(k.removeDuplicatesSQL())
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		log.WithFields(log.Fields{
+			"table":   k.Table,
+			"removed": res.RowsAffected,
+			"elapsed": time.Since(now),
+		}).Info("removed duplicates")
+		reports = append(reports, DuplicateReport{Table: k.Table, ExtraRows: res.RowsAffected})
+	}
+	return reports, nil
+}
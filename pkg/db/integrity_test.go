@@ -0,0 +1,32 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm/clause"
+)
+
+func TestNaturalKeyOnConflict(t *testing.T) {
+	c := ProwJobRunTestKey.OnConflict("status")
+	assert.Equal(t, []clause.Column{{Name: "prow_job_run_id"}, {Name: "test_id"},
+		{Name: `(COALESCE("suite_id", 0))`, Raw: true}}, c.Columns, "the conflict target matches the expression index")
+
+	var updated []string
+	for _, a := range c.DoUpdates {
+		updated = append(updated, a.Column.Name)
+	}
+	assert.Equal(t, []string{"updated_at", "deleted_at", "status"}, updated)
+}
+
+func TestNaturalKeyRemoveDuplicatesSQL(t *testing.T) {
+	sql := ProwJobRunAlertKey.removeDuplicatesSQL()
+	assert.Contains(t, sql, "DELETE FROM prow_job_run_alerts WHERE id IN")
+	assert.Contains(t, sql, `PARTITION BY "prow_job_run_id", "name", "namespace", "from" ORDER BY id DESC`)
+}
+
+func TestNaturalKeyNullableColumns(t *testing.T) {
+	assert.Equal(t, `CREATE UNIQUE INDEX idx_prow_job_run_tests_natural_key ON prow_job_run_tests `+
+		`("prow_job_run_id", "test_id", (COALESCE("suite_id", 0)))`, ProwJobRunTestKey.IndexSQL())
+	assert.Contains(t, ProwJobRunTestKey.countDuplicatesSQL(), `GROUP BY "prow_job_run_id", "test_id", COALESCE("suite_id", 0)`)
+}
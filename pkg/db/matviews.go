@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/db/models"
 )
 
 const replaceTimeNow = "|||TIMENOW|||"
@@ -163,7 +165,7 @@ pull_requests AS (
         GROUP BY prow_job_runs.id, prow_pull_requests.link, prow_pull_requests.sha, prow_pull_requests.org, prow_pull_requests.repo, prow_pull_requests.author
 )
 SELECT prow_job_runs.id,
-   prow_jobs.release,
+   ` + RunRelease + ` AS release,
    prow_jobs.name,
    prow_jobs.name AS job,
    prow_jobs.variants,
@@ -185,13 +187,26 @@ SELECT prow_job_runs.id,
    pull_requests.sha as pull_request_sha,
    pull_requests.org as pull_request_org,
    pull_requests.repo as pull_request_repo,
-   pull_requests.author as pull_request_author
+   pull_requests.author as pull_request_author,
+   CASE WHEN prow_jobs.kind = 'manual' THEN 'manual' ELSE 'automated' END AS provenance,
+   manual_job_runs.tester,
+   manual_job_runs.build
 FROM prow_job_runs
    LEFT JOIN failed_test_results ON failed_test_results.prow_job_run_id = prow_job_runs.id
    LEFT JOIN flaked_test_results ON flaked_test_results.prow_job_run_id = prow_job_runs.id
    LEFT JOIN pull_requests ON pull_requests.id = prow_job_runs.id
+   LEFT JOIN manual_job_runs ON manual_job_runs.prow_job_run_id = prow_job_runs.id AND manual_job_runs.deleted_at IS NULL
    JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id
 `
+
+// notRehearsal excludes the runs of rehearse-* jobs, whose results reflect untested CI configuration changes, from
+// test and job reports.
+const notRehearsal = "NOT ('" + models.RehearsalVariant + "' = ANY(prow_jobs.variants))"
+
+// RunRelease is the release a job run tested: the run's own, recorded when it differs from its job's, or its job's.
+// Queries over runs joined to prow_jobs select and filter by it rather than by prow_jobs.release.
+const RunRelease = "COALESCE(NULLIF(prow_job_runs.release, ''), prow_jobs.release)"
+
 const testReportMatView = `
 WITH open_bugs AS (
   SELECT
@@ -216,14 +231,16 @@ SELECT
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1 AND prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY|||) AS previous_successes,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13 AND prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY|||) AS previous_flakes,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12 AND prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY|||) AS previous_failures,
+    COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12 AND prow_job_run_tests.payload_flake AND prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY|||) AS previous_payload_flakes,
     COUNT(*) FILTER (WHERE prow_job_runs."timestamp" BETWEEN |||START||| AND |||BOUNDARY|||) AS previous_runs,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1 AND prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END|||) AS current_successes,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13 AND prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END|||) AS current_flakes,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12 AND prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END|||) AS current_failures,
+    COUNT(*) FILTER (WHERE prow_job_run_tests.status = 12 AND prow_job_run_tests.payload_flake AND prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END|||) AS current_payload_flakes,
     COUNT(*) FILTER (WHERE prow_job_runs."timestamp" BETWEEN |||BOUNDARY||| AND |||END|||) AS current_runs,
     open_bugs.open_bugs AS open_bugs,
     prow_jobs.variants,
-    prow_jobs.release
+    ` + RunRelease + ` AS release
 FROM
     prow_job_run_tests
     JOIN tests ON tests.id = prow_job_run_tests.test_id
@@ -235,8 +252,9 @@ FROM
     JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id
 WHERE
     prow_job_run_tests.created_at >= |||START||| AND prow_job_runs.timestamp >= |||START|||
+    AND ` + notRehearsal + `
 GROUP BY
-    tests.id, tests.name, jira_components.name, jira_components.id, suites.name, open_bugs.open_bugs, prow_jobs.variants, prow_jobs.release
+    tests.id, tests.name, jira_components.name, jira_components.id, suites.name, open_bugs.open_bugs, prow_jobs.variants, ` + RunRelease + `
 `
 
 const testAnalysisByVariantMatView = `
@@ -246,7 +264,7 @@ SELECT
     tests.watchlist,
     date(prow_job_runs."timestamp") AS date,
     unnest(prow_jobs.variants) AS variant,
-    prow_jobs.release,
+    ` + RunRelease + ` AS release,
     COUNT(*) FILTER (WHERE prow_job_runs."timestamp" >= (|||TIMENOW||| - '14 days'::interval) AND prow_job_runs."timestamp" <= |||TIMENOW|||) AS runs,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1 AND prow_job_runs."timestamp" >= (|||TIMENOW||| - '14 days'::interval) AND prow_job_runs."timestamp" <= |||TIMENOW|||) AS passes,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13 AND prow_job_runs."timestamp" >= (|||TIMENOW||| - '14 days'::interval) AND prow_job_runs."timestamp" <= |||TIMENOW|||) AS flakes,
@@ -258,8 +276,9 @@ FROM
     JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
 WHERE
     prow_job_run_tests.created_at > (|||TIMENOW||| - '14 days'::interval) AND prow_job_runs."timestamp" > (|||TIMENOW||| - '14 days'::interval)
+    AND ` + notRehearsal + `
 GROUP BY
-    tests.name, tests.id, date(prow_job_runs."timestamp"), unnest(prow_jobs.variants), prow_jobs.release
+    tests.name, tests.id, date(prow_job_runs."timestamp"), unnest(prow_jobs.variants), ` + RunRelease + `
 `
 
 const testAnalysisByJobMatView = `
@@ -268,7 +287,7 @@ SELECT
     tests.name AS test_name,
     tests.watchlist,
     date(prow_job_runs."timestamp") AS date,
-    prow_jobs.release,
+    ` + RunRelease + ` AS release,
     prow_jobs.name AS job_name,
     COUNT(*) FILTER (WHERE prow_job_runs."timestamp" >= (|||TIMENOW||| - '14 days'::interval) AND prow_job_runs."timestamp" <= |||TIMENOW|||) AS runs,
     COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1 AND prow_job_runs."timestamp" >= (|||TIMENOW||| - '14 days'::interval) AND prow_job_runs."timestamp" <= |||TIMENOW|||) AS passes,
@@ -281,8 +300,9 @@ FROM
     JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
 WHERE
     prow_job_run_tests.created_at > (|||TIMENOW||| - '14 days'::interval) AND prow_job_runs."timestamp" > (|||TIMENOW||| - '14 days'::interval)
+    AND ` + notRehearsal + `
 GROUP BY
-    tests.name, tests.id, date(prow_job_runs."timestamp"), prow_jobs.release, prow_jobs.name
+    tests.name, tests.id, date(prow_job_runs."timestamp"), ` + RunRelease + `, prow_jobs.name
 `
 
 const prowJobFailedTestsMatView = `
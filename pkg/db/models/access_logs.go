@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	CacheStatusHit  = "hit"
+	CacheStatusMiss = "miss"
+	CacheStatusNone = "none"
+)
+
+// AccessLog records a request to an API endpoint, to understand how the API is used before changing or removing
+// endpoints. Parameter values that could identify a user or what they were looking at are not recorded.
+type AccessLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Timestamp time.Time `json:"timestamp" gorm:"not null;index"`
+
+	Endpoint string `json:"endpoint" gorm:"not null;index"`
+	Method   string `json:"method"`
+
+	// Params is the normalized query string, see sippyserver.NormalizeParams.
+	Params string `json:"params"`
+
+	Status        int     `json:"status"`
+	LatencyMillis float64 `json:"latency_millis"`
+
+	// CacheStatus is hit or miss for cached endpoints, or none.
+	CacheStatus string `json:"cache_status"`
+
+	// Variants are the variants the request filtered on, in the form Name:value, and the names of those it grouped or
+	// compared by, see sippyserver.RequestVariants.
+	Variants pq.StringArray `json:"variants" gorm:"type:text[]"`
+}
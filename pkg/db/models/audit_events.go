@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AuditEvent records a change a person made through the API, i.e. opening an incident or pinning a basis, as
+// evidence for compliance audits of CI exceptions. Events are append only, and each is chained to the one before it
+// by hash, so an event being altered or removed can be detected, see the auditlog package.
+type AuditEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Timestamp time.Time `json:"timestamp" gorm:"not null;index"`
+
+	// Actor is who made the change, as identified by the authenticating proxy in front of sippy.
+	Actor string `json:"actor" gorm:"index"`
+	// Category is the kind of record changed, i.e. incident or basis_pin.
+	Category string `json:"category" gorm:"index"`
+
+	Method   string `json:"method"`
+	Endpoint string `json:"endpoint"`
+	// Params is the request's query string, and Body its body, recorded as sent.
+	Params string `json:"params"`
+	Body   string `json:"body"`
+	Status int    `json:"status"`
+
+	// PrevHash is the hash of the event before this one, empty for the first event, and Hash the hash of this event
+	// including PrevHash.
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash" gorm:"not null;uniqueIndex"`
+}
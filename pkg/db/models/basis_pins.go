@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// BasisPin overrides the basis a component readiness report compares a test, or every test in a component,
+// against. Pins are used when the default basis window includes a known bad period that would hide regressions.
+type BasisPin struct {
+	Model
+
+	// Release is the sample release whose reports use the pin.
+	Release string `json:"release" gorm:"not null;index"`
+
+	// Either TestID or Component is set. A test's pin takes precedence over its component's.
+	TestID    string `json:"test_id" gorm:"index"`
+	Component string `json:"component" gorm:"index"`
+
+	// BaseRelease, BaseStart and BaseEnd are the basis used instead of the report's.
+	BaseRelease string    `json:"base_release" gorm:"not null"`
+	BaseStart   time.Time `json:"base_start" gorm:"not null"`
+	BaseEnd     time.Time `json:"base_end" gorm:"not null"`
+
+	// Reason explains why the default basis can't be used, and is included in reports using the pin.
+	Reason string `json:"reason"`
+
+	// DeletedBy is who deleted the pin, so it can be found and restored.
+	DeletedBy string `json:"deleted_by"`
+}
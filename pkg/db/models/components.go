@@ -1,6 +1,10 @@
 package models
 
-import "github.com/lib/pq"
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type JiraComponent struct {
 	Model
@@ -18,6 +22,24 @@ type JiraComponent struct {
 	LeadEmail string
 }
 
+// ComponentBugBacklog summarizes the open bugs of a JIRA component, refreshed by the jira loader. Ages are as of
+// UpdatedAt.
+type ComponentBugBacklog struct {
+	Model
+
+	Component string `gorm:"uniqueIndex"`
+
+	OpenBugs     int
+	CriticalBugs int
+	// Over30Days, Over90Days and Over365Days count open bugs created more than that long ago.
+	Over30Days  int
+	Over90Days  int
+	Over365Days int
+
+	MedianAgeDays float64
+	OldestCreated time.Time
+}
+
 type TestOwnership struct {
 	Model
 
@@ -71,6 +93,10 @@ type TestOwnership struct {
 	// capabilities. For example, a networking test could belong to OVN, IPv6, and EndpointSlices capabilities.
 	Capabilities pq.StringArray `json:"capabilities" gorm:"type:text[]"`
 
+	// Features are the feature annotations in the test's name, i.e. Feature:NetworkPolicy or
+	// OCPFeatureGate:AdminNetworkPolicy, which are reported alongside capabilities.
+	Features pq.StringArray `json:"features" gorm:"type:text[]"`
+
 	// JiraComponent specifies the JIRA component that this test belongs to.
 	JiraComponent string `bigquery:"jira_component"`
 
@@ -0,0 +1,14 @@
+package models
+
+// Dashboard is a dashboard definition stored through the API, as opposed to one defined in the repo.
+type Dashboard struct {
+	Model
+
+	Name string `json:"name" gorm:"not null;uniqueIndex"`
+
+	// Definition is the YAML dashboard definition.
+	Definition string `json:"definition" gorm:"not null"`
+
+	// Author is who last saved the dashboard.
+	Author string `json:"author"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// FeatureFlagOverride is a runtime override of a feature flag declared in the server's feature flag config.
+type FeatureFlagOverride struct {
+	Model
+
+	Name string `json:"name" gorm:"not null;uniqueIndex"`
+
+	Enabled bool `json:"enabled"`
+
+	// Percentage is the percentage of requests the flag applies to when enabled.
+	Percentage int `json:"percentage"`
+
+	// Author is who last set the override.
+	Author string `json:"author"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// ImportProgress records the progress of a data load through each of its phases. It is updated periodically while
+// the load runs, so operators can tell a slow import, which is still making progress, from a hung one.
+type ImportProgress struct {
+	Model
+
+	Loader string `json:"loader" gorm:"index"`
+
+	// JobRunsDiscovered is the number of job runs listed from prow or BigQuery.
+	JobRunsDiscovered int `json:"job_runs_discovered"`
+	// JobRunsFetched is the number of discovered job runs that have been looked up, either fetched from GCS
+	// or skipped as already imported.
+	JobRunsFetched int `json:"job_runs_fetched"`
+	// JobRunsToImport is the number of fetched job runs that were not already imported.
+	JobRunsToImport int `json:"job_runs_to_import"`
+	// JunitParsed is the number of job runs to import whose junit has been parsed.
+	JunitParsed int `json:"junit_parsed"`
+	// JobRunsWritten is the number of job runs to import that have been written to the database, and RowsWritten
+	// the number of rows written for them.
+	JobRunsWritten int `json:"job_runs_written"`
+	RowsWritten    int `json:"rows_written"`
+	Errors         int `json:"errors"`
+
+	// LastProgressAt is the last time any counter changed.
+	LastProgressAt time.Time  `json:"last_progress_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+// MaxJobRunImportAttempts is the number of times importing a job run can fail before it's dead-lettered.
+const MaxJobRunImportAttempts = 3
+
+// JobRunImportFailure records a job run that failed to import, i.e. due to corrupt junit or missing artifacts.
+// Once it has failed MaxJobRunImportAttempts times it's dead-lettered, and skipped by subsequent imports until
+// it's retried through the API, which deletes the record.
+type JobRunImportFailure struct {
+	Model
+
+	ProwJobRunID  uint      `json:"prow_job_run_id" gorm:"uniqueIndex"`
+	ProwJobName   string    `json:"prow_job_name" gorm:"index"`
+	URL           string    `json:"url"`
+	Attempts      int       `json:"attempts"`
+	Error         string    `json:"error"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+}
+
+// DeadLettered returns true if the job run has failed too many times to be retried automatically.
+func (f JobRunImportFailure) DeadLettered() bool {
+	return f.Attempts >= MaxJobRunImportAttempts
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	IncidentKindInfrastructure = "infrastructure"
+	IncidentKindPayload        = "payload"
+	IncidentKindOther          = "other"
+)
+
+// Incident is a known period of disruption, such as an infrastructure outage or a bad payload, that explains
+// failures in an affected set of jobs. Job runs within an incident window can be excluded from reports so known
+// problems don't get re-investigated.
+type Incident struct {
+	Model
+
+	// Title is a short summary of the incident.
+	Title string `json:"title"`
+
+	// Description contains any additional details, such as links to related bugs or slack threads.
+	Description string `json:"description"`
+
+	// Kind is the type of incident, i.e. infrastructure or payload.
+	Kind string `json:"kind" gorm:"index"`
+
+	// StartTime is when the incident began affecting jobs.
+	StartTime time.Time `json:"start_time" gorm:"not null;index"`
+
+	// EndTime is when the incident was resolved, nil if ongoing.
+	EndTime *time.Time `json:"end_time" gorm:"index"`
+
+	// Release optionally limits the incident to a single release.
+	Release string `json:"release" gorm:"index"`
+
+	// Variants selects the affected jobs, a job is affected if it has all of these variants. An empty
+	// list affects all jobs.
+	Variants pq.StringArray `json:"variants" gorm:"type:text[]"`
+
+	// Provisional incidents were opened automatically and are awaiting confirmation by a human. They are not
+	// used to exclude job runs from reports until confirmed.
+	Provisional bool `json:"provisional" gorm:"index"`
+
+	// Signature is the failure signature that caused an automatically opened incident, i.e. a test name.
+	Signature string `json:"signature"`
+
+	// JiraKey is the JIRA issue opened for an automatically opened incident by the jira-sync loader, if any.
+	JiraKey string `json:"jira_key" gorm:"index"`
+
+	// TriageState follows the status of the JIRA issue, see the TriageState constants.
+	TriageState string `json:"triage_state"`
+
+	// DeletedBy is who deleted the incident, so it can be found and restored.
+	DeletedBy string `json:"deleted_by"`
+}
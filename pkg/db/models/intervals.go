@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProwJobRunOperatorCondition records a period during a job run where a cluster operator was Degraded or
+// Unavailable, as observed by the e2e monitor and recorded in the job run's interval files.
+type ProwJobRunOperatorCondition struct {
+	gorm.Model
+	ProwJobRunID uint `gorm:"index;uniqueIndex:idx_prow_job_run_operator_conditions_natural_key,priority:1"`
+	ProwJobRun   ProwJobRun
+	// Operator is the name of the cluster operator, i.e. kube-apiserver.
+	Operator string `gorm:"index;uniqueIndex:idx_prow_job_run_operator_conditions_natural_key,priority:2"`
+	// Condition is either Degraded or Unavailable.
+	Condition string `gorm:"index;uniqueIndex:idx_prow_job_run_operator_conditions_natural_key,priority:3"`
+	Reason    string
+	From      time.Time `gorm:"uniqueIndex:idx_prow_job_run_operator_conditions_natural_key,priority:4"`
+	To        time.Time
+}
+
+// ProwJobRunAlert records a period during a job run where an alert was firing, as observed by the e2e monitor
+// and recorded in the job run's interval files.
+type ProwJobRunAlert struct {
+	gorm.Model
+	ProwJobRunID uint `gorm:"index;uniqueIndex:idx_prow_job_run_alerts_natural_key,priority:1"`
+	ProwJobRun   ProwJobRun
+	// Name is the alert name, i.e. KubePodNotReady.
+	Name      string `gorm:"index;uniqueIndex:idx_prow_job_run_alerts_natural_key,priority:2"`
+	Namespace string `gorm:"uniqueIndex:idx_prow_job_run_alerts_natural_key,priority:3"`
+	Severity  string
+	From      time.Time `gorm:"uniqueIndex:idx_prow_job_run_alerts_natural_key,priority:4"`
+	To        time.Time
+}
+
+// ProwJobRunEventPattern counts occurrences of an abnormal event pattern, such as crashlooping or OOMKilled
+// containers, in a namespace during a job run.
+type ProwJobRunEventPattern struct {
+	gorm.Model
+	ProwJobRunID uint `gorm:"index;uniqueIndex:idx_prow_job_run_event_patterns_natural_key,priority:1"`
+	ProwJobRun   ProwJobRun
+	// Pattern is the type of event, i.e. crashloop.
+	Pattern string `gorm:"index;uniqueIndex:idx_prow_job_run_event_patterns_natural_key,priority:2"`
+	// Namespace the events occurred in, empty for node events.
+	Namespace string `gorm:"index;uniqueIndex:idx_prow_job_run_event_patterns_natural_key,priority:3"`
+	Count     int
+}
+
+// ProwJobRunFingerprint is an attribute of the environment a job run ran in, such as its cloud region or the
+// instance type of its workers, extracted from the run's artifacts.
+type ProwJobRunFingerprint struct {
+	gorm.Model
+	ProwJobRunID uint `gorm:"index;uniqueIndex:idx_prow_job_run_fingerprints_natural_key,priority:1"`
+	ProwJobRun   ProwJobRun
+	// Attribute is the kind of fingerprint, i.e. worker_instance_type.
+	Attribute string `gorm:"index;uniqueIndex:idx_prow_job_run_fingerprints_natural_key,priority:2"`
+	// Value is the attribute's value, with distinct values across nodes sorted and comma separated.
+	Value string `gorm:"index"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "github.com/lib/pq"
+
+// Lane groups the jobs of one configuration across releases and architectures, i.e. the aws-ovn-serial lane, so it
+// can be reported on as a whole.
+type Lane struct {
+	Model
+
+	Name        string `json:"name" gorm:"not null;uniqueIndex"`
+	Description string `json:"description"`
+
+	// JobNames are the lane's jobs, and JobPattern a regular expression matching more of them, so the lane picks up
+	// jobs for new releases without being updated. At least one is required.
+	JobNames   pq.StringArray `json:"job_names" gorm:"type:text[]"`
+	JobPattern string         `json:"job_pattern"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+const (
+	MilestoneFeatureFreeze = "feature-freeze"
+	MilestoneCodeFreeze    = "code-freeze"
+	MilestoneGA            = "ga"
+
+	MilestonePassRateComponent = "component"
+	MilestonePassRateVariant   = "variant"
+)
+
+// ReleaseMilestone is a date of interest in a release's development, such as feature freeze, code freeze or GA,
+// at which key pass rates are snapshotted so later readiness reviews can compare against them.
+type ReleaseMilestone struct {
+	Model
+
+	Release string `json:"release" gorm:"not null;uniqueIndex:idx_release_milestone"`
+	// Name is the milestone, i.e. feature-freeze, code-freeze or ga.
+	Name string    `json:"name" gorm:"not null;uniqueIndex:idx_release_milestone"`
+	Date time.Time `json:"date" gorm:"not null"`
+
+	// Snapshot is set once the milestone's pass rates have been snapshotted.
+	Snapshot *MilestoneSnapshot `json:"snapshot,omitempty" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// MilestoneSnapshot holds the pass rates of a release as of one of its milestones.
+type MilestoneSnapshot struct {
+	Model
+
+	ReleaseMilestoneID uint `json:"release_milestone_id" gorm:"uniqueIndex"`
+
+	// Start and End are the period the pass rates were computed over, which ends at the milestone date.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	PassRates []MilestonePassRate `json:"pass_rates,omitempty" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// MilestonePassRate is the pass rate of a component's tests, or of a variant's jobs, in a milestone snapshot.
+type MilestonePassRate struct {
+	Model
+
+	MilestoneSnapshotID uint `json:"milestone_snapshot_id" gorm:"index"`
+
+	// Kind is component or variant.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+
+	Runs      int `json:"runs"`
+	Successes int `json:"successes"`
+	Flakes    int `json:"flakes"`
+}
@@ -15,6 +15,16 @@ type ProwKind string
 const ProwPeriodic ProwKind = "periodic"
 const ProwPresubmit ProwKind = "presubmit"
 
+// ProwExternal jobs run in CI systems other than Prow, and are ingested through the external job runs API.
+const ProwExternal ProwKind = "external"
+
+// ProwManual jobs group test results QE recorded by hand, through the manual test results API.
+const ProwManual ProwKind = "manual"
+
+// RehearsalVariant is the variant of rehearse-* presubmits, defined here so the schema can exclude them from reports.
+// Use testidentification.Rehearsal elsewhere.
+const RehearsalVariant = "Rehearsal:true"
+
 // ProwJob represents a prow job with various fields inferred from it's name. (release, variants, etc)
 type ProwJob struct {
 	gorm.Model
@@ -26,6 +36,9 @@ type ProwJob struct {
 	TestGridURL string
 	Bugs        []Bug        `gorm:"many2many:bug_jobs;"`
 	JobRuns     []ProwJobRun `gorm:"constraint:OnDelete:CASCADE;"`
+
+	// CISystem is the CI system external jobs run in, i.e. jenkins, or manual for manual jobs. Empty for Prow jobs.
+	CISystem string
 }
 
 // IDName is a partial struct to query limited fields we need for caching. Can be used
@@ -46,6 +59,11 @@ type ProwJobRun struct {
 	// Cluster is the cluster where the prow job was run.
 	Cluster string
 
+	// Release is the release the run tested, when it differs from its job's. Jobs without a release in their name
+	// can test several releases' payloads, so it's resolved per run from the run's cluster data. Reports use the
+	// job's release when it's empty.
+	Release string `gorm:"index"`
+
 	URL          string
 	TestFailures int
 	Tests        []ProwJobRunTest  `gorm:"constraint:OnDelete:CASCADE;"`
@@ -59,9 +77,28 @@ type ProwJobRun struct {
 	Timestamp     time.Time `gorm:"index;index:idx_prow_job_runs_timestamp_date,expression:DATE(timestamp AT TIME ZONE 'UTC')"`
 	Duration      time.Duration
 	OverallResult v1.JobOverallResult `gorm:"index"`
+	// IntervalsLoaded is true if the job run's interval files were ingested, i.e. operator conditions and alerts.
+	IntervalsLoaded bool
+	// EventPatternsLoaded is true if abnormal event patterns were extracted from the job run's interval files.
+	EventPatternsLoaded bool
+	// JunitParserVersion is the version of the junit conversion the run's test results were imported with, 0 for
+	// runs imported before versions were tracked. Runs with an older version can be re-parsed.
+	JunitParserVersion int `gorm:"index"`
 	// used to pass the TestCount in via the api, we have the actual tests in the db and can calculate it here so don't persist
 	TestCount   int         `gorm:"-"`
 	ClusterData ClusterData `gorm:"-"`
+
+	// Manual records who ran a manual job's run and on what build, nil for other runs.
+	Manual *ManualJobRun `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// ManualJobRun is the provenance of a run of tests a tester executed by hand.
+type ManualJobRun struct {
+	gorm.Model
+	ProwJobRunID uint `gorm:"uniqueIndex"`
+	Tester       string
+	// Build is the payload or build the tests were run on, i.e. 4.16.0-rc.1.
+	Build string
 }
 
 type Test struct {
@@ -76,18 +113,31 @@ type Test struct {
 // that execution.
 type ProwJobRunTest struct {
 	gorm.Model
+	// ProwJobRunID, TestID and SuiteID are the natural key for a test result, and are unique so re-importing
+	// a job run upserts rather than duplicates its results. The unique index treats a nil SuiteID as equal to
+	// another, so is created with db.ProwJobRunTestKey rather than tags. TestID and CreatedAt are also indexed
+	// together so a test's recent results can be found without scanning its history.
 	ProwJobRunID uint `gorm:"index"`
 	ProwJobRun   ProwJobRun
-	TestID       uint `gorm:"index"`
+	TestID       uint `gorm:"index;index:idx_prow_job_run_tests_test_id_created_at,priority:1"`
 	Test         Test
 	// SuiteID may be nil if no suite name could be parsed from the testgrid test name.
 	SuiteID   *uint `gorm:"index"`
 	Suite     Suite
 	Status    int `gorm:"index"`
 	Duration  float64
-	CreatedAt time.Time `gorm:"index"`
+	CreatedAt time.Time `gorm:"index;index:idx_prow_job_run_tests_test_id_created_at,priority:2"`
 	DeletedAt gorm.DeletedAt
 
+	// CascadeFailure is true for failures in serial suites that appear to have been caused by an earlier
+	// failure in the same run, rather than being a genuine failure of this test.
+	CascadeFailure bool
+
+	// PayloadFlake is true for failures where the same test passed in another job run against the same payload,
+	// indicating the failure is likely a flake rather than a regression in the payload. Lenient pass rates count
+	// these as flakes rather than failures.
+	PayloadFlake bool
+
 	// ProwJobRunTestOutput collect the output of a failed test run. This is stored as a separate object in the DB, so
 	// we can keep the test result for a longer period of time than we keep the full failure output.
 	ProwJobRunTestOutput *ProwJobRunTestOutput `gorm:"constraint:OnDelete:CASCADE;"`
@@ -95,7 +145,7 @@ type ProwJobRunTest struct {
 
 type ProwJobRunTestOutput struct {
 	gorm.Model
-	ProwJobRunTestID uint `gorm:"index"`
+	ProwJobRunTestID uint `gorm:"uniqueIndex"`
 	// Output stores the output of a ProwJobRunTest.
 	Output string
 
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	// TriageStateNew means a JIRA issue is open but nobody has started on it.
+	TriageStateNew = "new"
+	// TriageStateInProgress means the JIRA issue is being worked on.
+	TriageStateInProgress = "in_progress"
+	// TriageStateResolved means the JIRA issue is done, or sippy stopped detecting the problem and said so on the
+	// issue. Resolved records are no longer synced once sippy has closed them too.
+	TriageStateResolved = "resolved"
+	// TriageStateReopened means the fix for a regression's JIRA issue shipped in a payload, but sippy still detected
+	// the regression after the verification window. It stays reopened until the issue is reopened too, or sippy
+	// closes the regression.
+	TriageStateReopened = "reopened"
+)
+
+const (
+	// FixVerificationPending means a payload with the fix has been accepted, and the regression is being watched.
+	FixVerificationPending = "pending"
+	// FixVerificationCleared means the regression closed after the fix shipped.
+	FixVerificationCleared = "cleared"
+	// FixVerificationNotCleared means the regression was still open at the end of the verification window.
+	FixVerificationNotCleared = "not_cleared"
+)
+
+// TestRegression records the lifecycle of a regressed test in a component readiness view, from when it first
+// appeared in the report until it no longer did. Regressions are maintained by the regression tracker as views
+// are refreshed, so their age and time to resolution can be reported without recomputing historical reports.
+type TestRegression struct {
+	Model
+
+	View    string `json:"view" gorm:"index"`
+	Release string `json:"release" gorm:"index"`
+
+	// TestID is the component readiness test ID, which is stable across test renames.
+	TestID    string `json:"test_id" gorm:"index"`
+	TestName  string `json:"test_name"`
+	Component string `json:"component" gorm:"index"`
+
+	// Variants are the column's variants in the form Name:value, sorted by name.
+	Variants pq.StringArray `json:"variants" gorm:"type:text[]"`
+
+	// Opened is when the regression was first detected.
+	Opened time.Time `json:"opened" gorm:"not null;index"`
+
+	// Closed is when the regression was no longer detected, nil while open.
+	Closed *time.Time `json:"closed" gorm:"index"`
+
+	// JiraKey is the JIRA issue opened for the regression by the jira-sync loader, if any.
+	JiraKey string `json:"jira_key" gorm:"index"`
+
+	// TriageState follows the status of the JIRA issue, see the TriageState constants.
+	TriageState string `json:"triage_state"`
+
+	// FixedIn is the first accepted payload of the release including a pull request for the JIRA issue, and FixedAt
+	// its release time.
+	FixedIn string     `json:"fixed_in"`
+	FixedAt *time.Time `json:"fixed_at"`
+
+	// FixVerification is whether the regression cleared after the fix shipped, see the FixVerification constants.
+	FixVerification string `json:"fix_verification"`
+}
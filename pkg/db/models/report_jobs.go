@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ReportJob is a report generation handed from an API frontend to a report worker. Key is the request URI of the
+// report, so identical requests share a job, and the worker's response is kept in ResultCode and Result.
+type ReportJob struct {
+	Model
+
+	Kind   string `json:"kind" gorm:"not null;index"`
+	Key    string `json:"key" gorm:"not null;index"`
+	Status string `json:"status" gorm:"not null;index"`
+
+	// Worker is the worker that last claimed the job, and Attempts how many times it has been claimed.
+	Worker   string `json:"worker"`
+	Attempts int    `json:"attempts"`
+
+	ResultCode int    `json:"result_code"`
+	Result     []byte `json:"-"`
+	Error      string `json:"error,omitempty"`
+
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ShadowEvaluation records how a candidate regression detection algorithm, run in shadow mode, agreed with the
+// current algorithm on a generated component readiness report for a view.
+type ShadowEvaluation struct {
+	Model
+
+	View      string `json:"view" gorm:"not null;uniqueIndex:idx_shadow_evaluation"`
+	Release   string `json:"release" gorm:"not null;index"`
+	Algorithm string `json:"algorithm" gorm:"not null;uniqueIndex:idx_shadow_evaluation"`
+
+	// GeneratedAt is when the report was generated, so a cached report is only recorded once.
+	GeneratedAt time.Time `json:"generated_at" gorm:"not null;uniqueIndex:idx_shadow_evaluation"`
+
+	TestsEvaluated int `json:"tests_evaluated"`
+	BothRegressed  int `json:"both_regressed"`
+	CurrentOnly    int `json:"current_only"`
+	CandidateOnly  int `json:"candidate_only"`
+
+	// Verdicts are the tests either algorithm found regressed.
+	Verdicts []ShadowVerdict `json:"verdicts,omitempty" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// ShadowVerdict is both algorithms' verdict for a test either found regressed.
+type ShadowVerdict struct {
+	Model
+
+	ShadowEvaluationID uint `json:"shadow_evaluation_id" gorm:"index"`
+
+	TestID    string `json:"test_id"`
+	TestName  string `json:"test_name"`
+	Component string `json:"component"`
+
+	// Variants are the column's variants in the form Name:value, sorted by name.
+	Variants pq.StringArray `json:"variants" gorm:"type:text[]"`
+
+	CurrentStatus      int     `json:"current_status"`
+	CurrentRegressed   bool    `json:"current_regressed"`
+	CandidateRegressed bool    `json:"candidate_regressed"`
+	CandidateScore     float64 `json:"candidate_score"`
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// VariantChange records a job's variant key changing value when its variants are re-identified. A key being added
+// or removed is recorded with an empty old or new value. Keys that change often usually point to a parsing bug, or a
+// nondeterministic source of variant data.
+type VariantChange struct {
+	Model
+
+	ProwJobID uint   `json:"prow_job_id" gorm:"index"`
+	JobName   string `json:"job_name" gorm:"index"`
+	Key       string `json:"key" gorm:"index"`
+	OldValue  string `json:"old_value"`
+	NewValue  string `json:"new_value"`
+}
+
+// DiffVariants returns the changes between a job's old and new variants, which are "Key:Value" strings, in key
+// order. Variants without a key, i.e. "never-stable", are treated as keys without a value.
+func DiffVariants(job *ProwJob, oldVariants, newVariants []string) []VariantChange {
+	oldValues, newValues := variantValues(oldVariants), variantValues(newVariants)
+
+	keys := make([]string, 0, len(oldValues)+len(newValues))
+	for k := range oldValues {
+		keys = append(keys, k)
+	}
+	for k := range newValues {
+		if _, ok := oldValues[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []VariantChange
+	for _, k := range keys {
+		oldValue, inOld := oldValues[k]
+		newValue, inNew := newValues[k]
+		if inOld == inNew && oldValue == newValue {
+			continue
+		}
+		changes = append(changes, VariantChange{
+			ProwJobID: job.ID,
+			JobName:   job.Name,
+			Key:       k,
+			OldValue:  oldValue,
+			NewValue:  newValue,
+		})
+	}
+	return changes
+}
+
+func variantValues(variants []string) map[string]string {
+	values := make(map[string]string, len(variants))
+	for _, v := range variants {
+		key, value, _ := strings.Cut(v, ":")
+		values[key] = value
+	}
+	return values
+}
+
+// JobOwnerChange records a job's Owner variant changing in the variant registry. Regressions are routed to teams
+// by owner, so an unexpected change silently sends a job's regressions to the wrong team.
+type JobOwnerChange struct {
+	Model
+
+	JobName  string `json:"job_name" gorm:"index"`
+	OldOwner string `json:"old_owner"`
+	NewOwner string `json:"new_owner"`
+}
+
+// VariantRulesRevision is a committed revision of the declarative variant rules, currently the release defaults
+// matrix used when generating expected job variants. The latest revision is active, with no revisions the rules
+// built into sippy are used.
+type VariantRulesRevision struct {
+	Model
+
+	// Rules is the YAML rules document.
+	Rules string `json:"rules" gorm:"not null"`
+
+	// BaseRevisionID is the revision that was active when this one was proposed, 0 for the built-in rules.
+	BaseRevisionID uint `json:"base_revision_id"`
+
+	// Author and Comment explain who changed the rules and why.
+	Author  string `json:"author" gorm:"not null"`
+	Comment string `json:"comment" gorm:"not null"`
+
+	// ChangedJobs is the number of jobs the preview showed would change when the revision was committed.
+	ChangedJobs int `json:"changed_jobs"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffVariants(t *testing.T) {
+	job := &ProwJob{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"}
+	job.ID = 7
+
+	changes := DiffVariants(job,
+		[]string{"Platform:aws", "Network:sdn", "Topology:ha", "never-stable"},
+		[]string{"Platform:aws", "Network:ovn", "Installer:ipi"})
+
+	assert.Equal(t, []VariantChange{
+		{ProwJobID: 7, JobName: job.Name, Key: "Installer", OldValue: "", NewValue: "ipi"},
+		{ProwJobID: 7, JobName: job.Name, Key: "Network", OldValue: "sdn", NewValue: "ovn"},
+		{ProwJobID: 7, JobName: job.Name, Key: "Topology", OldValue: "ha", NewValue: ""},
+		{ProwJobID: 7, JobName: job.Name, Key: "never-stable", OldValue: "", NewValue: ""},
+	}, changes)
+
+	assert.Empty(t, DiffVariants(job, []string{"Platform:aws", "Network:ovn"}, []string{"Network:ovn", "Platform:aws"}))
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	WatchStateAbove = "above"
+	WatchStateBelow = "below"
+)
+
+// WatchSubscription subscribes someone to a test, a job, or the jobs with a set of variants. They are notified when
+// the pass rate crosses their threshold, or a regression of a watched test opens or closes.
+type WatchSubscription struct {
+	Model
+
+	// Subscriber identifies who created the subscription, i.e. their e-mail address or username.
+	Subscriber string `json:"subscriber" gorm:"not null;index"`
+
+	Release string `json:"release" gorm:"not null;index"`
+
+	// At most one of TestName and JobName is set. Variants limits a test to runs in jobs with all of the variants,
+	// or on its own watches every job with all of the variants.
+	TestName string         `json:"test_name" gorm:"index"`
+	JobName  string         `json:"job_name" gorm:"index"`
+	Variants pq.StringArray `json:"variants" gorm:"type:text[]"`
+
+	// Threshold is the pass percentage to notify when the pass rate crosses, 0 disables pass rate notifications.
+	Threshold float64 `json:"threshold"`
+
+	// NotifyRegressions notifies when a component readiness regression of the watched test, or in the watched
+	// variants, opens or closes.
+	NotifyRegressions bool `json:"notify_regressions"`
+
+	// WebhookURL and Email are where notifications are sent, at least one is required.
+	WebhookURL string         `json:"webhook_url"`
+	Email      pq.StringArray `json:"email" gorm:"type:text[]"`
+
+	// LastState is whether the pass rate was above or below the threshold when last checked, LastPassPercentage
+	// what it was, and LastCheckedAt when. They are maintained by the watchlist loader.
+	LastState          string     `json:"last_state"`
+	LastPassPercentage *float64   `json:"last_pass_percentage"`
+	LastCheckedAt      *time.Time `json:"last_checked_at"`
+}
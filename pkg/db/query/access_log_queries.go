@@ -0,0 +1,113 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// EndpointUsage summarizes the access logs of each endpoint between start and end, most requested first.
+func EndpointUsage(dbc *db.DB, start, end time.Time) ([]apitype.EndpointUsage, error) {
+	now := time.Now()
+	results := make([]apitype.EndpointUsage, 0)
+	res := dbc.DB.Raw(`
+SELECT endpoint,
+	COUNT(*) AS requests,
+	COUNT(*) FILTER (WHERE status >= 400 AND status < 500) AS client_errors,
+	COUNT(*) FILTER (WHERE status >= 500) AS server_errors,
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_millis) AS p50_latency_millis,
+	percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_millis) AS p95_latency_millis,
+	COUNT(*) FILTER (WHERE cache_status = @hit) * 100.0
+		/ NULLIF(COUNT(*) FILTER (WHERE cache_status != @none), 0) AS cache_hit_percentage,
+	MAX(timestamp) AS last_request
+FROM access_logs
+WHERE timestamp BETWEEN @start AND @end
+GROUP BY endpoint
+ORDER BY requests DESC, endpoint`, map[string]interface{}{
+		"start": start,
+		"end":   end,
+		"hit":   models.CacheStatusHit,
+		"none":  models.CacheStatusNone,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("EndpointUsage completed")
+	return results, nil
+}
+
+// EndpointParams returns how often each endpoint was called with each set of normalized parameters between start
+// and end, most common first.
+func EndpointParams(dbc *db.DB, start, end time.Time) ([]apitype.EndpointParamsUsage, error) {
+	results := make([]apitype.EndpointParamsUsage, 0)
+	res := dbc.DB.Raw(`
+SELECT endpoint, params, COUNT(*) AS requests
+FROM access_logs
+WHERE timestamp BETWEEN @start AND @end
+GROUP BY endpoint, params
+ORDER BY endpoint, requests DESC, params`, map[string]interface{}{
+		"start": start,
+		"end":   end,
+	}).Scan(&results)
+	return results, res.Error
+}
+
+// requestVariants unnests the variants of the access logs between start and end into their key, and value for those
+// filtered on or an empty value for those grouped by.
+const requestVariants = `
+SELECT access_logs.id, access_logs.timestamp,
+	split_part(variant, ':', 1) AS key,
+	substr(variant, length(split_part(variant, ':', 1)) + 2) AS value
+FROM access_logs, unnest(access_logs.variants) AS variant
+WHERE access_logs.timestamp BETWEEN @start AND @end`
+
+// VariantKeyUsage returns how many requests between start and end used each variant key, most used first.
+func VariantKeyUsage(dbc *db.DB, start, end time.Time) ([]apitype.VariantUsage, error) {
+	now := time.Now()
+	results := make([]apitype.VariantUsage, 0)
+	res := dbc.DB.Raw(`
+SELECT key,
+	COUNT(DISTINCT id) AS requests,
+	COUNT(DISTINCT id) FILTER (WHERE value <> '') AS filter_requests,
+	COUNT(DISTINCT id) FILTER (WHERE value = '') AS group_requests,
+	MAX(timestamp) AS last_request
+FROM (`+requestVariants+`) AS request_variants
+GROUP BY key
+ORDER BY requests DESC, key`, map[string]interface{}{
+		"start": start,
+		"end":   end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("VariantKeyUsage completed")
+	return results, nil
+}
+
+// VariantValueUsage returns how many requests between start and end filtered on each variant value, in key order
+// and most used first.
+func VariantValueUsage(dbc *db.DB, start, end time.Time) ([]apitype.VariantValueUsage, error) {
+	results := make([]apitype.VariantValueUsage, 0)
+	res := dbc.DB.Raw(`
+SELECT key, value, COUNT(*) AS requests, MAX(timestamp) AS last_request
+FROM (`+requestVariants+`) AS request_variants
+WHERE value <> ''
+GROUP BY key, value
+ORDER BY key, requests DESC, value`, map[string]interface{}{
+		"start": start,
+		"end":   end,
+	}).Scan(&results)
+	return results, res.Error
+}
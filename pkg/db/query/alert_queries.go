@@ -0,0 +1,60 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// AlertFiringSummaries reports how often each alert fired in a release, by variant, as a percentage of runs with
+// alerts loaded. Results can optionally be limited to a single variant. Only the current release fields of the
+// summaries are populated.
+func AlertFiringSummaries(dbc *db.DB, release, variant string, start, end time.Time) ([]apitype.AlertFiringSummary, error) {
+	now := time.Now()
+	results := make([]apitype.AlertFiringSummary, 0)
+
+	res := dbc.DB.Raw(`
+WITH runs AS (
+	SELECT prow_job_runs.id, unnest(prow_jobs.variants) AS variant
+	FROM prow_job_runs
+	JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+	WHERE `+db.RunRelease+` = @release
+		AND prow_job_runs.timestamp BETWEEN @start AND @end
+		AND prow_job_runs.intervals_loaded
+		AND prow_job_runs.deleted_at IS NULL
+), totals AS (
+	SELECT variant, COUNT(*) AS total_runs
+	FROM runs
+	GROUP BY variant
+)
+SELECT alerts.name AS alert,
+	MAX(alerts.severity) AS severity,
+	runs.variant,
+	COUNT(DISTINCT runs.id) AS runs,
+	totals.total_runs,
+	COUNT(DISTINCT runs.id) * 100.0 / totals.total_runs AS percentage
+FROM prow_job_run_alerts alerts
+JOIN runs ON runs.id = alerts.prow_job_run_id
+JOIN totals ON totals.variant = runs.variant
+WHERE alerts.deleted_at IS NULL
+	AND (@variant = '' OR runs.variant = @variant)
+GROUP BY alerts.name, runs.variant, totals.total_runs`, map[string]interface{}{
+		"release": release,
+		"variant": variant,
+		"start":   start,
+		"end":     end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"release": release,
+		"rows":    len(results),
+	}).Info("AlertFiringSummaries completed")
+	return results, nil
+}
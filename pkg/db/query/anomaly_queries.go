@@ -0,0 +1,95 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// DailyPassRate is the pass rate of a job or component on a single day.
+type DailyPassRate struct {
+	Name           string
+	Date           time.Time
+	Runs           int
+	PassPercentage float64
+}
+
+// DailyJobPassRates returns the daily pass rate of every job in a release between start and end,
+// ordered by job and date. Runs during a known incident are optionally excluded.
+func DailyJobPassRates(dbc *db.DB, release string, start, end time.Time, excludeIncidents bool) ([]DailyPassRate, error) {
+	now := time.Now()
+	rates := make([]DailyPassRate, 0)
+
+	exclude := "TRUE"
+	if excludeIncidents {
+		exclude = ExcludeIncidentsClause
+	}
+
+	res := dbc.DB.Raw(`
+SELECT prow_jobs.name AS name,
+	date_trunc('day', prow_job_runs.timestamp) AS date,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.succeeded) * 100.0 / COUNT(*) AS pass_percentage
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+db.RunRelease+` = @release
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND `+exclude+`
+GROUP BY prow_jobs.name, date
+ORDER BY prow_jobs.name, date`, map[string]interface{}{
+		"release": release,
+		"start":   start,
+		"end":     end,
+	}).Scan(&rates)
+	if res.Error != nil {
+		return rates, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(rates),
+	}).Info("DailyJobPassRates completed")
+	return rates, nil
+}
+
+// DailyComponentPassRates returns the daily pass rate of the tests owned by each component in a release, ordered by
+// component and date. Flakes are counted as passes. The underlying matview only covers the last 14 days, and has no
+// suite, so a test with ownerships in several suites is counted once per component owning it.
+func DailyComponentPassRates(dbc *db.DB, release string, start, end time.Time) ([]DailyPassRate, error) {
+	now := time.Now()
+	rates := make([]DailyPassRate, 0)
+
+	res := dbc.DB.Raw(`
+SELECT test_ownerships.component AS name,
+	date_trunc('day', results.date) AS date,
+	SUM(results.runs) AS runs,
+	SUM(results.passes + results.flakes) * 100.0 / NULLIF(SUM(results.runs), 0) AS pass_percentage
+FROM prow_test_analysis_by_job_14d_matview results
+JOIN (
+	SELECT DISTINCT test_id, component
+	FROM test_ownerships
+	WHERE component != ''
+		AND deleted_at IS NULL
+) test_ownerships ON test_ownerships.test_id = results.test_id
+WHERE results.release = @release
+	AND results.date BETWEEN @start AND @end
+GROUP BY test_ownerships.component, date_trunc('day', results.date)
+HAVING SUM(results.runs) > 0
+ORDER BY test_ownerships.component, date_trunc('day', results.date)`, map[string]interface{}{
+		"release": release,
+		"start":   start,
+		"end":     end,
+	}).Scan(&rates)
+	if res.Error != nil {
+		return rates, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(rates),
+	}).Info("DailyComponentPassRates completed")
+	return rates, nil
+}
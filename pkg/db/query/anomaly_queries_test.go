@@ -0,0 +1,46 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/test/golden"
+)
+
+func TestDailyComponentPassRatesOwnershipInSeveralSuites(t *testing.T) {
+	// the database is shared with other tests, so ownerships are created in a transaction that is rolled back
+	tx := golden.NewDB(t, "../../../test/golden/testdata/fixtures.json").DB.Begin()
+	defer tx.Rollback()
+	dbc := &db.DB{DB: tx}
+
+	const testName = "[sig-network] pods should reach each other"
+	var test models.Test
+	require.NoError(t, tx.Where("name = ?", testName).First(&test).Error)
+	start, end := golden.ReportEnd.Add(-14*24*time.Hour), golden.ReportEnd
+
+	own := func(suite string) {
+		ownership := models.TestOwnership{Name: testName, TestID: test.ID, Suite: suite, Component: "Networking"}
+		require.NoError(t, tx.Create(&ownership).Error)
+	}
+
+	own("openshift-tests")
+	once, err := query.DailyComponentPassRates(dbc, "4.16", start, end)
+	require.NoError(t, err)
+	runs := 0
+	for _, rate := range once {
+		assert.Equal(t, "Networking", rate.Name)
+		runs += rate.Runs
+	}
+	assert.Equal(t, 8, runs, "every fixture run of the test is counted")
+
+	own("openshift-tests-upgrade")
+	twice, err := query.DailyComponentPassRates(dbc, "4.16", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, once, twice, "a second ownership by the same component does not count the test's runs twice")
+}
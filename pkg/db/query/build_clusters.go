@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"time"
 
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
 )
@@ -90,3 +93,69 @@ LEFT JOIN
 `, period)).Scan(&results)
 	return results, q.Error
 }
+
+// BuildClusterFailureCount is how many runs on a build cluster failed, or failed a test.
+type BuildClusterFailureCount struct {
+	Cluster                string
+	Runs                   int
+	Failures               int
+	InfrastructureFailures int
+}
+
+// BuildClusterFailures counts the runs and failures on each build cluster between start and end, optionally limited
+// to a release and job. When a test is given, the runs are those that ran the test and failures are of the test.
+func BuildClusterFailures(dbc *db.DB, release, job, test string, start, end time.Time) ([]BuildClusterFailureCount, error) {
+	now := time.Now()
+	results := make([]BuildClusterFailureCount, 0)
+
+	var q string
+	if test != "" {
+		q = `
+SELECT prow_job_runs.cluster,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @failure) AS failures,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @failure AND prow_job_runs.infrastructure_failure) AS infrastructure_failures
+FROM prow_job_run_tests
+JOIN tests ON tests.id = prow_job_run_tests.test_id
+JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE tests.name = @test
+	AND prow_job_run_tests.deleted_at IS NULL`
+	} else {
+		q = `
+SELECT prow_job_runs.cluster,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE NOT prow_job_runs.succeeded) AS failures,
+	COUNT(*) FILTER (WHERE prow_job_runs.infrastructure_failure) AS infrastructure_failures
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE TRUE`
+	}
+	q += `
+	AND prow_job_runs.cluster IS NOT NULL
+	AND prow_job_runs.cluster != ''
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND (@release = '' OR ` + db.RunRelease + ` = @release)
+	AND (@job = '' OR prow_jobs.name = @job)
+GROUP BY prow_job_runs.cluster
+ORDER BY prow_job_runs.cluster`
+
+	res := dbc.DB.Raw(q, map[string]interface{}{
+		"release": release,
+		"job":     job,
+		"test":    test,
+		"start":   start,
+		"end":     end,
+		"failure": v1.TestStatusFailure,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed":  time.Since(now),
+		"clusters": len(results),
+	}).Info("BuildClusterFailures completed")
+	return results, nil
+}
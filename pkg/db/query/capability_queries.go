@@ -0,0 +1,69 @@
+package query
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// CapabilityTestResult is a test's current results in one variant combination, for one of its capabilities or
+// features.
+type CapabilityTestResult struct {
+	Capability       string
+	Name             string
+	Component        string
+	Variants         pq.StringArray `gorm:"type:text[]"`
+	CurrentRuns      int
+	CurrentSuccesses int
+	CurrentFailures  int
+	CurrentFlakes    int
+}
+
+// CapabilityTestResults returns the current results of tests by capability and feature for a release, optionally
+// limited to one capability, and to variant combinations containing all the given variants. Tests without results
+// are returned once with no variants.
+func CapabilityTestResults(dbc *db.DB, release, capability string, variants []string) ([]CapabilityTestResult, error) {
+	now := time.Now()
+	q := `
+WITH capabilities AS (
+	SELECT name, component, unnest(COALESCE(capabilities, '{}') || COALESCE(features, '{}')) AS capability
+	FROM test_ownerships
+	WHERE deleted_at IS NULL
+)
+SELECT
+	capabilities.capability,
+	capabilities.name,
+	capabilities.component,
+	matview.variants,
+	COALESCE(SUM(matview.current_runs), 0) AS current_runs,
+	COALESCE(SUM(matview.current_successes), 0) AS current_successes,
+	COALESCE(SUM(matview.current_failures), 0) AS current_failures,
+	COALESCE(SUM(matview.current_flakes), 0) AS current_flakes
+FROM capabilities
+	LEFT JOIN prow_test_report_7d_matview matview ON matview.name = capabilities.name
+		AND matview.release = @release
+		AND matview.variants @> @variants
+WHERE @capability = '' OR capabilities.capability = @capability
+GROUP BY capabilities.capability, capabilities.name, capabilities.component, matview.variants
+ORDER BY capabilities.capability, capabilities.name`
+
+	if variants == nil {
+		variants = []string{}
+	}
+	var results []CapabilityTestResult
+	res := dbc.DB.Raw(q,
+		sql.Named("release", release),
+		sql.Named("capability", capability),
+		sql.Named("variants", pq.StringArray(variants))).Scan(&results)
+	log.WithFields(log.Fields{
+		"release":    release,
+		"capability": capability,
+		"results":    len(results),
+		"elapsed":    time.Since(now),
+	}).Debug("CapabilityTestResults completed")
+	return results, res.Error
+}
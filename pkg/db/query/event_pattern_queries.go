@@ -0,0 +1,64 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// EventPatternSummaries aggregates abnormal event patterns in a release by day, pattern, namespace and variant.
+// Results can optionally be limited to a single variant, pattern, or namespace.
+func EventPatternSummaries(dbc *db.DB, release, variant, pattern, namespace string, start, end time.Time) ([]apitype.EventPatternSummary, error) {
+	now := time.Now()
+	results := make([]apitype.EventPatternSummary, 0)
+
+	res := dbc.DB.Raw(`
+WITH runs AS (
+	SELECT prow_job_runs.id, date_trunc('day', prow_job_runs.timestamp) AS date, unnest(prow_jobs.variants) AS variant
+	FROM prow_job_runs
+	JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+	WHERE `+db.RunRelease+` = @release
+		AND prow_job_runs.timestamp BETWEEN @start AND @end
+		AND prow_job_runs.event_patterns_loaded
+		AND prow_job_runs.deleted_at IS NULL
+), totals AS (
+	SELECT date, variant, COUNT(*) AS total_runs
+	FROM runs
+	GROUP BY date, variant
+)
+SELECT runs.date,
+	patterns.pattern,
+	patterns.namespace,
+	runs.variant,
+	COUNT(DISTINCT runs.id) AS runs,
+	totals.total_runs,
+	SUM(patterns.count) AS events
+FROM prow_job_run_event_patterns patterns
+JOIN runs ON runs.id = patterns.prow_job_run_id
+JOIN totals ON totals.date = runs.date AND totals.variant = runs.variant
+WHERE patterns.deleted_at IS NULL
+	AND (@variant = '' OR runs.variant = @variant)
+	AND (@pattern = '' OR patterns.pattern = @pattern)
+	AND (@namespace = '' OR patterns.namespace = @namespace)
+GROUP BY runs.date, patterns.pattern, patterns.namespace, runs.variant, totals.total_runs
+ORDER BY runs.date, events DESC`, map[string]interface{}{
+		"release":   release,
+		"variant":   variant,
+		"pattern":   pattern,
+		"namespace": namespace,
+		"start":     start,
+		"end":       end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("EventPatternSummaries completed")
+	return results, nil
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// FingerprintResults are the runs, and failures, with one value of a fingerprint attribute.
+type FingerprintResults struct {
+	Attribute string
+	Value     string
+	Runs      int
+	Failures  int
+}
+
+// FingerprintFailures returns how often the job runs of a release between start and end failed for each value of
+// their fingerprint attributes. If testName is set, the runs that ran the test are counted, and failures are the
+// test's. If attribute is set, only that attribute is returned.
+func FingerprintFailures(dbc *db.DB, release, attribute, testName string, start, end time.Time) ([]FingerprintResults, error) {
+	now := time.Now()
+	results := make([]FingerprintResults, 0)
+
+	counts := `COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE NOT prow_job_runs.succeeded) AS failures`
+	join := ""
+	if testName != "" {
+		counts = `COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @failure) AS failures`
+		join = `JOIN prow_job_run_tests ON prow_job_run_tests.prow_job_run_id = prow_job_runs.id
+	AND prow_job_run_tests.deleted_at IS NULL
+	AND prow_job_run_tests.test_id = (SELECT id FROM tests WHERE name = @test)`
+	}
+
+	res := dbc.DB.Raw(`
+SELECT fingerprints.attribute,
+	fingerprints.value,
+	`+counts+`
+FROM prow_job_run_fingerprints fingerprints
+JOIN prow_job_runs ON prow_job_runs.id = fingerprints.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+`+join+`
+WHERE `+db.RunRelease+` = @release
+	AND (@attribute = '' OR fingerprints.attribute = @attribute)
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND fingerprints.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY fingerprints.attribute, fingerprints.value
+ORDER BY fingerprints.attribute, fingerprints.value`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"attribute": attribute,
+		"test":      testName,
+		"start":     start,
+		"end":       end,
+		"failure":   v1.TestStatusFailure,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("FingerprintFailures completed")
+	return results, nil
+}
@@ -0,0 +1,49 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// ClassifyPayloadFlakes marks test failures imported since the given time as payload flakes, where the same test
+// passed or flaked in another job run against the same payload. Returns the number of newly classified failures.
+func ClassifyPayloadFlakes(dbc *db.DB, since time.Time) (int64, error) {
+	now := time.Now()
+
+	res := dbc.DB.Exec(`
+UPDATE prow_job_run_tests AS failed
+SET payload_flake = TRUE
+FROM release_job_runs AS failed_run
+WHERE failed_run.prow_job_run_id = failed.prow_job_run_id
+	AND failed.status = @failure
+	AND NOT failed.payload_flake
+	AND failed.created_at >= @since
+	AND failed.deleted_at IS NULL
+	AND EXISTS (
+		SELECT 1
+		FROM release_job_runs AS other_run
+		JOIN prow_job_run_tests AS passed ON passed.prow_job_run_id = other_run.prow_job_run_id
+		WHERE other_run.release_tag_id = failed_run.release_tag_id
+			AND other_run.prow_job_run_id <> failed_run.prow_job_run_id
+			AND passed.test_id = failed.test_id
+			AND passed.status IN (@success, @flake)
+			AND passed.deleted_at IS NULL)`, map[string]interface{}{
+		"since":   since,
+		"success": v1.TestStatusSuccess,
+		"flake":   v1.TestStatusFlake,
+		"failure": v1.TestStatusFailure,
+	})
+	if res.Error != nil {
+		return 0, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed":    time.Since(now),
+		"classified": res.RowsAffected,
+	}).Info("ClassifyPayloadFlakes completed")
+	return res.RowsAffected, nil
+}
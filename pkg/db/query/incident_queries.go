@@ -0,0 +1,134 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// InfrastructureFailureSignature is the failure signature used for job runs that failed due to CI infrastructure,
+// rather than a specific test.
+const InfrastructureFailureSignature = "job run infrastructure failure"
+
+// ExcludeIncidentsClause is a where clause that filters out job runs that started during a confirmed incident
+// affecting their job. It requires prow_job_runs and prow_jobs to be available in the query.
+const ExcludeIncidentsClause = `NOT EXISTS (
+	SELECT 1 FROM incidents
+	WHERE incidents.deleted_at IS NULL
+		AND NOT incidents.provisional
+		AND prow_job_runs.timestamp >= incidents.start_time
+		AND (incidents.end_time IS NULL OR prow_job_runs.timestamp < incidents.end_time)
+		AND (incidents.release = '' OR incidents.release = ` + db.RunRelease + `)
+		AND prow_jobs.variants @> COALESCE(incidents.variants, '{}'))`
+
+// IncidentsOverlapping returns incidents overlapping the given time range, optionally limited to those affecting a
+// release and a specific job.
+func IncidentsOverlapping(dbc *db.DB, release, job string, start, end time.Time) ([]models.Incident, error) {
+	incidents := make([]models.Incident, 0)
+	q := dbc.DB.Model(&models.Incident{}).
+		Where("start_time <= ?", end).
+		Where("end_time IS NULL OR end_time >= ?", start)
+	if release != "" {
+		q = q.Where("release = '' OR release = ?", release)
+	}
+	if job != "" {
+		q = q.Where(`EXISTS (
+			SELECT 1 FROM prow_jobs
+			WHERE prow_jobs.name = ? AND prow_jobs.variants @> COALESCE(incidents.variants, '{}'))`, job)
+	}
+	res := q.Order("start_time DESC").Find(&incidents)
+	return incidents, res.Error
+}
+
+// FailureSpike is an hour in which a single failure signature was seen across many jobs.
+type FailureSpike struct {
+	Bucket     time.Time
+	Signature  string
+	FailedJobs int
+	Jobs       int
+	// Platforms is the number of distinct platforms the failed jobs ran on.
+	Platforms int
+	JobNames  pq.StringArray `gorm:"type:text[]"`
+}
+
+// FailureSpikes returns hourly buckets since the given time where at least minJobs distinct jobs on at least
+// minPlatforms distinct platforms failed with the same signature, and those jobs make up at least minFraction of the
+// jobs that ran it. Signatures are failed test names, or InfrastructureFailureSignature for job runs that failed due to
+// infrastructure. Sippy's synthetic tests and the Overall tests are excluded, as they fail along with any other test.
+func FailureSpikes(dbc *db.DB, since time.Time, minJobs, minPlatforms int, minFraction float64) ([]FailureSpike, error) {
+	now := time.Now()
+	spikes := make([]FailureSpike, 0)
+
+	// Test results are created when their run is imported, after it started, so bounding them by the indexed
+	// created_at as well as their run's timestamp avoids scanning the full history of prow_job_run_tests.
+	res := dbc.DB.Raw(`
+WITH runs AS (
+	SELECT date_trunc('hour', prow_job_runs.timestamp) AS bucket,
+		tests.name AS signature,
+		prow_job_runs.prow_job_id AS job_id,
+		prow_job_run_tests.status = @failure AS failed
+	FROM prow_job_run_tests
+	JOIN tests ON tests.id = prow_job_run_tests.test_id
+	JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+	WHERE prow_job_run_tests.created_at >= @since
+		AND prow_job_runs.timestamp >= @since
+		AND prow_job_runs.deleted_at IS NULL
+		AND prow_job_run_tests.deleted_at IS NULL
+		AND tests.name <> 'Overall'
+		AND tests.name NOT LIKE '%.Overall'
+		AND NOT EXISTS (
+			SELECT 1 FROM suites
+			WHERE suites.id = prow_job_run_tests.suite_id AND suites.name = @synthetic_suite)
+	UNION ALL
+	SELECT date_trunc('hour', prow_job_runs.timestamp) AS bucket,
+		@infra AS signature,
+		prow_job_runs.prow_job_id AS job_id,
+		prow_job_runs.overall_result = @infra_result AS failed
+	FROM prow_job_runs
+	WHERE prow_job_runs.timestamp >= @since
+		AND prow_job_runs.deleted_at IS NULL
+)
+SELECT runs.bucket,
+	runs.signature,
+	COUNT(DISTINCT runs.job_id) FILTER (WHERE runs.failed) AS failed_jobs,
+	COUNT(DISTINCT runs.job_id) AS jobs,
+	COUNT(DISTINCT platforms.platform) FILTER (WHERE runs.failed) AS platforms,
+	array_agg(DISTINCT prow_jobs.name) FILTER (WHERE runs.failed) AS job_names
+FROM runs
+JOIN prow_jobs ON prow_jobs.id = runs.job_id
+LEFT JOIN LATERAL (
+	SELECT split_part(variant, ':', 2) AS platform
+	FROM unnest(prow_jobs.variants) AS variant
+	WHERE variant LIKE 'Platform:%'
+	LIMIT 1
+) platforms ON true
+GROUP BY runs.bucket, runs.signature
+HAVING COUNT(DISTINCT runs.job_id) FILTER (WHERE runs.failed) >= @min_jobs
+	AND COUNT(DISTINCT platforms.platform) FILTER (WHERE runs.failed) >= @min_platforms
+	AND COUNT(DISTINCT runs.job_id) FILTER (WHERE runs.failed) >= @min_fraction * COUNT(DISTINCT runs.job_id)
+ORDER BY runs.bucket, failed_jobs DESC`, map[string]interface{}{
+		"since":           since,
+		"failure":         v1.TestStatusFailure,
+		"synthetic_suite": testidentification.SippySuiteName,
+		"infra":           InfrastructureFailureSignature,
+		"infra_result":    v1.JobInfrastructureFailure,
+		"min_jobs":        minJobs,
+		"min_platforms":   minPlatforms,
+		"min_fraction":    minFraction,
+	}).Scan(&spikes)
+	if res.Error != nil {
+		return spikes, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"spikes":  len(spikes),
+	}).Info("FailureSpikes completed")
+	return spikes, nil
+}
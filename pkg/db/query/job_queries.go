@@ -2,6 +2,8 @@ package query
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -110,7 +112,7 @@ WITH results AS (
         FROM prow_job_runs 
         JOIN prow_jobs 
                 ON prow_jobs.id = prow_job_runs.prow_job_id                 
-                                AND prow_jobs.release = @release
+                                AND `+db.RunRelease+` = @release
                 AND timestamp BETWEEN @start AND @end 
         group by variant
 )
@@ -133,9 +135,53 @@ ORDER BY current_pass_percentage ASC;
 		return nil, q.Error
 	}
 	q.Scan(&variantResults)
+	for i := range variantResults {
+		variantResults[i].SetConfidenceIntervals()
+	}
 	return variantResults, nil
 }
 
+// JobHourlyRuns are the runs, and passes, of a job in one hour of a report window. Current is true for the hours
+// after the boundary.
+type JobHourlyRuns struct {
+	Name    string
+	Hour    time.Time
+	Current bool
+	Runs    int
+	Passes  int
+}
+
+// JobHourlyResults returns the runs of each job in a release between start and end by hour, split at boundary, for
+// weighting pass rates by the age of their runs.
+func JobHourlyResults(dbc *db.DB, release string, start, boundary, end time.Time) ([]JobHourlyRuns, error) {
+	now := time.Now()
+	results := make([]JobHourlyRuns, 0)
+
+	res := dbc.DB.Raw(`
+SELECT prow_jobs.name,
+	date_trunc('hour', prow_job_runs.timestamp) AS hour,
+	prow_job_runs.timestamp >= @boundary AS current,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS passes
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+db.RunRelease+` = @release
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+GROUP BY 1, 2, 3`, map[string]interface{}{
+		"release":  release,
+		"start":    start,
+		"boundary": boundary,
+		"end":      end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	log.Infof("JobHourlyResults completed in %s with %d results from db", time.Since(now), len(results))
+	return results, nil
+}
+
 func ListFilteredJobIDs(dbc *db.DB, release string, fil *filter.Filter, start, boundary, end time.Time, limit int, sortField string, sort apitype.Sort) ([]int, error) {
 	table := dbc.DB.Table("job_results(?, ?, ?, ?)", release, start, boundary, end)
 
@@ -170,3 +216,51 @@ func LoadBugsForJobs(dbc *db.DB,
 	log.Infof("found %d bugs for job", len(job.Bugs))
 	return job.Bugs, nil
 }
+
+// ErrUnknownDurationGroup is returned when job run durations are grouped by anything but job or variant.
+var ErrUnknownDurationGroup = errors.New("unknown group, must be job or variant")
+
+// JobRunDurationPercentiles returns the P50/P90/P99 job run durations in seconds, grouped either by job name or by
+// variant, for runs in the given release between start and end.
+func JobRunDurationPercentiles(dbc *db.DB, release, groupBy string, start, end time.Time) ([]apitype.DurationPercentiles, error) {
+	now := time.Now()
+	results := make([]apitype.DurationPercentiles, 0)
+
+	var group string
+	switch groupBy {
+	case "job":
+		group = "prow_jobs.name"
+	case "variant":
+		group = "unnest(prow_jobs.variants)"
+	default:
+		return results, fmt.Errorf("%w: %q", ErrUnknownDurationGroup, groupBy)
+	}
+
+	// Durations are stored as nanoseconds.
+	runs := dbc.DB.Table("prow_job_runs").
+		Select(group+" AS name, prow_job_runs.duration / 1e9 AS seconds").
+		Joins("JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id").
+		Where(db.RunRelease+" = ?", release).
+		Where("prow_job_runs.timestamp BETWEEN ? AND ?", start, end).
+		Where("prow_job_runs.duration > 0").
+		Where("prow_job_runs.deleted_at IS NULL")
+
+	res := dbc.DB.Table("(?) AS runs", runs).
+		Select(`name,
+			COUNT(*) AS runs,
+			percentile_cont(0.50) WITHIN GROUP (ORDER BY seconds) AS p50,
+			percentile_cont(0.90) WITHIN GROUP (ORDER BY seconds) AS p90,
+			percentile_cont(0.99) WITHIN GROUP (ORDER BY seconds) AS p99`).
+		Group("name").
+		Order("p99 DESC").
+		Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"groups":  len(results),
+	}).Info("JobRunDurationPercentiles completed")
+	return results, nil
+}
@@ -0,0 +1,91 @@
+package query_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/test/golden"
+)
+
+func TestMain(m *testing.M) {
+	golden.Main(m)
+}
+
+func TestJobRunDurationPercentiles(t *testing.T) {
+	// the database is shared with other tests, so everything is created in a transaction that is rolled back, for a
+	// release no fixture uses
+	tx := golden.NewDB(t, "../../../test/golden/testdata/fixtures.json").DB.Begin()
+	defer tx.Rollback()
+	dbc := &db.DB{DB: tx}
+
+	const release = "4.99"
+	end := time.Now()
+	start := end.Add(-7 * 24 * time.Hour)
+	runs := 0
+	newJob := func(name string, variants ...string) models.ProwJob {
+		job := models.ProwJob{Kind: models.ProwPeriodic, Name: name, Release: release, Variants: pq.StringArray(variants)}
+		require.NoError(t, tx.Create(&job).Error)
+		return job
+	}
+	newRun := func(job models.ProwJob, seconds int, ago time.Duration) models.ProwJobRun {
+		runs++
+		run := models.ProwJobRun{
+			ProwJobID: job.ID,
+			URL:       fmt.Sprintf("https://example.com/%s/%d", job.Name, runs),
+			Timestamp: end.Add(-ago),
+			Duration:  time.Duration(seconds) * time.Second,
+		}
+		require.NoError(t, tx.Create(&run).Error)
+		return run
+	}
+
+	aws := newJob("duration-test-aws", "aws", "amd64")
+	for _, seconds := range []int{10, 20, 30, 40, 50} {
+		newRun(aws, seconds, time.Hour)
+	}
+	gcp := newJob("duration-test-gcp", "gcp", "amd64")
+	newRun(gcp, 100, time.Hour)
+	newRun(gcp, 0, time.Hour)          // still running, or its duration is unknown
+	newRun(gcp, 1000, 10*24*time.Hour) // before the window
+	deleted := newRun(gcp, 1000, time.Hour)
+	require.NoError(t, tx.Delete(&deleted).Error)
+
+	byJob, err := query.JobRunDurationPercentiles(dbc, release, "job", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, []apitype.DurationPercentiles{
+		{Name: gcp.Name, Runs: 1, P50: 100, P90: 100, P99: 100},
+		{Name: aws.Name, Runs: 5, P50: 30, P90: 46, P99: 49.6},
+	}, round(byJob))
+
+	byVariant, err := query.JobRunDurationPercentiles(dbc, release, "variant", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, []apitype.DurationPercentiles{
+		{Name: "gcp", Runs: 1, P50: 100, P90: 100, P99: 100},
+		{Name: "amd64", Runs: 6, P50: 35, P90: 75, P99: 97.5},
+		{Name: "aws", Runs: 5, P50: 30, P90: 46, P99: 49.6},
+	}, round(byVariant))
+}
+
+func TestJobRunDurationPercentilesUnknownGroup(t *testing.T) {
+	_, err := query.JobRunDurationPercentiles(nil, "4.16", "lane", time.Now().Add(-time.Hour), time.Now())
+	assert.ErrorIs(t, err, query.ErrUnknownDurationGroup)
+}
+
+// round rounds percentiles to a tenth of a second, as percentile_cont interpolates in floating point.
+func round(results []apitype.DurationPercentiles) []apitype.DurationPercentiles {
+	for i := range results {
+		results[i].P50 = float64(int(results[i].P50*10+0.5)) / 10
+		results[i].P90 = float64(int(results[i].P90*10+0.5)) / 10
+		results[i].P99 = float64(int(results[i].P99*10+0.5)) / 10
+	}
+	return results
+}
@@ -0,0 +1,103 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// JobRunSearch is the metadata to search job runs by. Empty fields match any run.
+type JobRunSearch struct {
+	Release string
+	Job     string
+	// Payload is the release tag of a payload the run was part of.
+	Payload string
+	// PullRequestLink, or org, repo and number, is a pull request the run tested.
+	PullRequestLink   string
+	PullRequestOrg    string
+	PullRequestRepo   string
+	PullRequestNumber int
+	BuildCluster      string
+	// Variants must all be variants of the run's job.
+	Variants []string
+	// Results are the overall results to match, i.e. F for test failures.
+	Results    []string
+	Start, End time.Time
+	Limit      int
+}
+
+// SearchJobRuns returns the most recent job runs between start and end matching the search, up to its limit.
+func SearchJobRuns(dbc *db.DB, search JobRunSearch) ([]apitype.JobRunSummary, error) {
+	now := time.Now()
+	runs := make([]apitype.JobRunSummary, 0)
+
+	variants := search.Variants
+	if variants == nil {
+		variants = []string{}
+	}
+	res := dbc.DB.Raw(`
+SELECT prow_job_runs.id,
+	prow_jobs.name AS job,
+	`+db.RunRelease+` AS release,
+	prow_jobs.variants,
+	prow_job_runs.cluster,
+	prow_job_runs.timestamp,
+	prow_job_runs.overall_result,
+	prow_job_runs.succeeded,
+	prow_job_runs.test_failures,
+	release_tags.release_tag AS payload,
+	(SELECT prow_pull_requests.link
+		FROM prow_job_run_prow_pull_requests
+		JOIN prow_pull_requests ON prow_pull_requests.id = prow_job_run_prow_pull_requests.prow_pull_request_id
+		WHERE prow_job_run_prow_pull_requests.prow_job_run_id = prow_job_runs.id
+		ORDER BY prow_pull_requests.id LIMIT 1) AS pull_request_link,
+	prow_job_runs.url
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+LEFT JOIN release_job_runs ON release_job_runs.prow_job_run_id = prow_job_runs.id AND release_job_runs.deleted_at IS NULL
+LEFT JOIN release_tags ON release_tags.id = release_job_runs.release_tag_id AND release_tags.deleted_at IS NULL
+WHERE prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND (@release = '' OR `+db.RunRelease+` = @release)
+	AND (@job = '' OR prow_jobs.name = @job)
+	AND (@payload = '' OR release_tags.release_tag = @payload)
+	AND (@build_cluster = '' OR prow_job_runs.cluster = @build_cluster)
+	AND prow_jobs.variants @> @variants
+	AND (cardinality(@results::text[]) = 0 OR prow_job_runs.overall_result = ANY(@results::text[]))
+	AND (@pr_link = '' AND @pr_org = '' OR EXISTS (
+		SELECT 1 FROM prow_job_run_prow_pull_requests
+		JOIN prow_pull_requests ON prow_pull_requests.id = prow_job_run_prow_pull_requests.prow_pull_request_id
+		WHERE prow_job_run_prow_pull_requests.prow_job_run_id = prow_job_runs.id
+			AND (@pr_link = '' OR prow_pull_requests.link = @pr_link)
+			AND (@pr_org = '' OR (prow_pull_requests.org = @pr_org AND prow_pull_requests.repo = @pr_repo
+				AND prow_pull_requests.number = @pr_number))))
+ORDER BY prow_job_runs.timestamp DESC
+LIMIT @limit`, map[string]interface{}{
+		"start":         search.Start,
+		"end":           search.End,
+		"release":       search.Release,
+		"job":           search.Job,
+		"payload":       search.Payload,
+		"build_cluster": search.BuildCluster,
+		"variants":      pq.StringArray(variants),
+		"results":       pq.StringArray(search.Results),
+		"pr_link":       search.PullRequestLink,
+		"pr_org":        search.PullRequestOrg,
+		"pr_repo":       search.PullRequestRepo,
+		"pr_number":     search.PullRequestNumber,
+		"limit":         search.Limit,
+	}).Scan(&runs)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(runs),
+	}).Info("SearchJobRuns completed")
+	return runs, nil
+}
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// LaneJobRuns are the runs, and passes, of one of a lane's jobs before and after the boundary.
+type LaneJobRuns struct {
+	Name           string
+	Release        string
+	CurrentRuns    int
+	CurrentPasses  int
+	PreviousRuns   int
+	PreviousPasses int
+}
+
+// LaneJobResults returns the runs of each of a lane's jobs between start and end, split at boundary, optionally
+// limited to a release. Jobs without runs in the window aren't returned.
+func LaneJobResults(dbc *db.DB, lane models.Lane, release string, start, boundary, end time.Time) ([]LaneJobRuns, error) {
+	now := time.Now()
+	results := make([]LaneJobRuns, 0)
+
+	res := dbc.DB.Raw(`
+SELECT prow_jobs.name,
+	`+db.RunRelease+` AS release,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp >= @boundary) AS current_runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp >= @boundary AND prow_job_runs.succeeded) AS current_passes,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp < @boundary) AS previous_runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp < @boundary AND prow_job_runs.succeeded) AS previous_passes
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE (prow_jobs.name IN @names OR (@pattern != '' AND prow_jobs.name ~ @pattern))
+	AND (@release = '' OR `+db.RunRelease+` = @release)
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+GROUP BY 1, 2
+ORDER BY release DESC, prow_jobs.name`, map[string]interface{}{
+		// IN () isn't valid, so always include an empty name, which matches no job
+		"names":    append([]string{""}, lane.JobNames...),
+		"pattern":  lane.JobPattern,
+		"release":  release,
+		"start":    start,
+		"boundary": boundary,
+		"end":      end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"lane":    lane.Name,
+		"jobs":    len(results),
+	}).Info("LaneJobResults completed")
+	return results, nil
+}
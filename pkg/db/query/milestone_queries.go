@@ -0,0 +1,91 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// PassRateCounts are the runs, successes and flakes of a component's tests or a variant's jobs.
+type PassRateCounts struct {
+	Name      string
+	Runs      int
+	Successes int
+	Flakes    int
+}
+
+// ComponentPassRates returns the results of each Jira component's tests in a release between start and end.
+// Tests without an owning component are not counted.
+func ComponentPassRates(dbc *db.DB, release string, start, end time.Time) ([]PassRateCounts, error) {
+	now := time.Now()
+	results := make([]PassRateCounts, 0)
+	res := dbc.DB.Raw(`
+SELECT test_ownerships.jira_component AS name,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = 1) AS successes,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = 13) AS flakes
+FROM prow_job_run_tests
+JOIN test_ownerships ON test_ownerships.test_id = prow_job_run_tests.test_id
+	AND test_ownerships.suite_id = prow_job_run_tests.suite_id
+JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+db.RunRelease+` = @release
+	AND prow_job_run_tests.created_at >= @start
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND test_ownerships.jira_component != ''
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY test_ownerships.jira_component
+ORDER BY test_ownerships.jira_component`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"start":     start,
+		"end":       end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("ComponentPassRates completed")
+	return results, nil
+}
+
+// VariantPassRates returns the results of each variant's job runs in a release between start and end.
+func VariantPassRates(dbc *db.DB, release string, start, end time.Time) ([]PassRateCounts, error) {
+	now := time.Now()
+	results := make([]PassRateCounts, 0)
+	res := dbc.DB.Raw(`
+SELECT variant AS name,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS successes,
+	0 AS flakes
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+CROSS JOIN LATERAL unnest(prow_jobs.variants) AS variant
+WHERE `+db.RunRelease+` = @release
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY variant
+ORDER BY variant`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"start":     start,
+		"end":       end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("VariantPassRates completed")
+	return results, nil
+}
@@ -0,0 +1,61 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// OperatorConditionSummaries reports how often each cluster operator went Degraded or Unavailable in a release,
+// by variant, as a percentage of runs with operator conditions loaded. Results can optionally be limited to a
+// single variant.
+func OperatorConditionSummaries(dbc *db.DB, release, variant string, start, end time.Time) ([]apitype.OperatorConditionSummary, error) {
+	now := time.Now()
+	results := make([]apitype.OperatorConditionSummary, 0)
+
+	res := dbc.DB.Raw(`
+WITH runs AS (
+	SELECT prow_job_runs.id, prow_job_runs.url, unnest(prow_jobs.variants) AS variant
+	FROM prow_job_runs
+	JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+	WHERE `+db.RunRelease+` = @release
+		AND prow_job_runs.timestamp BETWEEN @start AND @end
+		AND prow_job_runs.intervals_loaded
+		AND prow_job_runs.deleted_at IS NULL
+), totals AS (
+	SELECT variant, COUNT(*) AS total_runs
+	FROM runs
+	GROUP BY variant
+)
+SELECT conditions.operator,
+	conditions.condition,
+	runs.variant,
+	COUNT(DISTINCT runs.id) AS runs,
+	totals.total_runs,
+	COUNT(DISTINCT runs.id) * 100.0 / totals.total_runs AS percentage,
+	(array_agg(DISTINCT runs.url))[1:5] AS example_runs
+FROM prow_job_run_operator_conditions conditions
+JOIN runs ON runs.id = conditions.prow_job_run_id
+JOIN totals ON totals.variant = runs.variant
+WHERE conditions.deleted_at IS NULL
+	AND (@variant = '' OR runs.variant = @variant)
+GROUP BY conditions.operator, conditions.condition, runs.variant, totals.total_runs
+ORDER BY percentage DESC, runs DESC`, map[string]interface{}{
+		"release": release,
+		"variant": variant,
+		"start":   start,
+		"end":     end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("OperatorConditionSummaries completed")
+	return results, nil
+}
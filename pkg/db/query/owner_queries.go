@@ -0,0 +1,105 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// OwnerJobRuns are the runs of one job with an Owner variant before and after the boundary.
+type OwnerJobRuns struct {
+	Owner          string
+	JobName        string
+	PreviousRuns   int
+	PreviousPasses int
+	CurrentRuns    int
+	CurrentPasses  int
+}
+
+// OwnerJobs returns the runs of each job in a release by its Owner variant, previous being between start and
+// boundary and current between boundary and end.
+func OwnerJobs(dbc *db.DB, release string, start, boundary, end time.Time) ([]OwnerJobRuns, error) {
+	now := time.Now()
+	results := make([]OwnerJobRuns, 0)
+	res := dbc.DB.Raw(`
+SELECT substring(variant FROM 7) AS owner,
+	prow_jobs.name AS job_name,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp < @boundary) AS previous_runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp < @boundary AND prow_job_runs.succeeded) AS previous_passes,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp >= @boundary) AS current_runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.timestamp >= @boundary AND prow_job_runs.succeeded) AS current_passes
+FROM prow_jobs
+CROSS JOIN LATERAL unnest(prow_jobs.variants) AS variant
+JOIN prow_job_runs ON prow_job_runs.prow_job_id = prow_jobs.id
+WHERE `+db.RunRelease+` = @release
+	AND variant LIKE 'Owner:%'
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY owner, prow_jobs.name
+ORDER BY owner, prow_jobs.name`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"start":     start,
+		"boundary":  boundary,
+		"end":       end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("OwnerJobs completed")
+	return results, nil
+}
+
+// OwnerTestResults are the test results in the jobs of an Owner variant.
+type OwnerTestResults struct {
+	Owner  string
+	Runs   int
+	Flakes int
+}
+
+// OwnerTestFlakes returns how many test results in each Owner variant's jobs in a release between start and end
+// were flakes.
+func OwnerTestFlakes(dbc *db.DB, release string, start, end time.Time) ([]OwnerTestResults, error) {
+	now := time.Now()
+	results := make([]OwnerTestResults, 0)
+	res := dbc.DB.Raw(`
+SELECT substring(variant FROM 7) AS owner,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @flake) AS flakes
+FROM prow_job_run_tests
+JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+CROSS JOIN LATERAL unnest(prow_jobs.variants) AS variant
+WHERE `+db.RunRelease+` = @release
+	AND variant LIKE 'Owner:%'
+	AND prow_job_run_tests.created_at >= @start
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_run_tests.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY owner
+ORDER BY owner`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"start":     start,
+		"end":       end,
+		"flake":     v1.TestStatusFlake,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("OwnerTestFlakes completed")
+	return results, nil
+}
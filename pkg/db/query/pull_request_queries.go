@@ -7,6 +7,7 @@ import (
 
 	"github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/filter"
 )
 
@@ -27,7 +28,7 @@ func PullRequestReport(dbc *db.DB, filterOpts *filter.FilterOptions, release str
 		Joins("INNER JOIN prow_job_run_prow_pull_requests ON prow_job_run_prow_pull_requests.prow_pull_request_id = prow_pull_requests.id").
 		Joins("INNER JOIN prow_job_runs on prow_job_run_prow_pull_requests.prow_job_run_id = prow_job_runs.id").
 		Joins("INNER JOIN prow_jobs on prow_job_runs.prow_job_id = prow_jobs.id").
-		Where("prow_jobs.release = ?", release).
+		Where(db.RunRelease+" = ?", release).
 		Select("DISTINCT ON(prow_pull_requests.link) prow_pull_requests.*, ci.release_tag AS first_ci_payload, ci.phase AS first_ci_payload_phase, ci.release as first_ci_payload_release, nightly.release_tag as first_nightly_payload, nightly.phase as first_nightly_payload_phase, nightly.release as first_nightly_payload_release")
 
 	results := make([]api.PullRequest, 0)
@@ -62,3 +63,23 @@ func PullRequestAveragePremergeFailures(dbc *db.DB, start, end *time.Time) *gorm
 		Select("org, repo, prow_job_id, prow_job_name, AVG(total_runs) as average_premerge_job_failures").
 		Group("prow_job_id, prow_job_name, org, repo")
 }
+
+// FirstPayloadFixingBug returns the earliest accepted payload of the release that includes a pull request for the
+// JIRA issue with the given key, or nil if there isn't one yet.
+func FirstPayloadFixingBug(dbc *db.DB, release, key string) (*models.ReleaseTag, error) {
+	tags := []models.ReleaseTag{}
+	res := dbc.DB.Table("release_tags").
+		Select("release_tags.*").
+		Joins("JOIN release_tag_pull_requests ON release_tag_pull_requests.release_tag_id = release_tags.id").
+		Joins("JOIN release_pull_requests ON release_pull_requests.id = release_tag_pull_requests.release_pull_request_id").
+		Where("release_tags.release = ?", release).
+		Where("release_tags.phase = ?", "Accepted").
+		Where("release_pull_requests.bug_url LIKE ?", "%/"+key).
+		Order("release_tags.release_time").
+		Limit(1).
+		Scan(&tags)
+	if res.Error != nil || len(tags) == 0 {
+		return nil, res.Error
+	}
+	return &tags[0], nil
+}
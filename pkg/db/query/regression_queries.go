@@ -0,0 +1,63 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// TestRegressions returns the tracked regressions for a release that were open at any point since the given time,
+// optionally limited to a component.
+func TestRegressions(dbc *db.DB, release, component string, since time.Time) ([]models.TestRegression, error) {
+	now := time.Now()
+	regressions := make([]models.TestRegression, 0)
+	q := dbc.DB.Model(&models.TestRegression{}).
+		Where("release = ?", release).
+		Where("closed IS NULL OR closed >= ?", since)
+	if component != "" {
+		q = q.Where("component = ?", component)
+	}
+	res := q.Order("opened").Find(&regressions)
+	log.WithFields(log.Fields{
+		"release":     release,
+		"component":   component,
+		"regressions": len(regressions),
+		"elapsed":     time.Since(now),
+	}).Debug("TestRegressions completed")
+	return regressions, res.Error
+}
+
+// ComponentLeads maps Jira component names to their lead, so regressions can be grouped by the team that owns
+// them.
+func ComponentLeads(dbc *db.DB) (map[string]string, error) {
+	var components []models.JiraComponent
+	res := dbc.DB.Select("name", "lead_name").Find(&components)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	leads := make(map[string]string, len(components))
+	for _, c := range components {
+		leads[c.Name] = c.LeadName
+	}
+	return leads, nil
+}
+
+// ComponentBugBacklogs returns the open bug backlog of every component, or just one if component is set.
+func ComponentBugBacklogs(dbc *db.DB, component string) ([]models.ComponentBugBacklog, error) {
+	now := time.Now()
+	backlogs := make([]models.ComponentBugBacklog, 0)
+	q := dbc.DB.Model(&models.ComponentBugBacklog{})
+	if component != "" {
+		q = q.Where("component = ?", component)
+	}
+	res := q.Order("component").Find(&backlogs)
+	log.WithFields(log.Fields{
+		"component": component,
+		"backlogs":  len(backlogs),
+		"elapsed":   time.Since(now),
+	}).Debug("ComponentBugBacklogs completed")
+	return backlogs, res.Error
+}
@@ -28,3 +28,25 @@ func ReleasesFromDB(dbClient *db.DB) ([]Release, error) {
 	}
 	return releases, nil
 }
+
+// BlockingJobNames returns the names of the jobs that blocked a release's payloads created since the given time.
+func BlockingJobNames(dbClient *db.DB, release string, since time.Time) ([]string, error) {
+	names := make([]string, 0)
+	res := dbClient.DB.Raw(`
+		SELECT DISTINCT rjr.job_name
+		FROM release_job_runs rjr
+		JOIN release_tags rt ON rt.id = rjr.release_tag_id
+		WHERE rt.release = @release
+			AND rt.release_time >= @since
+			AND rjr.kind = 'Blocking'
+			AND rjr.deleted_at IS NULL
+		ORDER BY rjr.job_name`, map[string]interface{}{
+		"release": release,
+		"since":   since,
+	}).Scan(&names)
+	if res.Error != nil {
+		log.Errorf("error querying blocking jobs from db: %v", res.Error)
+		return names, res.Error
+	}
+	return names, nil
+}
@@ -0,0 +1,76 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// WeekCounts are the test results of one week relative to a release's GA date. Week 0 starts at GA, week -1 is the
+// week before it.
+type WeekCounts struct {
+	Week     int
+	Runs     int
+	Passes   int
+	Flakes   int
+	Failures int
+}
+
+// ReleaseTrendWeeks returns the weekly results of a test, or of all the tests a Jira component owns, in a release
+// from weeksBefore weeks before GA until weeksAfter weeks after it. Weeks without results are omitted.
+func ReleaseTrendWeeks(dbc *db.DB, release, test, component string, ga time.Time, weeksBefore, weeksAfter int) ([]WeekCounts, error) {
+	now := time.Now()
+	results := make([]WeekCounts, 0)
+
+	join := "JOIN tests ON tests.id = prow_job_run_tests.test_id"
+	where := "tests.name = @selected"
+	selected := test
+	if test == "" {
+		join = `JOIN test_ownerships ON test_ownerships.test_id = prow_job_run_tests.test_id
+	AND test_ownerships.suite_id = prow_job_run_tests.suite_id`
+		where = "test_ownerships.jira_component = @selected"
+		selected = component
+	}
+
+	res := dbc.DB.Raw(`
+SELECT floor(extract(epoch FROM prow_job_runs.timestamp - @ga::timestamp) / 604800)::int AS week,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @success) AS passes,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @flake) AS flakes,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @failure) AS failures
+FROM prow_job_run_tests
+`+join+`
+JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+where+`
+	AND `+db.RunRelease+` = @release
+	AND prow_job_runs.timestamp >= @start AND prow_job_runs.timestamp < @end
+	AND prow_job_run_tests.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY week
+ORDER BY week`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"selected":  selected,
+		"release":   release,
+		"ga":        ga,
+		"start":     ga.AddDate(0, 0, -7*weeksBefore),
+		"end":       ga.AddDate(0, 0, 7*weeksAfter),
+		"success":   v1.TestStatusSuccess,
+		"flake":     v1.TestStatusFlake,
+		"failure":   v1.TestStatusFailure,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"release": release,
+		"rows":    len(results),
+	}).Info("ReleaseTrendWeeks completed")
+	return results, nil
+}
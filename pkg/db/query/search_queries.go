@@ -0,0 +1,100 @@
+package query
+
+import (
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// SearchMatch is a record whose name contains all the terms of a search.
+type SearchMatch struct {
+	ID        uint
+	Name      string
+	Release   string
+	Component string
+	Detail    string
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// matchingNames limits a query to rows whose name column contains every term, case insensitively, and returns up
+// to limit of them: exact matches first, then names starting with the terms, then the shortest names.
+func matchingNames(q *gorm.DB, column string, terms []string, limit int) ([]SearchMatch, error) {
+	for _, term := range terms {
+		q = q.Where(column+" ILIKE ?", "%"+likeEscaper.Replace(term)+"%")
+	}
+	phrase := strings.ToLower(strings.Join(terms, " "))
+	q = q.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "LOWER(" + column + ") = ? DESC, LOWER(" + column + ") LIKE ? DESC, LENGTH(" + column + "), " + column,
+		Vars:               []interface{}{phrase, likeEscaper.Replace(phrase) + "%"},
+		WithoutParentheses: true,
+	}})
+
+	matches := make([]SearchMatch, 0)
+	res := q.Limit(limit).Scan(&matches)
+	return matches, res.Error
+}
+
+// SearchEntities returns up to limit records of each searchable kind whose name contains all terms. Jobs, variants,
+// regressions and incidents are limited to a release when one is given, tests and components are not release
+// specific.
+func SearchEntities(dbc *db.DB, kinds []string, terms []string, release string, limit int) (map[string][]SearchMatch, error) {
+	now := time.Now()
+	results := map[string][]SearchMatch{}
+	for _, kind := range kinds {
+		var q *gorm.DB
+		column := "name"
+		switch kind {
+		case apitype.SearchKindTests:
+			q = dbc.DB.Model(&models.Test{}).Select("id, name")
+		case apitype.SearchKindJobs:
+			q = dbc.DB.Model(&models.ProwJob{}).Select("id, name, release")
+			if release != "" {
+				q = q.Where("release = ?", release)
+			}
+		case apitype.SearchKindComponents:
+			q = dbc.DB.Model(&models.JiraComponent{}).Select("id, name, lead_name AS detail")
+		case apitype.SearchKindVariants:
+			sub := dbc.DB.Model(&models.ProwJob{}).Select("unnest(variants) AS name")
+			if release != "" {
+				sub = sub.Where("release = ?", release)
+			}
+			q = dbc.DB.Table("(?) AS variants", sub).Select("name, COUNT(*) || ' jobs' AS detail").Group("name")
+		case apitype.SearchKindRegressions:
+			column = "test_name"
+			q = dbc.DB.Model(&models.TestRegression{}).
+				Select("id, test_name AS name, release, component, " +
+					"view || CASE WHEN closed IS NULL THEN ', open' ELSE ', closed' END AS detail")
+			if release != "" {
+				q = q.Where("release = ?", release)
+			}
+		case apitype.SearchKindIncidents:
+			column = "title"
+			q = dbc.DB.Model(&models.Incident{}).Select("id, title AS name, release, kind AS detail")
+			if release != "" {
+				q = q.Where("release = '' OR release = ?", release)
+			}
+		default:
+			continue
+		}
+
+		matches, err := matchingNames(q, column, terms, limit)
+		if err != nil {
+			return nil, err
+		}
+		results[kind] = matches
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"kinds":   len(results),
+	}).Info("SearchEntities completed")
+	return results, nil
+}
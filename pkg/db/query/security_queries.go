@@ -0,0 +1,112 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// SecurityModeJobRuns are the runs of one job, with its SecurityMode variant value.
+type SecurityModeJobRuns struct {
+	JobName      string
+	SecurityMode string
+	Variants     pq.StringArray `gorm:"type:text[]"`
+	Runs         int
+	Passes       int
+}
+
+// SecurityModeJobs returns the runs of each job with a SecurityMode variant in a release between start and end.
+func SecurityModeJobs(dbc *db.DB, release string, start, end time.Time) ([]SecurityModeJobRuns, error) {
+	now := time.Now()
+	results := make([]SecurityModeJobRuns, 0)
+	res := dbc.DB.Raw(`
+SELECT prow_jobs.name AS job_name,
+	substring(variant FROM 14) AS security_mode,
+	prow_jobs.variants,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS passes
+FROM prow_jobs
+CROSS JOIN LATERAL unnest(prow_jobs.variants) AS variant
+JOIN prow_job_runs ON prow_job_runs.prow_job_id = prow_jobs.id
+WHERE `+db.RunRelease+` = @release
+	AND variant LIKE 'SecurityMode:%'
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY prow_jobs.name, security_mode, prow_jobs.variants
+ORDER BY prow_jobs.name`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"start":     start,
+		"end":       end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("SecurityModeJobs completed")
+	return results, nil
+}
+
+// SecurityModeTestResults are the results of a test in the jobs of a security mode and in the baseline jobs.
+type SecurityModeTestResults struct {
+	TestName       string
+	Runs           int
+	Passes         int
+	BaselineRuns   int
+	BaselinePasses int
+}
+
+// SecurityModeTests returns the results of each test run at least minRuns times in the jobs with the SecurityMode
+// variant value mode, alongside its results in the jobs with the baseline value. Flakes count as passes.
+func SecurityModeTests(dbc *db.DB, release, mode, baseline string, start, end time.Time, minRuns int) ([]SecurityModeTestResults, error) {
+	now := time.Now()
+	results := make([]SecurityModeTestResults, 0)
+	res := dbc.DB.Raw(`
+SELECT tests.name AS test_name,
+	COUNT(*) FILTER (WHERE @mode = ANY(prow_jobs.variants)) AS runs,
+	COUNT(*) FILTER (WHERE @mode = ANY(prow_jobs.variants) AND prow_job_run_tests.status IN (@success, @flake)) AS passes,
+	COUNT(*) FILTER (WHERE @baseline = ANY(prow_jobs.variants)) AS baseline_runs,
+	COUNT(*) FILTER (WHERE @baseline = ANY(prow_jobs.variants) AND prow_job_run_tests.status IN (@success, @flake)) AS baseline_passes
+FROM prow_job_run_tests
+JOIN tests ON tests.id = prow_job_run_tests.test_id
+JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+db.RunRelease+` = @release
+	AND prow_jobs.variants && ARRAY[@mode, @baseline]::text[]
+	AND prow_job_run_tests.created_at >= @start
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_run_tests.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY tests.name
+HAVING COUNT(*) FILTER (WHERE @mode = ANY(prow_jobs.variants)) >= @min_runs
+ORDER BY tests.name`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"mode":      "SecurityMode:" + mode,
+		"baseline":  "SecurityMode:" + baseline,
+		"start":     start,
+		"end":       end,
+		"min_runs":  minRuns,
+		"success":   v1.TestStatusSuccess,
+		"flake":     v1.TestStatusFlake,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"mode":    mode,
+		"rows":    len(results),
+	}).Info("SecurityModeTests completed")
+	return results, nil
+}
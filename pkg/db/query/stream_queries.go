@@ -0,0 +1,98 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// streamJobPattern matches the payload stream in job names like periodic-ci-openshift-release-master-nightly-4.16-e2e-aws,
+// capturing the stream.
+const streamJobPattern = `-master-(ci|nightly)-[0-9]+\.[0-9]+-`
+
+// StreamRuns are the runs and successes of a job, or of a test across a stream's jobs, in a payload stream.
+type StreamRuns struct {
+	Name      string
+	Stream    string
+	Runs      int
+	Successes int
+}
+
+// StreamJobRuns returns the runs of every nightly and ci stream job in a release.
+func StreamJobRuns(dbc *db.DB, release string, start, end time.Time) ([]StreamRuns, error) {
+	now := time.Now()
+	results := make([]StreamRuns, 0)
+	res := dbc.DB.Raw(`
+SELECT prow_jobs.name,
+	substring(prow_jobs.name from @pattern) AS stream,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS successes
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+db.RunRelease+` = @release
+	AND prow_jobs.name ~ @pattern
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+GROUP BY prow_jobs.name`, map[string]interface{}{
+		"release": release,
+		"pattern": streamJobPattern,
+		"start":   start,
+		"end":     end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("StreamJobRuns completed")
+	return results, nil
+}
+
+// StreamTestRuns returns the runs of every test per stream, counting only jobs that run in both the nightly and ci
+// streams so the streams are compared like for like. Flakes count as successes. Test results are only available
+// for the last 14 days.
+func StreamTestRuns(dbc *db.DB, release string, start, end time.Time) ([]StreamRuns, error) {
+	now := time.Now()
+	results := make([]StreamRuns, 0)
+	res := dbc.DB.Raw(`
+WITH jobs AS (
+	SELECT name,
+		substring(name from @pattern) AS stream,
+		regexp_replace(name, '-master-(ci|nightly)-', '-master-') AS pair
+	FROM prow_jobs
+	WHERE release = @release
+		AND name ~ @pattern
+		AND deleted_at IS NULL
+), paired AS (
+	SELECT name, stream
+	FROM jobs
+	WHERE pair IN (SELECT pair FROM jobs GROUP BY pair HAVING COUNT(DISTINCT stream) = 2)
+)
+SELECT results.test_name AS name,
+	paired.stream,
+	SUM(results.runs) AS runs,
+	SUM(results.passes) + SUM(results.flakes) AS successes
+FROM prow_test_analysis_by_job_14d_matview results
+JOIN paired ON paired.name = results.job_name
+WHERE results.release = @release
+	AND results.date BETWEEN @start AND @end
+GROUP BY results.test_name, paired.stream`, map[string]interface{}{
+		"release": release,
+		"pattern": streamJobPattern,
+		"start":   start,
+		"end":     end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("StreamTestRuns completed")
+	return results, nil
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/testidentification"
+)
+
+// TestEvidence is the results of a test in the jobs with one combination of variants.
+type TestEvidence struct {
+	TestName  string
+	SuiteName string
+	Variants  pq.StringArray `gorm:"type:text[]"`
+	Runs      int
+	Passes    int
+	Failures  int
+	Flakes    int
+	// LastRunURL and LastFailureURL link to the most recent job runs with the test, and with the test failing.
+	LastRun        time.Time
+	LastRunURL     string
+	LastFailureURL string
+}
+
+// TestEvidenceByVariants returns the results of each test matching the regex in a release between start and end,
+// for each combination of variants of the jobs that ran it. Only jobs with all the given variants are included.
+func TestEvidenceByVariants(dbc *db.DB, release, testRegex string, variants []string, start, end time.Time) ([]TestEvidence, error) {
+	now := time.Now()
+	results := make([]TestEvidence, 0)
+	res := dbc.DB.Raw(`
+SELECT tests.name AS test_name,
+	COALESCE(suites.name, '') AS suite_name,
+	prow_jobs.variants,
+	COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @success) AS passes,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @failure) AS failures,
+	COUNT(*) FILTER (WHERE prow_job_run_tests.status = @flake) AS flakes,
+	MAX(prow_job_runs.timestamp) AS last_run,
+	(array_agg(prow_job_runs.url ORDER BY prow_job_runs.timestamp DESC))[1] AS last_run_url,
+	COALESCE((array_agg(prow_job_runs.url ORDER BY prow_job_runs.timestamp DESC)
+		FILTER (WHERE prow_job_run_tests.status = @failure))[1], '') AS last_failure_url
+FROM prow_job_run_tests
+JOIN tests ON tests.id = prow_job_run_tests.test_id
+LEFT JOIN suites ON suites.id = prow_job_run_tests.suite_id
+JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+db.RunRelease+` = @release
+	AND tests.name ~* @test
+	AND prow_jobs.variants @> @variants
+	AND prow_job_run_tests.created_at >= @start
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_run_tests.deleted_at IS NULL
+	AND NOT (@rehearsal = ANY(prow_jobs.variants))
+GROUP BY tests.name, suites.name, prow_jobs.variants
+ORDER BY tests.name, suites.name, prow_jobs.variants`, map[string]interface{}{
+		"rehearsal": testidentification.Rehearsal,
+		"release":   release,
+		"test":      testRegex,
+		"variants":  pq.StringArray(variants),
+		"start":     start,
+		"end":       end,
+		"success":   v1.TestStatusSuccess,
+		"failure":   v1.TestStatusFailure,
+		"flake":     v1.TestStatusFlake,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("TestEvidenceByVariants completed")
+	return results, nil
+}
@@ -0,0 +1,82 @@
+package query
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// TestCoFailures counts, for each pair of tests that failed together in at least minCoFailures runs, how often they
+// failed together and individually. Runs with more than maxRunFailures failed tests are excluded, as a run where
+// everything fails says nothing about the relationship between any two tests. For the same reason, cascade failures
+// are ignored. Only the count fields of the results are populated.
+func TestCoFailures(dbc *db.DB, release, variant string, start, end time.Time, minCoFailures, maxRunFailures int, mode apitype.PassRateMode) ([]apitype.TestInteraction, error) {
+	now := time.Now()
+	results := make([]apitype.TestInteraction, 0)
+
+	res := dbc.DB.Raw(`
+WITH runs AS (
+	SELECT prow_job_runs.id
+	FROM prow_job_runs
+	JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+	WHERE `+db.RunRelease+` = @release
+		AND (@variant = '' OR @variant = ANY(prow_jobs.variants))
+		AND prow_job_runs.timestamp BETWEEN @start AND @end
+		AND prow_job_runs.deleted_at IS NULL
+		AND prow_job_runs.test_failures <= @max_run_failures
+), failures AS (
+	SELECT DISTINCT prow_job_run_tests.prow_job_run_id, prow_job_run_tests.test_id
+	FROM prow_job_run_tests
+	JOIN runs ON runs.id = prow_job_run_tests.prow_job_run_id
+	WHERE prow_job_run_tests.status = @failure
+		AND NOT prow_job_run_tests.cascade_failure
+		AND NOT (@lenient AND prow_job_run_tests.payload_flake)
+		AND prow_job_run_tests.deleted_at IS NULL
+), test_failures AS (
+	SELECT test_id, COUNT(*) AS failures
+	FROM failures
+	GROUP BY test_id
+	HAVING COUNT(*) >= @min_co_failures
+), pairs AS (
+	SELECT a.test_id AS test_a, b.test_id AS test_b, COUNT(*) AS co_failures
+	FROM failures a
+	JOIN failures b ON a.prow_job_run_id = b.prow_job_run_id AND a.test_id < b.test_id
+	WHERE a.test_id IN (SELECT test_id FROM test_failures)
+		AND b.test_id IN (SELECT test_id FROM test_failures)
+	GROUP BY a.test_id, b.test_id
+	HAVING COUNT(*) >= @min_co_failures
+)
+SELECT tests_a.name AS test_a,
+	tests_b.name AS test_b,
+	pairs.co_failures,
+	failures_a.failures AS failures_a,
+	failures_b.failures AS failures_b,
+	(SELECT COUNT(*) FROM runs) AS runs
+FROM pairs
+JOIN tests tests_a ON tests_a.id = pairs.test_a
+JOIN tests tests_b ON tests_b.id = pairs.test_b
+JOIN test_failures failures_a ON failures_a.test_id = pairs.test_a
+JOIN test_failures failures_b ON failures_b.test_id = pairs.test_b`, map[string]interface{}{
+		"release":          release,
+		"variant":          variant,
+		"start":            start,
+		"end":              end,
+		"failure":          v1.TestStatusFailure,
+		"min_co_failures":  minCoFailures,
+		"max_run_failures": maxRunFailures,
+		"lenient":          mode == apitype.PassRateLenient,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"pairs":   len(results),
+	}).Info("TestCoFailures completed")
+	return results, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/testidentification"
 )
 
 const (
@@ -58,6 +59,22 @@ const (
 	QueryTestAnalysis = "select current_successes, current_runs, current_successes * 100.0 / NULLIF(current_runs, 0) AS current_pass_percentage from ( select sum(runs) as current_runs, sum(passes) as current_successes from prow_test_analysis_by_job_14d_matview where test_name = '%s' AND job_name in (%s))t"
 )
 
+// TestReportSource returns the table expression to read a test report matview from. In lenient mode, failures
+// where the test passed elsewhere in the same payload are moved from failures to flakes. The result is aliased to
+// the matview name, so columns may still be qualified with it.
+func TestReportSource(table string, mode api.PassRateMode) string {
+	if mode != api.PassRateLenient {
+		return table
+	}
+	return fmt.Sprintf(`(SELECT id, name, watchlist, suite_name, jira_component, jira_component_id,
+		previous_successes, previous_flakes + previous_payload_flakes AS previous_flakes,
+		previous_failures - previous_payload_flakes AS previous_failures, previous_runs,
+		current_successes, current_flakes + current_payload_flakes AS current_flakes,
+		current_failures - current_payload_flakes AS current_failures, current_runs,
+		open_bugs, variants, release
+	FROM %s) AS %s`, table, table)
+}
+
 // TestReportsByVariant returns a test report for every test in the db matching the given substrings, separated by variant.
 func TestReportsByVariant(
 	dbc *db.DB,
@@ -118,6 +135,10 @@ FROM results;
 		return testReports, r.Error
 	}
 
+	for i := range testReports {
+		testReports[i].SetConfidenceIntervals()
+	}
+
 	elapsed := time.Since(now)
 	log.Infof("TestReportsByVariant completed in %s with %d results from db", elapsed, len(testReports))
 	return testReports, nil
@@ -164,6 +185,7 @@ func TestReportExcludeVariants(
 		log.Error(r.Error)
 		return testReport, r.Error
 	}
+	testReport.SetConfidenceIntervals()
 
 	elapsed := time.Since(now)
 	log.Infof("TestReportExcludeVariants completed in %s", elapsed)
@@ -201,9 +223,11 @@ func LoadBugsForTest(dbc *db.DB, testName string, filterClosed bool) ([]models.B
 // flake_average shows the average flake percentage among all variants.
 // flake_standard_deviation shows the standard deviation of the flake percentage among variants. The number reflects how much flake percentage differs among variants.
 // delta_from_flake_average shows how much each variant differs from the flake_average. This can be used to identify outliers.
-func TestsByNURPAndStandardDeviation(dbc *db.DB, release, table string) *gorm.DB {
+func TestsByNURPAndStandardDeviation(dbc *db.DB, release, table string, mode api.PassRateMode) *gorm.DB {
+	source := TestReportSource(table, mode)
+
 	// 1. Create a virtual stats table. There is a single row for each test.
-	stats := dbc.DB.Table(table).
+	stats := dbc.DB.Table(source).
 		Select(`
                  id                                                                             AS test_id,
                  suite_name                                                                     AS stats_suite_name,
@@ -217,18 +241,19 @@ func TestsByNURPAndStandardDeviation(dbc *db.DB, release, table string) *gorm.DB
 		Group("id, suite_name")
 
 	// 2. Collect standard stats for all tests. Each row applies to one variant of a test.
-	passRates := dbc.DB.Table(table).
+	passRates := dbc.DB.Table(source).
 		Select(`id as test_id, suite_name as pass_rate_suite_name, variants as pass_rate_variants, `+QueryTestPercentages).
 		Where(`release = ?`, release)
 
 	// 3. Join the tables to produce test report. Each row represent one variant of a test and contains all stats, both unique to the specific variant and average across all variants.
 	return dbc.DB.
-		Table(table).
+		Table(source).
 		Select("*, (current_working_percentage - working_average) as delta_from_working_average, (current_pass_percentage - passing_average) as delta_from_passing_average, (current_flake_percentage - flake_average) as delta_from_flake_average").
 		Joins(fmt.Sprintf(`INNER JOIN (?) as pass_rates on pass_rates.test_id = %s.id AND pass_rates.pass_rate_suite_name IS NOT DISTINCT FROM %s.suite_name AND pass_rates.pass_rate_variants = %s.variants`, table, table, table), passRates).
 		Joins(fmt.Sprintf(`JOIN (?) as stats ON stats.test_id = %s.id AND stats.stats_suite_name IS NOT DISTINCT FROM %s.suite_name`, table, table), stats).
 		Where(`release = ?`, release).
-		Where(fmt.Sprintf("NOT ('never-stable'=any(%s.variants))", table))
+		Where(fmt.Sprintf("NOT ('never-stable'=any(%s.variants))", table)).
+		Where(fmt.Sprintf("NOT (?=any(%s.variants))", table), testidentification.Rehearsal)
 }
 
 func TestOutputs(dbc *db.DB, release, test string, includedVariants, excludedVariants []string, quantity int) ([]api.TestOutput, error) {
@@ -241,7 +266,7 @@ func TestOutputs(dbc *db.DB, release, test string, includedVariants, excludedVar
 		Joins("JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id").
 		Where("prow_job_runs.timestamp > current_date - interval '14' day").
 		Where("prow_job_run_tests.test_id = (?)", testQuery).
-		Where("prow_jobs.release = ?", release)
+		Where(db.RunRelease+" = ?", release)
 
 	for _, variant := range includedVariants {
 		q = q.Where("? = any(prow_jobs.variants)", variant)
@@ -275,7 +300,7 @@ func TestDurations(dbc *db.DB, release, test string, includedVariants, excludedV
 		Joins("JOIN prow_jobs ON prow_job_runs.prow_job_id = prow_jobs.id").
 		Where("prow_job_runs.timestamp > current_date - interval '14' day").
 		Where("prow_job_run_tests.test_id = (?)", testQuery).
-		Where("prow_jobs.release = ?", release)
+		Where(db.RunRelease+" = ?", release)
 
 	for _, variant := range includedVariants {
 		q = q.Where("? = any(prow_jobs.variants)", variant)
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestTestReportSource(t *testing.T) {
+	const table = "prow_test_report_7d_matview"
+	tests := []struct {
+		mode        api.PassRateMode
+		contains    []string
+		notContains []string
+	}{
+		{
+			mode:        api.PassRateStrict,
+			notContains: []string{"payload_flakes"},
+		},
+		{
+			mode: api.PassRateLenient,
+			contains: []string{
+				"current_flakes + current_payload_flakes AS current_flakes",
+				"current_failures - current_payload_flakes AS current_failures",
+				"previous_flakes + previous_payload_flakes AS previous_flakes",
+				"previous_failures - previous_payload_flakes AS previous_failures",
+				"FROM " + table + ") AS " + table,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			source := TestReportSource(table, tc.mode)
+			if tc.mode == api.PassRateStrict {
+				assert.Equal(t, table, source)
+			}
+			for _, s := range tc.contains {
+				assert.Contains(t, source, s)
+			}
+			for _, s := range tc.notContains {
+				assert.NotContains(t, source, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// TestRun is the result of a test in one job run.
+type TestRun struct {
+	ProwJobRunID uint
+	JobName      string
+	URL          string
+	Timestamp    time.Time
+	Status       int
+}
+
+// TestRecentRuns returns the results of a test in its most recent job runs in a release since a time, newest
+// first, limited to jobs with all the given variants. Results are first narrowed by test and import time, which
+// idx_prow_job_run_tests_test_id_created_at covers, so the query stays cheap for tests with long histories.
+func TestRecentRuns(dbc *db.DB, release, test string, variants []string, since time.Time, limit int) ([]TestRun, error) {
+	now := time.Now()
+	results := make([]TestRun, 0)
+	res := dbc.DB.Raw(`
+SELECT prow_job_runs.id AS prow_job_run_id,
+	prow_jobs.name AS job_name,
+	prow_job_runs.url,
+	prow_job_runs.timestamp,
+	prow_job_run_tests.status
+FROM prow_job_run_tests
+JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE prow_job_run_tests.test_id = (SELECT id FROM tests WHERE name = @test)
+	AND prow_job_run_tests.created_at >= @since
+	AND prow_job_run_tests.deleted_at IS NULL
+	AND prow_job_runs.timestamp >= @since
+	AND `+db.RunRelease+` = @release
+	AND prow_jobs.variants @> @variants
+ORDER BY prow_job_runs.timestamp DESC
+LIMIT @limit`, map[string]interface{}{
+		"release":  release,
+		"test":     test,
+		"variants": pq.StringArray(variants),
+		"since":    since,
+		"limit":    limit,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("TestRecentRuns completed")
+	return results, nil
+}
@@ -0,0 +1,140 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/util"
+)
+
+// PassRateTimeSeries returns pass/fail/flake counts bucketed by the given granularity between start and end. Buckets
+// are generated with generate_series and left joined against the results, so periods with no runs are returned with
+// zero counts rather than being omitted. Days and weeks start at midnight in the given location, so charts can align
+// with a working day other than UTC's.
+//
+// A build cluster limits any selector to the runs that executed on that cluster. A job selected in a release includes
+// the runs of its names before the release branch was cut.
+//
+// For a test selector, counts are of test results (flakes are possible), otherwise counts are of job runs. If the
+// selector excludes incidents, runs during an incident affecting their job are not counted. If only primary failures
+// are requested, test failures caused by an earlier failure in the same run are not counted. In lenient mode, test
+// failures that passed elsewhere in the same payload are counted as flakes.
+func PassRateTimeSeries(dbc *db.DB, selector apitype.TimeSeriesSelector, granularity apitype.TimeSeriesGranularity, loc *time.Location, start, end time.Time) ([]apitype.TimeSeriesBucket, error) {
+	now := time.Now()
+	buckets := make([]apitype.TimeSeriesBucket, 0)
+
+	switch granularity {
+	case apitype.TimeSeriesHour, apitype.TimeSeriesDay, apitype.TimeSeriesWeek:
+	default:
+		return buckets, fmt.Errorf("unknown granularity %q", granularity)
+	}
+
+	exclude := "TRUE"
+	if selector.ExcludeIncidents {
+		exclude = ExcludeIncidentsClause
+	}
+
+	var results, selected string
+	switch {
+	case selector.Test != "":
+		results = `
+		SELECT date_trunc(@granularity, prow_job_runs.timestamp AT TIME ZONE @timezone) AS bucket,
+			COUNT(*) AS runs,
+			COUNT(*) FILTER (WHERE prow_job_run_tests.status = @success) AS passes,
+			COUNT(*) FILTER (WHERE prow_job_run_tests.status = @flake
+				OR (@lenient AND prow_job_run_tests.status = @failure AND prow_job_run_tests.payload_flake)) AS flakes,
+			COUNT(*) FILTER (WHERE prow_job_run_tests.status = @failure
+				AND NOT (@lenient AND prow_job_run_tests.payload_flake)) AS failures
+		FROM prow_job_run_tests
+		JOIN tests ON tests.id = prow_job_run_tests.test_id
+		JOIN prow_job_runs ON prow_job_runs.id = prow_job_run_tests.prow_job_run_id
+		JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+		WHERE tests.name = @selected
+			AND prow_job_runs.timestamp BETWEEN @start AND @end
+			AND prow_job_run_tests.deleted_at IS NULL
+			AND NOT (@primary_only AND prow_job_run_tests.cascade_failure)
+			AND (@release = '' OR ` + db.RunRelease + ` = @release)
+			AND (@build_cluster = '' OR prow_job_runs.cluster = @build_cluster)
+			AND ` + exclude + `
+		GROUP BY bucket`
+		selected = selector.Test
+	case selector.Job != "", selector.Variant != "":
+		where := "(prow_jobs.name = @selected OR prow_jobs.name IN @pre_branch)"
+		selected = selector.Job
+		if selector.Job == "" {
+			where = "@selected = ANY(prow_jobs.variants)"
+			selected = selector.Variant
+		}
+		results = `
+		SELECT date_trunc(@granularity, prow_job_runs.timestamp AT TIME ZONE @timezone) AS bucket,
+			COUNT(*) AS runs,
+			COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS passes,
+			0 AS flakes,
+			COUNT(*) FILTER (WHERE NOT prow_job_runs.succeeded) AS failures
+		FROM prow_job_runs
+		JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+		WHERE ` + where + `
+			AND prow_job_runs.timestamp BETWEEN @start AND @end
+			AND prow_job_runs.deleted_at IS NULL
+			AND (@release = '' OR ` + db.RunRelease + ` = @release)
+			AND (@build_cluster = '' OR prow_job_runs.cluster = @build_cluster)
+			AND ` + exclude + `
+		GROUP BY bucket`
+	default:
+		return buckets, fmt.Errorf("a job, test, or variant must be selected")
+	}
+
+	// IN () isn't valid, so always include an empty name, which matches no job
+	preBranch := append([]string{""}, util.PreBranchJobNames(selector.Job, selector.Release)...)
+
+	q := dbc.DB.Raw(`
+WITH results AS (`+results+`
+), series AS (
+	SELECT generate_series(date_trunc(@granularity, @start::timestamptz AT TIME ZONE @timezone),
+		date_trunc(@granularity, @end::timestamptz AT TIME ZONE @timezone), ('1 ' || @granularity)::interval) AS bucket
+)
+SELECT series.bucket AT TIME ZONE @timezone AS bucket,
+	COALESCE(results.runs, 0) AS runs,
+	COALESCE(results.passes, 0) AS passes,
+	COALESCE(results.flakes, 0) AS flakes,
+	COALESCE(results.failures, 0) AS failures,
+	results.passes * 100.0 / NULLIF(results.runs, 0) AS pass_percentage
+FROM series
+LEFT JOIN results ON results.bucket = series.bucket
+ORDER BY series.bucket ASC`, map[string]interface{}{
+		"granularity":   string(granularity),
+		"timezone":      loc.String(),
+		"selected":      selected,
+		"pre_branch":    preBranch,
+		"release":       selector.Release,
+		"build_cluster": selector.BuildCluster,
+		"primary_only":  selector.PrimaryFailuresOnly,
+		"lenient":       selector.PassRateMode == apitype.PassRateLenient,
+		"start":         start,
+		"end":           end,
+		"success":       v1.TestStatusSuccess,
+		"flake":         v1.TestStatusFlake,
+		"failure":       v1.TestStatusFailure,
+	})
+	if q.Error != nil {
+		return buckets, q.Error
+	}
+	if res := q.Scan(&buckets); res.Error != nil {
+		return buckets, res.Error
+	}
+	for i := range buckets {
+		buckets[i].Bucket = buckets[i].Bucket.In(loc)
+		buckets[i].PassPercentageCI = apitype.NewConfidenceInterval(buckets[i].Passes, buckets[i].Runs)
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"buckets": len(buckets),
+	}).Info("PassRateTimeSeries completed")
+	return buckets, nil
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/db"
+)
+
+// VariantKeyChurn returns the number of times each variant key changed value for a release's jobs since the given
+// time, most changed first.
+func VariantKeyChurn(dbc *db.DB, release string, since time.Time) ([]apitype.VariantKeyChurn, error) {
+	now := time.Now()
+	results := make([]apitype.VariantKeyChurn, 0)
+	res := dbc.DB.Raw(`
+SELECT variant_changes.key AS key,
+	COUNT(*) AS changes,
+	COUNT(DISTINCT variant_changes.prow_job_id) AS jobs,
+	MAX(variant_changes.created_at) AS last_changed_at
+FROM variant_changes
+JOIN prow_jobs ON prow_jobs.id = variant_changes.prow_job_id
+WHERE prow_jobs.release = @release
+	AND variant_changes.created_at >= @since
+	AND variant_changes.deleted_at IS NULL
+GROUP BY variant_changes.key
+ORDER BY changes DESC, key`, map[string]interface{}{
+		"release": release,
+		"since":   since,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("VariantKeyChurn completed")
+	return results, nil
+}
+
+// JobVariantChurn returns the number of times each variant key changed value for each of a release's jobs since the
+// given time, most changed first, optionally only for one key.
+func JobVariantChurn(dbc *db.DB, release, key string, since time.Time, limit int) ([]apitype.JobVariantChurn, error) {
+	now := time.Now()
+	results := make([]apitype.JobVariantChurn, 0)
+	res := dbc.DB.Raw(`
+SELECT variant_changes.job_name AS job_name,
+	variant_changes.key AS key,
+	-- each change is unnested into a row for its old and new value
+	COUNT(DISTINCT variant_changes.id) AS changes,
+	array_agg(DISTINCT value ORDER BY value) FILTER (WHERE value <> '') AS values,
+	MAX(variant_changes.created_at) AS last_changed_at
+FROM variant_changes
+JOIN prow_jobs ON prow_jobs.id = variant_changes.prow_job_id
+CROSS JOIN LATERAL unnest(ARRAY[variant_changes.old_value, variant_changes.new_value]) AS value
+WHERE prow_jobs.release = @release
+	AND variant_changes.created_at >= @since
+	AND variant_changes.deleted_at IS NULL
+	AND (@key = '' OR variant_changes.key = @key)
+GROUP BY variant_changes.job_name, variant_changes.key
+ORDER BY changes DESC, job_name, key
+LIMIT @limit`, map[string]interface{}{
+		"release": release,
+		"key":     key,
+		"since":   since,
+		"limit":   limit,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("JobVariantChurn completed")
+	return results, nil
+}
+
+// VariantValueCounts returns the number of jobs with each variant key and value, in key and value order, optionally
+// only for one release. Variants without a key, i.e. "never-stable", are not included.
+func VariantValueCounts(dbc *db.DB, release string) ([]apitype.VariantValueCount, error) {
+	now := time.Now()
+	results := make([]apitype.VariantValueCount, 0)
+	res := dbc.DB.Raw(`
+SELECT split_part(variant, ':', 1) AS key,
+	substr(variant, strpos(variant, ':') + 1) AS value,
+	COUNT(DISTINCT prow_jobs.id) AS jobs
+FROM prow_jobs
+CROSS JOIN LATERAL unnest(prow_jobs.variants) AS variant
+WHERE strpos(variant, ':') > 0
+	AND prow_jobs.deleted_at IS NULL
+	AND (@release = '' OR prow_jobs.release = @release)
+GROUP BY key, value
+ORDER BY key, value`, map[string]interface{}{
+		"release": release,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("VariantValueCounts completed")
+	return results, nil
+}
+
+// JobVariantRuns is a job's variants, and how many times it ran.
+type JobVariantRuns struct {
+	Name     string
+	Variants pq.StringArray `gorm:"type:text[]"`
+	Runs     int
+}
+
+// ReleaseJobVariantRuns returns the variants of each of a release's jobs that ran between start and end.
+func ReleaseJobVariantRuns(dbc *db.DB, release string, start, end time.Time) ([]JobVariantRuns, error) {
+	now := time.Now()
+	results := make([]JobVariantRuns, 0)
+	res := dbc.DB.Raw(`
+SELECT prow_jobs.name,
+	prow_jobs.variants,
+	COUNT(*) AS runs
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE `+db.RunRelease+` = @release
+	AND prow_jobs.deleted_at IS NULL
+	AND prow_job_runs.timestamp BETWEEN @start AND @end
+	AND prow_job_runs.deleted_at IS NULL
+GROUP BY prow_jobs.name, prow_jobs.variants
+ORDER BY prow_jobs.name`, map[string]interface{}{
+		"release": release,
+		"start":   start,
+		"end":     end,
+	}).Scan(&results)
+	if res.Error != nil {
+		return results, res.Error
+	}
+
+	log.WithFields(log.Fields{
+		"elapsed": time.Since(now),
+		"rows":    len(results),
+	}).Info("ReleaseJobVariantRuns completed")
+	return results, nil
+}
@@ -0,0 +1,64 @@
+package query
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// WatchedRuns are the runs and successes of a watch subscription's test or jobs.
+type WatchedRuns struct {
+	Runs      int
+	Successes int
+}
+
+// WatchedPassRate returns the runs and successes of a subscription's test over the current 7 day report period, or
+// of its job, or jobs with its variants, since the given time.
+func WatchedPassRate(dbc *db.DB, sub *models.WatchSubscription, since time.Time) (WatchedRuns, error) {
+	now := time.Now()
+	variants := pq.StringArray(sub.Variants)
+	if variants == nil {
+		variants = pq.StringArray{}
+	}
+
+	var q string
+	if sub.TestName != "" {
+		q = `
+SELECT COALESCE(SUM(current_runs), 0) AS runs,
+	COALESCE(SUM(current_successes), 0) AS successes
+FROM prow_test_report_7d_matview
+WHERE release = @release
+	AND name = @test
+	AND variants @> @variants`
+	} else {
+		q = `
+SELECT COUNT(*) AS runs,
+	COUNT(*) FILTER (WHERE prow_job_runs.succeeded) AS successes
+FROM prow_job_runs
+JOIN prow_jobs ON prow_jobs.id = prow_job_runs.prow_job_id
+WHERE ` + db.RunRelease + ` = @release
+	AND (@job = '' OR prow_jobs.name = @job)
+	AND prow_jobs.variants @> @variants
+	AND prow_job_runs.timestamp >= @since
+	AND prow_job_runs.deleted_at IS NULL`
+	}
+
+	var result WatchedRuns
+	res := dbc.DB.Raw(q,
+		sql.Named("release", sub.Release),
+		sql.Named("test", sub.TestName),
+		sql.Named("job", sub.JobName),
+		sql.Named("variants", variants),
+		sql.Named("since", since)).Scan(&result)
+	log.WithFields(log.Fields{
+		"subscription": sub.ID,
+		"runs":         result.Runs,
+		"elapsed":      time.Since(now),
+	}).Debug("WatchedPassRate completed")
+	return result, res.Error
+}
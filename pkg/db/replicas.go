@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+const (
+	replicaCheckInterval = 10 * time.Second
+	replicaCheckTimeout  = 5 * time.Second
+
+	// replicaLagQuery returns how far a replica is behind its primary. A replica that has replayed everything it
+	// received isn't behind, however long ago the last transaction was.
+	replicaLagQuery = `SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END`
+)
+
+type primaryKey struct{}
+
+type replica struct {
+	name    string
+	pool    gorm.ConnPool
+	db      *gorm.DB
+	checked bool
+	healthy bool
+	lag     time.Duration
+}
+
+// replicaSet routes read-only queries to the healthy read replica with the least replication lag. Replicas that
+// can't be reached, or lag by more than maxLag, are skipped until they recover, and queries go to the primary
+// when none are usable.
+type replicaSet struct {
+	lock     sync.RWMutex
+	replicas []*replica
+	maxLag   time.Duration
+}
+
+// AddReplicas sends read-only queries to read replicas, and starts monitoring their health and replication lag.
+// Queries in transactions, locking reads, and sessions from Primary() always go to the primary.
+func (d *DB) AddReplicas(dsns []string, maxLag time.Duration, logLevel gormlogger.LogLevel) error {
+	set := &replicaSet{maxLag: maxLag}
+	for i, dsn := range dsns {
+		rdb, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newGormLogger(logLevel)})
+		if err != nil {
+			return err
+		}
+		pool, err := rdb.DB()
+		if err != nil {
+			return err
+		}
+		set.replicas = append(set.replicas, &replica{name: replicaName(i, dsn), pool: pool, db: rdb})
+	}
+
+	callbacks := d.DB.Callback()
+	for _, err := range []error{
+		callbacks.Query().Before("gorm:query").Register("sippy:replica_query", set.routeRead),
+		callbacks.Row().Before("gorm:row").Register("sippy:replica_row", set.routeRead),
+		callbacks.Create().Before("gorm:create").Register("sippy:replica_create", routeWrite),
+		callbacks.Update().Before("gorm:update").Register("sippy:replica_update", routeWrite),
+		callbacks.Delete().Before("gorm:delete").Register("sippy:replica_delete", routeWrite),
+		callbacks.Raw().Before("gorm:raw").Register("sippy:replica_raw", routeWrite),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	d.replicas = set
+	set.check()
+	go func() {
+		for range time.Tick(replicaCheckInterval) {
+			set.check()
+		}
+	}()
+	return nil
+}
+
+// Primary returns a session whose queries all go to the primary, for reads that must see writes just made.
+func (d *DB) Primary() *DB {
+	return &DB{
+		DB:        d.DB.WithContext(context.WithValue(context.Background(), primaryKey{}, true)),
+		BatchSize: d.BatchSize,
+		replicas:  d.replicas,
+	}
+}
+
+// HasReplicas returns true if read replicas are configured, whether or not any are usable.
+func (d *DB) HasReplicas() bool {
+	return d != nil && d.replicas != nil
+}
+
+// ReplicationLag returns whether read-only queries currently go to a replica, and how far behind it is.
+func (d *DB) ReplicationLag() (bool, time.Duration) {
+	if d == nil || d.replicas == nil {
+		return false, 0
+	}
+	r := d.replicas.pick()
+	if r == nil {
+		return false, 0
+	}
+	return true, r.lag
+}
+
+func (s *replicaSet) routeRead(tx *gorm.DB) {
+	if inTransaction(tx) {
+		return
+	}
+	if _, locking := tx.Statement.Clauses["FOR"]; locking || !isReadQuery(tx.Statement.SQL.String()) {
+		tx.Statement.ConnPool = tx.Config.ConnPool
+		return
+	}
+	if primary, _ := tx.Statement.Context.Value(primaryKey{}).(bool); primary {
+		tx.Statement.ConnPool = tx.Config.ConnPool
+		return
+	}
+	if r := s.pick(); r != nil {
+		tx.Statement.ConnPool = r.pool
+	} else {
+		tx.Statement.ConnPool = tx.Config.ConnPool
+	}
+}
+
+// routeWrite sends writes to the primary, including those made through a statement that was used for a read.
+func routeWrite(tx *gorm.DB) {
+	if !inTransaction(tx) {
+		tx.Statement.ConnPool = tx.Config.ConnPool
+	}
+}
+
+func inTransaction(tx *gorm.DB) bool {
+	_, ok := tx.Statement.ConnPool.(gorm.TxCommitter)
+	return ok
+}
+
+// isReadQuery returns true for queries that can run on a replica. Queries built by gorm have no SQL yet when they
+// are routed, and are always reads.
+func isReadQuery(sql string) bool {
+	sql = strings.ToUpper(strings.TrimSpace(sql))
+	return sql == "" || strings.HasPrefix(sql, "SELECT") || strings.HasPrefix(sql, "WITH")
+}
+
+func (s *replicaSet) pick() *replica {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	var best *replica
+	for _, r := range s.replicas {
+		if r.healthy && (best == nil || r.lag < best.lag) {
+			best = r
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	picked := *best
+	return &picked
+}
+
+// check measures the replication lag of each replica, and marks those that can't be reached or lag too far
+// behind as unhealthy.
+func (s *replicaSet) check() {
+	for _, r := range s.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaCheckTimeout)
+		var seconds float64
+		err := r.db.WithContext(ctx).Raw(replicaLagQuery).Row().Scan(&seconds)
+		cancel()
+		lag := time.Duration(seconds * float64(time.Second))
+		healthy := err == nil && (s.maxLag <= 0 || lag <= s.maxLag)
+
+		s.lock.Lock()
+		changed := !r.checked || healthy != r.healthy
+		r.checked, r.healthy, r.lag = true, healthy, lag
+		s.lock.Unlock()
+
+		logger := log.WithFields(log.Fields{"replica": r.name, "lag": lag.Round(time.Second)})
+		switch {
+		case changed && healthy:
+			logger.Info("read replica is healthy, routing read-only queries to it")
+		case changed && err != nil:
+			logger.WithError(err).Warning("read replica is unreachable, routing read-only queries elsewhere")
+		case changed:
+			logger.Warningf("read replica lags by more than %s, routing read-only queries elsewhere", s.maxLag)
+		}
+	}
+}
+
+// replicaName identifies a replica in logs without its credentials.
+func replicaName(i int, dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return fmt.Sprintf("replica-%d", i)
+}
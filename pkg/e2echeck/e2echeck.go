@@ -0,0 +1,235 @@
+// Package e2echeck smoke tests a running sippy deployment through its API. Each check requests a critical
+// endpoint and asserts the response has the expected shape and recent data, so a deploy that serves errors, empty
+// reports or a stale database can be caught before it's promoted.
+package e2echeck
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift/sippy/pkg/client"
+)
+
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// HealthIndicators are the indicators every release with imported data has runs for.
+var HealthIndicators = []string{"bootstrap", "infrastructure", "install", "installConfig", "installOther", "tests", "upgrade"}
+
+// Options configures the checks.
+type Options struct {
+	// Release is the release whose reports are checked.
+	Release string
+	// View is the component readiness view to generate. If empty, the first view sampling Release is used, and
+	// the check is skipped if there is none.
+	View string
+	// SkipComponentReadiness skips the component readiness check, for deployments without BigQuery.
+	SkipComponentReadiness bool
+	// MaxDataAge is how long ago data may have last been imported. 0 disables the freshness assertion.
+	MaxDataAge time.Duration
+}
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// errSkipped is returned by checks that don't apply to the deployment.
+type errSkipped string
+
+func (e errSkipped) Error() string {
+	return string(e)
+}
+
+type check struct {
+	name string
+	run  func(ctx context.Context, c *client.Client, opts Options) (string, error)
+}
+
+var checks = []check{
+	{name: "health", run: checkHealth},
+	{name: "install", run: checkInstall},
+	{name: "variants", run: checkVariants},
+	{name: "component_readiness", run: checkComponentReadiness},
+}
+
+// Run runs every check in turn against the deployment c points at.
+func Run(ctx context.Context, c *client.Client, opts Options) []Result {
+	results := make([]Result, 0, len(checks))
+	for _, chk := range checks {
+		start := time.Now()
+		message, err := chk.run(ctx, c, opts)
+		result := Result{Name: chk.name, Status: StatusPassed, Message: message}
+		if skipped, ok := err.(errSkipped); ok {
+			result.Status, result.Message = StatusSkipped, skipped.Error()
+		} else if err != nil {
+			result.Status, result.Message = StatusFailed, err.Error()
+		}
+		result.DurationMS = time.Since(start).Milliseconds()
+		results = append(results, result)
+	}
+	return results
+}
+
+// Failed returns the number of failed checks.
+func Failed(results []Result) int {
+	failed := 0
+	for _, r := range results {
+		if r.Status == StatusFailed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// checkHealth asserts every health indicator has runs, and that data was imported recently.
+func checkHealth(ctx context.Context, c *client.Client, opts Options) (string, error) {
+	health, err := c.Health(ctx, opts.Release)
+	if err != nil {
+		return "", err
+	}
+
+	var missing []string
+	for _, name := range HealthIndicators {
+		if health.Indicators[name].CurrentRuns <= 0 {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("no current runs for indicators %s", strings.Join(missing, ", "))
+	}
+
+	if health.LastUpdated.IsZero() {
+		return "", fmt.Errorf("last_updated is not set")
+	}
+	age := time.Since(health.LastUpdated).Round(time.Minute)
+	if opts.MaxDataAge > 0 && age > opts.MaxDataAge {
+		return "", fmt.Errorf("data was last updated %s ago, more than %s", age, opts.MaxDataAge)
+	}
+	return fmt.Sprintf("data last updated %s ago", age), nil
+}
+
+// checkInstall asserts the install report has an overall column with runs.
+func checkInstall(ctx context.Context, c *client.Client, opts Options) (string, error) {
+	report, err := c.InstallReport(ctx, opts.Release)
+	if err != nil {
+		return "", err
+	}
+
+	hasAll := false
+	for _, name := range report.ColumnNames {
+		hasAll = hasAll || name == "All"
+	}
+	if !hasAll {
+		return "", fmt.Errorf("install report has no All column, columns are %v", report.ColumnNames)
+	}
+	if len(report.Tests) == 0 {
+		return "", fmt.Errorf("install report has no tests")
+	}
+	runs := 0
+	for _, byColumn := range report.Tests {
+		runs += byColumn["All"].CurrentRuns
+	}
+	if runs == 0 {
+		return "", fmt.Errorf("install report has no current runs")
+	}
+	return fmt.Sprintf("%d tests across %d columns", len(report.Tests), len(report.ColumnNames)), nil
+}
+
+// checkVariants asserts the release has named variants with runs, and that component readiness knows the
+// variants of jobs.
+func checkVariants(ctx context.Context, c *client.Client, opts Options) (string, error) {
+	variants, err := c.Variants(ctx, opts.Release, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(variants) == 0 {
+		return "", fmt.Errorf("no variants reported")
+	}
+	runs := 0
+	for _, v := range variants {
+		if v.Name == "" {
+			return "", fmt.Errorf("variant %d has no name", v.ID)
+		}
+		runs += v.CurrentRuns
+	}
+	if runs == 0 {
+		return "", fmt.Errorf("no variant has current runs")
+	}
+
+	jobVariants, err := c.JobVariants(ctx)
+	if err != nil {
+		return "", err
+	}
+	var empty []string
+	for name, values := range jobVariants.Variants {
+		if len(values) == 0 {
+			empty = append(empty, name)
+		}
+	}
+	if len(jobVariants.Variants) == 0 {
+		return "", fmt.Errorf("no job variants reported")
+	} else if len(empty) > 0 {
+		sort.Strings(empty)
+		return "", fmt.Errorf("job variants %s have no values", strings.Join(empty, ", "))
+	}
+	return fmt.Sprintf("%d variants, %d job variant keys", len(variants), len(jobVariants.Variants)), nil
+}
+
+// checkComponentReadiness generates the report of a view and asserts it has rows for components.
+func checkComponentReadiness(ctx context.Context, c *client.Client, opts Options) (string, error) {
+	if opts.SkipComponentReadiness {
+		return "", errSkipped("component readiness checks are disabled")
+	}
+
+	views, err := c.ComponentReadinessViews(ctx)
+	if err != nil {
+		return "", err
+	}
+	view := opts.View
+	if view == "" {
+		for _, v := range views {
+			if v.SampleRelease.Release == opts.Release {
+				view = v.Name
+				break
+			}
+		}
+		if view == "" {
+			return "", errSkipped(fmt.Sprintf("no component readiness view samples %s", opts.Release))
+		}
+	} else {
+		found := false
+		for _, v := range views {
+			found = found || v.Name == view
+		}
+		if !found {
+			return "", fmt.Errorf("component readiness view %s does not exist", view)
+		}
+	}
+
+	report, err := c.ComponentReport(ctx, view)
+	if err != nil {
+		return "", err
+	}
+	if report.GeneratedAt == nil {
+		return "", fmt.Errorf("view %s report has no generated_at", view)
+	}
+	if len(report.Rows) == 0 {
+		return "", fmt.Errorf("view %s report has no rows", view)
+	}
+	for i, row := range report.Rows {
+		if row.Component == "" || len(row.Columns) == 0 {
+			return "", fmt.Errorf("view %s report row %d has no component or columns", view, i)
+		}
+	}
+	return fmt.Sprintf("view %s has %d rows", view, len(report.Rows)), nil
+}
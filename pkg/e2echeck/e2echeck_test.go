@@ -0,0 +1,108 @@
+package e2echeck
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
+	"github.com/openshift/sippy/pkg/client"
+	"github.com/openshift/sippy/pkg/client/fake"
+)
+
+func healthyServer(t *testing.T, lastUpdated time.Time) *fake.Server {
+	s := fake.NewServer()
+	t.Cleanup(s.Close)
+	health := apitype.Health{Indicators: map[string]apitype.Test{}, LastUpdated: lastUpdated}
+	for _, name := range HealthIndicators {
+		health.Indicators[name] = apitype.Test{Name: name, CurrentRuns: 10}
+	}
+	require.NoError(t, s.SetResponse("/api/health", health))
+	return s
+}
+
+func statuses(results []Result) map[string]string {
+	byName := map[string]string{}
+	for _, r := range results {
+		byName[r.Name] = r.Status
+	}
+	return byName
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("healthy deployment", func(t *testing.T) {
+		s := healthyServer(t, time.Now().Add(-time.Hour))
+		results := Run(ctx, s.SippyClient(client.Options{}), Options{Release: "4.14", MaxDataAge: 24 * time.Hour})
+		assert.Zero(t, Failed(results), "%+v", results)
+		assert.Equal(t, map[string]string{
+			"health":              StatusPassed,
+			"install":             StatusPassed,
+			"variants":            StatusPassed,
+			"component_readiness": StatusPassed,
+		}, statuses(results))
+	})
+
+	t.Run("stale data", func(t *testing.T) {
+		s := healthyServer(t, time.Now().Add(-48*time.Hour))
+		results := Run(ctx, s.SippyClient(client.Options{}), Options{Release: "4.14", MaxDataAge: 24 * time.Hour})
+		assert.Equal(t, 1, Failed(results))
+		assert.Equal(t, StatusFailed, statuses(results)["health"])
+		assert.Contains(t, results[0].Message, "more than 24h0m0s")
+	})
+
+	t.Run("missing indicator runs", func(t *testing.T) {
+		s := healthyServer(t, time.Now())
+		require.NoError(t, s.SetResponse("/api/health", apitype.Health{
+			Indicators:  map[string]apitype.Test{"install": {CurrentRuns: 1}},
+			LastUpdated: time.Now(),
+		}))
+		results := Run(ctx, s.SippyClient(client.Options{}), Options{Release: "4.14"})
+		assert.Equal(t, StatusFailed, statuses(results)["health"])
+		assert.Equal(t, "no current runs for indicators bootstrap, infrastructure, installConfig, installOther, tests, upgrade", results[0].Message)
+	})
+
+	t.Run("endpoint errors", func(t *testing.T) {
+		s := healthyServer(t, time.Now())
+		s.SetError("/api/install", http.StatusInternalServerError, "could not generate install report")
+		results := Run(ctx, s.SippyClient(client.Options{MaxRetries: -1}), Options{Release: "4.14"})
+		assert.Equal(t, 1, Failed(results))
+		assert.Equal(t, StatusFailed, statuses(results)["install"])
+		assert.Contains(t, results[1].Message, "could not generate install report")
+	})
+
+	t.Run("empty component report", func(t *testing.T) {
+		s := healthyServer(t, time.Now())
+		now := time.Now()
+		require.NoError(t, s.SetResponse("/api/component_readiness", crtype.ComponentReport{GeneratedAt: &now}))
+		results := Run(ctx, s.SippyClient(client.Options{}), Options{Release: "4.14"})
+		assert.Equal(t, StatusFailed, statuses(results)["component_readiness"])
+	})
+
+	t.Run("unknown view", func(t *testing.T) {
+		s := healthyServer(t, time.Now())
+		results := Run(ctx, s.SippyClient(client.Options{}), Options{Release: "4.14", View: "nope"})
+		assert.Equal(t, StatusFailed, statuses(results)["component_readiness"])
+	})
+
+	t.Run("no view for release", func(t *testing.T) {
+		s := healthyServer(t, time.Now())
+		results := Run(ctx, s.SippyClient(client.Options{}), Options{Release: "4.15"})
+		assert.Equal(t, StatusSkipped, statuses(results)["component_readiness"])
+	})
+
+	t.Run("component readiness disabled", func(t *testing.T) {
+		s := healthyServer(t, time.Now())
+		results := Run(ctx, s.SippyClient(client.Options{}), Options{Release: "4.14", SkipComponentReadiness: true})
+		assert.Equal(t, StatusSkipped, statuses(results)["component_readiness"])
+		for _, r := range s.Requests() {
+			assert.NotContains(t, r.Path, "component_readiness")
+		}
+	})
+}
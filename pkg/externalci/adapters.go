@@ -0,0 +1,112 @@
+package externalci
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	RegisterAdapter("sippy", sippyAdapter{})
+	RegisterAdapter("jenkins", jenkinsAdapter{})
+	RegisterAdapter("gitlab", gitlabAdapter{})
+}
+
+// sippyAdapter parses reports already in sippy's format, for systems without an adapter of their own:
+// {"tests": [{"name": ..., "suite": ..., "status": "pass", ...}]}.
+type sippyAdapter struct{}
+
+func (sippyAdapter) ParseReport(report json.RawMessage) ([]TestResult, error) {
+	var r struct {
+		Tests []TestResult `json:"tests"`
+	}
+	if err := json.Unmarshal(report, &r); err != nil {
+		return nil, err
+	}
+	return r.Tests, nil
+}
+
+// jenkinsAdapter parses the JUnit plugin's test report, from a build's testReport/api/json.
+type jenkinsAdapter struct{}
+
+type jenkinsReport struct {
+	Suites []struct {
+		Name  string `json:"name"`
+		Cases []struct {
+			ClassName       string  `json:"className"`
+			Name            string  `json:"name"`
+			Status          string  `json:"status"`
+			Duration        float64 `json:"duration"`
+			ErrorDetails    string  `json:"errorDetails"`
+			ErrorStackTrace string  `json:"errorStackTrace"`
+		} `json:"cases"`
+	} `json:"suites"`
+}
+
+func (jenkinsAdapter) ParseReport(report json.RawMessage) ([]TestResult, error) {
+	var r jenkinsReport
+	if err := json.Unmarshal(report, &r); err != nil {
+		return nil, err
+	}
+	var results []TestResult
+	for _, suite := range r.Suites {
+		for _, c := range suite.Cases {
+			result := TestResult{Name: c.Name, Suite: suite.Name, DurationSeconds: c.Duration}
+			// FIXED and REGRESSION compare with the previous build, they are a pass and a failure in this one
+			switch c.Status {
+			case "PASSED", "FIXED":
+				result.Status = StatusPass
+			case "FAILED", "REGRESSION":
+				result.Status = StatusFail
+				result.Output = strings.TrimSpace(c.ErrorDetails + "\n" + c.ErrorStackTrace)
+			case "SKIPPED":
+				result.Status = StatusSkipped
+			default:
+				return nil, fmt.Errorf("test %q has unknown status %q", c.Name, c.Status)
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// gitlabAdapter parses a pipeline's test report, from the pipelines/:id/test_report API.
+type gitlabAdapter struct{}
+
+type gitlabReport struct {
+	TestSuites []struct {
+		Name      string `json:"name"`
+		TestCases []struct {
+			Name          string  `json:"name"`
+			Status        string  `json:"status"`
+			ExecutionTime float64 `json:"execution_time"`
+			SystemOutput  string  `json:"system_output"`
+		} `json:"test_cases"`
+	} `json:"test_suites"`
+}
+
+func (gitlabAdapter) ParseReport(report json.RawMessage) ([]TestResult, error) {
+	var r gitlabReport
+	if err := json.Unmarshal(report, &r); err != nil {
+		return nil, err
+	}
+	var results []TestResult
+	for _, suite := range r.TestSuites {
+		for _, c := range suite.TestCases {
+			result := TestResult{Name: c.Name, Suite: suite.Name, DurationSeconds: c.ExecutionTime}
+			switch c.Status {
+			case "success":
+				result.Status = StatusPass
+			case "failed", "error":
+				result.Status = StatusFail
+				result.Output = c.SystemOutput
+			case "skipped":
+				result.Status = StatusSkipped
+			default:
+				return nil, fmt.Errorf("test %q has unknown status %q", c.Name, c.Status)
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
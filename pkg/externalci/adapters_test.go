@@ -0,0 +1,70 @@
+package externalci
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJenkinsAdapter(t *testing.T) {
+	report := json.RawMessage(`{
+		"duration": 120.5,
+		"suites": [{
+			"name": "qe-storage",
+			"cases": [
+				{"className": "storage", "name": "should mount", "status": "FIXED", "duration": 12.5},
+				{"className": "storage", "name": "should resize", "status": "REGRESSION", "duration": 3,
+					"errorDetails": "expected 2Gi", "errorStackTrace": "at resize_test.go:42"},
+				{"className": "storage", "name": "should snapshot", "status": "SKIPPED"}
+			]
+		}]
+	}`)
+
+	results, err := jenkinsAdapter{}.ParseReport(report)
+	require.NoError(t, err)
+	assert.Equal(t, []TestResult{
+		{Name: "should mount", Suite: "qe-storage", Status: StatusPass, DurationSeconds: 12.5},
+		{Name: "should resize", Suite: "qe-storage", Status: StatusFail, DurationSeconds: 3,
+			Output: "expected 2Gi\nat resize_test.go:42"},
+		{Name: "should snapshot", Suite: "qe-storage", Status: StatusSkipped},
+	}, results)
+
+	_, err = jenkinsAdapter{}.ParseReport(json.RawMessage(`{"suites": [{"cases": [{"name": "x", "status": "ABORTED"}]}]}`))
+	assert.ErrorContains(t, err, `unknown status "ABORTED"`)
+}
+
+func TestGitLabAdapter(t *testing.T) {
+	report := json.RawMessage(`{
+		"total_count": 3,
+		"test_suites": [{
+			"name": "partner-certification",
+			"test_cases": [
+				{"name": "operator installs", "classname": "cert", "status": "success", "execution_time": 40},
+				{"name": "operator upgrades", "classname": "cert", "status": "error", "execution_time": 5,
+					"system_output": "panic: nil pointer"},
+				{"name": "operator uninstalls", "classname": "cert", "status": "skipped"}
+			]
+		}]
+	}`)
+
+	results, err := gitlabAdapter{}.ParseReport(report)
+	require.NoError(t, err)
+	assert.Equal(t, []TestResult{
+		{Name: "operator installs", Suite: "partner-certification", Status: StatusPass, DurationSeconds: 40},
+		{Name: "operator upgrades", Suite: "partner-certification", Status: StatusFail, DurationSeconds: 5,
+			Output: "panic: nil pointer"},
+		{Name: "operator uninstalls", Suite: "partner-certification", Status: StatusSkipped},
+	}, results)
+}
+
+func TestRegisterAdapter(t *testing.T) {
+	RegisterAdapter("test-system", sippyAdapter{})
+	defer func() {
+		adaptersLock.Lock()
+		delete(adapters, "test-system")
+		adaptersLock.Unlock()
+	}()
+	assert.Contains(t, AdapterNames(), "test-system")
+}
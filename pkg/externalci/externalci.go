@@ -0,0 +1,230 @@
+// Package externalci ingests job runs from CI systems other than Prow, i.e. Jenkins or GitLab pipelines running
+// partner or QE suites, so their results are reported alongside Prow's.
+//
+// Runs are posted with their job's name, release and variants, and the test report in the CI system's own format.
+// An Adapter for the system converts the report to test results; adapters register themselves with
+// RegisterAdapter, so supporting another system only needs a new adapter. Ingested jobs are stored like Prow jobs,
+// with kind external and the name of their CI system, and a job name already used by a Prow job is refused.
+//
+// Tests QE ran by hand are recorded the same way, as ManualRuns with the tester and build. They are grouped into jobs
+// of kind manual, flagged with the Provenance:manual variant, so reports can combine manual and automated results
+// while telling them apart.
+//
+// Runs are only stored in the database, not BigQuery, so reports read from BigQuery such as component readiness
+// don't include them.
+package externalci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+// Test result statuses, as adapters report them.
+const (
+	StatusPass    = "pass"
+	StatusFail    = "fail"
+	StatusFlake   = "flake"
+	StatusSkipped = "skipped"
+)
+
+// JobRun is a job run posted by an external CI system.
+type JobRun struct {
+	// System is the adapter that parses the report, and is recorded as the job's CI system.
+	System   string            `json:"system"`
+	Job      string            `json:"job"`
+	Release  string            `json:"release"`
+	Variants map[string]string `json:"variants"`
+	// URL links to the run in the CI system, and identifies it: a run is only ingested once.
+	URL             string    `json:"url"`
+	Cluster         string    `json:"cluster,omitempty"`
+	Timestamp       time.Time `json:"timestamp"`
+	DurationSeconds float64   `json:"duration_seconds,omitempty"`
+	// Succeeded is whether the run succeeded, defaulting to whether all its tests did. Runs can fail outside their
+	// tests, i.e. while provisioning.
+	Succeeded *bool `json:"succeeded,omitempty"`
+	// Report is the run's test report, in the format of the system's adapter.
+	Report json.RawMessage `json:"report"`
+}
+
+// TestResult is a test's result in a run.
+type TestResult struct {
+	Name            string  `json:"name"`
+	Suite           string  `json:"suite,omitempty"`
+	Status          string  `json:"status"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	// Output is the failure output of failed tests.
+	Output string `json:"output,omitempty"`
+}
+
+// Adapter converts test reports from a CI system to test results.
+type Adapter interface {
+	ParseReport(report json.RawMessage) ([]TestResult, error)
+}
+
+var (
+	adaptersLock sync.RWMutex
+	adapters     = map[string]Adapter{}
+)
+
+// RegisterAdapter makes an adapter available for runs posted with a system name. Registering a name twice replaces
+// the earlier adapter.
+func RegisterAdapter(system string, adapter Adapter) {
+	adaptersLock.Lock()
+	defer adaptersLock.Unlock()
+	adapters[system] = adapter
+}
+
+// AdapterNames returns the sorted names of the registered adapters.
+func AdapterNames() []string {
+	adaptersLock.RLock()
+	defer adaptersLock.RUnlock()
+	return adapterNames()
+}
+
+func adapterNames() []string {
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getAdapter(system string) (Adapter, error) {
+	adaptersLock.RLock()
+	defer adaptersLock.RUnlock()
+	adapter, ok := adapters[system]
+	if !ok {
+		return nil, fmt.Errorf("unknown system %q: must be one of %s", system, strings.Join(adapterNames(), ", "))
+	}
+	return adapter, nil
+}
+
+// Validate checks a posted run has what's needed to report on it.
+func (r *JobRun) Validate() error {
+	if r.Job == "" {
+		return fmt.Errorf("job is required")
+	}
+	if r.Release == "" {
+		return fmt.Errorf("release is required")
+	}
+	if _, err := url.ParseRequestURI(r.URL); err != nil {
+		return fmt.Errorf("url must be a link to the run: %v", err)
+	}
+	if r.Timestamp.IsZero() {
+		return fmt.Errorf("timestamp is required")
+	}
+	for name, value := range r.Variants {
+		if name == "" || value == "" || strings.ContainsAny(name+value, ":,") {
+			return fmt.Errorf("invalid variant %q=%q: names and values must be non-empty and not contain ':' or ','",
+				name, value)
+		}
+	}
+	return nil
+}
+
+// Tests parses the run's report with its system's adapter. A test reported more than once, i.e. when retried, is a
+// flake if it both passed and failed. Skipped tests are dropped.
+func (r *JobRun) Tests() ([]TestResult, error) {
+	adapter, err := getAdapter(r.System)
+	if err != nil {
+		return nil, err
+	}
+	results, err := adapter.ParseReport(r.Report)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s report: %v", r.System, err)
+	}
+	return mergeResults(results)
+}
+
+func mergeResults(results []TestResult) ([]TestResult, error) {
+	type key struct{ suite, name string }
+	merged := map[key]*TestResult{}
+	var order []key
+	for _, result := range results {
+		if result.Name == "" {
+			return nil, fmt.Errorf("test without a name")
+		}
+		switch result.Status {
+		case StatusSkipped:
+			continue
+		case StatusPass, StatusFail, StatusFlake:
+		default:
+			return nil, fmt.Errorf("test %q has unknown status %q", result.Name, result.Status)
+		}
+
+		k := key{result.Suite, result.Name}
+		existing, ok := merged[k]
+		if !ok {
+			r := result
+			merged[k] = &r
+			order = append(order, k)
+			continue
+		}
+		existing.DurationSeconds += result.DurationSeconds
+		if existing.Status != result.Status {
+			existing.Status = StatusFlake
+		}
+		if result.Output != "" {
+			existing.Output = result.Output
+		}
+	}
+
+	tests := make([]TestResult, 0, len(order))
+	for _, k := range order {
+		tests = append(tests, *merged[k])
+	}
+	return tests, nil
+}
+
+// variants returns the run's variants in the Name:value form jobs store them in, sorted.
+func (r *JobRun) variants() []string {
+	variants := make([]string, 0, len(r.Variants))
+	for name, value := range r.Variants {
+		variants = append(variants, name+":"+value)
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// overallResult returns the run's result, and its number of failed tests.
+func (r *JobRun) overallResult(tests []TestResult) (v1.JobOverallResult, int) {
+	failures := 0
+	for _, t := range tests {
+		if t.Status == StatusFail {
+			failures++
+		}
+	}
+	succeeded := failures == 0
+	if r.Succeeded != nil {
+		succeeded = *r.Succeeded
+	}
+	switch {
+	case succeeded:
+		return v1.JobSucceeded, failures
+	case failures > 0:
+		return v1.JobTestFailure, failures
+	default:
+		// failed outside its tests, which external systems don't tell us more about
+		return v1.JobUnknown, failures
+	}
+}
+
+// testStatus returns the status a test result is stored with.
+func testStatus(status string) v1.TestStatus {
+	switch status {
+	case StatusPass:
+		return v1.TestStatusSuccess
+	case StatusFlake:
+		return v1.TestStatusFlake
+	default:
+		return v1.TestStatusFailure
+	}
+}
@@ -0,0 +1,115 @@
+package externalci
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+)
+
+func validRun() *JobRun {
+	return &JobRun{
+		System:    "sippy",
+		Job:       "qe-e2e-vsphere-ipi-ovn",
+		Release:   "4.16",
+		Variants:  map[string]string{"Platform": "vsphere", "Network": "ovn"},
+		URL:       "https://jenkins.example.com/job/qe-e2e-vsphere-ipi-ovn/42/",
+		Timestamp: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(r *JobRun)
+		wantErr string
+	}{
+		{name: "valid", modify: func(r *JobRun) {}},
+		{name: "no job", modify: func(r *JobRun) { r.Job = "" }, wantErr: "job is required"},
+		{name: "no release", modify: func(r *JobRun) { r.Release = "" }, wantErr: "release is required"},
+		{name: "no url", modify: func(r *JobRun) { r.URL = "42" }, wantErr: "url must be a link"},
+		{name: "no timestamp", modify: func(r *JobRun) { r.Timestamp = time.Time{} }, wantErr: "timestamp is required"},
+		{
+			name:    "variant with separator",
+			modify:  func(r *JobRun) { r.Variants["Platform"] = "vsphere:8" },
+			wantErr: "invalid variant",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := validRun()
+			tc.modify(r)
+			err := r.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestTests(t *testing.T) {
+	r := validRun()
+	r.Report = json.RawMessage(`{"tests": [
+		{"name": "install", "status": "pass", "duration_seconds": 1800},
+		{"name": "storage should mount", "suite": "qe", "status": "fail", "output": "timed out"},
+		{"name": "storage should mount", "suite": "qe", "status": "pass", "duration_seconds": 30},
+		{"name": "gpu", "suite": "qe", "status": "skipped"}
+	]}`)
+
+	tests, err := r.Tests()
+	require.NoError(t, err)
+	assert.Equal(t, []TestResult{
+		{Name: "install", Status: StatusPass, DurationSeconds: 1800},
+		{Name: "storage should mount", Suite: "qe", Status: StatusFlake, DurationSeconds: 30, Output: "timed out"},
+	}, tests)
+
+	r.Report = json.RawMessage(`{"tests": [{"name": "install", "status": "broken"}]}`)
+	_, err = r.Tests()
+	assert.ErrorContains(t, err, `unknown status "broken"`)
+
+	r.System = "travis"
+	_, err = r.Tests()
+	assert.ErrorContains(t, err, `unknown system "travis": must be one of gitlab, jenkins, sippy`)
+}
+
+func TestOverallResult(t *testing.T) {
+	pass := []TestResult{{Name: "a", Status: StatusPass}, {Name: "b", Status: StatusFlake}}
+	fail := []TestResult{{Name: "a", Status: StatusPass}, {Name: "b", Status: StatusFail}}
+	succeeded, failed := true, false
+
+	r := validRun()
+	result, failures := r.overallResult(pass)
+	assert.Equal(t, v1.JobSucceeded, result)
+	assert.Equal(t, 0, failures)
+
+	result, failures = r.overallResult(fail)
+	assert.Equal(t, v1.JobTestFailure, result)
+	assert.Equal(t, 1, failures)
+
+	r.Succeeded = &failed
+	result, _ = r.overallResult(pass)
+	assert.Equal(t, v1.JobUnknown, result, "a run failing outside its tests")
+
+	r.Succeeded = &succeeded
+	result, _ = r.overallResult(fail)
+	assert.Equal(t, v1.JobSucceeded, result, "the posted result wins over the tests'")
+}
+
+func TestVariants(t *testing.T) {
+	assert.Equal(t, []string{"Network:ovn", "Platform:vsphere"}, validRun().variants())
+}
+
+func TestIngestInvalidRun(t *testing.T) {
+	r := validRun()
+	r.Report = json.RawMessage(`{"tests": [{"status": "pass"}]}`)
+	_, err := Ingest(context.Background(), nil, r)
+	assert.ErrorIs(t, err, ErrInvalidRun)
+	assert.ErrorContains(t, err, "test without a name")
+}
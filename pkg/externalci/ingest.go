@@ -0,0 +1,199 @@
+package externalci
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+var logger = logging.ForSubsystem("externalci")
+
+var (
+	// ErrInvalidRun is returned when a posted run is missing a field, or its report cannot be parsed.
+	ErrInvalidRun = errors.New("invalid job run")
+	// ErrJobKind is returned when ingesting a run for a job name another kind of job already has, i.e. a Prow job.
+	ErrJobKind = errors.New("job name belongs to another kind of job")
+	// ErrDuplicateRun is returned when a run with the same URL was already ingested for the job.
+	ErrDuplicateRun = errors.New("job run was already ingested")
+)
+
+// Ingestion is the result of ingesting a run.
+type Ingestion struct {
+	ProwJobID    uint `json:"prow_job_id"`
+	ProwJobRunID uint `json:"prow_job_run_id"`
+	Tests        int  `json:"tests"`
+	TestFailures int  `json:"test_failures"`
+}
+
+// Ingest records a run, and its job if it's new, so the run is included in reports like a Prow job's. The job's
+// release and variants are updated to the run's.
+func Ingest(ctx context.Context, dbc *db.DB, run *JobRun) (*Ingestion, error) {
+	if err := run.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRun, err)
+	}
+	tests, err := run.Tests()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRun, err)
+	}
+	return ingest(ctx, dbc, run, models.ProwExternal, tests, nil)
+}
+
+// IngestManual records a manual run as a run of its release and environment's manual job, with the tester and build
+// it was run by and on.
+func IngestManual(ctx context.Context, dbc *db.DB, run *ManualRun) (*Ingestion, error) {
+	if err := run.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRun, err)
+	}
+	tests, err := mergeResults(run.Results)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRun, err)
+	}
+	return ingest(ctx, dbc, run.jobRun(), models.ProwManual, tests,
+		&models.ManualJobRun{Tester: run.Tester, Build: run.Build})
+}
+
+func ingest(ctx context.Context, dbc *db.DB, run *JobRun, kind models.ProwKind, tests []TestResult,
+	manual *models.ManualJobRun) (*Ingestion, error) {
+	overallResult, failures := run.overallResult(tests)
+
+	result := &Ingestion{Tests: len(tests), TestFailures: failures}
+	err := dbc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		job, err := findOrCreateJob(tx, run, kind)
+		if err != nil {
+			return err
+		}
+		result.ProwJobID = job.ID
+
+		var existing int64
+		if err := tx.Model(&models.ProwJobRun{}).Where("prow_job_id = ? AND url = ?", job.ID, run.URL).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrDuplicateRun
+		}
+
+		jobRun := &models.ProwJobRun{
+			ProwJobID:     job.ID,
+			Cluster:       run.Cluster,
+			URL:           run.URL,
+			TestFailures:  failures,
+			Failed:        overallResult != v1.JobSucceeded,
+			Succeeded:     overallResult == v1.JobSucceeded,
+			Timestamp:     run.Timestamp,
+			Duration:      time.Duration(run.DurationSeconds * float64(time.Second)),
+			OverallResult: overallResult,
+			Manual:        manual,
+		}
+		if err := tx.Omit("ProwJob").Create(jobRun).Error; err != nil {
+			return errors.Wrap(err, "error creating job run")
+		}
+		result.ProwJobRunID = jobRun.ID
+
+		runTests, err := jobRunTests(tx, jobRun.ID, tests)
+		if err != nil {
+			return err
+		}
+		if len(runTests) > 0 {
+			if err := tx.CreateInBatches(runTests, 1000).Error; err != nil {
+				return errors.Wrap(err, "error creating test results")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.WithField("job", run.Job).WithField("system", run.System).
+		Infof("ingested %s job run %d with %d tests", kind, result.ProwJobRunID, result.Tests)
+	return result, nil
+}
+
+func findOrCreateJob(tx *gorm.DB, run *JobRun, kind models.ProwKind) (*models.ProwJob, error) {
+	job := &models.ProwJob{}
+	if err := tx.Where("name = ?", run.Job).Find(job).Error; err != nil {
+		return nil, err
+	}
+	if job.ID != 0 && job.Kind != kind {
+		return nil, fmt.Errorf("%w: %s is a %s job", ErrJobKind, run.Job, job.Kind)
+	}
+
+	variants := run.variants()
+	if job.ID != 0 {
+		changes := models.DiffVariants(job, job.Variants, variants)
+		job.Release = run.Release
+		job.Variants = variants
+		job.CISystem = run.System
+		if err := tx.Save(job).Error; err != nil {
+			return nil, errors.Wrap(err, "error updating job")
+		}
+		if len(changes) > 0 {
+			if err := tx.Create(&changes).Error; err != nil {
+				return nil, errors.Wrap(err, "error recording variant changes")
+			}
+		}
+		return job, nil
+	}
+
+	job = &models.ProwJob{
+		Kind:     kind,
+		Name:     run.Job,
+		Release:  run.Release,
+		Variants: variants,
+		CISystem: run.System,
+	}
+	if err := tx.Create(job).Error; err != nil {
+		return nil, errors.Wrap(err, "error creating job")
+	}
+	return job, nil
+}
+
+// jobRunTests returns the rows recording a run's test results, creating any tests and suites not seen before.
+func jobRunTests(tx *gorm.DB, jobRunID uint, tests []TestResult) ([]*models.ProwJobRunTest, error) {
+	testIDs := map[string]uint{}
+	suiteIDs := map[string]*uint{}
+	runTests := make([]*models.ProwJobRunTest, 0, len(tests))
+	for _, t := range tests {
+		testID, ok := testIDs[t.Name]
+		if !ok {
+			test := &models.Test{}
+			if err := tx.Where(models.Test{Name: t.Name}).FirstOrCreate(test).Error; err != nil {
+				return nil, errors.Wrapf(err, "error creating test %q", t.Name)
+			}
+			testID = test.ID
+			testIDs[t.Name] = testID
+		}
+
+		suiteID, ok := suiteIDs[t.Suite]
+		if !ok && t.Suite != "" {
+			suite := &models.Suite{}
+			if err := tx.Where(models.Suite{Name: t.Suite}).FirstOrCreate(suite).Error; err != nil {
+				return nil, errors.Wrapf(err, "error creating suite %q", t.Suite)
+			}
+			suiteID = &suite.ID
+			suiteIDs[t.Suite] = suiteID
+		}
+
+		runTest := &models.ProwJobRunTest{
+			ProwJobRunID: jobRunID,
+			TestID:       testID,
+			SuiteID:      suiteID,
+			Status:       int(testStatus(t.Status)),
+			Duration:     t.DurationSeconds,
+		}
+		if t.Status == StatusFail && t.Output != "" {
+			runTest.ProwJobRunTestOutput = &models.ProwJobRunTestOutput{Output: t.Output}
+		}
+		runTests = append(runTests, runTest)
+	}
+	return runTests, nil
+}
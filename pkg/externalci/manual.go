@@ -0,0 +1,88 @@
+package externalci
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ManualSystem is the CI system recorded for manual jobs. It has no adapter, so external runs can't be posted as
+// manual ones.
+const ManualSystem = "manual"
+
+// ProvenanceVariant is the variant flagging manual jobs, so reports slicing or listing variants tell their results
+// apart from automated ones.
+const (
+	ProvenanceVariant = "Provenance"
+	ProvenanceManual  = "manual"
+)
+
+var nonJobNameChars = regexp.MustCompile(`[^a-z0-9.]+`)
+
+// ManualRun is a run of tests a tester executed by hand, i.e. QE verifying a feature on a release candidate. Runs are
+// grouped into a manual job per release and environment.
+type ManualRun struct {
+	Tester  string `json:"tester"`
+	Release string `json:"release"`
+	// Build is the payload or build the tests were run on, i.e. 4.16.0-rc.1.
+	Build string `json:"build"`
+	// Environment describes the cluster the tests were run on, as variants, i.e. {"Platform": "vsphere"}.
+	Environment map[string]string `json:"environment"`
+	// URL links to the record of the run, i.e. in a test case management system, and identifies it: a run is only
+	// recorded once.
+	URL       string       `json:"url"`
+	Timestamp time.Time    `json:"timestamp"`
+	Results   []TestResult `json:"results"`
+}
+
+// ManualJobName returns the name of the manual job grouping runs for a release and environment, i.e.
+// manual-4.16-ovn-vsphere for {"Network": "ovn", "Platform": "vsphere"}. Values are ordered by variant name.
+func ManualJobName(release string, environment map[string]string) string {
+	names := make([]string, 0, len(environment))
+	for name := range environment {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := []string{"manual", release}
+	for _, name := range names {
+		parts = append(parts, environment[name])
+	}
+	return strings.Trim(nonJobNameChars.ReplaceAllString(strings.ToLower(strings.Join(parts, "-")), "-"), "-")
+}
+
+// Validate checks a manual run has what's needed to report on it.
+func (m *ManualRun) Validate() error {
+	if m.Tester == "" {
+		return fmt.Errorf("tester is required")
+	}
+	if m.Build == "" {
+		return fmt.Errorf("build is required")
+	}
+	if _, ok := m.Environment[ProvenanceVariant]; ok {
+		return fmt.Errorf("environment can't set the %s variant", ProvenanceVariant)
+	}
+	if len(m.Results) == 0 {
+		return fmt.Errorf("results are required")
+	}
+	return m.jobRun().Validate()
+}
+
+// jobRun returns the manual run as a run of its manual job, whose variants are its environment flagged with manual
+// provenance.
+func (m *ManualRun) jobRun() *JobRun {
+	variants := map[string]string{ProvenanceVariant: ProvenanceManual}
+	for name, value := range m.Environment {
+		variants[name] = value
+	}
+	return &JobRun{
+		System:    ManualSystem,
+		Job:       ManualJobName(m.Release, m.Environment),
+		Release:   m.Release,
+		Variants:  variants,
+		URL:       m.URL,
+		Timestamp: m.Timestamp,
+	}
+}
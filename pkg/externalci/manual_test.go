@@ -0,0 +1,79 @@
+package externalci
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validManualRun() *ManualRun {
+	return &ManualRun{
+		Tester:      "jdoe",
+		Release:     "4.16",
+		Build:       "4.16.0-rc.1",
+		Environment: map[string]string{"Platform": "vsphere", "Network": "ovn"},
+		URL:         "https://polarion.example.com/testrun/4.16.0-rc.1-vsphere",
+		Timestamp:   time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		Results:     []TestResult{{Name: "replace a failed control plane node", Status: StatusPass}},
+	}
+}
+
+func TestManualJobName(t *testing.T) {
+	assert.Equal(t, "manual-4.16-ovn-vsphere",
+		ManualJobName("4.16", map[string]string{"Platform": "vsphere", "Network": "ovn"}))
+	assert.Equal(t, "manual-4.16-ipv6-bare-metal",
+		ManualJobName("4.16", map[string]string{"Platform": "Bare Metal", "IPStack": "ipv6"}))
+	assert.Equal(t, "manual-4.16", ManualJobName("4.16", nil))
+}
+
+func TestManualRunValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(r *ManualRun)
+		wantErr string
+	}{
+		{name: "valid", modify: func(r *ManualRun) {}},
+		{name: "no tester", modify: func(r *ManualRun) { r.Tester = "" }, wantErr: "tester is required"},
+		{name: "no build", modify: func(r *ManualRun) { r.Build = "" }, wantErr: "build is required"},
+		{name: "no results", modify: func(r *ManualRun) { r.Results = nil }, wantErr: "results are required"},
+		{name: "no url", modify: func(r *ManualRun) { r.URL = "" }, wantErr: "url must be a link"},
+		{
+			name:    "provenance in environment",
+			modify:  func(r *ManualRun) { r.Environment[ProvenanceVariant] = "automated" },
+			wantErr: "can't set the Provenance variant",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := validManualRun()
+			tc.modify(r)
+			err := r.Validate()
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestManualJobRun(t *testing.T) {
+	run := validManualRun().jobRun()
+	assert.Equal(t, ManualSystem, run.System)
+	assert.Equal(t, "manual-4.16-ovn-vsphere", run.Job)
+	assert.Equal(t, []string{"Network:ovn", "Platform:vsphere", "Provenance:manual"}, run.variants())
+
+	// manual runs have no adapter, so they can't be posted as external runs
+	_, err := run.Tests()
+	assert.ErrorContains(t, err, `unknown system "manual"`)
+}
+
+func TestIngestManualInvalidRun(t *testing.T) {
+	r := validManualRun()
+	r.Results = []TestResult{{Name: "replace a failed control plane node", Status: "blocked"}}
+	_, err := IngestManual(context.Background(), nil, r)
+	assert.ErrorIs(t, err, ErrInvalidRun)
+	assert.ErrorContains(t, err, `unknown status "blocked"`)
+}
@@ -0,0 +1,239 @@
+// Package featureflags gates new analyses so they can be rolled out per deployment, or to a percentage of
+// requests, and compared against the existing behavior before becoming the default.
+//
+// Flags are declared in a YAML config file passed to the server, and can be overridden at runtime through the
+// /api/feature_flags API. Code checks a flag with Enabled(req.Context(), "flag-name"); flags that are not
+// declared are always off.
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	SourceConfig   = "config"
+	SourceOverride = "override"
+
+	// QueryParam forces flags on or off for a single request, e.g. features=new-method,-other-method. This is
+	// how the two sides of an A/B comparison are requested explicitly.
+	QueryParam = "features"
+
+	// Header lists the flags that were enabled for a request.
+	Header = "X-Sippy-Features"
+)
+
+var nameRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Flag is the configuration of a single feature flag. An enabled flag applies to Percentage percent of requests.
+type Flag struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Enabled     bool   `yaml:"enabled" json:"enabled"`
+	Percentage  int    `yaml:"percentage,omitempty" json:"percentage"`
+}
+
+// Config is the feature flag config file.
+type Config struct {
+	Flags []Flag `yaml:"flags"`
+}
+
+// State is the effective configuration of a flag and where it came from.
+type State struct {
+	Flag
+	Source string `json:"source"`
+}
+
+// Validate checks the flag name and percentage. An enabled flag without a percentage applies to every request.
+func (f *Flag) Validate() error {
+	if !nameRegex.MatchString(f.Name) {
+		return fmt.Errorf("invalid feature flag name %q, must be lowercase alphanumeric words separated by dashes", f.Name)
+	}
+	if f.Percentage < 0 || f.Percentage > 100 {
+		return fmt.Errorf("feature flag %s percentage must be between 0 and 100", f.Name)
+	}
+	if f.Enabled && f.Percentage == 0 {
+		f.Percentage = 100
+	}
+	return nil
+}
+
+// LoadConfig reads and validates a feature flag config file.
+func LoadConfig(path string) ([]Flag, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read feature flags from %s", path)
+	}
+	config := Config{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse feature flags from %s", path)
+	}
+	return config.Flags, nil
+}
+
+// Manager holds the configured flags and their runtime overrides, and evaluates them for requests.
+type Manager struct {
+	lock      sync.RWMutex
+	config    map[string]Flag
+	overrides map[string]Flag
+}
+
+// NewManager validates the configured flags and returns a manager for them.
+func NewManager(flags []Flag) (*Manager, error) {
+	m := &Manager{config: map[string]Flag{}, overrides: map[string]Flag{}}
+	for _, f := range flags {
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := m.config[f.Name]; ok {
+			return nil, fmt.Errorf("feature flag %s is defined more than once", f.Name)
+		}
+		m.config[f.Name] = f
+	}
+	return m, nil
+}
+
+// Known returns whether a flag is declared in the config.
+func (m *Manager) Known(name string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	_, ok := m.config[name]
+	return ok
+}
+
+// SetOverrides replaces all runtime overrides. Overrides for flags that are not declared are ignored.
+func (m *Manager) SetOverrides(overrides []Flag) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.overrides = map[string]Flag{}
+	for _, o := range overrides {
+		if c, ok := m.config[o.Name]; ok {
+			o.Description = c.Description
+			m.overrides[o.Name] = o
+		}
+	}
+}
+
+// Flags returns the effective state of every declared flag, sorted by name.
+func (m *Manager) Flags() []State {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	states := make([]State, 0, len(m.config))
+	for name := range m.config {
+		states = append(states, m.state(name))
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// Get returns the effective state of a flag.
+func (m *Manager) Get(name string) (State, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	if _, ok := m.config[name]; !ok {
+		return State{}, false
+	}
+	return m.state(name), true
+}
+
+func (m *Manager) state(name string) State {
+	if o, ok := m.overrides[name]; ok {
+		return State{Flag: o, Source: SourceOverride}
+	}
+	return State{Flag: m.config[name], Source: SourceConfig}
+}
+
+// Evaluate decides which flags are on for a request. Percentage rollouts are bucketed on a hash of the flag name
+// and key, so the same request always gets the same answer and cached responses stay consistent. Forced values,
+// typically parsed from the request with ParseForced, take precedence.
+func (m *Manager) Evaluate(key string, forced map[string]bool) Set {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	set := Set{}
+	for name := range m.config {
+		if on, ok := forced[name]; ok {
+			if on {
+				set[name] = true
+			}
+			continue
+		}
+		f := m.state(name).Flag
+		if f.Enabled && bucket(name, key) < f.Percentage {
+			set[name] = true
+		}
+	}
+	return set
+}
+
+// ParseForced parses the features query parameter, a comma separated list of flag names where a leading dash
+// forces the flag off.
+func ParseForced(value string) map[string]bool {
+	forced := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if strings.HasPrefix(name, "-") {
+			forced[strings.TrimPrefix(name, "-")] = false
+		} else {
+			forced[name] = true
+		}
+	}
+	return forced
+}
+
+func bucket(name, key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + key))
+	return int(h.Sum32() % 100)
+}
+
+// Set is the flags enabled for a single request.
+type Set map[string]bool
+
+// Enabled returns whether the flag is on.
+func (s Set) Enabled(name string) bool {
+	return s[name]
+}
+
+// String returns the enabled flag names, sorted and comma separated.
+func (s Set) String() string {
+	names := make([]string, 0, len(s))
+	for name, on := range s {
+		if on {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the request's flags.
+func NewContext(ctx context.Context, set Set) context.Context {
+	return context.WithValue(ctx, contextKey{}, set)
+}
+
+// FromContext returns the request's flags, or an empty set if none were evaluated.
+func FromContext(ctx context.Context) Set {
+	if set, ok := ctx.Value(contextKey{}).(Set); ok {
+		return set
+	}
+	return Set{}
+}
+
+// Enabled returns whether a flag is on for the request the context belongs to.
+func Enabled(ctx context.Context, name string) bool {
+	return FromContext(ctx).Enabled(name)
+}
@@ -0,0 +1,94 @@
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagValidate(t *testing.T) {
+	f := Flag{Name: "new-method", Enabled: true}
+	require.NoError(t, f.Validate())
+	assert.Equal(t, 100, f.Percentage, "enabled flag without percentage applies to every request")
+
+	f = Flag{Name: "new-method", Enabled: true, Percentage: 25}
+	require.NoError(t, f.Validate())
+	assert.Equal(t, 25, f.Percentage)
+
+	assert.Error(t, (&Flag{Name: "New Method"}).Validate())
+	assert.Error(t, (&Flag{Name: "new-method", Percentage: 101}).Validate())
+	assert.Error(t, (&Flag{Name: "new-method", Percentage: -1}).Validate())
+}
+
+func TestNewManagerDuplicate(t *testing.T) {
+	_, err := NewManager([]Flag{{Name: "a"}, {Name: "a"}})
+	assert.Error(t, err)
+}
+
+func TestEvaluate(t *testing.T) {
+	m, err := NewManager([]Flag{
+		{Name: "on", Enabled: true},
+		{Name: "off"},
+		{Name: "half", Enabled: true, Percentage: 50},
+	})
+	require.NoError(t, err)
+
+	set := m.Evaluate("/api/tests?release=4.16", nil)
+	assert.True(t, set.Enabled("on"))
+	assert.False(t, set.Enabled("off"))
+	assert.False(t, set.Enabled("undeclared"))
+
+	// Percentage rollouts are stable for a key and roughly match the percentage across keys.
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("/api/tests?release=4.%d", i)
+		first := m.Evaluate(key, nil).Enabled("half")
+		assert.Equal(t, first, m.Evaluate(key, nil).Enabled("half"))
+		if first {
+			enabled++
+		}
+	}
+	assert.InDelta(t, 500, enabled, 75)
+
+	// Forced values win, and undeclared flags cannot be forced on.
+	set = m.Evaluate("/api/tests", ParseForced("off,-on,undeclared"))
+	assert.True(t, set.Enabled("off"))
+	assert.False(t, set.Enabled("on"))
+	assert.False(t, set.Enabled("undeclared"))
+}
+
+func TestOverrides(t *testing.T) {
+	m, err := NewManager([]Flag{{Name: "new-method", Description: "A new method"}, {Name: "other"}})
+	require.NoError(t, err)
+
+	m.SetOverrides([]Flag{{Name: "new-method", Enabled: true, Percentage: 100}, {Name: "undeclared", Enabled: true}})
+	state, ok := m.Get("new-method")
+	require.True(t, ok)
+	assert.Equal(t, SourceOverride, state.Source)
+	assert.Equal(t, "A new method", state.Description)
+	assert.True(t, m.Evaluate("/api/tests", nil).Enabled("new-method"))
+
+	_, ok = m.Get("undeclared")
+	assert.False(t, ok)
+
+	states := m.Flags()
+	require.Len(t, states, 2)
+	assert.Equal(t, "new-method", states[0].Name)
+	assert.Equal(t, SourceConfig, states[1].Source)
+
+	m.SetOverrides(nil)
+	state, _ = m.Get("new-method")
+	assert.Equal(t, SourceConfig, state.Source)
+	assert.False(t, m.Evaluate("/api/tests", nil).Enabled("new-method"))
+}
+
+func TestContext(t *testing.T) {
+	assert.False(t, Enabled(context.Background(), "new-method"))
+
+	ctx := NewContext(context.Background(), Set{"new-method": true, "b": true, "c": false})
+	assert.True(t, Enabled(ctx, "new-method"))
+	assert.Equal(t, "b,new-method", FromContext(ctx).String())
+}
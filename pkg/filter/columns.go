@@ -0,0 +1,124 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// ParseSortParam parses a sort parameter in the form field:dir, i.e. "current_pass_percentage:desc". If the
+// direction is omitted, it defaults to ascending.
+func ParseSortParam(param string) (string, apitype.Sort, error) {
+	parts := strings.SplitN(param, ":", 2)
+	field := strings.TrimSpace(parts[0])
+	if field == "" {
+		return "", "", fmt.Errorf("invalid sort param %q: field is required", param)
+	}
+
+	sort := apitype.SortAscending
+	if len(parts) == 2 {
+		var err error
+		if sort, err = ParseSortDirection(parts[1]); err != nil {
+			return "", "", fmt.Errorf("invalid sort direction in %q: must be asc or desc", param)
+		}
+	}
+
+	return field, sort, nil
+}
+
+// ParseSortDirection parses a sort direction, asc or desc in any case.
+func ParseSortDirection(param string) (apitype.Sort, error) {
+	switch sort := apitype.Sort(strings.ToLower(strings.TrimSpace(param))); sort {
+	case apitype.SortAscending, apitype.SortDescending:
+		return sort, nil
+	default:
+		return "", fmt.Errorf("invalid sort direction %q: must be asc or desc", param)
+	}
+}
+
+// ParseColumnsParam parses a comma separated list of columns to project in a response.
+func ParseColumnsParam(param string) []string {
+	var columns []string
+	for _, c := range strings.Split(param, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			columns = append(columns, c)
+		}
+	}
+	return columns
+}
+
+// AllowedFields returns the set of JSON field names for an API type. These are the only fields that may be
+// used to sort or project results for list endpoints backed by that type, which prevents arbitrary
+// identifiers from making their way into the ORDER BY clause.
+func AllowedFields(item interface{}) sets.String {
+	fields := sets.NewString()
+	t := reflect.TypeOf(item)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous {
+			fields.Insert(AllowedFields(reflect.New(f.Type).Elem().Interface()).List()...)
+			continue
+		}
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields.Insert(name)
+	}
+	return fields
+}
+
+// Validate ensures the requested sort field and columns are whitelisted for the given API type.
+func (fo *FilterOptions) Validate(item interface{}) error {
+	allowed := AllowedFields(item)
+	if fo.SortField != "" && !allowed.Has(fo.SortField) {
+		return fmt.Errorf("invalid sort field %q", fo.SortField)
+	}
+	for _, c := range fo.Columns {
+		if !allowed.Has(c) {
+			return fmt.Errorf("invalid column %q", c)
+		}
+	}
+	return nil
+}
+
+// ProjectColumns reduces a slice of API results to only the requested columns. If no columns are given the
+// rows are returned unmodified.
+func ProjectColumns(rows interface{}, columns []string) (interface{}, error) {
+	if len(columns) == 0 {
+		return rows, nil
+	}
+
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	var all []map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	projected := make([]map[string]interface{}, 0, len(all))
+	for _, row := range all {
+		p := make(map[string]interface{}, len(columns))
+		for _, c := range columns {
+			p[c] = row[c]
+		}
+		projected = append(projected, p)
+	}
+	return projected, nil
+}
@@ -0,0 +1,87 @@
+package filter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+)
+
+func TestParseSortParam(t *testing.T) {
+	tests := []struct {
+		param         string
+		expectedField string
+		expectedSort  apitype.Sort
+		expectErr     bool
+	}{
+		{param: "name:asc", expectedField: "name", expectedSort: apitype.SortAscending},
+		{param: "current_runs:DESC", expectedField: "current_runs", expectedSort: apitype.SortDescending},
+		{param: "name", expectedField: "name", expectedSort: apitype.SortAscending},
+		{param: "name:sideways", expectErr: true},
+		{param: ":asc", expectErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.param, func(t *testing.T) {
+			field, sort, err := ParseSortParam(tc.param)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedField, field)
+			assert.Equal(t, tc.expectedSort, sort)
+		})
+	}
+}
+
+func TestSortParamsFromRequest(t *testing.T) {
+	tests := []struct {
+		query         string
+		expectedField string
+		expectedSort  apitype.Sort
+		expectErr     bool
+	}{
+		{query: "", expectedField: "current_runs", expectedSort: apitype.SortDescending},
+		{query: "sort=name:asc", expectedField: "name", expectedSort: apitype.SortAscending},
+		{query: "sort=name", expectedField: "name", expectedSort: apitype.SortAscending},
+		{query: "sort=asc", expectedField: "current_runs", expectedSort: apitype.SortAscending},
+		{query: "sortField=name&sort=desc", expectedField: "name", expectedSort: apitype.SortDescending},
+		{query: "sortField=name&sort=ASC", expectedField: "name", expectedSort: apitype.SortAscending},
+		{query: "sortField=name", expectedField: "name", expectedSort: apitype.SortDescending},
+		{query: "sortField=name&sort=sideways", expectErr: true},
+		{query: "sort=name:sideways", expectErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/jobs?"+tc.query, nil)
+			field, sort, err := SortParamsFromRequest(req, "current_runs", apitype.SortDescending)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedField, field)
+			assert.Equal(t, tc.expectedSort, sort)
+		})
+	}
+}
+
+func TestFilterOptionsValidate(t *testing.T) {
+	assert.NoError(t, (&FilterOptions{SortField: "current_pass_percentage", Columns: []string{"name"}}).Validate(apitype.Job{}))
+	assert.Error(t, (&FilterOptions{SortField: "name; DROP TABLE prow_jobs"}).Validate(apitype.Job{}))
+	assert.Error(t, (&FilterOptions{SortField: "name", Columns: []string{"bogus"}}).Validate(apitype.Job{}))
+}
+
+func TestProjectColumns(t *testing.T) {
+	jobs := []apitype.Job{{ID: 1, Name: "e2e-aws", CurrentPassPercentage: 90.5}}
+	result, err := ProjectColumns(jobs, []string{"name", "current_pass_percentage"})
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{{"name": "e2e-aws", "current_pass_percentage": 90.5}}, result)
+
+	unchanged, err := ProjectColumns(jobs, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, jobs, unchanged)
+}
@@ -237,6 +237,8 @@ type FilterOptions struct {
 	SortField string
 	Sort      apitype.Sort
 	Limit     int
+	// Columns optionally restricts the fields returned for each row.
+	Columns []string
 }
 
 func FilterOptionsFromRequest(req *http.Request, defaultSortField string, defaultSort apitype.Sort) (filterOpts *FilterOptions, err error) {
@@ -261,19 +263,43 @@ func FilterOptionsFromRequest(req *http.Request, defaultSortField string, defaul
 		filterOpts.Limit = limit
 	}
 
-	sortField := req.URL.Query().Get("sortField")
-	sort := apitype.Sort(req.URL.Query().Get("sort"))
-	if sortField == "" {
-		sortField = defaultSortField
-	}
-	if sort == "" {
-		sort = defaultSort
+	sortField, sort, err := SortParamsFromRequest(req, defaultSortField, defaultSort)
+	if err != nil {
+		return filterOpts, err
 	}
 	filterOpts.Sort = sort
 	filterOpts.SortField = sortField
+	filterOpts.Columns = ParseColumnsParam(req.URL.Query().Get("columns"))
 	return filterOpts, nil
 }
 
+// SortParamsFromRequest extracts the sort field and direction from a request. Both the combined
+// sort=field:dir form and the older sortField=field&sort=dir form are supported. A sort param that is neither a
+// direction nor has one, i.e. sort=name, sorts ascending by that field.
+func SortParamsFromRequest(req *http.Request, defaultSortField string, defaultSort apitype.Sort) (string, apitype.Sort, error) {
+	sortField := req.URL.Query().Get("sortField")
+	sortParam := strings.TrimSpace(req.URL.Query().Get("sort"))
+	sort := defaultSort
+	if sortParam != "" {
+		direction, err := ParseSortDirection(sortParam)
+		switch {
+		case err == nil:
+			sort = direction
+		case sortField != "" && !strings.Contains(sortParam, ":"):
+			// the older form's direction must be valid, rather than being taken as a field
+			return "", "", err
+		default:
+			if sortField, sort, err = ParseSortParam(sortParam); err != nil {
+				return "", "", err
+			}
+		}
+	}
+	if sortField == "" {
+		sortField = defaultSortField
+	}
+	return sortField, sort, nil
+}
+
 // TODO: merge with FilterOptionsFromRequest
 func ExtractFilters(req *http.Request) (*Filter, error) {
 	filter := &Filter{}
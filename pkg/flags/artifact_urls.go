@@ -0,0 +1,36 @@
+package flags
+
+import (
+	"github.com/spf13/pflag"
+
+	"github.com/openshift/sippy/pkg/artifacturls"
+)
+
+// ArtifactURLFlags are the templates of links to job runs and their artifacts, for deployments whose deck instance
+// or artifact browser differs from the defaults.
+type ArtifactURLFlags struct {
+	JobRunTemplate string
+	BrowseTemplate string
+}
+
+func NewArtifactURLFlags() *ArtifactURLFlags {
+	return &ArtifactURLFlags{
+		JobRunTemplate: artifacturls.DefaultJobRunTemplate,
+		BrowseTemplate: artifacturls.DefaultBrowseTemplate,
+	}
+}
+
+func (f *ArtifactURLFlags) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&f.JobRunTemplate, "job-run-url-template", f.JobRunTemplate,
+		"Template of links viewing a job run, with {prow_url}, {bucket} and {path} placeholders")
+	fs.StringVar(&f.BrowseTemplate, "artifact-browse-url-template", f.BrowseTemplate,
+		"Template of links browsing a job run's artifacts, with {prow_url}, {bucket} and {path} placeholders")
+}
+
+// GetBuilder returns a builder for the templates, a deck instance and a bucket.
+func (f *ArtifactURLFlags) GetBuilder(prowURL, bucket string) (*artifacturls.Builder, error) {
+	return artifacturls.New(artifacturls.Templates{
+		JobRun: f.JobRunTemplate,
+		Browse: f.BrowseTemplate,
+	}, prowURL, bucket)
+}
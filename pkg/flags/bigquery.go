@@ -14,15 +14,23 @@ import (
 type BigQueryFlags struct {
 	BigQueryProject string
 	BigQueryDataset string
+	VerifySchemas   bool
+	CreateTables    bool
 }
 
 func NewBigQueryFlags() *BigQueryFlags {
-	return &BigQueryFlags{}
+	return &BigQueryFlags{
+		VerifySchemas: true,
+	}
 }
 
 func (f *BigQueryFlags) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&f.BigQueryProject, "bigquery-project", "openshift-gce-devel", "BigQuery project to use")
 	fs.StringVar(&f.BigQueryDataset, "bigquery-dataset", "ci_analysis_us", "Dataset to use")
+	fs.BoolVar(&f.VerifySchemas, "verify-bigquery-schemas", f.VerifySchemas,
+		"Verify the dataset's tables match the schemas sippy expects when connecting, see the bigquery-schemas command")
+	fs.BoolVar(&f.CreateTables, "create-bigquery-tables", f.CreateTables,
+		"Create missing tables in the dataset, partitioned and clustered, when connecting")
 }
 
 func (f *BigQueryFlags) GetBigQueryClient(ctx context.Context, cacheClient cache.Cache, googleServiceAccountCredentialFile string) (*bqcachedclient.Client, error) {
@@ -30,5 +38,19 @@ func (f *BigQueryFlags) GetBigQueryClient(ctx context.Context, cacheClient cache
 		return nil, fmt.Errorf("service account required")
 	}
 
-	return bqcachedclient.New(ctx, googleServiceAccountCredentialFile, f.BigQueryProject, f.BigQueryDataset, cacheClient)
+	client, err := bqcachedclient.New(ctx, googleServiceAccountCredentialFile, f.BigQueryProject, f.BigQueryDataset, cacheClient)
+	if err != nil {
+		return nil, err
+	}
+	if f.CreateTables {
+		if _, err := client.CreateMissingTables(ctx, bqcachedclient.ExpectedTables()); err != nil {
+			return nil, err
+		}
+	}
+	if f.VerifySchemas {
+		if err := client.VerifySchemas(ctx, bqcachedclient.StartupTables); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
 }
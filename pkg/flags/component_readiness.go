@@ -5,7 +5,11 @@ import (
 	"os"
 	"time"
 
+	sippyapi "github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/api/componentreadiness"
 	"github.com/openshift/sippy/pkg/apis/api"
+	"github.com/openshift/sippy/pkg/componentreadiness/notifier"
+	"github.com/openshift/sippy/pkg/notification"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
@@ -21,6 +25,10 @@ var (
 type ComponentReadinessFlags struct {
 	ComponentReadinessViewsFile string
 	CRTimeRoundingFactor        time.Duration
+	NotificationSMTPAddr        string
+	NotificationEmailFrom       string
+	ReportMemoryLimitMB         int
+	TotalReportMemoryLimitMB    int
 }
 
 func NewComponentReadinessFlags() *ComponentReadinessFlags {
@@ -31,6 +39,25 @@ func (f *ComponentReadinessFlags) BindFlags(fs *pflag.FlagSet) {
 	factorUsage := fmt.Sprintf("Set the rounding factor for component readiness release time. The time will be rounded down to the nearest multiple of the factor. Maximum value is %v", maxCRTimeRoundingFactor)
 	fs.StringVar(&f.ComponentReadinessViewsFile, "views", "", "Optional yaml file for predefined Component Readiness views")
 	fs.DurationVar(&f.CRTimeRoundingFactor, "component-readiness-time-rounding-factor", defaultCRTimeRoundingFactor, factorUsage)
+	fs.StringVar(&f.NotificationSMTPAddr, "notification-smtp-addr", "", "host:port of the SMTP server used to e-mail view notifications, credentials are read from SIPPY_SMTP_USERNAME and SIPPY_SMTP_PASSWORD")
+	fs.StringVar(&f.NotificationEmailFrom, "notification-email-from", "sippy@redhat.com", "From address for e-mailed view notifications")
+	fs.IntVar(&f.ReportMemoryLimitMB, "component-report-memory-limit", 2048, "Approximate memory in MB the test status of a single component report may use before the request fails, 0 is unlimited")
+	fs.IntVar(&f.TotalReportMemoryLimitMB, "component-report-total-memory-limit", 0, "Approximate memory in MB the test status of all component reports being generated at once may use, 0 is unlimited")
+}
+
+// SetReportMemoryLimits applies the component report memory limits.
+func (f *ComponentReadinessFlags) SetReportMemoryLimits() {
+	componentreadiness.SetReportMemoryLimits(int64(f.ReportMemoryLimitMB)<<20, int64(f.TotalReportMemoryLimitMB)<<20)
+}
+
+// SMTPConfig returns the mail server configuration for view notifications.
+func (f *ComponentReadinessFlags) SMTPConfig() notification.SMTPConfig {
+	return notification.SMTPConfig{
+		Addr:     f.NotificationSMTPAddr,
+		From:     f.NotificationEmailFrom,
+		Username: os.Getenv("SIPPY_SMTP_USERNAME"),
+		Password: os.Getenv("SIPPY_SMTP_PASSWORD"),
+	}
 }
 
 func (f *ComponentReadinessFlags) ParseViewsFile() (*api.SippyViews, error) {
@@ -62,7 +89,8 @@ func (f *ComponentReadinessFlags) validateViews(views *api.SippyViews) error {
 	// Maps release (4.18) to views in that release with regression tracking on. Length of the slice should not be > 1.
 	viewsWithRegressionTracking := map[string][]string{}
 
-	for _, view := range views.ComponentReadiness {
+	for i := range views.ComponentReadiness {
+		view := &views.ComponentReadiness[i]
 		// If using variant cross compare, those variants must not appear in the dbGroupBy:
 		if len(view.VariantOptions.VariantCrossCompare) > 0 {
 			for _, vcc := range view.VariantOptions.VariantCrossCompare {
@@ -70,6 +98,26 @@ func (f *ComponentReadinessFlags) validateViews(views *api.SippyViews) error {
 					return fmt.Errorf("view %s db_group_by cannot contain variant being cross-compared: %s", view.Name, vcc)
 				}
 			}
+
+			// A cross-compare view without a basis release compares the variants within the sample release
+			// over the same period.
+			if view.BaseRelease.Release == "" {
+				view.BaseRelease = view.SampleRelease
+			}
+		}
+		if view.BaseRelease.Release == "" {
+			return fmt.Errorf("view %s must have a base_release unless cross-comparing variants", view.Name)
+		}
+
+		if err := componentreadiness.ValidateShadowAlgorithms(view.AdvancedOptions.ShadowAlgorithms); err != nil {
+			return fmt.Errorf("view %s: %w", view.Name, err)
+		}
+
+		if len(view.Notifications.Email) > 0 && f.NotificationSMTPAddr == "" {
+			return fmt.Errorf("view %s has e-mail notifications but --notification-smtp-addr is not set", view.Name)
+		}
+		if _, err := notifier.Destinations(view.Notifications, f.SMTPConfig()); err != nil {
+			return fmt.Errorf("view %s: %w", view.Name, err)
 		}
 
 		if view.RegressionTracking.Enabled {
@@ -88,5 +136,5 @@ func (f *ComponentReadinessFlags) validateViews(views *api.SippyViews) error {
 		}
 	}
 
-	return nil
+	return sippyapi.ValidateReleaseDefaults(views.ReleaseDefaults)
 }
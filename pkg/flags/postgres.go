@@ -3,6 +3,7 @@ package flags
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -97,6 +98,11 @@ type PostgresFlags struct {
 	LogLevel logLevel
 	DSN      string
 
+	// ReplicaDSNs are read replicas that read-only queries are sent to, while they lag by no more than
+	// MaxReplicaLag. Only bound by commands serving the API, see BindReplicaFlags.
+	ReplicaDSNs   []string
+	MaxReplicaLag time.Duration
+
 	// pinnedTime should not be exported. Use GetPinnedTime() instead.
 	pinnedTime PinnedTime
 }
@@ -108,8 +114,9 @@ func NewPostgresDatabaseFlags() *PostgresFlags {
 	}
 
 	return &PostgresFlags{
-		LogLevel: logLevel(logger.Info),
-		DSN:      dsn,
+		LogLevel:      logLevel(logger.Info),
+		DSN:           dsn,
+		MaxReplicaLag: 5 * time.Minute,
 	}
 }
 
@@ -119,6 +126,16 @@ func (f *PostgresFlags) BindFlags(fs *pflag.FlagSet) {
 	fs.Var(&f.pinnedTime, "pinned-date-time", "Pin database results to a fixed end date/time")
 }
 
+// BindReplicaFlags binds the read replica flags. Loaders read what they've just written, so replicas are only for
+// commands serving the API.
+func (f *PostgresFlags) BindReplicaFlags(fs *pflag.FlagSet) {
+	if replicas := os.Getenv("SIPPY_DATABASE_REPLICA_DSNS"); replicas != "" {
+		f.ReplicaDSNs = strings.Split(replicas, ",")
+	}
+	fs.StringSliceVar(&f.ReplicaDSNs, "database-replica-dsn", f.ReplicaDSNs, "Database DSN of a Postgres read replica to send read-only queries to, may be repeated")
+	fs.DurationVar(&f.MaxReplicaLag, "database-replica-max-lag", f.MaxReplicaLag, "Replication lag above which read-only queries are sent to the primary instead of a replica")
+}
+
 func (f *PostgresFlags) GetDBClient() (*db.DB, error) {
 	dbc, err := db.New(f.DSN, logger.LogLevel(f.LogLevel))
 	if err != nil {
@@ -126,5 +143,12 @@ func (f *PostgresFlags) GetDBClient() (*db.DB, error) {
 		return nil, err
 	}
 
+	if len(f.ReplicaDSNs) > 0 {
+		if err := dbc.AddReplicas(f.ReplicaDSNs, f.MaxReplicaLag, logger.LogLevel(f.LogLevel)); err != nil {
+			log.WithError(err).Error("could not connect to db replicas")
+			return nil, err
+		}
+	}
+
 	return dbc, nil
 }
@@ -0,0 +1,132 @@
+package jirasync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// searchBatch is the number of issues looked up per JQL search.
+const searchBatch = 50
+
+// Issue is the state of a JIRA issue relevant to syncing.
+type Issue struct {
+	Key string
+	// StatusCategory is the JIRA status category, new, indeterminate or done.
+	StatusCategory string
+	// Resolved is when the issue was resolved, if it has been.
+	Resolved *time.Time
+}
+
+// NewIssue is an issue to open in the configured project.
+type NewIssue struct {
+	Summary     string
+	Description string
+	Labels      []string
+}
+
+// Client is the subset of the JIRA API used to sync.
+type Client interface {
+	CreateIssue(ctx context.Context, issue NewIssue) (string, error)
+	// GetIssues returns the issues with the given keys, keys that don't exist are left out.
+	GetIssues(ctx context.Context, keys []string) (map[string]Issue, error)
+	AddComment(ctx context.Context, key, text string) error
+}
+
+type jiraClient struct {
+	client    *jira.Client
+	project   string
+	issueType string
+}
+
+// NewClient returns a client for the JIRA instance at url, creating issues of the given type in project. A personal
+// access token is sent as a bearer token if given.
+func NewClient(url, token, project, issueType string) (Client, error) {
+	httpClient := &http.Client{Transport: &bearerTransport{token: token}}
+	client, err := jira.NewClient(httpClient, url)
+	if err != nil {
+		return nil, err
+	}
+	return &jiraClient{client: client, project: project, issueType: issueType}, nil
+}
+
+func (c *jiraClient) CreateIssue(ctx context.Context, issue NewIssue) (string, error) {
+	created, resp, err := c.client.Issue.CreateWithContext(ctx, &jira.Issue{
+		Fields: &jira.IssueFields{
+			Project:     jira.Project{Key: c.project},
+			Type:        jira.IssueType{Name: c.issueType},
+			Summary:     issue.Summary,
+			Description: issue.Description,
+			Labels:      issue.Labels,
+		},
+	})
+	if err != nil {
+		return "", responseError(resp, err)
+	}
+	return created.Key, nil
+}
+
+func (c *jiraClient) GetIssues(ctx context.Context, keys []string) (map[string]Issue, error) {
+	issues := map[string]Issue{}
+	for start := 0; start < len(keys); start += searchBatch {
+		end := start + searchBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		jql := fmt.Sprintf("key in (%s)", strings.Join(keys[start:end], ","))
+		// Keys of deleted or moved issues would fail a strict search
+		found, resp, err := c.client.Issue.SearchWithContext(ctx, jql, &jira.SearchOptions{
+			MaxResults:    searchBatch,
+			Fields:        []string{"status", "resolutiondate"},
+			ValidateQuery: "warn",
+		})
+		if err != nil {
+			return nil, responseError(resp, err)
+		}
+		for _, i := range found {
+			issue := Issue{Key: i.Key}
+			if i.Fields != nil {
+				if i.Fields.Status != nil {
+					issue.StatusCategory = i.Fields.Status.StatusCategory.Key
+				}
+				if resolved := time.Time(i.Fields.Resolutiondate); !resolved.IsZero() {
+					issue.Resolved = &resolved
+				}
+			}
+			issues[i.Key] = issue
+		}
+	}
+	return issues, nil
+}
+
+func (c *jiraClient) AddComment(ctx context.Context, key, text string) error {
+	_, resp, err := c.client.Issue.AddCommentWithContext(ctx, key, &jira.Comment{Body: text})
+	if err != nil {
+		return responseError(resp, err)
+	}
+	return nil
+}
+
+// responseError includes JIRA's explanation in the error, i.e. which fields of a new issue were invalid.
+func responseError(resp *jira.Response, err error) error {
+	if resp == nil {
+		return err
+	}
+	return jira.NewJiraError(resp, err)
+}
+
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
@@ -0,0 +1,323 @@
+// Package jirasync keeps sippy's automatically opened incidents and component readiness regressions in step with
+// issues in a JIRA project. Each is given an issue when sippy opens it, the issue's status is reflected back into
+// the record's triage state, and when sippy stops detecting the problem it says so on the issue.
+package jirasync
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+var logger = logging.ForSubsystem("jirasync")
+
+const (
+	// DefaultMaxCreate bounds the issues opened per sync, so the first sync against a database with many open
+	// regressions doesn't flood the project.
+	DefaultMaxCreate = 20
+
+	// DefaultVerificationWindow gives component readiness sample windows time to pick up a fix before deciding it
+	// didn't clear the regression.
+	DefaultVerificationWindow = 7 * 24 * time.Hour
+
+	labelIncident   = "sippy-incident"
+	labelRegression = "sippy-regression"
+)
+
+// Config configures the issues opened by the syncer.
+type Config struct {
+	// Labels are added to every issue, alongside sippy-incident or sippy-regression.
+	Labels []string
+	// SippyURL is linked from issue descriptions, i.e. https://sippy.dptools.openshift.org.
+	SippyURL string
+	// MaxCreate is the maximum number of issues opened per sync.
+	MaxCreate int
+	// VerificationWindow is how long after a fix ships a regression has to clear before its triage is reopened.
+	VerificationWindow time.Duration
+}
+
+// Result counts what a sync changed.
+type Result struct {
+	Created  int `json:"created"`
+	Updated  int `json:"updated"`
+	Comments int `json:"comments"`
+	// Verified and Reopened count the fixes found to have cleared, or not cleared, their regression.
+	Verified int `json:"verified"`
+	Reopened int `json:"reopened"`
+}
+
+// Syncer syncs incidents and regressions with a JIRA project.
+type Syncer struct {
+	dbc    *db.DB
+	client Client
+	config Config
+}
+
+func New(dbc *db.DB, client Client, config Config) *Syncer {
+	if config.MaxCreate == 0 {
+		config.MaxCreate = DefaultMaxCreate
+	}
+	if config.VerificationWindow == 0 {
+		config.VerificationWindow = DefaultVerificationWindow
+	}
+	return &Syncer{dbc: dbc, client: client, config: config}
+}
+
+// TriageState maps a JIRA status category to a triage state.
+func TriageState(statusCategory string) string {
+	switch statusCategory {
+	case jira.StatusCategoryComplete:
+		return models.TriageStateResolved
+	case jira.StatusCategoryInProgress:
+		return models.TriageStateInProgress
+	default:
+		return models.TriageStateNew
+	}
+}
+
+// Sync opens issues for new incidents and regressions, then reconciles linked records with their issues. An error
+// syncing one record doesn't stop the others, all errors are returned.
+func (s *Syncer) Sync(ctx context.Context) (Result, []error) {
+	result := Result{}
+	var errs []error
+
+	var incidents []models.Incident
+	if err := s.dbc.DB.Where("signature <> '' AND (jira_key = '' OR jira_key IS NULL) AND end_time IS NULL").
+		Order("id").Find(&incidents).Error; err != nil {
+		return result, []error{errors.Wrap(err, "error listing incidents without issues")}
+	}
+	var regressions []models.TestRegression
+	if err := s.dbc.DB.Where("(jira_key = '' OR jira_key IS NULL) AND closed IS NULL").
+		Order("id").Find(&regressions).Error; err != nil {
+		return result, []error{errors.Wrap(err, "error listing regressions without issues")}
+	}
+
+	for i := range incidents {
+		if result.Created >= s.config.MaxCreate {
+			break
+		}
+		if err := s.openIncidentIssue(ctx, &incidents[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result.Created++
+	}
+	for i := range regressions {
+		if result.Created >= s.config.MaxCreate {
+			logger.Warningf("opened the maximum of %d issues, the rest will be opened on the next sync", s.config.MaxCreate)
+			break
+		}
+		if err := s.openRegressionIssue(ctx, &regressions[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result.Created++
+	}
+
+	errs = append(errs, s.reconcile(ctx, &result)...)
+	errs = append(errs, s.verifyFixes(ctx, time.Now(), &result)...)
+	return result, errs
+}
+
+func (s *Syncer) openIncidentIssue(ctx context.Context, incident *models.Incident) error {
+	key, err := s.client.CreateIssue(ctx, incidentIssue(*incident, s.config))
+	if err != nil {
+		return errors.Wrapf(err, "error opening issue for incident %d", incident.ID)
+	}
+	logger.WithFields(log.Fields{"incident": incident.ID, "issue": key}).Info("opened jira issue for incident")
+	return s.dbc.DB.Model(incident).Updates(map[string]interface{}{
+		"jira_key":     key,
+		"triage_state": models.TriageStateNew,
+	}).Error
+}
+
+func (s *Syncer) openRegressionIssue(ctx context.Context, regression *models.TestRegression) error {
+	key, err := s.client.CreateIssue(ctx, regressionIssue(*regression, s.config))
+	if err != nil {
+		return errors.Wrapf(err, "error opening issue for regression %d", regression.ID)
+	}
+	logger.WithFields(log.Fields{"regression": regression.ID, "issue": key}).Info("opened jira issue for regression")
+	return s.dbc.DB.Model(regression).Updates(map[string]interface{}{
+		"jira_key":     key,
+		"triage_state": models.TriageStateNew,
+	}).Error
+}
+
+// reconcile syncs linked records until both sippy and JIRA consider them resolved.
+func (s *Syncer) reconcile(ctx context.Context, result *Result) []error {
+	var incidents []models.Incident
+	if err := s.dbc.DB.Where("jira_key <> '' AND (triage_state <> ? OR end_time IS NULL)", models.TriageStateResolved).
+		Find(&incidents).Error; err != nil {
+		return []error{errors.Wrap(err, "error listing linked incidents")}
+	}
+	var regressions []models.TestRegression
+	if err := s.dbc.DB.Where("jira_key <> '' AND (triage_state <> ? OR closed IS NULL)", models.TriageStateResolved).
+		Find(&regressions).Error; err != nil {
+		return []error{errors.Wrap(err, "error listing linked regressions")}
+	}
+
+	keys := []string{}
+	for _, i := range incidents {
+		keys = append(keys, i.JiraKey)
+	}
+	for _, r := range regressions {
+		keys = append(keys, r.JiraKey)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	issues, err := s.client.GetIssues(ctx, keys)
+	if err != nil {
+		return []error{errors.Wrap(err, "error looking up linked issues")}
+	}
+
+	var errs []error
+	for _, incident := range incidents {
+		issue, ok := issues[incident.JiraKey]
+		if !ok {
+			logger.WithField("issue", incident.JiraKey).Warning("linked jira issue not found")
+			continue
+		}
+		u := reconcileIncident(incident, issue, time.Now())
+		if err := s.apply(ctx, &models.Incident{Model: incident.Model}, incident.JiraKey, u, result); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error syncing incident %d", incident.ID))
+		}
+	}
+	for _, regression := range regressions {
+		issue, ok := issues[regression.JiraKey]
+		if !ok {
+			logger.WithField("issue", regression.JiraKey).Warning("linked jira issue not found")
+			continue
+		}
+		u := reconcileRegression(regression, issue)
+		if err := s.apply(ctx, &models.TestRegression{Model: regression.Model}, regression.JiraKey, u, result); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error syncing regression %d", regression.ID))
+		}
+	}
+	return errs
+}
+
+// update is the changes reconciling a record with its issue calls for.
+type update struct {
+	// Fields are set on the sippy record.
+	Fields map[string]interface{}
+	// Comment is added to the issue.
+	Comment string
+}
+
+// reconcileIncident reflects the issue's status into the incident. Starting work on the issue confirms a
+// provisional incident, and resolving it ends the incident, so an issue closed as a false alarm ends it without
+// confirming it. An incident ended in sippy is noted on the issue.
+func reconcileIncident(incident models.Incident, issue Issue, now time.Time) update {
+	u := update{Fields: map[string]interface{}{}}
+	state := TriageState(issue.StatusCategory)
+	if state == models.TriageStateInProgress && incident.Provisional && incident.EndTime == nil {
+		u.Fields["provisional"] = false
+	}
+	if incident.EndTime != nil && state != models.TriageStateResolved {
+		u.Comment = fmt.Sprintf("Sippy ended this incident at %s.", incident.EndTime.UTC().Format(time.RFC3339))
+		state = models.TriageStateResolved
+	}
+	if state == models.TriageStateResolved && incident.EndTime == nil {
+		end := now
+		if issue.Resolved != nil {
+			end = *issue.Resolved
+		}
+		u.Fields["end_time"] = end
+	}
+	if state != incident.TriageState {
+		u.Fields["triage_state"] = state
+	}
+	return u
+}
+
+// reconcileRegression reflects the issue's status into the regression. A regression closed in sippy before its
+// issue is resolved is noted on the issue, and one reopened by fix verification stays reopened while the issue is
+// still resolved.
+func reconcileRegression(regression models.TestRegression, issue Issue) update {
+	u := update{Fields: map[string]interface{}{}}
+	state := TriageState(issue.StatusCategory)
+	if regression.Closed != nil && state != models.TriageStateResolved {
+		u.Comment = fmt.Sprintf("Sippy no longer detects this regression since %s.",
+			regression.Closed.UTC().Format(time.RFC3339))
+		state = models.TriageStateResolved
+	}
+	if regression.Closed == nil && regression.TriageState == models.TriageStateReopened && state == models.TriageStateResolved {
+		// The fix didn't clear the regression, keep it reopened until the issue is
+		state = models.TriageStateReopened
+	}
+	if state != regression.TriageState {
+		u.Fields["triage_state"] = state
+	}
+	return u
+}
+
+func (s *Syncer) apply(ctx context.Context, record interface{}, key string, u update, result *Result) error {
+	if u.Comment != "" {
+		if err := s.client.AddComment(ctx, key, u.Comment); err != nil {
+			return err
+		}
+		result.Comments++
+	}
+	if len(u.Fields) == 0 {
+		return nil
+	}
+	if err := s.dbc.DB.Model(record).Updates(u.Fields).Error; err != nil {
+		return err
+	}
+	result.Updated++
+	return nil
+}
+
+func incidentIssue(incident models.Incident, config Config) NewIssue {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Sippy opened incident %d at %s.\n\n", incident.ID, incident.StartTime.UTC().Format(time.RFC3339))
+	if incident.Description != "" {
+		fmt.Fprintf(&sb, "%s\n\n", incident.Description)
+	}
+	if incident.Signature != "" {
+		fmt.Fprintf(&sb, "Failure signature: {{%s}}\n", incident.Signature)
+	}
+	if incident.Release != "" {
+		fmt.Fprintf(&sb, "Release: %s\n", incident.Release)
+	}
+	if len(incident.Variants) > 0 {
+		fmt.Fprintf(&sb, "Variants: %s\n", strings.Join(incident.Variants, ", "))
+	}
+	if config.SippyURL != "" {
+		fmt.Fprintf(&sb, "\n%s/api/incidents/timeline?id=%d\n", strings.TrimSuffix(config.SippyURL, "/"), incident.ID)
+	}
+	return NewIssue{
+		Summary:     "Incident: " + incident.Title,
+		Description: sb.String(),
+		Labels:      append(append([]string{}, config.Labels...), labelIncident),
+	}
+}
+
+func regressionIssue(regression models.TestRegression, config Config) NewIssue {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Component readiness view %s detected a regression at %s.\n\n", regression.View,
+		regression.Opened.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Test: {{%s}}\n", regression.TestName)
+	fmt.Fprintf(&sb, "Component: %s\n", regression.Component)
+	fmt.Fprintf(&sb, "Variants: %s\n", strings.Join(regression.Variants, ", "))
+	if config.SippyURL != "" {
+		fmt.Fprintf(&sb, "\n%s/api/component_readiness/regressions?release=%s&component=%s\n",
+			strings.TrimSuffix(config.SippyURL, "/"), url.QueryEscape(regression.Release), url.QueryEscape(regression.Component))
+	}
+	return NewIssue{
+		Summary:     fmt.Sprintf("Regression: %s [%s]", regression.TestName, strings.Join(regression.Variants, " ")),
+		Description: sb.String(),
+		Labels:      append(append([]string{}, config.Labels...), labelRegression, "release-"+regression.Release),
+	}
+}
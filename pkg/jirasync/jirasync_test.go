@@ -0,0 +1,155 @@
+package jirasync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestReconcileIncident(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	resolved := time.Date(2024, 6, 9, 8, 0, 0, 0, time.UTC)
+	ended := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		incident        models.Incident
+		issue           Issue
+		expectedFields  map[string]interface{}
+		expectedComment bool
+	}{
+		{
+			name:           "unchanged",
+			incident:       models.Incident{Provisional: true, TriageState: models.TriageStateNew},
+			issue:          Issue{StatusCategory: "new"},
+			expectedFields: map[string]interface{}{},
+		},
+		{
+			name:     "work started confirms a provisional incident",
+			incident: models.Incident{Provisional: true, TriageState: models.TriageStateNew},
+			issue:    Issue{StatusCategory: "indeterminate"},
+			expectedFields: map[string]interface{}{
+				"provisional":  false,
+				"triage_state": models.TriageStateInProgress,
+			},
+		},
+		{
+			name:     "resolved issue ends the incident without confirming it",
+			incident: models.Incident{Provisional: true, TriageState: models.TriageStateNew},
+			issue:    Issue{StatusCategory: "done", Resolved: &resolved},
+			expectedFields: map[string]interface{}{
+				"end_time":     resolved,
+				"triage_state": models.TriageStateResolved,
+			},
+		},
+		{
+			name:            "incident ended in sippy is noted on the issue",
+			incident:        models.Incident{EndTime: &ended, TriageState: models.TriageStateInProgress},
+			issue:           Issue{StatusCategory: "indeterminate"},
+			expectedFields:  map[string]interface{}{"triage_state": models.TriageStateResolved},
+			expectedComment: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := reconcileIncident(tt.incident, tt.issue, now)
+			assert.Equal(t, tt.expectedFields, u.Fields)
+			assert.Equal(t, tt.expectedComment, u.Comment != "")
+		})
+	}
+}
+
+func TestReconcileRegression(t *testing.T) {
+	closed := time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC)
+
+	u := reconcileRegression(models.TestRegression{TriageState: models.TriageStateNew}, Issue{StatusCategory: "indeterminate"})
+	assert.Equal(t, map[string]interface{}{"triage_state": models.TriageStateInProgress}, u.Fields)
+	assert.Empty(t, u.Comment)
+
+	u = reconcileRegression(models.TestRegression{Closed: &closed, TriageState: models.TriageStateInProgress}, Issue{StatusCategory: "indeterminate"})
+	assert.Equal(t, map[string]interface{}{"triage_state": models.TriageStateResolved}, u.Fields)
+	assert.Contains(t, u.Comment, "2024-06-08T00:00:00Z")
+
+	// Both sides resolved, nothing left to do
+	u = reconcileRegression(models.TestRegression{Closed: &closed, TriageState: models.TriageStateResolved}, Issue{StatusCategory: "done"})
+	assert.Empty(t, u.Fields)
+	assert.Empty(t, u.Comment)
+}
+
+func TestRegressionIssue(t *testing.T) {
+	regression := models.TestRegression{
+		View:      "4.16-main",
+		Release:   "4.16",
+		TestName:  "[sig-network] pods should connect",
+		Component: "Networking / cluster-network-operator",
+		Variants:  pq.StringArray{"Arch:amd64", "Platform:aws"},
+	}
+	issue := regressionIssue(regression, Config{Labels: []string{"trt"}, SippyURL: "https://sippy.example.com/"})
+	assert.Equal(t, "Regression: [sig-network] pods should connect [Arch:amd64 Platform:aws]", issue.Summary)
+	assert.Equal(t, []string{"trt", labelRegression, "release-4.16"}, issue.Labels)
+	assert.Contains(t, issue.Description,
+		"https://sippy.example.com/api/component_readiness/regressions?release=4.16&component=Networking+%2F+cluster-network-operator")
+}
+
+func TestGetIssues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "key in (TRT-1,TRT-2)", r.URL.Query().Get("jql"))
+		_, _ = w.Write([]byte(`{"issues": [
+			{"key": "TRT-1", "fields": {"status": {"statusCategory": {"key": "done"}}, "resolutiondate": "2024-06-09T08:00:00.000+0000"}},
+			{"key": "TRT-2", "fields": {"status": {"statusCategory": {"key": "indeterminate"}}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "secret", "TRT", "Bug")
+	require.NoError(t, err)
+	issues, err := client.GetIssues(context.Background(), []string{"TRT-1", "TRT-2"})
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, models.TriageStateResolved, TriageState(issues["TRT-1"].StatusCategory))
+	require.NotNil(t, issues["TRT-1"].Resolved)
+	assert.True(t, issues["TRT-1"].Resolved.Equal(time.Date(2024, 6, 9, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, models.TriageStateInProgress, TriageState(issues["TRT-2"].StatusCategory))
+	assert.Nil(t, issues["TRT-2"].Resolved)
+}
+
+func TestVerifyFix(t *testing.T) {
+	fixedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	closed := fixedAt.Add(48 * time.Hour)
+	pending := models.TestRegression{FixedIn: "4.16.0-0.nightly-2024-06-01-000000", FixedAt: &fixedAt, FixVerification: models.FixVerificationPending}
+
+	// Still within the window
+	u := verifyFix(pending, fixedAt.Add(3*24*time.Hour), DefaultVerificationWindow)
+	assert.Empty(t, u.Fields)
+	assert.Empty(t, u.Comment)
+
+	cleared := pending
+	cleared.Closed = &closed
+	u = verifyFix(cleared, fixedAt.Add(3*24*time.Hour), DefaultVerificationWindow)
+	assert.Equal(t, map[string]interface{}{"fix_verification": models.FixVerificationCleared}, u.Fields)
+	assert.Empty(t, u.Comment)
+
+	u = verifyFix(pending, fixedAt.Add(8*24*time.Hour), DefaultVerificationWindow)
+	assert.Equal(t, map[string]interface{}{
+		"fix_verification": models.FixVerificationNotCleared,
+		"triage_state":     models.TriageStateReopened,
+	}, u.Fields)
+	assert.Contains(t, u.Comment, "4.16.0-0.nightly-2024-06-01-000000")
+	assert.Contains(t, u.Comment, "8 days later")
+
+	// A reopened regression stays reopened while its issue is resolved, and follows the issue once it's reopened
+	reopened := models.TestRegression{TriageState: models.TriageStateReopened}
+	u = reconcileRegression(reopened, Issue{StatusCategory: "done"})
+	assert.Empty(t, u.Fields)
+	u = reconcileRegression(reopened, Issue{StatusCategory: "indeterminate"})
+	assert.Equal(t, map[string]interface{}{"triage_state": models.TriageStateInProgress}, u.Fields)
+}
@@ -0,0 +1,92 @@
+package jirasync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/db/query"
+)
+
+// verifyFixes watches regressions whose JIRA issue was fixed in an accepted payload. A regression that closes within
+// the verification window after the fix shipped is verified, one that doesn't has its triage reopened and the
+// issue is told the fix didn't clear it.
+func (s *Syncer) verifyFixes(ctx context.Context, now time.Time, result *Result) []error {
+	var regressions []models.TestRegression
+	err := s.dbc.DB.Where("jira_key <> ''").
+		Where("((fix_verification IS NULL OR fix_verification = '') AND closed IS NULL) OR fix_verification = ?", models.FixVerificationPending).
+		Find(&regressions).Error
+	if err != nil {
+		return []error{errors.Wrap(err, "error listing regressions awaiting fix verification")}
+	}
+
+	var errs []error
+	for _, r := range regressions {
+		if r.FixVerification == "" {
+			payload, err := query.FirstPayloadFixingBug(s.dbc, r.Release, r.JiraKey)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "error looking up fix for regression %d", r.ID))
+				continue
+			}
+			if payload == nil {
+				continue
+			}
+			fixedAt := payload.ReleaseTime
+			r.FixedIn, r.FixedAt, r.FixVerification = payload.ReleaseTag, &fixedAt, models.FixVerificationPending
+			err = s.dbc.DB.Model(&models.TestRegression{Model: r.Model}).Updates(map[string]interface{}{
+				"fixed_in":         r.FixedIn,
+				"fixed_at":         fixedAt,
+				"fix_verification": r.FixVerification,
+			}).Error
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "error recording fix for regression %d", r.ID))
+				continue
+			}
+			logger.WithFields(log.Fields{"regression": r.ID, "issue": r.JiraKey, "payload": r.FixedIn}).
+				Info("fix for regression shipped, verifying")
+		}
+
+		u := verifyFix(r, now, s.config.VerificationWindow)
+		if len(u.Fields) == 0 {
+			continue
+		}
+		if err := s.apply(ctx, &models.TestRegression{Model: r.Model}, r.JiraKey, u, result); err != nil {
+			errs = append(errs, errors.Wrapf(err, "error verifying fix for regression %d", r.ID))
+			continue
+		}
+		if u.Fields["fix_verification"] == models.FixVerificationCleared {
+			result.Verified++
+		} else {
+			result.Reopened++
+		}
+	}
+	return errs
+}
+
+// verifyFix decides whether a regression with a pending fix cleared, as of now.
+func verifyFix(r models.TestRegression, now time.Time, window time.Duration) update {
+	u := update{Fields: map[string]interface{}{}}
+	switch {
+	case r.FixedAt == nil:
+	case r.Closed != nil:
+		u.Fields["fix_verification"] = models.FixVerificationCleared
+	case now.After(r.FixedAt.Add(window)):
+		u.Fields["fix_verification"] = models.FixVerificationNotCleared
+		u.Fields["triage_state"] = models.TriageStateReopened
+		u.Comment = fmt.Sprintf("The fix shipped in %s at %s, but sippy still detects this regression %s later.",
+			r.FixedIn, r.FixedAt.UTC().Format(time.RFC3339), formatDays(now.Sub(*r.FixedAt)))
+	}
+	return u
+}
+
+func formatDays(d time.Duration) string {
+	days := int(d.Hours() / 24)
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
@@ -0,0 +1,272 @@
+// Package jobpurge deletes all the data for jobs matching a selector, i.e. long-removed periodics of an old release,
+// from Postgres and the BigQuery tables sippy owns. Purges are planned first, and only carried out when given the
+// confirmation token of an unchanged plan, so nothing is deleted without the caller having seen the counts.
+package jobpurge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	bqclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/logging"
+)
+
+var logger = logging.ForSubsystem("jobpurge")
+
+// maxListedJobs bounds the job names returned in a plan, the counts always cover every job.
+const maxListedJobs = 100
+
+// ErrTokenMismatch is returned when purging with a token that doesn't match the current plan, either because it's
+// for a different selector or because the data changed since the plan was made.
+var ErrTokenMismatch = errors.New("confirmation token does not match the current plan, plan the purge again")
+
+// Selector chooses the jobs to purge. At least a release or job regex is required, so a purge can't select every
+// job by accident.
+type Selector struct {
+	Release string `json:"release"`
+	// JobRegex is a Postgres regular expression matched against job names.
+	JobRegex string `json:"job_regex"`
+	// Variants the jobs must all have, in the form Name:value.
+	Variants []string `json:"variants"`
+	// NoRunsSince limits the purge to jobs without any runs since this time.
+	NoRunsSince *time.Time `json:"no_runs_since"`
+}
+
+// Validate ensures the selector narrows down the jobs.
+func (s Selector) Validate() error {
+	if s.Release == "" && s.JobRegex == "" {
+		return fmt.Errorf("release or job_regex is required")
+	}
+	if s.JobRegex != "" {
+		if _, err := regexp.Compile(s.JobRegex); err != nil {
+			return errors.Wrap(err, "invalid job_regex")
+		}
+	}
+	return nil
+}
+
+// TableRows is the number of rows of a table the purge deletes.
+type TableRows struct {
+	Table string `json:"table"`
+	Rows  int64  `json:"rows"`
+}
+
+// Plan is what a purge would delete. Token confirms the purge, and changes whenever the selected jobs or counts do.
+type Plan struct {
+	Selector Selector    `json:"selector"`
+	Jobs     int         `json:"jobs"`
+	JobNames []string    `json:"job_names"`
+	Tables   []TableRows `json:"tables"`
+	Token    string      `json:"token"`
+	// Purged is set once the plan has been carried out.
+	Purged bool `json:"purged"`
+
+	jobIDs   []uint
+	allNames []string
+}
+
+// runIDs selects the runs of the purged jobs.
+const runIDs = "SELECT id FROM prow_job_runs WHERE prow_job_id IN @jobs"
+
+// postgresTables are deleted from in order, so rows are removed before those they reference.
+var postgresTables = []struct {
+	table string
+	where string
+}{
+	{"prow_job_run_test_output_metadata", "prow_job_run_test_output_id IN (SELECT id FROM prow_job_run_test_outputs WHERE prow_job_run_test_id IN (SELECT id FROM prow_job_run_tests WHERE prow_job_run_id IN (" + runIDs + ")))"},
+	{"prow_job_run_test_outputs", "prow_job_run_test_id IN (SELECT id FROM prow_job_run_tests WHERE prow_job_run_id IN (" + runIDs + "))"},
+	{"prow_job_run_tests", "prow_job_run_id IN (" + runIDs + ")"},
+	{"prow_job_run_operator_conditions", "prow_job_run_id IN (" + runIDs + ")"},
+	{"prow_job_run_alerts", "prow_job_run_id IN (" + runIDs + ")"},
+	{"prow_job_run_event_patterns", "prow_job_run_id IN (" + runIDs + ")"},
+	{"prow_job_run_fingerprints", "prow_job_run_id IN (" + runIDs + ")"},
+	{"prow_job_run_prow_pull_requests", "prow_job_run_id IN (" + runIDs + ")"},
+	{"job_run_import_failures", "prow_job_run_id IN (" + runIDs + ")"},
+	{"manual_job_runs", "prow_job_run_id IN (" + runIDs + ")"},
+	{"release_job_runs", "prow_job_run_id IN (" + runIDs + ")"},
+	{"prow_job_runs", "prow_job_id IN @jobs"},
+	{"bug_jobs", "prow_job_id IN @jobs"},
+	{"variant_changes", "prow_job_id IN @jobs"},
+	{"job_owner_changes", "job_name IN @names"},
+	{"prow_jobs", "id IN @jobs"},
+}
+
+// bigQueryJobVariants is the table the purge deletes from in BigQuery. The junit and jobs tables are written by
+// other CI tooling, so sippy doesn't delete from them.
+const bigQueryJobVariants = "bigquery:" + bqclient.JobVariantsTable
+
+// NewPlan selects the jobs to purge and counts the rows that would be deleted from each table. The BigQuery variant
+// registry is only counted when a client is given.
+func NewPlan(ctx context.Context, dbc *db.DB, bqc *bqclient.Client, selector Selector) (*Plan, error) {
+	if err := selector.Validate(); err != nil {
+		return nil, err
+	}
+
+	jobs, err := selectJobs(dbc.DB.WithContext(ctx), selector)
+	if err != nil {
+		return nil, errors.Wrap(err, "error selecting jobs")
+	}
+	plan := &Plan{Selector: selector, Jobs: len(jobs), JobNames: []string{}, Tables: []TableRows{}}
+	for _, j := range jobs {
+		plan.jobIDs = append(plan.jobIDs, j.ID)
+		plan.allNames = append(plan.allNames, j.Name)
+	}
+	if len(plan.allNames) > maxListedJobs {
+		plan.JobNames = append(plan.JobNames, plan.allNames[:maxListedJobs]...)
+	} else {
+		plan.JobNames = append(plan.JobNames, plan.allNames...)
+	}
+
+	if len(jobs) > 0 {
+		for _, t := range postgresTables {
+			var rows int64
+			res := dbc.DB.WithContext(ctx).Raw(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", t.table, t.where),
+				plan.params()).Row()
+			if err := res.Scan(&rows); err != nil {
+				return nil, errors.Wrapf(err, "error counting %s rows", t.table)
+			}
+			plan.Tables = append(plan.Tables, TableRows{Table: t.table, Rows: rows})
+		}
+		if bqc != nil {
+			rows, err := countJobVariants(ctx, bqc, plan.allNames)
+			if err != nil {
+				return nil, errors.Wrap(err, "error counting BigQuery job variants")
+			}
+			plan.Tables = append(plan.Tables, TableRows{Table: bigQueryJobVariants, Rows: rows})
+		}
+	}
+
+	plan.Token = plan.token()
+	return plan, nil
+}
+
+func (p *Plan) params() map[string]interface{} {
+	return map[string]interface{}{"jobs": p.jobIDs, "names": p.allNames}
+}
+
+// token hashes the selector, the selected jobs and the row counts, so it no longer matches once any of them change.
+func (p *Plan) token() string {
+	h := sha256.New()
+	selector, _ := json.Marshal(p.Selector)
+	h.Write(selector)
+	for _, id := range p.jobIDs {
+		fmt.Fprintf(h, "|%d", id)
+	}
+	for _, t := range p.Tables {
+		fmt.Fprintf(h, "|%s=%d", t.Table, t.Rows)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+type selectedJob struct {
+	ID   uint
+	Name string
+}
+
+func selectJobs(dbc *gorm.DB, selector Selector) ([]selectedJob, error) {
+	jobs := []selectedJob{}
+	q := dbc.Table("prow_jobs").Select("id", "name")
+	if selector.Release != "" {
+		q = q.Where("release = ?", selector.Release)
+	}
+	if selector.JobRegex != "" {
+		q = q.Where("name ~ ?", selector.JobRegex)
+	}
+	for _, v := range selector.Variants {
+		q = q.Where("? = ANY(variants)", v)
+	}
+	if selector.NoRunsSince != nil {
+		q = q.Where("NOT EXISTS (SELECT 1 FROM prow_job_runs WHERE prow_job_runs.prow_job_id = prow_jobs.id AND prow_job_runs.timestamp >= ?)",
+			*selector.NoRunsSince)
+	}
+	res := q.Order("id").Scan(&jobs)
+	return jobs, res.Error
+}
+
+// Purge deletes everything the plan for the selector counts, provided the token matches it. BigQuery is purged
+// first: deleting there is idempotent, so if the Postgres transaction then fails, planning and purging again
+// selects the same jobs and completes the purge.
+func Purge(ctx context.Context, dbc *db.DB, bqc *bqclient.Client, selector Selector, token string) (*Plan, error) {
+	plan, err := NewPlan(ctx, dbc, bqc, selector)
+	if err != nil {
+		return nil, err
+	}
+	if token != plan.Token {
+		return plan, ErrTokenMismatch
+	}
+	if plan.Jobs == 0 {
+		plan.Purged = true
+		return plan, nil
+	}
+
+	if bqc != nil {
+		if err := deleteJobVariants(ctx, bqc, plan.allNames); err != nil {
+			return plan, errors.Wrap(err, "error deleting BigQuery job variants")
+		}
+	}
+	err = dbc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, t := range postgresTables {
+			now := time.Now()
+			res := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s", t.table, t.where), plan.params())
+			if res.Error != nil {
+				return errors.Wrapf(res.Error, "error deleting from %s", t.table)
+			}
+			logger.WithFields(log.Fields{
+				"table":   t.table,
+				"rows":    res.RowsAffected,
+				"elapsed": time.Since(now),
+			}).Info("purged job data")
+		}
+		return nil
+	})
+	if err != nil {
+		return plan, err
+	}
+	plan.Purged = true
+	return plan, nil
+}
+
+func jobVariantsTable(bqc *bqclient.Client) string {
+	return fmt.Sprintf("`%s.%s.%s`", bqc.BQ.Project(), bqc.Dataset, bqclient.JobVariantsTable)
+}
+
+func countJobVariants(ctx context.Context, bqc *bqclient.Client, jobs []string) (int64, error) {
+	q := bqc.BQ.Query("SELECT COUNT(*) AS row_count FROM " + jobVariantsTable(bqc) + " WHERE job_name IN UNNEST(@jobs)")
+	q.Parameters = []bigquery.QueryParameter{{Name: "jobs", Value: jobs}}
+	it, err := q.Read(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var row struct {
+		RowCount int64 `bigquery:"row_count"`
+	}
+	if err := it.Next(&row); err != nil {
+		return 0, err
+	}
+	return row.RowCount, nil
+}
+
+func deleteJobVariants(ctx context.Context, bqc *bqclient.Client, jobs []string) error {
+	q := bqc.BQ.Query("DELETE FROM " + jobVariantsTable(bqc) + " WHERE job_name IN UNNEST(@jobs)")
+	q.Parameters = []bigquery.QueryParameter{{Name: "jobs", Value: jobs}}
+	job, err := q.Run(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
@@ -0,0 +1,43 @@
+package jobpurge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectorValidate(t *testing.T) {
+	assert.NoError(t, Selector{Release: "4.10"}.Validate())
+	assert.NoError(t, Selector{JobRegex: "^periodic-.*-4.10-"}.Validate())
+	assert.Error(t, Selector{}.Validate())
+	assert.Error(t, Selector{Variants: []string{"Owner:eng"}}.Validate())
+	assert.Error(t, Selector{JobRegex: "periodic-("}.Validate())
+}
+
+func TestPlanToken(t *testing.T) {
+	since := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	plan := func() *Plan {
+		return &Plan{
+			Selector: Selector{Release: "4.10", NoRunsSince: &since},
+			jobIDs:   []uint{1, 2},
+			Tables:   []TableRows{{Table: "prow_job_runs", Rows: 10}},
+		}
+	}
+
+	token := plan().token()
+	assert.Len(t, token, 32)
+	assert.Equal(t, token, plan().token())
+
+	moreRuns := plan()
+	moreRuns.Tables[0].Rows = 11
+	assert.NotEqual(t, token, moreRuns.token())
+
+	moreJobs := plan()
+	moreJobs.jobIDs = append(moreJobs.jobIDs, 3)
+	assert.NotEqual(t, token, moreJobs.token())
+
+	otherSelector := plan()
+	otherSelector.Selector.JobRegex = "periodic"
+	assert.NotEqual(t, token, otherSelector.token())
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubsystemLevels(t *testing.T) {
+	std := log.StandardLogger()
+	origOut, origFormatter, origLevel := std.Out, std.Formatter, std.GetLevel()
+	defer func() {
+		log.SetOutput(origOut)
+		log.SetFormatter(origFormatter)
+		SetDefaultLevel(origLevel)
+	}()
+	out := &bytes.Buffer{}
+	log.SetOutput(out)
+	log.SetFormatter(&log.TextFormatter{DisableTimestamp: true, DisableColors: true})
+	SetDefaultLevel(log.InfoLevel)
+
+	loader := ForSubsystem("test-loader")
+	other := ForSubsystem("test-other")
+
+	loader.Debug("hidden")
+	assert.Empty(t, out.String())
+
+	level, err := SetLevel("test-loader", log.DebugLevel)
+	require.NoError(t, err)
+	assert.Equal(t, Level{Subsystem: "test-loader", Level: "debug", Overridden: true}, level)
+
+	loader.Debug("shown")
+	other.Debug("hidden")
+	assert.Contains(t, out.String(), "subsystem=test-loader")
+	assert.Contains(t, out.String(), "shown")
+	assert.NotContains(t, out.String(), "hidden")
+
+	// the default level doesn't change overridden subsystems
+	SetDefaultLevel(log.WarnLevel)
+	levels := map[string]Level{}
+	for _, l := range Levels() {
+		levels[l.Subsystem] = l
+	}
+	assert.Equal(t, "debug", levels["test-loader"].Level)
+	assert.Equal(t, "warning", levels["test-other"].Level)
+
+	level, err = ResetLevel("test-loader")
+	require.NoError(t, err)
+	assert.Equal(t, Level{Subsystem: "test-loader", Level: "warning"}, level)
+
+	_, err = SetLevel("missing", log.DebugLevel)
+	assert.ErrorIs(t, err, ErrUnknownSubsystem)
+	assert.Panics(t, func() { ForSubsystem("Not Valid") })
+}
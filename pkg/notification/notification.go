@@ -0,0 +1,189 @@
+// Package notification delivers sippy's outbound notifications, i.e. component readiness changes, watchlist events,
+// anomalies and provisional incidents, to Slack, Google Chat, generic webhooks and e-mail. Each destination can render
+// the message text with its own template, deliveries to the same destination are rate limited, and every delivery
+// is counted in the sippy_notifications_total metric.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultInterval is the minimum time between deliveries to the same destination. Slack and Google Chat both
+	// throttle incoming webhooks to around one message per second.
+	DefaultInterval = time.Second
+	// DefaultMaxWait is the longest a delivery waits for its destination's rate limit before being dropped.
+	DefaultMaxWait = time.Minute
+)
+
+// ErrRateLimited is returned when a destination has too many deliveries queued to send a message within MaxWait.
+var ErrRateLimited = errors.New("notification rate limit exceeded")
+
+var (
+	deliveryMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sippy_notifications_total",
+		Help: "Notifications delivered by transport and result (success, error or rate_limited).",
+	}, []string{"transport", "result"})
+	deliveryDurationMetric = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sippy_notification_delivery_seconds",
+		Help:    "Time taken to deliver a notification, by transport.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"transport"})
+)
+
+// Message is a notification to deliver. Text is a plain text summary, which the chat transports and e-mail send
+// as is, and Data is the structured payload posted to generic webhooks.
+type Message struct {
+	Subject string
+	Text    string
+	Data    interface{}
+}
+
+// Transport delivers a message to one destination.
+type Transport interface {
+	// Kind names the type of transport, i.e. slack, googlechat, webhook or email, for metrics and errors.
+	Kind() string
+	// Key identifies the destination for rate limiting.
+	Key() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Destination is a transport with an optional template for the message text. The template is executed with the
+// Message, so can refer to .Subject, .Text and the fields of .Data.
+type Destination struct {
+	Transport Transport
+	Template  *template.Template
+}
+
+// NewDestination parses a destination spec (see ParseTransport) and, if not empty, its text template.
+func NewDestination(spec, textTemplate string, smtpConfig SMTPConfig) (Destination, error) {
+	transport, err := ParseTransport(spec, smtpConfig)
+	if err != nil {
+		return Destination{}, err
+	}
+	dest := Destination{Transport: transport}
+	if textTemplate != "" {
+		dest.Template, err = template.New(transport.Kind()).Parse(textTemplate)
+		if err != nil {
+			return Destination{}, errors.Wrap(err, "invalid notification template")
+		}
+	}
+	return dest, nil
+}
+
+// render returns the message with its text produced by the destination's template, if any.
+func (d Destination) render(msg Message) (Message, error) {
+	if d.Template == nil {
+		return msg, nil
+	}
+	var sb bytes.Buffer
+	if err := d.Template.Execute(&sb, msg); err != nil {
+		return msg, errors.Wrap(err, "error executing notification template")
+	}
+	msg.Text = sb.String()
+	return msg, nil
+}
+
+// Dispatcher sends messages to destinations, spacing deliveries to each destination at least Interval apart.
+type Dispatcher struct {
+	Interval time.Duration
+	MaxWait  time.Duration
+
+	lock sync.Mutex
+	// next is the earliest time the next delivery to each destination may be sent.
+	next map[string]time.Time
+}
+
+func NewDispatcher(interval, maxWait time.Duration) *Dispatcher {
+	return &Dispatcher{Interval: interval, MaxWait: maxWait, next: map[string]time.Time{}}
+}
+
+var defaultDispatcher = NewDispatcher(DefaultInterval, DefaultMaxWait)
+
+// Send delivers the message to each destination using the default dispatcher.
+func Send(ctx context.Context, msg Message, destinations ...Destination) error {
+	return defaultDispatcher.Send(ctx, msg, destinations...)
+}
+
+// Send delivers the message to each destination. A failed delivery doesn't stop the others, the errors of all
+// failed deliveries are returned together.
+func (d *Dispatcher) Send(ctx context.Context, msg Message, destinations ...Destination) error {
+	var errs []string
+	for _, dest := range destinations {
+		if err := d.deliver(ctx, dest, msg); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", dest.Transport.Kind(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, dest Destination, msg Message) error {
+	kind := dest.Transport.Kind()
+	msg, err := dest.render(msg)
+	if err != nil {
+		deliveryMetric.WithLabelValues(kind, "error").Inc()
+		return err
+	}
+	if err := d.wait(ctx, dest.Transport.Key()); err != nil {
+		deliveryMetric.WithLabelValues(kind, "rate_limited").Inc()
+		return err
+	}
+
+	start := time.Now()
+	err = dest.Transport.Send(ctx, msg)
+	deliveryDurationMetric.WithLabelValues(kind).Observe(time.Since(start).Seconds())
+	if err != nil {
+		deliveryMetric.WithLabelValues(kind, "error").Inc()
+		return err
+	}
+	deliveryMetric.WithLabelValues(kind, "success").Inc()
+	log.WithFields(log.Fields{
+		"transport": kind,
+		"elapsed":   time.Since(start),
+	}).Debug("delivered notification")
+	return nil
+}
+
+// wait reserves the destination's next delivery slot and sleeps until it, unless the slot is more than MaxWait
+// away or the context ends first.
+func (d *Dispatcher) wait(ctx context.Context, key string) error {
+	d.lock.Lock()
+	now := time.Now()
+	slot := d.next[key]
+	if slot.Before(now) {
+		slot = now
+	}
+	delay := slot.Sub(now)
+	if delay > d.MaxWait {
+		d.lock.Unlock()
+		return ErrRateLimited
+	}
+	d.next[key] = slot.Add(d.Interval)
+	d.lock.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTransport(t *testing.T) {
+	tests := []struct {
+		spec         string
+		expectedKind string
+		expectedKey  string
+	}{
+		{spec: "https://hooks.slack.com/services/T0/B0/X", expectedKind: KindSlack, expectedKey: "https://hooks.slack.com/services/T0/B0/X"},
+		{spec: "https://chat.googleapis.com/v1/spaces/S/messages?key=k", expectedKind: KindGoogleChat, expectedKey: "https://chat.googleapis.com/v1/spaces/S/messages?key=k"},
+		{spec: "https://example.com/hook", expectedKind: KindWebhook, expectedKey: "https://example.com/hook"},
+		{spec: "slack:https://proxy.example.com/slack", expectedKind: KindSlack, expectedKey: "https://proxy.example.com/slack"},
+		{spec: "webhook:https://hooks.slack.com/services/T0/B0/X", expectedKind: KindWebhook, expectedKey: "https://hooks.slack.com/services/T0/B0/X"},
+		{spec: "mailto:a@example.com,b@example.com", expectedKind: KindEmail, expectedKey: "mailto:a@example.com,b@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			transport, err := ParseTransport(tt.spec, SMTPConfig{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedKind, transport.Kind())
+			assert.Equal(t, tt.expectedKey, transport.Key())
+		})
+	}
+
+	_, err := ParseTransport("pager:team", SMTPConfig{})
+	assert.Error(t, err)
+}
+
+func TestDestinationTemplate(t *testing.T) {
+	dest, err := NewDestination("https://example.com/hook", "{{ .Subject }}: {{ .Data.Count }} changes", SMTPConfig{})
+	require.NoError(t, err)
+	msg, err := dest.render(Message{Subject: "Watchlist", Text: "ignored", Data: struct{ Count int }{3}})
+	require.NoError(t, err)
+	assert.Equal(t, "Watchlist: 3 changes", msg.Text)
+
+	_, err = NewDestination("https://example.com/hook", "{{ .Subject", SMTPConfig{})
+	assert.Error(t, err)
+}
+
+func TestDispatcherSend(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		bodies = append(bodies, body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(0, time.Minute)
+	ctx := context.Background()
+	data := map[string]interface{}{"text": "hello", "count": 1}
+
+	// Generic webhooks are posted the structured data, or the text if there isn't any
+	require.NoError(t, d.Send(ctx, Message{Text: "hello", Data: data}, Destination{Transport: &WebhookTransport{URL: server.URL}}))
+	require.NoError(t, d.Send(ctx, Message{Text: "hello"}, Destination{Transport: &WebhookTransport{URL: server.URL}}))
+	// Chat transports are only posted the text
+	require.NoError(t, d.Send(ctx, Message{Text: "hello", Data: data}, Destination{Transport: &SlackTransport{URL: server.URL}}))
+	require.Len(t, bodies, 3)
+	assert.Equal(t, map[string]interface{}{"text": "hello", "count": float64(1)}, bodies[0])
+	assert.Equal(t, map[string]interface{}{"text": "hello"}, bodies[1])
+	assert.Equal(t, map[string]interface{}{"text": "hello"}, bodies[2])
+
+	// A failed delivery doesn't stop the others
+	err := d.Send(ctx, Message{Text: "hello"},
+		Destination{Transport: &GoogleChatTransport{URL: server.URL + "/fail"}},
+		Destination{Transport: &WebhookTransport{URL: server.URL}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "googlechat: webhook returned unexpected status 500")
+	assert.Len(t, bodies, 5)
+}
+
+func TestDispatcherRateLimit(t *testing.T) {
+	d := NewDispatcher(time.Hour, time.Second)
+	ctx := context.Background()
+
+	require.NoError(t, d.wait(ctx, "a"))
+	// The next slot for the same destination is an hour away
+	assert.ErrorIs(t, d.wait(ctx, "a"), ErrRateLimited)
+	// Other destinations are limited separately
+	require.NoError(t, d.wait(ctx, "b"))
+
+	d = NewDispatcher(time.Hour, 2*time.Hour)
+	require.NoError(t, d.wait(ctx, "a"))
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, d.wait(cancelled, "a"), context.Canceled)
+}
@@ -0,0 +1,160 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	KindSlack      = "slack"
+	KindGoogleChat = "googlechat"
+	KindWebhook    = "webhook"
+	KindEmail      = "email"
+)
+
+// SMTPConfig configures the mail server used for e-mail notifications.
+type SMTPConfig struct {
+	// Addr is the host:port of the mail server, e-mail notifications are disabled when empty.
+	Addr     string
+	From     string
+	Username string
+	Password string
+}
+
+// ParseTransport returns the transport for a destination spec, which is one of:
+//
+//	slack:<incoming webhook url>
+//	googlechat:<incoming webhook url>
+//	webhook:<url>
+//	mailto:<address>[,<address>...]
+//
+// A bare URL is a Slack or Google Chat webhook if it's hosted by them, and a generic webhook otherwise.
+func ParseTransport(spec string, smtpConfig SMTPConfig) (Transport, error) {
+	kind, target, _ := strings.Cut(spec, ":")
+	switch kind {
+	case KindSlack:
+		return &SlackTransport{URL: target}, nil
+	case KindGoogleChat:
+		return &GoogleChatTransport{URL: target}, nil
+	case KindWebhook:
+		return &WebhookTransport{URL: target}, nil
+	case "mailto":
+		return NewEmailTransport(smtpConfig, strings.Split(target, ",")), nil
+	case "http", "https":
+		return WebhookURL(spec), nil
+	}
+	return nil, fmt.Errorf("unknown notification destination %q", spec)
+}
+
+// WebhookURL returns the transport for a webhook URL, detecting Slack and Google Chat incoming webhooks by host.
+func WebhookURL(webhookURL string) Transport {
+	if u, err := url.Parse(webhookURL); err == nil {
+		switch u.Hostname() {
+		case "hooks.slack.com":
+			return &SlackTransport{URL: webhookURL}
+		case "chat.googleapis.com":
+			return &GoogleChatTransport{URL: webhookURL}
+		}
+	}
+	return &WebhookTransport{URL: webhookURL}
+}
+
+// SlackTransport posts the message text to a Slack incoming webhook.
+type SlackTransport struct {
+	URL string
+}
+
+func (t *SlackTransport) Kind() string { return KindSlack }
+func (t *SlackTransport) Key() string  { return t.URL }
+
+func (t *SlackTransport) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, t.URL, map[string]string{"text": msg.Text})
+}
+
+// GoogleChatTransport posts the message text to a Google Chat space's incoming webhook.
+type GoogleChatTransport struct {
+	URL string
+}
+
+func (t *GoogleChatTransport) Kind() string { return KindGoogleChat }
+func (t *GoogleChatTransport) Key() string  { return t.URL }
+
+func (t *GoogleChatTransport) Send(ctx context.Context, msg Message) error {
+	return postJSON(ctx, t.URL, map[string]string{"text": msg.Text})
+}
+
+// WebhookTransport posts the message's structured data to a URL as json, or just its text if it has no data.
+type WebhookTransport struct {
+	URL string
+}
+
+func (t *WebhookTransport) Kind() string { return KindWebhook }
+func (t *WebhookTransport) Key() string  { return t.URL }
+
+func (t *WebhookTransport) Send(ctx context.Context, msg Message) error {
+	if msg.Data == nil {
+		return postJSON(ctx, t.URL, map[string]string{"text": msg.Text})
+	}
+	return postJSON(ctx, t.URL, msg.Data)
+}
+
+// EmailTransport e-mails the message text, with the message subject, through the configured mail server.
+type EmailTransport struct {
+	SMTP SMTPConfig
+	To   []string
+}
+
+func NewEmailTransport(smtpConfig SMTPConfig, to []string) *EmailTransport {
+	return &EmailTransport{SMTP: smtpConfig, To: to}
+}
+
+func (t *EmailTransport) Kind() string { return KindEmail }
+func (t *EmailTransport) Key() string  { return "mailto:" + strings.Join(t.To, ",") }
+
+func (t *EmailTransport) Send(ctx context.Context, msg Message) error {
+	if t.SMTP.Addr == "" {
+		return fmt.Errorf("no smtp server configured")
+	}
+
+	var auth smtp.Auth
+	if t.SMTP.Username != "" {
+		host := strings.Split(t.SMTP.Addr, ":")[0]
+		auth = smtp.PlainAuth("", t.SMTP.Username, t.SMTP.Password, host)
+	}
+	body := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
+		t.SMTP.From, strings.Join(t.To, ", "), msg.Subject, strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	return smtp.SendMail(t.SMTP.Addr, auth, t.SMTP.From, t.To, []byte(body))
+}
+
+// postJSON sends the payload to url as json.
+func postJSON(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+// Package reportqueue hands long report generations from API frontends to report worker processes through a
+// job table, so slow reports don't compete with interactive requests on the same process.
+//
+// A frontend enqueues a request with Enqueue and waits for it with Wait. Workers claim pending jobs with
+// FOR UPDATE SKIP LOCKED, so any number of them can share the queue, replay the request against the handler
+// registered for its path, and store the response on the job for the frontend to return.
+package reportqueue
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+
+	// MaxAttempts is the number of times a job is claimed before it's failed, when its workers keep dying.
+	MaxAttempts = 3
+
+	// ReuseResultsFor is how long a finished job's result is returned for identical requests, so a client that
+	// gave up waiting can retry and get the result.
+	ReuseResultsFor = 5 * time.Minute
+)
+
+// ErrNotFound is returned when a job does not exist.
+var ErrNotFound = errors.New("report job not found")
+
+// Finished returns true if a job has succeeded or failed.
+func Finished(job *models.ReportJob) bool {
+	return job.Status == StatusSucceeded || job.Status == StatusFailed
+}
+
+// Enqueue returns the job for a request, reusing a pending or running job for the same request, or one that
+// finished within ReuseResultsFor, before creating a new one.
+func Enqueue(dbc *db.DB, kind, key string) (*models.ReportJob, error) {
+	job := &models.ReportJob{}
+	res := dbc.Primary().DB.
+		Where("kind = ? AND key = ?", kind, key).
+		Where("status IN ? OR (status = ? AND finished_at > ?)",
+			[]string{StatusPending, StatusRunning}, StatusSucceeded, time.Now().Add(-ReuseResultsFor)).
+		Order("id DESC").
+		First(job)
+	if res.Error == nil {
+		return job, nil
+	} else if !errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(res.Error, "error looking up report job")
+	}
+
+	job = &models.ReportJob{Kind: kind, Key: key, Status: StatusPending}
+	if err := dbc.DB.Create(job).Error; err != nil {
+		return nil, errors.Wrap(err, "error creating report job")
+	}
+	log.WithFields(log.Fields{"id": job.ID, "kind": kind, "key": key}).Info("enqueued report job")
+	return job, nil
+}
+
+// Get returns a job by its ID. Jobs are read from the primary, so they're up to date for frontends waiting on them.
+func Get(dbc *db.DB, id uint) (*models.ReportJob, error) {
+	job := &models.ReportJob{}
+	res := dbc.Primary().DB.First(job, id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return job, res.Error
+}
+
+// List returns the most recent jobs, newest first.
+func List(dbc *db.DB, limit int) ([]models.ReportJob, error) {
+	jobs := []models.ReportJob{}
+	res := dbc.DB.Order("id DESC").Limit(limit).Find(&jobs)
+	return jobs, res.Error
+}
+
+// Wait polls a job until it finishes, returning its last state and the context's error if it's done first.
+func Wait(ctx context.Context, dbc *db.DB, id uint, interval time.Duration) (*models.ReportJob, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		job, err := Get(dbc, id)
+		if err != nil || Finished(job) {
+			return job, err
+		}
+		select {
+		case <-ctx.Done():
+			return job, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim marks the oldest pending job as running on a worker, returning nil if there are none.
+func claim(dbc *db.DB, worker string) (*models.ReportJob, error) {
+	var job *models.ReportJob
+	err := dbc.DB.Transaction(func(tx *gorm.DB) error {
+		pending := &models.ReportJob{}
+		res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", StatusPending).
+			Order("id").
+			First(pending)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return nil
+		} else if res.Error != nil {
+			return res.Error
+		}
+		now := time.Now()
+		pending.Status = StatusRunning
+		pending.Worker = worker
+		pending.Attempts++
+		pending.StartedAt = &now
+		if err := tx.Save(pending).Error; err != nil {
+			return err
+		}
+		job = pending
+		return nil
+	})
+	return job, errors.Wrap(err, "error claiming report job")
+}
+
+// finish stores the response of a job. Responses other than 200 OK fail the job.
+func finish(dbc *db.DB, job *models.ReportJob, code int, body []byte) error {
+	now := time.Now()
+	job.Status = StatusSucceeded
+	if code != http.StatusOK {
+		job.Status = StatusFailed
+		job.Error = string(bytes.TrimSpace(body))
+	}
+	job.ResultCode = code
+	job.Result = body
+	job.FinishedAt = &now
+	return errors.Wrap(dbc.DB.Save(job).Error, "error saving report job")
+}
+
+// requeueStale returns jobs whose worker has been running them for longer than staleAfter, presumably because it
+// died, to the queue, or fails them once they've used up their attempts.
+func requeueStale(dbc *db.DB, staleAfter time.Duration) error {
+	cutoff := time.Now().Add(-staleAfter)
+	res := dbc.DB.Model(&models.ReportJob{}).
+		Where("status = ? AND started_at < ? AND attempts < ?", StatusRunning, cutoff, MaxAttempts).
+		Update("status", StatusPending)
+	if res.Error != nil {
+		return errors.Wrap(res.Error, "error requeueing stale report jobs")
+	}
+	if res.RowsAffected > 0 {
+		log.Warningf("requeued %d stale report jobs", res.RowsAffected)
+	}
+	res = dbc.DB.Model(&models.ReportJob{}).
+		Where("status = ? AND started_at < ? AND attempts >= ?", StatusRunning, cutoff, MaxAttempts).
+		Updates(map[string]interface{}{
+			"status":      StatusFailed,
+			"result_code": http.StatusInternalServerError,
+			"error":       "report worker did not finish the job",
+			"finished_at": time.Now(),
+		})
+	return errors.Wrap(res.Error, "error failing stale report jobs")
+}
+
+// prune deletes jobs that finished before a cutoff.
+func prune(dbc *db.DB, before time.Time) error {
+	res := dbc.DB.Unscoped().Where("finished_at < ?", before).Delete(&models.ReportJob{})
+	if res.Error != nil {
+		return errors.Wrap(res.Error, "error pruning report jobs")
+	}
+	if res.RowsAffected > 0 {
+		log.Infof("pruned %d finished report jobs", res.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package reportqueue
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+const (
+	pollInterval = 2 * time.Second
+	// staleAfter is how long a job can run before it's assumed its worker died. It's well above the time the
+	// largest component reports take to generate.
+	staleAfter = 30 * time.Minute
+	// retention is how long finished jobs are kept.
+	retention = 24 * time.Hour
+)
+
+// Worker generates queued reports by replaying their requests against the handler registered for their kind,
+// which is the path of the API endpoint.
+type Worker struct {
+	dbc      *db.DB
+	name     string
+	handlers map[string]http.HandlerFunc
+}
+
+// NewWorker returns a worker for the jobs of the given kinds, identified in the queue by its name.
+func NewWorker(dbc *db.DB, name string, handlers map[string]http.HandlerFunc) *Worker {
+	return &Worker{dbc: dbc, name: name, handlers: handlers}
+}
+
+// Run processes jobs one at a time until the context is cancelled. Run more workers to process jobs concurrently.
+func (w *Worker) Run(ctx context.Context) {
+	log.WithField("worker", w.name).Info("report worker started")
+	maintenance := time.NewTicker(time.Minute)
+	defer maintenance.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-maintenance.C:
+			if err := requeueStale(w.dbc, staleAfter); err != nil {
+				log.WithError(err).Warning("error requeueing stale report jobs")
+			}
+			if err := prune(w.dbc, time.Now().Add(-retention)); err != nil {
+				log.WithError(err).Warning("error pruning report jobs")
+			}
+		default:
+		}
+
+		job, err := claim(w.dbc, w.name)
+		if err != nil {
+			log.WithError(err).Warning("error claiming report job")
+		}
+		if job == nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollInterval):
+			}
+			continue
+		}
+
+		code, body := w.process(ctx, job)
+		if err := finish(w.dbc, job, code, body); err != nil {
+			log.WithError(err).Errorf("error finishing report job %d", job.ID)
+		}
+	}
+}
+
+func (w *Worker) process(ctx context.Context, job *models.ReportJob) (int, []byte) {
+	start := time.Now()
+	logger := log.WithFields(log.Fields{"id": job.ID, "kind": job.Kind, "key": job.Key, "attempt": job.Attempts})
+	logger.Info("generating queued report")
+
+	handler, ok := w.handlers[job.Kind]
+	if !ok {
+		logger.Error("no handler for report job")
+		return http.StatusInternalServerError, []byte(fmt.Sprintf(`{"code":500,"message":"no report worker handles %s"}`, job.Kind))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, job.Key, nil)
+	if err != nil {
+		logger.WithError(err).Error("invalid report job")
+		return http.StatusBadRequest, []byte(fmt.Sprintf(`{"code":400,"message":%q}`, err.Error()))
+	}
+
+	response := &bufferedResponse{header: http.Header{}, code: http.StatusOK}
+	handler(response, req)
+	logger.WithFields(log.Fields{
+		"elapsed": time.Since(start),
+		"code":    response.code,
+		"bytes":   response.body.Len(),
+	}).Info("generated queued report")
+	return response.code, response.body.Bytes()
+}
+
+// bufferedResponse captures a handler's response so it can be stored on its job.
+type bufferedResponse struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) Header() http.Header {
+	return r.header
+}
+
+func (r *bufferedResponse) Write(p []byte) (int, error) {
+	return r.body.Write(p)
+}
+
+func (r *bufferedResponse) WriteHeader(code int) {
+	r.code = code
+}
@@ -0,0 +1,41 @@
+package reportqueue
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestWorkerProcess(t *testing.T) {
+	w := NewWorker(nil, "test", map[string]http.HandlerFunc{
+		"/api/report": func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Query().Get("view") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":400}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"view":"` + req.URL.Query().Get("view") + `"}`))
+		},
+	})
+
+	code, body := w.process(context.Background(), &models.ReportJob{Kind: "/api/report", Key: "/api/report?view=main"})
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"view":"main"}`, string(body))
+
+	code, _ = w.process(context.Background(), &models.ReportJob{Kind: "/api/report", Key: "/api/report"})
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = w.process(context.Background(), &models.ReportJob{Kind: "/api/other", Key: "/api/other"})
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+func TestFinished(t *testing.T) {
+	assert.False(t, Finished(&models.ReportJob{Status: StatusPending}))
+	assert.False(t, Finished(&models.ReportJob{Status: StatusRunning}))
+	assert.True(t, Finished(&models.ReportJob{Status: StatusSucceeded}))
+	assert.True(t, Finished(&models.ReportJob{Status: StatusFailed}))
+}
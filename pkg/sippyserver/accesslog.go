@@ -0,0 +1,255 @@
+package sippyserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+const (
+	accessLogBufferSize    = 1000
+	accessLogBatchSize     = 100
+	accessLogFlushInterval = 10 * time.Second
+	accessLogPruneInterval = time.Hour
+
+	// redactedParam replaces the value of parameters not in accessLogParams.
+	redactedParam = "*"
+)
+
+// accessLogParams are query parameters whose values are recorded in access logs. They take one of a small set of
+// values that don't identify a user or what they were looking at, unlike free-form parameters such as test names
+// and filters, of which only the presence is recorded.
+var accessLogParams = map[string]bool{
+	"release":     true,
+	"period":      true,
+	"granularity": true,
+	"group_by":    true,
+	"groupBy":     true,
+	"kind":        true,
+	"status":      true,
+	"view":        true,
+	"pass_rate":   true,
+	"sortField":   true,
+	"sort":        true,
+	"limit":       true,
+	"perPage":     true,
+	"page":        true,
+	"format":      true,
+}
+
+// variantParams are query parameters whose values are variants in the form Name:value, and variantNameParams those
+// whose values are comma separated variant names to group or compare by.
+var (
+	variantParams     = []string{"variant", "includeVariant", "compareVariant"}
+	variantNameParams = []string{"columnGroupBy", "dbGroupBy", "variantCrossCompare"}
+)
+
+var accessLogDroppedMetric = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "sippy_access_log_dropped_total",
+	Help: "Number of access log entries dropped because the database could not keep up",
+})
+
+// NormalizeParams returns the query string sorted by parameter name, with the values of parameters that could
+// identify a user or what they were looking at replaced by *, so that requests can be grouped by how the API was
+// called.
+func NormalizeParams(values url.Values) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]string, 0, len(names))
+	for _, name := range names {
+		if !accessLogParams[name] {
+			params = append(params, url.QueryEscape(name)+"="+redactedParam)
+			continue
+		}
+		vals := append([]string{}, values[name]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			params = append(params, url.QueryEscape(name)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// RequestVariants returns the variants a request filtered on in the form Name:value, from variant parameters and
+// variants filter items, and the names of the variants it grouped or compared by, sorted. Variant values are
+// recorded, unlike other parameter values, as they are from a small set that doesn't identify what a user was looking
+// at.
+func RequestVariants(values url.Values) []string {
+	variants := map[string]bool{}
+	for _, param := range variantParams {
+		for _, v := range values[param] {
+			if strings.Contains(v, ":") {
+				variants[v] = true
+			}
+		}
+	}
+	for _, param := range variantNameParams {
+		for _, v := range values[param] {
+			for _, name := range strings.Split(v, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					variants[name] = true
+				}
+			}
+		}
+	}
+	if f := values.Get("filter"); f != "" {
+		items := filter.Filter{}
+		if err := json.Unmarshal([]byte(f), &items); err == nil {
+			for _, item := range items.Items {
+				if item.Field == "variants" && strings.Contains(item.Value, ":") {
+					variants[item.Value] = true
+				}
+			}
+		}
+	}
+
+	result := make([]string, 0, len(variants))
+	for v := range variants {
+		result = append(result, v)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// AccessLogStore records API requests in the database in the background, so requests are not slowed down by it.
+// Entries are dropped if the database can't keep up, and deleted once older than the retention.
+type AccessLogStore struct {
+	dbc       *db.DB
+	retention time.Duration
+	entries   chan models.AccessLog
+}
+
+func NewAccessLogStore(dbc *db.DB, retention time.Duration) *AccessLogStore {
+	return &AccessLogStore{
+		dbc:       dbc,
+		retention: retention,
+		entries:   make(chan models.AccessLog, accessLogBufferSize),
+	}
+}
+
+// Record queues an entry to be written, dropping it if the queue is full.
+func (a *AccessLogStore) Record(entry models.AccessLog) {
+	select {
+	case a.entries <- entry:
+	default:
+		accessLogDroppedMetric.Inc()
+	}
+}
+
+// Run writes queued entries in batches and prunes expired ones until the context is cancelled.
+func (a *AccessLogStore) Run(ctx context.Context) {
+	flush := time.NewTicker(accessLogFlushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(accessLogPruneInterval)
+	defer prune.Stop()
+
+	batch := make([]models.AccessLog, 0, accessLogBatchSize)
+	write := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := a.dbc.DB.CreateInBatches(batch, accessLogBatchSize).Error; err != nil {
+			logger.WithError(err).Warningf("error writing %d access log entries", len(batch))
+			accessLogDroppedMetric.Add(float64(len(batch)))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			write()
+			return
+		case entry := <-a.entries:
+			batch = append(batch, entry)
+			if len(batch) >= accessLogBatchSize {
+				write()
+			}
+		case <-flush.C:
+			write()
+		case <-prune.C:
+			res := a.dbc.DB.Where("timestamp < ?", time.Now().Add(-a.retention)).Delete(&models.AccessLog{})
+			if res.Error != nil {
+				logger.WithError(res.Error).Warning("error pruning access logs")
+			} else {
+				logger.Infof("pruned %d expired access log entries", res.RowsAffected)
+			}
+		}
+	}
+}
+
+// SetAccessLogStore configures where API requests are recorded. Requests are only logged when not set.
+func (s *Server) SetAccessLogStore(store *AccessLogStore) {
+	s.accessLogStore = store
+}
+
+// statusRecorder captures the status code a handler responds with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// logRequestHandler logs every request with its normalized parameters, and records requests to API endpoints in
+// the access log store when one is configured. endpoints maps each API endpoint to whether its responses are cached.
+func (s *Server) logRequestHandler(h http.Handler, endpoints map[string]bool) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(recorder, r)
+		elapsed := time.Since(start)
+
+		params := NormalizeParams(r.URL.Query())
+		cached, isEndpoint := endpoints[r.URL.Path]
+		cacheStatus := models.CacheStatusNone
+		if cached && s.cache != nil {
+			cacheStatus = models.CacheStatusMiss
+			if w.Header().Get("X-Sippy-Cached") == "true" {
+				cacheStatus = models.CacheStatusHit
+			}
+		}
+
+		logger.WithFields(log.Fields{
+			"path":    r.URL.Path,
+			"params":  params,
+			"method":  r.Method,
+			"status":  recorder.status,
+			"cache":   cacheStatus,
+			"elapsed": elapsed,
+		}).Info("responded to request")
+
+		if isEndpoint && s.accessLogStore != nil {
+			s.accessLogStore.Record(models.AccessLog{
+				Timestamp:     start,
+				Endpoint:      r.URL.Path,
+				Method:        r.Method,
+				Params:        params,
+				Status:        recorder.status,
+				LatencyMillis: float64(elapsed.Microseconds()) / 1000,
+				CacheStatus:   cacheStatus,
+				Variants:      RequestVariants(r.URL.Query()),
+			})
+		}
+	}
+	return http.HandlerFunc(fn)
+}
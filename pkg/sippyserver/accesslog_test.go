@@ -0,0 +1,90 @@
+package sippyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+type mapCache map[string][]byte
+
+func (c mapCache) Get(key string) ([]byte, error) {
+	return c[key], nil
+}
+
+func (c mapCache) Set(key string, content []byte, _ time.Duration) error {
+	c[key] = content
+	return nil
+}
+
+func TestNormalizeParams(t *testing.T) {
+	values, err := url.ParseQuery("test=[sig-network] pods&release=4.16&filter={}&sort=asc&release=4.15")
+	require.NoError(t, err)
+	assert.Equal(t, "filter=*&release=4.15&release=4.16&sort=asc&test=*", NormalizeParams(values))
+	assert.Empty(t, NormalizeParams(url.Values{}))
+}
+
+func TestRequestVariants(t *testing.T) {
+	values := url.Values{
+		"variant":        {"Platform:aws", "aws"},
+		"includeVariant": {"Network:ovn", "Platform:aws"},
+		"dbGroupBy":      {"Platform, Architecture,Network"},
+		"columnGroupBy":  {"Platform"},
+		"filter": {`{"items":[{"columnField":"variants","operatorValue":"contains","value":"CloudRegion:us-east-1"},` +
+			`{"columnField":"name","operatorValue":"contains","value":"Platform:gcp"}]}`},
+	}
+	assert.Equal(t, []string{
+		"Architecture", "CloudRegion:us-east-1", "Network", "Network:ovn", "Platform", "Platform:aws",
+	}, RequestVariants(values), "bare values and other filter fields are left out, duplicates are recorded once")
+
+	values.Set("filter", "not json")
+	assert.NotContains(t, RequestVariants(values), "CloudRegion:us-east-1")
+	assert.Empty(t, RequestVariants(url.Values{"release": {"4.16"}}))
+}
+
+func TestLogRequestHandlerRecordsAccessLogs(t *testing.T) {
+	s := &Server{cache: mapCache{}}
+	s.SetAccessLogStore(NewAccessLogStore(nil, time.Hour))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/tests", s.cached(time.Hour, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	mux.HandleFunc("/api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	handler := s.logRequestHandler(mux, map[string]bool{"/api/tests": true, "/api/jobs": false})
+
+	targets := []string{
+		"/api/tests?release=4.16&test=foo&variant=Platform:aws",
+		"/api/tests?release=4.16&test=foo&variant=Platform:aws",
+		"/api/jobs",
+		"/static/app.js",
+	}
+	for _, target := range targets {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	// Requests outside of the API endpoints are not recorded
+	require.Len(t, s.accessLogStore.entries, 3)
+	miss := <-s.accessLogStore.entries
+	assert.Equal(t, "/api/tests", miss.Endpoint)
+	assert.Equal(t, "release=4.16&test=*&variant=*", miss.Params)
+	assert.Equal(t, pq.StringArray{"Platform:aws"}, miss.Variants)
+	assert.Equal(t, http.StatusOK, miss.Status)
+	assert.Equal(t, models.CacheStatusMiss, miss.CacheStatus)
+	hit := <-s.accessLogStore.entries
+	assert.Equal(t, models.CacheStatusHit, hit.CacheStatus)
+	uncached := <-s.accessLogStore.entries
+	assert.Equal(t, "/api/jobs", uncached.Endpoint)
+	assert.Equal(t, http.StatusBadRequest, uncached.Status)
+	assert.Equal(t, models.CacheStatusNone, uncached.CacheStatus)
+}
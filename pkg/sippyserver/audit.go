@@ -0,0 +1,69 @@
+package sippyserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/auditlog"
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+// maxAuditedBodyBytes limits the size of requests to audited endpoints, as their bodies are recorded.
+const maxAuditedBodyBytes = 1 << 20
+
+// audited records the successful changes made through an endpoint in the audit log, under a category. Requests that
+// don't change anything, i.e. GETs, are not recorded.
+func (s *Server) audited(category string, handler func(w http.ResponseWriter, r *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if s.db == nil || req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			handler(w, req)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxAuditedBodyBytes+1))
+		if err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error reading request: %v", err),
+			})
+			return
+		}
+		if len(body) > maxAuditedBodyBytes {
+			api.RespondWithJSON(http.StatusRequestEntityTooLarge, w, map[string]interface{}{
+				"code":    http.StatusRequestEntityTooLarge,
+				"message": fmt.Sprintf("request body exceeds %d bytes", maxAuditedBodyBytes),
+			})
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler(recorder, req)
+		if recorder.status < 200 || recorder.status >= 300 {
+			return
+		}
+
+		event := &models.AuditEvent{
+			Actor:    getRequestUser(req),
+			Category: category,
+			Method:   req.Method,
+			Endpoint: req.URL.Path,
+			Params:   req.URL.RawQuery,
+			Body:     string(body),
+			Status:   recorder.status,
+		}
+		if err := auditlog.Append(s.db, event); err != nil {
+			logger.WithError(err).WithFields(log.Fields{
+				"actor":    event.Actor,
+				"category": category,
+				"method":   req.Method,
+				"endpoint": req.URL.Path,
+			}).Error("error recording audit event, the change was made but is missing from the audit log")
+		}
+	}
+}
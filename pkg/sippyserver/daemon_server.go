@@ -7,8 +7,6 @@ import (
 	"sync"
 	"syscall"
 	"time"
-
-	log "github.com/sirupsen/logrus"
 )
 
 type DaemonProcess interface {
@@ -40,11 +38,11 @@ func (da *DaemonServer) addProcess(process DaemonProcess) {
 func (da *DaemonServer) Serve() {
 
 	if len(da.processes) < 1 {
-		log.Error("Empty process list, exiting")
+		logger.Error("Empty process list, exiting")
 		return
 	}
 
-	log.Info("Started serving")
+	logger.Info("Started serving")
 
 	pendingContexts := make([]context.CancelFunc, 0)
 	wg := sync.WaitGroup{}
@@ -66,10 +64,10 @@ func (da *DaemonServer) Serve() {
 	signal.Notify(sigChannel, syscall.SIGINT, syscall.SIGTERM)
 	s := <-sigChannel
 
-	log.Infof("Received shutdown signal: %v", s)
+	logger.Infof("Received shutdown signal: %v", s)
 
 	for _, cancel := range pendingContexts {
-		log.Info("Canceling context")
+		logger.Info("Canceling context")
 		cancel()
 	}
 
@@ -83,10 +81,10 @@ func (da *DaemonServer) Serve() {
 	select {
 
 	case <-wchan:
-		log.Info("Wait group completed")
+		logger.Info("Wait group completed")
 	case <-time.After(10 * time.Second):
-		log.Info("Timed out on wait group")
+		logger.Info("Timed out on wait group")
 	}
 
-	log.Info("Ended serving ")
+	logger.Info("Ended serving ")
 }
@@ -0,0 +1,162 @@
+package sippyserver
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/openshift/sippy/pkg/api"
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	grpcv1 "github.com/openshift/sippy/pkg/apis/grpc/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/filter"
+)
+
+// ServeGRPC serves the Sippy gRPC service on the given address, alongside the HTTP API. It requires a local
+// database, and blocks until the listener fails.
+func (s *Server) ServeGRPC(addr string) error {
+	if s.db == nil {
+		return errors.New("the gRPC service requires a database")
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	grpcServer := grpc.NewServer()
+	grpcv1.RegisterSippyServer(grpcServer, &sippyGRPCServer{dbc: s.db})
+
+	logger.Infof("Serving gRPC on %s", addr)
+	return grpcServer.Serve(lis)
+}
+
+// sippyGRPCServer implements the Sippy gRPC service using the same queries as the HTTP API.
+type sippyGRPCServer struct {
+	grpcv1.UnimplementedSippyServer
+	dbc *db.DB
+}
+
+func (gs *sippyGRPCServer) GetJobVariants(ctx context.Context, req *grpcv1.GetJobVariantsRequest) (*grpcv1.GetJobVariantsResponse, error) {
+	if len(req.GetJobNames()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "job_names is required")
+	}
+
+	var jobs []models.ProwJob
+	res := gs.dbc.DB.WithContext(ctx).Select("name", "release", "variants").Where("name IN ?", req.GetJobNames()).Find(&jobs)
+	if res.Error != nil {
+		logger.WithError(res.Error).Error("error querying job variants")
+		return nil, status.Error(codes.Internal, "error querying job variants")
+	}
+
+	resp := &grpcv1.GetJobVariantsResponse{}
+	for _, job := range jobs {
+		resp.Jobs = append(resp.Jobs, &grpcv1.JobVariants{
+			JobName:  job.Name,
+			Release:  job.Release,
+			Variants: api.TypedVariants(job.Variants),
+		})
+	}
+	return resp, nil
+}
+
+func (gs *sippyGRPCServer) GetTestPassRates(ctx context.Context, req *grpcv1.GetTestPassRatesRequest) (*grpcv1.GetTestPassRatesResponse, error) {
+	if req.GetRelease() == "" || len(req.GetTestNames()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "release and test_names are required")
+	}
+
+	mode := apitype.PassRateStrict
+	if req.GetPassRateMode() == grpcv1.PassRateMode_PASS_RATE_MODE_LENIENT {
+		mode = apitype.PassRateLenient
+	}
+
+	fil := &filter.Filter{LinkOperator: filter.LinkOperatorOr}
+	for _, name := range req.GetTestNames() {
+		fil.Items = append(fil.Items, filter.FilterItem{
+			Field:    "name",
+			Operator: filter.OperatorEquals,
+			Value:    name,
+		})
+	}
+
+	tests, _, err := api.BuildTestsResults(gs.dbc, req.GetRelease(), "default", true, false, mode, &filter.FilterOptions{Filter: fil})
+	if err != nil {
+		logger.WithError(err).Error("error querying test pass rates")
+		return nil, status.Error(codes.Internal, "error querying test pass rates")
+	}
+
+	resp := &grpcv1.GetTestPassRatesResponse{}
+	for _, test := range tests {
+		resp.Tests = append(resp.Tests, testPassRateToProto(test))
+	}
+	return resp, nil
+}
+
+func (gs *sippyGRPCServer) AnalyzeJobRunRisk(ctx context.Context, req *grpcv1.AnalyzeJobRunRiskRequest) (*grpcv1.AnalyzeJobRunRiskResponse, error) {
+	logger := logger.WithFields(log.Fields{"func": "AnalyzeJobRunRisk", "jobRunID": req.GetProwJobRunId()})
+
+	jobRun, jobRunTestCount, err := api.FetchJobRun(gs.dbc, req.GetProwJobRunId(), logger)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "job run %d not found", req.GetProwJobRunId())
+	} else if err != nil {
+		logger.WithError(err).Error("error fetching job run")
+		return nil, status.Error(codes.Internal, "error fetching job run")
+	}
+
+	result, err := api.JobRunRiskAnalysis(gs.dbc, jobRun, jobRunTestCount, logger)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return riskAnalysisToProto(result), nil
+}
+
+func testPassRateToProto(test apitype.Test) *grpcv1.TestPassRate {
+	return &grpcv1.TestPassRate{
+		TestName:               test.Name,
+		CurrentRuns:            int32(test.CurrentRuns),
+		CurrentSuccesses:       int32(test.CurrentSuccesses),
+		CurrentFailures:        int32(test.CurrentFailures),
+		CurrentFlakes:          int32(test.CurrentFlakes),
+		CurrentPassPercentage:  test.CurrentPassPercentage,
+		PreviousRuns:           int32(test.PreviousRuns),
+		PreviousSuccesses:      int32(test.PreviousSuccesses),
+		PreviousFailures:       int32(test.PreviousFailures),
+		PreviousFlakes:         int32(test.PreviousFlakes),
+		PreviousPassPercentage: test.PreviousPassPercentage,
+	}
+}
+
+func riskLevelToProto(level apitype.RiskLevel) *grpcv1.RiskLevel {
+	return &grpcv1.RiskLevel{
+		Name:  level.Name,
+		Level: int32(level.Level),
+	}
+}
+
+func riskAnalysisToProto(result apitype.ProwJobRunRiskAnalysis) *grpcv1.AnalyzeJobRunRiskResponse {
+	resp := &grpcv1.AnalyzeJobRunRiskResponse{
+		ProwJobName:  result.ProwJobName,
+		ProwJobRunId: uint64(result.ProwJobRunID),
+		Release:      result.Release,
+		OverallRisk:  riskLevelToProto(result.OverallRisk.Level),
+		Reasons:      result.OverallRisk.Reasons,
+	}
+	for _, test := range result.Tests {
+		resp.Tests = append(resp.Tests, &grpcv1.TestRisk{
+			TestName:              test.Name,
+			Risk:                  riskLevelToProto(test.Risk.Level),
+			Reasons:               test.Risk.Reasons,
+			CurrentRuns:           int32(test.Risk.CurrentRuns),
+			CurrentPasses:         int32(test.Risk.CurrentPasses),
+			CurrentPassPercentage: test.Risk.CurrentPassPercentage,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,69 @@
+package sippyserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	apitype "github.com/openshift/sippy/pkg/apis/api"
+	grpcv1 "github.com/openshift/sippy/pkg/apis/grpc/v1"
+)
+
+func TestGRPCRequestValidation(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	grpcv1.RegisterSippyServer(srv, &sippyGRPCServer{})
+	go srv.Serve(lis) //nolint:errcheck
+	defer srv.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := grpcv1.NewSippyClient(conn)
+
+	_, err = client.GetJobVariants(context.Background(), &grpcv1.GetJobVariantsRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetTestPassRates(context.Background(), &grpcv1.GetTestPassRatesRequest{TestNames: []string{"a test"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestRiskAnalysisToProto(t *testing.T) {
+	resp := riskAnalysisToProto(apitype.ProwJobRunRiskAnalysis{
+		ProwJobName:  "periodic-ci-openshift-release-master-ci-4.16-e2e-aws",
+		ProwJobRunID: 42,
+		Release:      "4.16",
+		OverallRisk: apitype.JobFailureRisk{
+			Level:   apitype.FailureRiskLevelHigh,
+			Reasons: []string{"test failed which passed 100% of the time"},
+		},
+		Tests: []apitype.ProwJobRunTestRiskAnalysis{
+			{
+				Name: "[sig-network] a test",
+				Risk: apitype.TestFailureRisk{
+					Level:                 apitype.FailureRiskLevelHigh,
+					CurrentRuns:           100,
+					CurrentPasses:         100,
+					CurrentPassPercentage: 100,
+				},
+			},
+		},
+	})
+
+	assert.Equal(t, uint64(42), resp.GetProwJobRunId())
+	assert.Equal(t, "High", resp.GetOverallRisk().GetName())
+	assert.Equal(t, int32(100), resp.GetOverallRisk().GetLevel())
+	assert.Equal(t, []string{"test failed which passed 100% of the time"}, resp.GetReasons())
+	if assert.Len(t, resp.GetTests(), 1) {
+		assert.Equal(t, "[sig-network] a test", resp.GetTests()[0].GetTestName())
+		assert.Equal(t, int32(100), resp.GetTests()[0].GetCurrentRuns())
+	}
+}
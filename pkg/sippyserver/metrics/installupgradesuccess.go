@@ -5,7 +5,6 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift/sippy/pkg/api"
 	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
@@ -61,7 +60,7 @@ func refreshTestSuccessMetrics(dbc *db.DB, testName string, successMetric, succe
 			// Just use the one install test we're interested in:
 			testVariants, ok := testToVariantToResults[testName]
 			if !ok {
-				log.WithField("release", release).Warnf("upgrade report for release did not include test: %s",
+				logger.WithField("release", release).Warnf("upgrade report for release did not include test: %s",
 					testidentification.UpgradeTestName)
 				return nil
 			}
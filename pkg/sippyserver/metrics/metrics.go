@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,15 +12,16 @@ import (
 	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
 	"github.com/openshift/sippy/pkg/apis/cache"
 	bqclient "github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/componentreadiness/notifier"
 	"github.com/openshift/sippy/pkg/componentreadiness/tracker"
 	"github.com/openshift/sippy/pkg/filter"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/testidentification"
 	"github.com/openshift/sippy/pkg/util"
 	"github.com/openshift/sippy/pkg/util/sets"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/openshift/sippy/pkg/api"
 	apitype "github.com/openshift/sippy/pkg/apis/api"
@@ -28,6 +30,8 @@ import (
 	"github.com/openshift/sippy/pkg/db/query"
 )
 
+var logger = logging.ForSubsystem("metrics")
+
 const (
 	blockerScoreToAlertOn = 70
 )
@@ -115,6 +119,10 @@ var (
 		Name: "sippy_disruption_vs_two_weeks_ago",
 		Help: "Delta of percentiles now vs two weeks ago for a given release",
 	}, []string{"delta", "release", "platform", "backend", "upgrade_type", "master_nodes_updated", "network", "topology", "architecture", "releaseStatus"})
+	variantKeyChangesMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "sippy_variant_key_changes",
+		Help: "Number of times a variant key changed value for a release's jobs in the last week",
+	}, []string{"release", "key"})
 	disruptionVsTwoWeeksAgoRelevanceMetric = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "sippy_disruption_vs_two_weeks_ago_relevance",
 		Help: "Rating of how relevant we feel our data is for regression detection.",
@@ -136,9 +144,9 @@ func getReleaseStatus(releases []query.Release, release string) string {
 // pinning the time just to be consistent
 func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, prowURL, gcsBucket string,
 	variantManager testidentification.VariantManager, reportEnd time.Time,
-	cacheOptions cache.RequestOptions, views []crtype.View, maintainRegressionTables bool) error {
+	cacheOptions cache.RequestOptions, views []crtype.View, maintainRegressionTables bool, viewNotifier *notifier.Notifier) error {
 	start := time.Now()
-	log.Info("beginning refresh metrics")
+	logger.Info("beginning refresh metrics")
 	releases, err := api.GetReleases(dbc, bqc)
 	if err != nil {
 		return err
@@ -189,54 +197,59 @@ func RefreshMetricsDB(dbc *db.DB, bqc *bqclient.Client, prowURL, gcsBucket strin
 		}
 
 		if err := refreshBuildClusterMetrics(dbc, reportEnd); err != nil {
-			log.WithError(err).Error("error refreshing build cluster metrics")
+			logger.WithError(err).Error("error refreshing build cluster metrics")
 		}
 
 		refreshPayloadMetrics(dbc, reportEnd, releases)
 
 		if err := refreshInstallSuccessMetrics(dbc, releases); err != nil {
-			log.WithError(err).Error("error refreshing install success metrics")
+			logger.WithError(err).Error("error refreshing install success metrics")
 		}
 		if err := refreshUpgradeSuccessMetrics(dbc, releases); err != nil {
-			log.WithError(err).Error("error refreshing upgrade success metrics")
+			logger.WithError(err).Error("error refreshing upgrade success metrics")
 		}
 		if err := refreshInfraMetrics(dbc, variantManager); err != nil {
-			log.WithError(err).Error("error refreshing infrastructure success metrics")
+			logger.WithError(err).Error("error refreshing infrastructure success metrics")
+		}
+		if err := refreshVariantChurnMetrics(dbc, releases); err != nil {
+			logger.WithError(err).Error("error refreshing variant churn metrics")
 		}
 	}
 
 	// BigQuery metrics
 	if bqc != nil {
-		refreshComponentReadinessMetrics(bqc, prowURL, gcsBucket, cacheOptions, views, releases, maintainRegressionTables)
+		refreshComponentReadinessMetrics(dbc, bqc, prowURL, gcsBucket, cacheOptions, views, releases, maintainRegressionTables, viewNotifier)
 
 		if err := refreshDisruptionMetrics(bqc, releases); err != nil {
-			log.WithError(err).Error("error refreshing disruption metrics")
+			logger.WithError(err).Error("error refreshing disruption metrics")
 		}
 	}
 
-	log.Infof("refresh metrics completed in %s", time.Since(start))
+	logger.Infof("refresh metrics completed in %s", time.Since(start))
 
 	return nil
 }
 
-func refreshComponentReadinessMetrics(client *bqclient.Client, prowURL, gcsBucket string,
-	cacheOptions cache.RequestOptions, views []crtype.View, releases []query.Release, maintainRegressionTables bool) {
+func refreshComponentReadinessMetrics(dbc *db.DB, client *bqclient.Client, prowURL, gcsBucket string,
+	cacheOptions cache.RequestOptions, views []crtype.View, releases []query.Release, maintainRegressionTables bool,
+	viewNotifier *notifier.Notifier) {
 	if client == nil || client.BQ == nil {
-		log.Warningf("not generating component readiness metrics as we don't have a bigquery client")
+		logger.Warningf("not generating component readiness metrics as we don't have a bigquery client")
 		return
 	}
 
 	if client.Cache == nil {
-		log.Warningf("not generating component readiness metrics as we don't have a cache configured")
+		logger.Warningf("not generating component readiness metrics as we don't have a cache configured")
 		return
 	}
 
 	for _, view := range views {
-		if view.Metrics.Enabled || view.RegressionTracking.Enabled {
-			err := updateComponentReadinessTrackingForView(client, prowURL, gcsBucket, cacheOptions, view, releases, maintainRegressionTables)
-			log.WithError(err).Error("error")
+		if view.Metrics.Enabled || view.RegressionTracking.Enabled || view.Notifications.Enabled() ||
+			len(view.AdvancedOptions.ShadowAlgorithms) > 0 {
+			err := updateComponentReadinessTrackingForView(dbc, client, prowURL, gcsBucket, cacheOptions, view, releases, maintainRegressionTables, viewNotifier)
+			logger.WithError(err).Error("error")
 			if err != nil {
-				log.WithError(err).WithField("view", view.Name).Error("error refreshing metrics/regressions for view")
+				logger.WithError(err).WithField("view", view.Name).Error("error refreshing metrics/regressions for view")
 				// continue to next view
 			}
 		}
@@ -244,11 +257,12 @@ func refreshComponentReadinessMetrics(client *bqclient.Client, prowURL, gcsBucke
 }
 
 // updateCompnentReadinessTrackingForView queries the report for the given view, and then updates metrics,
-// regression tracking, or both, depending on view configuration.
-func updateComponentReadinessTrackingForView(client *bqclient.Client, prowURL, gcsBucket string,
-	cacheOptions cache.RequestOptions, view crtype.View, releases []query.Release, maintainRegressionTables bool) error {
+// regression tracking, notifications and shadow algorithm evaluations, depending on view configuration.
+func updateComponentReadinessTrackingForView(dbc *db.DB, client *bqclient.Client, prowURL, gcsBucket string,
+	cacheOptions cache.RequestOptions, view crtype.View, releases []query.Release, maintainRegressionTables bool,
+	viewNotifier *notifier.Notifier) error {
 
-	logger := log.WithField("view", view.Name)
+	logger := logger.WithField("view", view.Name)
 	logger.Info("generating report for view")
 
 	baseRelease, err := componentreadiness.GetViewReleaseOptions("basis", view.BaseRelease, cacheOptions.CRTimeRoundingFactor)
@@ -274,6 +288,11 @@ func updateComponentReadinessTrackingForView(client *bqclient.Client, prowURL, g
 		CacheOption:    cacheOptions,
 	}
 
+	reportOpts.BasisPins, err = api.ComponentReportBasisPins(dbc, view.SampleRelease.Release)
+	if err != nil {
+		return errors.Wrap(err, "error fetching basis pins")
+	}
+
 	report, errs := componentreadiness.GetComponentReportFromBigQuery(client, prowURL, gcsBucket, reportOpts)
 	if len(errs) > 0 {
 		var strErrors []string
@@ -328,6 +347,28 @@ func updateComponentReadinessTrackingForView(client *bqclient.Client, prowURL, g
 		if err != nil {
 			return errors.Wrap(err, "regression tracker reported an error")
 		}
+
+		// Also maintain regressions in the local db, where their lifecycle is reported on:
+		if dbc != nil {
+			dbTracker := tracker.NewRegressionTracker(tracker.NewPostgresRegressionStore(dbc), view, !maintainRegressionTables)
+			if err := dbTracker.SyncComponentReport(&report); err != nil {
+				return errors.Wrap(err, "db regression tracker reported an error")
+			}
+		}
+	}
+
+	if len(report.ShadowEvaluations) > 0 && dbc != nil {
+		logger.Info("recording shadow algorithm evaluations for view")
+		if err := api.SaveShadowEvaluations(dbc, view, &report); err != nil {
+			return errors.Wrap(err, "error recording shadow algorithm evaluations")
+		}
+	}
+
+	if view.Notifications.Enabled() && viewNotifier != nil {
+		logger.Info("sending notifications for view")
+		if err := viewNotifier.Notify(context.Background(), client.Cache, view, &report); err != nil {
+			return errors.Wrap(err, "view notifier reported an error")
+		}
 	}
 
 	return nil
@@ -348,6 +389,23 @@ func refreshInfraMetrics(dbc *db.DB, variantManager testidentification.VariantMa
 	return nil
 }
 
+func refreshVariantChurnMetrics(dbc *db.DB, releases []query.Release) error {
+	since := time.Now().Add(-7 * 24 * time.Hour)
+	// keys that stopped changing are dropped, rather than left at their last count
+	variantKeyChangesMetric.Reset()
+	for _, r := range releases {
+		keys, err := query.VariantKeyChurn(dbc, r.Release, since)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			variantKeyChangesMetric.WithLabelValues(r.Release, k.Key).Set(float64(k.Changes))
+		}
+	}
+
+	return nil
+}
+
 func refreshBuildClusterMetrics(dbc *db.DB, reportEnd time.Time) error {
 	for _, period := range []string{"current", "twoDay"} {
 		start, boundary, end := util.PeriodToDates(period, reportEnd)
@@ -368,7 +426,7 @@ func refreshPayloadMetrics(dbc *db.DB, reportEnd time.Time, releases []query.Rel
 	for _, r := range releases {
 		results, err := api.ReleaseHealthReports(dbc, r.Release, reportEnd)
 		if err != nil {
-			log.WithError(err).Error("error calling ReleaseHealthReports")
+			logger.WithError(err).Error("error calling ReleaseHealthReports")
 			return
 		}
 
@@ -384,7 +442,7 @@ func refreshPayloadMetrics(dbc *db.DB, reportEnd time.Time, releases []query.Rel
 				possibleTestBlockers, err := api.GetPayloadStreamTestFailures(dbc, r.Release, rhr.Stream,
 					rhr.Architecture, &filter.FilterOptions{Filter: &filter.Filter{}}, reportEnd)
 				if err != nil {
-					log.WithError(err).Error("error getting payload stream test failures")
+					logger.WithError(err).Error("error getting payload stream test failures")
 					return
 				}
 				blockersFound := 0
@@ -401,7 +459,7 @@ func refreshPayloadMetrics(dbc *db.DB, reportEnd time.Time, releases []query.Rel
 
 		lastAcceptedReleaseTags, err := query.GetLastAcceptedByArchitectureAndStream(dbc.DB, r.Release, reportEnd)
 		if err != nil {
-			log.WithError(err).Error("error querying last accepted payloads")
+			logger.WithError(err).Error("error querying last accepted payloads")
 			return
 		}
 
@@ -413,7 +471,7 @@ func refreshPayloadMetrics(dbc *db.DB, reportEnd time.Time, releases []query.Rel
 
 		lastOSUpgradeTags, err := query.GetLastOSUpgradeByArchitectureAndStream(dbc.DB, r.Release)
 		if err != nil {
-			log.WithError(err).Error("error querying last os upgrades")
+			logger.WithError(err).Error("error querying last os upgrades")
 			return
 		}
 		for _, archStream := range lastOSUpgradeTags {
@@ -429,12 +487,12 @@ func refreshPayloadMetrics(dbc *db.DB, reportEnd time.Time, releases []query.Rel
 // The previous GA view should have its release and GA date updated on each release GA.
 func refreshDisruptionMetrics(client *bqclient.Client, releases []query.Release) error {
 	if client == nil || client.BQ == nil {
-		log.Warningf("not generating disruption metrics as we don't have a bigquery client")
+		logger.Warningf("not generating disruption metrics as we don't have a bigquery client")
 		return nil
 	}
 
 	if client.Cache == nil {
-		log.Warningf("not generating disruption metrics as we don't have a cache configured")
+		logger.Warningf("not generating disruption metrics as we don't have a cache configured")
 		return nil
 	}
 
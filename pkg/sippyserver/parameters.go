@@ -5,12 +5,9 @@ import (
 	"strconv"
 	"time"
 
-	log "github.com/sirupsen/logrus"
-
+	"github.com/openshift/sippy/pkg/api"
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/filter"
-
-	"github.com/openshift/sippy/pkg/util"
 )
 
 const (
@@ -32,33 +29,48 @@ func getISO8601Date(paramName string, req *http.Request) (*time.Time, error) {
 	return &date, nil
 }
 
-func getPeriodDates(defaultPeriod string, req *http.Request, reportEnd time.Time) (start, boundary, end time.Time) {
-	period := getPeriod(req, defaultPeriod)
-
-	// If start, boundary, and end params are all specified, use those
-	startp := getDateParam("start", req)
-	boundaryp := getDateParam("boundary", req)
-	endp := getDateParam("end", req)
-	if startp != nil && boundaryp != nil && endp != nil {
-		return *startp, *boundaryp, *endp
+// getTimeWindowOrFail returns the window a report covers from the start, end and window params, defaulting to the
+// two weeks prior to the report end, and echoes it in the response headers. Invalid params respond with a 400 and
+// return false.
+func getTimeWindowOrFail(w http.ResponseWriter, req *http.Request, reportEnd time.Time) (start, end time.Time, ok bool) {
+	start, end, err := api.ParseTimeWindow(req, reportEnd, api.DefaultTimeWindow)
+	if err != nil {
+		respondBadRequest(w, err)
+		return start, end, false
 	}
+	api.SetTimeWindowHeaders(w, start, time.Time{}, end)
+	return start, end, true
+}
 
-	// Otherwise generate from the period name
-	return util.PeriodToDates(period, reportEnd)
+// getPeriodWindowOrFail returns the window of a report comparing the time after boundary with the time before it,
+// defaulting to the period param or defaultPeriod, and echoes it in the response headers. Invalid params respond
+// with a 400 and return false.
+func getPeriodWindowOrFail(w http.ResponseWriter, req *http.Request, defaultPeriod string, reportEnd time.Time) (start, boundary, end time.Time, ok bool) {
+	start, boundary, end, err := api.ParsePeriodWindow(req, getPeriod(req, defaultPeriod), reportEnd)
+	if err != nil {
+		respondBadRequest(w, err)
+		return start, boundary, end, false
+	}
+	api.SetTimeWindowHeaders(w, start, boundary, end)
+	return start, boundary, end, true
 }
 
-func getDateParam(paramName string, req *http.Request) *time.Time {
-	param := req.URL.Query().Get(paramName)
-	if param != "" {
-		t, err := time.Parse("2006-01-02", param)
-		if err != nil {
-			log.WithError(err).Warningf("error decoding %q param: %s", param, err.Error())
-			return nil
-		}
-		return &t
+// getTimezoneOrFail returns the location of the timezone param daily buckets start at midnight in, defaulting to
+// UTC. An unknown timezone responds with a 400 and returns false.
+func getTimezoneOrFail(w http.ResponseWriter, req *http.Request) (*time.Location, bool) {
+	loc, err := api.ParseTimezone(req.URL.Query().Get("timezone"))
+	if err != nil {
+		respondBadRequest(w, err)
+		return nil, false
 	}
+	return loc, true
+}
 
-	return nil
+func respondBadRequest(w http.ResponseWriter, err error) {
+	api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+		"code":    http.StatusBadRequest,
+		"message": err.Error(),
+	})
 }
 
 func getPeriod(req *http.Request, defaultValue string) string {
@@ -100,16 +112,15 @@ func getPaginationParams(req *http.Request) (*apitype.Pagination, error) {
 	return nil, nil
 }
 
-func getSortParams(req *http.Request) (string, apitype.Sort) {
-	sortField := req.URL.Query().Get("sortField")
-	sort := apitype.Sort(req.URL.Query().Get("sort"))
-	if sortField == "" {
-		sortField = defaultSortField
-	}
-	if sort == "" {
-		sort = defaultSort
+// getSortParamsOrFail returns the sort field and direction of a request, defaulting to descending by name. An invalid
+// sort responds with a 400 and returns false.
+func getSortParamsOrFail(w http.ResponseWriter, req *http.Request) (string, apitype.Sort, bool) {
+	sortField, sort, err := filter.SortParamsFromRequest(req, defaultSortField, defaultSort)
+	if err != nil {
+		respondBadRequest(w, err)
+		return sortField, sort, false
 	}
-	return sortField, sort
+	return sortField, sort, true
 }
 
 func splitJobAndJobRunFilters(fil *filter.Filter) (*filter.Filter, *filter.Filter, error) {
@@ -139,3 +150,12 @@ func splitJobAndJobRunFilters(fil *filter.Filter) (*filter.Filter, *filter.Filte
 	}
 	return jobFilter, jobRunsFilter, nil
 }
+
+// getRequestUser returns who made a request, as set by the authenticating proxy in front of sippy, or an empty
+// string when the request wasn't authenticated.
+func getRequestUser(req *http.Request) string {
+	if user := req.Header.Get("X-Forwarded-User"); user != "" {
+		return user
+	}
+	return req.Header.Get("X-Forwarded-Email")
+}
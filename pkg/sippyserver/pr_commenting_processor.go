@@ -15,7 +15,6 @@ import (
 	"cloud.google.com/go/storage"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	log "github.com/sirupsen/logrus"
 	"google.golang.org/api/iterator"
 	"gorm.io/gorm"
 
@@ -155,7 +154,7 @@ func (wp *WorkProcessor) Run(ctx context.Context) {
 	}
 
 	if commentWorker.dryRunOnly {
-		log.Warning("Github Comment Worker started in dry run only mode, active commenting is disabled")
+		logger.Warning("Github Comment Worker started in dry run only mode, active commenting is disabled")
 	}
 	go commentWorker.Run()
 
@@ -199,13 +198,13 @@ func (wp *WorkProcessor) Run(ctx context.Context) {
 					running = false
 				}
 			} else {
-				log.Info("Work still pending, skipping WorkProcessor work cycle")
+				logger.Info("Work still pending, skipping WorkProcessor work cycle")
 			}
 
 		}
 	}
 
-	log.Info("No longer active, shutting down")
+	logger.Info("No longer active, shutting down")
 
 }
 
@@ -217,7 +216,7 @@ func (wp *WorkProcessor) work(ctx context.Context, pendingWork chan models.PullR
 		buildPendingWork.WithLabelValues("work-processor").Observe(float64(end.UnixMilli() - start.UnixMilli()))
 	}()
 
-	log.Debug("Checking for work")
+	logger.Debug("Checking for work")
 
 	// get a list of items
 	// process each item one at a time while checking for shutdown
@@ -225,7 +224,7 @@ func (wp *WorkProcessor) work(ctx context.Context, pendingWork chan models.PullR
 	items, err := wp.fetchItems()
 
 	if err != nil {
-		log.WithError(err).Error("Failed to query pending comments")
+		logger.WithError(err).Error("Failed to query pending comments")
 
 		// we want to keep our processor loop active so don't pass the error back up
 		return nil
@@ -237,17 +236,17 @@ func (wp *WorkProcessor) work(ctx context.Context, pendingWork chan models.PullR
 
 		case <-ctx.Done():
 
-			log.Info("Context is done, stopping processor")
+			logger.Info("Context is done, stopping processor")
 			return errors.New("context closed")
 
 		default:
-			log.Debugf("Adding item to pending work: %s/%s/%d/%s", i.Org, i.Repo, i.PullNumber, i.SHA)
+			logger.Debugf("Adding item to pending work: %s/%s/%d/%s", i.Org, i.Repo, i.PullNumber, i.SHA)
 			pendingWork <- i
-			log.Debugf("Item added to pending work: %s/%s/%d/%s", i.Org, i.Repo, i.PullNumber, i.SHA)
+			logger.Debugf("Item added to pending work: %s/%s/%d/%s", i.Org, i.Repo, i.PullNumber, i.SHA)
 		}
 	}
 
-	log.Debug("Finished Checking for work")
+	logger.Debug("Finished Checking for work")
 	return nil
 }
 
@@ -268,11 +267,11 @@ func (cw *CommentWorker) Run() {
 		// if we had an error here this is different from errors with GitHub
 		// log them but don't include in the rate limiter
 		if err != nil {
-			log.WithError(err).Errorf("Error validating pending record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
+			logger.WithError(err).Errorf("Error validating pending record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
 			continue
 		}
 		if !commentReady {
-			log.Infof("Skipping pending record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
+			logger.Infof("Skipping pending record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
 			continue
 		}
 
@@ -288,12 +287,12 @@ func (cw *CommentWorker) Run() {
 				writeCommentErrorMetric.WithLabelValues(pc.org, pc.repo).Set(errCount)
 			}
 		} else {
-			log.WithError(err).Errorf("Error processing record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
+			logger.WithError(err).Errorf("Error processing record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
 			errCount++
 			writeCommentErrorMetric.WithLabelValues(pc.org, pc.repo).Set(errCount)
 			err = cw.ghCommenter.UpdatePendingRecordErrorCount(pc.org, pc.repo, pc.number, pc.sha, models.CommentType(pc.commentType))
 			if err != nil {
-				log.WithError(err).Errorf("Error updating error count for record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
+				logger.WithError(err).Errorf("Error updating error count for record %s/%s/%d - %s", pc.org, pc.repo, pc.number, pc.sha)
 			}
 		}
 
@@ -301,7 +300,7 @@ func (cw *CommentWorker) Run() {
 		// if no errors then we reduce any current backoff
 		cw.commentUpdaterRateLimiter.UpdateRate(err != nil)
 
-		log.Debug("Pending comment processed")
+		logger.Debug("Pending comment processed")
 	}
 }
 
@@ -326,7 +325,7 @@ func (cw *CommentWorker) writeComment(ghCommenter *commenter.GitHubCommenter, pe
 		return nil
 	}
 
-	logger := log.WithField("org", pendingComment.org).
+	logger := logger.WithField("org", pendingComment.org).
 		WithField("repo", pendingComment.repo).
 		WithField("number", pendingComment.number)
 
@@ -422,7 +421,7 @@ func (aw *AnalysisWorker) Run() {
 		if i.CommentType == int(models.CommentTypeRiskAnalysis) {
 			aw.processRiskAnalysisComment(i)
 		} else {
-			log.Warningf("Unsupported comment type: %d for %s/%s/%d/%s", i.CommentType, i.Org, i.Repo, i.PullNumber, i.SHA)
+			logger.Warningf("Unsupported comment type: %d for %s/%s/%d/%s", i.CommentType, i.Org, i.Repo, i.PullNumber, i.SHA)
 		}
 
 	}
@@ -430,7 +429,7 @@ func (aw *AnalysisWorker) Run() {
 
 func (aw *AnalysisWorker) processRiskAnalysisComment(prPendingComment models.PullRequestComment) {
 
-	logger := log.WithField("org", prPendingComment.Org).
+	logger := logger.WithField("org", prPendingComment.Org).
 		WithField("repo", prPendingComment.Repo).
 		WithField("Number", prPendingComment.PullNumber).
 		WithField("sha", prPendingComment.SHA)
@@ -497,9 +496,9 @@ func (aw *AnalysisWorker) processRiskAnalysisComment(prPendingComment models.Pul
 	}
 
 	// will block if the buffer is full
-	log.Debugf("Adding comment to pendingComments: %s/%s/%s", pendingComment.org, pendingComment.repo, pendingComment.sha)
+	logger.Debugf("Adding comment to pendingComments: %s/%s/%s", pendingComment.org, pendingComment.repo, pendingComment.sha)
 	aw.pendingComments <- pendingComment
-	log.Debugf("Comment added to pendingComments: %s/%s/%s", pendingComment.org, pendingComment.repo, pendingComment.sha)
+	logger.Debugf("Comment added to pendingComments: %s/%s/%s", pendingComment.org, pendingComment.repo, pendingComment.sha)
 }
 
 func buildComment(sortedAnalysis RiskAnalysisEntryList, sha string) string {
@@ -602,7 +601,7 @@ func (aw *AnalysisWorker) buildPRJobRiskAnalysis(prRoot string, dryrun bool) (bo
 		}
 
 		if err != nil {
-			log.WithError(err).Warningf("gcs bucket iterator returned error")
+			logger.WithError(err).Warningf("gcs bucket iterator returned error")
 			continue
 		}
 
@@ -621,7 +620,7 @@ func (aw *AnalysisWorker) buildPRJobRiskAnalysis(prRoot string, dryrun bool) (bo
 		// we have to get the data from latest-build.txt and then check for finished.json in that path
 		bytes, err := jobRun.GetContent(context.TODO(), fmt.Sprintf("%s%s", attrs.Prefix, "latest-build.txt"))
 		if err != nil {
-			log.WithError(err).Errorf("Failed to get latest build info for: %s", attrs.Prefix)
+			logger.WithError(err).Errorf("Failed to get latest build info for: %s", attrs.Prefix)
 			return false, nil
 		}
 
@@ -680,28 +679,28 @@ func (aw *AnalysisWorker) buildPRJobRiskAnalysis(prRoot string, dryrun bool) (bo
 
 		// we didn't find the latest so log a warning and continue on
 		if latestProwJob == nil {
-			log.Warnf("Failed to find latest prowjob for: %s", latestPath)
+			logger.Warnf("Failed to find latest prowjob for: %s", latestPath)
 			continue
 		}
 
 		// at times it appears that we add a comment that reflects the prior job
 		// and then update again shortly after
 		if latestProwJob.Status.StartTime.Before(mostRecentStartTime) {
-			log.Warnf("Latest prowjob start time: %s is before mostRecentStartTime: %s", latestProwJob.Status.StartTime.Format(time.RFC3339), mostRecentStartTime.Format(time.RFC3339))
+			logger.Warnf("Latest prowjob start time: %s is before mostRecentStartTime: %s", latestProwJob.Status.StartTime.Format(time.RFC3339), mostRecentStartTime.Format(time.RFC3339))
 			continue
 		}
 
 		// job count is > 1, but we didn't find a valid prior job
 		// Completion time is validated in buildProwJobMap
 		if priorProwJob == nil || latestProwJob.Status.CompletionTime.Before(*priorProwJob.Status.CompletionTime) {
-			log.Warnf("Invalid prior prowjob for: %s", latestPath)
+			logger.Warnf("Invalid prior prowjob for: %s", latestPath)
 			continue
 		}
 
 		priorRunID := priorProwJob.Status.BuildID
 		// lastly sanity check that our priorRun && latest are not the same
 		if latest == priorRunID {
-			log.Warnf("Prior prowjob: %s and latest: %s are the same", priorRunID, latest)
+			logger.Warnf("Prior prowjob: %s and latest: %s are the same", priorRunID, latest)
 			continue
 		}
 
@@ -711,7 +710,7 @@ func (aw *AnalysisWorker) buildPRJobRiskAnalysis(prRoot string, dryrun bool) (bo
 		// this can happen if the job hasn't been imported yet
 		// and the prior risk analysis artifact failed to be created in gcs
 		if priorRiskAnalysis == nil {
-			log.Warnf("Failed to determine prior risk analysis for prowjob: %s", priorRunID)
+			logger.Warnf("Failed to determine prior risk analysis for prowjob: %s", priorRunID)
 			continue
 		}
 
@@ -756,13 +755,13 @@ func (aw *AnalysisWorker) buildProwJobMap(prJobRoot string) (map[time.Time]prow.
 
 		bytes, err := jobRun.GetContent(context.TODO(), fmt.Sprintf("%s%s", attrs.Prefix, "prowjob.json"))
 		if err != nil {
-			log.WithError(err).Errorf("Failed to get prowjob for: %s", attrs.Prefix)
+			logger.WithError(err).Errorf("Failed to get prowjob for: %s", attrs.Prefix)
 			continue
 		}
 
 		var pj prow.ProwJob
 		if err := json.Unmarshal(bytes, &pj); err != nil {
-			log.WithError(err).Errorf("Failed to unmarshall prowjob for: %s", attrs.Prefix)
+			logger.WithError(err).Errorf("Failed to unmarshall prowjob for: %s", attrs.Prefix)
 			continue
 		}
 
@@ -773,7 +772,7 @@ func (aw *AnalysisWorker) buildProwJobMap(prJobRoot string) (map[time.Time]prow.
 			// not sure if we sometimes get duplicate jobs with different completion times
 			// but adding defensive check in case
 			if buildIDSet.Has(pj.Status.BuildID) {
-				log.Warnf("BuildID: %s has been processed already", pj.Status.BuildID)
+				logger.Warnf("BuildID: %s has been processed already", pj.Status.BuildID)
 				continue
 			}
 
@@ -796,14 +795,14 @@ func (aw *AnalysisWorker) getRiskSummary(jobRunID, jobRunIDPath string, priorRis
 	jobRunIntID, err := strconv.ParseInt(jobRunID, 10, 64)
 
 	if err != nil {
-		log.WithError(err).Errorf("Failed to parse jobRunId id: %s for: %s", jobRunID, jobRunIDPath)
+		logger.WithError(err).Errorf("Failed to parse jobRunId id: %s for: %s", jobRunID, jobRunIDPath)
 
 		// skip the db lookup and go right to gcs
 		riskSummary, riskAnalysis = aw.getGCSOverallRiskLevel(jobRunIDPath)
 	} else {
 
 		// lookup prowjob and run count
-		logger := log.WithField("jobRunID", jobRunIntID)
+		logger := logger.WithField("jobRunID", jobRunIntID)
 		jobRun, jobRunTestCount, err := jobQueries.FetchJobRun(aw.dbc, jobRunIntID, logger)
 
 		if err != nil {
@@ -890,7 +889,7 @@ func isTestFiltered(test api.ProwJobRunTestRiskAnalysis, priorRiskAnalysis *api.
 func (aw *AnalysisWorker) getGCSOverallRiskLevel(latestPath string) (api.RiskSummary, *api.ProwJobRunRiskAnalysis) {
 	riskAnalysis, err := aw.getJobRunGCSRiskAnalysis(latestPath)
 	if err != nil {
-		log.WithError(err).Errorf("Error with fallback lookup of gcs RiskAnalysis for: %s", latestPath)
+		logger.WithError(err).Errorf("Error with fallback lookup of gcs RiskAnalysis for: %s", latestPath)
 		return api.RiskSummary{
 			OverallRisk: api.JobFailureRisk{Level: api.FailureRiskLevelUnknown},
 		}, nil
@@ -0,0 +1,202 @@
+package sippyserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/openshift/sippy/pkg/api"
+)
+
+const (
+	ProbeStatusOK      = "ok"
+	ProbeStatusStale   = "stale"
+	ProbeStatusFailed  = "failed"
+	ProbeStatusSkipped = "skipped"
+
+	// DefaultReadinessMaxDataAge is how old the newest imported job run may be before /readyz reports the data
+	// as stale.
+	DefaultReadinessMaxDataAge = 24 * time.Hour
+
+	probeTimeout  = 5 * time.Second
+	probeCacheKey = "sippy-readyz-probe"
+)
+
+// ProbeCheck is the result of a single readiness check.
+type ProbeCheck struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// ProbeResult is the body returned by /readyz. Status is the worst status of any check, so monitors can tell a
+// server that is up but serving stale data from one that is healthy or one that cannot serve at all.
+type ProbeResult struct {
+	Status string       `json:"status"`
+	Checks []ProbeCheck `json:"checks"`
+}
+
+type probe struct {
+	name  string
+	check func(ctx context.Context) (string, string)
+}
+
+// SetReadinessMaxDataAge configures the data freshness threshold used by /readyz.
+func (s *Server) SetReadinessMaxDataAge(maxAge time.Duration) {
+	s.readinessMaxDataAge = maxAge
+}
+
+func (s *Server) livez(w http.ResponseWriter, req *http.Request) {
+	api.RespondWithJSON(http.StatusOK, w, map[string]string{"status": ProbeStatusOK})
+}
+
+// readyz runs all readiness checks. A failed check returns 503, a stale one returns 200 unless strict=true is
+// given, in which case stale data also makes the server unready.
+func (s *Server) readyz(w http.ResponseWriter, req *http.Request) {
+	result := runProbes(req.Context(), s.readinessProbes())
+
+	code := http.StatusOK
+	if result.Status == ProbeStatusFailed || (result.Status == ProbeStatusStale && req.URL.Query().Get("strict") == "true") {
+		code = http.StatusServiceUnavailable
+	}
+	api.RespondWithJSON(code, w, result)
+}
+
+func (s *Server) readinessProbes() []probe {
+	return []probe{
+		{name: "database", check: s.checkDatabase},
+		{name: "bigquery", check: s.checkBigQuery},
+		{name: "cache", check: s.checkCache},
+		{name: "data_freshness", check: s.checkDataFreshness},
+	}
+}
+
+// runProbes runs all probes concurrently, each bounded by probeTimeout, and aggregates their statuses.
+func runProbes(ctx context.Context, probes []probe) ProbeResult {
+	result := ProbeResult{Status: ProbeStatusOK, Checks: make([]ProbeCheck, len(probes))}
+
+	wg := sync.WaitGroup{}
+	for i, p := range probes {
+		wg.Add(1)
+		go func(i int, p probe) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+			defer cancel()
+
+			start := time.Now()
+			status, message := p.check(checkCtx)
+			result.Checks[i] = ProbeCheck{
+				Name:       p.name,
+				Status:     status,
+				Message:    message,
+				DurationMS: time.Since(start).Milliseconds(),
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	for _, c := range result.Checks {
+		switch c.Status {
+		case ProbeStatusFailed:
+			result.Status = ProbeStatusFailed
+		case ProbeStatusStale:
+			if result.Status != ProbeStatusFailed {
+				result.Status = ProbeStatusStale
+			}
+		}
+	}
+	return result
+}
+
+func (s *Server) checkDatabase(ctx context.Context) (string, string) {
+	if s.db == nil || s.db.DB == nil {
+		return ProbeStatusSkipped, "no database configured"
+	}
+	sqlDB, err := s.db.DB.DB()
+	if err != nil {
+		return ProbeStatusFailed, err.Error()
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return ProbeStatusFailed, err.Error()
+	}
+	return ProbeStatusOK, ""
+}
+
+func (s *Server) checkBigQuery(ctx context.Context) (string, string) {
+	if s.bigQueryClient == nil || s.bigQueryClient.BQ == nil {
+		return ProbeStatusSkipped, "no bigquery client configured"
+	}
+	if _, err := s.bigQueryClient.BQ.Dataset(s.bigQueryClient.Dataset).Metadata(ctx); err != nil {
+		return ProbeStatusFailed, err.Error()
+	}
+	return ProbeStatusOK, ""
+}
+
+func (s *Server) checkCache(ctx context.Context) (string, string) {
+	if s.cache == nil {
+		return ProbeStatusSkipped, "no cache configured"
+	}
+
+	// The cache interface has no context, so bound the round trip ourselves.
+	done := make(chan error, 1)
+	go func() {
+		value := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+		if err := s.cache.Set(probeCacheKey, value, time.Minute); err != nil {
+			done <- err
+			return
+		}
+		got, err := s.cache.Get(probeCacheKey)
+		if err != nil {
+			done <- err
+			return
+		}
+		if !bytes.Equal(got, value) {
+			done <- fmt.Errorf("cache returned %q, expected %q", got, value)
+			return
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return ProbeStatusFailed, err.Error()
+		}
+		return ProbeStatusOK, ""
+	case <-ctx.Done():
+		return ProbeStatusFailed, ctx.Err().Error()
+	}
+}
+
+func (s *Server) checkDataFreshness(ctx context.Context) (string, string) {
+	if s.db == nil || s.db.DB == nil {
+		return ProbeStatusSkipped, "no database configured"
+	}
+	if s.pinnedDateTime != nil {
+		return ProbeStatusSkipped, "server is pinned to a fixed date"
+	}
+
+	var lastImport *time.Time
+	if res := s.db.DB.WithContext(ctx).Raw("SELECT MAX(created_at) FROM prow_job_runs").Scan(&lastImport); res.Error != nil {
+		return ProbeStatusFailed, res.Error.Error()
+	}
+	return dataFreshness(lastImport, time.Now(), s.readinessMaxDataAge)
+}
+
+func dataFreshness(lastImport *time.Time, now time.Time, maxAge time.Duration) (string, string) {
+	if lastImport == nil {
+		return ProbeStatusStale, "no job runs have been imported"
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultReadinessMaxDataAge
+	}
+	age := now.Sub(*lastImport).Round(time.Second)
+	if age > maxAge {
+		return ProbeStatusStale, fmt.Sprintf("last job run imported %s ago, exceeds threshold of %s", age, maxAge)
+	}
+	return ProbeStatusOK, fmt.Sprintf("last job run imported %s ago", age)
+}
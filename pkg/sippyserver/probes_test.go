@@ -0,0 +1,85 @@
+package sippyserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func staticProbe(name, status string) probe {
+	return probe{name: name, check: func(ctx context.Context) (string, string) { return status, "" }}
+}
+
+func TestRunProbes(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string
+		expected string
+	}{
+		{name: "all ok", statuses: []string{ProbeStatusOK, ProbeStatusOK}, expected: ProbeStatusOK},
+		{name: "skipped is ok", statuses: []string{ProbeStatusOK, ProbeStatusSkipped}, expected: ProbeStatusOK},
+		{name: "stale", statuses: []string{ProbeStatusStale, ProbeStatusOK}, expected: ProbeStatusStale},
+		{name: "failed wins over stale", statuses: []string{ProbeStatusFailed, ProbeStatusStale}, expected: ProbeStatusFailed},
+		{name: "stale after failed", statuses: []string{ProbeStatusStale, ProbeStatusFailed}, expected: ProbeStatusFailed},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			probes := []probe{}
+			for i, s := range tc.statuses {
+				probes = append(probes, staticProbe(string(rune('a'+i)), s))
+			}
+			result := runProbes(context.Background(), probes)
+			assert.Equal(t, tc.expected, result.Status)
+			require.Len(t, result.Checks, len(tc.statuses))
+			for i, s := range tc.statuses {
+				assert.Equal(t, string(rune('a'+i)), result.Checks[i].Name)
+				assert.Equal(t, s, result.Checks[i].Status)
+			}
+		})
+	}
+}
+
+func TestDataFreshness(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-2 * time.Hour)
+	old := now.Add(-30 * time.Hour)
+
+	status, _ := dataFreshness(&recent, now, 24*time.Hour)
+	assert.Equal(t, ProbeStatusOK, status)
+
+	status, msg := dataFreshness(&old, now, 24*time.Hour)
+	assert.Equal(t, ProbeStatusStale, status)
+	assert.Contains(t, msg, "30h0m0s")
+
+	status, _ = dataFreshness(&old, now, 48*time.Hour)
+	assert.Equal(t, ProbeStatusOK, status)
+
+	status, _ = dataFreshness(nil, now, 24*time.Hour)
+	assert.Equal(t, ProbeStatusStale, status)
+}
+
+func TestReadyzWithoutDependencies(t *testing.T) {
+	s := &Server{readinessMaxDataAge: DefaultReadinessMaxDataAge}
+
+	rec := httptest.NewRecorder()
+	s.readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	result := ProbeResult{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, ProbeStatusOK, result.Status)
+	require.Len(t, result.Checks, 4)
+	for _, c := range result.Checks {
+		assert.Equal(t, ProbeStatusSkipped, c.Status, c.Name)
+	}
+
+	rec = httptest.NewRecorder()
+	s.livez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,29 @@
+package sippyserver
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	// DatabaseHeader is whether a response was read from a database replica or the primary, and
+	// ReplicationLagHeader how many seconds the replica was behind. They're only set when replicas are configured.
+	DatabaseHeader       = "X-Sippy-Database"
+	ReplicationLagHeader = "X-Sippy-Replication-Lag"
+)
+
+// withDatabaseHeaders tells clients whether a response was read from a database replica, and how far behind the
+// primary it was, when replicas are configured.
+func (s *Server) withDatabaseHeaders(handler func(w http.ResponseWriter, r *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.db.HasReplicas() {
+			if onReplica, lag := s.db.ReplicationLag(); onReplica {
+				w.Header().Set(DatabaseHeader, "replica")
+				w.Header().Set(ReplicationLagHeader, strconv.Itoa(int(lag.Seconds())))
+			} else {
+				w.Header().Set(DatabaseHeader, "primary")
+			}
+		}
+		handler(w, r)
+	}
+}
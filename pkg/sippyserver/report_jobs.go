@@ -0,0 +1,115 @@
+package sippyserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openshift/sippy/pkg/api"
+	"github.com/openshift/sippy/pkg/reportqueue"
+)
+
+const (
+	// reportWaitTimeout is how long a frontend waits for a queued report before telling the client to retry, it's
+	// below the timeout of the proxies in front of the API.
+	reportWaitTimeout = 50 * time.Second
+	reportJobsListed  = 100
+)
+
+// EnqueueReports hands the generation of component reports to report workers instead of generating them in the
+// API process.
+func (s *Server) EnqueueReports() {
+	s.enqueueReports = true
+}
+
+// ReportJobHandlers returns the handlers report workers generate queued reports with, by endpoint path.
+func (s *Server) ReportJobHandlers() map[string]http.HandlerFunc {
+	return map[string]http.HandlerFunc{
+		"/api/component_readiness": s.generateComponentReport,
+	}
+}
+
+// reportJobKey identifies a report request, with its parameters sorted so the same report shares a job.
+func reportJobKey(req *http.Request) string {
+	return req.URL.Path + "?" + req.URL.Query().Encode()
+}
+
+// respondFromReportQueue enqueues a report request for the report workers and returns its response, or a 202
+// asking the client to retry if it isn't ready within reportWaitTimeout.
+func (s *Server) respondFromReportQueue(w http.ResponseWriter, req *http.Request) {
+	job, err := reportqueue.Enqueue(s.db, req.URL.Path, reportJobKey(req))
+	if err != nil {
+		logger.WithError(err).Error("error enqueueing report")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error enqueueing report",
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), reportWaitTimeout)
+	defer cancel()
+	job, err = reportqueue.Wait(ctx, s.db, job.ID, time.Second)
+	if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		logger.WithError(err).Error("error waiting for report")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error waiting for report",
+		})
+		return
+	}
+	if !reportqueue.Finished(job) {
+		api.RespondWithJSON(http.StatusAccepted, w, map[string]interface{}{
+			"code":    http.StatusAccepted,
+			"message": "the report is being generated, retry the request to get it",
+			"job":     job,
+		})
+		return
+	}
+	api.RespondWithJSON(job.ResultCode, w, string(job.Result))
+}
+
+// jsonReportJobs lists recent report jobs, or returns the one with the given id.
+func (s *Server) jsonReportJobs(w http.ResponseWriter, req *http.Request) {
+	idParam := req.URL.Query().Get("id")
+	if idParam == "" {
+		jobs, err := reportqueue.List(s.db, reportJobsListed)
+		if err != nil {
+			logger.WithError(err).Error("error listing report jobs")
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+				"code":    http.StatusInternalServerError,
+				"message": "error listing report jobs",
+			})
+			return
+		}
+		api.RespondWithJSON(http.StatusOK, w, jobs)
+		return
+	}
+
+	id, err := strconv.ParseUint(idParam, 10, 64)
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "invalid id: " + err.Error(),
+		})
+		return
+	}
+	job, err := reportqueue.Get(s.db, uint(id))
+	if errors.Is(err, reportqueue.ErrNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error getting report job")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error getting report job",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, job)
+}
@@ -1,8 +1,10 @@
 package sippyserver
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"maps"
 	"net/http"
@@ -23,22 +25,32 @@ import (
 	"github.com/prometheus/client_golang/prometheus/push"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/openshift/sippy/pkg/anomaly"
 	"github.com/openshift/sippy/pkg/api"
 	"github.com/openshift/sippy/pkg/api/jobrunintervals"
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	crtype "github.com/openshift/sippy/pkg/apis/api/componentreport"
 	"github.com/openshift/sippy/pkg/apis/cache"
 	"github.com/openshift/sippy/pkg/bigquery"
+	"github.com/openshift/sippy/pkg/dashboards"
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
 	"github.com/openshift/sippy/pkg/db"
 	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/db/query"
+	"github.com/openshift/sippy/pkg/externalci"
+	"github.com/openshift/sippy/pkg/featureflags"
 	"github.com/openshift/sippy/pkg/filter"
+	"github.com/openshift/sippy/pkg/jobpurge"
+	"github.com/openshift/sippy/pkg/logging"
 	"github.com/openshift/sippy/pkg/synthetictests"
 	"github.com/openshift/sippy/pkg/testidentification"
 	"github.com/openshift/sippy/pkg/util"
+	"github.com/openshift/sippy/pkg/util/sets"
+	"github.com/openshift/sippy/pkg/variantregistry"
 )
 
+var logger = logging.ForSubsystem("server")
+
 // Mode defines the server mode of operation, OpenShift or upstream Kubernetes.
 type Mode string
 
@@ -47,6 +59,10 @@ const (
 	ModeKubernetes Mode = "kube"
 )
 
+// reportMemoryRetryAfterSeconds is how long clients are asked to wait before retrying a component report that
+// couldn't be generated because other reports were using all the memory reports are allowed.
+const reportMemoryRetryAfterSeconds = 30
+
 func NewServer(
 	mode Mode,
 	listenAddr string,
@@ -81,6 +97,7 @@ func NewServer(
 		cache:                cacheClient,
 		crTimeRoundingFactor: crTimeRoundingFactor,
 		views:                views,
+		readinessMaxDataAge:  DefaultReadinessMaxDataAge,
 	}
 
 	if bigQueryClient != nil {
@@ -120,6 +137,47 @@ type Server struct {
 	crTimeRoundingFactor time.Duration
 	capabilities         []string
 	views                *apitype.SippyViews
+	readinessMaxDataAge  time.Duration
+	featureFlags         *featureflags.Manager
+	accessLogStore       *AccessLogStore
+	jobPurgeEnabled      bool
+	externalRunsEnabled  bool
+	manualResultsEnabled bool
+	writeAPIEnabled      bool
+	adminUsers           sets.String
+	enqueueReports       bool
+}
+
+// SetFeatureFlags configures the feature flags evaluated for each API request.
+func (s *Server) SetFeatureFlags(manager *featureflags.Manager) {
+	s.featureFlags = manager
+}
+
+// EnableJobPurge enables the API deleting all data for selected jobs.
+func (s *Server) EnableJobPurge() {
+	s.jobPurgeEnabled = true
+}
+
+// EnableExternalJobRuns enables the API ingesting job runs from CI systems other than Prow.
+func (s *Server) EnableExternalJobRuns() {
+	s.externalRunsEnabled = true
+}
+
+// EnableManualTestResults enables the API recording test results QE ran by hand.
+func (s *Server) EnableManualTestResults() {
+	s.manualResultsEnabled = true
+}
+
+// EnableWriteAPI enables creating, updating and deleting incidents, watchlist subscriptions, lanes and dashboards
+// through the API.
+func (s *Server) EnableWriteAPI() {
+	s.writeAPIEnabled = true
+}
+
+// SetAdminUsers configures the users, as authenticated by the proxy in front of sippy, allowed to change feature
+// flags and log levels through the API.
+func (s *Server) SetAdminUsers(users []string) {
+	s.adminUsers = sets.NewString(users...)
 }
 
 func (s *Server) GetReportEnd() time.Time {
@@ -140,11 +198,11 @@ func refreshMaterializedViews(dbc *db.DB, refreshMatviewOnlyIfEmpty bool) {
 		promPusher.Collector(allMatViewsRefreshMetric)
 	}
 
-	log.Info("refreshing materialized views")
+	logger.Info("refreshing materialized views")
 	allStart := time.Now()
 
 	if dbc == nil {
-		log.Info("skipping materialized view refresh as server has no db connection provided")
+		logger.Info("skipping materialized view refresh as server has no db connection provided")
 		return
 	}
 	// create a channel for work "tasks"
@@ -166,15 +224,15 @@ func refreshMaterializedViews(dbc *db.DB, refreshMatviewOnlyIfEmpty bool) {
 	wg.Wait()
 
 	allElapsed := time.Since(allStart)
-	log.WithField("elapsed", allElapsed).Info("refreshed all materialized views")
+	logger.WithField("elapsed", allElapsed).Info("refreshed all materialized views")
 	allMatViewsRefreshMetric.Observe(float64(allElapsed.Milliseconds()))
 
 	if promPusher != nil {
-		log.Info("pushing metrics to prometheus gateway")
+		logger.Info("pushing metrics to prometheus gateway")
 		if err := promPusher.Add(); err != nil {
-			log.WithError(err).Error("could not push to prometheus pushgateway")
+			logger.WithError(err).Error("could not push to prometheus pushgateway")
 		} else {
-			log.Info("successfully pushed metrics to prometheus gateway")
+			logger.Info("successfully pushed metrics to prometheus gateway")
 		}
 	}
 }
@@ -183,7 +241,7 @@ func refreshMatview(dbc *db.DB, refreshMatviewOnlyIfEmpty bool, ch chan string,
 
 	for matView := range ch {
 		start := time.Now()
-		tmpLog := log.WithField("matview", matView)
+		tmpLog := logger.WithField("matview", matView)
 
 		// If requested, we only refresh the materialized view if it has no rows
 		if refreshMatviewOnlyIfEmpty {
@@ -223,11 +281,11 @@ func refreshMatview(dbc *db.DB, refreshMatviewOnlyIfEmpty bool, ch chan string,
 }
 
 func RefreshData(dbc *db.DB, pinnedDateTime *time.Time, refreshMatviewsOnlyIfEmpty bool) {
-	log.Infof("Refreshing data")
+	logger.Infof("Refreshing data")
 
 	refreshMaterializedViews(dbc, refreshMatviewsOnlyIfEmpty)
 
-	log.Infof("Refresh complete")
+	logger.Infof("Refresh complete")
 }
 
 func (s *Server) hasCapabilities(capabilities []string) bool {
@@ -261,7 +319,7 @@ func (s *Server) determineCapabilities() {
 		if hasBuildCluster, err := query.HasBuildClusterData(s.db); hasBuildCluster {
 			capabilities = append(capabilities, BuildClusterCapability)
 		} else if err != nil {
-			log.WithError(err).Warningf("could not fetch build cluster data")
+			logger.WithError(err).Warningf("could not fetch build cluster data")
 		}
 	}
 
@@ -305,211 +363,2047 @@ func (s *Server) jsonIncidentEvent(w http.ResponseWriter, req *http.Request) {
 	api.RespondWithJSON(http.StatusOK, w, results)
 }
 
-func (s *Server) jsonReleaseTagsEvent(w http.ResponseWriter, req *http.Request) {
-	release := s.getReleaseOrFail(w, req)
-	if release != "" {
-		filterOpts, err := filter.FilterOptionsFromRequest(req, "release_time", apitype.SortDescending)
+// jsonIncidents provides CRUD for the incident timeline. GET lists incidents overlapping start and end (or returns
+// a single incident by id, or the deleted incidents when deleted is true), POST creates an incident, PUT replaces
+// the incident with the given id, and DELETE soft deletes it.
+func (s *Server) jsonIncidents(w http.ResponseWriter, req *http.Request) {
+	var id uint
+	if idParam := req.URL.Query().Get("id"); idParam != "" {
+		parsed, err := strconv.ParseUint(idParam, 10, 64)
 		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse filter opts " + err.Error()})
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "invalid id: " + err.Error(),
+			})
 			return
 		}
+		id = uint(parsed)
+	}
+	if id == 0 && (req.Method == http.MethodPut || req.Method == http.MethodDelete) {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "id is required",
+		})
+		return
+	}
 
-		start, err := getISO8601Date("start", req)
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse start param" + err.Error()})
+	var incident models.Incident
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
+		if err := json.NewDecoder(req.Body).Decode(&incident); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding incident json in request body: %s", err),
+			})
+			return
+		}
+		if err := api.ValidateIncident(&incident); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
 			return
 		}
+	}
 
-		end, err := getISO8601Date("end", req)
+	var result interface{}
+	var err error
+	status := http.StatusOK
+	switch req.Method {
+	case http.MethodGet:
+		if id != 0 {
+			result, err = api.GetIncident(s.db, id)
+		} else if req.URL.Query().Get("deleted") == "true" {
+			result, err = api.ListDeletedIncidents(s.db)
+		} else {
+			start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+			if !ok {
+				return
+			}
+			result, err = api.ListIncidents(s.db, req.URL.Query().Get("release"), req.URL.Query().Get("job"), start, end)
+		}
+	case http.MethodPost:
+		err = api.CreateIncident(s.db, &incident)
+		result, status = incident, http.StatusCreated
+	case http.MethodPut:
+		err = api.UpdateIncident(s.db, id, &incident)
+		result = incident
+	case http.MethodDelete:
+		err = api.DeleteIncident(s.db, id, getRequestUser(req))
+		result = map[string]interface{}{"id": id}
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	if errors.Is(err, api.ErrIncidentNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error accessing incidents in db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error accessing incidents in db",
+		})
+		return
+	}
+	api.RespondWithJSON(status, w, result)
+}
+
+// jsonIncidentRestore undeletes the soft deleted incident with the given id.
+func (s *Server) jsonIncidentRestore(w http.ResponseWriter, req *http.Request) {
+	respondWithRestored(w, req, "incident", api.ErrIncidentNotFound, func(id uint) (interface{}, error) {
+		return api.RestoreIncident(s.db, id)
+	})
+}
+
+// jsonBasisPinRestore undeletes the soft deleted basis pin with the given id.
+func (s *Server) jsonBasisPinRestore(w http.ResponseWriter, req *http.Request) {
+	respondWithRestored(w, req, "basis pin", api.ErrBasisPinNotFound, func(id uint) (interface{}, error) {
+		return api.RestoreBasisPin(s.db, id)
+	})
+}
+
+// respondWithRestored handles a POST restoring the soft deleted record with the id param, responding with the
+// restored record.
+func respondWithRestored(w http.ResponseWriter, req *http.Request, kind string, notFound error, restore func(uint) (interface{}, error)) {
+	if req.Method != http.MethodPost {
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	id, err := strconv.ParseUint(req.URL.Query().Get("id"), 10, 64)
+	if err != nil || id == 0 {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "a valid id is required",
+		})
+		return
+	}
+
+	result, err := restore(uint(id))
+	if errors.Is(err, notFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": "deleted " + kind + " not found",
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Errorf("error restoring %s in db", kind)
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error restoring " + kind + " in db",
+		})
+		return
+	}
+	logger.WithFields(log.Fields{"id": id, "user": getRequestUser(req)}).Infof("restored %s", kind)
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonLanes provides CRUD for lanes, groups of jobs of one configuration across releases. GET lists lanes (or returns
+// a single lane by id), POST creates a lane, PUT replaces the lane with the given id, and DELETE removes it.
+func (s *Server) jsonLanes(w http.ResponseWriter, req *http.Request) {
+	var id uint
+	if idParam := req.URL.Query().Get("id"); idParam != "" {
+		parsed, err := strconv.ParseUint(idParam, 10, 64)
 		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse start param" + err.Error()})
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "invalid id: " + err.Error(),
+			})
 			return
 		}
+		id = uint(parsed)
+	}
+	if id == 0 && (req.Method == http.MethodPut || req.Method == http.MethodDelete) {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "id is required",
+		})
+		return
+	}
 
-		results, err := api.GetPayloadEvents(s.db, release, filterOpts, start, end)
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse start param" + err.Error()})
+	var lane models.Lane
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
+		if err := json.NewDecoder(req.Body).Decode(&lane); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding lane json in request body: %s", err),
+			})
 			return
 		}
+		if err := api.ValidateLane(&lane); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
 
-		api.RespondWithJSON(http.StatusOK, w, results)
+	var result interface{}
+	var err error
+	status := http.StatusOK
+	switch req.Method {
+	case http.MethodGet:
+		if id != 0 {
+			result, err = api.GetLane(s.db, id)
+		} else {
+			result, err = api.ListLanes(s.db)
+		}
+	case http.MethodPost:
+		err = api.CreateLane(s.db, &lane)
+		result, status = lane, http.StatusCreated
+	case http.MethodPut:
+		err = api.UpdateLane(s.db, id, &lane)
+		result = lane
+	case http.MethodDelete:
+		err = api.DeleteLane(s.db, id)
+		result = map[string]interface{}{"id": id}
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
 	}
-}
 
-func (s *Server) jsonReleasePullRequestsReport(w http.ResponseWriter, req *http.Request) {
-	api.PrintPullRequestsReport(w, req, s.db)
+	if errors.Is(err, api.ErrLaneNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error accessing lanes in db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error accessing lanes in db",
+		})
+		return
+	}
+	api.RespondWithJSON(status, w, result)
 }
 
-func (s *Server) jsonListPayloadJobRuns(w http.ResponseWriter, req *http.Request) {
-	// Release appears optional here, perhaps when listing all job runs for all payloads
-	// in the release, but this may not make sense. Likely this API call should be
-	// moved away from filters and possible support for multiple payloads at once to
-	// URL encoded single payload.
-	release := req.URL.Query().Get("release")
-	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
-	if err != nil {
-		log.WithError(err).Error("error")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "Error building job run report:" + err.Error()})
+// jsonLaneReport reports the pass rate of a lane's jobs together, or of every lane, comparing the current period
+// with the previous one.
+func (s *Server) jsonLaneReport(w http.ResponseWriter, req *http.Request) {
+	start, boundary, end, ok := getPeriodWindowOrFail(w, req, "default", s.GetReportEnd())
+	if !ok {
 		return
 	}
 
-	payloadJobRuns, err := api.ListPayloadJobRuns(s.db, filterOpts, release)
-	if err != nil {
-		log.WithError(err).Error("error listing payload job runs")
+	result, err := api.GetLaneReports(s.db, req.URL.Query().Get("lane"), req.URL.Query().Get("release"), start, boundary, end)
+	if errors.Is(err, api.ErrLaneNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error generating lane report")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error generating lane report",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+func (s *Server) jsonWatchSubscriptions(w http.ResponseWriter, req *http.Request) {
+	var id uint
+	if idParam := req.URL.Query().Get("id"); idParam != "" {
+		parsed, err := strconv.ParseUint(idParam, 10, 64)
+		if err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "invalid id: " + err.Error(),
+			})
+			return
+		}
+		id = uint(parsed)
+	}
+	if id == 0 && (req.Method == http.MethodPut || req.Method == http.MethodDelete) {
 		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
 			"code":    http.StatusBadRequest,
+			"message": "id is required",
+		})
+		return
+	}
+
+	var sub models.WatchSubscription
+	if req.Method == http.MethodPost || req.Method == http.MethodPut {
+		if err := json.NewDecoder(req.Body).Decode(&sub); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding watch subscription json in request body: %s", err),
+			})
+			return
+		}
+		sub.Subscriber = getRequestUser(req)
+		if err := api.ValidateWatchSubscription(&sub); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	var result interface{}
+	var err error
+	status := http.StatusOK
+	switch req.Method {
+	case http.MethodGet:
+		if id != 0 {
+			result, err = api.GetWatchSubscription(s.db, id)
+		} else {
+			result, err = api.ListWatchSubscriptions(s.db, req.URL.Query().Get("subscriber"))
+		}
+	case http.MethodPost:
+		err = api.CreateWatchSubscription(s.db, &sub)
+		result, status = sub, http.StatusCreated
+	case http.MethodPut:
+		err = api.UpdateWatchSubscription(s.db, id, &sub)
+		result = sub
+	case http.MethodDelete:
+		err = api.DeleteWatchSubscription(s.db, id)
+		result = map[string]interface{}{"id": id}
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	if errors.Is(err, api.ErrWatchSubscriptionNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
 			"message": err.Error(),
 		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error accessing watch subscriptions in db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error accessing watch subscriptions in db",
+		})
+		return
 	}
-	api.RespondWithJSON(http.StatusOK, w, payloadJobRuns)
+	api.RespondWithJSON(status, w, result)
 }
 
-// TODO: may want to merge with jsonReleaseHealthReport, but this is a fair bit slower, and release health is run
-// on startup many times over when we calculate the metrics.
-// if we could boil the go logic for building this down into a query, it could become another matview and then
-// could be run quickly, assembling into the health api much more easily
-func (s *Server) jsonGetPayloadAnalysis(w http.ResponseWriter, req *http.Request) {
+// jsonDashboards lists dashboards, or gets one by name, optionally substituting a release into its report
+// parameters. POST stores the YAML dashboard definition in the request body, DELETE removes a stored dashboard.
+func (s *Server) jsonDashboards(w http.ResponseWriter, req *http.Request) {
+	name := req.URL.Query().Get("name")
 	release := req.URL.Query().Get("release")
-	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"release" is required`),
+
+	var result interface{}
+	var err error
+	status := http.StatusOK
+	switch req.Method {
+	case http.MethodGet:
+		if name != "" {
+			var d *dashboards.Dashboard
+			d, err = api.GetDashboard(s.db, name)
+			if err == nil {
+				result = d.ForRelease(release)
+			}
+		} else {
+			var all []dashboards.Dashboard
+			all, err = api.ListDashboards(s.db)
+			for i := range all {
+				all[i] = all[i].ForRelease(release)
+			}
+			result = all
+		}
+	case http.MethodPost:
+		definition, readErr := io.ReadAll(req.Body)
+		if readErr == nil {
+			_, readErr = dashboards.Parse(definition)
+		}
+		if readErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": readErr.Error(),
+			})
+			return
+		}
+		var created bool
+		result, created, err = api.SaveDashboard(s.db, definition, getRequestUser(req))
+		if created {
+			status = http.StatusCreated
+		}
+	case http.MethodDelete:
+		if name == "" {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "name is required",
+			})
+			return
+		}
+		err = api.DeleteDashboard(s.db, name)
+		result = map[string]interface{}{"name": name}
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
 		})
 		return
 	}
-	stream := req.URL.Query().Get("stream")
-	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"stream" is required`),
+
+	if errors.Is(err, api.ErrDashboardNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if errors.Is(err, api.ErrDashboardReadOnly) {
+		api.RespondWithJSON(http.StatusConflict, w, map[string]interface{}{
+			"code":    http.StatusConflict,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error accessing dashboards")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error accessing dashboards",
 		})
 		return
 	}
-	arch := req.URL.Query().Get("arch")
-	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"arch" is required`),
+	api.RespondWithJSON(status, w, result)
+}
+
+// jsonFeatureFlags lists the effective feature flags, and sets or removes runtime overrides of them.
+func (s *Server) jsonFeatureFlags(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodGet {
+		api.RespondWithJSON(http.StatusOK, w, s.featureFlags.Flags())
+		return
+	}
+	if s.db == nil {
+		api.RespondWithJSON(http.StatusNotImplemented, w, map[string]interface{}{
+			"code":    http.StatusNotImplemented,
+			"message": "feature flag overrides require a database",
+		})
+		return
+	}
+
+	var result *featureflags.State
+	var err error
+	switch req.Method {
+	case http.MethodPut:
+		flag := featureflags.Flag{}
+		if decodeErr := json.NewDecoder(req.Body).Decode(&flag); decodeErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding feature flag: %s", decodeErr),
+			})
+			return
+		}
+		if validationErr := flag.Validate(); validationErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": validationErr.Error(),
+			})
+			return
+		}
+		result, err = api.SaveFeatureFlagOverride(s.db, s.featureFlags, flag, getRequestUser(req))
+	case http.MethodDelete:
+		name := req.URL.Query().Get("name")
+		if name == "" {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "name is required",
+			})
+			return
+		}
+		result, err = api.DeleteFeatureFlagOverride(s.db, s.featureFlags, name)
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	if errors.Is(err, api.ErrFeatureFlagNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error updating feature flags")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error updating feature flags",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonLogLevels lists the log levels of subsystems, and overrides or resets them. Levels are per process, so an
+// override only applies to the replica that served the request.
+func (s *Server) jsonLogLevels(w http.ResponseWriter, req *http.Request) {
+	var result logging.Level
+	var err error
+	switch req.Method {
+	case http.MethodGet:
+		api.RespondWithJSON(http.StatusOK, w, logging.Levels())
+		return
+	case http.MethodPut:
+		update := logging.Level{}
+		if decodeErr := json.NewDecoder(req.Body).Decode(&update); decodeErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding log level: %s", decodeErr),
+			})
+			return
+		}
+		level, parseErr := log.ParseLevel(update.Level)
+		if parseErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": parseErr.Error(),
+			})
+			return
+		}
+		result, err = logging.SetLevel(update.Subsystem, level)
+		if err == nil {
+			logger.WithFields(log.Fields{
+				"subsystem": update.Subsystem,
+				"level":     level,
+				"user":      getRequestUser(req),
+			}).Info("log level overridden")
+		}
+	case http.MethodDelete:
+		subsystem := req.URL.Query().Get("subsystem")
+		if subsystem == "" {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "subsystem is required",
+			})
+			return
+		}
+		result, err = logging.ResetLevel(subsystem)
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	if errors.Is(err, logging.ErrUnknownSubsystem) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonReleaseMilestones lists, defines and deletes release milestones. Milestones are snapshotted by the
+// milestones loader once they have passed.
+func (s *Server) jsonReleaseMilestones(w http.ResponseWriter, req *http.Request) {
+	var id uint
+	if idParam := req.URL.Query().Get("id"); idParam != "" {
+		parsed, err := strconv.ParseUint(idParam, 10, 64)
+		if err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "invalid id: " + err.Error(),
+			})
+			return
+		}
+		id = uint(parsed)
+	}
+
+	var result interface{}
+	var err error
+	status := http.StatusOK
+	switch req.Method {
+	case http.MethodGet:
+		result, err = api.ListReleaseMilestones(s.db, req.URL.Query().Get("release"))
+	case http.MethodPost:
+		var milestone models.ReleaseMilestone
+		if decodeErr := json.NewDecoder(req.Body).Decode(&milestone); decodeErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding milestone json in request body: %s", decodeErr),
+			})
+			return
+		}
+		if validationErr := api.ValidateReleaseMilestone(&milestone); validationErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": validationErr.Error(),
+			})
+			return
+		}
+		var created bool
+		created, err = api.SaveReleaseMilestone(s.db, &milestone)
+		result = milestone
+		if created {
+			status = http.StatusCreated
+		}
+	case http.MethodDelete:
+		if id == 0 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "id is required",
+			})
+			return
+		}
+		err = api.DeleteReleaseMilestone(s.db, id)
+		result = map[string]interface{}{"id": id}
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	if errors.Is(err, api.ErrReleaseMilestoneNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error accessing release milestones in db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error accessing release milestones in db",
+		})
+		return
+	}
+	api.RespondWithJSON(status, w, result)
+}
+
+func (s *Server) jsonMilestoneComparison(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	milestone := req.URL.Query().Get("milestone")
+	if milestone == "" {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "milestone is required",
+		})
+		return
+	}
+	kind := req.URL.Query().Get("kind")
+	if kind != "" && kind != models.MilestonePassRateComponent && kind != models.MilestonePassRateVariant {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Sprintf("invalid kind %q: must be component or variant", kind),
+		})
+		return
+	}
+	_, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	result, err := api.CompareToMilestone(s.db, release, milestone, kind, end)
+	if errors.Is(err, api.ErrReleaseMilestoneNotFound) || errors.Is(err, api.ErrMilestoneSnapshotNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error comparing to release milestone")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error comparing to release milestone",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+func (s *Server) jsonReleaseTagsEvent(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release != "" {
+		filterOpts, err := filter.FilterOptionsFromRequest(req, "release_time", apitype.SortDescending)
+		if err != nil {
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+				"message": "couldn't parse filter opts " + err.Error()})
+			return
+		}
+
+		start, err := getISO8601Date("start", req)
+		if err != nil {
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+				"message": "couldn't parse start param" + err.Error()})
+			return
+		}
+
+		end, err := getISO8601Date("end", req)
+		if err != nil {
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+				"message": "couldn't parse start param" + err.Error()})
+			return
+		}
+
+		results, err := api.GetPayloadEvents(s.db, release, filterOpts, start, end)
+		if err != nil {
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+				"message": "couldn't parse start param" + err.Error()})
+			return
+		}
+
+		api.RespondWithJSON(http.StatusOK, w, results)
+	}
+}
+
+func (s *Server) jsonReleasePullRequestsReport(w http.ResponseWriter, req *http.Request) {
+	api.PrintPullRequestsReport(w, req, s.db)
+}
+
+func (s *Server) jsonListPayloadJobRuns(w http.ResponseWriter, req *http.Request) {
+	// Release appears optional here, perhaps when listing all job runs for all payloads
+	// in the release, but this may not make sense. Likely this API call should be
+	// moved away from filters and possible support for multiple payloads at once to
+	// URL encoded single payload.
+	release := req.URL.Query().Get("release")
+	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
+	if err != nil {
+		logger.WithError(err).Error("error")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+			"message": "Error building job run report:" + err.Error()})
+		return
+	}
+
+	payloadJobRuns, err := api.ListPayloadJobRuns(s.db, filterOpts, release)
+	if err != nil {
+		logger.WithError(err).Error("error listing payload job runs")
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+	}
+	api.RespondWithJSON(http.StatusOK, w, payloadJobRuns)
+}
+
+// TODO: may want to merge with jsonReleaseHealthReport, but this is a fair bit slower, and release health is run
+// on startup many times over when we calculate the metrics.
+// if we could boil the go logic for building this down into a query, it could become another matview and then
+// could be run quickly, assembling into the health api much more easily
+func (s *Server) jsonGetPayloadAnalysis(w http.ResponseWriter, req *http.Request) {
+	release := req.URL.Query().Get("release")
+	if release == "" {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Errorf(`"release" is required`),
+		})
+		return
+	}
+	stream := req.URL.Query().Get("stream")
+	if release == "" {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Errorf(`"stream" is required`),
+		})
+		return
+	}
+	arch := req.URL.Query().Get("arch")
+	if release == "" {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Errorf(`"arch" is required`),
+		})
+		return
+	}
+
+	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
+	if err != nil {
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": err.Error()})
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"release": release,
+		"stream":  stream,
+		"arch":    arch,
+	}).Info("analyzing payload stream")
+
+	result, err := api.GetPayloadStreamTestFailures(s.db, release, stream, arch, filterOpts, s.GetReportEnd())
+	if err != nil {
+		logger.WithError(err).Error("error")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+			"message": "Error analyzing payload: " + err.Error()})
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonGetPayloadTestFailures is an api to fetch information about what tests failed across all jobs in a specific
+// payload.
+func (s *Server) jsonGetPayloadTestFailures(w http.ResponseWriter, req *http.Request) {
+	payload := req.URL.Query().Get("payload")
+	if payload == "" {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Errorf(`"payload" is required`),
+		})
+		return
+	}
+
+	logger := logger.WithFields(log.Fields{
+		"payload": payload,
+	})
+	logger.Info("checking for test failures in payload")
+
+	result, err := api.GetPayloadTestFailures(s.db, payload, logger)
+	if err != nil {
+		logger.WithError(err).Error("error")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+			"message": "Error looking up test failures for payload: " + err.Error()})
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+func (s *Server) jsonReleaseHealthReport(w http.ResponseWriter, req *http.Request) {
+	release := req.URL.Query().Get("release")
+	if release == "" {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Errorf(`"release" is required`),
+		})
+		return
+	}
+
+	results, err := api.ReleaseHealthReports(s.db, release, s.GetReportEnd())
+	if err != nil {
+		logger.WithError(err).Error("error generating release health report")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonTestAnalysis(w http.ResponseWriter, req *http.Request, dbFN func(*db.DB, *filter.Filter, string, string, time.Time) (map[string][]api.CountByDate, error)) {
+	testName := req.URL.Query().Get("test")
+	if testName == "" {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "'test' is required.",
+		})
+		return
+	}
+	release := s.getReleaseOrFail(w, req)
+	if release != "" {
+		filters, err := filter.ExtractFilters(req)
+		if err != nil {
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+				"message": "couldn't parse filter opts " + err.Error()})
+			return
+		}
+		results, err := dbFN(s.db, filters, release, testName, s.GetReportEnd())
+		if err != nil {
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
+				"message": err.Error()})
+			return
+		}
+		api.RespondWithJSON(200, w, results)
+	}
+}
+
+func (s *Server) jsonTestAnalysisByJobFromDB(w http.ResponseWriter, req *http.Request) {
+	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByJobFromDB)
+}
+
+func (s *Server) jsonTestAnalysisByVariantFromDB(w http.ResponseWriter, req *http.Request) {
+	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByVariantFromDB)
+}
+
+func (s *Server) jsonTestAnalysisOverallFromDB(w http.ResponseWriter, req *http.Request) {
+	s.jsonTestAnalysis(w, req, api.GetTestAnalysisOverallFromDB)
+}
+
+func (s *Server) jsonPassRateTimeSeries(w http.ResponseWriter, req *http.Request) {
+	selector := apitype.TimeSeriesSelector{
+		Release:      req.URL.Query().Get("release"),
+		Job:          req.URL.Query().Get("job"),
+		Test:         req.URL.Query().Get("test"),
+		Variant:      req.URL.Query().Get("variant"),
+		BuildCluster: req.URL.Query().Get("build_cluster"),
+
+		ExcludeIncidents:    req.URL.Query().Get("exclude_incidents") == "true",
+		PrimaryFailuresOnly: req.URL.Query().Get("primary_failures_only") == "true",
+	}
+	mode, err := apitype.ParsePassRateMode(req.URL.Query().Get("pass_rate"))
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+	selector.PassRateMode = mode
+
+	granularity := apitype.TimeSeriesGranularity(req.URL.Query().Get("granularity"))
+	if granularity == "" {
+		granularity = apitype.TimeSeriesDay
+	}
+
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	if err := api.ValidateTimeSeriesRequest(selector, granularity, start, end); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	loc, ok := getTimezoneOrFail(w, req)
+	if !ok {
+		return
+	}
+
+	results, err := api.GetPassRateTimeSeries(s.db, selector, granularity, loc, start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying time series from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying time series from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonPassRateAnomalies(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	kind := req.URL.Query().Get("kind")
+	if kind == "" {
+		kind = api.AnomalyKindJob
+	}
+	if kind != api.AnomalyKindJob && kind != api.AnomalyKindComponent {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "kind must be job or component",
+		})
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := api.GetPassRateAnomalies(s.db, release, kind, start, end,
+		req.URL.Query().Get("exclude_incidents") == "true", anomaly.DefaultConfig())
+	if err != nil {
+		logger.WithError(err).Error("error detecting pass rate anomalies")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error detecting pass rate anomalies",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonStreamComparison(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	minRuns := api.DefaultStreamMinRuns
+	if minRunsParam := req.URL.Query().Get("min_runs"); minRunsParam != "" {
+		var err error
+		if minRuns, err = strconv.Atoi(minRunsParam); err != nil || minRuns < 1 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "min_runs must be a positive integer",
+			})
+			return
+		}
+	}
+
+	result, err := api.GetStreamComparison(s.db, release, start, end, minRuns)
+	if err != nil {
+		logger.WithError(err).Error("error comparing payload streams")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error comparing payload streams",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonVariantCoverageComparison reports the variant combinations a release's jobs gained or lost coverage of compared
+// with a base release.
+func (s *Server) jsonVariantCoverageComparison(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	dimensions := req.URL.Query()["dimension"]
+	if len(dimensions) == 0 {
+		dimensions = api.DefaultCoverageDimensions
+	}
+	baseRelease, err := api.ValidateVariantCoverageOptions(release, req.URL.Query().Get("base_release"), dimensions)
+	if err != nil {
+		respondBadRequest(w, err)
+		return
+	}
+
+	result, err := api.GetVariantCoverageComparison(s.db, release, baseRelease, dimensions, start, end)
+	if err != nil {
+		logger.WithError(err).Error("error comparing variant coverage")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error comparing variant coverage",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonReleaseTrend charts a test's or component's pass rate across the most recent releases, aligned by weeks to GA.
+func (s *Server) jsonReleaseTrend(w http.ResponseWriter, req *http.Request) {
+	params := map[string]int{
+		"releases":     api.DefaultReleaseTrendReleases,
+		"weeks_before": api.DefaultReleaseTrendWeeksBefore,
+		"weeks_after":  api.DefaultReleaseTrendWeeksAfter,
+	}
+	for name := range params {
+		if param := req.URL.Query().Get(name); param != "" {
+			value, err := strconv.Atoi(param)
+			if err != nil {
+				api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+					"code":    http.StatusBadRequest,
+					"message": fmt.Sprintf("%s must be an integer", name),
+				})
+				return
+			}
+			params[name] = value
+		}
+	}
+	test := req.URL.Query().Get("test")
+	component := req.URL.Query().Get("component")
+
+	if err := api.ValidateReleaseTrendRequest(test, component, params["releases"], params["weeks_before"], params["weeks_after"]); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	result, err := api.GetReleaseTrend(s.db, s.bigQueryClient, test, component,
+		params["releases"], params["weeks_before"], params["weeks_after"])
+	if err != nil {
+		logger.WithError(err).Error("error querying release trend")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying release trend",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonOwnerReport rolls up job health, flakes, open regressions and SLO compliance per Owner variant value.
+func (s *Server) jsonOwnerReport(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	slo := api.DefaultOwnerJobSLO
+	if sloParam := req.URL.Query().Get("slo"); sloParam != "" {
+		var err error
+		if slo, err = strconv.ParseFloat(sloParam, 64); err != nil || slo < 0 || slo > 100 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "slo must be a percentage between 0 and 100",
+			})
+			return
+		}
+	}
+	start, boundary, end, ok := getPeriodWindowOrFail(w, req, "default", s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	result, err := api.GetOwnerReport(s.db, s.views.ComponentReadiness, release, start, boundary, end, slo)
+	if err != nil {
+		logger.WithError(err).Error("error generating owner report")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error generating owner report",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonSecurityComplianceReport compares the jobs of each security profile, e.g. FIPS, with the default jobs.
+func (s *Server) jsonSecurityComplianceReport(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	minTestRuns := api.DefaultSecurityMinTestRuns
+	if minParam := req.URL.Query().Get("min_test_runs"); minParam != "" {
+		var err error
+		if minTestRuns, err = strconv.Atoi(minParam); err != nil || minTestRuns < 1 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "min_test_runs must be a positive integer",
+			})
+			return
+		}
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	result, err := api.GetSecurityComplianceReport(s.db, release, req.URL.Query().Get("profile"), start, end, minTestRuns)
+	if err != nil {
+		logger.WithError(err).Error("error generating security compliance report")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error generating security compliance report",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonFingerprintCorrelation compares job run, or test, failures across the values of job run fingerprint attributes.
+func (s *Server) jsonFingerprintCorrelation(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	minRuns := api.DefaultFingerprintMinRuns
+	if minParam := req.URL.Query().Get("min_runs"); minParam != "" {
+		var err error
+		if minRuns, err = strconv.Atoi(minParam); err != nil || minRuns < 1 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "min_runs must be a positive integer",
+			})
+			return
+		}
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	result, err := api.GetFingerprintCorrelation(s.db, release, req.URL.Query().Get("attribute"),
+		req.URL.Query().Get("test"), start, end, minRuns)
+	if err != nil {
+		logger.WithError(err).Error("error generating fingerprint correlation")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error generating fingerprint correlation",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonEndpointUsage summarizes how each API endpoint has been used, from the access logs.
+func (s *Server) jsonEndpointUsage(w http.ResponseWriter, req *http.Request) {
+	topParams := api.DefaultTopParams
+	if topParam := req.URL.Query().Get("top_params"); topParam != "" {
+		var err error
+		if topParams, err = strconv.Atoi(topParam); err != nil || topParams < 0 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "top_params must be a non-negative integer",
+			})
+			return
+		}
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, time.Now())
+	if !ok {
+		return
+	}
+
+	result, err := api.GetEndpointUsage(s.db, start, end, topParams)
+	if err != nil {
+		logger.WithError(err).Error("error querying endpoint usage")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying endpoint usage",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+func (s *Server) jsonVariantUsage(w http.ResponseWriter, req *http.Request) {
+	start, end, ok := getTimeWindowOrFail(w, req, time.Now())
+	if !ok {
+		return
+	}
+
+	result, err := api.GetVariantUsage(s.db, start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying variant usage")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying variant usage",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonPurgeJobs plans, and with a confirmation token carries out, the deletion of all data for the selected jobs.
+// It is only enabled when the server is started with --enable-job-purge-api.
+func (s *Server) jsonPurgeJobs(w http.ResponseWriter, req *http.Request) {
+	if !s.jobPurgeEnabled {
+		api.RespondWithJSON(http.StatusForbidden, w, map[string]interface{}{
+			"code":    http.StatusForbidden,
+			"message": "job purge API is disabled, start the server with --enable-job-purge-api",
+		})
+		return
+	}
+	if req.Method != http.MethodPost {
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	var body struct {
+		jobpurge.Selector
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Sprintf("error decoding purge json in request body: %s", err),
+		})
+		return
+	}
+	if err := body.Selector.Validate(); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	var plan *jobpurge.Plan
+	var err error
+	if body.Token == "" {
+		plan, err = jobpurge.NewPlan(req.Context(), s.db, s.bigQueryClient, body.Selector)
+	} else {
+		plan, err = jobpurge.Purge(req.Context(), s.db, s.bigQueryClient, body.Selector, body.Token)
+	}
+	if errors.Is(err, jobpurge.ErrTokenMismatch) {
+		api.RespondWithJSON(http.StatusConflict, w, map[string]interface{}{
+			"code":    http.StatusConflict,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error purging jobs")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": fmt.Sprintf("error purging jobs: %s", err),
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, plan)
+}
+
+// jsonIngestExternalJobRun records a job run posted by a CI system other than Prow. It is only enabled when the server
+// is started with --enable-external-job-runs-api.
+func (s *Server) jsonIngestExternalJobRun(w http.ResponseWriter, req *http.Request) {
+	if !s.externalRunsEnabled {
+		api.RespondWithJSON(http.StatusForbidden, w, map[string]interface{}{
+			"code":    http.StatusForbidden,
+			"message": "external job runs API is disabled, start the server with --enable-external-job-runs-api",
+		})
+		return
+	}
+	if req.Method != http.MethodPost {
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	var run externalci.JobRun
+	if err := json.NewDecoder(req.Body).Decode(&run); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Sprintf("error decoding job run json in request body: %s", err),
+		})
+		return
+	}
+	result, err := externalci.Ingest(req.Context(), s.db, &run)
+	respondIngestion(w, "external job run", result, err)
+}
+
+// jsonIngestManualTestResults records test results QE ran by hand, flagged with manual provenance. It is only enabled
+// when the server is started with --enable-manual-test-results-api.
+func (s *Server) jsonIngestManualTestResults(w http.ResponseWriter, req *http.Request) {
+	if !s.manualResultsEnabled {
+		api.RespondWithJSON(http.StatusForbidden, w, map[string]interface{}{
+			"code":    http.StatusForbidden,
+			"message": "manual test results API is disabled, start the server with --enable-manual-test-results-api",
+		})
+		return
+	}
+	if req.Method != http.MethodPost {
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	var run externalci.ManualRun
+	if err := json.NewDecoder(req.Body).Decode(&run); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Sprintf("error decoding manual test results json in request body: %s", err),
+		})
+		return
+	}
+	result, err := externalci.IngestManual(req.Context(), s.db, &run)
+	respondIngestion(w, "manual test results", result, err)
+}
+
+// respondIngestion responds with the result of ingesting a run, or the error ingesting it.
+func respondIngestion(w http.ResponseWriter, what string, result *externalci.Ingestion, err error) {
+	if errors.Is(err, externalci.ErrInvalidRun) {
+		respondBadRequest(w, err)
+		return
+	} else if errors.Is(err, externalci.ErrJobKind) || errors.Is(err, externalci.ErrDuplicateRun) {
+		api.RespondWithJSON(http.StatusConflict, w, map[string]interface{}{
+			"code":    http.StatusConflict,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Errorf("error ingesting %s", what)
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": fmt.Sprintf("error ingesting %s: %s", what, err),
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusCreated, w, result)
+}
+
+func (s *Server) jsonOperatorConditions(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := query.OperatorConditionSummaries(s.db, release, req.URL.Query().Get("variant"), start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying operator conditions from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying operator conditions from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+// jsonReleaseDefaults serves /api/releases/{release}/defaults, the default view configuration frontends use for a
+// release.
+func (s *Server) jsonReleaseDefaults(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/api/releases/")
+	release := strings.TrimSuffix(path, "/defaults")
+	if release == path || release == "" || strings.Contains(release, "/") {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": "not found, use /api/releases/{release}/defaults",
+		})
+		return
+	}
+
+	result, err := api.GetReleaseDefaults(s.db, s.views.ReleaseDefaults, release, s.GetReportEnd())
+	if err != nil {
+		logger.WithError(err).Error("error querying release defaults from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying release defaults from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonOperatorHealth serves /api/operators/{name}/health, combining an operator's install and upgrade test pass
+// rates, Degraded condition frequency and open regressions by variant.
+func (s *Server) jsonOperatorHealth(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/api/operators/")
+	operator := strings.TrimSuffix(path, "/health")
+	if operator == path || operator == "" || strings.Contains(operator, "/") {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": "not found, use /api/operators/{name}/health",
+		})
+		return
+	}
+	if err := api.ValidateOperatorName(operator); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	health, err := api.GetOperatorHealth(s.db, release, operator, start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying operator health from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying operator health from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, health)
+}
+
+func (s *Server) jsonAlertFiringReport(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := api.GetAlertFiringReport(s.db, release, req.URL.Query().Get("variant"), start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying alerts from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying alerts from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonEventPatterns(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := query.EventPatternSummaries(s.db, release,
+		req.URL.Query().Get("variant"),
+		req.URL.Query().Get("pattern"),
+		req.URL.Query().Get("namespace"),
+		start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying event patterns from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying event patterns from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonTestInteractions(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+	mode, err := apitype.ParsePassRateMode(req.URL.Query().Get("pass_rate"))
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	results, err := api.GetTestInteractions(s.db, release, req.URL.Query().Get("variant"), start, end, mode)
+	if err != nil {
+		logger.WithError(err).Error("error querying test interactions from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying test interactions from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+// jsonTestCapabilities maps capabilities and feature annotations to the tests covering them, with their current
+// results per variant combination. Repeated variant params narrow to combinations containing all of them.
+func (s *Server) jsonTestCapabilities(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	results, err := api.GetCapabilityCoverage(s.db, release, req.URL.Query().Get("capability"), req.URL.Query()["variant"])
+	if err != nil {
+		logger.WithError(err).Error("error querying test capabilities from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying test capabilities from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+// jsonImportProgress reports the progress of the most recent imports, so a slow import can be told apart from a
+// hung one.
+func (s *Server) jsonImportProgress(w http.ResponseWriter, req *http.Request) {
+	limit := 10
+	if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "limit must be a positive integer",
+			})
+			return
+		}
+	}
+
+	results, err := api.GetImportProgress(s.db, req.URL.Query().Get("loader"), limit, time.Now())
+	if err != nil {
+		logger.WithError(err).Error("error querying import progress from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying import progress from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+// jsonDeadLetters lists job runs skipped by imports after failing repeatedly with GET, and queues one to be retried
+// by the next import with POST ?prow_job_run_id=.
+func (s *Server) jsonDeadLetters(w http.ResponseWriter, req *http.Request) {
+	var result interface{}
+	var err error
+	switch req.Method {
+	case http.MethodGet:
+		result, err = api.ListDeadLetteredJobRuns(s.db, req.URL.Query().Get("job"))
+	case http.MethodPost:
+		id, parseErr := strconv.ParseUint(req.URL.Query().Get("prow_job_run_id"), 10, 64)
+		if parseErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "a valid prow_job_run_id is required",
+			})
+			return
+		}
+		err = api.RetryDeadLetteredJobRun(s.db, uint(id))
+		result = map[string]interface{}{"prow_job_run_id": id}
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	if errors.Is(err, api.ErrDeadLetterNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error accessing dead-lettered job runs in db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error accessing dead-lettered job runs in db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonVariantChurn reports how often variant keys changed value across syncs, per key and per job, since ?start=
+// (default 30 days ago). Changes are recorded when they happen, so this isn't relative to the report end.
+func (s *Server) jsonVariantChurn(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	since := time.Now().Add(-30 * 24 * time.Hour)
+	if start, err := api.ParseTimeParam("start", req); err != nil {
+		respondBadRequest(w, err)
+		return
+	} else if start != nil {
+		since = *start
+	}
+	limit := 100
+	if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "limit must be a positive integer",
+			})
+			return
+		}
+	}
+
+	keys, err := query.VariantKeyChurn(s.db, release, since)
+	if err != nil {
+		logger.WithError(err).Error("error querying variant churn from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying variant churn from db",
+		})
+		return
+	}
+	jobs, err := query.JobVariantChurn(s.db, release, req.URL.Query().Get("key"), since, limit)
+	if err != nil {
+		logger.WithError(err).Error("error querying job variant churn from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying job variant churn from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, apitype.VariantChurn{Keys: keys, Jobs: jobs})
+}
+
+// jsonVariantRules lists the active variant rules and their revision history on GET, and commits a new revision
+// on POST. Commits must include the rules, author, comment and the base_revision_id the change was previewed
+// against, and are rejected with a conflict if another revision was committed since.
+func (s *Server) jsonVariantRules(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		rules, err := api.GetVariantRules(s.db)
+		if err != nil {
+			logger.WithError(err).Error("error loading variant rules")
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+				"code":    http.StatusInternalServerError,
+				"message": "error loading variant rules",
+			})
+			return
+		}
+		api.RespondWithJSON(http.StatusOK, w, rules)
+	case http.MethodPost:
+		var revision models.VariantRulesRevision
+		if err := json.NewDecoder(req.Body).Decode(&revision); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding variant rules json in request body: %s", err),
+			})
+			return
+		}
+		proposed, err := api.ValidateVariantRulesRevision(&revision)
+		if err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
+			return
+		}
+
+		// Record how many jobs the change affects alongside the revision, the registry is only updated when
+		// expected variants are next generated and synced.
+		if s.bigQueryClient != nil {
+			preview, err := api.PreviewVariantRules(s.db, s.bigQueryClient, proposed)
+			if err != nil {
+				logger.WithError(err).Warning("error previewing committed variant rules")
+			} else {
+				revision.ChangedJobs = preview.ChangedJobs
+			}
+		}
+
+		err = api.CommitVariantRules(s.db, &revision)
+		if errors.Is(err, api.ErrVariantRulesConflict) {
+			api.RespondWithJSON(http.StatusConflict, w, map[string]interface{}{
+				"code":    http.StatusConflict,
+				"message": err.Error(),
+			})
+			return
+		} else if err != nil {
+			logger.WithError(err).Error("error committing variant rules")
+			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+				"code":    http.StatusInternalServerError,
+				"message": "error committing variant rules",
+			})
+			return
+		}
+		logger.WithFields(log.Fields{
+			"revision":     revision.ID,
+			"author":       revision.Author,
+			"changed_jobs": revision.ChangedJobs,
+		}).Info("committed variant rules")
+		api.RespondWithJSON(http.StatusCreated, w, revision)
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+	}
+}
+
+// jsonVariantRulesPreview dry-runs the rules in the POSTed body against every job in the variant registry, and
+// returns the variant changes committing them would cause.
+func (s *Server) jsonVariantRulesPreview(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+	if s.bigQueryClient == nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": "variant rules preview is only available when google-service-account-credential-file is configured",
+		})
+		return
+	}
+
+	var revision models.VariantRulesRevision
+	if err := json.NewDecoder(req.Body).Decode(&revision); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": fmt.Sprintf("error decoding variant rules json in request body: %s", err),
+		})
+		return
+	}
+	proposed, err := variantregistry.ParseReleaseDefaults([]byte(revision.Rules))
+	if err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	preview, err := api.PreviewVariantRules(s.db, s.bigQueryClient, proposed)
+	if err != nil {
+		logger.WithError(err).Error("error previewing variant rules")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": fmt.Sprintf("error previewing variant rules: %s", err),
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, preview)
+}
+
+// jsonVariantKeys lists every variant key with its distinct values and job counts, optionally for one release.
+func (s *Server) jsonVariantKeys(w http.ResponseWriter, req *http.Request) {
+	keys, err := api.GetVariantKeys(s.db, req.URL.Query().Get("release"))
+	if err != nil {
+		logger.WithError(err).Error("error querying variant keys from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying variant keys from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, keys)
+}
+
+func (s *Server) jsonJobRunDurations(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	groupBy := req.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = "job"
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := query.JobRunDurationPercentiles(s.db, release, groupBy, start, end)
+	if errors.Is(err, query.ErrUnknownDurationGroup) {
+		respondBadRequest(w, err)
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error querying job run durations from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying job run durations from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonRegressions(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	status := req.URL.Query().Get("status")
+	if err := api.ValidateRegressionStatus(status); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := api.ListRegressions(s.db, release, req.URL.Query().Get("component"), status, start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying regressions from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying regressions from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
+func (s *Server) jsonRegressionComponentStats(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := api.GetRegressionStatsByComponent(s.db, release, start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying regressions from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying regressions from db",
 		})
 		return
 	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
 
-	filterOpts, err := filter.FilterOptionsFromRequest(req, "id", apitype.SortDescending)
-	if err != nil {
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": err.Error()})
+func (s *Server) jsonComponentBugHealth(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	_, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
 		return
 	}
 
-	log.WithFields(log.Fields{
-		"release": release,
-		"stream":  stream,
-		"arch":    arch,
-	}).Info("analyzing payload stream")
-
-	result, err := api.GetPayloadStreamTestFailures(s.db, release, stream, arch, filterOpts, s.GetReportEnd())
+	results, err := api.GetComponentBugHealth(s.db, release, req.URL.Query().Get("component"), end)
 	if err != nil {
-		log.WithError(err).Error("error")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "Error analyzing payload: " + err.Error()})
+		logger.WithError(err).Error("error querying component bug health from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying component bug health from db",
+		})
 		return
 	}
-
-	api.RespondWithJSON(http.StatusOK, w, result)
+	api.RespondWithJSON(http.StatusOK, w, results)
 }
 
-// jsonGetPayloadTestFailures is an api to fetch information about what tests failed across all jobs in a specific
-// payload.
-func (s *Server) jsonGetPayloadTestFailures(w http.ResponseWriter, req *http.Request) {
-	payload := req.URL.Query().Get("payload")
-	if payload == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"payload" is required`),
-		})
+func (s *Server) jsonRegressionReport(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
 		return
 	}
 
-	logger := log.WithFields(log.Fields{
-		"payload": payload,
-	})
-	logger.Info("checking for test failures in payload")
+	limit := 20
+	if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit < 1 {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "limit must be a positive integer",
+			})
+			return
+		}
+	}
+	_, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
 
-	result, err := api.GetPayloadTestFailures(s.db, payload, logger)
+	result, err := api.GetRegressionReport(s.db, release, end, limit)
 	if err != nil {
-		log.WithError(err).Error("error")
-		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-			"message": "Error looking up test failures for payload: " + err.Error()})
+		logger.WithError(err).Error("error querying regressions from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying regressions from db",
+		})
 		return
 	}
-
 	api.RespondWithJSON(http.StatusOK, w, result)
 }
 
-func (s *Server) jsonReleaseHealthReport(w http.ResponseWriter, req *http.Request) {
-	release := req.URL.Query().Get("release")
+func (s *Server) jsonShadowEvaluations(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
 	if release == "" {
-		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
-			"code":    http.StatusBadRequest,
-			"message": fmt.Errorf(`"release" is required`),
-		})
 		return
 	}
 
-	results, err := api.ReleaseHealthReports(s.db, release, s.GetReportEnd())
+	algorithm := req.URL.Query().Get("algorithm")
+	if algorithm != "" {
+		if err := componentreadiness.ValidateShadowAlgorithms([]string{algorithm}); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := api.GetShadowComparisons(s.db, release, req.URL.Query().Get("view"), algorithm, start, end)
 	if err != nil {
-		log.WithError(err).Error("error generating release health report")
+		logger.WithError(err).Error("error querying shadow evaluations from db")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
-			"message": err.Error(),
+			"message": "error querying shadow evaluations from db",
 		})
 		return
 	}
-
 	api.RespondWithJSON(http.StatusOK, w, results)
 }
 
-func (s *Server) jsonTestAnalysis(w http.ResponseWriter, req *http.Request, dbFN func(*db.DB, *filter.Filter, string, string, time.Time) (map[string][]api.CountByDate, error)) {
-	testName := req.URL.Query().Get("test")
-	if testName == "" {
+func (s *Server) jsonRegressionBurndown(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	groupBy := req.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = api.RegressionGroupByComponent
+	}
+	granularity := apitype.TimeSeriesGranularity(req.URL.Query().Get("granularity"))
+	if granularity == "" {
+		granularity = apitype.TimeSeriesDay
+	}
+	if err := api.ValidateRegressionBurndownRequest(groupBy, granularity); err != nil {
 		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
 			"code":    http.StatusBadRequest,
-			"message": "'test' is required.",
+			"message": err.Error(),
 		})
 		return
 	}
-	release := s.getReleaseOrFail(w, req)
-	if release != "" {
-		filters, err := filter.ExtractFilters(req)
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": "couldn't parse filter opts " + err.Error()})
-			return
-		}
-		results, err := dbFN(s.db, filters, release, testName, s.GetReportEnd())
-		if err != nil {
-			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
-				"message": err.Error()})
-			return
-		}
-		api.RespondWithJSON(200, w, results)
-	}
-}
 
-func (s *Server) jsonTestAnalysisByJobFromDB(w http.ResponseWriter, req *http.Request) {
-	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByJobFromDB)
-}
+	// Without an explicit start, the burn-down covers the release cycle so far.
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+	if req.URL.Query().Get("start") == "" && req.URL.Query().Get("window") == "" {
+		start = time.Time{}
+		w.Header().Del(api.WindowStartHeader)
+	}
 
-func (s *Server) jsonTestAnalysisByVariantFromDB(w http.ResponseWriter, req *http.Request) {
-	s.jsonTestAnalysis(w, req, api.GetTestAnalysisByVariantFromDB)
-}
+	loc, ok := getTimezoneOrFail(w, req)
+	if !ok {
+		return
+	}
 
-func (s *Server) jsonTestAnalysisOverallFromDB(w http.ResponseWriter, req *http.Request) {
-	s.jsonTestAnalysis(w, req, api.GetTestAnalysisOverallFromDB)
+	result, err := api.GetRegressionBurndown(s.db, release, groupBy, granularity, loc, start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying regressions from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying regressions from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
 }
 
 func (s *Server) jsonTestBugsFromDB(w http.ResponseWriter, req *http.Request) {
@@ -528,7 +2422,7 @@ func (s *Server) jsonTestBugsFromDB(w http.ResponseWriter, req *http.Request) {
 			api.RespondWithJSON(http.StatusOK, w, []models.Bug{})
 			return
 		}
-		log.WithError(err).Error("error querying test bugs from db")
+		logger.WithError(err).Error("error querying test bugs from db")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying test bugs from db",
@@ -564,7 +2458,7 @@ func (s *Server) jsonTestDurationsFromDB(w http.ResponseWriter, req *http.Reques
 
 	outputs, err := api.GetTestDurationsFromDB(s.db, release, testName, filters)
 	if err != nil {
-		log.WithError(err).Error("error querying test outputs from db")
+		logger.WithError(err).Error("error querying test outputs from db")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying test outputs from db",
@@ -600,7 +2494,7 @@ func (s *Server) jsonTestOutputsFromDB(w http.ResponseWriter, req *http.Request)
 
 	outputs, err := api.GetTestOutputsFromDB(s.db, release, testName, filters, 10)
 	if err != nil {
-		log.WithError(err).Error("error querying test outputs from db")
+		logger.WithError(err).Error("error querying test outputs from db")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying test outputs from db",
@@ -610,6 +2504,104 @@ func (s *Server) jsonTestOutputsFromDB(w http.ResponseWriter, req *http.Request)
 	api.RespondWithJSON(http.StatusOK, w, outputs)
 }
 
+func (s *Server) jsonTestRecentRuns(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+
+	test := req.URL.Query().Get("test")
+	variants := req.URL.Query()["variant"]
+	limit := api.DefaultTestRecentRuns
+	if limitParam := req.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "limit must be a number",
+			})
+			return
+		}
+	}
+	if err := api.ValidateTestRecentRunsOptions(test, variants, limit); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	runs, err := api.GetTestRecentRuns(s.db, release, test, variants, limit, s.GetReportEnd())
+	if err != nil {
+		logger.WithError(err).Error("error querying recent test runs")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying recent test runs",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, runs)
+}
+
+func (s *Server) exportTestEvidence(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release == "" {
+		return
+	}
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	opts := api.TestExportOptions{
+		Release:           release,
+		TestRegex:         req.URL.Query().Get("test"),
+		Variants:          req.URL.Query()["variant"],
+		Start:             start,
+		End:               end,
+		Format:            req.URL.Query().Get("format"),
+		MinPassPercentage: api.DefaultExportMinPassPercentage,
+		ProjectID:         req.URL.Query().Get("project_id"),
+	}
+	if opts.Format == "" {
+		opts.Format = api.TestExportPolarion
+	}
+	if minPass := req.URL.Query().Get("min_pass_percentage"); minPass != "" {
+		var err error
+		if opts.MinPassPercentage, err = strconv.ParseFloat(minPass, 64); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "min_pass_percentage must be a number",
+			})
+			return
+		}
+	}
+	if err := api.ValidateTestExportOptions(opts); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+			"code":    http.StatusBadRequest,
+			"message": err.Error(),
+		})
+		return
+	}
+
+	// Buffered so a failed query can still be reported as JSON
+	var buf bytes.Buffer
+	if err := api.ExportTestEvidence(s.db, opts, &buf); err != nil {
+		logger.WithError(err).Error("error exporting test evidence")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error exporting test evidence",
+		})
+		return
+	}
+	contentType, extension := api.TestExportContentType(opts.Format)
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"sippy-%s-evidence.%s\"", release, extension))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
 func (s *Server) jsonComponentTestVariantsFromBigQuery(w http.ResponseWriter, req *http.Request) {
 	if s.bigQueryClient == nil {
 		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
@@ -620,9 +2612,9 @@ func (s *Server) jsonComponentTestVariantsFromBigQuery(w http.ResponseWriter, re
 	}
 	outputs, errs := componentreadiness.GetComponentTestVariantsFromBigQuery(s.bigQueryClient, s.gcsBucket)
 	if len(errs) > 0 {
-		log.Warningf("%d errors were encountered while querying test variants from big query:", len(errs))
+		logger.Warningf("%d errors were encountered while querying test variants from big query:", len(errs))
 		for _, err := range errs {
-			log.Error(err.Error())
+			logger.Error(err.Error())
 		}
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
@@ -643,9 +2635,9 @@ func (s *Server) jsonJobVariantsFromBigQuery(w http.ResponseWriter, req *http.Re
 	}
 	outputs, errs := componentreadiness.GetJobVariantsFromBigQuery(s.bigQueryClient, s.gcsBucket)
 	if len(errs) > 0 {
-		log.Warningf("%d errors were encountered while querying job variants from big query:", len(errs))
+		logger.Warningf("%d errors were encountered while querying job variants from big query:", len(errs))
 		for _, err := range errs {
-			log.Error(err.Error())
+			logger.Error(err.Error())
 		}
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
@@ -688,6 +2680,14 @@ func (s *Server) jsonComponentReadinessViews(w http.ResponseWriter, req *http.Re
 }
 
 func (s *Server) jsonComponentReportFromBigQuery(w http.ResponseWriter, req *http.Request) {
+	if s.enqueueReports {
+		s.respondFromReportQueue(w, req)
+		return
+	}
+	s.generateComponentReport(w, req)
+}
+
+func (s *Server) generateComponentReport(w http.ResponseWriter, req *http.Request) {
 	if s.bigQueryClient == nil {
 		err := fmt.Errorf("component report API is only available when google-service-account-credential-file is configured")
 		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
@@ -714,6 +2714,16 @@ func (s *Server) jsonComponentReportFromBigQuery(w http.ResponseWriter, req *htt
 		return
 	}
 
+	options.BasisPins, err = api.ComponentReportBasisPins(s.db, options.SampleRelease.Release)
+	if err != nil {
+		logger.WithError(err).Error("error fetching basis pins")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error fetching basis pins",
+		})
+		return
+	}
+
 	outputs, errs := componentreadiness.GetComponentReportFromBigQuery(
 		s.bigQueryClient,
 		s.prowURL,
@@ -721,9 +2731,27 @@ func (s *Server) jsonComponentReportFromBigQuery(w http.ResponseWriter, req *htt
 		options,
 	)
 	if len(errs) > 0 {
-		log.Warningf("%d errors were encountered while querying component from big query:", len(errs))
+		logger.Warningf("%d errors were encountered while querying component from big query:", len(errs))
+		for _, err := range errs {
+			logger.Error(err.Error())
+		}
 		for _, err := range errs {
-			log.Error(err.Error())
+			// the request itself was fine, but the report it asked for is too large to build in memory
+			if errors.Is(err, componentreadiness.ErrReportTooLarge) {
+				api.RespondWithJSON(http.StatusUnprocessableEntity, w, map[string]interface{}{
+					"code":    http.StatusUnprocessableEntity,
+					"message": err.Error(),
+				})
+				return
+			}
+			if errors.Is(err, componentreadiness.ErrReportMemoryUnavailable) {
+				w.Header().Set("Retry-After", strconv.Itoa(reportMemoryRetryAfterSeconds))
+				api.RespondWithJSON(http.StatusServiceUnavailable, w, map[string]interface{}{
+					"code":    http.StatusServiceUnavailable,
+					"message": err.Error(),
+				})
+				return
+			}
 		}
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
@@ -760,11 +2788,20 @@ func (s *Server) jsonComponentReportTestDetailsFromBigQuery(w http.ResponseWrite
 		})
 		return
 	}
+	reqOptions.BasisPins, err = api.ComponentReportBasisPins(s.db, reqOptions.SampleRelease.Release)
+	if err != nil {
+		logger.WithError(err).Error("error fetching basis pins")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error fetching basis pins",
+		})
+		return
+	}
 	outputs, errs := componentreadiness.GetTestDetails(s.bigQueryClient, s.prowURL, s.gcsBucket, reqOptions)
 	if len(errs) > 0 {
-		log.Warningf("%d errors were encountered while querying component test details from big query:", len(errs))
+		logger.Warningf("%d errors were encountered while querying component test details from big query:", len(errs))
 		for _, err := range errs {
-			log.Error(err.Error())
+			logger.Error(err.Error())
 		}
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
@@ -775,6 +2812,73 @@ func (s *Server) jsonComponentReportTestDetailsFromBigQuery(w http.ResponseWrite
 	api.RespondWithJSON(http.StatusOK, w, outputs)
 }
 
+// jsonBasisPins lists, creates and deletes component readiness basis pins. GET lists the pins for a release (or the
+// deleted pins when deleted is true), POST creates a pin, and DELETE soft deletes the pin with the given id.
+func (s *Server) jsonBasisPins(w http.ResponseWriter, req *http.Request) {
+	var result interface{}
+	var err error
+	status := http.StatusOK
+	switch req.Method {
+	case http.MethodGet:
+		if req.URL.Query().Get("deleted") == "true" {
+			result, err = api.ListDeletedBasisPins(s.db)
+		} else {
+			result, err = api.ListBasisPins(s.db, req.URL.Query().Get("release"))
+		}
+	case http.MethodPost:
+		var pin models.BasisPin
+		if err := json.NewDecoder(req.Body).Decode(&pin); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": fmt.Sprintf("error decoding basis pin json in request body: %s", err),
+			})
+			return
+		}
+		if err := api.ValidateBasisPin(&pin); err != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": err.Error(),
+			})
+			return
+		}
+		err = api.CreateBasisPin(s.db, &pin)
+		result, status = pin, http.StatusCreated
+	case http.MethodDelete:
+		id, parseErr := strconv.ParseUint(req.URL.Query().Get("id"), 10, 64)
+		if parseErr != nil {
+			api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
+				"code":    http.StatusBadRequest,
+				"message": "a valid id is required",
+			})
+			return
+		}
+		err = api.DeleteBasisPin(s.db, uint(id), getRequestUser(req))
+		result = map[string]interface{}{"id": id}
+	default:
+		api.RespondWithJSON(http.StatusMethodNotAllowed, w, map[string]interface{}{
+			"code":    http.StatusMethodNotAllowed,
+			"message": "method not allowed",
+		})
+		return
+	}
+
+	if errors.Is(err, api.ErrBasisPinNotFound) {
+		api.RespondWithJSON(http.StatusNotFound, w, map[string]interface{}{
+			"code":    http.StatusNotFound,
+			"message": err.Error(),
+		})
+		return
+	} else if err != nil {
+		logger.WithError(err).Error("error accessing basis pins in db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error accessing basis pins in db",
+		})
+		return
+	}
+	api.RespondWithJSON(status, w, result)
+}
+
 func (s *Server) jsonJobBugsFromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getRelease(req)
 
@@ -789,13 +2893,19 @@ func (s *Server) jsonJobBugsFromDB(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	start, boundary, end := getPeriodDates("default", req, s.GetReportEnd())
+	start, boundary, end, ok := getPeriodWindowOrFail(w, req, "default", s.GetReportEnd())
+	if !ok {
+		return
+	}
 	limit := getLimitParam(req)
-	sortField, sort := getSortParams(req)
+	sortField, sort, ok := getSortParamsOrFail(w, req)
+	if !ok {
+		return
+	}
 
 	jobIDs, err := query.ListFilteredJobIDs(s.db, release, jobFilter, start, boundary, end, limit, sortField, sort)
 	if err != nil {
-		log.WithError(err).Error("error querying jobs")
+		logger.WithError(err).Error("error querying jobs")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying jobs",
@@ -805,7 +2915,7 @@ func (s *Server) jsonJobBugsFromDB(w http.ResponseWriter, req *http.Request) {
 
 	bugs, err := query.LoadBugsForJobs(s.db, jobIDs, false)
 	if err != nil {
-		log.WithError(err).Error("error querying job bugs from db")
+		logger.WithError(err).Error("error querying job bugs from db")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying job bugs from db",
@@ -839,7 +2949,7 @@ func (s *Server) jsonReleasesReportFromDB(w http.ResponseWriter, _ *http.Request
 	}
 	releases, err := api.GetReleases(s.db, s.bigQueryClient)
 	if err != nil {
-		log.WithError(err).Error("error querying releases")
+		logger.WithError(err).Error("error querying releases")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying releases",
@@ -863,7 +2973,7 @@ func (s *Server) jsonReleasesReportFromDB(w http.ResponseWriter, _ *http.Request
 		// Assume our last update is the last time we inserted a prow job run.
 		res := s.db.DB.Raw("SELECT MAX(created_at) FROM prow_job_runs").Scan(&lastUpdated)
 		if res.Error != nil {
-			log.WithError(res.Error).Error("error querying last updated from db")
+			logger.WithError(res.Error).Error("error querying last updated from db")
 			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 				"code":    http.StatusInternalServerError,
 				"message": "error querying last updated from db",
@@ -885,11 +2995,14 @@ func (s *Server) jsonHealthReportFromDB(w http.ResponseWriter, req *http.Request
 }
 
 func (s *Server) jsonBuildClusterHealth(w http.ResponseWriter, req *http.Request) {
-	start, boundary, end := getPeriodDates("default", req, s.GetReportEnd())
+	start, boundary, end, ok := getPeriodWindowOrFail(w, req, "default", s.GetReportEnd())
+	if !ok {
+		return
+	}
 
 	results, err := api.GetBuildClusterHealthReport(s.db, start, boundary, end)
 	if err != nil {
-		log.WithError(err).Error("error querying build cluster health from db")
+		logger.WithError(err).Error("error querying build cluster health from db")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying build cluster health from db " + err.Error(),
@@ -908,7 +3021,7 @@ func (s *Server) jsonBuildClusterHealthAnalysis(w http.ResponseWriter, req *http
 
 	results, err := api.GetBuildClusterHealthAnalysis(s.db, period)
 	if err != nil {
-		log.WithError(err).Error("error querying build cluster health from db")
+		logger.WithError(err).Error("error querying build cluster health from db")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
 			"code":    http.StatusInternalServerError,
 			"message": "error querying build cluster health from db " + err.Error(),
@@ -919,6 +3032,25 @@ func (s *Server) jsonBuildClusterHealthAnalysis(w http.ResponseWriter, req *http
 	api.RespondWithJSON(200, w, results)
 }
 
+func (s *Server) jsonBuildClusterFailures(w http.ResponseWriter, req *http.Request) {
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+
+	results, err := api.GetBuildClusterFailures(s.db, req.URL.Query().Get("release"), req.URL.Query().Get("job"),
+		req.URL.Query().Get("test"), start, end)
+	if err != nil {
+		logger.WithError(err).Error("error querying build cluster failures from db")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error querying build cluster failures from db",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, results)
+}
+
 func (s *Server) getRelease(req *http.Request) string {
 	return req.URL.Query().Get("release")
 }
@@ -943,7 +3075,7 @@ func (s *Server) jsonJobsDetailsReportFromDB(w http.ResponseWriter, req *http.Re
 	if release != "" && jobName != "" {
 		err := api.PrintJobDetailsReportFromDB(w, req, s.db, release, jobName, s.GetReportEnd())
 		if err != nil {
-			log.Errorf("Error from PrintJobDetailsReportFromDB: %v", err)
+			logger.Errorf("Error from PrintJobDetailsReportFromDB: %v", err)
 		}
 	}
 }
@@ -970,10 +3102,17 @@ func (s *Server) jsonVariantsReportFromDB(w http.ResponseWriter, req *http.Reque
 	}
 }
 
-func (s *Server) jsonJobsReportFromDB(w http.ResponseWriter, req *http.Request) {
+func (s *Server) jsonJobsReportFromDB(w http.ResponseWriter, req *http.Request) {
+	release := s.getReleaseOrFail(w, req)
+	if release != "" {
+		api.PrintJobsReportFromDB(w, req, s.db, release, s.GetReportEnd())
+	}
+}
+
+func (s *Server) jsonJobsReportV2FromDB(w http.ResponseWriter, req *http.Request) {
 	release := s.getReleaseOrFail(w, req)
 	if release != "" {
-		api.PrintJobsReportFromDB(w, req, s.db, release, s.GetReportEnd())
+		api.PrintJobsReportV2FromDB(w, req, s.db, release, s.GetReportEnd())
 	}
 }
 
@@ -989,7 +3128,7 @@ func (s *Server) jsonRepositoriesReportFromDB(w http.ResponseWriter, req *http.R
 
 		results, err := api.GetRepositoriesReportFromDB(s.db, release, filterOpts, s.GetReportEnd())
 		if err != nil {
-			log.WithError(err).Error("error")
+			logger.WithError(err).Error("error")
 			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
 				"message": "Error fetching repositories " + err.Error()})
 			return
@@ -1011,7 +3150,7 @@ func (s *Server) jsonPullRequestsReportFromDB(w http.ResponseWriter, req *http.R
 
 		results, err := api.GetPullRequestsReportFromDB(s.db, release, filterOpts)
 		if err != nil {
-			log.WithError(err).Error("error")
+			logger.WithError(err).Error("error")
 			api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError,
 				"message": "Error fetching pull requests" + err.Error()})
 			return
@@ -1029,6 +3168,10 @@ func (s *Server) jsonJobRunsReportFromDB(w http.ResponseWriter, req *http.Reques
 		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": "Could not marshal query:" + err.Error()})
 		return
 	}
+	if err := filterOpts.Validate(apitype.JobRun{}); err != nil {
+		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{"code": http.StatusBadRequest, "message": err.Error()})
+		return
+	}
 
 	pagination, err := getPaginationParams(req)
 	if err != nil {
@@ -1045,6 +3188,52 @@ func (s *Server) jsonJobRunsReportFromDB(w http.ResponseWriter, req *http.Reques
 	api.RespondWithJSON(http.StatusOK, w, result)
 }
 
+// jsonJobRunSearch searches job runs by metadata, such as payload, pull request, build cluster, variants, result and
+// time range, returning the most recent matches.
+func (s *Server) jsonJobRunSearch(w http.ResponseWriter, req *http.Request) {
+	start, end, ok := getTimeWindowOrFail(w, req, s.GetReportEnd())
+	if !ok {
+		return
+	}
+	search, err := api.ParseJobRunSearch(req, start, end)
+	if err != nil {
+		respondBadRequest(w, err)
+		return
+	}
+
+	result, err := api.SearchJobRuns(s.db, search)
+	if err != nil {
+		logger.WithError(err).Error("error searching job runs")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error searching job runs",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
+// jsonSearch searches the names of tests, jobs, components, variants, regressions and incidents for the terms in
+// the q param, returning the closest matches of each kind.
+func (s *Server) jsonSearch(w http.ResponseWriter, req *http.Request) {
+	search, err := api.ParseSearch(req)
+	if err != nil {
+		respondBadRequest(w, err)
+		return
+	}
+
+	result, err := api.Search(s.db, search)
+	if err != nil {
+		logger.WithError(err).Error("error searching")
+		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{
+			"code":    http.StatusInternalServerError,
+			"message": "error searching",
+		})
+		return
+	}
+	api.RespondWithJSON(http.StatusOK, w, result)
+}
+
 // jsonJobRunRiskAnalysis is an API to make a guess at the severity of failures in a prow job run, based on historical
 // pass rates for each failed test, on-going incidents, and other factors.
 //
@@ -1057,7 +3246,7 @@ func (s *Server) jsonJobRunsReportFromDB(w http.ResponseWriter, req *http.Reques
 // data that is returned by the get by ID version.
 func (s *Server) jsonJobRunRiskAnalysis(w http.ResponseWriter, req *http.Request) {
 
-	logger := log.WithField("func", "jsonJobRunRiskAnalysis")
+	logger := logger.WithField("func", "jsonJobRunRiskAnalysis")
 
 	jobRun := &models.ProwJobRun{}
 	var jobRunTestCount int
@@ -1099,7 +3288,7 @@ func (s *Server) jsonJobRunRiskAnalysis(w http.ResponseWriter, req *http.Request
 		// we want to mark this as a high risk
 		if isValid, detailReason := isValidProwJobRun(jobRun); !isValid {
 
-			log.Warn("Invalid ProwJob provided for analysis, returning elevated risk")
+			logger.Warn("Invalid ProwJob provided for analysis, returning elevated risk")
 			result := apitype.ProwJobRunRiskAnalysis{
 				OverallRisk: apitype.JobFailureRisk{
 					Level:   apitype.FailureRiskLevelMissingData,
@@ -1148,7 +3337,7 @@ func (s *Server) jsonJobRunRiskAnalysis(w http.ResponseWriter, req *http.Request
 // This API is used by the job run intervals chart in the UI.
 func (s *Server) jsonJobRunIntervals(w http.ResponseWriter, req *http.Request) {
 
-	logger := log.WithField("func", "jsonJobRunIntervals")
+	logger := logger.WithField("func", "jsonJobRunIntervals")
 
 	if s.gcsClient == nil {
 		api.RespondWithJSON(http.StatusBadRequest, w, map[string]interface{}{
@@ -1245,9 +3434,15 @@ func (s *Server) jsonJobsAnalysisFromDB(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	start, boundary, end := getPeriodDates("default", req, s.GetReportEnd())
+	start, boundary, end, ok := getPeriodWindowOrFail(w, req, "default", s.GetReportEnd())
+	if !ok {
+		return
+	}
 	limit := getLimitParam(req)
-	sortField, sort := getSortParams(req)
+	sortField, sort, ok := getSortParamsOrFail(w, req)
+	if !ok {
+		return
+	}
 
 	period := req.URL.Query().Get("period")
 	if period == "" {
@@ -1257,7 +3452,7 @@ func (s *Server) jsonJobsAnalysisFromDB(w http.ResponseWriter, req *http.Request
 	results, err := api.PrintJobAnalysisJSONFromDB(s.db, release, jobFilter, jobRunsFilter,
 		start, boundary, end, limit, sortField, sort, period, s.GetReportEnd())
 	if err != nil {
-		log.WithError(err).Error("error in PrintJobAnalysisJSONFromDB")
+		logger.WithError(err).Error("error in PrintJobAnalysisJSONFromDB")
 		api.RespondWithJSON(http.StatusInternalServerError, w, map[string]interface{}{"code": http.StatusInternalServerError, "message": err.Error()})
 		return
 	}
@@ -1275,8 +3470,69 @@ func (s *Server) requireCapabilities(capabilities []string, implFn func(w http.R
 	}
 }
 
+// writeGated rejects requests changing records through an endpoint unless the server was started with
+// --enable-write-api, and they come from a user authenticated by the proxy in front of sippy. Reads are always
+// allowed.
+func (s *Server) writeGated(implFn func(w http.ResponseWriter, req *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			implFn(w, req)
+			return
+		}
+		if !s.writeAPIEnabled {
+			api.RespondWithJSON(http.StatusForbidden, w, map[string]interface{}{
+				"code":    http.StatusForbidden,
+				"message": "write API is disabled, start the server with --enable-write-api",
+			})
+			return
+		}
+		if getRequestUser(req) == "" {
+			api.RespondWithJSON(http.StatusUnauthorized, w, map[string]interface{}{
+				"code":    http.StatusUnauthorized,
+				"message": "changes require a user authenticated by the proxy in front of sippy",
+			})
+			return
+		}
+		implFn(w, req)
+	}
+}
+
+// adminGated wraps implFn so that anything but reads requires the request user to be one of the configured admins.
+func (s *Server) adminGated(implFn func(w http.ResponseWriter, req *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			implFn(w, req)
+			return
+		}
+		if s.adminUsers.Len() == 0 {
+			api.RespondWithJSON(http.StatusForbidden, w, map[string]interface{}{
+				"code":    http.StatusForbidden,
+				"message": "admin API is disabled, start the server with --admin-user",
+			})
+			return
+		}
+		user := getRequestUser(req)
+		if user == "" {
+			api.RespondWithJSON(http.StatusUnauthorized, w, map[string]interface{}{
+				"code":    http.StatusUnauthorized,
+				"message": "changes require a user authenticated by the proxy in front of sippy",
+			})
+			return
+		}
+		if !s.adminUsers.Has(user) {
+			api.RespondWithJSON(http.StatusForbidden, w, map[string]interface{}{
+				"code":    http.StatusForbidden,
+				"message": fmt.Sprintf("%s is not an admin", user),
+			})
+			return
+		}
+		implFn(w, req)
+	}
+}
+
 func (s *Server) Serve() {
 	s.determineCapabilities()
+	s.startFeatureFlagRefresh()
 
 	// Use private ServeMux to prevent tests from stomping on http.DefaultServeMux
 	serveMux := http.NewServeMux()
@@ -1292,7 +3548,7 @@ func (s *Server) Serve() {
 					w.WriteHeader(http.StatusNotFound)
 					w.Header().Set("Content-Type", "text/plain")
 					if _, err := w.Write([]byte(fmt.Sprintf("404 Not Found: %s", fullPath))); err != nil {
-						log.WithError(err).Warningf("could not write response")
+						logger.WithError(err).Warningf("could not write response")
 					}
 					return
 				}
@@ -1304,6 +3560,10 @@ func (s *Server) Serve() {
 
 	serveMux.Handle("/static/", http.FileServer(http.FS(s.static)))
 
+	// Kubernetes probes and external monitors
+	serveMux.HandleFunc("/livez", s.livez)
+	serveMux.HandleFunc("/readyz", s.readyz)
+
 	// Re-direct "/" to sippy-ng
 	serveMux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
 		if req.URL.Path != "/" {
@@ -1319,6 +3579,17 @@ func (s *Server) Serve() {
 		Capabilities []string                                     `json:"required_capabilities"`
 		CacheTime    time.Duration                                `json:"cache_time"`
 		HandlerFunc  func(w http.ResponseWriter, r *http.Request) `json:"-"`
+		// Sunset is set for deprecated endpoints, and is when they may be removed in favor of Successor.
+		Sunset    *time.Time `json:"sunset,omitempty"`
+		Successor string     `json:"successor,omitempty"`
+
+		// AuditCategory is set for endpoints people change records through, whose successful changes are recorded
+		// in the audit log under it.
+		AuditCategory string `json:"audit_category,omitempty"`
+		// WriteGated is set for endpoints whose changes require the write API to be enabled, see writeGated.
+		WriteGated bool `json:"write_gated,omitempty"`
+		// AdminGated is set for endpoints whose changes only admins may make, see adminGated.
+		AdminGated bool `json:"admin_gated,omitempty"`
 	}
 
 	var endpoints []apiEndpoints
@@ -1336,6 +3607,12 @@ func (s *Server) Serve() {
 				api.RespondWithJSON(http.StatusOK, w, availableEndpoints)
 			},
 		},
+		{
+			EndpointPath: "/api/search",
+			Description:  "Searches tests, jobs, components, variants, regressions and incidents by name, grouped by kind",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonSearch,
+		},
 		{
 			EndpointPath: "/api/autocomplete/",
 			Description:  "Autocompletes queries from database",
@@ -1347,6 +3624,14 @@ func (s *Server) Serve() {
 			Description:  "Returns a list of jobs",
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonJobsReportFromDB,
+			Sunset:       &jobsV1Sunset,
+			Successor:    "/api/v2/jobs",
+		},
+		{
+			EndpointPath: "/api/v2/jobs",
+			Description:  "Returns a list of jobs, with variants keyed by variant name",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonJobsReportV2FromDB,
 		},
 		{
 			EndpointPath: "/api/jobs/runs",
@@ -1360,6 +3645,12 @@ func (s *Server) Serve() {
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonJobRunRiskAnalysis,
 		},
+		{
+			EndpointPath: "/api/jobs/runs/search",
+			Description:  "Searches job runs by payload, pull request, build cluster, variants, result and time range",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonJobRunSearch,
+		},
 		{
 			EndpointPath: "/api/jobs/runs/intervals",
 			Description:  "Reports intervals of job runs",
@@ -1379,6 +3670,13 @@ func (s *Server) Serve() {
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonJobsDetailsReportFromDB,
 		},
+		{
+			EndpointPath: "/api/jobs/durations",
+			Description:  "Reports job run duration percentiles by job or variant",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonJobRunDurations,
+		},
 		{
 			EndpointPath: "/api/jobs/bugs",
 			Description:  "Reports bugs related to jobs",
@@ -1444,6 +3742,12 @@ func (s *Server) Serve() {
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonTestBugsFromDB,
 		},
+		{
+			EndpointPath: "/api/tests/export",
+			Description:  "Exports pass/fail evidence for tests by variant for test case management systems",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.exportTestEvidence,
+		},
 		{
 			EndpointPath: "/api/tests/outputs",
 			Description:  "Outputs of tests",
@@ -1451,6 +3755,13 @@ func (s *Server) Serve() {
 			CacheTime:    1 * time.Hour,
 			HandlerFunc:  s.jsonTestOutputsFromDB,
 		},
+		{
+			EndpointPath: "/api/tests/recent_runs",
+			Description:  "Returns a test's results in its most recent job runs",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    5 * time.Minute,
+			HandlerFunc:  s.jsonTestRecentRuns,
+		},
 		{
 			EndpointPath: "/api/tests/durations",
 			Description:  "Durations of tests",
@@ -1458,6 +3769,63 @@ func (s *Server) Serve() {
 			CacheTime:    1 * time.Hour,
 			HandlerFunc:  s.jsonTestDurationsFromDB,
 		},
+		{
+			EndpointPath: "/api/tests/interactions",
+			Description:  "Reports pairs of tests whose failures are correlated within job runs",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    4 * time.Hour,
+			HandlerFunc:  s.jsonTestInteractions,
+		},
+		{
+			EndpointPath: "/api/tests/capabilities",
+			Description:  "Maps capabilities and feature annotations to the tests covering them and their current health by variant",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonTestCapabilities,
+		},
+		{
+			EndpointPath: "/api/timeseries",
+			Description:  "Reports pass/fail/flake counts over time for a job, test, or variant",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonPassRateTimeSeries,
+		},
+		{
+			EndpointPath: "/api/anomalies",
+			Description:  "Reports days where job or component pass rates dropped significantly below their baseline",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonPassRateAnomalies,
+		},
+		{
+			EndpointPath: "/api/operators/conditions",
+			Description:  "Reports how often cluster operators go Degraded or Unavailable by variant",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonOperatorConditions,
+		},
+		{
+			// Serves /api/operators/{name}/health, more specific /api/operators/ paths take precedence.
+			EndpointPath: "/api/operators/",
+			Description:  "Reports an operator's install and upgrade pass rates, Degraded frequency and open regressions by variant",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonOperatorHealth,
+		},
+		{
+			EndpointPath: "/api/alerts",
+			Description:  "Reports how often alerts fire by variant, compared to the previous release",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonAlertFiringReport,
+		},
+		{
+			EndpointPath: "/api/events/patterns",
+			Description:  "Reports abnormal event patterns such as crashloops and OOMKills by namespace and variant over time",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonEventPatterns,
+		},
 		{
 			EndpointPath: "/api/install",
 			Description:  "Reports on installations",
@@ -1478,6 +3846,14 @@ func (s *Server) Serve() {
 			Capabilities: []string{},
 			HandlerFunc:  s.jsonReleasesReportFromDB,
 		},
+		{
+			// Serves /api/releases/{release}/defaults, more specific /api/releases/ paths take precedence.
+			EndpointPath: "/api/releases/",
+			Description:  "Returns the default variants, blocking jobs and date range frontends use for a release",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonReleaseDefaults,
+		},
 		{
 			EndpointPath: "/api/health/build_cluster/analysis",
 			Description:  "Analyzes build cluster health",
@@ -1490,6 +3866,12 @@ func (s *Server) Serve() {
 			Capabilities: []string{LocalDBCapability, BuildClusterCapability},
 			HandlerFunc:  s.jsonBuildClusterHealth,
 		},
+		{
+			EndpointPath: "/api/health/build_cluster/failures",
+			Description:  "Compares failure rates of runs on each build cluster to the other clusters",
+			Capabilities: []string{LocalDBCapability, BuildClusterCapability},
+			HandlerFunc:  s.jsonBuildClusterFailures,
+		},
 		{
 			EndpointPath: "/api/health",
 			Description:  "Reports general health from DB",
@@ -1502,6 +3884,13 @@ func (s *Server) Serve() {
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonVariantsReportFromDB,
 		},
+		{
+			EndpointPath: "/api/variants/keys",
+			Description:  "Lists variant keys with their distinct values and job counts",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonVariantKeys,
+		},
 		{
 			EndpointPath: "/api/canary",
 			Description:  "Displays canary report from database",
@@ -1525,6 +3914,20 @@ func (s *Server) Serve() {
 			Capabilities: []string{ComponentReadinessCapability},
 			HandlerFunc:  s.jsonComponentReportTestDetailsFromBigQuery,
 		},
+		{
+			EndpointPath:  "/api/component_readiness/basis_pins",
+			Description:   "Create, delete, and list pinned component readiness bases for specific tests or components",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonBasisPins,
+			AuditCategory: "basis_pin",
+		},
+		{
+			EndpointPath:  "/api/component_readiness/basis_pins/restore",
+			Description:   "Restore a deleted component readiness basis pin",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonBasisPinRestore,
+			AuditCategory: "basis_pin",
+		},
 		{
 			EndpointPath: "/api/component_readiness/variants",
 			Description:  "Reports test variants for component readiness from BigQuery",
@@ -1537,6 +3940,43 @@ func (s *Server) Serve() {
 			Capabilities: []string{ComponentReadinessCapability},
 			HandlerFunc:  s.jsonComponentReadinessViews,
 		},
+		{
+			EndpointPath: "/api/component_readiness/regressions",
+			Description:  "Lists tracked component readiness regressions with their age or time to resolution",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonRegressions,
+		},
+		{
+			EndpointPath: "/api/component_readiness/regressions/components",
+			Description:  "Reports open regressions and time to resolution by component",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonRegressionComponentStats,
+		},
+		{
+			EndpointPath: "/api/component_readiness/regressions/report",
+			Description:  "Reports regressions opened and resolved in the past week, and the oldest open regressions",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonRegressionReport,
+		},
+		{
+			EndpointPath: "/api/component_readiness/regressions/burndown",
+			Description:  "Reports open regressions over the release cycle and mean time to resolution, by component or team",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonRegressionBurndown,
+		},
+		{
+			EndpointPath: "/api/component_readiness/bug_health",
+			Description:  "Reports each component's open JIRA bug backlog alongside its open regressions",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonComponentBugHealth,
+		},
+		{
+			EndpointPath: "/api/component_readiness/shadow_evaluations",
+			Description:  "Compares candidate regression detection algorithms run in shadow mode with the current one",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonShadowEvaluations,
+		},
 		{
 			EndpointPath: "/api/capabilities",
 			Description:  "Lists available API capabilities",
@@ -1573,18 +4013,203 @@ func (s *Server) Serve() {
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonListPayloadJobRuns,
 		},
+		{
+			EndpointPath: "/api/load/progress",
+			Description:  "Reports the progress of running and recent imports by phase",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonImportProgress,
+		},
+		{
+			EndpointPath: "/api/load/dead_letters",
+			Description:  "Lists and retries job runs skipped by imports after failing repeatedly",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonDeadLetters,
+		},
+		{
+			EndpointPath: "/api/jobs/variant_churn",
+			Description:  "Reports how often variant keys change value across syncs, per key and per job",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonVariantChurn,
+		},
+		{
+			EndpointPath:  "/api/jobs/variant_rules",
+			Description:   "Lists and commits revisions of the declarative variant rules",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonVariantRules,
+			AuditCategory: "variant_rules",
+		},
+		{
+			EndpointPath: "/api/jobs/variant_rules/preview",
+			Description:  "Previews the job variant changes proposed variant rules would cause",
+			Capabilities: []string{LocalDBCapability, ComponentReadinessCapability},
+			HandlerFunc:  s.jsonVariantRulesPreview,
+		},
 		{
 			EndpointPath: "/api/incidents",
 			Description:  "Reports incident events",
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonIncidentEvent,
 		},
+		{
+			EndpointPath:  "/api/incidents/timeline",
+			Description:   "Create, update, delete, and list incidents used to explain and exclude known outages",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonIncidents,
+			AuditCategory: "incident",
+			WriteGated:    true,
+		},
+		{
+			EndpointPath:  "/api/incidents/timeline/restore",
+			Description:   "Restore a deleted incident",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonIncidentRestore,
+			AuditCategory: "incident",
+			WriteGated:    true,
+		},
+		{
+			EndpointPath:  "/api/watchlist/subscriptions",
+			Description:   "Create, update, delete, and list subscriptions notifying of test or job pass rate threshold crossings and regressions",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonWatchSubscriptions,
+			AuditCategory: "watch_subscription",
+			WriteGated:    true,
+		},
+		{
+			EndpointPath:  "/api/lanes",
+			Description:   "Create, update, delete, and list lanes grouping the jobs of one configuration across releases and architectures",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonLanes,
+			AuditCategory: "lane",
+			WriteGated:    true,
+		},
+		{
+			EndpointPath: "/api/lanes/report",
+			Description:  "Reports the pass rate of lanes and their jobs",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonLaneReport,
+		},
+		{
+			EndpointPath:  "/api/dashboards",
+			Description:   "Lists, stores, and deletes declarative dashboard definitions rendered by the frontend",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonDashboards,
+			AuditCategory: "dashboard",
+			WriteGated:    true,
+		},
 		{
 			EndpointPath: "/api/releases/test_failures",
 			Description:  "Analysis of test failures for releases",
 			Capabilities: []string{LocalDBCapability},
 			HandlerFunc:  s.jsonGetPayloadAnalysis,
 		},
+		{
+			EndpointPath: "/api/releases/milestones",
+			Description:  "Lists, defines and deletes release milestones at which pass rates are snapshotted",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonReleaseMilestones,
+		},
+		{
+			EndpointPath: "/api/releases/milestones/compare",
+			Description:  "Compares a release's current component and variant pass rates with a milestone snapshot",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonMilestoneComparison,
+		},
+		{
+			EndpointPath: "/api/owners/report",
+			Description:  "Rolls up job health, flakes, open regressions and SLO compliance per Owner variant",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonOwnerReport,
+		},
+		{
+			EndpointPath: "/api/security/compliance",
+			Description:  "Compares FIPS and other security profile jobs with the default jobs, including coverage gaps",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonSecurityComplianceReport,
+		},
+		{
+			EndpointPath: "/api/jobs/runs/fingerprint_correlation",
+			Description:  "Correlates job run or test failures with job run environment fingerprints, e.g. cloud region or instance type",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonFingerprintCorrelation,
+		},
+		{
+			EndpointPath: "/api/releases/trend",
+			Description:  "Charts a test's or component's pass rate across recent releases, aligned by weeks to GA",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    4 * time.Hour,
+			HandlerFunc:  s.jsonReleaseTrend,
+		},
+		{
+			EndpointPath: "/api/releases/stream_comparison",
+			Description:  "Compares the pass rates of a release's nightly and ci payload stream jobs and tests",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonStreamComparison,
+		},
+		{
+			EndpointPath: "/api/releases/variant_coverage/compare",
+			Description:  "Compares the variant combinations a release's jobs ran with to those of a base release",
+			Capabilities: []string{LocalDBCapability},
+			CacheTime:    1 * time.Hour,
+			HandlerFunc:  s.jsonVariantCoverageComparison,
+		},
+		{
+			EndpointPath: "/api/access_logs/usage",
+			Description:  "Summarizes how each API endpoint has been used, from the access logs",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonEndpointUsage,
+		},
+		{
+			EndpointPath: "/api/access_logs/variants",
+			Description:  "Summarizes which variant keys and values API requests filtered or grouped by, from the access logs",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonVariantUsage,
+		},
+		{
+			EndpointPath: "/api/jobs/runs/external",
+			Description:  "Ingests a job run from a CI system other than Prow, i.e. Jenkins or GitLab",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonIngestExternalJobRun,
+		},
+		{
+			EndpointPath:  "/api/jobs/runs/manual",
+			Description:   "Records test results QE ran by hand, flagged with manual provenance",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonIngestManualTestResults,
+			AuditCategory: "manual_results",
+		},
+		{
+			EndpointPath:  "/api/jobs/purge",
+			Description:   "Plans, and with a confirmation token carries out, deleting all data for the selected jobs",
+			Capabilities:  []string{LocalDBCapability},
+			HandlerFunc:   s.jsonPurgeJobs,
+			AuditCategory: "job_purge",
+		},
+		{
+			EndpointPath:  "/api/feature_flags",
+			Description:   "Lists feature flags and sets or removes runtime overrides of them",
+			HandlerFunc:   s.jsonFeatureFlags,
+			AuditCategory: "feature_flag",
+			AdminGated:    true,
+		},
+		{
+			EndpointPath:  "/api/admin/log_levels",
+			Description:   "Lists the log levels of subsystems and overrides or resets them at runtime",
+			HandlerFunc:   s.jsonLogLevels,
+			AuditCategory: "log_level",
+			AdminGated:    true,
+		},
+		{
+			EndpointPath: "/api/report_jobs",
+			Description:  "Lists report generations queued for report workers",
+			Capabilities: []string{LocalDBCapability},
+			HandlerFunc:  s.jsonReportJobs,
+		},
 		{
 			EndpointPath: "/api/payloads/test_failures",
 			Description:  "Analysis of test failures in payloads",
@@ -1593,20 +4218,38 @@ func (s *Server) Serve() {
 		},
 	}
 
+	cachedEndpoints := make(map[string]bool, len(endpoints))
 	for _, ep := range endpoints {
+		cachedEndpoints[ep.EndpointPath] = ep.CacheTime > 0
 		fn := ep.HandlerFunc
+		if ep.AuditCategory != "" {
+			fn = s.audited(ep.AuditCategory, fn)
+		}
 		if ep.CacheTime > 0 {
 			fn = s.cached(ep.CacheTime, fn)
 		}
+		if ep.WriteGated {
+			fn = s.writeGated(fn)
+		}
+		if ep.AdminGated {
+			fn = s.adminGated(fn)
+		}
 		if len(ep.Capabilities) > 0 {
 			fn = s.requireCapabilities(ep.Capabilities, fn)
 		}
+		for _, capability := range ep.Capabilities {
+			if capability == LocalDBCapability {
+				fn = s.withDatabaseHeaders(fn)
+			}
+		}
+		fn = s.withFeatureFlags(fn)
+		fn = versioned(ep.EndpointPath, ep.Sunset, ep.Successor, fn)
 		serveMux.HandleFunc(ep.EndpointPath, fn)
 	}
 
 	var handler http.Handler = serveMux
 	// wrap mux with our logger. this will
-	handler = logRequestHandler(handler)
+	handler = s.logRequestHandler(handler, cachedEndpoints)
 	// ... potentially add more middleware handlers
 
 	// Store a pointer to the HTTP server for later retrieval.
@@ -1616,50 +4259,47 @@ func (s *Server) Serve() {
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
-	log.Infof("Serving reports on %s ", s.listenAddr)
+	logger.Infof("Serving reports on %s ", s.listenAddr)
 
 	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
-		log.WithError(err).Error("Server exited")
-	}
-}
-
-func logRequestHandler(h http.Handler) http.Handler {
-	fn := func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		h.ServeHTTP(w, r)
-		log.WithFields(log.Fields{
-			"uri":     r.URL.String(),
-			"method":  r.Method,
-			"elapsed": time.Since(start),
-		}).Info("responded to request")
+		logger.WithError(err).Error("Server exited")
 	}
-	return http.HandlerFunc(fn)
 }
 
 func (s *Server) cached(duration time.Duration, handler func(w http.ResponseWriter, r *http.Request)) func(http.ResponseWriter, *http.Request) {
 	if s.cache == nil {
-		log.Debugf("no cache configured, making live api call")
+		logger.Debugf("no cache configured, making live api call")
 		return handler
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		content, err := s.cache.Get(r.RequestURI)
+		key := cacheKey(r)
+		content, err := s.cache.Get(key)
 		if err != nil { // cache miss
-			log.WithError(err).Debugf("cache miss: could not fetch data from cache for %q", r.RequestURI)
+			logger.WithError(err).Debugf("cache miss: could not fetch data from cache for %q", key)
 		} else if content != nil && respondFromCache(content, w, r) == nil { // cache hit
 			return
 		}
-		recordResponse(s.cache, duration, w, r, handler)
+		recordResponse(s.cache, key, duration, w, r, handler)
+	}
+}
+
+// cacheKey is the request URI, plus the feature flags enabled for the request so that responses
+// computed with and without a flag are cached separately.
+func cacheKey(r *http.Request) string {
+	if features := featureflags.FromContext(r.Context()).String(); features != "" {
+		return r.RequestURI + "#" + featureflags.QueryParam + "=" + features
 	}
+	return r.RequestURI
 }
 
 func respondFromCache(content []byte, w http.ResponseWriter, r *http.Request) error {
 	apiResponse := cache.APIResponse{}
 	if err := json.Unmarshal(content, &apiResponse); err != nil {
-		log.WithError(err).Warningf("couldn't unmarshal api response")
+		logger.WithError(err).Warningf("couldn't unmarshal api response")
 		return err
 	}
-	log.Debugf("cache hit for %q", r.RequestURI)
+	logger.Debugf("cache hit for %q", r.RequestURI)
 	for k, v := range apiResponse.Headers {
 		w.Header()[k] = v
 	}
@@ -1667,14 +4307,14 @@ func respondFromCache(content []byte, w http.ResponseWriter, r *http.Request) er
 	w.WriteHeader(http.StatusOK)
 
 	if _, err := w.Write(apiResponse.Response); err != nil {
-		log.WithError(err).Debugf("error writing http response")
+		logger.WithError(err).Debugf("error writing http response")
 		return err
 	}
 
 	return nil
 }
 
-func recordResponse(c cache.Cache, duration time.Duration, w http.ResponseWriter, r *http.Request, handler func(w http.ResponseWriter, r *http.Request)) {
+func recordResponse(c cache.Cache, key string, duration time.Duration, w http.ResponseWriter, r *http.Request, handler func(w http.ResponseWriter, r *http.Request)) {
 	apiResponse := cache.APIResponse{}
 	recorder := httptest.NewRecorder()
 	handler(recorder, r)
@@ -1686,20 +4326,57 @@ func recordResponse(c cache.Cache, duration time.Duration, w http.ResponseWriter
 	content := recorder.Body.Bytes()
 	apiResponse.Response = content
 
-	log.Debugf("caching new page: %s for %s\n", r.RequestURI, duration)
+	logger.Debugf("caching new page: %s for %s\n", key, duration)
 	apiResponseBytes, err := json.Marshal(apiResponse)
 	if err != nil {
-		log.WithError(err).Warningf("couldn't marshal api response")
+		logger.WithError(err).Warningf("couldn't marshal api response")
 	}
 
-	if err := c.Set(r.RequestURI, apiResponseBytes, duration); err != nil {
-		log.WithError(err).Warningf("could not cache page")
+	if err := c.Set(key, apiResponseBytes, duration); err != nil {
+		logger.WithError(err).Warningf("could not cache page")
 	}
 	if _, err := w.Write(content); err != nil {
-		log.WithError(err).Debugf("error writing http response")
+		logger.WithError(err).Debugf("error writing http response")
+	}
+}
+
+// withFeatureFlags evaluates the feature flags for a request, making them available to handlers through
+// featureflags.Enabled(req.Context(), ...) and listing the enabled ones in a response header.
+func (s *Server) withFeatureFlags(handler func(w http.ResponseWriter, r *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.featureFlags == nil {
+			handler(w, r)
+			return
+		}
+		set := s.featureFlags.Evaluate(r.URL.RequestURI(), featureflags.ParseForced(r.URL.Query().Get(featureflags.QueryParam)))
+		if features := set.String(); features != "" {
+			w.Header().Set(featureflags.Header, features)
+		}
+		handler(w, r.WithContext(featureflags.NewContext(r.Context(), set)))
 	}
 }
 
+// startFeatureFlagRefresh loads feature flag overrides from the database, and periodically reloads them so
+// overrides made through another replica are picked up.
+func (s *Server) startFeatureFlagRefresh() {
+	if s.featureFlags == nil {
+		s.featureFlags, _ = featureflags.NewManager(nil)
+	}
+	if s.db == nil {
+		return
+	}
+	if err := api.RefreshFeatureFlags(s.db, s.featureFlags); err != nil {
+		logger.WithError(err).Error("error loading feature flag overrides")
+	}
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := api.RefreshFeatureFlags(s.db, s.featureFlags); err != nil {
+				logger.WithError(err).Error("error refreshing feature flag overrides")
+			}
+		}
+	}()
+}
+
 func (s *Server) GetHTTPServer() *http.Server {
 	return s.httpServer
 }
@@ -2,11 +2,17 @@ package sippyserver
 
 import (
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	apitype "github.com/openshift/sippy/pkg/apis/api"
 	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/logging"
 )
 
 func TestValidateProwJobRun(t *testing.T) {
@@ -120,3 +126,151 @@ func TestEncodeDefaultHighRisk(t *testing.T) {
 		t.Fatal("Invalid overall risk analysis after decoding")
 	}
 }
+
+func TestRespondWithRestored(t *testing.T) {
+	errNotFound := errors.New("not found")
+	restore := func(id uint) (interface{}, error) {
+		if id != 1 {
+			return nil, errNotFound
+		}
+		return map[string]interface{}{"id": id}, nil
+	}
+
+	tests := []struct {
+		method, query string
+		status        int
+	}{
+		{http.MethodPost, "id=1", http.StatusOK},
+		{http.MethodPost, "id=2", http.StatusNotFound},
+		{http.MethodPost, "", http.StatusBadRequest},
+		{http.MethodGet, "id=1", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		respondWithRestored(w, httptest.NewRequest(tc.method, "/restore?"+tc.query, nil), "record", errNotFound, restore)
+		assert.Equal(t, tc.status, w.Code, "%s %s", tc.method, tc.query)
+	}
+}
+
+func TestGetRequestUser(t *testing.T) {
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	assert.Empty(t, getRequestUser(req))
+	req.Header.Set("X-Forwarded-Email", "someone@example.com")
+	assert.Equal(t, "someone@example.com", getRequestUser(req))
+	req.Header.Set("X-Forwarded-User", "someone")
+	assert.Equal(t, "someone", getRequestUser(req))
+}
+
+func TestWriteGated(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	tests := []struct {
+		method  string
+		enabled bool
+		user    string
+		status  int
+	}{
+		{http.MethodGet, false, "", http.StatusOK},
+		{http.MethodPost, false, "someone", http.StatusForbidden},
+		{http.MethodDelete, false, "someone", http.StatusForbidden},
+		{http.MethodPut, true, "", http.StatusUnauthorized},
+		{http.MethodPut, true, "someone", http.StatusOK},
+	}
+	for _, tc := range tests {
+		s := &Server{writeAPIEnabled: tc.enabled}
+		req := httptest.NewRequest(tc.method, "/api/lanes", nil)
+		if tc.user != "" {
+			req.Header.Set("X-Forwarded-User", tc.user)
+		}
+		w := httptest.NewRecorder()
+		s.writeGated(ok)(w, req)
+		assert.Equal(t, tc.status, w.Code, "%s enabled=%t user=%q", tc.method, tc.enabled, tc.user)
+	}
+}
+
+func TestAdminGated(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	tests := []struct {
+		method string
+		admins []string
+		user   string
+		status int
+	}{
+		{http.MethodGet, nil, "", http.StatusOK},
+		{http.MethodPut, nil, "admin", http.StatusForbidden},
+		{http.MethodPut, []string{"admin"}, "", http.StatusUnauthorized},
+		{http.MethodDelete, []string{"admin"}, "someone", http.StatusForbidden},
+		{http.MethodPut, []string{"admin"}, "admin", http.StatusOK},
+	}
+	for _, tc := range tests {
+		s := &Server{}
+		s.SetAdminUsers(tc.admins)
+		req := httptest.NewRequest(tc.method, "/api/admin/log_levels", nil)
+		if tc.user != "" {
+			req.Header.Set("X-Forwarded-User", tc.user)
+		}
+		w := httptest.NewRecorder()
+		s.adminGated(ok)(w, req)
+		assert.Equal(t, tc.status, w.Code, "%s admins=%v user=%q", tc.method, tc.admins, tc.user)
+	}
+}
+
+func TestJSONLogLevels(t *testing.T) {
+	logging.ForSubsystem("test-handler")
+	s := &Server{}
+
+	tests := []struct {
+		method, query, body string
+		status              int
+	}{
+		{http.MethodGet, "", "", http.StatusOK},
+		{http.MethodPut, "", `{"subsystem": "test-handler", "level": "debug"}`, http.StatusOK},
+		{http.MethodPut, "", `{"subsystem": "test-handler", "level": "loud"}`, http.StatusBadRequest},
+		{http.MethodPut, "", `{"subsystem": "missing", "level": "debug"}`, http.StatusNotFound},
+		{http.MethodDelete, "subsystem=test-handler", "", http.StatusOK},
+		{http.MethodDelete, "", "", http.StatusBadRequest},
+		{http.MethodPost, "", "", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		w := httptest.NewRecorder()
+		s.jsonLogLevels(w, httptest.NewRequest(tc.method, "/api/admin/log_levels?"+tc.query, strings.NewReader(tc.body)))
+		assert.Equal(t, tc.status, w.Code, "%s %s %s", tc.method, tc.query, tc.body)
+	}
+}
+
+func TestJSONJobRunDurationsUnknownGroup(t *testing.T) {
+	s := &Server{}
+	w := httptest.NewRecorder()
+	s.jsonJobRunDurations(w, httptest.NewRequest(http.MethodGet, "/api/jobs/durations?release=4.16&group_by=lane", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "must be job or variant")
+}
+
+func TestGetSortParamsOrFail(t *testing.T) {
+	w := httptest.NewRecorder()
+	sortField, sort, ok := getSortParamsOrFail(w, httptest.NewRequest(http.MethodGet, "/api/jobs/analysis?sort=current_runs:asc", nil))
+	assert.True(t, ok)
+	assert.Equal(t, "current_runs", sortField)
+	assert.Equal(t, apitype.SortAscending, sort)
+
+	w = httptest.NewRecorder()
+	_, _, ok = getSortParamsOrFail(w, httptest.NewRequest(http.MethodGet, "/api/jobs/analysis?sortField=name&sort=sideways", nil))
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestReportJobKey(t *testing.T) {
+	a := httptest.NewRequest(http.MethodGet, "/api/component_readiness?view=4.16-main&forceRefresh=true", nil)
+	b := httptest.NewRequest(http.MethodGet, "/api/component_readiness?forceRefresh=true&view=4.16-main", nil)
+	assert.Equal(t, reportJobKey(a), reportJobKey(b))
+	assert.Equal(t, "/api/component_readiness?forceRefresh=true&view=4.16-main", reportJobKey(a))
+}
+
+func TestWithDatabaseHeadersWithoutReplicas(t *testing.T) {
+	s := &Server{}
+	w := httptest.NewRecorder()
+	s.withDatabaseHeaders(func(w http.ResponseWriter, r *http.Request) {})(w, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	assert.Empty(t, w.Header().Get(DatabaseHeader))
+	assert.Empty(t, w.Header().Get(ReplicationLagHeader))
+}
@@ -0,0 +1,51 @@
+package sippyserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	apiV1 = "v1"
+	apiV2 = "v2"
+)
+
+// jobsV1Sunset is when the v1 jobs report, superseded by /api/v2/jobs, may be removed.
+var jobsV1Sunset = time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+var apiRequestsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "sippy_api_requests_total",
+	Help: "Number of API requests by API version and endpoint, used to plan the removal of deprecated endpoints",
+}, []string{"version", "endpoint", "deprecated"})
+
+// apiVersion returns the API version an endpoint belongs to. Endpoints under /api/v2 are v2, all others are the
+// original unversioned API, referred to as v1.
+func apiVersion(endpoint string) string {
+	if endpoint == "/api/v2" || strings.HasPrefix(endpoint, "/api/v2/") {
+		return apiV2
+	}
+	return apiV1
+}
+
+// versioned wraps an endpoint handler to count requests by API version. Deprecated endpoints, those with a sunset
+// time, also respond with Deprecation and Sunset headers, and a link to their successor.
+func versioned(endpoint string, sunset *time.Time, successor string, handler func(w http.ResponseWriter, r *http.Request)) func(http.ResponseWriter, *http.Request) {
+	version := apiVersion(endpoint)
+	deprecated := fmt.Sprintf("%t", sunset != nil)
+	return func(w http.ResponseWriter, r *http.Request) {
+		apiRequestsMetric.WithLabelValues(version, endpoint, deprecated).Inc()
+		if sunset != nil {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Sunset", sunset.Format(http.TimeFormat))
+			if successor != "" {
+				w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
+			}
+		}
+		handler(w, r)
+	}
+}
@@ -0,0 +1,35 @@
+package sippyserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIVersion(t *testing.T) {
+	assert.Equal(t, apiV1, apiVersion("/api/jobs"))
+	assert.Equal(t, apiV1, apiVersion("/api/v2jobs"))
+	assert.Equal(t, apiV2, apiVersion("/api/v2/jobs"))
+}
+
+func TestVersionedDeprecationHeaders(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	sunset := time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	rec := httptest.NewRecorder()
+	versioned("/api/jobs", &sunset, "/api/v2/jobs", handler)(rec, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+	assert.Equal(t, "Thu, 01 Apr 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v2/jobs>; rel="successor-version"`, rec.Header().Get("Link"))
+
+	rec = httptest.NewRecorder()
+	versioned("/api/v2/jobs", nil, "", handler)(rec, httptest.NewRequest(http.MethodGet, "/api/v2/jobs", nil))
+	assert.Empty(t, rec.Header().Get("Deprecation"))
+	assert.Empty(t, rec.Header().Get("Sunset"))
+}
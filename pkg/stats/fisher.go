@@ -0,0 +1,25 @@
+package stats
+
+import (
+	fischer "github.com/glycerine/golang-fisher-exact"
+)
+
+// FisherExactResult holds the p-values of Fisher's exact test comparing the failure rate of a sample to a basis.
+type FisherExactResult struct {
+	// Greater is the one-sided p-value for the sample failing more often than the basis, the one used to find
+	// regressions.
+	Greater float64
+	// Less is the one-sided p-value for the sample failing less often than the basis, the one used to find
+	// improvements.
+	Less float64
+	// TwoSided is the p-value for the sample failing at a different rate than the basis, in either direction.
+	TwoSided float64
+}
+
+// FisherExact runs Fisher's exact test on the 2x2 table of sample and basis failures and successes. It's exact
+// for any number of runs, so it's safe on the small samples of rarely run jobs where normal approximations
+// aren't.
+func FisherExact(sample, basis Counts) FisherExactResult {
+	_, less, greater, twoSided := fischer.FisherExactTest(sample.Failures, sample.Successes, basis.Failures, basis.Successes)
+	return FisherExactResult{Greater: greater, Less: less, TwoSided: twoSided}
+}
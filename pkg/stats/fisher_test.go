@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFisherExact(t *testing.T) {
+	tests := []struct {
+		name         string
+		sample       Counts
+		basis        Counts
+		wantGreater  float64
+		wantLess     float64
+		wantTwoSided float64
+	}{
+		{
+			name:         "large regression",
+			sample:       Counts{Successes: 80, Failures: 20},
+			basis:        Counts{Successes: 990, Failures: 10},
+			wantGreater:  3.32569e-15,
+			wantLess:     1,
+			wantTwoSided: 3.32569e-15,
+		},
+		{
+			name:         "identical rates",
+			sample:       Counts{Successes: 9, Failures: 1},
+			basis:        Counts{Successes: 9, Failures: 1},
+			wantGreater:  0.763158,
+			wantLess:     0.763158,
+			wantTwoSided: 1,
+		},
+		{
+			name:         "improvement",
+			sample:       Counts{Successes: 10, Failures: 0},
+			basis:        Counts{Successes: 5, Failures: 5},
+			wantGreater:  1,
+			wantLess:     0.0162539,
+			wantTwoSided: 0.0325077,
+		},
+		{
+			name:         "tiny sample that always fails",
+			sample:       Counts{Successes: 0, Failures: 3},
+			basis:        Counts{Successes: 3, Failures: 0},
+			wantGreater:  0.05,
+			wantLess:     1,
+			wantTwoSided: 0.1,
+		},
+		{
+			name:         "same rate with more basis runs",
+			sample:       Counts{Successes: 95, Failures: 5},
+			basis:        Counts{Successes: 950, Failures: 50},
+			wantGreater:  0.572215,
+			wantLess:     0.616561,
+			wantTwoSided: 1,
+		},
+		{
+			name:         "moderate regression",
+			sample:       Counts{Successes: 90, Failures: 10},
+			basis:        Counts{Successes: 98, Failures: 2},
+			wantGreater:  0.0165201,
+			wantLess:     0.997509,
+			wantTwoSided: 0.0330401,
+		},
+		{
+			name:         "no runs",
+			wantGreater:  1,
+			wantLess:     1,
+			wantTwoSided: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := FisherExact(tt.sample, tt.basis)
+			assert.InEpsilon(t, tt.wantGreater, result.Greater, 1e-4, "greater")
+			assert.InEpsilon(t, tt.wantLess, result.Less, 1e-4, "less")
+			assert.InEpsilon(t, tt.wantTwoSided, result.TwoSided, 1e-4, "two sided")
+		})
+	}
+}
+
+func TestSignificant(t *testing.T) {
+	tests := []struct {
+		p          float64
+		confidence int
+		want       bool
+	}{
+		{p: 0.049, confidence: 95, want: true},
+		{p: 0.051, confidence: 95, want: false},
+		{p: 0.02, confidence: 99, want: false},
+		{p: 0.009, confidence: 99, want: true},
+		{p: 0.2, confidence: 80, want: false},
+		{p: 0, confidence: 100, want: false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Significant(tt.p, tt.confidence), "p=%v confidence=%d", tt.p, tt.confidence)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	assert.Equal(t, 10, Counts{Successes: 9, Failures: 1}.Total())
+	assert.InDelta(t, 0.9, Counts{Successes: 9, Failures: 1}.PassRate(), 1e-9)
+	assert.Zero(t, Counts{}.PassRate())
+}
@@ -0,0 +1,28 @@
+package stats
+
+import (
+	"fmt"
+	"math"
+)
+
+// RequiredSampleSize returns the number of runs needed to detect that a pass rate dropped from basisPassRate to
+// regressedPassRate, with a one-sided test at the given confidence percentage that detects the drop with the
+// given power percentage, i.e. 80. The basis is assumed to have enough runs that its pass rate is known, as it
+// usually is for a previous release.
+func RequiredSampleSize(basisPassRate, regressedPassRate float64, confidence, power int) (int, error) {
+	if basisPassRate < 0 || basisPassRate > 1 || regressedPassRate < 0 || regressedPassRate > 1 {
+		return 0, fmt.Errorf("pass rates must be between 0 and 1")
+	}
+	if regressedPassRate >= basisPassRate {
+		return 0, fmt.Errorf("regressed pass rate %v must be below the basis pass rate %v", regressedPassRate, basisPassRate)
+	}
+	if confidence <= 0 || confidence >= 100 || power <= 0 || power >= 100 {
+		return 0, fmt.Errorf("confidence and power must be percentages between 0 and 100")
+	}
+
+	zAlpha := zScore(float64(confidence))
+	zBeta := zScore(float64(power))
+	n := (zAlpha*math.Sqrt(basisPassRate*(1-basisPassRate)) + zBeta*math.Sqrt(regressedPassRate*(1-regressedPassRate))) /
+		(basisPassRate - regressedPassRate)
+	return int(math.Ceil(n * n)), nil
+}
@@ -0,0 +1,49 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequiredSampleSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		basis      float64
+		regressed  float64
+		confidence int
+		power      int
+		want       int
+		wantErr    bool
+	}{
+		{name: "small drop from a stable test", basis: 0.99, regressed: 0.95, confidence: 95, power: 80, want: 76},
+		{name: "drop from a flaky test", basis: 0.95, regressed: 0.90, confidence: 95, power: 80, want: 150},
+		{name: "higher confidence and power", basis: 0.90, regressed: 0.80, confidence: 99, power: 90, want: 147},
+		{name: "from a test that never fails", basis: 1, regressed: 0.9, confidence: 95, power: 80, want: 7},
+		{name: "coin flip", basis: 0.5, regressed: 0.4, confidence: 95, power: 80, want: 153},
+		{name: "no drop", basis: 0.9, regressed: 0.9, confidence: 95, power: 80, wantErr: true},
+		{name: "improvement", basis: 0.8, regressed: 0.9, confidence: 95, power: 80, wantErr: true},
+		{name: "pass rate out of range", basis: 95, regressed: 90, confidence: 95, power: 80, wantErr: true},
+		{name: "confidence out of range", basis: 0.95, regressed: 0.9, confidence: 100, power: 80, wantErr: true},
+		{name: "power out of range", basis: 0.95, regressed: 0.9, confidence: 95, power: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := RequiredSampleSize(tt.basis, tt.regressed, tt.confidence, tt.power)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, n)
+		})
+	}
+
+	// Smaller drops need more runs to detect.
+	small, err := RequiredSampleSize(0.95, 0.94, 95, 80)
+	require.NoError(t, err)
+	large, err := RequiredSampleSize(0.95, 0.85, 95, 80)
+	require.NoError(t, err)
+	assert.Greater(t, small, large)
+}
@@ -0,0 +1,44 @@
+// Package stats has the statistics sippy uses to compare pass rates: Fisher's exact test to decide whether a sample
+// fails significantly more than a basis, Wilson score intervals to bound a pass rate, the sample size needed to
+// detect a given drop, and decay weights to count recent runs for more than older ones. Subsystems should use these
+// rather than their own math, so a test judged regressed in one report is judged the same way everywhere.
+//
+// Confidence levels are percentages, i.e. 95, as they are in views and request parameters.
+package stats
+
+import (
+	"math"
+)
+
+// Counts are the results of a test or job. Flakes should be counted as successes, as they are when judging
+// regressions.
+type Counts struct {
+	Successes int
+	Failures  int
+}
+
+// Total returns the number of runs.
+func (c Counts) Total() int {
+	return c.Successes + c.Failures
+}
+
+// PassRate returns the fraction of runs that succeeded, 0 if there were none.
+func (c Counts) PassRate() float64 {
+	if c.Total() == 0 {
+		return 0
+	}
+	return float64(c.Successes) / float64(c.Total())
+}
+
+// Significant returns true if a p-value is below the significance level of a confidence percentage, i.e. below
+// 0.05 at 95.
+func Significant(p float64, confidence int) bool {
+	return p < 1-float64(confidence)/100
+}
+
+// zScore returns the standard normal quantile for a one-sided confidence percentage, clamped between 50 and
+// 99.99: a 100% bound is infinite, and one below 50% is on the wrong side of the estimate.
+func zScore(confidence float64) float64 {
+	c := math.Max(math.Min(confidence, 99.99), 50) / 100
+	return math.Sqrt2 * math.Erfinv(2*c-1)
+}
@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"math"
+	"time"
+)
+
+// DecayWeight returns the weight of a result age before the end of a window, when weights halve every halfLife:
+// 1 at the end of the window, 0.5 one half-life before it. A result at or after the end, or a half-life of 0,
+// has full weight.
+func DecayWeight(age, halfLife time.Duration) float64 {
+	if age <= 0 || halfLife <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(halfLife))
+}
+
+// WeightedCounts are Counts summed with a weight per run, so recent runs can count for more than older ones.
+type WeightedCounts struct {
+	Successes float64
+	Total     float64
+}
+
+// Add adds counts with the given weight.
+func (w *WeightedCounts) Add(c Counts, weight float64) {
+	w.Successes += float64(c.Successes) * weight
+	w.Total += float64(c.Total()) * weight
+}
+
+// PassRate returns the weighted fraction of runs that succeeded, 0 if there were none.
+func (w WeightedCounts) PassRate() float64 {
+	if w.Total == 0 {
+		return 0
+	}
+	return w.Successes / w.Total
+}
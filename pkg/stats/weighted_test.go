@@ -0,0 +1,32 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecayWeight(t *testing.T) {
+	halfLife := 72 * time.Hour
+	assert.Equal(t, 1.0, DecayWeight(0, halfLife))
+	assert.Equal(t, 1.0, DecayWeight(-time.Hour, halfLife), "runs after the end have full weight")
+	assert.InDelta(t, 0.5, DecayWeight(halfLife, halfLife), 1e-9)
+	assert.InDelta(t, 0.25, DecayWeight(2*halfLife, halfLife), 1e-9)
+	assert.Equal(t, 1.0, DecayWeight(2*halfLife, 0), "no half-life weighs everything equally")
+}
+
+func TestWeightedCounts(t *testing.T) {
+	var flat, weighted WeightedCounts
+	// Failing a week ago, passing since
+	old := Counts{Successes: 2, Failures: 8}
+	recent := Counts{Successes: 10}
+	flat.Add(old, 1)
+	flat.Add(recent, 1)
+	weighted.Add(old, DecayWeight(7*24*time.Hour, 72*time.Hour))
+	weighted.Add(recent, 1)
+
+	assert.InDelta(t, 0.6, flat.PassRate(), 1e-9)
+	assert.InDelta(t, 0.8675, weighted.PassRate(), 1e-4)
+	assert.Equal(t, 0.0, WeightedCounts{}.PassRate())
+}
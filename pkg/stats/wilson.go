@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"math"
+)
+
+// WilsonInterval returns the two-sided Wilson score interval of a pass rate at a confidence percentage. Unlike the
+// normal approximation it stays within [0, 1] and behaves for pass rates near 0 or 1. With no runs, nothing is
+// known and the interval is [0, 1].
+func WilsonInterval(successes, total, confidence int) (lower, upper float64) {
+	if total <= 0 {
+		return 0, 1
+	}
+	// A two-sided interval at c% leaves (100-c)/2% in each tail.
+	z := zScore(100 - (100-float64(confidence))/2)
+	return wilsonBound(successes, total, -z), wilsonBound(successes, total, z)
+}
+
+// WilsonUpperBound returns the one-sided upper bound of the Wilson score interval of a pass rate: the pass rate
+// is below it with the given confidence. It's 1 with no runs.
+func WilsonUpperBound(successes, total, confidence int) float64 {
+	if total <= 0 {
+		return 1
+	}
+	return wilsonBound(successes, total, zScore(float64(confidence)))
+}
+
+// WilsonLowerBound returns the one-sided lower bound of the Wilson score interval of a pass rate: the pass rate
+// is above it with the given confidence. It's 0 with no runs.
+func WilsonLowerBound(successes, total, confidence int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return wilsonBound(successes, total, -zScore(float64(confidence)))
+}
+
+// wilsonBound returns the upper bound of the Wilson score interval for a positive z, and the lower for a
+// negative one.
+func wilsonBound(successes, total int, z float64) float64 {
+	n := float64(total)
+	p := float64(successes) / n
+	center := p + z*z/(2*n)
+	margin := z * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return math.Max(math.Min((center+margin)/(1+z*z/n), 1), 0)
+}
@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	tests := []struct {
+		name       string
+		successes  int
+		total      int
+		confidence int
+		wantLower  float64
+		wantUpper  float64
+	}{
+		{name: "90% pass rate", successes: 90, total: 100, confidence: 95, wantLower: 0.825634, wantUpper: 0.944771},
+		{name: "never passes", successes: 0, total: 10, confidence: 95, wantLower: 0, wantUpper: 0.277533},
+		{name: "always passes", successes: 10, total: 10, confidence: 95, wantLower: 0.722467, wantUpper: 1},
+		{name: "high confidence", successes: 45, total: 50, confidence: 99, wantLower: 0.740269, wantUpper: 0.966009},
+		{name: "single run", successes: 1, total: 1, confidence: 90, wantLower: 0.269866, wantUpper: 1},
+		{name: "no runs", successes: 0, total: 0, confidence: 95, wantLower: 0, wantUpper: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lower, upper := WilsonInterval(tt.successes, tt.total, tt.confidence)
+			assert.InDelta(t, tt.wantLower, lower, 1e-5, "lower")
+			assert.InDelta(t, tt.wantUpper, upper, 1e-5, "upper")
+		})
+	}
+}
+
+func TestWilsonBounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		successes  int
+		total      int
+		confidence int
+		wantLower  float64
+		wantUpper  float64
+	}{
+		{name: "90% pass rate", successes: 90, total: 100, confidence: 95, wantLower: 0.839644, wantUpper: 0.939281},
+		{name: "never passes", successes: 0, total: 10, confidence: 95, wantLower: 0, wantUpper: 0.212942},
+		{name: "always passes", successes: 10, total: 10, confidence: 95, wantLower: 0.787058, wantUpper: 1},
+		{name: "high confidence", successes: 45, total: 50, confidence: 99, wantLower: 0.759365, wantUpper: 0.962502},
+		{name: "single run", successes: 1, total: 1, confidence: 90, wantLower: 0.378448, wantUpper: 1},
+		{name: "no confidence is the pass rate", successes: 90, total: 100, confidence: 0, wantLower: 0.9, wantUpper: 0.9},
+		{name: "full confidence is clamped", successes: 100, total: 100, confidence: 100, wantLower: 0.878495, wantUpper: 1},
+		{name: "no runs", successes: 0, total: 0, confidence: 95, wantLower: 0, wantUpper: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.wantLower, WilsonLowerBound(tt.successes, tt.total, tt.confidence), 1e-5, "lower")
+			assert.InDelta(t, tt.wantUpper, WilsonUpperBound(tt.successes, tt.total, tt.confidence), 1e-5, "upper")
+		})
+	}
+
+	// Higher confidence widens the interval, and more runs narrow it.
+	assert.Greater(t, WilsonUpperBound(90, 100, 99), WilsonUpperBound(90, 100, 95))
+	assert.Less(t, WilsonUpperBound(900, 1000, 95), WilsonUpperBound(90, 100, 95))
+}
@@ -0,0 +1,171 @@
+package testidentification
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// TestMatcher identifies the install, upgrade and operator tests of a test suite or product. Suites name these
+// tests differently, so each registers a matcher and sippy treats a test as e.g. an install test if any registered
+// matcher does. This lets layered products reuse the install and upgrade reports with their own test names.
+type TestMatcher interface {
+	// Name identifies the matcher, registering a matcher replaces any with the same name.
+	Name() string
+
+	IsInstallStepEquivalent(testName string) bool
+	IsUpgradeStartedTest(testName string) bool
+	IsOperatorsUpgradedTest(testName string) bool
+	IsMachineConfigPoolsUpgradedTest(testName string) bool
+
+	// OperatorFromTest returns the operator an operator install, upgrade or health test is for, and false for any
+	// other test.
+	OperatorFromTest(testName string) (string, bool)
+
+	// InstallReportTests and UpgradeReportTests return the tests shown in a release's install and upgrade reports.
+	InstallReportTests(release string) ReportTests
+	UpgradeReportTests(release string) ReportTests
+}
+
+// ReportTests selects tests for a report by exact name, name prefix, or substring.
+type ReportTests struct {
+	Names      sets.String
+	Prefixes   sets.String
+	SubStrings sets.String
+}
+
+var (
+	testMatchersLock sync.RWMutex
+	testMatchers     = []TestMatcher{openshiftTestMatcher{}, kubernetesTestMatcher{}}
+)
+
+// RegisterTestMatcher adds a matcher, replacing any registered matcher with the same name.
+func RegisterTestMatcher(m TestMatcher) {
+	testMatchersLock.Lock()
+	defer testMatchersLock.Unlock()
+	for i, existing := range testMatchers {
+		if existing.Name() == m.Name() {
+			testMatchers[i] = m
+			return
+		}
+	}
+	testMatchers = append(testMatchers, m)
+}
+
+// UnregisterTestMatcher removes the matcher with the given name, if registered.
+func UnregisterTestMatcher(name string) {
+	testMatchersLock.Lock()
+	defer testMatchersLock.Unlock()
+	for i, existing := range testMatchers {
+		if existing.Name() == name {
+			testMatchers = append(testMatchers[:i:i], testMatchers[i+1:]...)
+			return
+		}
+	}
+}
+
+// TestMatchers returns the registered matchers, in registration order.
+func TestMatchers() []TestMatcher {
+	testMatchersLock.RLock()
+	defer testMatchersLock.RUnlock()
+	return append([]TestMatcher{}, testMatchers...)
+}
+
+func anyTestMatcher(match func(m TestMatcher) bool) bool {
+	for _, m := range TestMatchers() {
+		if match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInstallStepEquivalent returns true if any matcher identifies the test as signalling install success.
+func IsInstallStepEquivalent(testName string) bool {
+	return anyTestMatcher(func(m TestMatcher) bool { return m.IsInstallStepEquivalent(testName) })
+}
+
+// IsUpgradeStartedTest returns true if any matcher identifies the test as signalling an upgrade started.
+func IsUpgradeStartedTest(testName string) bool {
+	return anyTestMatcher(func(m TestMatcher) bool { return m.IsUpgradeStartedTest(testName) })
+}
+
+// IsOperatorsUpgradedTest returns true if any matcher identifies the test as signalling operators upgraded.
+func IsOperatorsUpgradedTest(testName string) bool {
+	return anyTestMatcher(func(m TestMatcher) bool { return m.IsOperatorsUpgradedTest(testName) })
+}
+
+// IsMachineConfigPoolsUpgradedTest returns true if any matcher identifies the test as signalling machine config
+// pools upgraded.
+func IsMachineConfigPoolsUpgradedTest(testName string) bool {
+	return anyTestMatcher(func(m TestMatcher) bool { return m.IsMachineConfigPoolsUpgradedTest(testName) })
+}
+
+// IsOperatorHealthTest returns true if any matcher identifies the test as an operator install, upgrade or health
+// test.
+func IsOperatorHealthTest(testName string) bool {
+	_, ok := operatorFromTest(testName)
+	return ok
+}
+
+// GetOperatorNameFromTest returns the operator an operator install, upgrade or health test is for, or an empty
+// string for any other test.
+func GetOperatorNameFromTest(testName string) string {
+	operator, _ := operatorFromTest(testName)
+	return operator
+}
+
+func operatorFromTest(testName string) (string, bool) {
+	for _, m := range TestMatchers() {
+		if operator, ok := m.OperatorFromTest(testName); ok {
+			return operator, true
+		}
+	}
+	return "", false
+}
+
+// InstallReportTests returns the tests every matcher shows in a release's install report.
+func InstallReportTests(release string) ReportTests {
+	return mergeReportTests(func(m TestMatcher) ReportTests { return m.InstallReportTests(release) })
+}
+
+// UpgradeReportTests returns the tests every matcher shows in a release's upgrade report.
+func UpgradeReportTests(release string) ReportTests {
+	return mergeReportTests(func(m TestMatcher) ReportTests { return m.UpgradeReportTests(release) })
+}
+
+func mergeReportTests(tests func(m TestMatcher) ReportTests) ReportTests {
+	merged := ReportTests{Names: sets.NewString(), Prefixes: sets.NewString(), SubStrings: sets.NewString()}
+	for _, m := range TestMatchers() {
+		t := tests(m)
+		merged.Names.Insert(t.Names.List()...)
+		merged.Prefixes.Insert(t.Prefixes.List()...)
+		merged.SubStrings.Insert(t.SubStrings.List()...)
+	}
+	return merged
+}
+
+// UseNewInstallTest decides which install test name to use based on releases. For
+// 4.11 and above, use the new install test name.
+func UseNewInstallTest(release string) bool {
+	digits := strings.Split(release, ".")
+	if len(digits) < 2 {
+		return false
+	}
+	major, err := strconv.Atoi(digits[0])
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(digits[1])
+	if err != nil {
+		return false
+	}
+	if major < 4 {
+		return false
+	} else if major == 4 && minor < 11 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package testidentification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// layeredProductMatcher identifies tests of a hypothetical layered product that prefixes its tests "[lp] ".
+type layeredProductMatcher struct{}
+
+func (layeredProductMatcher) Name() string { return "layered-product" }
+func (layeredProductMatcher) IsInstallStepEquivalent(testName string) bool {
+	return testName == "[lp] product installs"
+}
+func (layeredProductMatcher) IsUpgradeStartedTest(string) bool             { return false }
+func (layeredProductMatcher) IsOperatorsUpgradedTest(string) bool          { return false }
+func (layeredProductMatcher) IsMachineConfigPoolsUpgradedTest(string) bool { return false }
+func (layeredProductMatcher) OperatorFromTest(testName string) (string, bool) {
+	if strings.HasPrefix(testName, "[lp] operator ") {
+		return strings.TrimPrefix(testName, "[lp] operator "), true
+	}
+	return "", false
+}
+func (layeredProductMatcher) InstallReportTests(string) ReportTests {
+	return ReportTests{Names: sets.NewString("[lp] product installs"), Prefixes: sets.NewString("[lp] operator ")}
+}
+func (layeredProductMatcher) UpgradeReportTests(string) ReportTests { return ReportTests{} }
+
+func TestBuiltInTestMatchers(t *testing.T) {
+	assert.True(t, IsInstallStepEquivalent("install should succeed: overall"))
+	assert.True(t, IsInstallStepEquivalent("e2e-aws-ovn-ipi-install-install container test"))
+	assert.True(t, IsInstallStepEquivalent("Up"))
+	assert.False(t, IsInstallStepEquivalent("[sig-network] some test"))
+
+	assert.True(t, IsUpgradeStartedTest("Cluster upgrade.[sig-cluster-lifecycle] Cluster version operator acknowledges upgrade"))
+	assert.True(t, IsOperatorsUpgradedTest("[sig-cluster-lifecycle] Cluster completes upgrade"))
+	assert.True(t, IsMachineConfigPoolsUpgradedTest("[sig-mco] Machine config pools complete upgrade"))
+
+	assert.True(t, IsOperatorHealthTest("Operator upgrade etcd"))
+	assert.Equal(t, "etcd", GetOperatorNameFromTest("Operator upgrade etcd"))
+	assert.Equal(t, "dns", GetOperatorNameFromTest("Operator results.operator conditions dns"))
+	assert.Equal(t, "", GetOperatorNameFromTest("[sig-network] some test"))
+
+	install := InstallReportTests("4.16")
+	assert.True(t, install.Prefixes.Has(InstallTestNamePrefix))
+	assert.False(t, install.Names.Has(InstallTestName))
+	install = InstallReportTests("4.10")
+	assert.True(t, install.Names.Has(InstallTestName))
+
+	upgrade := UpgradeReportTests("4.16")
+	assert.True(t, upgrade.Names.Has(UpgradeTestName))
+	assert.True(t, upgrade.SubStrings.Has(OperatorsUpgradedTest))
+}
+
+func TestRegisterTestMatcher(t *testing.T) {
+	RegisterTestMatcher(layeredProductMatcher{})
+	defer UnregisterTestMatcher(layeredProductMatcher{}.Name())
+
+	assert.True(t, IsInstallStepEquivalent("[lp] product installs"))
+	assert.Equal(t, "lp-operator", GetOperatorNameFromTest("[lp] operator lp-operator"))
+	assert.Equal(t, "etcd", GetOperatorNameFromTest("Operator upgrade etcd"), "built-in matchers are still used")
+
+	install := InstallReportTests("4.16")
+	assert.True(t, install.Names.Has("[lp] product installs"))
+	assert.True(t, install.Prefixes.Has("[lp] operator "))
+	assert.True(t, install.Prefixes.Has(InstallTestNamePrefix))
+
+	// Registering a matcher with the same name replaces it.
+	before := len(TestMatchers())
+	RegisterTestMatcher(layeredProductMatcher{})
+	assert.Len(t, TestMatchers(), before)
+
+	UnregisterTestMatcher(layeredProductMatcher{}.Name())
+	assert.False(t, IsInstallStepEquivalent("[lp] product installs"))
+}
+
+func TestUseNewInstallTest(t *testing.T) {
+	assert.True(t, UseNewInstallTest("4.11"))
+	assert.True(t, UseNewInstallTest("5.0"))
+	assert.False(t, UseNewInstallTest("4.10"))
+	assert.False(t, UseNewInstallTest("3.11"))
+	assert.False(t, UseNewInstallTest("Presubmits"))
+}
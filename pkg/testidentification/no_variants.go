@@ -15,6 +15,9 @@ func (noVariants) AllPlatforms() sets.String {
 }
 
 func (v noVariants) IdentifyVariants(jobName string) []string {
+	if _, ok := RehearsedJob(jobName); ok {
+		return []string{Rehearsal}
+	}
 	return []string{}
 }
 func (noVariants) IsJobNeverStable(jobName string) bool {
@@ -0,0 +1,115 @@
+package testidentification
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// notApplicableRaw is the default test exclusion matrix, see not_applicable.yaml.
+//
+//go:embed not_applicable.yaml
+var notApplicableRaw []byte
+
+var defaultNotApplicableRules = mustParseNotApplicableRules(notApplicableRaw)
+
+// NotApplicableRule declares that tests matching TestPattern can never run in variants matching Variants, a map of
+// variant names to the values the tests can't run with.
+type NotApplicableRule struct {
+	TestPattern string              `yaml:"test_pattern" json:"test_pattern"`
+	Variants    map[string][]string `yaml:"variants" json:"variants"`
+	Reason      string              `yaml:"reason" json:"reason"`
+
+	re *regexp.Regexp
+}
+
+// NotApplicableRules are the rules for tests whose absence in some variants is expected, so reports don't show them
+// as missing or regressed there. A nil NotApplicableRules matches nothing.
+type NotApplicableRules struct {
+	rules []NotApplicableRule
+}
+
+// ParseNotApplicableRules parses a yaml list of NotApplicableRule.
+func ParseNotApplicableRules(data []byte) (*NotApplicableRules, error) {
+	var rules []NotApplicableRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, errors.Wrap(err, "invalid not applicable rules")
+	}
+	for i := range rules {
+		r := &rules[i]
+		re, err := regexp.Compile(r.TestPattern)
+		if err != nil || r.TestPattern == "" {
+			return nil, errors.Errorf("invalid test_pattern %q in not applicable rules", r.TestPattern)
+		}
+		if len(r.Variants) == 0 {
+			return nil, errors.Errorf("not applicable rule for %s has no variants", r.TestPattern)
+		}
+		if r.Reason == "" {
+			return nil, errors.Errorf("not applicable rule for %s has no reason", r.TestPattern)
+		}
+		r.re = re
+	}
+	return &NotApplicableRules{rules: rules}, nil
+}
+
+// DefaultNotApplicableRules returns the not applicable rules built into sippy.
+func DefaultNotApplicableRules() *NotApplicableRules {
+	return defaultNotApplicableRules
+}
+
+func mustParseNotApplicableRules(data []byte) *NotApplicableRules {
+	r, err := ParseNotApplicableRules(data)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Match returns the first rule making a test not applicable in variants, a map of variant names to values, or nil if
+// the test can run there. Variants a rule lists that aren't in the map don't match.
+func (n *NotApplicableRules) Match(testName string, variants map[string]string) *NotApplicableRule {
+	if n == nil {
+		return nil
+	}
+	for i := range n.rules {
+		r := &n.rules[i]
+		if r.matchesVariants(variants) && r.re.MatchString(testName) {
+			return r
+		}
+	}
+	return nil
+}
+
+// MatchVariantList is Match for variants in the form Name:value.
+func (n *NotApplicableRules) MatchVariantList(testName string, variants []string) *NotApplicableRule {
+	typed := make(map[string]string, len(variants))
+	for _, v := range variants {
+		if name, value, ok := strings.Cut(v, ":"); ok {
+			typed[name] = value
+		}
+	}
+	return n.Match(testName, typed)
+}
+
+func (r *NotApplicableRule) matchesVariants(variants map[string]string) bool {
+	for name, values := range r.Variants {
+		value, ok := variants[name]
+		if !ok {
+			return false
+		}
+		matched := false
+		for _, v := range values {
+			if v == value {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package testidentification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotApplicableRules(t *testing.T) {
+	rules, err := ParseNotApplicableRules([]byte(`
+- test_pattern: '\[sig-cloud-provider-aws\]'
+  variants:
+    Platform: [metal, gcp]
+    Upgrade: [none]
+  reason: AWS only
+`))
+	require.NoError(t, err)
+
+	rule := rules.Match("[sig-cloud-provider-aws] test", map[string]string{"Platform": "metal", "Upgrade": "none", "Network": "ovn"})
+	require.NotNil(t, rule)
+	assert.Equal(t, "AWS only", rule.Reason)
+	assert.NotNil(t, rules.MatchVariantList("[sig-cloud-provider-aws] test", []string{"Platform:gcp", "Upgrade:none"}))
+
+	// every listed variant must match
+	assert.Nil(t, rules.Match("[sig-cloud-provider-aws] test", map[string]string{"Platform": "aws", "Upgrade": "none"}))
+	assert.Nil(t, rules.Match("[sig-cloud-provider-aws] test", map[string]string{"Platform": "metal"}))
+	assert.Nil(t, rules.Match("[sig-network] test", map[string]string{"Platform": "metal", "Upgrade": "none"}))
+
+	var none *NotApplicableRules
+	assert.Nil(t, none.Match("[sig-cloud-provider-aws] test", map[string]string{"Platform": "metal"}))
+}
+
+func TestParseNotApplicableRulesInvalid(t *testing.T) {
+	for _, doc := range []string{
+		`- {test_pattern: '(', variants: {Platform: [metal]}, reason: r}`,
+		`- {test_pattern: 'a', reason: r}`,
+		`- {test_pattern: 'a', variants: {Platform: [metal]}}`,
+		`not a list`,
+	} {
+		_, err := ParseNotApplicableRules([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+}
+
+func TestDefaultNotApplicableRules(t *testing.T) {
+	assert.NotNil(t, DefaultNotApplicableRules().Match("[sig-cloud-provider-aws] test", map[string]string{"Platform": "metal"}))
+}
@@ -0,0 +1,107 @@
+package testidentification
+
+import (
+	"strings"
+
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+// openshiftTestMatcher identifies the install, upgrade and operator tests of OpenShift CI jobs.
+type openshiftTestMatcher struct{}
+
+func (openshiftTestMatcher) Name() string {
+	return "openshift"
+}
+
+// TODO We should instead try to detect whether we fail in a pre-step to determine whether install succeeded
+// Install steps have different names in different jobs. This is heavily dependent on the actual UPI jobs, but they turn out to be different.
+// When this needs updating,  it shows up as installs timing out in weird numbers
+func (openshiftTestMatcher) IsInstallStepEquivalent(testName string) bool {
+	if strings.Contains(testName, NewInstallTestName) {
+		return true
+	}
+	for installName := range customJobInstallNames {
+		if strings.Contains(testName, installName) {
+			return true
+		}
+	}
+
+	if strings.HasSuffix(testName, "container setup") {
+		return true
+	}
+	if strings.HasSuffix(testName, "create-cluster") {
+		return true
+	}
+
+	return false
+}
+
+func (openshiftTestMatcher) IsUpgradeStartedTest(testName string) bool {
+	return cvoAcknowledgesUpgradeRegex.MatchString(testName)
+}
+
+func (openshiftTestMatcher) IsOperatorsUpgradedTest(testName string) bool {
+	return operatorsUpgradedRegex.MatchString(testName)
+}
+
+func (openshiftTestMatcher) IsMachineConfigPoolsUpgradedTest(testName string) bool {
+	return machineConfigsUpgradedRegex.MatchString(testName)
+}
+
+func (openshiftTestMatcher) OperatorFromTest(testName string) (string, bool) {
+	if IsOldUpgradeOperatorTest(testName) {
+		return GetOperatorFromUpgradeTest(testName), true
+	}
+	if IsOldInstallOperatorTest(testName) {
+		return GetOperatorFromInstallTest(testName), true
+	}
+	if strings.HasPrefix(testName, OperatorFinalHealthPrefix) {
+		return testName[len(OperatorFinalHealthPrefix):], true
+	}
+	return "", false
+}
+
+func (openshiftTestMatcher) InstallReportTests(release string) ReportTests {
+	tests := ReportTests{Names: sets.NewString(), Prefixes: sets.NewString(OperatorInstallPrefix)}
+	if UseNewInstallTest(release) {
+		tests.Prefixes.Insert(InstallTestNamePrefix)
+	} else {
+		tests.Names.Insert(InstallTestName)
+	}
+	return tests
+}
+
+func (openshiftTestMatcher) UpgradeReportTests(string) ReportTests {
+	return ReportTests{
+		Names: sets.NewString(UpgradeTestName),
+		// "old" upgrade test
+		Prefixes: sets.NewString(OperatorUpgradePrefix),
+		// Some of these are substring matches due to suites being included in the test name but not in sippy code.
+		SubStrings: sets.NewString(
+			OperatorsUpgradedTest,
+			APIsRemainAvailTest,
+			MachineConfigsUpgradedTest,
+			CVOAcknowledgesUpgradeTest,
+		),
+	}
+}
+
+// kubernetesTestMatcher identifies the install tests of upstream Kubernetes jobs, which have no upgrade or operator
+// tests sippy reports on.
+type kubernetesTestMatcher struct{}
+
+func (kubernetesTestMatcher) Name() string {
+	return "kubernetes"
+}
+
+func (kubernetesTestMatcher) IsInstallStepEquivalent(testName string) bool {
+	//  kube uses this to mean the installation worked.  It's not perfectly analogous, but it's close.
+	return testName == "Up"
+}
+
+func (kubernetesTestMatcher) IsUpgradeStartedTest(string) bool             { return false }
+func (kubernetesTestMatcher) IsOperatorsUpgradedTest(string) bool          { return false }
+func (kubernetesTestMatcher) IsMachineConfigPoolsUpgradedTest(string) bool { return false }
+func (kubernetesTestMatcher) OperatorFromTest(string) (string, bool)       { return "", false }
+func (kubernetesTestMatcher) InstallReportTests(string) ReportTests        { return ReportTests{} }
+func (kubernetesTestMatcher) UpgradeReportTests(string) ReportTests        { return ReportTests{} }
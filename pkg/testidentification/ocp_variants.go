@@ -117,6 +117,11 @@ func (v *openshiftVariants) AllPlatforms() sets.String {
 }
 
 func (v *openshiftVariants) IdentifyVariants(jobName string) []string {
+	// rehearsals aren't in the registry, so take the variants of the job being rehearsed
+	if rehearsed, ok := RehearsedJob(jobName); ok {
+		return append(v.IdentifyVariants(rehearsed), Rehearsal)
+	}
+
 	allVariants := v.jobVariants[jobName]
 	if v.IsJobNeverStable(jobName) {
 		allVariants = append(allVariants, NeverStable)
@@ -113,34 +113,6 @@ var customJobInstallNames = sets.NewString(
 	"vsphere-upi-upi-install-vsphere",
 )
 
-// TODO We should instead try to detect whether we fail in a pre-step to determine whether install succeeded
-// Install steps have different names in different jobs. This is heavily dependent on the actual UPI jobs, but they turn out to be different.
-// When this needs updating,  it shows up as installs timing out in weird numbers
-func IsInstallStepEquivalent(testName string) bool {
-	if strings.Contains(testName, NewInstallTestName) {
-		return true
-	}
-	for installName := range customJobInstallNames {
-		if strings.Contains(testName, installName) {
-			return true
-		}
-	}
-
-	if strings.HasSuffix(testName, "container setup") {
-		return true
-	}
-
-	//  kube uses this to mean the installation worked.  It's not perfectly analogous, but it's close.
-	if testName == "Up" {
-		return true
-	}
-	if strings.HasSuffix(testName, "create-cluster") {
-		return true
-	}
-
-	return false
-}
-
 var (
 	cvoAcknowledgesUpgradeRegex = regexp.MustCompile(`^(Cluster upgrade\.)?\[sig-cluster-lifecycle\] Cluster version operator acknowledges upgrade$`)
 	CVOAcknowledgesUpgradeTest  = "[sig-cluster-lifecycle] Cluster version operator acknowledges upgrade"
@@ -153,6 +125,24 @@ var (
 	ignoreTestRegex             = regexp.MustCompile(`^$|Run multi-stage test|operator.Import the release payload|operator.Import a release payload|operator.Run template|operator.Build image|Monitor cluster while tests execute|Overall|job.initialize|\[sig-arch\]\[Feature:ClusterUpgrade\] Cluster should remain functional during upgrade`)
 )
 
+// featureAnnotationRegex matches the feature annotations in test names, i.e. [Feature:NetworkPolicy],
+// [FeatureGate:GatewayAPI] and [OCPFeatureGate:AdminNetworkPolicy].
+var featureAnnotationRegex = regexp.MustCompile(`\[((?:OCP)?Feature(?:Gate)?:[^\]]+)\]`)
+
+// FeatureAnnotations returns the feature annotations in a test name without brackets, de-duplicated and in
+// the order they appear.
+func FeatureAnnotations(testName string) []string {
+	var features []string
+	seen := sets.NewString()
+	for _, m := range featureAnnotationRegex.FindAllStringSubmatch(testName, -1) {
+		if !seen.Has(m[1]) {
+			seen.Insert(m[1])
+			features = append(features, m[1])
+		}
+	}
+	return features
+}
+
 func IsOldInstallOperatorTest(testName string) bool {
 	return OperatorConditionsTestCaseName.MatchString(testName)
 }
@@ -170,31 +160,6 @@ func IsOldUpgradeOperatorTest(testName string) bool {
 	return strings.HasPrefix(testName, OperatorUpgradePrefix)
 }
 
-func IsOperatorHealthTest(testName string) bool {
-	if strings.HasPrefix(testName, OperatorUpgradePrefix) {
-		return true
-	}
-	if OperatorConditionsTestCaseName.MatchString(testName) {
-		return true
-	}
-	if strings.HasPrefix(testName, OperatorFinalHealthPrefix) {
-		return true
-	}
-	return false
-}
-
-func IsUpgradeStartedTest(testName string) bool {
-	return cvoAcknowledgesUpgradeRegex.MatchString(testName)
-}
-
-func IsOperatorsUpgradedTest(testName string) bool {
-	return operatorsUpgradedRegex.MatchString(testName)
-}
-
-func IsMachineConfigPoolsUpgradedTest(testName string) bool {
-	return machineConfigsUpgradedRegex.MatchString(testName)
-}
-
 func IsOpenShiftTest(testName string) bool {
 	return openshiftTestsRegex.MatchString(testName)
 }
@@ -206,19 +171,6 @@ func GetOperatorFromUpgradeTest(testName string) string {
 	return testName[len(OperatorUpgradePrefix):]
 }
 
-func GetOperatorNameFromTest(testName string) string {
-	if IsOldUpgradeOperatorTest(testName) {
-		return GetOperatorFromUpgradeTest(testName)
-	}
-	if IsOldInstallOperatorTest(testName) {
-		return GetOperatorFromInstallTest(testName)
-	}
-	if strings.HasPrefix(testName, OperatorFinalHealthPrefix) {
-		return testName[len(OperatorFinalHealthPrefix):]
-	}
-	return ""
-}
-
 // IsIgnoredTest is used to strip out tests that don't have predictive or diagnostic value.  We don't want to show these in our data.
 func IsIgnoredTest(testName string) bool {
 	return ignoreTestRegex.MatchString(testName)
@@ -1,6 +1,10 @@
 package testidentification
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestIsIgnoredTest(t *testing.T) {
 	tests := []struct {
@@ -28,3 +32,11 @@ func TestIsIgnoredTest(t *testing.T) {
 		})
 	}
 }
+
+func TestFeatureAnnotations(t *testing.T) {
+	assert.Equal(t,
+		[]string{"Feature:NetworkPolicy", "OCPFeatureGate:AdminNetworkPolicy"},
+		FeatureAnnotations("[sig-network][Feature:NetworkPolicy][OCPFeatureGate:AdminNetworkPolicy] policy should apply [Feature:NetworkPolicy] [Suite:openshift/conformance/parallel]"))
+	assert.Equal(t, []string{"FeatureGate:GatewayAPI"}, FeatureAnnotations("[sig-network][FeatureGate:GatewayAPI] Gateway should route"))
+	assert.Empty(t, FeatureAnnotations("[sig-storage] CSI volumes should mount [Serial]"))
+}
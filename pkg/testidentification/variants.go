@@ -1,9 +1,40 @@
 package testidentification
 
 import (
+	"regexp"
+
+	"github.com/openshift/sippy/pkg/db/models"
 	"github.com/openshift/sippy/pkg/util/sets"
 )
 
+// Rehearsal is the variant of rehearse-* presubmits, which run a periodic against a pull request to the CI
+// configuration. They are tagged so their results, which often reflect an untested configuration change, are kept
+// out of periodic job and test statistics.
+const Rehearsal = models.RehearsalVariant
+
+var rehearsalRegex = regexp.MustCompile(`^rehearse-[0-9]+-(.+)$`)
+
+// IsRehearsal returns true if the variants are those of a rehearse-* run of another job.
+func IsRehearsal(variants []string) bool {
+	for _, variant := range variants {
+		if variant == Rehearsal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RehearsedJob returns the name of the job a rehearse-<pr>-<job> run is rehearsing, and false if it isn't a
+// rehearsal.
+func RehearsedJob(jobName string) (string, bool) {
+	m := rehearsalRegex.FindStringSubmatch(jobName)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
 // VariantManager identifies and describes different variants
 type VariantManager interface {
 	// AllPlatforms returns a set of all known platform variants
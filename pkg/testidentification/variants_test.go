@@ -0,0 +1,36 @@
+package testidentification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/util/sets"
+)
+
+func TestRehearsalVariants(t *testing.T) {
+	periodic := "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"
+	rehearsal := "rehearse-51234-" + periodic
+
+	rehearsed, ok := RehearsedJob(rehearsal)
+	assert.True(t, ok)
+	assert.Equal(t, periodic, rehearsed)
+	_, ok = RehearsedJob(periodic)
+	assert.False(t, ok)
+	_, ok = RehearsedJob("rehearse-e2e-aws")
+	assert.False(t, ok, "rehearsals are numbered by pull request")
+
+	mgr := &openshiftVariants{
+		jobVariants:   map[string][]string{periodic: {"Platform:aws", "Network:ovn", "Owner:eng"}},
+		variantValues: map[string]sets.String{},
+	}
+	assert.Equal(t, []string{"Platform:aws", "Network:ovn"}, mgr.IdentifyVariants(periodic))
+	assert.Equal(t, []string{"Platform:aws", "Network:ovn", Rehearsal}, mgr.IdentifyVariants(rehearsal),
+		"rehearsals take the rehearsed job's variants")
+
+	assert.Equal(t, []string{Rehearsal}, NewEmptyVariantManager().IdentifyVariants(rehearsal))
+	assert.Empty(t, NewEmptyVariantManager().IdentifyVariants(periodic))
+
+	assert.True(t, IsRehearsal(mgr.IdentifyVariants(rehearsal)))
+	assert.False(t, IsRehearsal(mgr.IdentifyVariants(periodic)))
+}
@@ -0,0 +1,75 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	// ErrMemoryBudgetExceeded is returned when a reservation would take a MemoryBudget over its limit.
+	ErrMemoryBudgetExceeded = errors.New("memory budget exceeded")
+	// ErrSharedMemoryBudgetExceeded is returned when a reservation would take a budget's parent over its limit, so
+	// may succeed once other budgets charged to it are closed. It wraps ErrMemoryBudgetExceeded.
+	ErrSharedMemoryBudgetExceeded = fmt.Errorf("shared %w", ErrMemoryBudgetExceeded)
+)
+
+// MemoryBudget accounts for the approximate memory held while building a large result, so oversized requests
+// fail instead of running the process out of memory. A budget can have a parent, typically shared by all
+// requests, which every reservation is also charged to. A nil budget or a limit of 0 is unlimited.
+type MemoryBudget struct {
+	limit  int64
+	used   int64
+	parent *MemoryBudget
+}
+
+// NewMemoryBudget returns a budget of limit bytes, charged to parent as well if it's not nil.
+func NewMemoryBudget(limit int64, parent *MemoryBudget) *MemoryBudget {
+	return &MemoryBudget{limit: limit, parent: parent}
+}
+
+// Reserve accounts for n more bytes, or returns ErrMemoryBudgetExceeded without reserving anything if the budget
+// doesn't have room, or ErrSharedMemoryBudgetExceeded if its parent doesn't.
+func (b *MemoryBudget) Reserve(n int64) error {
+	return b.reserve(n, ErrMemoryBudgetExceeded)
+}
+
+func (b *MemoryBudget) reserve(n int64, exceeded error) error {
+	if b == nil {
+		return nil
+	}
+	if err := b.parent.reserve(n, ErrSharedMemoryBudgetExceeded); err != nil {
+		return err
+	}
+	used := atomic.AddInt64(&b.used, n)
+	if b.limit > 0 && used > b.limit {
+		atomic.AddInt64(&b.used, -n)
+		b.parent.release(n)
+		return fmt.Errorf("%w: %d of %d bytes used", exceeded, used-n, b.limit)
+	}
+	return nil
+}
+
+// Used returns the bytes currently reserved.
+func (b *MemoryBudget) Used() int64 {
+	if b == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&b.used)
+}
+
+// Close releases everything reserved from the parent, once the result the budget accounted for is no longer held.
+func (b *MemoryBudget) Close() {
+	if b == nil {
+		return
+	}
+	b.parent.release(atomic.SwapInt64(&b.used, 0))
+}
+
+func (b *MemoryBudget) release(n int64) {
+	if b == nil {
+		return
+	}
+	atomic.AddInt64(&b.used, -n)
+	b.parent.release(n)
+}
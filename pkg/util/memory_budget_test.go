@@ -0,0 +1,35 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	total := NewMemoryBudget(150, nil)
+	first := NewMemoryBudget(100, total)
+	second := NewMemoryBudget(100, total)
+
+	assert.NoError(t, first.Reserve(80))
+	err := first.Reserve(30)
+	assert.ErrorIs(t, err, ErrMemoryBudgetExceeded)
+	assert.NotErrorIs(t, err, ErrSharedMemoryBudgetExceeded)
+	assert.Equal(t, int64(80), first.Used())
+	assert.Equal(t, int64(80), total.Used())
+
+	// the shared budget runs out before the second request's own
+	err = second.Reserve(80)
+	assert.ErrorIs(t, err, ErrMemoryBudgetExceeded)
+	assert.ErrorIs(t, err, ErrSharedMemoryBudgetExceeded)
+	assert.Zero(t, second.Used())
+	assert.Equal(t, int64(80), total.Used())
+
+	first.Close()
+	assert.Zero(t, total.Used())
+	assert.NoError(t, second.Reserve(80))
+
+	var unlimited *MemoryBudget
+	assert.NoError(t, unlimited.Reserve(1<<40))
+	assert.NoError(t, NewMemoryBudget(0, nil).Reserve(1<<40))
+}
@@ -6,6 +6,7 @@ import (
 	gourl "net/url"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/openshift/sippy/pkg/dataloader/releaseloader"
@@ -71,6 +72,38 @@ func ConvertNaNToZero(f float64) float64 {
 	return f
 }
 
+// PreviousRelease returns the release before an OpenShift release, i.e. 4.15 for 4.16.
+func PreviousRelease(release string) (string, error) {
+	var major, minor int
+	if _, err := fmt.Sscanf(release, "%d.%d", &major, &minor); err != nil || fmt.Sprintf("%d.%d", major, minor) != release {
+		return "", fmt.Errorf("invalid release %q", release)
+	}
+	if minor == 0 {
+		return "", fmt.Errorf("unable to determine release before %q", release)
+	}
+	return fmt.Sprintf("%d.%d", major, minor-1), nil
+}
+
+// preBranchNames are the development branches jobs run against before a release branch is cut.
+var preBranchNames = []string{"master", "main"}
+
+// PreBranchJobNames returns the names a release branch job had before the branch was cut, when it ran against the
+// development branch, i.e. pull-ci-openshift-origin-master-e2e-aws for pull-ci-openshift-origin-release-4.16-e2e-aws
+// in 4.16. Both are one logical job in the release's reports, so its history doesn't reset at branch cut. Jobs not
+// run against the release's branch have no earlier names.
+func PreBranchJobNames(jobName, release string) []string {
+	branch := "-release-" + release + "-"
+	i := strings.Index(jobName, branch)
+	if release == "" || i < 0 {
+		return nil
+	}
+	names := make([]string, 0, len(preBranchNames))
+	for _, pre := range preBranchNames {
+		names = append(names, jobName[:i]+"-"+pre+"-"+jobName[i+len(branch):])
+	}
+	return names
+}
+
 func URLForJob(dashboard, jobName string) *gourl.URL {
 	url := &gourl.URL{
 		Scheme: "https",
@@ -110,3 +110,38 @@ func TestParseCRReleaseTime(t *testing.T) {
 		})
 	}
 }
+
+func TestPreviousRelease(t *testing.T) {
+	tests := []struct {
+		release     string
+		expected    string
+		expectedErr bool
+	}{
+		{release: "4.16", expected: "4.15"},
+		{release: "4.10", expected: "4.9"},
+		{release: "5.1", expected: "5.0"},
+		{release: "4.0", expectedErr: true},
+		{release: "Presubmits", expectedErr: true},
+		{release: "4.16-okd", expectedErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.release, func(t *testing.T) {
+			prev, err := PreviousRelease(tt.release)
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, prev)
+		})
+	}
+}
+
+func TestPreBranchJobNames(t *testing.T) {
+	assert.Equal(t, []string{"pull-ci-openshift-origin-master-e2e-aws", "pull-ci-openshift-origin-main-e2e-aws"},
+		PreBranchJobNames("pull-ci-openshift-origin-release-4.16-e2e-aws", "4.16"))
+	// Another release's branch, or the development branch itself
+	assert.Empty(t, PreBranchJobNames("pull-ci-openshift-origin-release-4.15-e2e-aws", "4.16"))
+	assert.Empty(t, PreBranchJobNames("pull-ci-openshift-origin-master-e2e-aws", "4.16"))
+	assert.Empty(t, PreBranchJobNames("periodic-ci-openshift-release-master-nightly-4.16-e2e-aws", "4.16"))
+}
@@ -0,0 +1,106 @@
+package variantregistry
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	auditInsert = "insert"
+	auditUpdate = "update"
+	auditDelete = "delete"
+)
+
+// auditRow records a change the loader made to the registry. Inserts have no old value, and deletes no new one.
+type auditRow struct {
+	JobName     string    `bigquery:"job_name"`
+	VariantName string    `bigquery:"variant_name"`
+	Action      string    `bigquery:"action"`
+	OldValue    string    `bigquery:"old_value"`
+	NewValue    string    `bigquery:"new_value"`
+	Actor       string    `bigquery:"actor"`
+	Reason      string    `bigquery:"reason"`
+	ChangedAt   time.Time `bigquery:"changed_at"`
+}
+
+// EnableAudit appends every insert, update and delete the loader makes to the registry to an audit table in the
+// same dataset, recording the actor running the sync and the reason for it, i.e. the variant rules revision synced.
+func (s *JobVariantsLoader) EnableAudit(table, actor, reason string) {
+	s.auditTable = table
+	s.auditActor = actor
+	s.auditReason = reason
+}
+
+// variantAuditRows returns the audit rows for variants inserted, updated or deleted, taking old values from the
+// variants current before the sync.
+func variantAuditRows(action string, variants []jobVariant, currentVariants map[string]map[string]string) []auditRow {
+	rows := make([]auditRow, 0, len(variants))
+	for _, jv := range variants {
+		row := auditRow{JobName: jv.JobName, VariantName: jv.VariantName, Action: action}
+		switch action {
+		case auditInsert:
+			row.NewValue = jv.VariantValue
+		case auditUpdate:
+			row.OldValue = currentVariants[jv.JobName][jv.VariantName]
+			row.NewValue = jv.VariantValue
+		case auditDelete:
+			row.OldValue = jv.VariantValue
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// deletedJobAuditRows returns an audit row deleting each variant of jobs removed from the registry.
+func deletedJobAuditRows(jobs []string, currentVariants map[string]map[string]string) []auditRow {
+	var rows []auditRow
+	for _, job := range jobs {
+		names := make([]string, 0, len(currentVariants[job]))
+		for name := range currentVariants[job] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rows = append(rows, auditRow{
+				JobName: job, VariantName: name, Action: auditDelete, OldValue: currentVariants[job][name],
+			})
+		}
+	}
+	return rows
+}
+
+// recordAudit audits changes once they have been made to the registry. Changes from a batch that failed aren't
+// audited, as which of them were made isn't known.
+func (s *JobVariantsLoader) recordAudit(rows []auditRow) {
+	if err := s.audit(context.TODO(), rows); err != nil {
+		log.WithError(err).Error("error recording variant changes")
+		s.errors = append(s.errors, err)
+	}
+}
+
+// audit appends changes made to the registry to the audit table, if auditing is enabled.
+func (s *JobVariantsLoader) audit(ctx context.Context, rows []auditRow) error {
+	if s.auditTable == "" || len(rows) == 0 {
+		return nil
+	}
+
+	changedAt := time.Now().UTC()
+	for i := range rows {
+		rows[i].Actor = s.auditActor
+		rows[i].Reason = s.auditReason
+		rows[i].ChangedAt = changedAt
+	}
+
+	inserter := s.bqClient.Dataset(s.bigQueryDataSet).Table(s.auditTable).Inserter()
+	for _, batch := range batches(rows, 500) {
+		if err := inserter.Put(ctx, batch); err != nil {
+			return errors.Wrap(err, "error appending to variant audit table")
+		}
+	}
+	log.Infof("recorded %d variant changes in %s", len(rows), s.auditTable)
+	return nil
+}
@@ -0,0 +1,58 @@
+package variantregistry
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bqclient "github.com/openshift/sippy/pkg/bigquery"
+)
+
+func TestVariantAuditRows(t *testing.T) {
+	current := map[string]map[string]string{
+		"job1": {"Platform": "aws", "Owner": "eng"},
+	}
+
+	assert.Equal(t, []auditRow{
+		{JobName: "job2", VariantName: "Platform", Action: auditInsert, NewValue: "gcp"},
+	}, variantAuditRows(auditInsert, []jobVariant{{JobName: "job2", VariantName: "Platform", VariantValue: "gcp"}}, current))
+
+	assert.Equal(t, []auditRow{
+		{JobName: "job1", VariantName: "Platform", Action: auditUpdate, OldValue: "aws", NewValue: "metal"},
+	}, variantAuditRows(auditUpdate, []jobVariant{{JobName: "job1", VariantName: "Platform", VariantValue: "metal"}}, current))
+
+	assert.Equal(t, []auditRow{
+		{JobName: "job1", VariantName: "Owner", Action: auditDelete, OldValue: "eng"},
+	}, variantAuditRows(auditDelete, []jobVariant{{JobName: "job1", VariantName: "Owner", VariantValue: "eng"}}, current))
+}
+
+func TestDeletedJobAuditRows(t *testing.T) {
+	current := map[string]map[string]string{
+		"job1": {"Platform": "aws", "Owner": "eng"},
+		"job2": {"Platform": "gcp"},
+	}
+
+	assert.Equal(t, []auditRow{
+		{JobName: "job1", VariantName: "Owner", Action: auditDelete, OldValue: "eng"},
+		{JobName: "job1", VariantName: "Platform", Action: auditDelete, OldValue: "aws"},
+	}, deletedJobAuditRows([]string{"job1"}, current))
+	assert.Empty(t, deletedJobAuditRows(nil, current))
+}
+
+func TestAuditDisabled(t *testing.T) {
+	s := &JobVariantsLoader{}
+	assert.NoError(t, s.audit(context.Background(), []auditRow{{JobName: "job1"}}),
+		"nothing is written without an audit table")
+}
+
+func TestAuditSchemaMatchesRow(t *testing.T) {
+	expected, ok := bqclient.ExpectedSchema(bqclient.JobVariantsAuditTable)
+	require.True(t, ok)
+
+	inferred, err := bigquery.InferSchema(auditRow{})
+	require.NoError(t, err)
+	assert.Empty(t, bqclient.DiffSchema(expected, inferred))
+}
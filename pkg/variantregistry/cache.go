@@ -0,0 +1,49 @@
+package variantregistry
+
+import (
+	"sync"
+)
+
+// currentVariantsCache holds snapshots of the registry tables read during this process, keyed by the fully
+// qualified table name, so several syncs or validation passes against the same table only scan it once. Anything
+// writing to a table must invalidate its snapshot.
+var currentVariantsCache = &variantsCache{snapshots: map[string]map[string]map[string]string{}}
+
+type variantsCache struct {
+	lock      sync.Mutex
+	snapshots map[string]map[string]map[string]string
+}
+
+// get returns a copy of the snapshot of table, so callers are free to modify it.
+func (c *variantsCache) get(table string) (map[string]map[string]string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	snapshot, ok := c.snapshots[table]
+	if !ok {
+		return nil, false
+	}
+	return copyVariants(snapshot), true
+}
+
+func (c *variantsCache) set(table string, variants map[string]map[string]string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.snapshots[table] = copyVariants(variants)
+}
+
+func (c *variantsCache) invalidate(table string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.snapshots, table)
+}
+
+func copyVariants(variants map[string]map[string]string) map[string]map[string]string {
+	c := make(map[string]map[string]string, len(variants))
+	for job, jobVariants := range variants {
+		c[job] = make(map[string]string, len(jobVariants))
+		for k, v := range jobVariants {
+			c[job][k] = v
+		}
+	}
+	return c
+}
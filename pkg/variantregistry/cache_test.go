@@ -0,0 +1,33 @@
+package variantregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariantsCache(t *testing.T) {
+	c := &variantsCache{snapshots: map[string]map[string]map[string]string{}}
+
+	_, ok := c.get("project.dataset.job_variants")
+	assert.False(t, ok)
+
+	variants := map[string]map[string]string{"job1": {"Platform": "aws"}}
+	c.set("project.dataset.job_variants", variants)
+	variants["job1"]["Platform"] = "gcp"
+
+	got, ok := c.get("project.dataset.job_variants")
+	assert.True(t, ok)
+	assert.Equal(t, map[string]map[string]string{"job1": {"Platform": "aws"}}, got, "snapshot should not share maps with the caller")
+
+	got["job1"]["Platform"] = "metal"
+	got, _ = c.get("project.dataset.job_variants")
+	assert.Equal(t, "aws", got["job1"]["Platform"], "modifying a returned snapshot should not modify the cache")
+
+	_, ok = c.get("project.other.job_variants")
+	assert.False(t, ok, "snapshots are per table")
+
+	c.invalidate("project.dataset.job_variants")
+	_, ok = c.get("project.dataset.job_variants")
+	assert.False(t, ok)
+}
@@ -0,0 +1,144 @@
+package variantregistry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job changes in a diff.
+const (
+	JobAdded   = "added"
+	JobChanged = "changed"
+	JobRemoved = "removed"
+)
+
+// VariantDiff is a variant a sync would change. Added variants have no old value, and removed ones no new value.
+type VariantDiff struct {
+	Name string `json:"name"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// JobDiff is what a sync would change for a job.
+type JobDiff struct {
+	Job      string        `json:"job"`
+	Change   string        `json:"change"`
+	Variants []VariantDiff `json:"variants"`
+}
+
+// matchingLoader is a VariantLoader only listing the jobs matching a regex.
+type matchingLoader struct {
+	VariantLoader
+	match *regexp.Regexp
+}
+
+// MatchingJobs returns a loader only listing loader's jobs matching a regex, so variants are only calculated for
+// those.
+func MatchingJobs(loader VariantLoader, match *regexp.Regexp) VariantLoader {
+	return &matchingLoader{VariantLoader: loader, match: match}
+}
+
+func (m *matchingLoader) ListJobs(ctx context.Context) ([]ListedJob, error) {
+	jobs, err := m.VariantLoader.ListJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matching := make([]ListedJob, 0, len(jobs))
+	for _, job := range jobs {
+		if m.match.MatchString(job.Name) {
+			matching = append(matching, job)
+		}
+	}
+	logrus.Infof("%d of %d jobs match %s", len(matching), len(jobs), m.match)
+	return matching, nil
+}
+
+// DiffJobVariants returns what syncing expected variants into the registry would change, for jobs matching a regex,
+// or all jobs if it's nil. Jobs are sorted by name, and their variants by variant name.
+func DiffJobVariants(expectedVariants, currentVariants map[string]map[string]string, match *regexp.Regexp) []JobDiff {
+	expected, current := matchingVariants(expectedVariants, match), matchingVariants(currentVariants, match)
+	inserts, updates, deletes, deleteJobs := compareVariants(expected, current)
+
+	diffs := map[string]*JobDiff{}
+	jobDiff := func(job string) *JobDiff {
+		d, ok := diffs[job]
+		if !ok {
+			d = &JobDiff{Job: job, Change: JobChanged}
+			if _, ok := current[job]; !ok {
+				d.Change = JobAdded
+			}
+			diffs[job] = d
+		}
+		return d
+	}
+	for _, jv := range inserts {
+		d := jobDiff(jv.JobName)
+		d.Variants = append(d.Variants, VariantDiff{Name: jv.VariantName, New: jv.VariantValue})
+	}
+	for _, jv := range updates {
+		d := jobDiff(jv.JobName)
+		d.Variants = append(d.Variants, VariantDiff{
+			Name: jv.VariantName, Old: current[jv.JobName][jv.VariantName], New: jv.VariantValue,
+		})
+	}
+	for _, jv := range deletes {
+		d := jobDiff(jv.JobName)
+		d.Variants = append(d.Variants, VariantDiff{Name: jv.VariantName, Old: jv.VariantValue})
+	}
+	for _, job := range deleteJobs {
+		d := &JobDiff{Job: job, Change: JobRemoved}
+		for name, value := range current[job] {
+			d.Variants = append(d.Variants, VariantDiff{Name: name, Old: value})
+		}
+		diffs[job] = d
+	}
+
+	result := make([]JobDiff, 0, len(diffs))
+	for _, d := range diffs {
+		sort.Slice(d.Variants, func(i, j int) bool { return d.Variants[i].Name < d.Variants[j].Name })
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Job < result[j].Job })
+	return result
+}
+
+func matchingVariants(variants map[string]map[string]string, match *regexp.Regexp) map[string]map[string]string {
+	if match == nil {
+		return variants
+	}
+	matching := map[string]map[string]string{}
+	for job, jobVariants := range variants {
+		if match.MatchString(job) {
+			matching[job] = jobVariants
+		}
+	}
+	return matching
+}
+
+// WriteJobDiffs writes diffs in the style of a unified diff: each job with its change, then its variants' old values
+// prefixed with - and new values with +.
+func WriteJobDiffs(w io.Writer, diffs []JobDiff) error {
+	for _, d := range diffs {
+		if _, err := fmt.Fprintf(w, "%s (%s)\n", d.Job, d.Change); err != nil {
+			return err
+		}
+		for _, v := range d.Variants {
+			if v.Old != "" {
+				if _, err := fmt.Fprintf(w, "  - %s: %s\n", v.Name, v.Old); err != nil {
+					return err
+				}
+			}
+			if v.New != "" {
+				if _, err := fmt.Fprintf(w, "  + %s: %s\n", v.Name, v.New); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
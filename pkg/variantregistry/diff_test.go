@@ -0,0 +1,86 @@
+package variantregistry
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type listedJobsLoader []string
+
+func (l listedJobsLoader) ListJobs(ctx context.Context) ([]ListedJob, error) {
+	jobs := make([]ListedJob, 0, len(l))
+	for _, name := range l {
+		jobs = append(jobs, ListedJob{Name: name})
+	}
+	return jobs, nil
+}
+
+func (l listedJobsLoader) CalculateVariantsForJob(ctx context.Context, jLog logrus.FieldLogger, job ListedJob) (map[string]string, error) {
+	return map[string]string{"Platform": "aws"}, nil
+}
+
+func TestMatchingJobs(t *testing.T) {
+	loader := MatchingJobs(listedJobsLoader{"e2e-aws-4.16", "e2e-gcp-4.16", "e2e-aws-4.15"}, regexp.MustCompile(`-4\.16$`))
+	expected, err := LoadExpectedJobVariants(context.Background(), loader)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"e2e-aws-4.16": {"Platform": "aws"},
+		"e2e-gcp-4.16": {"Platform": "aws"},
+	}, expected)
+}
+
+func TestDiffJobVariants(t *testing.T) {
+	expected := map[string]map[string]string{
+		"e2e-aws-4.16":       {"Platform": "aws", "Network": "ovn"},
+		"e2e-gcp-4.16":       {"Platform": "gcp", "Owner": "eng"},
+		"e2e-metal-4.16":     {"Platform": "metal"},
+		"e2e-unchanged-4.16": {"Platform": "aws"},
+	}
+	current := map[string]map[string]string{
+		"e2e-aws-4.16":       {"Platform": "aws", "Network": "sdn", "Topology": "ha"},
+		"e2e-gcp-4.16":       {"Platform": "gcp"},
+		"e2e-vsphere-4.16":   {"Platform": "vsphere", "Owner": "eng"},
+		"e2e-unchanged-4.16": {"Platform": "aws"},
+		"e2e-aws-4.15":       {"Platform": "aws"},
+	}
+
+	diffs := DiffJobVariants(expected, current, regexp.MustCompile(`-4\.16$`))
+	assert.Equal(t, []JobDiff{
+		{Job: "e2e-aws-4.16", Change: JobChanged, Variants: []VariantDiff{
+			{Name: "Network", Old: "sdn", New: "ovn"},
+			{Name: "Topology", Old: "ha"},
+		}},
+		{Job: "e2e-gcp-4.16", Change: JobChanged, Variants: []VariantDiff{{Name: "Owner", New: "eng"}}},
+		{Job: "e2e-metal-4.16", Change: JobAdded, Variants: []VariantDiff{{Name: "Platform", New: "metal"}}},
+		{Job: "e2e-vsphere-4.16", Change: JobRemoved, Variants: []VariantDiff{
+			{Name: "Owner", Old: "eng"},
+			{Name: "Platform", Old: "vsphere"},
+		}},
+	}, diffs, "jobs not matching, like e2e-aws-4.15, are left out rather than removed")
+
+	assert.Len(t, DiffJobVariants(expected, current, nil), 5, "without a regex, e2e-aws-4.15 would be removed")
+}
+
+func TestWriteJobDiffs(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJobDiffs(&buf, []JobDiff{
+		{Job: "e2e-aws-4.16", Change: JobChanged, Variants: []VariantDiff{
+			{Name: "Network", Old: "sdn", New: "ovn"},
+			{Name: "Topology", Old: "ha"},
+		}},
+		{Job: "e2e-metal-4.16", Change: JobAdded, Variants: []VariantDiff{{Name: "Platform", New: "metal"}}},
+	}))
+	assert.Equal(t, `e2e-aws-4.16 (changed)
+  - Network: sdn
+  + Network: ovn
+  - Topology: ha
+e2e-metal-4.16 (added)
+  + Platform: metal
+`, buf.String())
+}
@@ -0,0 +1,94 @@
+package variantregistry
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// JobExclusions are patterns of job names kept out of the variant registry, such as rehearsals or private jobs,
+// along with the jobs each pattern has excluded. A nil JobExclusions excludes nothing.
+type JobExclusions struct {
+	patterns []*regexp.Regexp
+	matched  map[string][]string
+}
+
+// JobExclusionMatch is the jobs excluded by one pattern.
+type JobExclusionMatch struct {
+	Pattern string
+	Jobs    []string
+}
+
+// NewJobExclusions compiles the given job name regexes.
+func NewJobExclusions(patterns []string) (*JobExclusions, error) {
+	e := &JobExclusions{matched: map[string][]string{}}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid job exclusion %q", p)
+		}
+		e.patterns = append(e.patterns, re)
+	}
+	return e, nil
+}
+
+// ReadJobExclusions reads job name regexes one per line, ignoring blank lines and lines starting with #.
+func ReadJobExclusions(r io.Reader) (*JobExclusions, error) {
+	var patterns []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewJobExclusions(patterns)
+}
+
+// LoadJobExclusions reads job exclusions from a file, see ReadJobExclusions.
+func LoadJobExclusions(path string) (*JobExclusions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadJobExclusions(f)
+}
+
+// Excluded returns true if the job matches an exclusion, recording the match against the first pattern matching it.
+func (e *JobExclusions) Excluded(jobName string) bool {
+	if e == nil {
+		return false
+	}
+	for _, re := range e.patterns {
+		if re.MatchString(jobName) {
+			e.matched[re.String()] = append(e.matched[re.String()], jobName)
+			return true
+		}
+	}
+	return false
+}
+
+// Matches returns the jobs excluded by each pattern so far, in pattern order. Patterns that excluded nothing are
+// included, as they may no longer be needed.
+func (e *JobExclusions) Matches() []JobExclusionMatch {
+	if e == nil {
+		return nil
+	}
+	matches := make([]JobExclusionMatch, 0, len(e.patterns))
+	for _, re := range e.patterns {
+		jobs := append([]string{}, e.matched[re.String()]...)
+		sort.Strings(jobs)
+		matches = append(matches, JobExclusionMatch{Pattern: re.String(), Jobs: jobs})
+	}
+	return matches
+}
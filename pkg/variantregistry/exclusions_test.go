@@ -0,0 +1,37 @@
+package variantregistry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobExclusions(t *testing.T) {
+	exclusions, err := ReadJobExclusions(strings.NewReader(`
+# rehearsals of config changes
+^rehearse-
+
+-priv-
+-unused-$
+`))
+	require.NoError(t, err)
+
+	assert.True(t, exclusions.Excluded("rehearse-12345-periodic-ci-openshift-release-master-nightly-4.16-e2e-aws"))
+	assert.True(t, exclusions.Excluded("periodic-ci-openshift-priv-release-master-nightly-4.16-e2e-aws"))
+	assert.False(t, exclusions.Excluded("periodic-ci-openshift-release-master-nightly-4.16-e2e-aws"))
+
+	assert.Equal(t, []JobExclusionMatch{
+		{Pattern: "^rehearse-", Jobs: []string{"rehearse-12345-periodic-ci-openshift-release-master-nightly-4.16-e2e-aws"}},
+		{Pattern: "-priv-", Jobs: []string{"periodic-ci-openshift-priv-release-master-nightly-4.16-e2e-aws"}},
+		{Pattern: "-unused-$", Jobs: []string{}},
+	}, exclusions.Matches())
+
+	var none *JobExclusions
+	assert.False(t, none.Excluded("rehearse-12345"))
+	assert.Nil(t, none.Matches())
+
+	_, err = NewJobExclusions([]string{"("})
+	assert.Error(t, err)
+}
@@ -0,0 +1,73 @@
+package variantregistry
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+)
+
+var (
+	// ci-operator jobs set their steps' environment in the test configuration, which is embedded in the job's
+	// environment (i.e. UNRESOLVED_CONFIG) as YAML, so these match both FEATURE_SET=X and FEATURE_SET: X.
+	featureSetSpecRegex = regexp.MustCompile(`(?i)feature[_-]set["']?\s*[:=]\s*["']?([a-z]+)`)
+	realtimeSpecRegex   = regexp.MustCompile(`(?i)rt[_-]enabled["']?\s*[:=]\s*["']?true`)
+)
+
+// featureSetValues maps the cluster feature sets jobs install with to FeatureSet variant values.
+var featureSetValues = map[string]string{
+	"techpreviewnoupgrade": "techpreview",
+	"devpreviewnoupgrade":  "devpreview",
+	"customnoupgrade":      "custom",
+}
+
+// JobSpecVariants returns the FeatureSet and Scheduler variants a prow job's container arguments and environment
+// configure, for jobs whose names don't say so. Variants the spec doesn't configure are left out.
+func JobSpecVariants(pj *prow.ProwJob) map[string]string {
+	variants := map[string]string{}
+	if pj == nil || pj.Spec.PodSpec == nil {
+		return variants
+	}
+
+	var spec []string
+	for _, c := range pj.Spec.PodSpec.Containers {
+		spec = append(spec, c.Command...)
+		spec = append(spec, c.Args...)
+		for _, e := range c.Env {
+			spec = append(spec, e.Name+"="+e.Value)
+		}
+	}
+	text := strings.Join(spec, "\n")
+
+	for _, m := range featureSetSpecRegex.FindAllStringSubmatch(text, -1) {
+		if fs, ok := featureSetValues[strings.ToLower(m[1])]; ok {
+			variants[VariantFeatureSet] = fs
+			break
+		}
+	}
+	if realtimeSpecRegex.MatchString(text) {
+		variants[VariantScheduler] = "realtime"
+	}
+	return variants
+}
+
+// applyJobSpecVariants sets the variants detected from a job's spec where the job name left the default value. The
+// job name wins when both identify a non-default value.
+func applyJobSpecVariants(jLog logrus.FieldLogger, variants, specVariants map[string]string) {
+	for k, v := range specVariants {
+		current, ok := variants[k]
+		if ok && current != VariantDefaultValue {
+			if current != v {
+				jLog.WithFields(logrus.Fields{
+					"variant":  k,
+					"fromJob":  current,
+					"fromSpec": v,
+				}).Infof("variant mismatch: using %s from job name", k)
+			}
+			continue
+		}
+		variants[k] = v
+	}
+}
@@ -0,0 +1,52 @@
+package variantregistry
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/apis/prow"
+)
+
+func TestJobSpecVariants(t *testing.T) {
+	tests := []struct {
+		name      string
+		container prow.Container
+		expected  map[string]string
+	}{
+		{
+			name: "feature set and realtime in the embedded ci-operator config",
+			container: prow.Container{
+				Args: []string{"--target=e2e-aws-ovn"},
+				Env:  []prow.EnvVar{{Name: "UNRESOLVED_CONFIG", Value: "tests:\n- as: e2e-aws-ovn\n  steps:\n    env:\n      FEATURE_SET: TechPreviewNoUpgrade\n      RT_ENABLED: \"true\"\n"}},
+			},
+			expected: map[string]string{VariantFeatureSet: "techpreview", VariantScheduler: "realtime"},
+		},
+		{
+			name:      "feature set argument",
+			container: prow.Container{Args: []string{"--feature-set=DevPreviewNoUpgrade"}},
+			expected:  map[string]string{VariantFeatureSet: "devpreview"},
+		},
+		{
+			name: "default values are not variants",
+			container: prow.Container{
+				Env: []prow.EnvVar{{Name: "FEATURE_SET", Value: "Default"}, {Name: "RT_ENABLED", Value: "false"}},
+			},
+			expected: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pj := &prow.ProwJob{Spec: prow.ProwJobSpec{PodSpec: &prow.PodSpec{Containers: []prow.Container{tt.container}}}}
+			assert.Equal(t, tt.expected, JobSpecVariants(pj))
+		})
+	}
+	assert.Empty(t, JobSpecVariants(&prow.ProwJob{}))
+}
+
+func TestApplyJobSpecVariants(t *testing.T) {
+	variants := map[string]string{VariantFeatureSet: VariantDefaultValue, VariantScheduler: "realtime"}
+	applyJobSpecVariants(logrus.New(), variants, map[string]string{VariantFeatureSet: "techpreview", VariantScheduler: "other"})
+	assert.Equal(t, map[string]string{VariantFeatureSet: "techpreview", VariantScheduler: "realtime"}, variants)
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/bigquery"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"google.golang.org/api/iterator"
+
+	"github.com/openshift/sippy/pkg/dataloader"
+	"github.com/openshift/sippy/pkg/db"
 )
 
 const invalidCharacters = ",:"
@@ -28,6 +32,25 @@ type JobVariantsLoader struct {
 	bigQueryTable    string
 	expectedVariants map[string]map[string]string
 	errors           []error
+	summary          dataloader.LoaderSummary
+
+	// dbc and ownerChangeWebhookURL are optional, and used to record and notify of jobs changing owner.
+	dbc                   *db.DB
+	ownerChangeWebhookURL string
+
+	// mergeBatchSize is how many variant updates and deletes are merged into the registry with each statement.
+	mergeBatchSize int
+	// pool writes batches of inserts, merges and job deletes to the registry, retrying those that fail.
+	pool syncPool
+
+	// auditTable, auditActor and auditReason are optional, and used to record every change made to the registry.
+	auditTable  string
+	auditActor  string
+	auditReason string
+
+	// schema is optional, and used to keep values it doesn't allow out of the registry.
+	schema            *VariantSchema
+	schemaEnforcement SchemaEnforcement
 }
 
 func NewJobVariantsLoader(
@@ -36,18 +59,52 @@ func NewJobVariantsLoader(
 	bigQueryDataSet string,
 	bigQueryTable string,
 	expectedVariants map[string]map[string]string,
+	dbc *db.DB,
+	ownerChangeWebhookURL string,
 ) *JobVariantsLoader {
 
 	return &JobVariantsLoader{
-		bqClient:         bigQueryClient,
-		bigQueryProject:  bigQueryProject,
-		bigQueryDataSet:  bigQueryDataSet,
-		bigQueryTable:    bigQueryTable,
-		expectedVariants: expectedVariants,
-		errors:           []error{},
+		bqClient:              bigQueryClient,
+		bigQueryProject:       bigQueryProject,
+		bigQueryDataSet:       bigQueryDataSet,
+		bigQueryTable:         bigQueryTable,
+		expectedVariants:      expectedVariants,
+		errors:                []error{},
+		dbc:                   dbc,
+		ownerChangeWebhookURL: ownerChangeWebhookURL,
+		mergeBatchSize:        DefaultMergeBatchSize,
+		pool: syncPool{
+			concurrency: DefaultSyncConcurrency,
+			retries:     DefaultSyncRetries,
+			backoff:     DefaultSyncBackoff,
+		},
 	}
 }
 
+// SetMergeBatchSize sets how many variant updates and deletes are merged into the registry with each statement.
+func (s *JobVariantsLoader) SetMergeBatchSize(size int) {
+	s.mergeBatchSize = size
+}
+
+// SetConcurrency sets how many batches of inserts are streamed into the registry at once.
+func (s *JobVariantsLoader) SetConcurrency(concurrency int) {
+	s.pool.concurrency = concurrency
+}
+
+// SetRetries sets how many times a batch that failed to be written to the registry is retried, and the delay before
+// the first retry, which doubles on each subsequent one.
+func (s *JobVariantsLoader) SetRetries(retries int, backoff time.Duration) {
+	s.pool.retries = retries
+	s.pool.backoff = backoff
+}
+
+// SetVariantSchema validates the variants inserted or updated by a sync against a schema, failing the sync before
+// anything is written if rejecting invalid values.
+func (s *JobVariantsLoader) SetVariantSchema(schema *VariantSchema, enforcement SchemaEnforcement) {
+	s.schema = schema
+	s.schemaEnforcement = enforcement
+}
+
 func (s *JobVariantsLoader) Name() string {
 	return "job-variants"
 }
@@ -56,6 +113,11 @@ func (s *JobVariantsLoader) Errors() []error {
 	return s.errors
 }
 
+// Summary returns the number of jobs compared, and the changes made to the registry.
+func (s *JobVariantsLoader) Summary() dataloader.LoaderSummary {
+	return s.summary
+}
+
 func (s *JobVariantsLoader) Load() {
 	currentVariants, err := s.loadCurrentJobVariants()
 	if err != nil {
@@ -66,48 +128,68 @@ func (s *JobVariantsLoader) Load() {
 	log.Infof("loaded %d current jobs with variants", len(currentVariants))
 
 	inserts, updates, deletes, deleteJobs := compareVariants(s.expectedVariants, currentVariants)
+	owners := ownerChanges(s.expectedVariants, currentVariants)
+	s.summary = dataloader.LoaderSummary{
+		Counts: map[string]int{
+			"expected_jobs": len(s.expectedVariants),
+			"current_jobs":  len(currentVariants),
+		},
+		Diff: map[string]int{
+			"inserted_variants": len(inserts),
+			"updated_variants":  len(updates),
+			"deleted_variants":  len(deletes),
+			"deleted_jobs":      len(deleteJobs),
+			"owner_changes":     len(owners),
+		},
+	}
 
+	violations, err := validateJobVariants(s.schema, s.schemaEnforcement, inserts, updates)
+	s.summary.Diff["schema_violations"] = violations
+	if err != nil {
+		log.WithError(err).Error("refusing to sync job variants")
+		s.errors = append(s.errors, err)
+		return
+	}
 	if err := verifyVariants(inserts, updates); err != nil {
 		s.errors = append(s.errors, err)
 		return
 	}
+	if len(inserts)+len(updates)+len(deletes)+len(deleteJobs) > 0 {
+		// invalidated up front, so a partially applied sync is never served from the cache
+		currentVariantsCache.invalidate(s.tableName())
+	}
 
+	// Each step audits the batches it wrote, even if others failed.
+	ctx := context.TODO()
 	log.Infof("inserting %d new job variants", len(inserts))
-	err = s.bulkInsertVariants(inserts)
+	inserted, err := s.bulkInsertVariants(ctx, inserts)
 	if err != nil {
 		log.WithError(err).Error("error syncing job variants to bigquery")
 		s.errors = append(s.errors, err)
 	}
+	s.recordAudit(variantAuditRows(auditInsert, inserted, currentVariants))
 
-	log.Infof("updating %d job variants", len(updates))
-	for i, jv := range updates {
-		uLog := log.WithField("progress", fmt.Sprintf("%d/%d", i+1, len(updates)))
-		err = s.updateVariant(uLog, jv)
-		if err != nil {
-			log.WithError(err).Error("error syncing job variants to bigquery")
-			s.errors = append(s.errors, err)
-		}
-	}
-
-	// This loop is called for variants being removed from a job that is still in the system.
-	log.Infof("deleting %d job variants", len(deletes))
-	for i, jv := range deletes {
-		uLog := log.WithField("progress", fmt.Sprintf("%d/%d", i+1, len(deletes)))
-		err = s.deleteVariant(uLog, jv)
-		if err != nil {
-			log.WithError(err).Error("error syncing job variants to bigquery")
-			s.errors = append(s.errors, err)
-		}
+	// Variants being changed or removed from a job that is still in the system.
+	log.Infof("merging %d job variant updates and %d deletes", len(updates), len(deletes))
+	updated, deleted, err := s.mergeVariants(ctx, updates, deletes)
+	if err != nil {
+		log.WithError(err).Error("error syncing job variants to bigquery")
+		s.errors = append(s.errors, err)
 	}
+	s.recordAudit(append(variantAuditRows(auditUpdate, updated, currentVariants),
+		variantAuditRows(auditDelete, deleted, currentVariants)...))
 
 	// Delete jobs entirely, much faster than one variant at a time when jobs have been removed.
 	// This should be relatively rare and would require the job to not have run for weeks/months.
 	log.Infof("deleting %d jobs", len(deleteJobs))
-	err = s.deleteJobsInBatches(deleteJobs, 500)
+	deletedJobs, err := s.deleteJobsInBatches(ctx, deleteJobs, 500)
 	if err != nil {
 		log.WithError(err).Error("error deleting jobs from registry")
 		s.errors = append(s.errors, err)
 	}
+	s.recordAudit(deletedJobAuditRows(deletedJobs, currentVariants))
+
+	s.recordOwnerChanges(owners)
 }
 
 func verifyVariants(variants ...[]jobVariant) error {
@@ -186,10 +268,24 @@ func compareVariants(expectedVariants, currentVariants map[string]map[string]str
 	return insertVariants, updateVariants, deleteVariants, deleteJobs
 }
 
+func (s *JobVariantsLoader) tableName() string {
+	return fmt.Sprintf("%s.%s.%s", s.bigQueryProject, s.bigQueryDataSet, s.bigQueryTable)
+}
+
+// CurrentJobVariants returns the variants currently in the registry, keyed by job name then variant name.
+func (s *JobVariantsLoader) CurrentJobVariants() (map[string]map[string]string, error) {
+	return s.loadCurrentJobVariants()
+}
+
+// loadCurrentJobVariants returns the variants currently in the registry, from the snapshot taken earlier in this
+// process if the table hasn't been written to since.
 func (s *JobVariantsLoader) loadCurrentJobVariants() (map[string]map[string]string, error) {
-	query := s.bqClient.Query(`SELECT * FROM ` +
-		fmt.Sprintf("%s.%s.%s", s.bigQueryProject, s.bigQueryDataSet, s.bigQueryTable) +
-		` ORDER BY job_name, variant_name`)
+	if currentVariants, ok := currentVariantsCache.get(s.tableName()); ok {
+		log.Infof("using cached snapshot of %s", s.tableName())
+		return currentVariants, nil
+	}
+
+	query := s.bqClient.Query(`SELECT * FROM ` + s.tableName() + ` ORDER BY job_name, variant_name`)
 	it, err := query.Read(context.TODO())
 	if err != nil {
 		return nil, errors.Wrap(err, "error querying current job variants")
@@ -213,6 +309,7 @@ func (s *JobVariantsLoader) loadCurrentJobVariants() (map[string]map[string]stri
 		currentVariants[jv.JobName][jv.VariantName] = jv.VariantValue
 	}
 
+	currentVariantsCache.set(s.tableName(), currentVariants)
 	return currentVariants, nil
 }
 
@@ -222,86 +319,34 @@ type jobVariant struct {
 	VariantValue string `bigquery:"variant_value"`
 }
 
-// bulkInsertVariants inserts all new job variants in batches.
-func (s *JobVariantsLoader) bulkInsertVariants(inserts []jobVariant) error {
-	var batchSize = 500
-
-	table := s.bqClient.Dataset(s.bigQueryDataSet).Table(s.bigQueryTable)
-	inserter := table.Inserter()
-	for i := 0; i < len(inserts); i += batchSize {
-		end := i + batchSize
-		if end > len(inserts) {
-			end = len(inserts)
-		}
-
-		if err := inserter.Put(context.TODO(), inserts[i:end]); err != nil {
-			return err
-		}
-		log.Infof("added %d new job variant rows", end-i)
-	}
-
-	return nil
-}
-
-// updateVariant updates a job variant in the registry.
-func (s *JobVariantsLoader) updateVariant(logger log.FieldLogger, jv jobVariant) error {
-	queryStr := fmt.Sprintf("UPDATE `%s.%s.%s` SET variant_value = '%s' WHERE job_name = '%s' and variant_name = '%s'",
-		s.bigQueryProject, s.bigQueryDataSet, s.bigQueryTable, jv.VariantValue, jv.JobName, jv.VariantName)
-	insertQuery := s.bqClient.Query(queryStr)
-	_, err := insertQuery.Read(context.TODO())
-	if err != nil {
-		return errors.Wrapf(err, "error updating variants: %s", queryStr)
-	}
-	logger.Infof("successful query: %s", queryStr)
-	return nil
-}
-
-// deleteVariant deletes a job variant in the registry.
-func (s *JobVariantsLoader) deleteVariant(logger log.FieldLogger, jv jobVariant) error {
-	queryStr := fmt.Sprintf("DELETE FROM `%s.%s.%s` WHERE job_name = '%s' and variant_name = '%s' and variant_value = '%s'",
-		s.bigQueryProject, s.bigQueryDataSet, s.bigQueryTable, jv.JobName, jv.VariantName, jv.VariantValue)
-	insertQuery := s.bqClient.Query(queryStr)
-	_, err := insertQuery.Read(context.TODO())
-	if err != nil {
-		return errors.Wrapf(err, "error deleting variant: %s", queryStr)
-	}
-	logger.Infof("successful query: %s", queryStr)
-	return nil
+// bulkInsertVariants inserts all new job variants in batches, returning those inserted. Rows are inserted with their
+// job and variant name as insert ID, so BigQuery drops rows duplicated by retrying a batch that partially succeeded.
+func (s *JobVariantsLoader) bulkInsertVariants(ctx context.Context, inserts []jobVariant) ([]jobVariant, error) {
+	inserter := s.bqClient.Dataset(s.bigQueryDataSet).Table(s.bigQueryTable).Inserter()
+	done, err := runBatches(ctx, s.pool, "insert", batches(inserts, 500),
+		func(ctx context.Context, bLog log.FieldLogger, batch []jobVariant) error {
+			rows := make([]*bigquery.StructSaver, 0, len(batch))
+			for _, jv := range batch {
+				rows = append(rows, &bigquery.StructSaver{Struct: jv, InsertID: jv.JobName + "/" + jv.VariantName})
+			}
+			if err := inserter.Put(ctx, rows); err != nil {
+				return err
+			}
+			bLog.Infof("added %d new job variant rows", len(batch))
+			return nil
+		})
+	return flatten(done), err
 }
 
 // deleteJobsInBatches deletes jobs that should no longer be in the registry in batches, as one at a time can be
-// very slow.
-func (s *JobVariantsLoader) deleteJobsInBatches(deleteJobs []string, batchSize int) error {
-	numBatches := (len(deleteJobs) + batchSize - 1) / batchSize
-	for batchNum := 0; batchNum < numBatches; batchNum++ {
-		start := batchNum * batchSize
-		end := (batchNum + 1) * batchSize
-		if end > len(deleteJobs) {
-			end = len(deleteJobs)
-		}
-
-		batch := deleteJobs[start:end]
-
-		err := s.deleteJobsBatch(batch)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+// very slow, returning the jobs deleted. Batches are deleted one at a time, see syncPool.
+func (s *JobVariantsLoader) deleteJobsInBatches(ctx context.Context, deleteJobs []string, batchSize int) ([]string, error) {
+	done, err := runBatches(ctx, s.pool.serial(), "job delete", batches(deleteJobs, batchSize), s.deleteJobsBatch)
+	return flatten(done), err
 }
 
-func (s *JobVariantsLoader) deleteJobsBatch(batch []string) error {
-	log.Infof("deleting batch of %d jobs", len(batch))
-
-	queryStr := fmt.Sprintf("DELETE FROM `%s.%s.%s` WHERE job_name IN ('%s')",
-		s.bigQueryProject, s.bigQueryDataSet, s.bigQueryTable, strings.Join(batch, "','"))
-
-	insertQuery := s.bqClient.Query(queryStr)
-	_, err := insertQuery.Read(context.TODO())
-	if err != nil {
-		return errors.Wrapf(err, "error deleting batch of jobs: %s", queryStr)
-	}
-
-	log.Infof("successful batch job delete query: %s", queryStr)
-	return nil
+func (s *JobVariantsLoader) deleteJobsBatch(ctx context.Context, bLog log.FieldLogger, batch []string) error {
+	bLog.Infof("deleting batch of %d jobs", len(batch))
+	return errors.Wrap(s.exec(ctx, bLog, deleteJobsStatement(s.tableName(), batch)),
+		"error deleting batch of jobs")
 }
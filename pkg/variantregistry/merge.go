@@ -0,0 +1,147 @@
+package variantregistry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultMergeBatchSize is how many variant updates and deletes are merged into the registry with each statement.
+const DefaultMergeBatchSize = 5000
+
+// stagingTableExpiration is how long a staging table outlives the sync that created it, should the sync fail before
+// dropping it.
+const stagingTableExpiration = 24 * time.Hour
+
+const (
+	stagedUpdate = "update"
+	stagedDelete = "delete"
+)
+
+// stagedVariant is a variant update or delete staged for merging into the registry.
+type stagedVariant struct {
+	JobName      string `bigquery:"job_name" json:"job_name"`
+	VariantName  string `bigquery:"variant_name" json:"variant_name"`
+	VariantValue string `bigquery:"variant_value" json:"variant_value"`
+	Action       string `bigquery:"action" json:"action"`
+}
+
+// stageVariants returns the rows to stage for updating and deleting variants.
+func stageVariants(updates, deletes []jobVariant) []stagedVariant {
+	staged := make([]stagedVariant, 0, len(updates)+len(deletes))
+	for _, jv := range updates {
+		staged = append(staged, stagedVariant{
+			JobName: jv.JobName, VariantName: jv.VariantName, VariantValue: jv.VariantValue, Action: stagedUpdate,
+		})
+	}
+	for _, jv := range deletes {
+		staged = append(staged, stagedVariant{
+			JobName: jv.JobName, VariantName: jv.VariantName, VariantValue: jv.VariantValue, Action: stagedDelete,
+		})
+	}
+	return staged
+}
+
+// batches splits items into batches of at most size, or one batch if size is not positive.
+func batches[T any](items []T, size int) [][]T {
+	if size < 1 {
+		size = len(items)
+	}
+	var result [][]T
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+		result = append(result, items[start:end])
+	}
+	return result
+}
+
+// unstageVariants splits staged rows back into the variants updated and deleted.
+func unstageVariants(staged []stagedVariant) (updates, deletes []jobVariant) {
+	for _, sv := range staged {
+		jv := jobVariant{JobName: sv.JobName, VariantName: sv.VariantName, VariantValue: sv.VariantValue}
+		if sv.Action == stagedUpdate {
+			updates = append(updates, jv)
+		} else {
+			deletes = append(deletes, jv)
+		}
+	}
+	return updates, deletes
+}
+
+// mergeVariants updates and deletes variants in batches, staging each batch in a table and merging it into the
+// registry with one statement, returning the variants updated and deleted. One DML statement per variant takes hours
+// for a large registry, and uses up the table's DML quota. Batches are merged one at a time, see syncPool.
+func (s *JobVariantsLoader) mergeVariants(ctx context.Context, updates, deletes []jobVariant) ([]jobVariant, []jobVariant, error) {
+	staged := stageVariants(updates, deletes)
+	if len(staged) == 0 {
+		return nil, nil, nil
+	}
+
+	schema, err := bigquery.InferSchema(stagedVariant{})
+	if err != nil {
+		return nil, nil, err
+	}
+	stagingName := fmt.Sprintf("%s_staging_%d", s.bigQueryTable, time.Now().UnixNano())
+	staging := s.bqClient.Dataset(s.bigQueryDataSet).Table(stagingName)
+	if err := staging.Create(ctx, &bigquery.TableMetadata{
+		Schema:         schema,
+		ExpirationTime: time.Now().Add(stagingTableExpiration),
+	}); err != nil {
+		return nil, nil, errors.Wrap(err, "error creating variant staging table")
+	}
+	defer func() {
+		if err := staging.Delete(ctx); err != nil {
+			log.WithError(err).Warnf("error deleting variant staging table %s, it will expire", stagingName)
+		}
+	}()
+
+	stagingTable := fmt.Sprintf("%s.%s.%s", s.bigQueryProject, s.bigQueryDataSet, stagingName)
+	all := batches(staged, s.mergeBatchSize)
+	done, err := runBatches(ctx, s.pool.serial(), "merge", all, func(ctx context.Context, bLog log.FieldLogger, batch []stagedVariant) error {
+		if err := stage(ctx, staging, batch); err != nil {
+			return errors.Wrap(err, "error staging variants")
+		}
+		if err := s.exec(ctx, bLog, mergeVariantsStatement(s.tableName(), stagingTable)); err != nil {
+			return errors.Wrap(err, "error merging variants")
+		}
+		bLog.Infof("merged %d variant updates and deletes", len(batch))
+		return nil
+	})
+	updated, deleted := unstageVariants(flatten(done))
+	return updated, deleted, err
+}
+
+// stage replaces the rows of the staging table with a batch. It uses a load job, as a table with rows in the streaming
+// buffer cannot be truncated.
+func stage(ctx context.Context, staging *bigquery.Table, batch []stagedVariant) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, row := range batch {
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+	}
+
+	source := bigquery.NewReaderSource(&buf)
+	source.SourceFormat = bigquery.JSON
+	loader := staging.LoaderFrom(source)
+	loader.WriteDisposition = bigquery.WriteTruncate
+	job, err := loader.Run(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
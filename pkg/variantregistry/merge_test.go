@@ -0,0 +1,31 @@
+package variantregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStageVariants(t *testing.T) {
+	updates := []jobVariant{{JobName: "job1", VariantName: "Platform", VariantValue: "aws"}}
+	deletes := []jobVariant{{JobName: "job2", VariantName: "Owner", VariantValue: "eng"}}
+
+	assert.Equal(t, []stagedVariant{
+		{JobName: "job1", VariantName: "Platform", VariantValue: "aws", Action: stagedUpdate},
+		{JobName: "job2", VariantName: "Owner", VariantValue: "eng", Action: stagedDelete},
+	}, stageVariants(updates, deletes))
+	assert.Empty(t, stageVariants(nil, nil))
+
+	unstagedUpdates, unstagedDeletes := unstageVariants(stageVariants(updates, deletes))
+	assert.Equal(t, updates, unstagedUpdates)
+	assert.Equal(t, deletes, unstagedDeletes)
+}
+
+func TestBatches(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	assert.Equal(t, [][]int{{1, 2}, {3, 4}, {5}}, batches(items, 2))
+	assert.Equal(t, [][]int{{1, 2, 3, 4, 5}}, batches(items, 5))
+	assert.Equal(t, [][]int{{1, 2, 3, 4, 5}}, batches(items, 0), "a batch size that is not positive makes one batch")
+	assert.Empty(t, batches([]int{}, 2))
+}
@@ -2,11 +2,12 @@ package variantregistry
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"cloud.google.com/go/bigquery"
 	"cloud.google.com/go/storage"
@@ -15,6 +16,8 @@ import (
 	"github.com/sirupsen/logrus"
 	"google.golang.org/api/iterator"
 
+	"github.com/openshift/sippy/pkg/apis/prow"
+	"github.com/openshift/sippy/pkg/artifacturls"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader"
 	"github.com/openshift/sippy/pkg/dataloader/prowloader/gcs"
 )
@@ -26,6 +29,11 @@ type OCPVariantLoader struct {
 	bigQueryProject string
 	bigQueryDataSet string
 	bigQueryTable   string
+	exclusions      *JobExclusions
+	defaults        *ReleaseDefaults
+	overrides       *VariantOverrides
+	schema          *VariantSchema
+	enforcement     SchemaEnforcement
 }
 
 func NewOCPVariantLoader(
@@ -34,7 +42,12 @@ func NewOCPVariantLoader(
 	bigQueryDataSet string,
 	bigQueryTable string,
 	gcsClient *storage.Client,
-	gcsBucket string) *OCPVariantLoader {
+	gcsBucket string,
+	exclusions *JobExclusions,
+	defaults *ReleaseDefaults,
+	overrides *VariantOverrides,
+	schema *VariantSchema,
+	enforcement SchemaEnforcement) *OCPVariantLoader {
 
 	bkt := gcsClient.Bucket(gcsBucket)
 	return &OCPVariantLoader{
@@ -43,6 +56,11 @@ func NewOCPVariantLoader(
 		bigQueryProject: bigQueryProject,
 		bigQueryDataSet: bigQueryDataSet,
 		bigQueryTable:   bigQueryTable,
+		exclusions:      exclusions,
+		defaults:        defaults,
+		overrides:       overrides,
+		schema:          schema,
+		enforcement:     enforcement,
 	}
 
 }
@@ -53,12 +71,21 @@ type prowJobLastRun struct {
 	URL      bigquery.NullString `bigquery:"prowjob_url"`
 }
 
-// LoadExpectedJobVariants queries all known jobs from the gce-devel "jobs" table (actually contains job runs).
+func init() {
+	RegisterVariantLoader("ocp", func(opts VariantLoaderOptions) (VariantLoader, error) {
+		if opts.GCSClient == nil {
+			return nil, fmt.Errorf("the ocp variant loader requires a GCS client")
+		}
+		return NewOCPVariantLoader(opts.BigQueryClient, opts.BigQueryProject, opts.BigQueryDataSet, opts.BigQueryTable,
+			opts.GCSClient, opts.GCSBucket, opts.Exclusions, opts.Defaults, opts.Overrides, opts.Schema, opts.SchemaEnforcement), nil
+	})
+}
+
+// ListJobs queries all known jobs from the gce-devel "jobs" table (actually contains job runs).
 // This effectively is every job that actually ran in the last several years.
-func (v *OCPVariantLoader) LoadExpectedJobVariants(ctx context.Context) (map[string]map[string]string, error) {
-	log := logrus.WithField("func", "LoadExpectedJobVariants")
+func (v *OCPVariantLoader) ListJobs(ctx context.Context) ([]ListedJob, error) {
+	log := logrus.WithField("func", "ListJobs")
 	log.Info("loading all known jobs from bigquery for variant classification")
-	start := time.Now()
 
 	// For the primary list of all job names, we will query everything that's run in the last 6 months. Because
 	// we also try to pull cluster-data.json, we also join in a column for the prowjob_url of the most recent
@@ -103,16 +130,15 @@ ORDER BY j.prowjob_job_name;
 	log.Infof("running query for recent jobs: \n%s", queryStr)
 
 	query := v.BigQueryClient.Query(queryStr)
-	it, err := query.Read(context.TODO())
+	it, err := query.Read(ctx)
 	if err != nil {
 		return nil, errors.Wrap(err, "error querying primary list of all jobs")
 	}
 
 	// TODO: fix release on presubmits
 
-	expectedVariants := map[string]map[string]string{}
-
-	count := 0
+	jobs := []ListedJob{}
+	excluded := 0
 	for {
 		// TODO: last run but not necessarily successful, this could be a problem for cluster-data file parsing causing
 		// our churn. We can't flip the query to last success either as we wouldn't have variants for non-passing jobs at all.
@@ -126,48 +152,107 @@ ORDER BY j.prowjob_job_name;
 			log.WithError(err).Error("error parsing prowjob name from bigquery")
 			return nil, err
 		}
-		clusterData := map[string]string{}
-		jLog := log.WithField("job", jlr.JobName)
+		if v.exclusions.Excluded(jlr.JobName) {
+			log.WithField("job", jlr.JobName).Debug("job excluded from variant registry")
+			excluded++
+			continue
+		}
+		job := ListedJob{Name: jlr.JobName}
 		if jlr.URL.Valid {
-			path, err := prowloader.GetGCSPathForProwJobURL(jLog, jlr.URL.StringVal)
+			job.RunURL = jlr.URL.StringVal
+		}
+		jobs = append(jobs, job)
+	}
+	log.WithField("count", len(jobs)).WithField("excluded", excluded).Info("listed jobs")
+	for _, m := range v.exclusions.Matches() {
+		log.WithField("pattern", m.Pattern).WithField("jobs", len(m.Jobs)).Infof("excluded jobs: %s", strings.Join(m.Jobs, ", "))
+	}
+
+	return jobs, nil
+}
+
+// CalculateVariantsForJob determines a job's variants from its name, and the cluster-data.json and prowjob.json of
+// its recent run if it has one, with the loader's overrides applied, see VariantOverrides. The result is checked
+// against the loader's variant schema last, see VariantSchema.Enforce.
+func (v *OCPVariantLoader) CalculateVariantsForJob(ctx context.Context, jLog logrus.FieldLogger, job ListedJob) (map[string]string, error) {
+	clusterData := map[string]string{}
+	specVariants := map[string]string{}
+	if job.RunURL != "" {
+		path, err := prowloader.GetGCSPathForProwJobURL(jLog, job.RunURL)
+		if err != nil {
+			jLog.WithError(err).WithField("prowJobURL", job.RunURL).Error("error getting GCS path for prow job URL")
+			return nil, err
+		}
+		gcsJobRun := gcs.NewGCSJobRun(v.bkt, path)
+		allMatches := gcsJobRun.FindAllMatches([]*regexp.Regexp{gcs.GetDefaultClusterDataFile()})
+		var clusterMatches []string
+		if len(allMatches) > 0 {
+			clusterMatches = allMatches[0]
+		}
+		for _, cm := range clusterMatches {
+			// log with the file prefix for easy click/copy to browser:
+			jLog.WithField("prowJobURL", job.RunURL).Infof("Found cluster-data file: %s", artifacturls.Default().BrowseURL(cm))
+		}
+
+		if len(clusterMatches) > 0 {
+			clusterDataBytes, err := prowloader.GetClusterDataBytes(ctx, v.bkt, path, clusterMatches)
 			if err != nil {
-				jLog.WithError(err).WithField("prowJobURL", jlr.URL).Error("error getting GCS path for prow job URL")
-				return nil, err
-			}
-			gcsJobRun := gcs.NewGCSJobRun(v.bkt, path)
-			allMatches := gcsJobRun.FindAllMatches([]*regexp.Regexp{gcs.GetDefaultClusterDataFile()})
-			var clusterMatches []string
-			if len(allMatches) > 0 {
-				clusterMatches = allMatches[0]
+				jLog.WithError(err).Error("unable to read cluster data file, proceeding without")
 			}
-			for _, cm := range clusterMatches {
-				// log with the file prefix for easy click/copy to browser:
-				jLog.WithField("prowJobURL", jlr.URL.StringVal).Infof("Found cluster-data file: https://gcsweb-ci.apps.ci.l2s4.p1.openshiftapps.com/gcs/test-platform-results/%s", cm)
+			clusterData, err = prowloader.ParseVariantDataFile(clusterDataBytes)
+			if err != nil {
+				jLog.WithError(err).Error("unable to parse cluster data file, proceeding without")
+			} else {
+				jLog.Infof("loaded cluster data: %+v", clusterData)
 			}
+		}
 
-			if len(clusterMatches) > 0 {
-				clusterDataBytes, err := prowloader.GetClusterDataBytes(ctx, v.bkt, path, clusterMatches)
-				if err != nil {
-					jLog.WithError(err).Error("unable to read cluster data file, proceeding without")
-				}
-				clusterData, err = prowloader.ParseVariantDataFile(clusterDataBytes)
-				if err != nil {
-					jLog.WithError(err).Error("unable to parse cluster data file, proceeding without")
-				} else {
-					jLog.Infof("loaded cluster data: %+v", clusterData)
-				}
+		// The job's spec configures feature sets and schedulers that job names often don't mention
+		if pjBytes, err := gcsJobRun.GetContent(ctx, path+"/prowjob.json"); err != nil {
+			jLog.WithError(err).Warn("unable to read prowjob.json, proceeding without")
+		} else {
+			pj := &prow.ProwJob{}
+			if err := json.Unmarshal(pjBytes, pj); err != nil {
+				jLog.WithError(err).Warn("unable to parse prowjob.json, proceeding without")
+			} else {
+				specVariants = JobSpecVariants(pj)
 			}
 		}
+	}
 
-		variants := v.CalculateVariantsForJob(jLog, jlr.JobName, clusterData)
-		count++
-		jLog.WithField("variants", variants).WithField("count", count).Info("calculated variants")
-		expectedVariants[jlr.JobName] = variants
+	variants := v.CalculateVariantsFromFile(jLog, job.Name, clusterData)
+	applyJobSpecVariants(jLog, variants, specVariants)
+	v.finishVariants(jLog, job.Name, variants)
+	return variants, nil
+}
+
+// finishVariants applies the loader's overrides to a job's variants, then its variant schema.
+func (v *OCPVariantLoader) finishVariants(jLog logrus.FieldLogger, jobName string, variants map[string]string) {
+	v.overrides.Apply(jLog, jobName, variants)
+	schema := v.variantSchema()
+	schema.ApplyDefaults(variants)
+	schema.Enforce(jLog, variants, v.enforcement)
+}
+
+// ReclassifyJob recalculates a job's variants offline from its name and the variants it was stored with, see
+// VariantReclassifier. Stored variants the job name doesn't determine came from the job's cluster-data.json or spec,
+// which aren't stored, so are kept; release defaulted ones are recalculated. Cluster data that won over the job name
+// when the job was stored isn't replayed, so those variants take the job name's value.
+func (v *OCPVariantLoader) ReclassifyJob(jLog logrus.FieldLogger, jobName string, stored map[string]string) map[string]string {
+	defaulted := map[string]bool{}
+	for _, variant := range v.releaseDefaults().Variants() {
+		defaulted[variant] = true
 	}
-	dur := time.Since(start)
-	log.WithField("count", count).Infof("processed primary job list in %s", dur)
 
-	return expectedVariants, nil
+	variants := v.IdentifyVariants(jLog, jobName)
+	for k, value := range stored {
+		if _, ok := variants[k]; !ok && !defaulted[k] {
+			variants[k] = value
+		}
+	}
+	v.releaseDefaults().Apply(variants, variants[VariantRelease])
+	v.finishVariants(jLog, jobName, variants)
+	return variants
 }
 
 // fileVariantsToIgnore are values in the cluster-data.json that vary by run, and are not consistent for the job itself.
@@ -178,7 +263,9 @@ var fileVariantsToIgnore = map[string]bool{
 	"MasterNodesUpdated": true,
 }
 
-func (v *OCPVariantLoader) CalculateVariantsForJob(jLog logrus.FieldLogger, jobName string, variantFile map[string]string) map[string]string {
+// CalculateVariantsFromFile determines a job's variants from its name, merged with those read from a job run's
+// variants data file, i.e. cluster-data.json.
+func (v *OCPVariantLoader) CalculateVariantsFromFile(jLog logrus.FieldLogger, jobName string, variantFile map[string]string) map[string]string {
 
 	// Calculate variants based on job name:
 	variants := v.IdentifyVariants(jLog, jobName)
@@ -237,14 +324,35 @@ func (v *OCPVariantLoader) CalculateVariantsForJob(jLog logrus.FieldLogger, jobN
 		}
 	}
 
+	if variants[VariantArch] == "heterogeneous" {
+		variants[VariantArchMix] = determineArchitectureMix(jobName, variantFile[VariantArch])
+	}
+
+	// Fill in release dependent defaults for anything neither the job name nor the file determined.
+	v.releaseDefaults().Apply(variants, variants[VariantRelease])
+
 	return variants
 }
 
+func (v *OCPVariantLoader) variantSchema() *VariantSchema {
+	if v.schema == nil {
+		return defaultVariantSchema
+	}
+	return v.schema
+}
+
+func (v *OCPVariantLoader) releaseDefaults() *ReleaseDefaults {
+	if v.defaults == nil {
+		return defaultReleaseDefaults
+	}
+	return v.defaults
+}
+
 var (
 	aggregatedRegex = regexp.MustCompile(`(?i)aggregated-`)
 	// We're not sure what these aggregator jobs are but they exist as of right now:
 	aggregatorRegex = regexp.MustCompile(`(?i)aggregator-`)
-	alibabaRegex    = regexp.MustCompile(`(?i)-alibaba`)
+	alibabaRegex    = regexp.MustCompile(`(?i)-alibaba|-alicloud`)
 	arm64Regex      = regexp.MustCompile(`(?i)-arm64|-multi-a-a|-arm`)
 	assistedRegex   = regexp.MustCompile(`(?i)-assisted`)
 	awsRegex        = regexp.MustCompile(`(?i)-aws`)
@@ -253,6 +361,7 @@ var (
 	etcdScaling     = regexp.MustCompile(`(?i)-etcd-scaling`)
 	fipsRegex       = regexp.MustCompile(`(?i)-fips`)
 	hypershiftRegex = regexp.MustCompile(`(?i)-hypershift`)
+	ibmCloudRegex   = regexp.MustCompile(`(?i)-ibmcloud|-ibm-cloud`)
 	upiRegex        = regexp.MustCompile(`(?i)-upi`)
 	libvirtRegex    = regexp.MustCompile(`(?i)-libvirt`)
 	metalRegex      = regexp.MustCompile(`(?i)-metal`)
@@ -287,14 +396,17 @@ var (
 	// some vsphere jobs do not have a trailing -version segment
 	vsphereRegex   = regexp.MustCompile(`(?i)-vsphere`)
 	crunRegex      = regexp.MustCompile(`(?i)-crun`)
+	runcRegex      = regexp.MustCompile(`(?i)-runc`)
 	cgroupsv1Regex = regexp.MustCompile(`(?i)-cgroupsv1`)
+	cgroupsv2Regex = regexp.MustCompile(`(?i)-cgroupsv2`)
 )
 
 const (
 	VariantAggregation      = "Aggregation" // aggregated or none
 	VariantArch             = "Architecture"
-	VariantFeatureSet       = "FeatureSet" // techpreview / standard
-	VariantInstaller        = "Installer"  // ipi / upi / assisted
+	VariantArchMix          = "ArchitectureMix" // node architectures of heterogeneous jobs, i.e. amd64+arm64
+	VariantFeatureSet       = "FeatureSet"      // techpreview / standard
+	VariantInstaller        = "Installer"       // ipi / upi / assisted
 	VariantNetwork          = "Network"
 	VariantNetworkAccess    = "NetworkAccess" // disconnected / proxy / standard
 	VariantNetworkStack     = "NetworkStack"  // ipv4 / ipv6 / dual
@@ -325,6 +437,9 @@ func (v *OCPVariantLoader) IdentifyVariants(jLog logrus.FieldLogger, jobName str
 		variants[VariantAggregation] = "none"
 	}
 
+	// The registry maps job names to variants, so the release is the one in the job's name even for jobs that test
+	// several releases' payloads. Runs of those are attributed to the release they tested from their junit rows'
+	// branch in component readiness, and from their cluster data in postgres.
 	release, fromRelease := extractReleases(jobName)
 	releaseMajorMinor := strings.Split(release, ".")
 	if release != "" {
@@ -440,15 +555,16 @@ func (v *OCPVariantLoader) IdentifyVariants(jLog logrus.FieldLogger, jobName str
 		variants[VariantNetworkAccess] = VariantDefaultValue
 	}
 
+	// Jobs not naming a container runtime or cgroup mode get the release default in CalculateVariantsForJob.
 	if crunRegex.MatchString(jobName) {
 		variants[VariantContainerRuntime] = "crun"
-	} else {
+	} else if runcRegex.MatchString(jobName) {
 		variants[VariantContainerRuntime] = "runc"
 	}
 
 	if cgroupsv1Regex.MatchString(jobName) {
 		variants[VariantCGroupMode] = "v1"
-	} else {
+	} else if cgroupsv2Regex.MatchString(jobName) {
 		variants[VariantCGroupMode] = "v2"
 	}
 
@@ -529,6 +645,8 @@ func determinePlatform(jLog logrus.FieldLogger, variants map[string]string, jobN
 		platform = "azure"
 	} else if gcpRegex.MatchString(jobName) {
 		platform = "gcp"
+	} else if ibmCloudRegex.MatchString(jobName) {
+		platform = "ibmcloud"
 	} else if libvirtRegex.MatchString(jobName) {
 		platform = "libvirt"
 	} else if metalRegex.MatchString(jobName) {
@@ -592,6 +710,59 @@ func determineArchitecture(jobName string) string {
 	}
 }
 
+// multiArchRegex matches the multi-<control plane>-<compute> naming of heterogeneous jobs, i.e. multi-x-ax for
+// amd64 control plane nodes with arm64 and amd64 compute nodes.
+var multiArchRegex = regexp.MustCompile(`(?i)-multi-([xapz])-([xapz]+)(-|$)`)
+
+var multiArchLetters = map[rune]string{
+	'x': "amd64",
+	'a': "arm64",
+	'p': "ppc64le",
+	'z': "s390x",
+}
+
+// clusterArchitectures normalizes the node architecture reported in cluster-data.
+var clusterArchitectures = map[string]string{
+	"x86_64":  "amd64",
+	"aarch64": "arm64",
+}
+
+// determineArchitectureMix returns the node architectures a heterogeneous job exercises, sorted and joined with +.
+// They're taken from the job name, which names the architecture of each node pool, or only if the name doesn't,
+// from the architectures cluster-data reports, separated by + or commas. The two aren't merged, as adding a cluster
+// architecture the name doesn't list would report a mix the job doesn't run. A single architecture can't be the
+// whole mix, so it's unknown unless at least two are found.
+func determineArchitectureMix(jobName, clusterArch string) string {
+	archs := map[string]bool{}
+	if m := multiArchRegex.FindStringSubmatch(jobName); m != nil {
+		for _, l := range strings.ToLower(m[1] + m[2]) {
+			archs[multiArchLetters[l]] = true
+		}
+	}
+	if len(archs) == 0 {
+		for _, a := range strings.FieldsFunc(strings.ToLower(clusterArch), func(r rune) bool { return r == '+' || r == ',' }) {
+			a = strings.TrimSpace(a)
+			if a == "" || a == "heterogeneous" || a == "multi" {
+				continue
+			}
+			if normalized, ok := clusterArchitectures[a]; ok {
+				a = normalized
+			}
+			archs[a] = true
+		}
+	}
+	if len(archs) < 2 {
+		return "unknown"
+	}
+
+	mix := make([]string, 0, len(archs))
+	for a := range archs {
+		mix = append(mix, a)
+	}
+	sort.Strings(mix)
+	return strings.Join(mix, "+")
+}
+
 func determineNetwork(jLog logrus.FieldLogger, jobName, release string) string {
 	if ovnRegex.MatchString(jobName) {
 		return "ovn"
@@ -177,6 +177,7 @@ func TestVariantSyncer(t *testing.T) {
 				VariantReleaseMajor:     "4",
 				VariantReleaseMinor:     "17",
 				VariantArch:             "heterogeneous",
+				VariantArchMix:          "amd64+arm64",
 				VariantInstaller:        "ipi",
 				VariantPlatform:         "aws",
 				VariantNetwork:          "ovn",
@@ -314,6 +315,7 @@ func TestVariantSyncer(t *testing.T) {
 				VariantFromReleaseMajor: "4",
 				VariantFromReleaseMinor: "15",
 				VariantArch:             "heterogeneous",
+				VariantArchMix:          "unknown", // only the control plane architecture is known
 				VariantInstaller:        "ipi",
 				VariantPlatform:         "gcp",
 				VariantNetwork:          "ovn",
@@ -682,10 +684,64 @@ func TestVariantSyncer(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.job, func(t *testing.T) {
 			assert.Equal(t, test.expected,
-				variantSyncer.CalculateVariantsForJob(
+				variantSyncer.CalculateVariantsFromFile(
 					logrus.WithField("source", "TestVariantSyncer"),
 					test.job,
 					test.variantsFile))
 		})
 	}
 }
+
+func TestDeterminePlatform(t *testing.T) {
+	tests := []struct {
+		job       string
+		platform  string
+		installer string
+	}{
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-nutanix-ovn", platform: "nutanix", installer: "ipi"},
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-nutanix-ovn-upi", platform: "nutanix", installer: "upi"},
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-ibmcloud-ovn", platform: "ibmcloud", installer: "ipi"},
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-ibmcloud-ovn-upi", platform: "ibmcloud", installer: "upi"},
+		{job: "periodic-ci-openshift-ibm-cloud-release-4.16-e2e-ibm-cloud-csi", platform: "ibmcloud", installer: "ipi"},
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-alibabacloud-ovn", platform: "alibaba", installer: "ipi"},
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-alicloud-ovn-upi", platform: "alibaba", installer: "upi"},
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-openstack-ovn", platform: "openstack", installer: "ipi"},
+		{job: "periodic-ci-openshift-release-master-nightly-4.16-e2e-openstack-ovn-upi", platform: "openstack", installer: "upi"},
+		{job: "periodic-ci-shiftstack-ci-release-4.16-e2e-openstack-ccpmso-zone", platform: "openstack", installer: "ipi"},
+		{job: "periodic-ci-shiftstack-ci-release-4.16-e2e-openstack-nfv-intel", platform: "openstack", installer: "ipi"},
+		{job: "periodic-ci-shiftstack-ci-release-4.16-e2e-openstack-proxy", platform: "openstack", installer: "ipi"},
+	}
+	for _, test := range tests {
+		t.Run(test.job, func(t *testing.T) {
+			variants := map[string]string{}
+			determinePlatform(logrus.WithField("source", "TestDeterminePlatform"), variants, test.job)
+			assert.Equal(t, test.platform, variants[VariantPlatform])
+			assert.Equal(t, test.installer, determineInstallation(test.job))
+		})
+	}
+}
+
+func TestDetermineArchitectureMix(t *testing.T) {
+	const prefix = "periodic-ci-openshift-multiarch-master-nightly-4.17-ocp-e2e-"
+	tests := []struct {
+		name        string
+		job         string
+		clusterArch string
+		expected    string
+	}{
+		{name: "from the name", job: prefix + "aws-ovn-multi-x-ax", expected: "amd64+arm64"},
+		{name: "cluster arch in the name's mix", job: prefix + "ovn-multi-x-px-serial", clusterArch: "amd64", expected: "amd64+ppc64le"},
+		{name: "cluster arch missing from the name's mix is ignored", job: prefix + "aws-ovn-multi-x-ax", clusterArch: "s390x", expected: "amd64+arm64"},
+		{name: "single arch name with another cluster arch", job: prefix + "aws-ovn-multi-a-a", clusterArch: "x86_64", expected: "unknown"},
+		{name: "single arch name with the same cluster arch", job: prefix + "aws-ovn-multi-a-a", clusterArch: "aarch64", expected: "unknown"},
+		{name: "single arch name with a cluster mix", job: prefix + "aws-ovn-multi-a-a", clusterArch: "x86_64+aarch64", expected: "unknown"},
+		{name: "cluster mix when the name has none", job: prefix + "gcp-ovn-heterogeneous", clusterArch: "x86_64,aarch64", expected: "amd64+arm64"},
+		{name: "single cluster arch when the name has none", job: prefix + "gcp-ovn-heterogeneous", clusterArch: "amd64", expected: "unknown"},
+		{name: "heterogeneous cluster when the name has none", job: prefix + "gcp-ovn-heterogeneous", clusterArch: "multi", expected: "unknown"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, determineArchitectureMix(tc.job, tc.clusterArch))
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package variantregistry
+
+import (
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// VariantOverride forces variant values for the jobs whose name matches a regex.
+type VariantOverride struct {
+	JobRegex string            `yaml:"job_regex" json:"job_regex"`
+	Variants map[string]string `yaml:"variants" json:"variants"`
+	// Reason is why the override is needed, i.e. a link to the issue about the misparsed jobs, for whoever
+	// maintains the file.
+	Reason string `yaml:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// VariantOverrides correct the variants of jobs the loader misclassifies, without code changes. They take
+// precedence over everything else a job's variants are determined from:
+//
+//  1. overrides, later entries winning over earlier ones that match the same job
+//  2. the job name, the job's prowjob.json spec and its cluster-data.json, whose conflicts are settled per variant
+//     by CalculateVariantsFromFile and applyJobSpecVariants
+//  3. release defaults, for variants none of the above set
+//
+// Variants derived from an overridden one, i.e. ReleaseMinor from Release, are not recalculated, and need their own
+// override. A nil VariantOverrides overrides nothing.
+type VariantOverrides struct {
+	overrides []compiledOverride
+}
+
+type compiledOverride struct {
+	VariantOverride
+	re *regexp.Regexp
+}
+
+// ParseVariantOverrides parses a YAML or JSON list of VariantOverride.
+func ParseVariantOverrides(data []byte) (*VariantOverrides, error) {
+	raw := []VariantOverride{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "invalid variant overrides")
+	}
+
+	o := &VariantOverrides{}
+	for i, override := range raw {
+		if override.JobRegex == "" {
+			return nil, errors.Errorf("variant override %d has no job_regex", i+1)
+		}
+		re, err := regexp.Compile(override.JobRegex)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid job_regex in variant override %d", i+1)
+		}
+		if len(override.Variants) == 0 {
+			return nil, errors.Errorf("variant override %d for %s has no variants", i+1, override.JobRegex)
+		}
+		for name, value := range override.Variants {
+			if name == "" || value == "" {
+				return nil, errors.Errorf("variant override %d for %s has an empty variant name or value", i+1,
+					override.JobRegex)
+			}
+		}
+		o.overrides = append(o.overrides, compiledOverride{VariantOverride: override, re: re})
+	}
+	return o, nil
+}
+
+// LoadVariantOverrides reads variant overrides from a file, see ParseVariantOverrides.
+func LoadVariantOverrides(path string) (*VariantOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVariantOverrides(data)
+}
+
+// Apply forces the variants of every override matching the job, logging values it changes.
+func (o *VariantOverrides) Apply(jLog logrus.FieldLogger, jobName string, variants map[string]string) {
+	if o == nil {
+		return
+	}
+	for _, override := range o.overrides {
+		if !override.re.MatchString(jobName) {
+			continue
+		}
+		names := make([]string, 0, len(override.Variants))
+		for name := range override.Variants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := override.Variants[name]
+			if current, ok := variants[name]; ok && current != value {
+				jLog.WithFields(logrus.Fields{
+					"variant":      name,
+					"calculated":   current,
+					"fromOverride": value,
+					"override":     override.JobRegex,
+				}).Infof("variant override: using %s from overrides file", name)
+			}
+			variants[name] = value
+		}
+	}
+}
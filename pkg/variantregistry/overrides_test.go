@@ -0,0 +1,83 @@
+package variantregistry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVariantOverrides(t *testing.T) {
+	yamlOverrides, err := ParseVariantOverrides([]byte(`
+- job_regex: -e2e-metal-.*-sno
+  variants:
+    Topology: single
+  reason: https://issues.redhat.com/browse/TRT-0000
+`))
+	require.NoError(t, err)
+	jsonOverrides, err := ParseVariantOverrides([]byte(
+		`[{"job_regex": "-e2e-metal-.*-sno", "variants": {"Topology": "single"}, "reason": "https://issues.redhat.com/browse/TRT-0000"}]`))
+	require.NoError(t, err)
+	assert.Equal(t, yamlOverrides, jsonOverrides)
+
+	for data, expected := range map[string]string{
+		`- variants: {Topology: single}`:                   "has no job_regex",
+		`- {job_regex: "-e2e-(", variants: {A: b}}`:        "invalid job_regex in variant override 1",
+		`- {job_regex: "-e2e-"}`:                           "has no variants",
+		`- {job_regex: "-e2e-", variants: {Topology: ""}}`: "empty variant name or value",
+		`job_regex: -e2e-`:                                 "invalid variant overrides",
+	} {
+		_, err := ParseVariantOverrides([]byte(data))
+		assert.ErrorContains(t, err, expected, data)
+	}
+}
+
+func TestVariantOverridesApply(t *testing.T) {
+	overrides, err := ParseVariantOverrides([]byte(`
+- job_regex: -e2e-
+  variants:
+    Owner: eng
+    Suite: parallel
+- job_regex: -e2e-.*-serial$
+  variants:
+    Suite: serial
+`))
+	require.NoError(t, err)
+
+	variants := map[string]string{VariantSuite: "unknown", VariantPlatform: "aws"}
+	overrides.Apply(logrus.New(), "periodic-e2e-aws-serial", variants)
+	assert.Equal(t, map[string]string{
+		VariantOwner:    "eng",
+		VariantSuite:    "serial",
+		VariantPlatform: "aws",
+	}, variants, "later overrides win")
+
+	variants = map[string]string{VariantPlatform: "aws"}
+	overrides.Apply(logrus.New(), "periodic-unit", variants)
+	assert.Equal(t, map[string]string{VariantPlatform: "aws"}, variants, "jobs not matching are left alone")
+
+	var none *VariantOverrides
+	none.Apply(logrus.New(), "periodic-e2e-aws-serial", variants)
+	assert.Equal(t, map[string]string{VariantPlatform: "aws"}, variants)
+}
+
+func TestCalculateVariantsForJobWithOverrides(t *testing.T) {
+	overrides, err := ParseVariantOverrides([]byte(`
+- job_regex: ^periodic-ci-openshift-release-master-nightly-4\.16-e2e-aws-ovn$
+  variants:
+    Platform: vsphere
+    ContainerRuntime: crun
+`))
+	require.NoError(t, err)
+	loader := &OCPVariantLoader{overrides: overrides}
+
+	variants, err := loader.CalculateVariantsForJob(context.Background(), logrus.New(),
+		ListedJob{Name: "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"})
+	require.NoError(t, err)
+	assert.Equal(t, "vsphere", variants[VariantPlatform], "overrides win over the job name")
+	assert.Equal(t, "crun", variants[VariantContainerRuntime], "overrides win over release defaults")
+	assert.Equal(t, "ovn", variants[VariantNetwork], "variants not overridden are still calculated")
+	assert.Equal(t, "4.16", variants[VariantRelease])
+}
@@ -0,0 +1,92 @@
+package variantregistry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/notification"
+)
+
+// OwnerChangeWebhookPayload is the body posted to the owner change webhook. Text summarizes the changes, so the
+// webhook can be a Slack incoming webhook.
+type OwnerChangeWebhookPayload struct {
+	Text    string                  `json:"text"`
+	Changes []models.JobOwnerChange `json:"changes"`
+}
+
+// ownerChanges returns the jobs in both the current and expected registry whose Owner variant differs, in job name
+// order. New and removed jobs are not ownership changes.
+func ownerChanges(expectedVariants, currentVariants map[string]map[string]string) []models.JobOwnerChange {
+	changes := []models.JobOwnerChange{}
+	for job, expected := range expectedVariants {
+		current, ok := currentVariants[job]
+		if !ok || current[VariantOwner] == expected[VariantOwner] {
+			continue
+		}
+		changes = append(changes, models.JobOwnerChange{
+			JobName:  job,
+			OldOwner: current[VariantOwner],
+			NewOwner: expected[VariantOwner],
+		})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].JobName < changes[j].JobName
+	})
+	return changes
+}
+
+func ownerChangeText(changes []models.JobOwnerChange) string {
+	lines := []string{fmt.Sprintf("%d jobs changed owner in the variant registry:", len(changes))}
+	for _, c := range changes {
+		lines = append(lines, fmt.Sprintf("• %s: %s → %s", c.JobName, ownerOrNone(c.OldOwner), ownerOrNone(c.NewOwner)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func ownerOrNone(owner string) string {
+	if owner == "" {
+		return "(none)"
+	}
+	return owner
+}
+
+// recordOwnerChanges logs each ownership change, saves them to the database if there is one, and posts them to the
+// webhook if configured.
+func (s *JobVariantsLoader) recordOwnerChanges(changes []models.JobOwnerChange) {
+	if len(changes) == 0 {
+		return
+	}
+	for _, c := range changes {
+		log.WithFields(log.Fields{
+			"job":      c.JobName,
+			"oldOwner": c.OldOwner,
+			"newOwner": c.NewOwner,
+		}).Warning("job owner changed")
+	}
+
+	if s.dbc != nil {
+		if err := s.dbc.DB.Create(&changes).Error; err != nil {
+			s.errors = append(s.errors, errors.Wrap(err, "error recording job owner changes"))
+		}
+	}
+	if s.ownerChangeWebhookURL != "" {
+		text := ownerChangeText(changes)
+		msg := notification.Message{
+			Subject: "Job owner changes",
+			Text:    text,
+			Data:    OwnerChangeWebhookPayload{Text: text, Changes: changes},
+		}
+		dest := notification.Destination{Transport: notification.WebhookURL(s.ownerChangeWebhookURL)}
+		if err := notification.Send(context.TODO(), msg, dest); err != nil {
+			s.errors = append(s.errors, errors.Wrap(err, "error posting job owner changes to webhook"))
+		} else {
+			log.Infof("posted %d job owner changes to webhook", len(changes))
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package variantregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestOwnerChanges(t *testing.T) {
+	current := map[string]map[string]string{
+		"job-unchanged": {VariantOwner: "eng", VariantPlatform: "aws"},
+		"job-flipped":   {VariantOwner: "eng"},
+		"job-unowned":   {VariantOwner: "qe"},
+		"job-removed":   {VariantOwner: "eng"},
+	}
+	expected := map[string]map[string]string{
+		"job-unchanged": {VariantOwner: "eng", VariantPlatform: "gcp"},
+		"job-flipped":   {VariantOwner: "perfscale"},
+		"job-unowned":   {VariantPlatform: "aws"},
+		"job-new":       {VariantOwner: "cnf"},
+	}
+
+	changes := ownerChanges(expected, current)
+	assert.Equal(t, []models.JobOwnerChange{
+		{JobName: "job-flipped", OldOwner: "eng", NewOwner: "perfscale"},
+		{JobName: "job-unowned", OldOwner: "qe", NewOwner: ""},
+	}, changes)
+	assert.Equal(t, "2 jobs changed owner in the variant registry:\n• job-flipped: eng → perfscale\n• job-unowned: qe → (none)",
+		ownerChangeText(changes))
+}
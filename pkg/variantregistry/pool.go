@@ -0,0 +1,155 @@
+package variantregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultSyncConcurrency is how many batches of inserts a sync streams into the registry at once.
+	DefaultSyncConcurrency = 4
+	// DefaultSyncRetries is how many times a batch that failed to be written is retried.
+	DefaultSyncRetries = 3
+	// DefaultSyncBackoff is the delay before a failed batch is first retried, doubling on each subsequent retry.
+	DefaultSyncBackoff = 5 * time.Second
+
+	// maxSyncBackoff caps the delay between retries of a batch.
+	maxSyncBackoff = 2 * time.Minute
+)
+
+// syncPool writes batches to the registry, retrying failed batches with backoff, so every batch write must be safe to
+// repeat. Only streaming inserts are written concurrently. DML stays serial: merges and job deletes are written one
+// at a time with a serial pool, because of BigQuery's concurrent DML limits. BigQuery runs at most two mutating DML
+// statements against a table at once, queueing the rest, and fails one of two that modify the same partition with a
+// concurrent update error. The registry table is not partitioned, so any two MERGE or DELETE statements conflict,
+// even ones for disjoint jobs; splitting batches by job name, each worker with its own staging table, would only
+// trade the wait for retries.
+type syncPool struct {
+	concurrency int
+	retries     int
+	backoff     time.Duration
+}
+
+// batchErrors aggregates the errors of the batches that failed every attempt.
+type batchErrors struct {
+	step   string
+	total  int
+	errors []error
+}
+
+func (e *batchErrors) Error() string {
+	msgs := make([]string, 0, len(e.errors))
+	for _, err := range e.errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d of %d %s batches failed: %s", len(e.errors), e.total, e.step, strings.Join(msgs, "; "))
+}
+
+// runBatches calls write for every batch, with up to the pool's concurrency at once, passing it a logger for the
+// batch. The batches written are returned, in no particular order, along with a batchErrors if any failed every
+// attempt; batches not yet started when ctx is done are neither.
+func runBatches[T any](ctx context.Context, p syncPool, step string, all [][]T,
+	write func(ctx context.Context, logger log.FieldLogger, batch []T) error) ([][]T, error) {
+
+	queue := make(chan int)
+	go func() {
+		defer close(queue)
+		for i := range all {
+			select {
+			case queue <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	done := [][]T{}
+	failed := &batchErrors{step: step, total: len(all)}
+	for w := 0; w < p.workers(len(all)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+				bLog := log.WithField("step", step).WithField("batch", fmt.Sprintf("%d/%d", i+1, len(all)))
+				err := p.retry(ctx, bLog, func() error {
+					return write(ctx, bLog, all[i])
+				})
+				lock.Lock()
+				if err != nil {
+					bLog.WithError(err).Error("batch failed, continuing")
+					failed.errors = append(failed.errors, fmt.Errorf("batch %d: %w", i+1, err))
+				} else {
+					done = append(done, all[i])
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(failed.errors) > 0 {
+		return done, failed
+	}
+	return done, ctx.Err()
+}
+
+// serial returns a copy of the pool writing one batch at a time, for DML statements.
+func (p syncPool) serial() syncPool {
+	p.concurrency = 1
+	return p
+}
+
+// workers returns how many workers to write a number of batches with.
+func (p syncPool) workers(batches int) int {
+	workers := p.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > batches {
+		workers = batches
+	}
+	return workers
+}
+
+// retry calls f until it succeeds or has been retried the pool's number of times, backing off exponentially between
+// attempts.
+func (p syncPool) retry(ctx context.Context, logger log.FieldLogger, f func() error) error {
+	var err error
+	for attempt := 0; attempt <= p.retries; attempt++ {
+		if attempt > 0 {
+			delay := p.backoff << (attempt - 1)
+			if delay > maxSyncBackoff || delay < 0 {
+				delay = maxSyncBackoff
+			}
+			logger.WithError(err).Warnf("retrying in %s, attempt %d of %d", delay, attempt+1, p.retries+1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		if err = f(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// flatten joins batches back into one list.
+func flatten[T any](batches [][]T) []T {
+	var items []T
+	for _, batch := range batches {
+		items = append(items, batch...)
+	}
+	return items
+}
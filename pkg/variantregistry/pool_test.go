@@ -0,0 +1,97 @@
+package variantregistry
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatches(t *testing.T) {
+	all := batches([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 2)
+	pool := syncPool{concurrency: 3, retries: 2}
+
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	attempts := map[int]int{}
+	loggers := map[logrus.FieldLogger]bool{}
+	done, err := runBatches(context.Background(), pool, "test", all, func(_ context.Context, logger logrus.FieldLogger, batch []int) error {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		attempts[batch[0]]++
+		attempt := attempts[batch[0]]
+		loggers[logger] = true
+		lock.Unlock()
+
+		time.Sleep(time.Millisecond)
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		switch batch[0] {
+		case 3:
+			if attempt < 3 {
+				return errors.New("transient")
+			}
+		case 7:
+			return errors.New("permanent")
+		}
+		return nil
+	})
+
+	assert.LessOrEqual(t, maxRunning, 3, "no more batches run at once than the pool's concurrency")
+	assert.Len(t, loggers, 5, "each batch is passed its own logger")
+	assert.Equal(t, 3, attempts[3], "failed batches are retried")
+	assert.Equal(t, 3, attempts[7], "batches are attempted at most retries+1 times")
+	assert.Equal(t, 1, attempts[1])
+
+	items := flatten(done)
+	sort.Ints(items)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 9, 10}, items, "batches written are returned even if others failed")
+
+	var batchErr *batchErrors
+	require.ErrorAs(t, err, &batchErr)
+	assert.EqualError(t, err, "1 of 5 test batches failed: batch 4: permanent")
+}
+
+func TestRunBatchesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := syncPool{concurrency: 1, retries: 3, backoff: time.Hour}
+
+	calls := 0
+	done, err := runBatches(ctx, pool, "test", batches([]int{1, 2, 3}, 1), func(context.Context, logrus.FieldLogger, []int) error {
+		calls++
+		cancel()
+		return errors.New("failed")
+	})
+	assert.Empty(t, done)
+	assert.Equal(t, 1, calls, "canceling stops retries and batches not yet started")
+	assert.ErrorContains(t, err, "batch 1: context canceled")
+}
+
+func TestSyncPoolWorkers(t *testing.T) {
+	assert.Equal(t, 4, syncPool{concurrency: 4}.workers(10))
+	assert.Equal(t, 2, syncPool{concurrency: 4}.workers(2), "no more workers than batches")
+	assert.Equal(t, 1, syncPool{}.workers(10), "at least one worker")
+	assert.Equal(t, syncPool{concurrency: 1, retries: 3}, syncPool{concurrency: 4, retries: 3}.serial(),
+		"DML batches are written one at a time, with the same retries")
+}
+
+func TestSyncPoolRetryBackoff(t *testing.T) {
+	pool := syncPool{retries: 2, backoff: 10 * time.Millisecond}
+	start := time.Now()
+	err := pool.retry(context.Background(), logrus.New(), func() error {
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond, "waits 10ms then 20ms between attempts")
+}
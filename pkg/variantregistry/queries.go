@@ -0,0 +1,61 @@
+package variantregistry
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/bigquery"
+	log "github.com/sirupsen/logrus"
+)
+
+// registryStatement is a DML statement on the registry table. Job names and variants are passed as query parameters,
+// never interpolated, as they come from job configuration: a name containing a quote would otherwise break the sync,
+// or change what it deletes. Only the table name, which comes from flags, is part of the SQL.
+type registryStatement struct {
+	SQL    string
+	Params []bigquery.QueryParameter
+}
+
+// String returns the statement and its parameters for logging and errors.
+func (r registryStatement) String() string {
+	params := make(map[string]interface{}, len(r.Params))
+	for _, p := range r.Params {
+		params[p.Name] = p.Value
+	}
+	return fmt.Sprintf("%s %v", r.SQL, params)
+}
+
+// mergeVariantsStatement applies the updates and deletes staged in a table to the registry. Deletes only remove a
+// variant that still has the staged value.
+func mergeVariantsStatement(table, staging string) registryStatement {
+	return registryStatement{
+		SQL: fmt.Sprintf(`MERGE `+"`%s`"+` AS registry
+USING `+"`%s`"+` AS staged
+ON registry.job_name = staged.job_name AND registry.variant_name = staged.variant_name
+WHEN MATCHED AND staged.action = '%s' THEN
+	UPDATE SET variant_value = staged.variant_value
+WHEN MATCHED AND staged.action = '%s' AND registry.variant_value = staged.variant_value THEN
+	DELETE`, table, staging, stagedUpdate, stagedDelete),
+	}
+}
+
+// deleteJobsStatement removes every variant of jobs.
+func deleteJobsStatement(table string, jobs []string) registryStatement {
+	return registryStatement{
+		SQL: fmt.Sprintf("DELETE FROM `%s` WHERE job_name IN UNNEST(@job_names)", table),
+		Params: []bigquery.QueryParameter{
+			{Name: "job_names", Value: jobs},
+		},
+	}
+}
+
+// exec runs a statement against the registry, returning an error describing it if it fails.
+func (s *JobVariantsLoader) exec(ctx context.Context, logger log.FieldLogger, stmt registryStatement) error {
+	q := s.bqClient.Query(stmt.SQL)
+	q.Parameters = stmt.Params
+	if _, err := q.Read(ctx); err != nil {
+		return fmt.Errorf("%w: %s", err, stmt)
+	}
+	logger.Infof("successful query: %s", stmt)
+	return nil
+}
@@ -0,0 +1,34 @@
+package variantregistry
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTable = "openshift-ci-data-analysis.ci_data.job_variants"
+
+func TestMergeVariantsStatement(t *testing.T) {
+	stmt := mergeVariantsStatement(testTable, testTable+"_staging_1")
+	assert.Equal(t, "MERGE `openshift-ci-data-analysis.ci_data.job_variants` AS registry\n"+
+		"USING `openshift-ci-data-analysis.ci_data.job_variants_staging_1` AS staged\n"+
+		"ON registry.job_name = staged.job_name AND registry.variant_name = staged.variant_name\n"+
+		"WHEN MATCHED AND staged.action = 'update' THEN\n"+
+		"\tUPDATE SET variant_value = staged.variant_value\n"+
+		"WHEN MATCHED AND staged.action = 'delete' AND registry.variant_value = staged.variant_value THEN\n"+
+		"\tDELETE", stmt.SQL)
+	assert.Empty(t, stmt.Params, "staged values are read from the staging table, not the statement")
+}
+
+func TestDeleteJobsStatement(t *testing.T) {
+	jobs := []string{"job1", "job2', 'job3"}
+	stmt := deleteJobsStatement(testTable, jobs)
+	assert.Equal(t, "DELETE FROM `openshift-ci-data-analysis.ci_data.job_variants` WHERE job_name IN UNNEST(@job_names)", stmt.SQL)
+	assert.Equal(t, []bigquery.QueryParameter{{Name: "job_names", Value: jobs}}, stmt.Params)
+}
+
+func TestRegistryStatementString(t *testing.T) {
+	stmt := deleteJobsStatement(testTable, []string{"job1"})
+	assert.Equal(t, "DELETE FROM `openshift-ci-data-analysis.ci_data.job_variants` WHERE job_name IN UNNEST(@job_names) map[job_names:[job1]]", stmt.String())
+}
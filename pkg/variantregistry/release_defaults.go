@@ -0,0 +1,126 @@
+package variantregistry
+
+import (
+	_ "embed"
+	"os"
+	"sort"
+
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// releaseDefaultsRaw is the default release capability matrix, see release_defaults.yaml.
+//
+//go:embed release_defaults.yaml
+var releaseDefaultsRaw []byte
+
+var defaultReleaseDefaults = mustParseReleaseDefaults(releaseDefaultsRaw)
+
+// ReleaseDefault is the value a variant defaults to from a release onward.
+type ReleaseDefault struct {
+	Since string `yaml:"since"`
+	Value string `yaml:"value"`
+}
+
+// ReleaseDefaults maps variant names to the values they default to by release, for variants such as
+// ContainerRuntime whose default changes between releases.
+type ReleaseDefaults struct {
+	raw      string
+	variants map[string][]releaseDefault
+}
+
+type releaseDefault struct {
+	since *version.Version
+	value string
+}
+
+// ParseReleaseDefaults parses a yaml map of variant names to ReleaseDefault lists in ascending release order.
+func ParseReleaseDefaults(data []byte) (*ReleaseDefaults, error) {
+	raw := map[string][]ReleaseDefault{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "invalid release defaults")
+	}
+
+	d := &ReleaseDefaults{raw: string(data), variants: map[string][]releaseDefault{}}
+	for variant, entries := range raw {
+		if len(entries) == 0 {
+			return nil, errors.Errorf("release defaults for %s are empty", variant)
+		}
+		for i, e := range entries {
+			since, err := version.NewVersion(e.Since)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid release %q in %s defaults", e.Since, variant)
+			}
+			if e.Value == "" {
+				return nil, errors.Errorf("%s default since %s has no value", variant, e.Since)
+			}
+			if i > 0 && !since.GreaterThan(d.variants[variant][i-1].since) {
+				return nil, errors.Errorf("%s defaults are not in ascending release order at %s", variant, e.Since)
+			}
+			d.variants[variant] = append(d.variants[variant], releaseDefault{since: since, value: e.Value})
+		}
+	}
+	return d, nil
+}
+
+// LoadReleaseDefaults reads release defaults from a file, see ParseReleaseDefaults.
+func LoadReleaseDefaults(path string) (*ReleaseDefaults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseReleaseDefaults(data)
+}
+
+// DefaultReleaseDefaults returns the release defaults built into sippy.
+func DefaultReleaseDefaults() *ReleaseDefaults {
+	return defaultReleaseDefaults
+}
+
+// YAML returns the document the release defaults were parsed from.
+func (d *ReleaseDefaults) YAML() string {
+	return d.raw
+}
+
+// Variants returns the names of the defaulted variants, sorted.
+func (d *ReleaseDefaults) Variants() []string {
+	variants := make([]string, 0, len(d.variants))
+	for v := range d.variants {
+		variants = append(variants, v)
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+func mustParseReleaseDefaults(data []byte) *ReleaseDefaults {
+	d, err := ParseReleaseDefaults(data)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Apply sets every defaulted variant not already present in variants to its value for the given release.
+// Releases that are empty or unparseable get the earliest default.
+func (d *ReleaseDefaults) Apply(variants map[string]string, release string) {
+	releaseVersion, err := version.NewVersion(release)
+	if err != nil {
+		releaseVersion = nil
+	}
+	for variant, entries := range d.variants {
+		if _, ok := variants[variant]; ok {
+			continue
+		}
+		value := entries[0].value
+		if releaseVersion != nil {
+			for _, e := range entries[1:] {
+				if releaseVersion.LessThan(e.since) {
+					break
+				}
+				value = e.value
+			}
+		}
+		variants[variant] = value
+	}
+}
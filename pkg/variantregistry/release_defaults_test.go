@@ -0,0 +1,106 @@
+package variantregistry
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseDefaultsApply(t *testing.T) {
+	defaults, err := ParseReleaseDefaults([]byte(`
+ContainerRuntime:
+  - since: "3.11"
+    value: runc
+  - since: "4.18"
+    value: crun
+CGroupMode:
+  - since: "3.11"
+    value: v1
+  - since: "4.14"
+    value: v2
+`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		release  string
+		variants map[string]string
+		expected map[string]string
+	}{
+		{
+			name:     "before any flip",
+			release:  "4.13",
+			variants: map[string]string{},
+			expected: map[string]string{VariantContainerRuntime: "runc", VariantCGroupMode: "v1"},
+		},
+		{
+			name:     "flip release is inclusive",
+			release:  "4.18",
+			variants: map[string]string{},
+			expected: map[string]string{VariantContainerRuntime: "crun", VariantCGroupMode: "v2"},
+		},
+		{
+			name:     "later release",
+			release:  "4.20",
+			variants: map[string]string{},
+			expected: map[string]string{VariantContainerRuntime: "crun", VariantCGroupMode: "v2"},
+		},
+		{
+			name:     "unknown release uses earliest",
+			release:  "",
+			variants: map[string]string{},
+			expected: map[string]string{VariantContainerRuntime: "runc", VariantCGroupMode: "v1"},
+		},
+		{
+			name:     "explicit values win",
+			release:  "4.18",
+			variants: map[string]string{VariantContainerRuntime: "runc"},
+			expected: map[string]string{VariantContainerRuntime: "runc", VariantCGroupMode: "v2"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			defaults.Apply(test.variants, test.release)
+			assert.Equal(t, test.expected, test.variants)
+		})
+	}
+}
+
+func TestParseReleaseDefaultsErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"bad release":  "ContainerRuntime:\n  - since: foo\n    value: runc\n",
+		"no value":     "ContainerRuntime:\n  - since: \"4.18\"\n",
+		"out of order": "ContainerRuntime:\n  - since: \"4.18\"\n    value: crun\n  - since: \"4.10\"\n    value: runc\n",
+		"empty":        "ContainerRuntime: []\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseReleaseDefaults([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCalculateVariantsForJobReleaseDefaults(t *testing.T) {
+	defaults, err := ParseReleaseDefaults([]byte(`
+ContainerRuntime:
+  - since: "3.11"
+    value: runc
+  - since: "4.18"
+    value: crun
+`))
+	require.NoError(t, err)
+	loader := OCPVariantLoader{defaults: defaults}
+	log := logrus.WithField("source", "TestCalculateVariantsForJobReleaseDefaults")
+
+	variants := loader.CalculateVariantsFromFile(log, "periodic-ci-openshift-release-master-nightly-4.18-e2e-aws-ovn", nil)
+	assert.Equal(t, "crun", variants[VariantContainerRuntime])
+	assert.NotContains(t, variants, VariantCGroupMode, "only configured variants are defaulted")
+
+	variants = loader.CalculateVariantsFromFile(log, "periodic-ci-openshift-release-master-nightly-4.17-e2e-aws-ovn", nil)
+	assert.Equal(t, "runc", variants[VariantContainerRuntime])
+
+	variants = loader.CalculateVariantsFromFile(log, "periodic-ci-openshift-release-master-nightly-4.18-e2e-aws-ovn-runc", nil)
+	assert.Equal(t, "runc", variants[VariantContainerRuntime])
+}
@@ -0,0 +1,82 @@
+package variantregistry
+
+import (
+	"io"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RuleChange is a job variant that would change value under proposed rules. A variant being added or removed has
+// an empty old or new value.
+type RuleChange struct {
+	JobName  string `json:"job_name"`
+	Variant  string `json:"variant"`
+	OldValue string `json:"old_value"`
+	NewValue string `json:"new_value"`
+}
+
+// RulesPreview is the registry diff proposed rules would cause.
+type RulesPreview struct {
+	Jobs        int          `json:"jobs"`
+	ChangedJobs int          `json:"changed_jobs"`
+	Changes     []RuleChange `json:"changes"`
+}
+
+// PreviewReleaseDefaults dry-runs proposed release defaults against the current registry variants, returning the
+// changes in job and variant order. Defaulted variants are recalculated for every job whose name does not set them
+// explicitly, variants defaulted by the active rules but not the proposed ones are removed.
+func PreviewReleaseDefaults(current map[string]map[string]string, active, proposed *ReleaseDefaults) RulesPreview {
+	variants := map[string]bool{}
+	for _, v := range append(active.Variants(), proposed.Variants()...) {
+		variants[v] = true
+	}
+	keys := make([]string, 0, len(variants))
+	for v := range variants {
+		keys = append(keys, v)
+	}
+	sort.Strings(keys)
+
+	jobs := make([]string, 0, len(current))
+	for job := range current {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+
+	// Job name identification logs about jobs it can't fully identify, which isn't useful here.
+	quiet := logrus.New()
+	quiet.Out = io.Discard
+	loader := &OCPVariantLoader{}
+
+	preview := RulesPreview{Jobs: len(jobs), Changes: []RuleChange{}}
+	for _, job := range jobs {
+		explicit := loader.IdentifyVariants(quiet, job)
+		next := map[string]string{}
+		for k, v := range current[job] {
+			if _, ok := explicit[k]; !ok && variants[k] {
+				continue
+			}
+			next[k] = v
+		}
+		proposed.Apply(next, current[job][VariantRelease])
+
+		changed := false
+		for _, k := range keys {
+			oldValue, newValue := current[job][k], next[k]
+			if oldValue == newValue {
+				continue
+			}
+			changed = true
+			preview.Changes = append(preview.Changes, RuleChange{
+				JobName:  job,
+				Variant:  k,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+		if changed {
+			preview.ChangedJobs++
+		}
+	}
+	return preview
+}
@@ -0,0 +1,72 @@
+package variantregistry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewReleaseDefaults(t *testing.T) {
+	proposed, err := ParseReleaseDefaults([]byte(`
+ContainerRuntime:
+  - since: "3.11"
+    value: runc
+  - since: "4.18"
+    value: crun
+`))
+	require.NoError(t, err)
+
+	current := map[string]map[string]string{
+		"periodic-ci-openshift-release-master-nightly-4.18-e2e-aws-ovn": {
+			VariantRelease:          "4.18",
+			VariantContainerRuntime: "runc",
+			VariantCGroupMode:       "v2",
+		},
+		"periodic-ci-openshift-release-master-nightly-4.17-e2e-aws-ovn": {
+			VariantRelease:          "4.17",
+			VariantContainerRuntime: "runc",
+			VariantCGroupMode:       "v2",
+		},
+		"periodic-ci-openshift-release-master-nightly-4.18-e2e-aws-ovn-runc-cgroupsv2": {
+			VariantRelease:          "4.18",
+			VariantContainerRuntime: "runc",
+			VariantCGroupMode:       "v2",
+		},
+	}
+
+	preview := PreviewReleaseDefaults(current, DefaultReleaseDefaults(), proposed)
+	assert.Equal(t, 3, preview.Jobs)
+	assert.Equal(t, 2, preview.ChangedJobs)
+	assert.Equal(t, []RuleChange{
+		{
+			JobName:  "periodic-ci-openshift-release-master-nightly-4.17-e2e-aws-ovn",
+			Variant:  VariantCGroupMode,
+			OldValue: "v2",
+		},
+		{
+			JobName:  "periodic-ci-openshift-release-master-nightly-4.18-e2e-aws-ovn",
+			Variant:  VariantCGroupMode,
+			OldValue: "v2",
+		},
+		{
+			JobName:  "periodic-ci-openshift-release-master-nightly-4.18-e2e-aws-ovn",
+			Variant:  VariantContainerRuntime,
+			OldValue: "runc",
+			NewValue: "crun",
+		},
+	}, preview.Changes)
+}
+
+func TestPreviewReleaseDefaultsUnchanged(t *testing.T) {
+	current := map[string]map[string]string{
+		"periodic-ci-openshift-release-master-nightly-4.18-e2e-aws-ovn": {
+			VariantRelease:          "4.18",
+			VariantContainerRuntime: "runc",
+			VariantCGroupMode:       "v2",
+		},
+	}
+	preview := PreviewReleaseDefaults(current, DefaultReleaseDefaults(), DefaultReleaseDefaults())
+	assert.Equal(t, 0, preview.ChangedJobs)
+	assert.Empty(t, preview.Changes)
+}
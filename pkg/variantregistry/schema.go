@@ -0,0 +1,248 @@
+package variantregistry
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// variantSchemaRaw is the schema of OpenShift's variants, see variant_schema.yaml.
+//
+//go:embed variant_schema.yaml
+var variantSchemaRaw []byte
+
+var defaultVariantSchema = mustParseVariantSchema(variantSchemaRaw)
+
+// VariantDefinition describes a variant and the values it may have, either listed in Allowed or matching Pattern.
+type VariantDefinition struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	Allowed     []string `yaml:"allowed,omitempty"`
+	Pattern     string   `yaml:"pattern,omitempty"`
+	// Default is the value of jobs nothing else gave the variant, if set.
+	Default string `yaml:"default,omitempty"`
+}
+
+// VariantSchema defines the variants jobs are classified with, so values the loader or an override produce by
+// mistake, i.e. a misspelled amd46, are caught before they reach the registry. A nil VariantSchema allows anything.
+type VariantSchema struct {
+	raw      string
+	variants map[string]*compiledDefinition
+}
+
+type compiledDefinition struct {
+	VariantDefinition
+	allowed map[string]bool
+	pattern *regexp.Regexp
+}
+
+// VariantViolation is a variant value the schema doesn't allow.
+type VariantViolation struct {
+	Variant string
+	Value   string
+	// UnknownVariant is set for variants the schema doesn't define. These are only flagged, never rejected, as
+	// cluster-data.json can add variants the schema doesn't know about yet.
+	UnknownVariant bool
+}
+
+func (v VariantViolation) String() string {
+	if v.UnknownVariant {
+		return fmt.Sprintf("unknown variant %s=%s", v.Variant, v.Value)
+	}
+	return fmt.Sprintf("invalid value %q for variant %s", v.Value, v.Variant)
+}
+
+// SchemaEnforcement is what is done with values the variant schema doesn't allow.
+type SchemaEnforcement string
+
+const (
+	// SchemaEnforcementOff skips validation.
+	SchemaEnforcementOff SchemaEnforcement = "off"
+	// SchemaEnforcementWarn logs invalid values and keeps them.
+	SchemaEnforcementWarn SchemaEnforcement = "warn"
+	// SchemaEnforcementReject drops invalid values from calculated variants, and fails syncs that would write them
+	// to the registry.
+	SchemaEnforcementReject SchemaEnforcement = "reject"
+)
+
+// ParseSchemaEnforcement parses a SchemaEnforcement, defaulting to warn when empty.
+func ParseSchemaEnforcement(s string) (SchemaEnforcement, error) {
+	switch e := SchemaEnforcement(s); e {
+	case "":
+		return SchemaEnforcementWarn, nil
+	case SchemaEnforcementOff, SchemaEnforcementWarn, SchemaEnforcementReject:
+		return e, nil
+	}
+	return "", fmt.Errorf("invalid schema enforcement %q: must be one of off, warn, reject", s)
+}
+
+// ParseVariantSchema parses a yaml list of VariantDefinition.
+func ParseVariantSchema(data []byte) (*VariantSchema, error) {
+	raw := []VariantDefinition{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, errors.Wrap(err, "invalid variant schema")
+	}
+
+	s := &VariantSchema{raw: string(data), variants: map[string]*compiledDefinition{}}
+	for i, def := range raw {
+		if def.Name == "" {
+			return nil, errors.Errorf("variant schema entry %d has no name", i+1)
+		}
+		if _, ok := s.variants[def.Name]; ok {
+			return nil, errors.Errorf("variant %s is defined more than once", def.Name)
+		}
+		if (len(def.Allowed) == 0) == (def.Pattern == "") {
+			return nil, errors.Errorf("variant %s must have one of allowed or pattern", def.Name)
+		}
+
+		d := &compiledDefinition{VariantDefinition: def}
+		if def.Pattern != "" {
+			pattern, err := regexp.Compile(`^(?:` + def.Pattern + `)$`)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid pattern for variant %s", def.Name)
+			}
+			d.pattern = pattern
+		} else {
+			d.allowed = map[string]bool{}
+			for _, value := range def.Allowed {
+				d.allowed[value] = true
+			}
+		}
+		if def.Default != "" && !d.allows(def.Default) {
+			return nil, errors.Errorf("default %q for variant %s is not allowed", def.Default, def.Name)
+		}
+		s.variants[def.Name] = d
+	}
+	return s, nil
+}
+
+// LoadVariantSchema reads a variant schema from a file, see ParseVariantSchema.
+func LoadVariantSchema(path string) (*VariantSchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseVariantSchema(data)
+}
+
+// DefaultVariantSchema returns the schema of OpenShift's variants built into sippy.
+func DefaultVariantSchema() *VariantSchema {
+	return defaultVariantSchema
+}
+
+func mustParseVariantSchema(data []byte) *VariantSchema {
+	s, err := ParseVariantSchema(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// YAML returns the document the schema was parsed from.
+func (s *VariantSchema) YAML() string {
+	return s.raw
+}
+
+func (d *compiledDefinition) allows(value string) bool {
+	if d.pattern != nil {
+		return d.pattern.MatchString(value)
+	}
+	return d.allowed[value]
+}
+
+// check returns the violation of a variant value, if it is one.
+func (s *VariantSchema) check(variant, value string) (VariantViolation, bool) {
+	if s == nil {
+		return VariantViolation{}, false
+	}
+	d, ok := s.variants[variant]
+	if !ok {
+		return VariantViolation{Variant: variant, Value: value, UnknownVariant: true}, true
+	}
+	if d.allows(value) {
+		return VariantViolation{}, false
+	}
+	return VariantViolation{Variant: variant, Value: value}, true
+}
+
+// Validate returns the variants the schema doesn't allow, sorted by variant name.
+func (s *VariantSchema) Validate(variants map[string]string) []VariantViolation {
+	violations := []VariantViolation{}
+	for variant, value := range variants {
+		if violation, ok := s.check(variant, value); ok {
+			violations = append(violations, violation)
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Variant < violations[j].Variant
+	})
+	return violations
+}
+
+// ApplyDefaults sets every variant with a schema default that is not already present in variants.
+func (s *VariantSchema) ApplyDefaults(variants map[string]string) {
+	if s == nil {
+		return
+	}
+	for name, d := range s.variants {
+		if _, ok := variants[name]; !ok && d.Default != "" {
+			variants[name] = d.Default
+		}
+	}
+}
+
+// Enforce logs the variants of a job the schema doesn't allow, and when rejecting, removes the invalid values so
+// the job is synced without them. Unknown variants are only logged.
+func (s *VariantSchema) Enforce(jLog logrus.FieldLogger, variants map[string]string, enforcement SchemaEnforcement) {
+	if enforcement == SchemaEnforcementOff {
+		return
+	}
+	for _, violation := range s.Validate(variants) {
+		vLog := jLog.WithField("variant", violation.Variant).WithField("value", violation.Value)
+		switch {
+		case violation.UnknownVariant:
+			vLog.Debug("variant schema: variant is not defined")
+		case enforcement == SchemaEnforcementReject:
+			vLog.Errorf("variant schema: dropping %s", violation)
+			delete(variants, violation.Variant)
+		default:
+			vLog.Warnf("variant schema: %s", violation)
+		}
+	}
+}
+
+// validateJobVariants checks the variants about to be written to the registry against the schema, returning an
+// error describing the invalid values if they are to be rejected.
+func validateJobVariants(schema *VariantSchema, enforcement SchemaEnforcement, variants ...[]jobVariant) (int, error) {
+	if schema == nil || enforcement == SchemaEnforcementOff {
+		return 0, nil
+	}
+	invalid := []string{}
+	for _, variantGroup := range variants {
+		for _, jv := range variantGroup {
+			violation, ok := schema.check(jv.VariantName, jv.VariantValue)
+			if !ok || violation.UnknownVariant {
+				continue
+			}
+			logrus.WithField("job", jv.JobName).Warnf("variant schema: %s", violation)
+			invalid = append(invalid, fmt.Sprintf("%s: %s", jv.JobName, violation))
+		}
+	}
+	if len(invalid) > 0 && enforcement == SchemaEnforcementReject {
+		sort.Strings(invalid)
+		examples := invalid
+		if len(examples) > 5 {
+			examples = examples[:5]
+		}
+		return len(invalid), fmt.Errorf("%d variants violate the variant schema, i.e. %s", len(invalid),
+			strings.Join(examples, "; "))
+	}
+	return len(invalid), nil
+}
@@ -0,0 +1,164 @@
+package variantregistry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVariantSchema(t *testing.T) {
+	for data, expected := range map[string]string{
+		`name: A`:                                                   "invalid variant schema",
+		`- {name: A}`:                                               "must have one of allowed or pattern",
+		`- {name: A, pattern: 'a('}`:                                "invalid pattern for variant A",
+		`- {name: A, allowed: [a], default: b}`:                     `default "b" for variant A is not allowed`,
+		`- {description: no name, allowed: [a]}`:                    "entry 1 has no name",
+		`- {name: A, allowed: [a], pattern: 'a+'}`:                  "must have one of allowed or pattern",
+		`[{name: A, allowed: [a]}, {name: A, allowed: [b]}]`:        "A is defined more than once",
+		`[{name: Release, pattern: '\d+\.\d+', default: "latest"}]`: `default "latest" for variant Release`,
+	} {
+		_, err := ParseVariantSchema([]byte(data))
+		assert.ErrorContains(t, err, expected, data)
+	}
+
+	schema := DefaultVariantSchema()
+	for _, name := range []string{VariantAggregation, VariantArch, VariantArchMix, VariantFeatureSet, VariantInstaller,
+		VariantNetwork, VariantNetworkAccess, VariantNetworkStack, VariantOwner, VariantPlatform, VariantScheduler,
+		VariantSecurityMode, VariantSuite, VariantTopology, VariantUpgrade, VariantContainerRuntime, VariantCGroupMode,
+		VariantRelease, VariantReleaseMinor, VariantReleaseMajor, VariantFromRelease, VariantFromReleaseMinor,
+		VariantFromReleaseMajor} {
+		assert.Contains(t, schema.variants, name, "the built in schema defines every OpenShift variant")
+	}
+}
+
+func TestVariantSchemaValidate(t *testing.T) {
+	violations := DefaultVariantSchema().Validate(map[string]string{
+		VariantArch:     "amd46",
+		VariantArchMix:  "amd64+arm64",
+		VariantPlatform: "aws",
+		VariantRelease:  "4.16",
+		VariantUpgrade:  "major",
+		"Foo":           "bar",
+	})
+	assert.Equal(t, []VariantViolation{
+		{Variant: VariantArch, Value: "amd46"},
+		{Variant: "Foo", Value: "bar", UnknownVariant: true},
+		{Variant: VariantUpgrade, Value: "major"},
+	}, violations)
+	assert.Equal(t, `invalid value "amd46" for variant Architecture`, violations[0].String())
+
+	for _, release := range []string{"4.16", "3.11", "4.100"} {
+		assert.Empty(t, DefaultVariantSchema().Validate(map[string]string{VariantRelease: release}), release)
+	}
+	for _, release := range []string{"4", "4.16.1", "v4.16", ""} {
+		assert.Len(t, DefaultVariantSchema().Validate(map[string]string{VariantRelease: release}), 1,
+			"patterns must match the whole value: %q", release)
+	}
+
+	var none *VariantSchema
+	assert.Empty(t, none.Validate(map[string]string{VariantArch: "amd46"}))
+}
+
+func TestVariantSchemaEnforce(t *testing.T) {
+	variants := func() map[string]string {
+		return map[string]string{VariantArch: "amd46", VariantPlatform: "aws", "Foo": "bar"}
+	}
+
+	warned := variants()
+	DefaultVariantSchema().Enforce(logrus.New(), warned, SchemaEnforcementWarn)
+	assert.Equal(t, variants(), warned, "warning keeps invalid values")
+
+	rejected := variants()
+	DefaultVariantSchema().Enforce(logrus.New(), rejected, SchemaEnforcementReject)
+	assert.Equal(t, map[string]string{VariantPlatform: "aws", "Foo": "bar"}, rejected,
+		"rejecting drops invalid values, but keeps unknown variants")
+}
+
+func TestVariantSchemaApplyDefaults(t *testing.T) {
+	schema, err := ParseVariantSchema([]byte(`
+- name: Platform
+  description: Infrastructure the cluster runs on.
+  allowed: [aws, gcp]
+- name: Scheduler
+  description: Kernel scheduler of the nodes.
+  allowed: [default, realtime]
+  default: default
+`))
+	require.NoError(t, err)
+
+	variants := map[string]string{VariantPlatform: "aws"}
+	schema.ApplyDefaults(variants)
+	assert.Equal(t, map[string]string{VariantPlatform: "aws", VariantScheduler: "default"}, variants)
+
+	variants = map[string]string{VariantScheduler: "realtime"}
+	schema.ApplyDefaults(variants)
+	assert.Equal(t, map[string]string{VariantScheduler: "realtime"}, variants, "defaults don't replace values")
+}
+
+func TestParseSchemaEnforcement(t *testing.T) {
+	for s, expected := range map[string]SchemaEnforcement{
+		"":       SchemaEnforcementWarn,
+		"off":    SchemaEnforcementOff,
+		"warn":   SchemaEnforcementWarn,
+		"reject": SchemaEnforcementReject,
+	} {
+		e, err := ParseSchemaEnforcement(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, e)
+	}
+	_, err := ParseSchemaEnforcement("fail")
+	assert.ErrorContains(t, err, `invalid schema enforcement "fail"`)
+}
+
+func TestValidateJobVariants(t *testing.T) {
+	inserts := []jobVariant{
+		{JobName: "periodic-e2e-aws", VariantName: VariantArch, VariantValue: "amd46"},
+		{JobName: "periodic-e2e-aws", VariantName: VariantPlatform, VariantValue: "aws"},
+		{JobName: "periodic-e2e-aws", VariantName: "Foo", VariantValue: "bar"},
+	}
+	updates := []jobVariant{
+		{JobName: "periodic-e2e-gcp", VariantName: VariantUpgrade, VariantValue: "major"},
+	}
+
+	count, err := validateJobVariants(DefaultVariantSchema(), SchemaEnforcementReject, inserts, updates)
+	assert.Equal(t, 2, count)
+	assert.EqualError(t, err, `2 variants violate the variant schema, i.e. periodic-e2e-aws: invalid value "amd46" `+
+		`for variant Architecture; periodic-e2e-gcp: invalid value "major" for variant Upgrade`)
+
+	count, err = validateJobVariants(DefaultVariantSchema(), SchemaEnforcementWarn, inserts, updates)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	count, err = validateJobVariants(DefaultVariantSchema(), SchemaEnforcementOff, inserts, updates)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	count, err = validateJobVariants(nil, SchemaEnforcementReject, inserts, updates)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestCalculateVariantsForJobWithSchema(t *testing.T) {
+	overrides, err := ParseVariantOverrides([]byte(`
+- job_regex: -e2e-aws-ovn$
+  variants:
+    Architecture: amd46
+`))
+	require.NoError(t, err)
+	jobName := "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn"
+
+	loader := &OCPVariantLoader{overrides: overrides}
+	variants, err := loader.CalculateVariantsForJob(context.Background(), logrus.New(), ListedJob{Name: jobName})
+	require.NoError(t, err)
+	assert.Equal(t, "amd46", variants[VariantArch], "invalid values are only flagged by default")
+
+	loader = &OCPVariantLoader{overrides: overrides, enforcement: SchemaEnforcementReject}
+	variants, err = loader.CalculateVariantsForJob(context.Background(), logrus.New(), ListedJob{Name: jobName})
+	require.NoError(t, err)
+	assert.NotContains(t, variants, VariantArch)
+	assert.Equal(t, "aws", variants[VariantPlatform])
+	assert.Empty(t, DefaultVariantSchema().Validate(variants), "every calculated variant is in the schema")
+}
@@ -0,0 +1,324 @@
+package variantregistry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/iterator"
+)
+
+// VariantReclassifier is implemented by loaders able to recalculate the variants of jobs without listing them or
+// reading their artifacts, from the variants they were stored with, so proposed rules can be replayed against past
+// syncs. OCPVariantLoader is one.
+type VariantReclassifier interface {
+	ReclassifyJob(jLog logrus.FieldLogger, jobName string, stored map[string]string) map[string]string
+}
+
+// snapshotDateRegex matches the date a snapshot of expected variants is named after.
+var snapshotDateRegex = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// VariantSnapshot is a stored file of the expected variants synced on a day, as written by generate-job-variants.
+type VariantSnapshot struct {
+	Date time.Time
+	Path string
+}
+
+// Load reads the snapshot's expected variants, keyed by job name then variant name.
+func (s VariantSnapshot) Load() (map[string]map[string]string, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	variants := map[string]map[string]string{}
+	if err := json.Unmarshal(data, &variants); err != nil {
+		return nil, errors.Wrapf(err, "invalid expected variants in %s", s.Path)
+	}
+	return variants, nil
+}
+
+// FindVariantSnapshots lists the JSON files in a directory named with the date they were synced on, i.e.
+// expected-job-variants-2024-06-01.json, in date order. Snapshots are read one at a time while simulating, as each
+// is as large as the registry.
+func FindVariantSnapshots(dir string) ([]VariantSnapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := []VariantSnapshot{}
+	dates := map[time.Time]string{}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		match := snapshotDateRegex.FindString(e.Name())
+		if match == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", match)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid date in snapshot %s", e.Name())
+		}
+		if other, ok := dates[date]; ok {
+			return nil, fmt.Errorf("snapshots %s and %s are both from %s", other, e.Name(), match)
+		}
+		dates[date] = e.Name()
+		snapshots = append(snapshots, VariantSnapshot{Date: date, Path: filepath.Join(dir, e.Name())})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+	return snapshots, nil
+}
+
+// RegistryHistory is the registry as it was at a point in time, and the audited changes made to it since, from which
+// its state at any later time can be replayed.
+type RegistryHistory struct {
+	registry map[string]map[string]string
+	changes  []auditRow
+}
+
+// newRegistryHistory rolls the current registry back to how it was at since, by undoing the audited changes made
+// from then on.
+func newRegistryHistory(current map[string]map[string]string, changes []auditRow, since time.Time) *RegistryHistory {
+	h := &RegistryHistory{registry: map[string]map[string]string{}}
+	for job, variants := range current {
+		h.registry[job] = map[string]string{}
+		for k, v := range variants {
+			h.registry[job][k] = v
+		}
+	}
+	for _, c := range changes {
+		if !c.ChangedAt.Before(since) {
+			h.changes = append(h.changes, c)
+		}
+	}
+	sort.SliceStable(h.changes, func(i, j int) bool {
+		return h.changes[i].ChangedAt.Before(h.changes[j].ChangedAt)
+	})
+
+	for i := len(h.changes) - 1; i >= 0; i-- {
+		c := h.changes[i]
+		if c.Action == auditInsert {
+			h.set(c.JobName, c.VariantName, "")
+		} else {
+			h.set(c.JobName, c.VariantName, c.OldValue)
+		}
+	}
+	return h
+}
+
+// set sets a variant of a job in the registry, removing it if value is empty.
+func (h *RegistryHistory) set(job, variant, value string) {
+	if value == "" {
+		delete(h.registry[job], variant)
+		if len(h.registry[job]) == 0 {
+			delete(h.registry, job)
+		}
+		return
+	}
+	if _, ok := h.registry[job]; !ok {
+		h.registry[job] = map[string]string{}
+	}
+	h.registry[job][variant] = value
+}
+
+// Replay calls f with the registry as it was at each of times, in ascending order, stopping at the first error. The
+// registry passed to f is only valid until f returns, and a history can only be replayed once.
+func (h *RegistryHistory) Replay(times []time.Time, f func(i int, registry map[string]map[string]string) error) error {
+	next := 0
+	for i, t := range times {
+		for ; next < len(h.changes) && h.changes[next].ChangedAt.Before(t); next++ {
+			c := h.changes[next]
+			if c.Action == auditDelete {
+				h.set(c.JobName, c.VariantName, "")
+			} else {
+				h.set(c.JobName, c.VariantName, c.NewValue)
+			}
+		}
+		if err := f(i, h.registry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegistryHistory returns the registry's history since a time, from its current state and the changes recorded in
+// the audit table, which must be enabled with EnableAudit. It is only complete from when syncs started being audited.
+func (s *JobVariantsLoader) RegistryHistory(ctx context.Context, since time.Time) (*RegistryHistory, error) {
+	if s.auditTable == "" {
+		return nil, fmt.Errorf("the registry's history requires its audit table")
+	}
+	current, err := s.loadCurrentJobVariants()
+	if err != nil {
+		return nil, err
+	}
+
+	q := s.bqClient.Query(fmt.Sprintf("SELECT * FROM `%s.%s.%s` WHERE changed_at >= @since",
+		s.bigQueryProject, s.bigQueryDataSet, s.auditTable))
+	q.Parameters = []bigquery.QueryParameter{{Name: "since", Value: since}}
+	it, err := q.Read(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error querying audited variant changes")
+	}
+	changes := []auditRow{}
+	for {
+		row := auditRow{}
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "error parsing audited variant change")
+		}
+		changes = append(changes, row)
+	}
+	logrus.Infof("loaded %d variant changes audited since %s", len(changes), since.Format(time.RFC3339))
+	return newRegistryHistory(current, changes, since), nil
+}
+
+// SimulatedSync compares a day's sync with what it would have been under proposed rules.
+type SimulatedSync struct {
+	Date string `json:"date"`
+	Jobs int    `json:"jobs"`
+	// ActualRows is how many registry rows the day's sync changed.
+	ActualRows int `json:"actual_rows"`
+	// ProposedRows is how many rows it would have changed had the proposed rules been in place since the first day
+	// simulated. The first day includes switching the registry to the proposed rules.
+	ProposedRows int `json:"proposed_rows"`
+	// DivergentRows is how many rows the registry would have differed by after the sync, by variant name in
+	// DivergentVariants.
+	DivergentRows     int            `json:"divergent_rows"`
+	DivergentVariants map[string]int `json:"divergent_variants"`
+}
+
+// Simulation is how proposed rules would have changed a series of past syncs.
+type Simulation struct {
+	Syncs        []SimulatedSync `json:"syncs"`
+	ActualRows   int             `json:"actual_rows"`
+	ProposedRows int             `json:"proposed_rows"`
+}
+
+// SimulateSyncs replays snapshots of the expected variants synced each day against the registry as it was before
+// each sync, and against the registry the reclassifier's rules would have produced, to quantify how the rules would
+// have changed past syncs.
+func SimulateSyncs(snapshots []VariantSnapshot, history *RegistryHistory, reclassifier VariantReclassifier) (*Simulation, error) {
+	times := make([]time.Time, 0, len(snapshots))
+	for _, s := range snapshots {
+		times = append(times, s.Date)
+	}
+
+	// Reclassifying logs about jobs rules can't fully identify, which isn't useful here.
+	quiet := logrus.New()
+	quiet.Out = io.Discard
+
+	simulation := &Simulation{Syncs: []SimulatedSync{}}
+	var previous map[string]map[string]string
+	err := history.Replay(times, func(i int, registry map[string]map[string]string) error {
+		expected, err := snapshots[i].Load()
+		if err != nil {
+			return err
+		}
+		proposed := make(map[string]map[string]string, len(expected))
+		for job, variants := range expected {
+			proposed[job] = reclassifier.ReclassifyJob(quiet, job, variants)
+		}
+		if previous == nil {
+			previous = registry
+		}
+
+		sync := SimulatedSync{
+			Date:              snapshots[i].Date.Format("2006-01-02"),
+			Jobs:              len(expected),
+			ActualRows:        total(rowChanges(expected, registry)),
+			ProposedRows:      total(rowChanges(proposed, previous)),
+			DivergentVariants: rowChanges(proposed, expected),
+		}
+		sync.DivergentRows = total(sync.DivergentVariants)
+		logrus.WithFields(logrus.Fields{
+			"date":     sync.Date,
+			"actual":   sync.ActualRows,
+			"proposed": sync.ProposedRows,
+		}).Info("simulated sync")
+
+		simulation.Syncs = append(simulation.Syncs, sync)
+		simulation.ActualRows += sync.ActualRows
+		simulation.ProposedRows += sync.ProposedRows
+		previous = proposed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return simulation, nil
+}
+
+// rowChanges returns how many registry rows syncing expected into current changes, by variant name.
+func rowChanges(expected, current map[string]map[string]string) map[string]int {
+	changes := map[string]int{}
+	inserts, updates, deletes, deleteJobs := compareVariants(expected, current)
+	for _, group := range [][]jobVariant{inserts, updates, deletes} {
+		for _, jv := range group {
+			changes[jv.VariantName]++
+		}
+	}
+	for _, job := range deleteJobs {
+		for variant := range current[job] {
+			changes[variant]++
+		}
+	}
+	return changes
+}
+
+func total(counts map[string]int) int {
+	sum := 0
+	for _, c := range counts {
+		sum += c
+	}
+	return sum
+}
+
+// WriteSimulation writes a simulation as a table of the rows each day's sync changed and would have changed.
+func WriteSimulation(w io.Writer, simulation *Simulation) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tJOBS\tACTUAL ROWS\tPROPOSED ROWS\tDIVERGENT ROWS\tMOST DIVERGENT")
+	for _, s := range simulation.Syncs {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", s.Date, s.Jobs, s.ActualRows, s.ProposedRows, s.DivergentRows,
+			mostDivergent(s.DivergentVariants))
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t%d\t%d\t\t\n", simulation.ActualRows, simulation.ProposedRows)
+	return tw.Flush()
+}
+
+// mostDivergent returns the variants differing in the most rows, up to three.
+func mostDivergent(counts map[string]int) string {
+	variants := make([]string, 0, len(counts))
+	for v := range counts {
+		variants = append(variants, v)
+	}
+	sort.Slice(variants, func(i, j int) bool {
+		if counts[variants[i]] != counts[variants[j]] {
+			return counts[variants[i]] > counts[variants[j]]
+		}
+		return variants[i] < variants[j]
+	})
+	if len(variants) > 3 {
+		variants = variants[:3]
+	}
+	for i, v := range variants {
+		variants[i] = fmt.Sprintf("%s (%d)", v, counts[v])
+	}
+	return strings.Join(variants, ", ")
+}
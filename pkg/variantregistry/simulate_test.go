@@ -0,0 +1,183 @@
+package variantregistry
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func day(date string) time.Time {
+	t, _ := time.Parse("2006-01-02", date)
+	return t
+}
+
+func writeSnapshot(t *testing.T, dir, name string, variants map[string]map[string]string) {
+	data, err := json.Marshal(variants)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func TestFindVariantSnapshots(t *testing.T) {
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "expected-job-variants-2024-06-02.json", nil)
+	writeSnapshot(t, dir, "expected-job-variants-2024-06-01.json", nil)
+	writeSnapshot(t, dir, "expected-job-variants.json", nil)
+	writeSnapshot(t, dir, "notes-2024-06-03.txt", nil)
+
+	snapshots, err := FindVariantSnapshots(dir)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, day("2024-06-01"), snapshots[0].Date)
+	assert.Equal(t, filepath.Join(dir, "expected-job-variants-2024-06-01.json"), snapshots[0].Path)
+	assert.Equal(t, day("2024-06-02"), snapshots[1].Date)
+
+	writeSnapshot(t, dir, "retry-2024-06-02.json", nil)
+	_, err = FindVariantSnapshots(dir)
+	assert.ErrorContains(t, err, "are both from 2024-06-02")
+}
+
+func TestRegistryHistory(t *testing.T) {
+	current := map[string]map[string]string{
+		"job-a": {VariantPlatform: "gcp", VariantOwner: "eng"},
+		"job-c": {VariantPlatform: "aws"},
+	}
+	changes := []auditRow{
+		{JobName: "job-a", VariantName: VariantPlatform, Action: auditUpdate, OldValue: "aws", NewValue: "gcp",
+			ChangedAt: day("2024-06-02").Add(time.Hour)},
+		{JobName: "job-b", VariantName: VariantPlatform, Action: auditDelete, OldValue: "azure",
+			ChangedAt: day("2024-06-02").Add(time.Hour)},
+		{JobName: "job-c", VariantName: VariantPlatform, Action: auditInsert, NewValue: "aws",
+			ChangedAt: day("2024-06-01").Add(time.Hour)},
+		{JobName: "job-a", VariantName: VariantOwner, Action: auditInsert, NewValue: "eng",
+			ChangedAt: day("2024-05-01")},
+	}
+
+	history := newRegistryHistory(current, changes, day("2024-06-01"))
+	var states []map[string]map[string]string
+	err := history.Replay([]time.Time{day("2024-06-01"), day("2024-06-02"), day("2024-06-03")},
+		func(i int, registry map[string]map[string]string) error {
+			data, err := json.Marshal(registry)
+			require.NoError(t, err)
+			state := map[string]map[string]string{}
+			require.NoError(t, json.Unmarshal(data, &state))
+			states = append(states, state)
+			return nil
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, []map[string]map[string]string{
+		{
+			"job-a": {VariantPlatform: "aws", VariantOwner: "eng"},
+			"job-b": {VariantPlatform: "azure"},
+		},
+		{
+			"job-a": {VariantPlatform: "aws", VariantOwner: "eng"},
+			"job-b": {VariantPlatform: "azure"},
+			"job-c": {VariantPlatform: "aws"},
+		},
+		current,
+	}, states, "changes from before the history starts are not undone")
+}
+
+// platformReclassifier proposes classifying every job on gcp.
+type platformReclassifier struct{}
+
+func (platformReclassifier) ReclassifyJob(_ logrus.FieldLogger, _ string, stored map[string]string) map[string]string {
+	variants := map[string]string{}
+	for k, v := range stored {
+		variants[k] = v
+	}
+	variants[VariantPlatform] = "gcp"
+	return variants
+}
+
+func TestSimulateSyncs(t *testing.T) {
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "expected-job-variants-2024-06-01.json", map[string]map[string]string{
+		"job-a": {VariantPlatform: "aws", VariantOwner: "eng"},
+		"job-b": {VariantPlatform: "aws", VariantOwner: "eng"},
+	})
+	writeSnapshot(t, dir, "expected-job-variants-2024-06-02.json", map[string]map[string]string{
+		"job-a": {VariantPlatform: "aws", VariantOwner: "qe"},
+		"job-b": {VariantPlatform: "aws", VariantOwner: "eng"},
+		"job-c": {VariantPlatform: "aws", VariantOwner: "eng"},
+	})
+	snapshots, err := FindVariantSnapshots(dir)
+	require.NoError(t, err)
+
+	// the registry before the first sync only has job-a, and each sync succeeded
+	current := map[string]map[string]string{
+		"job-a": {VariantPlatform: "aws", VariantOwner: "qe"},
+		"job-b": {VariantPlatform: "aws", VariantOwner: "eng"},
+		"job-c": {VariantPlatform: "aws", VariantOwner: "eng"},
+	}
+	changes := []auditRow{
+		{JobName: "job-b", VariantName: VariantPlatform, Action: auditInsert, NewValue: "aws", ChangedAt: day("2024-06-01")},
+		{JobName: "job-b", VariantName: VariantOwner, Action: auditInsert, NewValue: "eng", ChangedAt: day("2024-06-01")},
+		{JobName: "job-a", VariantName: VariantOwner, Action: auditUpdate, OldValue: "eng", NewValue: "qe", ChangedAt: day("2024-06-02")},
+		{JobName: "job-c", VariantName: VariantPlatform, Action: auditInsert, NewValue: "aws", ChangedAt: day("2024-06-02")},
+		{JobName: "job-c", VariantName: VariantOwner, Action: auditInsert, NewValue: "eng", ChangedAt: day("2024-06-02")},
+	}
+	history := newRegistryHistory(current, changes, day("2024-06-01"))
+
+	simulation, err := SimulateSyncs(snapshots, history, platformReclassifier{})
+	require.NoError(t, err)
+	assert.Equal(t, &Simulation{
+		Syncs: []SimulatedSync{
+			{
+				Date:              "2024-06-01",
+				Jobs:              2,
+				ActualRows:        2,
+				ProposedRows:      3, // job-b inserted on gcp, and job-a switched to gcp
+				DivergentRows:     2,
+				DivergentVariants: map[string]int{VariantPlatform: 2},
+			},
+			{
+				Date:              "2024-06-02",
+				Jobs:              3,
+				ActualRows:        3,
+				ProposedRows:      3, // the same Owner change and new job, which is on gcp
+				DivergentRows:     3,
+				DivergentVariants: map[string]int{VariantPlatform: 3},
+			},
+		},
+		ActualRows:   5,
+		ProposedRows: 6,
+	}, simulation)
+
+	var out bytes.Buffer
+	require.NoError(t, WriteSimulation(&out, simulation))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"2024-06-02", "3", "3", "3", "3", "Platform", "(3)"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"TOTAL", "5", "6"}, strings.Fields(lines[3]))
+}
+
+func TestReclassifyJob(t *testing.T) {
+	defaults, err := ParseReleaseDefaults([]byte(`
+ContainerRuntime:
+  - since: "3.11"
+    value: crun
+`))
+	require.NoError(t, err)
+	loader := &OCPVariantLoader{defaults: defaults}
+
+	variants := loader.ReclassifyJob(logrus.New(), "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn",
+		map[string]string{
+			VariantPlatform:         "gcp",   // from an older rule, replaced by the job name's
+			VariantContainerRuntime: "runc",  // from older release defaults, recalculated
+			"CloudProfile":          "aws-2", // from cluster data, kept
+		})
+	assert.Equal(t, "aws", variants[VariantPlatform])
+	assert.Equal(t, "crun", variants[VariantContainerRuntime])
+	assert.Equal(t, "aws-2", variants["CloudProfile"])
+	assert.Equal(t, "4.16", variants[VariantRelease])
+}
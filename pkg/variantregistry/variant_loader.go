@@ -0,0 +1,115 @@
+package variantregistry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// VariantLoader determines the variants of every job of a product. OCPVariantLoader is OpenShift's; other products,
+// i.e. Kubernetes, ROSA standalone or OKD, register a loader with their own job name parsing, and the expected
+// variants it loads are reconciled into the registry by the same JobVariantsLoader.
+type VariantLoader interface {
+	// ListJobs returns the jobs to classify.
+	ListJobs(ctx context.Context) ([]ListedJob, error)
+	// CalculateVariantsForJob returns the variants of a job, keyed by variant name.
+	CalculateVariantsForJob(ctx context.Context, jLog logrus.FieldLogger, job ListedJob) (map[string]string, error)
+}
+
+// ListedJob is a job to classify, with the URL of a recent run whose artifacts can be read for variants, if it has
+// one.
+type ListedJob struct {
+	Name   string
+	RunURL string
+}
+
+// VariantLoaderOptions are what a loader is created with. Loaders use what they need, and error if something they
+// need is missing.
+type VariantLoaderOptions struct {
+	BigQueryClient  *bigquery.Client
+	BigQueryProject string
+	BigQueryDataSet string
+	// BigQueryTable is the table of job runs to list jobs from.
+	BigQueryTable string
+	GCSClient     *storage.Client
+	GCSBucket     string
+	Exclusions    *JobExclusions
+	Defaults      *ReleaseDefaults
+	Overrides     *VariantOverrides
+	// Schema is the variant schema calculated variants are checked against, and SchemaEnforcement what is done
+	// with values it doesn't allow. Loaders may default either.
+	Schema            *VariantSchema
+	SchemaEnforcement SchemaEnforcement
+}
+
+// VariantLoaderFactory creates a variant loader.
+type VariantLoaderFactory func(opts VariantLoaderOptions) (VariantLoader, error)
+
+var (
+	variantLoadersLock sync.RWMutex
+	variantLoaders     = map[string]VariantLoaderFactory{}
+)
+
+// RegisterVariantLoader registers a loader under a name, i.e. the generate-job-variants --mode, replacing any
+// loader registered with the same name.
+func RegisterVariantLoader(name string, factory VariantLoaderFactory) {
+	variantLoadersLock.Lock()
+	defer variantLoadersLock.Unlock()
+	variantLoaders[name] = factory
+}
+
+// VariantLoaderNames returns the names of the registered loaders, sorted.
+func VariantLoaderNames() []string {
+	variantLoadersLock.RLock()
+	defer variantLoadersLock.RUnlock()
+	names := make([]string, 0, len(variantLoaders))
+	for name := range variantLoaders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewVariantLoader creates the loader registered under name.
+func NewVariantLoader(name string, opts VariantLoaderOptions) (VariantLoader, error) {
+	variantLoadersLock.RLock()
+	factory, ok := variantLoaders[name]
+	variantLoadersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown variant loader %q: must be one of %s", name, strings.Join(VariantLoaderNames(), ", "))
+	}
+	return factory(opts)
+}
+
+// LoadExpectedJobVariants lists a loader's jobs and calculates the variants of each, keyed by job name then
+// variant name, as the JobVariantsLoader expects them.
+func LoadExpectedJobVariants(ctx context.Context, loader VariantLoader) (map[string]map[string]string, error) {
+	log := logrus.WithField("func", "LoadExpectedJobVariants")
+	start := time.Now()
+
+	jobs, err := loader.ListJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedVariants := map[string]map[string]string{}
+	for i, job := range jobs {
+		jLog := log.WithField("job", job.Name)
+		variants, err := loader.CalculateVariantsForJob(ctx, jLog, job)
+		if err != nil {
+			return nil, err
+		}
+		jLog.WithField("variants", variants).WithField("count", i+1).Info("calculated variants")
+		expectedVariants[job.Name] = variants
+	}
+	log.WithField("count", len(expectedVariants)).Infof("processed job list in %s", time.Since(start))
+
+	return expectedVariants, nil
+}
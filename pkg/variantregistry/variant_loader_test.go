@@ -0,0 +1,64 @@
+package variantregistry
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// kubeVariantLoader classifies jobs by platform from their names, as a product outside OpenShift might.
+type kubeVariantLoader struct {
+	jobs []ListedJob
+}
+
+func (l kubeVariantLoader) ListJobs(context.Context) ([]ListedJob, error) {
+	return l.jobs, nil
+}
+
+func (l kubeVariantLoader) CalculateVariantsForJob(_ context.Context, _ logrus.FieldLogger, job ListedJob) (map[string]string, error) {
+	if strings.Contains(job.Name, "invalid") {
+		return nil, fmt.Errorf("can't classify %s", job.Name)
+	}
+	platform := "gce"
+	if strings.Contains(job.Name, "-aws") {
+		platform = "aws"
+	}
+	return map[string]string{VariantPlatform: platform}, nil
+}
+
+func TestVariantLoaderRegistration(t *testing.T) {
+	assert.Contains(t, VariantLoaderNames(), "ocp")
+
+	jobs := []ListedJob{{Name: "ci-kubernetes-e2e-aws"}, {Name: "ci-kubernetes-e2e-gce"}}
+	RegisterVariantLoader("kube", func(VariantLoaderOptions) (VariantLoader, error) {
+		return kubeVariantLoader{jobs: jobs}, nil
+	})
+	defer func() {
+		variantLoadersLock.Lock()
+		delete(variantLoaders, "kube")
+		variantLoadersLock.Unlock()
+	}()
+	assert.Equal(t, []string{"kube", "ocp"}, VariantLoaderNames())
+
+	loader, err := NewVariantLoader("kube", VariantLoaderOptions{})
+	require.NoError(t, err)
+	expected, err := LoadExpectedJobVariants(context.Background(), loader)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"ci-kubernetes-e2e-aws": {VariantPlatform: "aws"},
+		"ci-kubernetes-e2e-gce": {VariantPlatform: "gce"},
+	}, expected)
+
+	_, err = LoadExpectedJobVariants(context.Background(), kubeVariantLoader{jobs: []ListedJob{{Name: "invalid"}}})
+	assert.Error(t, err)
+
+	_, err = NewVariantLoader("okd", VariantLoaderOptions{})
+	assert.ErrorContains(t, err, "must be one of kube, ocp")
+	_, err = NewVariantLoader("ocp", VariantLoaderOptions{})
+	assert.Error(t, err, "ocp requires a GCS client")
+}
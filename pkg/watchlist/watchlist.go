@@ -0,0 +1,159 @@
+// Package watchlist evaluates watch subscriptions to individual tests, jobs or variants, and notifies subscribers
+// when a pass rate crosses their threshold or a related regression opens or closes. It complements component
+// readiness view notifications for people who only care about a handful of signals.
+package watchlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/notification"
+)
+
+const (
+	EventBelowThreshold   = "below_threshold"
+	EventAboveThreshold   = "above_threshold"
+	EventRegressionOpened = "regression_opened"
+	EventRegressionClosed = "regression_closed"
+)
+
+// Event is something a subscriber is notified of.
+type Event struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Notification is the events for one subscription. The text field makes it usable as a Slack incoming webhook
+// message, the remaining fields are for other consumers.
+type Notification struct {
+	Text           string  `json:"text"`
+	SubscriptionID uint    `json:"subscription_id"`
+	Subscriber     string  `json:"subscriber"`
+	Target         string  `json:"target"`
+	Events         []Event `json:"events"`
+}
+
+// Target describes what a subscription watches.
+func Target(sub *models.WatchSubscription) string {
+	var target string
+	switch {
+	case sub.TestName != "":
+		target = fmt.Sprintf("test %q", sub.TestName)
+	case sub.JobName != "":
+		target = fmt.Sprintf("job %s", sub.JobName)
+	default:
+		target = "jobs"
+	}
+	if len(sub.Variants) > 0 {
+		target += " with " + strings.Join(sub.Variants, ", ")
+	}
+	return fmt.Sprintf("%s in %s", target, sub.Release)
+}
+
+// PassRateEvent returns the event for a subscription's pass rate, if it crossed the threshold since last checked,
+// and the subscription's new state. Nothing is notified the first time a subscription is checked, or when there
+// were no runs.
+func PassRateEvent(sub *models.WatchSubscription, runs, successes int) (*Event, string) {
+	if sub.Threshold <= 0 || runs == 0 {
+		return nil, sub.LastState
+	}
+	percentage := float64(successes) * 100.0 / float64(runs)
+	state := models.WatchStateAbove
+	if percentage < sub.Threshold {
+		state = models.WatchStateBelow
+	}
+	if sub.LastState == "" || sub.LastState == state {
+		return nil, state
+	}
+
+	if state == models.WatchStateBelow {
+		return &Event{
+			Kind: EventBelowThreshold,
+			Message: fmt.Sprintf("pass rate dropped to %.1f%% (%d/%d runs), below %.1f%%",
+				percentage, successes, runs, sub.Threshold),
+		}, state
+	}
+	return &Event{
+		Kind: EventAboveThreshold,
+		Message: fmt.Sprintf("pass rate recovered to %.1f%% (%d/%d runs), above %.1f%%",
+			percentage, successes, runs, sub.Threshold),
+	}, state
+}
+
+// RegressionEvents returns events for regressions related to a subscription that opened or closed after since.
+// Regressions are related if they are for the subscription's test, if any, and have all of its variants. Job
+// subscriptions have no related regressions, as regressions aren't tracked per job.
+func RegressionEvents(sub *models.WatchSubscription, regressions []models.TestRegression, since time.Time) []Event {
+	if !sub.NotifyRegressions || sub.JobName != "" {
+		return nil
+	}
+
+	var events []Event
+	for _, r := range regressions {
+		if sub.TestName != "" && r.TestName != sub.TestName {
+			continue
+		}
+		if !hasVariants(r.Variants, sub.Variants) {
+			continue
+		}
+		name := fmt.Sprintf("%s [%s]", r.TestName, strings.Join(r.Variants, " "))
+		if r.Opened.After(since) {
+			events = append(events, Event{Kind: EventRegressionOpened, Message: "regression opened: " + name})
+		}
+		if r.Closed != nil && r.Closed.After(since) {
+			events = append(events, Event{Kind: EventRegressionClosed, Message: "regression closed: " + name})
+		}
+	}
+	return events
+}
+
+func hasVariants(have, want []string) bool {
+	set := map[string]bool{}
+	for _, v := range have {
+		set[v] = true
+	}
+	for _, v := range want {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
+
+// NewNotification builds the notification for a subscription's events.
+func NewNotification(sub *models.WatchSubscription, events []Event) Notification {
+	target := Target(sub)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Sippy watchlist changes for %s:\n", target)
+	for _, e := range events {
+		fmt.Fprintf(&sb, "• %s\n", e.Message)
+	}
+	return Notification{
+		Text:           sb.String(),
+		SubscriptionID: sub.ID,
+		Subscriber:     sub.Subscriber,
+		Target:         target,
+		Events:         events,
+	}
+}
+
+// Send posts a notification to the subscription's webhook and e-mails it to the subscription's addresses.
+func Send(ctx context.Context, smtpConfig notification.SMTPConfig, sub *models.WatchSubscription, n Notification) error {
+	var destinations []notification.Destination
+	if sub.WebhookURL != "" {
+		destinations = append(destinations, notification.Destination{Transport: notification.WebhookURL(sub.WebhookURL)})
+	}
+	if len(sub.Email) > 0 {
+		destinations = append(destinations, notification.Destination{
+			Transport: notification.NewEmailTransport(smtpConfig, sub.Email),
+		})
+	}
+	msg := notification.Message{Subject: "Sippy watchlist changes for " + n.Target, Text: n.Text, Data: n}
+	if err := notification.Send(ctx, msg, destinations...); err != nil {
+		return fmt.Errorf("error sending notifications for subscription %d: %w", sub.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package watchlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openshift/sippy/pkg/db/models"
+)
+
+func TestPassRateEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		threshold     float64
+		lastState     string
+		runs          int
+		successes     int
+		expectedKind  string
+		expectedState string
+	}{
+		{
+			name:          "first check records baseline",
+			threshold:     90,
+			runs:          10,
+			successes:     5,
+			expectedState: models.WatchStateBelow,
+		},
+		{
+			name:          "dropped below threshold",
+			threshold:     90,
+			lastState:     models.WatchStateAbove,
+			runs:          10,
+			successes:     8,
+			expectedKind:  EventBelowThreshold,
+			expectedState: models.WatchStateBelow,
+		},
+		{
+			name:          "recovered to threshold",
+			threshold:     90,
+			lastState:     models.WatchStateBelow,
+			runs:          10,
+			successes:     9,
+			expectedKind:  EventAboveThreshold,
+			expectedState: models.WatchStateAbove,
+		},
+		{
+			name:          "still below",
+			threshold:     90,
+			lastState:     models.WatchStateBelow,
+			runs:          10,
+			successes:     1,
+			expectedState: models.WatchStateBelow,
+		},
+		{
+			name:          "no runs keeps state",
+			threshold:     90,
+			lastState:     models.WatchStateAbove,
+			expectedState: models.WatchStateAbove,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sub := &models.WatchSubscription{Threshold: tc.threshold, LastState: tc.lastState}
+			event, state := PassRateEvent(sub, tc.runs, tc.successes)
+			assert.Equal(t, tc.expectedState, state)
+			if tc.expectedKind == "" {
+				assert.Nil(t, event)
+			} else {
+				require.NotNil(t, event)
+				assert.Equal(t, tc.expectedKind, event.Kind)
+			}
+		})
+	}
+}
+
+func TestRegressionEvents(t *testing.T) {
+	since := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+	before := since.Add(-time.Hour)
+	after := since.Add(time.Hour)
+	regressions := []models.TestRegression{
+		{TestName: "test a", Variants: pq.StringArray{"Network:ovn", "Platform:aws"}, Opened: after},
+		{TestName: "test a", Variants: pq.StringArray{"Network:ovn", "Platform:gcp"}, Opened: after},
+		{TestName: "test b", Variants: pq.StringArray{"Network:ovn", "Platform:aws"}, Opened: before, Closed: &after},
+		{TestName: "test c", Variants: pq.StringArray{"Network:ovn", "Platform:aws"}, Opened: before},
+	}
+
+	tests := []struct {
+		name     string
+		sub      models.WatchSubscription
+		expected []Event
+	}{
+		{
+			name: "test with variants",
+			sub:  models.WatchSubscription{TestName: "test a", Variants: pq.StringArray{"Platform:aws"}, NotifyRegressions: true},
+			expected: []Event{
+				{Kind: EventRegressionOpened, Message: "regression opened: test a [Network:ovn Platform:aws]"},
+			},
+		},
+		{
+			name: "variants only",
+			sub:  models.WatchSubscription{Variants: pq.StringArray{"Platform:aws"}, NotifyRegressions: true},
+			expected: []Event{
+				{Kind: EventRegressionOpened, Message: "regression opened: test a [Network:ovn Platform:aws]"},
+				{Kind: EventRegressionClosed, Message: "regression closed: test b [Network:ovn Platform:aws]"},
+			},
+		},
+		{
+			name: "not subscribed to regressions",
+			sub:  models.WatchSubscription{TestName: "test a"},
+		},
+		{
+			name: "job",
+			sub:  models.WatchSubscription{JobName: "job", NotifyRegressions: true},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, RegressionEvents(&tc.sub, regressions, since))
+		})
+	}
+}
+
+func TestNewNotification(t *testing.T) {
+	sub := &models.WatchSubscription{
+		Model:      models.Model{ID: 7},
+		Subscriber: "jdoe",
+		Release:    "4.16",
+		JobName:    "periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn",
+	}
+	n := NewNotification(sub, []Event{{Kind: EventBelowThreshold, Message: "pass rate dropped"}})
+	assert.Equal(t, uint(7), n.SubscriptionID)
+	assert.Equal(t, "job periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn in 4.16", n.Target)
+	assert.Equal(t, "Sippy watchlist changes for job periodic-ci-openshift-release-master-nightly-4.16-e2e-aws-ovn in 4.16:\n• pass rate dropped\n", n.Text)
+}
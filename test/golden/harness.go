@@ -0,0 +1,296 @@
+// Package golden is a harness for end-to-end tests of report generation. It loads fixture data into an ephemeral
+// postgres database, and compares report output against golden JSON files, so refactors of the query code can be
+// validated against the reports they produce rather than the SQL they generate.
+//
+// A database is started with docker or podman for each test binary, or SIPPY_TEST_DATABASE_DSN can point at an
+// empty database to use instead. Tests are skipped if neither is available. Run the tests with -update to
+// regenerate the golden files after an intended change to report output.
+//
+// The container is managed through the docker or podman CLI rather than a client library such as dockertest, which
+// only speaks the Docker API and would bring the docker client and its dependencies into the module for the three
+// commands the harness needs.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	gormlogger "gorm.io/gorm/logger"
+
+	v1 "github.com/openshift/sippy/pkg/apis/sippyprocessing/v1"
+	"github.com/openshift/sippy/pkg/db"
+	"github.com/openshift/sippy/pkg/db/models"
+	"github.com/openshift/sippy/pkg/sippyserver"
+)
+
+const postgresImage = "quay.io/enterprisedb/postgresql"
+
+// ReportEnd is the pinned time reports are generated at. Fixture timestamps are relative to it, so output does not
+// change from one run to the next.
+var ReportEnd = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+var update = flag.Bool("update", false, "update golden files with the current output")
+
+var (
+	postgresOnce sync.Once
+	postgresDSN  string
+	postgresErr  error
+	containerID  string
+	containerCLI string
+)
+
+// Fixtures describes the job runs and test results loaded into the database.
+type Fixtures struct {
+	Release string       `json:"release"`
+	Jobs    []FixtureJob `json:"jobs"`
+}
+
+type FixtureJob struct {
+	Name     string       `json:"name"`
+	Variants []string     `json:"variants"`
+	Runs     []FixtureRun `json:"runs"`
+}
+
+// FixtureRun is a single job run, HoursAgo before ReportEnd. Tests map test names to "pass", "fail" or "flake",
+// and are recorded in the openshift-tests suite.
+type FixtureRun struct {
+	HoursAgo int               `json:"hours_ago"`
+	Tests    map[string]string `json:"tests"`
+}
+
+var fixtureStatuses = map[string]v1.TestStatus{
+	"pass":  v1.TestStatusSuccess,
+	"fail":  v1.TestStatusFailure,
+	"flake": v1.TestStatusFlake,
+}
+
+// Main runs the tests in a package, removing the database container afterwards. Packages using NewDB call it from
+// TestMain.
+func Main(m *testing.M) {
+	code := m.Run()
+	if containerID != "" {
+		_ = exec.Command(containerCLI, "rm", "-f", containerID).Run()
+	}
+	os.Exit(code)
+}
+
+// NewDB returns a migrated database with the fixtures in path loaded, and the materialized views refreshed as of
+// ReportEnd. The database is shared by the tests in a package, so they should load the same fixtures.
+func NewDB(t *testing.T, path string) *db.DB {
+	t.Helper()
+
+	postgresOnce.Do(func() {
+		postgresDSN = os.Getenv("SIPPY_TEST_DATABASE_DSN")
+		if postgresDSN == "" {
+			postgresDSN, postgresErr = startPostgres()
+		}
+	})
+	if postgresErr != nil {
+		t.Skipf("no database available: %v", postgresErr)
+	}
+
+	dbc, err := connect(postgresDSN)
+	if err != nil {
+		t.Fatalf("couldn't connect to database: %v", err)
+	}
+	reportEnd := ReportEnd
+	if err := dbc.UpdateSchema(&reportEnd); err != nil {
+		t.Fatalf("couldn't migrate database: %v", err)
+	}
+
+	var count int64
+	if err := dbc.DB.Model(&models.ProwJob{}).Count(&count).Error; err != nil {
+		t.Fatalf("couldn't check for fixtures: %v", err)
+	}
+	if count == 0 {
+		if err := LoadFixtures(dbc, path); err != nil {
+			t.Fatalf("couldn't load fixtures: %v", err)
+		}
+		sippyserver.RefreshData(dbc, &reportEnd, false)
+	}
+	return dbc
+}
+
+func startPostgres() (string, error) {
+	for _, cli := range []string{"docker", "podman"} {
+		if _, err := exec.LookPath(cli); err == nil {
+			containerCLI = cli
+			break
+		}
+	}
+	if containerCLI == "" {
+		return "", errors.New("SIPPY_TEST_DATABASE_DSN is not set, and neither docker nor podman were found")
+	}
+
+	out, err := exec.Command(containerCLI, "run", "-d", "-e", "POSTGRES_PASSWORD=password", "-p", "127.0.0.1::5432",
+		postgresImage).Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "couldn't start %s", postgresImage)
+	}
+	containerID = strings.TrimSpace(string(out))
+
+	out, err = exec.Command(containerCLI, "port", containerID, "5432/tcp").Output()
+	if err != nil {
+		return "", errors.Wrap(err, "couldn't find postgres port")
+	}
+	hostPort := strings.TrimSpace(strings.Split(string(out), "\n")[0])
+	return fmt.Sprintf("postgresql://postgres:password@%s/postgres?sslmode=disable", hostPort), nil
+}
+
+// connect waits up to 30 seconds for a freshly started database to accept connections.
+func connect(dsn string) (*db.DB, error) {
+	var err error
+	for i := 0; i < 30; i++ {
+		var dbc *db.DB
+		if dbc, err = db.New(dsn, gormlogger.Silent); err == nil {
+			return dbc, nil
+		}
+		time.Sleep(time.Second)
+	}
+	return nil, err
+}
+
+// LoadFixtures inserts the jobs, runs and test results described in the JSON file at path.
+func LoadFixtures(dbc *db.DB, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(b, &fixtures); err != nil {
+		return errors.Wrapf(err, "couldn't parse %s", path)
+	}
+
+	var suite models.Suite
+	if err := dbc.DB.Where("name = ?", "openshift-tests").First(&suite).Error; err != nil {
+		return errors.Wrap(err, "couldn't find suite")
+	}
+
+	tests := map[string]uint{}
+	for _, fj := range fixtures.Jobs {
+		job := models.ProwJob{
+			Kind:     models.ProwPeriodic,
+			Name:     fj.Name,
+			Release:  fixtures.Release,
+			Variants: fj.Variants,
+		}
+		if err := dbc.DB.Create(&job).Error; err != nil {
+			return errors.Wrapf(err, "couldn't create job %s", fj.Name)
+		}
+
+		for i, fr := range fj.Runs {
+			timestamp := ReportEnd.Add(-time.Duration(fr.HoursAgo) * time.Hour)
+			run := models.ProwJobRun{
+				ProwJobID:     job.ID,
+				URL:           fmt.Sprintf("https://prow.ci.openshift.org/view/gs/test-platform-results/logs/%s/%d", fj.Name, i),
+				Timestamp:     timestamp,
+				Succeeded:     true,
+				OverallResult: v1.JobSucceeded,
+			}
+			for _, status := range fr.Tests {
+				if status == "fail" {
+					run.TestFailures++
+					run.Succeeded = false
+					run.Failed = true
+					run.OverallResult = v1.JobTestFailure
+				}
+			}
+			run.CreatedAt = timestamp
+			if err := dbc.DB.Create(&run).Error; err != nil {
+				return errors.Wrapf(err, "couldn't create run of %s", fj.Name)
+			}
+
+			// insert in name order so IDs, and the reports including them, are stable
+			names := make([]string, 0, len(fr.Tests))
+			for name := range fr.Tests {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				status := fr.Tests[name]
+				testStatus, ok := fixtureStatuses[status]
+				if !ok {
+					return fmt.Errorf("unknown status %q for test %s", status, name)
+				}
+				if _, ok := tests[name]; !ok {
+					test := models.Test{Name: name}
+					if err := dbc.DB.Create(&test).Error; err != nil {
+						return errors.Wrapf(err, "couldn't create test %s", name)
+					}
+					tests[name] = test.ID
+				}
+				result := models.ProwJobRunTest{
+					ProwJobRunID: run.ID,
+					TestID:       tests[name],
+					SuiteID:      &suite.ID,
+					Status:       int(testStatus),
+					CreatedAt:    timestamp,
+				}
+				if err := dbc.DB.Create(&result).Error; err != nil {
+					return errors.Wrapf(err, "couldn't create result of %s", name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// AssertGolden compares the JSON encoding of got against testdata/<name>.golden.json, or writes it there if the
+// tests are run with -update.
+func AssertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, got, "", "  "); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, got)
+	}
+	indented.WriteString("\n")
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil { //nolint:gosec
+			t.Fatalf("couldn't write golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("couldn't read golden file, run with -update to create it: %v", err)
+	}
+	if !bytes.Equal(want, indented.Bytes()) {
+		t.Errorf("output differs from %s, run with -update if this is intended:\n%s", path, diffLines(string(want), indented.String()))
+	}
+}
+
+// diffLines returns the lines that differ between two outputs, which is enough to spot a change in a report
+// without pulling in a diff library.
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+	var sb strings.Builder
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&sb, "line %d:\n- %s\n+ %s\n", i+1, w, g)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,64 @@
+package golden
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openshift/sippy/pkg/api"
+)
+
+const fixturesPath = "testdata/fixtures.json"
+
+func TestMain(m *testing.M) {
+	Main(m)
+}
+
+func TestReports(t *testing.T) {
+	dbc := NewDB(t, fixturesPath)
+
+	tests := []struct {
+		name    string
+		url     string
+		handler func(w http.ResponseWriter, req *http.Request)
+	}{
+		{
+			name: "tests",
+			url:  "/api/tests?release=4.16",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				api.PrintTestsJSONFromDB("4.16", w, req, dbc)
+			},
+		},
+		{
+			name: "tests-by-variant",
+			url:  "/api/tests?release=4.16&collapse=false",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				api.PrintTestsJSONFromDB("4.16", w, req, dbc)
+			},
+		},
+		{
+			name: "jobs",
+			url:  "/api/jobs?release=4.16",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				api.PrintJobsReportFromDB(w, req, dbc, "4.16", ReportEnd)
+			},
+		},
+		{
+			name: "variants",
+			url:  "/api/variants?release=4.16",
+			handler: func(w http.ResponseWriter, req *http.Request) {
+				api.PrintVariantReportFromDB(w, req, dbc, "4.16", ReportEnd)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+			}
+			AssertGolden(t, tt.name, w.Body.Bytes())
+		})
+	}
+}